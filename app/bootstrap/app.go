@@ -0,0 +1,206 @@
+// Package bootstrap wires the repositories, services, interactors and web
+// handlers together from the config. Build assembles the whole graph, the
+// Open and New functions it calls can be used on their own to assemble
+// parts of it.
+package bootstrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"game-tracker/domain"
+	"game-tracker/infrastructure"
+	"game-tracker/interfaces"
+	"game-tracker/interfaces/web"
+	"game-tracker/models/postgres"
+	"game-tracker/routes"
+	"game-tracker/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type App struct {
+	Config       postgres.Configuration
+	Repositories Repositories
+	Caches       Caches
+	Services     Services
+	Interactors  Interactors
+	Handler      interfaces.WebserviceHandler
+	Site         web.Site
+	Engine       *gin.Engine
+	Diagnostics  *gin.Engine //Nil unless Diagnostics.Address is set
+}
+
+func LoadConfig(path string) (postgres.Configuration, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return postgres.Configuration{}, fmt.Errorf("Cannot open config file: %v", err)
+	}
+	defer file.Close()
+	config := postgres.Configuration{}
+	err = json.NewDecoder(file).Decode(&config)
+	if err != nil {
+		return postgres.Configuration{}, fmt.Errorf("Cannot read config file: %v", err)
+	}
+	if secret := os.Getenv("GAME_TRACKER_TOKEN_SECRET"); secret != "" {
+		config.Tokens.Secret = secret
+	}
+	return config, nil
+}
+
+// Opens everything the config asks for and builds the engine, jobs only
+// run once StartJobs is called. Close has to be called when done.
+func Build(config postgres.Configuration) (*App, error) {
+	if config.Tokens.Secret == "" {
+		return nil, fmt.Errorf("No token secret, set Tokens.Secret or GAME_TRACKER_TOKEN_SECRET")
+	}
+	if config.Diagnostics.Address != "" && !loopback(config.Diagnostics.Address) {
+		return nil, fmt.Errorf("Diagnostics.Address '%s' is not a loopback address",
+			config.Diagnostics.Address)
+	}
+	repos, err := OpenRepositories(config)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot open database: %v", err)
+	}
+	caches, err := OpenCaches(config.Redis)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot open cache: %v", err)
+	}
+	services, err := OpenServices(config, repos)
+	if err != nil {
+		return nil, err
+	}
+	if repos.Database != nil {
+		repos.Database.OnFailover(func(from, to int, cause error) {
+			fmt.Printf("Database failed over from DSN %d to %d: %v\n", from, to, cause)
+			services.EventBus.Publish(domain.Event{Name: domain.EventDatabaseFailover,
+				Payload: map[string]string{"from": strconv.Itoa(from), "to": strconv.Itoa(to),
+					"cause": cause.Error()}})
+		})
+	}
+
+	interactors := NewInteractors(config, repos, caches, services)
+	handler, err := NewHandler(config, interactors, services, caches)
+	if err != nil {
+		services.Plugins.Close()
+		return nil, err
+	}
+	site := web.Site{
+		ProfileInteractor: &interactors.Profile,
+		StatsInteractor:   &interactors.Stats,
+		Sessions:          handler.Sessions,
+		SessionTtl:        config.Redis.Sessions.Ttl,
+	}
+
+	var diagnostics *gin.Engine
+	if config.Diagnostics.Address != "" {
+		diagnostics = routes.CreateDiagnosticsEngine(handler)
+	}
+
+	return &App{
+		Config:       config,
+		Repositories: repos,
+		Caches:       caches,
+		Services:     services,
+		Interactors:  interactors,
+		Handler:      handler,
+		Site:         site,
+		Engine:       routes.CreateEngine(handler, site, repos.Idempotency, caches.RateLimit, config),
+		Diagnostics:  diagnostics,
+	}, nil
+}
+
+// Hands the interactors to the web handlers, the error reporter is only
+// set up when a Sentry DSN is configured
+func NewHandler(config postgres.Configuration, interactors Interactors, services Services,
+	caches Caches) (interfaces.WebserviceHandler, error) {
+	handler := interfaces.WebserviceHandler{}
+	handler.ProfileInteractor = &interactors.Profile
+	handler.NotificationInteractor = &interactors.Notification
+	handler.SettingsInteractor = &interactors.Settings
+	handler.SyncInteractor = &interactors.Sync
+	handler.AdminInteractor = &interactors.Admin
+	handler.ActivityInteractor = &interactors.Activity
+	handler.CalendarInteractor = &interactors.Calendar
+	handler.FranchiseInteractor = &interactors.Franchise
+	handler.ParentalInteractor = &interactors.Parental
+	handler.PersonalInteractor = &interactors.Personal
+	handler.JournalInteractor = &interactors.Journal
+	handler.ExportInteractor = &interactors.Export
+	handler.SharingInteractor = &interactors.Sharing
+	handler.BadgeInteractor = &interactors.Badge
+	handler.GoalInteractor = &interactors.Goal
+	handler.HardwareInteractor = &interactors.Hardware
+	handler.SubscriptionInteractor = &interactors.Subscription
+	handler.CatalogInteractor = &interactors.Catalog
+	handler.SpeedrunInteractor = &interactors.Speedrun
+	handler.MatchInteractor = &interactors.Match
+	handler.AgentInteractor = &interactors.Agent
+	handler.WebhookInteractor = &interactors.Webhook
+	handler.RuleInteractor = &interactors.Rule
+	handler.ScriptInteractor = &interactors.Script
+	handler.SearchInteractor = &interactors.Search
+	handler.StatsInteractor = &interactors.Stats
+	handler.RenderInteractor = &usecases.RenderInteractor{Renderer: services.Renderer}
+	handler.DiagnosticsInteractor = &interactors.Diagnostics
+	handler.ChangeStreamInteractor = interactors.ChangeStream
+	handler.LibraryEventsInteractor = &interactors.LibraryEvents
+	handler.UndoInteractor = &interactors.Undo
+	handler.TrashInteractor = &interactors.Trash
+	handler.IntegrityInteractor = &interactors.Integrity
+	handler.Translator = services.Translator
+	handler.Sessions = interfaces.NewCacheSessionStore(caches.Sessions)
+	handler.TokenSecret = []byte(config.Tokens.Secret)
+	handler.Maintenance = interfaces.NewMaintenance(interfaces.MaintenanceStatus{
+		Enabled:    config.Maintenance.Enabled,
+		RetryAfter: config.Maintenance.RetryAfter,
+		Reason:     config.Maintenance.Reason,
+	})
+
+	if config.Errors.SentryDsn != "" {
+		reporter, err := infrastructure.NewSentryReporter(config.Errors.SentryDsn,
+			config.Errors.Environment)
+		if err != nil {
+			return interfaces.WebserviceHandler{}, fmt.Errorf("Cannot enable error reporting: %v", err)
+		}
+		handler.ErrorReporter = reporter
+	}
+	return handler, nil
+}
+
+// Serves the diagnostics port in its own goroutine, if configured
+func (app *App) StartDiagnostics() {
+	if app.Diagnostics == nil {
+		return
+	}
+	go func() {
+		err := app.Diagnostics.Run(app.Config.Diagnostics.Address)
+		if err != nil {
+			fmt.Println("Cannot serve diagnostics", err)
+		}
+	}()
+}
+
+// The diagnostics port serves pprof, so it only listens on this host.
+// ":6060" would listen on every interface.
+func loopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (app *App) Close() error {
+	if app.Services.Bus != nil {
+		app.Services.Bus.Close()
+	}
+	return app.Services.Plugins.Close()
+}
@@ -0,0 +1,361 @@
+package bootstrap
+
+import (
+	"time"
+
+	"game-tracker/infrastructure"
+	"game-tracker/interfaces"
+	"game-tracker/models/postgres"
+	"game-tracker/usecases"
+)
+
+type Interactors struct {
+	Profile       usecases.ProfileInteractor
+	Notification  usecases.NotificationInteractor
+	Settings      usecases.SettingsInteractor
+	Activity      usecases.ActivityInteractor
+	Calendar      usecases.CalendarInteractor
+	Parental      usecases.ParentalInteractor
+	Personal      usecases.PersonalInteractor
+	Journal       usecases.JournalInteractor
+	Export        usecases.ExportInteractor
+	Franchise     usecases.FranchiseInteractor
+	Sharing       usecases.SharingInteractor
+	Badge         usecases.BadgeInteractor
+	Goal          usecases.GoalInteractor
+	Hardware      usecases.HardwareInteractor
+	Subscription  usecases.SubscriptionInteractor
+	Catalog       usecases.CatalogInteractor
+	Speedrun      usecases.SpeedrunInteractor
+	Match         usecases.MatchInteractor
+	Agent         usecases.AgentInteractor
+	Webhook       usecases.WebhookInteractor
+	Rule          usecases.RuleInteractor
+	Sync          usecases.SyncInteractor
+	Admin         usecases.AdminInteractor
+	Script        usecases.ScriptInteractor
+	Search        usecases.SearchInteractor
+	Stats         usecases.StatsInteractor
+	Diagnostics   usecases.DiagnosticsInteractor
+	LibraryEvents usecases.LibraryEventsInteractor
+	Undo          usecases.UndoInteractor
+	Trash         usecases.TrashInteractor
+	Integrity     usecases.IntegrityInteractor
+	ChangeStream  *usecases.ChangeStreamInteractor
+	Messaging     *usecases.MessagingInteractor //Nil unless Messaging.Driver is set
+}
+
+// Builds every interactor and subscribes the ones that listen for events to
+// the event bus of services
+func NewInteractors(config postgres.Configuration, repos Repositories, caches Caches,
+	services Services) Interactors {
+	var interactors Interactors
+	games := interfaces.NewCachedGameRepo(repos.Games, caches.Cache)
+	var workQueue usecases.WorkQueue
+	if services.Bus != nil {
+		interactors.Messaging = &usecases.MessagingInteractor{Bus: services.Bus,
+			Prefix: config.Messaging.Prefix}
+		workQueue = interactors.Messaging
+		if config.Messaging.ForwardEvents {
+			interactors.Messaging.ForwardEvents(services.EventBus)
+		}
+	}
+	interactors.Profile = usecases.ProfileInteractor{
+		UserRepository:          repos.Users,
+		GameRepository:          games,
+		LibraryRepository:       repos.Libraries,
+		SettingsRepository:      repos.Settings,
+		EventBus:                services.EventBus,
+		NamePolicy:              services.NamePolicy,
+		Flags:                   services.Flags,
+		Parental:                services.Parental,
+		MetadataProvider:        services.Metadata,
+		Steam:                   services.Steam,
+		SteamRepository:         repos.Steam,
+		Barcodes:                services.Barcodes,
+		PhysicalCopyRepository:  repos.Copies,
+		Vision:                  services.Vision,
+		PhotoImportRepository:   repos.Photos,
+		BlobStore:               services.Blobs,
+		LibraryMemberRepository: repos.Members,
+		TradeRepository:         repos.Trades,
+		AddonRepository:         repos.Addons,
+		ModRepository:           repos.Mods,
+		SaveBackupRepository:    repos.Backups,
+		Pricing:                 services.Pricing,
+		Templates:               services.Templates,
+		Printer:                 infrastructure.NewPdfRenderer(),
+		WorkQueue:               workQueue,
+	}
+	if services.Reporter != nil {
+		interactors.Profile.Reporter = services.Reporter
+	}
+	if config.Undo.Window > 0 {
+		interactors.Profile.UndoRepository = repos.Undo
+		interactors.Profile.UndoWindow = time.Duration(config.Undo.Window) * time.Second
+	}
+	if config.Trash.RetainDays > 0 {
+		interactors.Profile.TrashRepository = repos.Trash
+	}
+	if interactors.Messaging != nil {
+		interactors.Messaging.Profile = &interactors.Profile
+	}
+
+	interactors.Notification = usecases.NotificationInteractor{
+		NotificationRepository: repos.Notifications,
+		UserRepository:         repos.Users,
+		SettingsRepository:     repos.Settings,
+		Translator:             services.Translator,
+		Channels:               services.Plugins.NotificationChannels(),
+	}
+	interactors.Notification.Subscribe(services.EventBus)
+
+	interactors.Settings = usecases.SettingsInteractor{
+		SettingsRepository: repos.Settings,
+		UserRepository:     repos.Users,
+		LibraryRepository:  repos.Libraries,
+		Translator:         services.Translator,
+	}
+	interactors.Settings.Subscribe(services.EventBus)
+
+	interactors.Activity = usecases.ActivityInteractor{
+		ActivityRepository:    repos.Activities,
+		UserRepository:        repos.Users,
+		GameRepository:        repos.Games,
+		SettingsRepository:    repos.Settings,
+		PlaySessionRepository: repos.Sessions,
+	}
+	interactors.Activity.Subscribe(services.EventBus)
+
+	interactors.Calendar = usecases.CalendarInteractor{
+		PlaySessionRepository:   repos.Sessions,
+		ReleaseRepository:       repos.Releases,
+		CalendarTokenRepository: repos.Calendars,
+		UserRepository:          repos.Users,
+		LibraryRepository:       repos.Libraries,
+		GameRepository:          repos.Games,
+		SettingsRepository:      repos.Settings,
+		MetadataProvider:        services.Metadata,
+		EventBus:                services.EventBus,
+		Parental:                services.Parental,
+	}
+	interactors.Calendar.Subscribe(services.EventBus)
+
+	interactors.Parental = usecases.ParentalInteractor{
+		ChildAccountRepository: repos.Children,
+		UserRepository:         repos.Users,
+		PlaySessionRepository:  repos.Sessions,
+		SettingsRepository:     repos.Settings,
+	}
+	interactors.Parental.Subscribe(services.EventBus)
+
+	interactors.Personal = usecases.PersonalInteractor{
+		PersonalMetadataRepository: repos.Personal,
+		UserRepository:             repos.Users,
+		LibraryRepository:          repos.Libraries,
+		GameRepository:             repos.Games,
+		Parental:                   services.Parental,
+	}
+	interactors.Personal.Subscribe(services.EventBus)
+
+	interactors.Journal = usecases.JournalInteractor{
+		JournalRepository: repos.Journal,
+		UserRepository:    repos.Users,
+		LibraryRepository: repos.Libraries,
+		GameRepository:    repos.Games,
+		BlobStore:         services.Blobs,
+		Renderer:          services.Renderer,
+	}
+	interactors.Journal.Subscribe(services.EventBus)
+
+	interactors.Export = usecases.ExportInteractor{
+		UserRepository:    repos.Users,
+		LibraryRepository: repos.Libraries,
+		GameRepository:    repos.Games,
+		JournalRepository: repos.Journal,
+		ModRepository:     repos.Mods,
+		Renderer:          services.Renderer,
+	}
+
+	interactors.Franchise = usecases.FranchiseInteractor{
+		FranchiseRepository: repos.Franchises,
+		GameRepository:      repos.Games,
+		UserRepository:      repos.Users,
+	}
+
+	interactors.Sharing = usecases.SharingInteractor{
+		ShareLinkRepository: repos.Shares,
+		UserRepository:      repos.Users,
+		LibraryRepository:   repos.Libraries,
+		GameRepository:      repos.Games,
+		Failures:            caches.RateLimit,
+	}
+	interactors.Sharing.Subscribe(services.EventBus)
+
+	interactors.Badge = usecases.BadgeInteractor{
+		BadgeRepository:        repos.Badges,
+		UserRepository:         repos.Users,
+		GameRepository:         repos.Games,
+		PlaySessionRepository:  repos.Sessions,
+		PhysicalCopyRepository: repos.Copies,
+		SettingsRepository:     repos.Settings,
+		EventBus:               services.EventBus,
+	}
+	interactors.Badge.Subscribe(services.EventBus)
+
+	interactors.Goal = usecases.GoalInteractor{
+		GoalRepository:        repos.Goals,
+		UserRepository:        repos.Users,
+		GameRepository:        repos.Games,
+		ActivityRepository:    repos.Activities,
+		PlaySessionRepository: repos.Sessions,
+		SettingsRepository:    repos.Settings,
+		EventBus:              services.EventBus,
+	}
+	interactors.Goal.Subscribe(services.EventBus)
+
+	interactors.Hardware = usecases.HardwareInteractor{
+		HardwareRepository: repos.Hardware,
+		UserRepository:     repos.Users,
+		SettingsRepository: repos.Settings,
+		EventBus:           services.EventBus,
+	}
+	interactors.Hardware.Subscribe(services.EventBus)
+
+	interactors.Subscription = usecases.SubscriptionInteractor{
+		SubscriptionRepository: repos.Subscriptions,
+		UserRepository:         repos.Users,
+		LibraryRepository:      repos.Libraries,
+		GameRepository:         interactors.Profile.GameRepository,
+		PlaySessionRepository:  repos.Sessions,
+		SettingsRepository:     repos.Settings,
+		EventBus:               services.EventBus,
+	}
+	interactors.Subscription.Subscribe(services.EventBus)
+
+	interactors.Catalog = usecases.CatalogInteractor{
+		CatalogRepository:      repos.Catalogs,
+		Catalogs:               services.Catalogs,
+		Services:               config.Catalogs.Services,
+		SubscriptionRepository: repos.Subscriptions,
+		UserRepository:         repos.Users,
+		GameRepository:         interactors.Profile.GameRepository,
+		EventBus:               services.EventBus,
+	}
+	interactors.Catalog.Subscribe(services.EventBus)
+
+	interactors.Speedrun = usecases.SpeedrunInteractor{
+		SpeedrunRepository:    repos.Speedruns,
+		UserRepository:        repos.Users,
+		GameRepository:        interactors.Profile.GameRepository,
+		PlaySessionRepository: repos.Sessions,
+		SettingsRepository:    repos.Settings,
+		EventBus:              services.EventBus,
+	}
+	interactors.Speedrun.Subscribe(services.EventBus)
+
+	interactors.Match = usecases.MatchInteractor{
+		MatchRepository: repos.Matches,
+		UserRepository:  repos.Users,
+		GameRepository:  interactors.Profile.GameRepository,
+	}
+	interactors.Match.Subscribe(services.EventBus)
+
+	interactors.Agent = usecases.AgentInteractor{
+		AgentRepository:      repos.Agents,
+		ExecutableRepository: repos.Executables,
+		Calendar:             interactors.Calendar,
+	}
+	interactors.Agent.Subscribe(services.EventBus)
+
+	interactors.Webhook = usecases.WebhookInteractor{
+		WebhookRepository: repos.Webhooks,
+		UserRepository:    repos.Users,
+		LibraryRepository: repos.Libraries,
+		Profile:           interactors.Profile,
+		EventBus:          services.EventBus,
+	}
+	interactors.Webhook.Subscribe(services.EventBus)
+
+	interactors.Rule = usecases.RuleInteractor{
+		RuleRepository:        repos.Rules,
+		UserRepository:        repos.Users,
+		GameRepository:        interactors.Profile.GameRepository,
+		PlaySessionRepository: repos.Sessions,
+		Profile:               interactors.Profile,
+		EventBus:              services.EventBus,
+	}
+	interactors.Rule.Subscribe(services.EventBus)
+
+	interactors.Sync = usecases.SyncInteractor{
+		ChangeRepository:   repos.Changes,
+		UserRepository:     repos.Users,
+		SettingsRepository: repos.Settings,
+	}
+	interactors.ChangeStream = usecases.NewChangeStreamInteractor(services.Changes, games,
+		repos.Users, repos.Settings)
+
+	interactors.Admin = usecases.AdminInteractor{
+		AdminRepository: repos.Admin,
+		UserRepository:  repos.Users,
+		GameRepository:  interactors.Profile.GameRepository, //Flagging spoilers clears the cached game
+		Flags:           services.Flags,
+	}
+
+	interactors.Script = usecases.ScriptInteractor{
+		ScriptRepository: repos.Scripts,
+		AdminRepository:  repos.Admin,
+		Admin:            interactors.Admin,
+		Rules:            interactors.Rule,
+	}
+	if config.Scripts.Enabled {
+		interactors.Script.Engine = infrastructure.NewStarlarkEngine()
+	}
+	interactors.Script.Subscribe(services.EventBus)
+
+	interactors.Search = usecases.SearchInteractor{
+		SavedSearchRepository: repos.Searches,
+		UserRepository:        repos.Users,
+		LibraryRepository:     repos.Libraries,
+		Profile:               interactors.Profile,
+	}
+	interactors.Search.Subscribe(services.EventBus)
+
+	interactors.Stats = usecases.StatsInteractor{
+		StatsRepository: repos.Stats,
+		UserRepository:  repos.Users,
+	}
+
+	interactors.LibraryEvents = usecases.LibraryEventsInteractor{
+		Events:           repos.LibraryEvents,
+		ChangeRepository: repos.Changes,
+		Profile:          interactors.Profile,
+		Admin:            interactors.Admin,
+	}
+
+	interactors.Undo = usecases.UndoInteractor{
+		UndoRepository: interactors.Profile.UndoRepository,
+		GameRepository: interactors.Profile.GameRepository,
+		Profile:        interactors.Profile,
+		Window:         interactors.Profile.UndoWindow,
+	}
+
+	interactors.Trash = usecases.TrashInteractor{
+		TrashRepository: interactors.Profile.TrashRepository,
+		Profile:         interactors.Profile,
+		Retention:       time.Duration(config.Trash.RetainDays) * 24 * time.Hour,
+	}
+
+	interactors.Integrity = usecases.IntegrityInteractor{
+		IntegrityRepository: repos.Integrity,
+		Admin:               interactors.Admin,
+	}
+
+	interactors.Diagnostics = usecases.DiagnosticsInteractor{
+		Admin:     interactors.Admin,
+		Pool:      repos.Pool,
+		Caches:    caches.sized(),
+		StartedAt: time.Now().UTC(),
+	}
+	return interactors
+}
@@ -0,0 +1,315 @@
+package bootstrap
+
+import (
+	"fmt"
+	"time"
+
+	"game-tracker/infrastructure"
+	"game-tracker/interfaces"
+	"game-tracker/usecases"
+)
+
+// Starts the jobs the config gives an interval for and the telemetry
+// reporter, each in its own goroutine
+func (app *App) StartJobs() {
+	runs := jobRuns{maintenance: app.Handler.Maintenance, locks: app.jobLocks()}
+	if app.Services.Changes != nil {
+		go app.Interactors.ChangeStream.Run()
+	}
+	if app.Services.Reporter != nil {
+		go app.Services.Reporter.Run()
+	}
+	if app.Config.Releases.Interval > 0 {
+		go runReleaseJob(app.Interactors.Calendar, runs,
+			time.Duration(app.Config.Releases.Interval)*time.Second)
+	}
+	if app.Services.Vision != nil && app.Config.Vision.Interval > 0 {
+		go runPhotoImportJob(app.Interactors.Profile, runs,
+			time.Duration(app.Config.Vision.Interval)*time.Second)
+	}
+	if app.Config.Goals.Interval > 0 {
+		go runGoalJob(app.Interactors.Goal, runs,
+			time.Duration(app.Config.Goals.Interval)*time.Second)
+	}
+	if app.Config.Hardware.Interval > 0 {
+		go runHardwareJob(app.Interactors.Hardware, runs,
+			time.Duration(app.Config.Hardware.Interval)*time.Second,
+			time.Duration(app.Config.Hardware.ReminderDays)*24*time.Hour)
+	}
+	if app.Config.Subscriptions.Interval > 0 {
+		go runSubscriptionJob(app.Interactors.Subscription, runs,
+			time.Duration(app.Config.Subscriptions.Interval)*time.Second,
+			time.Duration(app.Config.Subscriptions.ReminderDays)*24*time.Hour)
+	}
+	if app.Services.Catalogs != nil && app.Config.Catalogs.Interval > 0 {
+		go runCatalogJob(app.Interactors.Catalog, runs,
+			time.Duration(app.Config.Catalogs.Interval)*time.Second)
+	}
+	if app.Config.Backups.Interval > 0 && app.Config.Backups.MaxAge > 0 {
+		go runBackupJob(app.Interactors.Profile, runs,
+			time.Duration(app.Config.Backups.Interval)*time.Second,
+			time.Duration(app.Config.Backups.MaxAge)*24*time.Hour)
+	}
+	if app.Config.PlaySessions.Interval > 0 {
+		go runPlaySessionJob(app.Interactors.Calendar, runs,
+			time.Duration(app.Config.PlaySessions.Interval)*time.Second,
+			app.Config.PlaySessions.MonthsAhead, app.Config.PlaySessions.RetainMonths)
+	}
+	if app.Config.Stats.Interval > 0 {
+		go runStatsJob(app.Interactors.Stats, runs,
+			time.Duration(app.Config.Stats.Interval)*time.Second)
+	}
+	if app.Config.Trash.Interval > 0 && app.Config.Trash.RetainDays > 0 {
+		go runTrashJob(app.Interactors.Trash, runs,
+			time.Duration(app.Config.Trash.Interval)*time.Second)
+	}
+	if app.Services.Pricing != nil && app.Config.Pricing.Interval > 0 {
+		go runPricingJob(app.Interactors.Profile, runs,
+			time.Duration(app.Config.Pricing.Interval)*time.Second)
+	}
+}
+
+// Checks tracked releases every interval, it never returns so run it in
+// its own goroutine
+func runReleaseJob(interactor usecases.CalendarInteractor, runs jobRuns,
+	interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		release, claimed := runs.claim("releases", interval)
+		if !claimed {
+			continue
+		}
+		err := interactor.CheckReleases()
+		release()
+		if err != nil {
+			fmt.Printf("Cannot check releases: %s\n", err)
+		}
+	}
+}
+
+// Reads the photos of pending photo imports every interval, it never
+// returns so run it in its own goroutine
+func runPhotoImportJob(interactor usecases.ProfileInteractor, runs jobRuns,
+	interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		release, claimed := runs.claim("photoImports", interval)
+		if !claimed {
+			continue
+		}
+		err := interactor.ProcessPhotoImports()
+		release()
+		if err != nil {
+			fmt.Printf("Cannot process photo imports: %s\n", err)
+		}
+	}
+}
+
+// Refreshes the estimated values of physical copies every interval, nightly
+// by default. It never returns so run it in its own goroutine.
+func runPricingJob(interactor usecases.ProfileInteractor, runs jobRuns,
+	interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		release, claimed := runs.claim("pricing", interval)
+		if !claimed {
+			continue
+		}
+		err := interactor.RefreshPrices(interval)
+		release()
+		if err != nil {
+			fmt.Printf("Cannot refresh prices: %s\n", err)
+		}
+	}
+}
+
+// Purges the trash of items past its retention every interval, nightly by
+// default. It never returns so run it in its own goroutine.
+func runTrashJob(interactor usecases.TrashInteractor, runs jobRuns, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		release, claimed := runs.claim("trash", interval)
+		if !claimed {
+			continue
+		}
+		err := interactor.PurgeTrash()
+		release()
+		if err != nil {
+			fmt.Printf("Cannot purge the trash: %s\n", err)
+		}
+	}
+}
+
+// Reminds users of goals they fall behind on every interval, it never
+// returns so run it in its own goroutine
+func runGoalJob(interactor usecases.GoalInteractor, runs jobRuns,
+	interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		release, claimed := runs.claim("goals", interval)
+		if !claimed {
+			continue
+		}
+		err := interactor.RemindGoals()
+		release()
+		if err != nil {
+			fmt.Printf("Cannot remind goals: %s\n", err)
+		}
+	}
+}
+
+// Reminds owners of warranties ending within lead every interval, it never
+// returns so run it in its own goroutine
+func runHardwareJob(interactor usecases.HardwareInteractor, runs jobRuns,
+	interval, lead time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		release, claimed := runs.claim("hardware", interval)
+		if !claimed {
+			continue
+		}
+		err := interactor.RemindWarranties(lead)
+		release()
+		if err != nil {
+			fmt.Printf("Cannot remind warranties: %s\n", err)
+		}
+	}
+}
+
+// Moves renewal dates on and reminds owners of renewals within lead every
+// interval, it never returns so run it in its own goroutine
+func runSubscriptionJob(interactor usecases.SubscriptionInteractor, runs jobRuns,
+	interval, lead time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		release, claimed := runs.claim("subscriptions", interval)
+		if !claimed {
+			continue
+		}
+		err := interactor.RemindRenewals(lead)
+		release()
+		if err != nil {
+			fmt.Printf("Cannot remind subscription renewals: %s\n", err)
+		}
+	}
+}
+
+// Fetches the subscription catalogs every interval, it never returns so run
+// it in its own goroutine
+func runCatalogJob(interactor usecases.CatalogInteractor, runs jobRuns,
+	interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		release, claimed := runs.claim("catalogs", interval)
+		if !claimed {
+			continue
+		}
+		err := interactor.RefreshCatalogs()
+		release()
+		if err != nil {
+			fmt.Printf("Cannot refresh catalogs: %s\n", err)
+		}
+	}
+}
+
+// Reminds users of games whose latest save backup is older than maxAge
+// every interval, it never returns so run it in its own goroutine
+func runBackupJob(interactor usecases.ProfileInteractor, runs jobRuns,
+	interval, maxAge time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		release, claimed := runs.claim("backups", interval)
+		if !claimed {
+			continue
+		}
+		err := interactor.RemindBackups(maxAge)
+		release()
+		if err != nil {
+			fmt.Printf("Cannot remind save backups: %s\n", err)
+		}
+	}
+}
+
+// Readies the play session months ahead and archives the ones past retain
+// every interval, nightly by default. It never returns so run it in its own
+// goroutine.
+func runPlaySessionJob(interactor usecases.CalendarInteractor, runs jobRuns,
+	interval time.Duration, ahead, retain int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		release, claimed := runs.claim("playSessions", interval)
+		if !claimed {
+			continue
+		}
+		err := interactor.MaintainSessions(ahead, retain)
+		release()
+		if err != nil {
+			fmt.Printf("Cannot maintain play sessions: %s\n", err)
+		}
+	}
+}
+
+// Refreshes the totals of the stats pages every interval, it never returns
+// so run it in its own goroutine
+func runStatsJob(interactor usecases.StatsInteractor, runs jobRuns,
+	interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		release, claimed := runs.claim("stats", interval)
+		if !claimed {
+			continue
+		}
+		err := interactor.RefreshStats()
+		release()
+		if err != nil {
+			fmt.Printf("Cannot refresh stats: %s\n", err)
+		}
+	}
+}
+
+// Postgres deployments claim job runs with advisory locks, MongoDB ones
+// with Redis when it is configured. Without either jobs run on every
+// instance, which only suits a single one.
+func (app *App) jobLocks() usecases.JobLocks {
+	if app.Repositories.Database != nil {
+		return infrastructure.NewPostgresqlJobLocks(app.Repositories.Database,
+			infrastructure.JobInstance())
+	}
+	if app.Caches.Redis != nil {
+		return infrastructure.NewRedisJobLocks(app.Caches.Redis, app.Config.Redis.JobPrefix,
+			infrastructure.JobInstance())
+	}
+	return nil
+}
+
+// What each job waits for before a run: the end of maintenance and its
+// turn among the instances
+type jobRuns struct {
+	maintenance *interfaces.Maintenance
+	locks       usecases.JobLocks //Nil runs every job on every instance
+}
+
+// False when another instance has the run, release ends it otherwise
+func (runs jobRuns) claim(job string, interval time.Duration) (func(), bool) {
+	runs.maintenance.Wait()
+	if runs.locks == nil {
+		return func() {}, true
+	}
+	release, claimed, err := runs.locks.Claim(job, interval)
+	if err != nil {
+		fmt.Printf("Cannot claim job %s: %s\n", job, err)
+		return nil, false
+	}
+	return release, claimed
+}
@@ -0,0 +1,31 @@
+package bootstrap
+
+import (
+	"game-tracker/infrastructure"
+	"game-tracker/models/postgres"
+	"game-tracker/usecases"
+)
+
+// Builds the policy usernames and player names are checked against, the
+// word lists are read once here
+func namePolicy(config postgres.Names) (usecases.NamePolicy, error) {
+	policies := usecases.NamePolicies{
+		usecases.LengthPolicy{Min: config.MinLength, Max: config.MaxLength},
+		usecases.CharsetPolicy{},
+	}
+	if config.ReservedWords != "" {
+		words, err := infrastructure.LoadWordList(config.ReservedWords)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, usecases.NewReservedPolicy(words))
+	}
+	if config.Profanity != "" {
+		words, err := infrastructure.LoadWordList(config.Profanity)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, usecases.NewProfanityPolicy(words))
+	}
+	return policies, nil
+}
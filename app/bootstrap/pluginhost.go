@@ -0,0 +1,32 @@
+package bootstrap
+
+import (
+	"game-tracker/infrastructure"
+	"game-tracker/models/postgres"
+	"game-tracker/plugins"
+)
+
+// Opens the plugins enabled in the config. The provider URLs of the older
+// Releases, Steam and Pricing sections turn on the matching built-in plugin
+// unless Plugins configures it.
+func openPlugins(config postgres.Configuration) (*plugins.Host, error) {
+	infrastructure.RegisterPlugins()
+
+	configs := make(map[string]plugins.Config)
+	if config.Releases.ProviderUrl != "" {
+		configs["http-metadata"] = plugins.Config{Enabled: true,
+			Settings: map[string]interface{}{"url": config.Releases.ProviderUrl}}
+	}
+	if config.Steam.ApiUrl != "" {
+		configs["steam"] = plugins.Config{Enabled: true, Settings: map[string]interface{}{
+			"apiUrl": config.Steam.ApiUrl, "storeUrl": config.Steam.StoreUrl}}
+	}
+	if config.Pricing.ProviderUrl != "" {
+		configs["http-pricing"] = plugins.Config{Enabled: true,
+			Settings: map[string]interface{}{"url": config.Pricing.ProviderUrl}}
+	}
+	for name, plugin := range config.Plugins {
+		configs[name] = plugins.Config{Enabled: plugin.Enabled, Settings: plugin.Settings}
+	}
+	return plugins.Open(configs)
+}
@@ -0,0 +1,117 @@
+package bootstrap
+
+import (
+	"fmt"
+
+	"game-tracker/domain"
+	"game-tracker/infrastructure"
+	"game-tracker/models/postgres"
+	"game-tracker/plugins"
+	"game-tracker/usecases"
+)
+
+// Everything the interactors depend on besides the repositories. Optional
+// providers stay nil when the config leaves them out.
+type Services struct {
+	EventBus   domain.EventBus
+	NamePolicy usecases.NamePolicy
+	Flags      *usecases.FlagService
+	Parental   *usecases.ParentalControls
+	Blobs      *infrastructure.FileBlobStore
+	Renderer   *infrastructure.MarkdownRenderer
+	Plugins    *plugins.Host
+	Metadata   usecases.MetadataProvider
+	Steam      usecases.SteamStore
+	Pricing    usecases.PricingProvider
+	Barcodes   usecases.BarcodeProvider
+	Catalogs   usecases.CatalogProvider
+	Translator usecases.Translator
+	Templates  *infrastructure.DocumentTemplates
+	Vision     usecases.VisionProvider
+	Reporter   *infrastructure.HttpReporter //Nil unless telemetry is opted into
+	Changes    usecases.ChangeStream        //Nil unless ChangeStream is enabled on Postgres
+	Bus        usecases.MessageBus          //Nil unless Messaging.Driver is set
+}
+
+// Builds the services from the config. The plugin host is left open for
+// the caller to close once the services are no longer used.
+func OpenServices(config postgres.Configuration, repos Repositories) (Services, error) {
+	policy, err := namePolicy(config.Names)
+	if err != nil {
+		return Services{}, fmt.Errorf("Cannot load name policy: %v", err)
+	}
+
+	services := Services{
+		EventBus:   infrastructure.NewInMemoryEventBus(),
+		NamePolicy: policy,
+		Flags:      usecases.NewFlagService(repos.Flags),
+		Parental:   usecases.NewParentalControls(repos.Children, repos.Sessions, repos.Settings),
+		Renderer:   infrastructure.NewMarkdownRenderer(),
+	}
+
+	services.Blobs, err = infrastructure.NewFileBlobStore(config.Blobs.Dir)
+	if err != nil {
+		return Services{}, fmt.Errorf("Cannot open blob store: %v", err)
+	}
+
+	if config.Barcodes.ProviderUrl != "" {
+		services.Barcodes = infrastructure.NewHttpBarcodeProvider(config.Barcodes.ProviderUrl)
+	}
+	if config.Catalogs.ProviderUrl != "" {
+		services.Catalogs = infrastructure.NewHttpCatalogProvider(config.Catalogs.ProviderUrl)
+	}
+	if config.Vision.ProviderUrl != "" {
+		services.Vision = infrastructure.NewHttpVisionProvider(config.Vision.ProviderUrl)
+	}
+
+	if config.ChangeStream.Enabled && repos.Database != nil {
+		services.Changes = infrastructure.NewPostgresqlChangeStream(config.PostgresAdr,
+			config.PostgresFailover...)
+	}
+
+	services.Bus, err = messageBus(config.Messaging)
+	if err != nil {
+		return Services{}, fmt.Errorf("Cannot connect to message bus: %v", err)
+	}
+
+	if config.Telemetry.Enabled {
+		services.Reporter, err = usageReporter(config.Telemetry)
+		if err != nil {
+			return Services{}, fmt.Errorf("Cannot enable telemetry: %v", err)
+		}
+	}
+
+	if config.Locales.Dir != "" {
+		services.Translator, err = infrastructure.LoadMessageCatalogs(config.Locales.Dir)
+		if err != nil {
+			return Services{}, fmt.Errorf("Cannot load message catalogs: %v", err)
+		}
+	}
+	services.Templates, err = infrastructure.LoadDocumentTemplates(config.Documents.Templates,
+		services.Translator)
+	if err != nil {
+		return Services{}, fmt.Errorf("Cannot load document templates: %v", err)
+	}
+
+	// Opened last so nothing above can fail with the host left open
+	services.Plugins, err = openPlugins(config)
+	if err != nil {
+		return Services{}, fmt.Errorf("Cannot open plugins: %v", err)
+	}
+	services.Metadata = services.Plugins.Metadata()
+	services.Steam = services.Plugins.LibrarySync()
+	services.Pricing = services.Plugins.Pricing()
+	return services, nil
+}
+
+func messageBus(config postgres.Messaging) (usecases.MessageBus, error) {
+	switch config.Driver {
+	case "":
+		return nil, nil
+	case "nats":
+		return infrastructure.NewNatsBus(config.Url)
+	case "rabbitmq":
+		return infrastructure.NewRabbitmqBus(config.Url)
+	}
+	return nil, fmt.Errorf("Unknown driver '%s'", config.Driver)
+}
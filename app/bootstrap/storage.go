@@ -0,0 +1,375 @@
+package bootstrap
+
+import (
+	"fmt"
+	"time"
+
+	"game-tracker/infrastructure"
+	"game-tracker/interfaces"
+	"game-tracker/middlewares/idempotency"
+	"game-tracker/models/postgres"
+	"game-tracker/usecases"
+)
+
+// One repository per usecases interface, all on the same backend
+type Repositories struct {
+	Users         usecases.UserRepository
+	Libraries     usecases.LibraryRepository
+	Games         usecases.GameRepository
+	Settings      usecases.SettingsRepository
+	Notifications usecases.NotificationRepository
+	Changes       usecases.ChangeRepository
+	Admin         usecases.AdminRepository
+	Flags         usecases.FlagRepository
+	Activities    usecases.ActivityRepository
+	Sessions      usecases.PlaySessionRepository
+	Releases      usecases.ReleaseRepository
+	Calendars     usecases.CalendarTokenRepository
+	Franchises    usecases.FranchiseRepository
+	Children      usecases.ChildAccountRepository
+	Personal      usecases.PersonalMetadataRepository
+	Journal       usecases.JournalRepository
+	Steam         usecases.SteamRepository
+	Copies        usecases.PhysicalCopyRepository
+	Photos        usecases.PhotoImportRepository
+	Shares        usecases.ShareLinkRepository
+	Members       usecases.LibraryMemberRepository
+	Trades        usecases.TradeRepository
+	Badges        usecases.BadgeRepository
+	Goals         usecases.GoalRepository
+	Hardware      usecases.HardwareRepository
+	Subscriptions usecases.SubscriptionRepository
+	Catalogs      usecases.CatalogRepository
+	Addons        usecases.AddonRepository
+	Mods          usecases.ModRepository
+	Backups       usecases.SaveBackupRepository
+	Speedruns     usecases.SpeedrunRepository
+	Matches       usecases.MatchRepository
+	Agents        usecases.AgentRepository
+	Executables   usecases.ExecutableRepository
+	Webhooks      usecases.WebhookRepository
+	Rules         usecases.RuleRepository
+	Scripts       usecases.ScriptRepository
+	Searches      usecases.SavedSearchRepository
+	Stats         usecases.StatsRepository
+	Undo          usecases.UndoRepository
+	Trash         usecases.TrashRepository
+	Integrity     usecases.IntegrityRepository
+	Idempotency   idempotency.Store
+	LibraryEvents usecases.LibraryEventStore        //Nil unless libraries are event sourced
+	Pool          usecases.PoolStatsProvider        //Nil on MongoDB
+	Database      *infrastructure.PostgresqlHandler //Nil on MongoDB
+	Documents     interfaces.DocumentHandler        //Nil on Postgres
+}
+
+// Opens the database selected in the config, the usecases only ever see
+// the repository interfaces so either backend can be swapped in
+func OpenRepositories(config postgres.Configuration) (Repositories, error) {
+	switch config.Database {
+	case "", "postgres":
+		return postgresRepositories(config)
+	case "mongo":
+		if config.Libraries.Persistence == "events" {
+			return Repositories{}, fmt.Errorf("Event sourced libraries need Postgres")
+		}
+		return mongoRepositories(config)
+	case "memory":
+		if config.Libraries.Persistence == "events" {
+			return Repositories{}, fmt.Errorf("Event sourced libraries need Postgres")
+		}
+		return documentRepositories(infrastructure.NewMemoryHandler()), nil
+	}
+	return Repositories{}, fmt.Errorf("Unknown database '%s'", config.Database)
+}
+
+func postgresRepositories(config postgres.Configuration) (Repositories, error) {
+	timeout := time.Duration(config.Queries.Timeout) * time.Millisecond
+	var failover []string
+	for _, dsn := range config.PostgresFailover {
+		failover = append(failover, infrastructure.StatementTimeoutDsn(dsn, timeout))
+	}
+	dbHandler, err := infrastructure.NewPostgresqlHandler(
+		infrastructure.StatementTimeoutDsn(config.PostgresAdr, timeout), failover...)
+	if err != nil {
+		return Repositories{}, err
+	}
+	dbHandler.Timeout = timeout
+	err = infrastructure.Migrate(dbHandler, "migrations")
+	if err != nil {
+		return Repositories{}, err
+	}
+
+	handlers := make(map[string]interfaces.DbHandler)
+	handlers["DbUserRepo"] = dbHandler
+	handlers["DbPlayerRepo"] = dbHandler
+	handlers["DbGameRepo"] = dbHandler
+	handlers["DbLibraryRepo"] = dbHandler
+	handlers["DbNotificationRepo"] = dbHandler
+	handlers["DbSettingsRepo"] = dbHandler
+	handlers["DbIdempotencyRepo"] = dbHandler
+	handlers["DbChangeRepo"] = dbHandler
+	handlers["DbAdminRepo"] = dbHandler
+	handlers["DbFlagRepo"] = dbHandler
+	handlers["DbActivityRepo"] = dbHandler
+	handlers["DbPlaySessionRepo"] = dbHandler
+	handlers["DbReleaseRepo"] = dbHandler
+	handlers["DbCalendarTokenRepo"] = dbHandler
+	handlers["DbFranchiseRepo"] = dbHandler
+	handlers["DbChildAccountRepo"] = dbHandler
+	handlers["DbPersonalMetadataRepo"] = dbHandler
+	handlers["DbJournalRepo"] = dbHandler
+	handlers["DbSteamRepo"] = dbHandler
+	handlers["DbPhysicalCopyRepo"] = dbHandler
+	handlers["DbPhotoImportRepo"] = dbHandler
+	handlers["DbShareLinkRepo"] = dbHandler
+	handlers["DbLibraryMemberRepo"] = dbHandler
+	handlers["DbTradeRepo"] = dbHandler
+	handlers["DbBadgeRepo"] = dbHandler
+	handlers["DbGoalRepo"] = dbHandler
+	handlers["DbHardwareRepo"] = dbHandler
+	handlers["DbSubscriptionRepo"] = dbHandler
+	handlers["DbCatalogRepo"] = dbHandler
+	handlers["DbAddonRepo"] = dbHandler
+	handlers["DbModRepo"] = dbHandler
+	handlers["DbSaveBackupRepo"] = dbHandler
+	handlers["DbSpeedrunRepo"] = dbHandler
+	handlers["DbMatchRepo"] = dbHandler
+	handlers["DbAgentRepo"] = dbHandler
+	handlers["DbExecutableRepo"] = dbHandler
+	handlers["DbWebhookRepo"] = dbHandler
+	handlers["DbRuleRepo"] = dbHandler
+	handlers["DbScriptRepo"] = dbHandler
+	handlers["DbSavedSearchRepo"] = dbHandler
+	handlers["DbStatsRepo"] = dbHandler
+	handlers["DbLibraryEventStore"] = dbHandler
+	handlers["DbUndoRepo"] = dbHandler
+	handlers["DbTrashRepo"] = dbHandler
+	handlers["DbIntegrityRepo"] = dbHandler
+	for key, milliseconds := range config.Queries.Overrides {
+		handlers[key] = dbHandler.WithTimeout(time.Duration(milliseconds) * time.Millisecond)
+	}
+
+	// Repositories that load others get them here, built once and shared
+	users := interfaces.NewDbUserRepo(handlers, interfaces.NewDbPlayerRepo(handlers))
+	var libraries usecases.LibraryRepository
+	var games usecases.GameRepository
+	var libraryEvents usecases.LibraryEventStore
+	switch config.Libraries.Persistence {
+	case "", "tables":
+		libraries = interfaces.NewDbLibraryRepo(handlers, users)
+		games = interfaces.NewDbGameRepo(handlers)
+	case "events":
+		events := interfaces.NewDbLibraryEventStore(handlers, config.Libraries.SnapshotEvery)
+		libraries = interfaces.NewEventSourcedLibraryRepo(handlers, users, events)
+		games = interfaces.NewEventSourcedGameRepo(handlers, events)
+		libraryEvents = events
+	default:
+		return Repositories{}, fmt.Errorf("Unknown library persistence '%s'",
+			config.Libraries.Persistence)
+	}
+	return Repositories{
+		Pool:          dbHandler,
+		Database:      dbHandler,
+		Users:         users,
+		Libraries:     libraries,
+		Games:         games,
+		LibraryEvents: libraryEvents,
+		Settings:      interfaces.NewDbSettingsRepo(handlers),
+		Notifications: interfaces.NewDbNotificationRepo(handlers),
+		Changes:       interfaces.NewDbChangeRepo(handlers),
+		Admin:         interfaces.NewDbAdminRepo(handlers),
+		Flags:         interfaces.NewDbFlagRepo(handlers),
+		Activities:    interfaces.NewDbActivityRepo(handlers),
+		Sessions:      interfaces.NewDbPlaySessionRepo(handlers),
+		Releases:      interfaces.NewDbReleaseRepo(handlers),
+		Calendars:     interfaces.NewDbCalendarTokenRepo(handlers),
+		Franchises:    interfaces.NewDbFranchiseRepo(handlers),
+		Children:      interfaces.NewDbChildAccountRepo(handlers),
+		Personal:      interfaces.NewDbPersonalMetadataRepo(handlers),
+		Journal:       interfaces.NewDbJournalRepo(handlers),
+		Steam:         interfaces.NewDbSteamRepo(handlers),
+		Copies:        interfaces.NewDbPhysicalCopyRepo(handlers),
+		Photos:        interfaces.NewDbPhotoImportRepo(handlers),
+		Shares:        interfaces.NewDbShareLinkRepo(handlers),
+		Members:       interfaces.NewDbLibraryMemberRepo(handlers),
+		Trades:        interfaces.NewDbTradeRepo(handlers),
+		Badges:        interfaces.NewDbBadgeRepo(handlers),
+		Goals:         interfaces.NewDbGoalRepo(handlers),
+		Hardware:      interfaces.NewDbHardwareRepo(handlers),
+		Subscriptions: interfaces.NewDbSubscriptionRepo(handlers),
+		Catalogs:      interfaces.NewDbCatalogRepo(handlers),
+		Addons:        interfaces.NewDbAddonRepo(handlers),
+		Mods:          interfaces.NewDbModRepo(handlers),
+		Backups:       interfaces.NewDbSaveBackupRepo(handlers),
+		Speedruns:     interfaces.NewDbSpeedrunRepo(handlers),
+		Matches:       interfaces.NewDbMatchRepo(handlers),
+		Agents:        interfaces.NewDbAgentRepo(handlers),
+		Executables:   interfaces.NewDbExecutableRepo(handlers),
+		Webhooks:      interfaces.NewDbWebhookRepo(handlers),
+		Rules:         interfaces.NewDbRuleRepo(handlers),
+		Scripts:       interfaces.NewDbScriptRepo(handlers),
+		Searches:      interfaces.NewDbSavedSearchRepo(handlers),
+		Stats:         interfaces.NewDbStatsRepo(handlers),
+		Undo:          interfaces.NewDbUndoRepo(handlers),
+		Trash:         interfaces.NewDbTrashRepo(handlers),
+		Integrity:     interfaces.NewDbIntegrityRepo(handlers),
+		Idempotency:   interfaces.NewDbIdempotencyRepo(handlers),
+	}, nil
+}
+
+func mongoRepositories(config postgres.Configuration) (Repositories, error) {
+	docHandler, err := infrastructure.NewMongoHandler(config.MongoAdr, config.MongoDatabase)
+	if err != nil {
+		return Repositories{}, err
+	}
+	err = infrastructure.EnsureMongoIndexes(docHandler)
+	if err != nil {
+		return Repositories{}, err
+	}
+	return documentRepositories(docHandler), nil
+}
+
+// The Mongo repositories over docHandler, which is Mongo itself or the
+// in-memory handler
+func documentRepositories(docHandler interfaces.DocumentHandler) Repositories {
+	handlers := make(map[string]interfaces.DocumentHandler)
+	handlers["MongoUserRepo"] = docHandler
+	handlers["MongoPlayerRepo"] = docHandler
+	handlers["MongoGameRepo"] = docHandler
+	handlers["MongoLibraryRepo"] = docHandler
+	handlers["MongoNotificationRepo"] = docHandler
+	handlers["MongoSettingsRepo"] = docHandler
+	handlers["MongoIdempotencyRepo"] = docHandler
+	handlers["MongoChangeRepo"] = docHandler
+	handlers["MongoAdminRepo"] = docHandler
+	handlers["MongoFlagRepo"] = docHandler
+	handlers["MongoActivityRepo"] = docHandler
+	handlers["MongoPlaySessionRepo"] = docHandler
+	handlers["MongoReleaseRepo"] = docHandler
+	handlers["MongoCalendarTokenRepo"] = docHandler
+	handlers["MongoFranchiseRepo"] = docHandler
+	handlers["MongoChildAccountRepo"] = docHandler
+	handlers["MongoPersonalMetadataRepo"] = docHandler
+	handlers["MongoJournalRepo"] = docHandler
+	handlers["MongoSteamRepo"] = docHandler
+	handlers["MongoPhysicalCopyRepo"] = docHandler
+	handlers["MongoPhotoImportRepo"] = docHandler
+	handlers["MongoShareLinkRepo"] = docHandler
+	handlers["MongoLibraryMemberRepo"] = docHandler
+	handlers["MongoTradeRepo"] = docHandler
+	handlers["MongoBadgeRepo"] = docHandler
+	handlers["MongoGoalRepo"] = docHandler
+	handlers["MongoHardwareRepo"] = docHandler
+	handlers["MongoSubscriptionRepo"] = docHandler
+	handlers["MongoCatalogRepo"] = docHandler
+	handlers["MongoAddonRepo"] = docHandler
+	handlers["MongoModRepo"] = docHandler
+	handlers["MongoSaveBackupRepo"] = docHandler
+	handlers["MongoSpeedrunRepo"] = docHandler
+	handlers["MongoMatchRepo"] = docHandler
+	handlers["MongoAgentRepo"] = docHandler
+	handlers["MongoExecutableRepo"] = docHandler
+	handlers["MongoWebhookRepo"] = docHandler
+	handlers["MongoRuleRepo"] = docHandler
+	handlers["MongoScriptRepo"] = docHandler
+	handlers["MongoSavedSearchRepo"] = docHandler
+	handlers["MongoStatsRepo"] = docHandler
+	handlers["MongoUndoRepo"] = docHandler
+	handlers["MongoTrashRepo"] = docHandler
+	handlers["MongoIntegrityRepo"] = docHandler
+
+	// Repositories that load others get them here, built once and shared
+	users := interfaces.NewMongoUserRepo(handlers, interfaces.NewMongoPlayerRepo(handlers))
+	return Repositories{
+		Users:         users,
+		Libraries:     interfaces.NewMongoLibraryRepo(handlers, users),
+		Games:         interfaces.NewMongoGameRepo(handlers),
+		Settings:      interfaces.NewMongoSettingsRepo(handlers),
+		Notifications: interfaces.NewMongoNotificationRepo(handlers),
+		Changes:       interfaces.NewMongoChangeRepo(handlers),
+		Admin:         interfaces.NewMongoAdminRepo(handlers),
+		Flags:         interfaces.NewMongoFlagRepo(handlers),
+		Activities:    interfaces.NewMongoActivityRepo(handlers),
+		Sessions:      interfaces.NewMongoPlaySessionRepo(handlers),
+		Releases:      interfaces.NewMongoReleaseRepo(handlers),
+		Calendars:     interfaces.NewMongoCalendarTokenRepo(handlers),
+		Franchises:    interfaces.NewMongoFranchiseRepo(handlers),
+		Children:      interfaces.NewMongoChildAccountRepo(handlers),
+		Personal:      interfaces.NewMongoPersonalMetadataRepo(handlers),
+		Journal:       interfaces.NewMongoJournalRepo(handlers),
+		Steam:         interfaces.NewMongoSteamRepo(handlers),
+		Copies:        interfaces.NewMongoPhysicalCopyRepo(handlers),
+		Photos:        interfaces.NewMongoPhotoImportRepo(handlers),
+		Shares:        interfaces.NewMongoShareLinkRepo(handlers),
+		Members:       interfaces.NewMongoLibraryMemberRepo(handlers),
+		Trades:        interfaces.NewMongoTradeRepo(handlers),
+		Badges:        interfaces.NewMongoBadgeRepo(handlers),
+		Goals:         interfaces.NewMongoGoalRepo(handlers),
+		Hardware:      interfaces.NewMongoHardwareRepo(handlers),
+		Subscriptions: interfaces.NewMongoSubscriptionRepo(handlers),
+		Catalogs:      interfaces.NewMongoCatalogRepo(handlers),
+		Addons:        interfaces.NewMongoAddonRepo(handlers),
+		Mods:          interfaces.NewMongoModRepo(handlers),
+		Backups:       interfaces.NewMongoSaveBackupRepo(handlers),
+		Speedruns:     interfaces.NewMongoSpeedrunRepo(handlers),
+		Matches:       interfaces.NewMongoMatchRepo(handlers),
+		Agents:        interfaces.NewMongoAgentRepo(handlers),
+		Executables:   interfaces.NewMongoExecutableRepo(handlers),
+		Webhooks:      interfaces.NewMongoWebhookRepo(handlers),
+		Rules:         interfaces.NewMongoRuleRepo(handlers),
+		Scripts:       interfaces.NewMongoScriptRepo(handlers),
+		Searches:      interfaces.NewMongoSavedSearchRepo(handlers),
+		Stats:         interfaces.NewMongoStatsRepo(handlers),
+		Undo:          interfaces.NewMongoUndoRepo(handlers),
+		Trash:         interfaces.NewMongoTrashRepo(handlers),
+		Integrity:     interfaces.NewMongoIntegrityRepo(handlers),
+		Idempotency:   interfaces.NewMongoIdempotencyRepo(handlers),
+		Documents:     docHandler,
+	}
+}
+
+// The game cache, the web sessions and the rate limit counters
+type Caches struct {
+	Cache     interfaces.Cache
+	Sessions  interfaces.Cache
+	RateLimit interfaces.Cache
+	Redis     *infrastructure.RedisHandler //Nil when caches are in memory
+}
+
+// The caches that can count their entries, by name for diagnostics
+func (caches Caches) sized() map[string]usecases.SizedCache {
+	sized := make(map[string]usecases.SizedCache)
+	for name, cache := range map[string]interfaces.Cache{"games": caches.Cache,
+		"sessions": caches.Sessions, "rateLimit": caches.RateLimit} {
+		if counting, ok := cache.(usecases.SizedCache); ok {
+			sized[name] = counting
+		}
+	}
+	return sized
+}
+
+// Uses Redis when an address is configured, otherwise keeps everything in
+// memory which only suits a single instance
+func OpenCaches(config postgres.Redis) (Caches, error) {
+	cacheTtl := time.Duration(config.Cache.Ttl) * time.Second
+	sessionTtl := time.Duration(config.Sessions.Ttl) * time.Second
+	rateLimitTtl := time.Duration(config.RateLimit.Ttl) * time.Second
+	if config.Address == "" {
+		return Caches{
+			Cache:     infrastructure.NewInMemoryCache(cacheTtl),
+			Sessions:  infrastructure.NewInMemoryCache(sessionTtl),
+			RateLimit: infrastructure.NewInMemoryCache(rateLimitTtl),
+		}, nil
+	}
+
+	redisHandler, err := infrastructure.NewRedisHandler(config.Address, config.Password, config.Db)
+	if err != nil {
+		return Caches{}, err
+	}
+	return Caches{
+		Cache:     infrastructure.NewRedisCache(redisHandler, config.Cache.Prefix, cacheTtl),
+		Sessions:  infrastructure.NewRedisCache(redisHandler, config.Sessions.Prefix, sessionTtl),
+		RateLimit: infrastructure.NewRedisCache(redisHandler, config.RateLimit.Prefix, rateLimitTtl),
+		Redis:     redisHandler,
+	}, nil
+}
@@ -0,0 +1,23 @@
+package bootstrap
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	"game-tracker/infrastructure"
+	"game-tracker/models/postgres"
+)
+
+// Builds the reporter usage stats are batched in, only called once the
+// operator opted in
+func usageReporter(config postgres.Telemetry) (*infrastructure.HttpReporter, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, errors.New("telemetry endpoint must be an http(s) URL")
+	}
+	if config.Interval <= 0 {
+		return nil, errors.New("telemetry interval must be positive")
+	}
+	return infrastructure.NewHttpReporter(config.Endpoint, time.Duration(config.Interval)*time.Second), nil
+}
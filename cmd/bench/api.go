@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Drives the API over HTTP as a user signed up for the run
+type apiTarget struct {
+	base      string
+	http      *http.Client
+	name      string
+	password  string
+	token     string
+	userId    string
+	libraryId string
+	lock      sync.Mutex
+	added     map[int]string //Game the worker added last, by worker
+	sequence  int
+}
+
+type created struct {
+	Data struct {
+		Id         string `json:"id"`
+		Attributes struct {
+			TokenString string `json:"tokenString"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+func newApiTarget(base string) *apiTarget {
+	return &apiTarget{
+		base:     strings.TrimRight(base, "/"),
+		http:     &http.Client{Timeout: 30 * time.Second, Transport: &http.Transport{MaxIdleConnsPerHost: 256}},
+		name:     fmt.Sprintf("bench%d", time.Now().UnixNano()),
+		password: fmt.Sprintf("bench-%d", time.Now().UnixNano()),
+		added:    make(map[int]string),
+	}
+}
+
+func (api *apiTarget) setup() error {
+	var user, token, library created
+	err := api.do("POST", "/users", map[string]string{"playerName": api.name, "name": api.name,
+		"password": api.password}, &user)
+	if err != nil {
+		return err
+	}
+	api.userId = user.Data.Id
+	err = api.do("POST", "/login", map[string]string{"username": api.name, "password": api.password},
+		&token)
+	if err != nil {
+		return err
+	}
+	api.token = token.Data.Attributes.TokenString
+	err = api.do("POST", "/users/"+api.userId+"/libraries", nil, &library)
+	if err != nil {
+		return err
+	}
+	api.libraryId = library.Data.Id
+	return nil
+}
+
+func (api *apiTarget) games() string {
+	return "/users/" + api.userId + "/libraries/" + api.libraryId + "/games"
+}
+
+func (api *apiTarget) read(worker int) (string, error) {
+	return "list games", api.do("GET", api.games(), nil, nil)
+}
+
+func (api *apiTarget) write(worker int) (string, error) {
+	api.lock.Lock()
+	gameId := api.added[worker]
+	delete(api.added, worker)
+	api.sequence++
+	sequence := api.sequence
+	api.lock.Unlock()
+
+	if gameId != "" {
+		return "remove game", api.do("DELETE", api.games()+"/"+gameId, nil, nil)
+	}
+	var game created
+	err := api.do("POST", api.games(), map[string]interface{}{
+		"name": fmt.Sprintf("Bench %s %d", api.name, sequence), "producer": "Bench", "value": 1}, &game)
+	if err == nil {
+		api.lock.Lock()
+		api.added[worker] = game.Data.Id
+		api.lock.Unlock()
+	}
+	return "add game", err
+}
+
+func (api *apiTarget) teardown() error {
+	return api.do("DELETE", "/users/"+api.userId, nil, nil)
+}
+
+func (api *apiTarget) do(method, path string, body, into interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	request, err := http.NewRequest(method, api.base+path, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if api.token != "" {
+		request.Header.Set("X-Auth-Key", api.token)
+	}
+	response, err := api.http.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 400 {
+		io.Copy(ioutil.Discard, response.Body)
+		return fmt.Errorf("%s %s answered %d", method, path, response.StatusCode)
+	}
+	if into == nil {
+		// Read to the end so the connection is reused
+		_, err = io.Copy(ioutil.Discard, response.Body)
+		return err
+	}
+	return json.NewDecoder(response.Body).Decode(into)
+}
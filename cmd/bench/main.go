@@ -0,0 +1,164 @@
+// Generates load against the API or straight against the usecases and
+// reports latency percentiles per operation, for capacity planning. Every
+// run signs up a user of its own with one library and removes it again.
+//
+//	go run ./cmd/bench -target api -url http://localhost:8080 -concurrency 16 -duration 1m
+//	go run ./cmd/bench -target usecases -reads 0.8
+//
+// The usecases target opens the database of config.json, so run it from
+// the repository root. Reads list the games of the library, writes add a
+// game or remove the one the worker added last.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// What the workers drive, reads and writes name the operation they timed
+type target interface {
+	setup() error
+	read(worker int) (string, error)
+	write(worker int) (string, error)
+	teardown() error
+}
+
+type sample struct {
+	latency time.Duration
+	failed  bool
+}
+
+type recorder struct {
+	lock    sync.Mutex
+	samples map[string][]sample
+}
+
+func (r *recorder) add(operation string, latency time.Duration, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.samples[operation] = append(r.samples[operation], sample{latency: latency, failed: err != nil})
+}
+
+func main() {
+	kind := flag.String("target", "api", "api or usecases")
+	url := flag.String("url", "http://localhost:8080", "base URL of the API for the api target")
+	concurrency := flag.Int("concurrency", 8, "workers sending operations at once")
+	duration := flag.Duration("duration", 30*time.Second, "how long to generate load")
+	reads := flag.Float64("reads", 0.9, "share of operations that are reads, 0 to 1")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed of the read/write mix")
+	flag.Parse()
+
+	if *concurrency < 1 || *duration <= 0 || *reads < 0 || *reads > 1 {
+		fmt.Println("concurrency must be positive, duration positive and reads between 0 and 1")
+		os.Exit(2)
+	}
+	var load target
+	switch *kind {
+	case "api":
+		load = newApiTarget(*url)
+	case "usecases":
+		direct, err := newUsecaseTarget("config.json")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+		load = direct
+	default:
+		fmt.Printf("Unknown target '%s', use api or usecases\n", *kind)
+		os.Exit(2)
+	}
+
+	err := load.setup()
+	if err != nil {
+		fmt.Println("Cannot set up the bench user", err)
+		os.Exit(2)
+	}
+	fmt.Printf("Running %d workers for %s, %.0f%% reads\n", *concurrency, *duration, *reads*100)
+	results := &recorder{samples: make(map[string][]sample)}
+	elapsed := run(load, results, *concurrency, *duration, *reads, *seed)
+
+	err = load.teardown()
+	if err != nil {
+		fmt.Println("Cannot remove the bench user", err)
+	}
+	report(results, elapsed)
+}
+
+// Keeps every worker busy until duration is over
+func run(load target, results *recorder, concurrency int, duration time.Duration, reads float64,
+	seed int64) time.Duration {
+	started := time.Now()
+	deadline := started.Add(duration)
+	var workers sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		workers.Add(1)
+		go func(worker int) {
+			defer workers.Done()
+			mix := rand.New(rand.NewSource(seed + int64(worker)))
+			for time.Now().Before(deadline) {
+				began := time.Now()
+				var operation string
+				var err error
+				if mix.Float64() < reads {
+					operation, err = load.read(worker)
+				} else {
+					operation, err = load.write(worker)
+				}
+				results.add(operation, time.Since(began), err)
+			}
+		}(worker)
+	}
+	workers.Wait()
+	return time.Since(started)
+}
+
+func report(results *recorder, elapsed time.Duration) {
+	var operations []string
+	for operation := range results.samples {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+
+	fmt.Printf("\n%-12s %8s %7s %9s %9s %9s %9s %9s\n", "operation", "count", "errors",
+		"per sec", "p50", "p90", "p99", "max")
+	total := 0
+	for _, operation := range operations {
+		samples := results.samples[operation]
+		total += len(samples)
+		var latencies []time.Duration
+		errors := 0
+		for _, sample := range samples {
+			latencies = append(latencies, sample.latency)
+			if sample.failed {
+				errors++
+			}
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		fmt.Printf("%-12s %8d %7d %9.1f %9s %9s %9s %9s\n", operation, len(samples), errors,
+			float64(len(samples))/elapsed.Seconds(), percentile(latencies, 0.5),
+			percentile(latencies, 0.9), percentile(latencies, 0.99),
+			percentile(latencies, 1).Round(time.Microsecond))
+	}
+	fmt.Printf("\n%d operations in %s, %.1f per second\n", total, elapsed.Round(time.Millisecond),
+		float64(total)/elapsed.Seconds())
+}
+
+// Nearest rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(sorted))+0.999999) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank].Round(time.Microsecond)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"game-tracker/app/bootstrap"
+	"game-tracker/usecases"
+)
+
+// Calls the profile usecase in process, which leaves out HTTP, the
+// middlewares and JSON so the cost of the usecases and the database shows
+type usecaseTarget struct {
+	app       *bootstrap.App
+	profile   usecases.ProfileUsecase
+	name      string
+	userId    int
+	libraryId int
+	lock      sync.Mutex
+	added     map[int]int //Game the worker added last, by worker
+	sequence  int
+}
+
+func newUsecaseTarget(configPath string) (*usecaseTarget, error) {
+	config, err := bootstrap.LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	app, err := bootstrap.Build(config)
+	if err != nil {
+		return nil, err
+	}
+	return &usecaseTarget{app: app, profile: &app.Interactors.Profile,
+		name: fmt.Sprintf("bench%d", time.Now().UnixNano()), added: make(map[int]int)}, nil
+}
+
+func (direct *usecaseTarget) setup() error {
+	user, err, _ := direct.profile.AddUser(direct.name, direct.name, direct.name)
+	if err != nil {
+		return err
+	}
+	direct.userId = user.Id
+	library, err, _ := direct.profile.AddLibrary(user.Id)
+	if err != nil {
+		return err
+	}
+	direct.libraryId = library.Id
+	return nil
+}
+
+func (direct *usecaseTarget) read(worker int) (string, error) {
+	_, _, err, _ := direct.profile.ShowGames(direct.userId, direct.libraryId, usecases.GameFilter{},
+		usecases.Page{Limit: 100})
+	return "list games", err
+}
+
+func (direct *usecaseTarget) write(worker int) (string, error) {
+	direct.lock.Lock()
+	gameId := direct.added[worker]
+	delete(direct.added, worker)
+	direct.sequence++
+	sequence := direct.sequence
+	direct.lock.Unlock()
+
+	if gameId != 0 {
+		err, _ := direct.profile.RemoveGame(direct.userId, direct.libraryId, gameId, 0)
+		return "remove game", err
+	}
+	game, err, _ := direct.profile.AddGame(direct.userId, direct.libraryId, usecases.Game{
+		Name: fmt.Sprintf("Bench %s %d", direct.name, sequence), Producer: "Bench", Value: 1}, 0)
+	if err == nil {
+		direct.lock.Lock()
+		direct.added[worker] = game.Id
+		direct.lock.Unlock()
+	}
+	return "add game", err
+}
+
+func (direct *usecaseTarget) teardown() error {
+	defer direct.app.Close()
+	err, _ := direct.profile.RemoveUser(direct.userId)
+	return err
+}
@@ -0,0 +1,91 @@
+// Explains the lookups the API makes most against the Postgres database of
+// config.json and fails when one of them reads a whole table, so a missing
+// index is found before it is slow. Run it from the repository root after
+// the migrations, in CI against a freshly migrated database:
+//
+//	go run ./cmd/explain
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"game-tracker/infrastructure"
+	"game-tracker/interfaces"
+	"game-tracker/models/postgres"
+	"game-tracker/usecases"
+)
+
+func main() {
+	file, err := os.Open("config.json")
+	if err != nil {
+		fmt.Println("Cannot open config file")
+		os.Exit(2)
+	}
+	config := postgres.Configuration{}
+	err = json.NewDecoder(file).Decode(&config)
+	if err != nil {
+		fmt.Println("Cannot read config file")
+		os.Exit(2)
+	}
+	dbHandler, err := infrastructure.NewPostgresqlHandler(config.PostgresAdr)
+	if err != nil {
+		fmt.Println("Cannot open the database", err)
+		os.Exit(2)
+	}
+	err = infrastructure.Migrate(dbHandler, "migrations")
+	if err != nil {
+		fmt.Println("Cannot migrate the database", err)
+		os.Exit(2)
+	}
+
+	checker := infrastructure.NewPlanChecker(dbHandler)
+	handlers := map[string]interfaces.DbHandler{"DbUserRepo": checker, "DbPlayerRepo": checker,
+		"DbLibraryRepo": checker, "DbGameRepo": checker, "DbNotificationRepo": checker}
+	players := interfaces.NewDbPlayerRepo(handlers)
+	users := interfaces.NewDbUserRepo(handlers, players)
+	libraries := interfaces.NewDbLibraryRepo(handlers, users)
+	games := interfaces.NewDbGameRepo(handlers)
+	notifications := interfaces.NewDbNotificationRepo(handlers)
+
+	// Found or not, every lookup sends its first statement
+	firstPage := usecases.Page{Limit: 100}
+	lookups := map[string]func() error{
+		"user by id":            func() error { _, err, code := users.FindById(1); return failed(err, code) },
+		"user by name":          func() error { _, err, code := users.FindByName("explain", false); return failed(err, code) },
+		"user by name key":      func() error { _, err, code := users.FindByName("explain", true); return failed(err, code) },
+		"login":                 func() error { _, err := users.CheckLogin("explain", "explain"); return err },
+		"player by name":        func() error { _, err, code := players.FindByName("explain", true); return failed(err, code) },
+		"libraries of a user":   func() error { _, err := libraries.FindVersionsByUser(1); return err },
+		"games of a library":    func() error { _, err := games.FindByLib(1, usecases.GameFilter{}, firstPage); return err },
+		"game in a library":     func() error { _, err, code := games.FindInLib(1, 1); return failed(err, code) },
+		"tags of libraries":     func() error { _, err := games.FindTags([]int{1}); return err },
+		"notifications of user": func() error { _, err := notifications.FindByUser(1, usecases.Page{Limit: 20}); return err },
+	}
+	ok := true
+	for name, lookup := range lookups {
+		err = lookup()
+		if err != nil {
+			fmt.Printf("Cannot explain %s: %v\n", name, err)
+			ok = false
+		}
+	}
+	for _, scan := range checker.SeqScans {
+		fmt.Printf("Sequential scan of %v in: %s\n", scan.Relations, scan.Statement)
+		ok = false
+	}
+	if !ok {
+		os.Exit(1)
+	}
+	fmt.Printf("%d lookups use indexes\n", len(lookups))
+}
+
+// Lookups of rows that do not exist fail with 404, only the database
+// failing counts
+func failed(err error, code int) error {
+	if code == 404 {
+		return nil
+	}
+	return err
+}
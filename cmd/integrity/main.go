@@ -0,0 +1,59 @@
+// Checks the database of config.json for rows breaking relations it does
+// not enforce, such as games in libraries that were removed or users whose
+// player is gone, and repairs them with -repair:
+//
+//	go run ./cmd/integrity -config config.json
+//	go run ./cmd/integrity -config config.json -repair
+//
+// It exits with 1 when a check finds rows it did not repair.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"game-tracker/app/bootstrap"
+	"game-tracker/usecases"
+)
+
+func main() {
+	configPath := flag.String("config", "config.json", "config file of the API")
+	repair := flag.Bool("repair", false, "repair what the checks find")
+	flag.Parse()
+
+	config, err := bootstrap.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	repos, err := bootstrap.OpenRepositories(config)
+	if err != nil {
+		fmt.Println("Cannot open the database", err)
+		os.Exit(2)
+	}
+	interactor := usecases.IntegrityInteractor{IntegrityRepository: repos.Integrity}
+	findings, err := interactor.Check(*repair)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	broken := false
+	for _, finding := range findings {
+		switch {
+		case finding.Count == 0:
+			fmt.Printf("ok        %s\n", finding.Name)
+		case finding.Repaired > 0:
+			fmt.Printf("repaired  %s: %d of %d. %s: %s.\n", finding.Name, finding.Repaired,
+				finding.Count, finding.Description, finding.Repair)
+		default:
+			broken = true
+			fmt.Printf("broken    %s: %d. %s, such as %v. Repair: %s.\n", finding.Name,
+				finding.Count, finding.Description, finding.Ids, finding.Repair)
+		}
+	}
+	if broken {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Talks to the API as the signed in user, the server does every check the
+// web client gets
+type client struct {
+	base   string
+	http   *http.Client
+	token  string
+	userId string //External id, read from the token
+}
+
+type library struct {
+	Id        string
+	UpdatedAt string
+}
+
+type game struct {
+	Id       string
+	Name     string
+	Status   string
+	Platform string
+}
+
+type apiError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func newClient(base string) *client {
+	return &client{base: strings.TrimRight(base, "/"), http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *client) login(username, password string) error {
+	var token struct {
+		Data struct {
+			Attributes struct {
+				TokenString string `json:"tokenString"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	err := c.do("POST", "/login", map[string]string{"username": username, "password": password}, &token)
+	if err != nil {
+		return err
+	}
+	c.token = token.Data.Attributes.TokenString
+	c.userId, err = tokenSubject(c.token)
+	return err
+}
+
+// The subject of a JWT without checking its signature, the server does
+func tokenSubject(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("Token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", err
+	}
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	err = json.Unmarshal(payload, &claims)
+	if err != nil {
+		return "", err
+	}
+	return claims.Subject, nil
+}
+
+func (c *client) libraries() ([]library, error) {
+	var response struct {
+		Data []struct {
+			Id         string `json:"id"`
+			Attributes struct {
+				UpdatedAt string `json:"updatedAt"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	err := c.do("GET", "/users/"+c.userId+"/libraries", nil, &response)
+	if err != nil {
+		return nil, err
+	}
+	var libraries []library
+	for _, data := range response.Data {
+		libraries = append(libraries, library{Id: data.Id, UpdatedAt: data.Attributes.UpdatedAt})
+	}
+	return libraries, nil
+}
+
+// Follows the cursors until the last page
+func (c *client) games(libraryId string) ([]game, error) {
+	var games []game
+	cursor := ""
+	for {
+		var response struct {
+			Data []struct {
+				Id         string `json:"id"`
+				Attributes struct {
+					Name     string `json:"name"`
+					Status   string `json:"status"`
+					Platform string `json:"platform"`
+				} `json:"attributes"`
+			} `json:"data"`
+			Meta struct {
+				Cursor  string `json:"cursor"`
+				HasMore bool   `json:"hasMore"`
+			} `json:"meta"`
+		}
+		path := "/users/" + c.userId + "/libraries/" + libraryId + "/games?limit=500"
+		if cursor != "" {
+			path += "&cursor=" + url.QueryEscape(cursor)
+		}
+		err := c.do("GET", path, nil, &response)
+		if err != nil {
+			return nil, err
+		}
+		for _, data := range response.Data {
+			games = append(games, game{Id: data.Id, Name: data.Attributes.Name,
+				Status: data.Attributes.Status, Platform: data.Attributes.Platform})
+		}
+		if !response.Meta.HasMore {
+			return games, nil
+		}
+		cursor = response.Meta.Cursor
+	}
+}
+
+// Logs a session that ended just now
+func (c *client) logSession(gameId string, minutes int) error {
+	session := map[string]interface{}{"gameId": gameId, "minutes": minutes,
+		"startsAt": time.Now().Add(-time.Duration(minutes) * time.Minute).UTC()}
+	return c.do("POST", "/users/"+c.userId+"/sessions", session, nil)
+}
+
+func (c *client) setStatus(libraryId, gameId, status string) error {
+	batch := map[string]interface{}{"ids": []string{gameId}, "status": status}
+	var response struct {
+		Data []struct {
+			Status  int    `json:"status"`
+			Message string `json:"message"`
+		} `json:"data"`
+	}
+	err := c.do("PUT", "/users/"+c.userId+"/libraries/"+libraryId+"/games", batch, &response)
+	if err != nil {
+		return err
+	}
+	for _, item := range response.Data {
+		if item.Status >= 400 {
+			return fmt.Errorf("%s", item.Message)
+		}
+	}
+	return nil
+}
+
+func (c *client) do(method, path string, body, into interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	request, err := http.NewRequest(method, c.base+path, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		request.Header.Set("X-Auth-Key", c.token)
+	}
+	response, err := c.http.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 400 {
+		var failure apiError
+		if json.NewDecoder(response.Body).Decode(&failure) == nil && failure.Error.Message != "" {
+			return fmt.Errorf("%s", failure.Error.Message)
+		}
+		return fmt.Errorf("%s %s answered %d", method, path, response.StatusCode)
+	}
+	if into == nil || response.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(into)
+}
@@ -0,0 +1,50 @@
+// A terminal client for the API, laid out so it can be driven with a game
+// controller: browse libraries, log sessions and tick off completed games.
+//
+//	go run ./cmd/tui -api http://localhost:8080 -user alice
+//
+// The password is read from GAME_TRACKER_PASSWORD, or asked for when unset.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func main() {
+	api := flag.String("api", "http://localhost:8080", "Base URL of the API")
+	username := flag.String("user", "", "Name to sign in with")
+	flag.Parse()
+	if *username == "" {
+		fmt.Println("Usage: tui -user <name> [-api <url>]")
+		os.Exit(2)
+	}
+
+	password := os.Getenv("GAME_TRACKER_PASSWORD")
+	if password == "" {
+		fmt.Print("Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			fmt.Println("Cannot read the password", err)
+			os.Exit(1)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	client := newClient(*api)
+	err := client.login(*username, password)
+	if err != nil {
+		fmt.Println("Cannot sign in:", err)
+		os.Exit(1)
+	}
+	_, err = tea.NewProgram(newModel(client), tea.WithAltScreen()).Run()
+	if err != nil {
+		fmt.Println("Cannot run the terminal client:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	screenLibraries = iota
+	screenGames
+	screenSession
+)
+
+const (
+	sessionStep    = 15 //Minutes the left and right keys add or take
+	defaultSession = 60
+	maxSession     = 24 * 60
+)
+
+// Keys as a controller mapped through Steam Input or a similar layer sends
+// them: the d-pad gives arrows, A enter, B escape, X x and Y y
+type model struct {
+	client    *client
+	screen    int
+	libraries []library
+	games     []game
+	library   string //Id of the library shown
+	cursor    int
+	minutes   int
+	status    string //Outcome of the last action
+	err       error
+}
+
+type librariesLoaded struct {
+	libraries []library
+	err       error
+}
+
+type gamesLoaded struct {
+	games []game
+	err   error
+}
+
+type actionDone struct {
+	status string
+	err    error
+}
+
+func newModel(client *client) model {
+	return model{client: client, minutes: defaultSession}
+}
+
+func (m model) Init() tea.Cmd {
+	return m.loadLibraries
+}
+
+func (m model) loadLibraries() tea.Msg {
+	libraries, err := m.client.libraries()
+	return librariesLoaded{libraries, err}
+}
+
+func (m model) loadGames(libraryId string) tea.Cmd {
+	return func() tea.Msg {
+		games, err := m.client.games(libraryId)
+		return gamesLoaded{games, err}
+	}
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case librariesLoaded:
+		m.libraries, m.err = msg.libraries, msg.err
+		return m, nil
+	case gamesLoaded:
+		m.games, m.err = msg.games, msg.err
+		if m.cursor >= len(m.games) {
+			m.cursor = 0
+		}
+		return m, nil
+	case actionDone:
+		m.status, m.err = msg.status, msg.err
+		if msg.err == nil {
+			return m, m.loadGames(m.library)
+		}
+		return m, nil
+	case tea.KeyMsg:
+		return m.press(msg.String())
+	}
+	return m, nil
+}
+
+func (m model) press(key string) (tea.Model, tea.Cmd) {
+	if key == "ctrl+c" || key == "q" {
+		return m, tea.Quit
+	}
+	switch m.screen {
+	case screenLibraries:
+		switch key {
+		case "up", "k":
+			m.cursor = clamp(m.cursor-1, 0, len(m.libraries)-1)
+		case "down", "j":
+			m.cursor = clamp(m.cursor+1, 0, len(m.libraries)-1)
+		case "enter":
+			if len(m.libraries) > 0 {
+				m.library = m.libraries[m.cursor].Id
+				m.screen, m.cursor, m.games, m.status, m.err = screenGames, 0, nil, "", nil
+				return m, m.loadGames(m.library)
+			}
+		case "r":
+			return m, m.loadLibraries
+		}
+	case screenGames:
+		switch key {
+		case "up", "k":
+			m.cursor = clamp(m.cursor-1, 0, len(m.games)-1)
+		case "down", "j":
+			m.cursor = clamp(m.cursor+1, 0, len(m.games)-1)
+		case "esc", "backspace":
+			m.screen, m.cursor, m.status, m.err = screenLibraries, 0, "", nil
+		case "x", " ":
+			if len(m.games) > 0 {
+				return m, m.toggleCompleted(m.games[m.cursor])
+			}
+		case "y", "enter":
+			if len(m.games) > 0 {
+				m.screen, m.minutes = screenSession, defaultSession
+			}
+		case "r":
+			return m, m.loadGames(m.library)
+		}
+	case screenSession:
+		switch key {
+		case "left", "h", "down", "j":
+			m.minutes = clamp(m.minutes-sessionStep, sessionStep, maxSession)
+		case "right", "l", "up", "k":
+			m.minutes = clamp(m.minutes+sessionStep, sessionStep, maxSession)
+		case "esc", "backspace":
+			m.screen = screenGames
+		case "enter", "y":
+			m.screen = screenGames
+			return m, m.logSession(m.games[m.cursor], m.minutes)
+		}
+	}
+	return m, nil
+}
+
+// Ticks a game off as completed, a completed one goes back to playing
+func (m model) toggleCompleted(selected game) tea.Cmd {
+	library := m.library
+	return func() tea.Msg {
+		status := "completed"
+		if selected.Status == "completed" {
+			status = "playing"
+		}
+		err := m.client.setStatus(library, selected.Id, status)
+		return actionDone{fmt.Sprintf("%s is now %s", selected.Name, status), err}
+	}
+}
+
+func (m model) logSession(selected game, minutes int) tea.Cmd {
+	return func() tea.Msg {
+		err := m.client.logSession(selected.Id, minutes)
+		return actionDone{fmt.Sprintf("Logged %s of %s", duration(minutes), selected.Name), err}
+	}
+}
+
+func (m model) View() string {
+	var view strings.Builder
+	switch m.screen {
+	case screenLibraries:
+		view.WriteString("Libraries\n\n")
+		if len(m.libraries) == 0 && m.err == nil {
+			view.WriteString("  No libraries yet\n")
+		}
+		for i, library := range m.libraries {
+			fmt.Fprintf(&view, "%s Library %s  %s\n", pointer(i == m.cursor), library.Id,
+				library.UpdatedAt)
+		}
+		view.WriteString("\n↑/↓ move · A/enter open · r reload · q quit\n")
+	case screenGames:
+		fmt.Fprintf(&view, "Library %s\n\n", m.library)
+		if len(m.games) == 0 && m.err == nil {
+			view.WriteString("  No games in this library\n")
+		}
+		for i, game := range m.games {
+			tick := "[ ]"
+			if game.Status == "completed" {
+				tick = "[x]"
+			}
+			fmt.Fprintf(&view, "%s %s %s  %s %s\n", pointer(i == m.cursor), tick, game.Name,
+				game.Status, game.Platform)
+		}
+		view.WriteString("\n↑/↓ move · X/space complete · Y/enter log session · B/esc back · q quit\n")
+	case screenSession:
+		fmt.Fprintf(&view, "Log a session of %s\n\n", m.games[m.cursor].Name)
+		fmt.Fprintf(&view, "  ◀ %s ▶\n", duration(m.minutes))
+		view.WriteString("\n←/→ change · A/enter log · B/esc cancel\n")
+	}
+	if m.err != nil {
+		fmt.Fprintf(&view, "\nError: %s\n", m.err)
+	} else if m.status != "" {
+		fmt.Fprintf(&view, "\n%s\n", m.status)
+	}
+	return view.String()
+}
+
+// Keeps value within low and high, low wins on an empty range
+func clamp(value, low, high int) int {
+	if value > high {
+		value = high
+	}
+	if value < low {
+		value = low
+	}
+	return value
+}
+
+func pointer(selected bool) string {
+	if selected {
+		return ">"
+	}
+	return " "
+}
+
+func duration(minutes int) string {
+	if minutes < 60 {
+		return fmt.Sprintf("%d min", minutes)
+	}
+	return fmt.Sprintf("%dh %02dmin", minutes/60, minutes%60)
+}
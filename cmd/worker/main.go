@@ -0,0 +1,47 @@
+// Does the work the API hands off over the message bus of config.json, such
+// as reading the photos of photo imports, so heavy work does not hold up
+// requests. Run as many as the work needs, each message goes to one:
+//
+//	go run ./cmd/worker -config config.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"game-tracker/app/bootstrap"
+)
+
+func main() {
+	configPath := flag.String("config", "config.json", "config file of the API")
+	flag.Parse()
+
+	config, err := bootstrap.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	app, err := bootstrap.Build(config)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	defer app.Close()
+	if app.Interactors.Messaging == nil {
+		fmt.Println("Messaging.Driver is not set, there is no work to take")
+		os.Exit(2)
+	}
+
+	err = app.Interactors.Messaging.Serve()
+	if err != nil {
+		fmt.Println("Cannot subscribe to work", err)
+		os.Exit(1)
+	}
+	fmt.Println("Waiting for work...")
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+}
@@ -0,0 +1,407 @@
+// Replays the requests of cases.json against the API on the in-memory
+// database and compares each response to its golden file in golden/, so a
+// change to what an endpoint answers shows up as a failing case. Needs no
+// database server:
+//
+//	go test ./contracts          # compare
+//	go test ./contracts -update  # rewrite the golden files
+//
+// Values a case captures (ids, tokens), other external ids, tokens and
+// timestamps are replaced by placeholders before comparing. The test fails
+// for every route of the engine no case reaches.
+package contracts
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/app/bootstrap"
+	"game-tracker/domain"
+	"game-tracker/interfaces"
+	"game-tracker/models/postgres"
+	"game-tracker/usecases"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files instead of comparing")
+
+const (
+	casesFile = "contracts/cases.json"
+	goldenDir = "contracts/golden"
+
+	minPlaceholderLength = 8
+)
+
+type contractCase struct {
+	Name    string            `json:"name"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"` //A JSON string is sent as it is, e.g. a form
+	// Variable name to dotted path in the response, or cookie.<name> for a
+	// cookie it sets. A path ending in :token captures the last segment of
+	// the URL there without its extension, for tokens only shown in links.
+	Capture map[string]string `json:"capture"`
+	// Only the status is compared, for bodies that differ on every run such
+	// as runtime figures and PDFs
+	StatusOnly bool `json:"statusOnly"`
+}
+
+type golden struct {
+	Status int         `json:"status"`
+	Body   interface{} `json:"body,omitempty"`
+}
+
+// Runs from the repository root, where config.json and the migrations are
+func TestMain(m *testing.M) {
+	err := os.Chdir("..")
+	if err != nil {
+		fmt.Println("Cannot change to the repository root", err)
+		os.Exit(2)
+	}
+	os.Exit(m.Run())
+}
+
+func TestContracts(t *testing.T) {
+	cases := loadCases(t)
+	app := build(t)
+	vars := seed(t, app)
+
+	var paths []string
+	for _, contract := range cases {
+		path := substitute(contract.Path, vars)
+		paths = append(paths, contract.Method+" "+strings.SplitN(path, "?", 2)[0])
+		t.Run(contract.Name, func(t *testing.T) {
+			actual, err := replay(app, contract, vars)
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(t, filepath.Join(goldenDir, contract.Name+".json"), actual)
+		})
+	}
+
+	for _, route := range uncovered(app.Engine, paths) {
+		t.Errorf("No case covers %s", route)
+	}
+}
+
+func loadCases(t *testing.T) []contractCase {
+	content, err := ioutil.ReadFile(casesFile)
+	if err != nil {
+		t.Fatalf("Cannot read cases: %v", err)
+	}
+	var cases []contractCase
+	err = json.Unmarshal(content, &cases)
+	if err != nil {
+		t.Fatalf("Cannot read cases: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, contract := range cases {
+		if names[contract.Name] {
+			t.Fatalf("Case %s is listed twice", contract.Name)
+		}
+		names[contract.Name] = true
+	}
+	return cases
+}
+
+func build(t *testing.T) *bootstrap.App {
+	config, err := bootstrap.LoadConfig("config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	isolate(&config, t.TempDir())
+	app, err := bootstrap.Build(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		app.Close()
+	})
+	return app
+}
+
+// Keeps the run to process memory, providers that would reach out over the
+// network are left unconfigured
+func isolate(config *postgres.Configuration, blobs string) {
+	config.Database = "memory"
+	config.Redis.Address = ""
+	config.Redis.RequestsPerWindow = 0
+	config.Blobs.Dir = blobs
+	config.Releases.ProviderUrl = ""
+	config.Steam.ApiUrl = ""
+	config.Pricing.ProviderUrl = ""
+	config.Barcodes.ProviderUrl = ""
+	config.Catalogs.ProviderUrl = ""
+	config.Vision.ProviderUrl = ""
+	config.Plugins = nil
+	config.Telemetry.Enabled = false
+	config.Errors.SentryDsn = ""
+	config.Maintenance.Enabled = false
+	config.Tokens.Secret = "contract-secret"
+	config.Scripts.Enabled = true
+}
+
+// Admins are promoted by hand, so the admin the cases sign in as is stored
+// directly. Its id is the variable admin.
+func seed(t *testing.T, app *bootstrap.App) map[string]string {
+	users := app.Repositories.Users
+	id, err := users.Store(usecases.User{Name: "contract-admin",
+		Player: domain.Player{Name: "contract-admin"}})
+	if err != nil {
+		t.Fatalf("Cannot store the admin: %v", err)
+	}
+	err = users.AddLoginInfo("contract-admin", "contract-admin-secret")
+	if err != nil {
+		t.Fatalf("Cannot add the login of the admin: %v", err)
+	}
+	_, err = app.Repositories.Documents.Update("users", interfaces.Document{"_id": id},
+		interfaces.Document{"$set": interfaces.Document{"role": usecases.RoleAdmin}})
+	if err != nil {
+		t.Fatalf("Cannot promote the admin: %v", err)
+	}
+	admin, err, _ := users.FindById(id)
+	if err != nil {
+		t.Fatalf("Cannot load the admin: %v", err)
+	}
+	return map[string]string{"admin": admin.ExternalId}
+}
+
+// Sends the case to the engine, stores what it captures in vars and returns
+// the response with captured values, ids and timestamps replaced
+func replay(app *bootstrap.App, contract contractCase, vars map[string]string) (golden, error) {
+	body := []byte(substitute(string(contract.Body), vars))
+	var raw string
+	if json.Unmarshal(body, &raw) == nil {
+		body = []byte(raw)
+	}
+	request, err := http.NewRequest(contract.Method, substitute(contract.Path, vars),
+		bytes.NewReader(body))
+	if err != nil {
+		return golden{}, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range contract.Headers {
+		request.Header.Set(name, substitute(value, vars))
+	}
+	recorder := httptest.NewRecorder()
+	app.Engine.ServeHTTP(recorder, request)
+
+	var parsed interface{}
+	text := recorder.Body.String()
+	if text != "" && json.Unmarshal([]byte(text), &parsed) != nil {
+		parsed = text //Feeds, calendars and pages are compared as text
+	}
+	for name, path := range contract.Capture {
+		value, found := lookup(recorder.Result(), parsed, path)
+		if !found {
+			return golden{}, fmt.Errorf("Response has nothing at %s to capture as %s:\n%s", path, name,
+				text)
+		}
+		vars[name] = value
+	}
+	if contract.StatusOnly {
+		return golden{Status: recorder.Code}, nil
+	}
+	return golden{Status: recorder.Code, Body: normalize(parsed, vars)}, nil
+}
+
+func substitute(text string, vars map[string]string) string {
+	for name, value := range vars {
+		text = strings.Replace(text, "{{"+name+"}}", value, -1)
+	}
+	return text
+}
+
+// Follows a dotted path such as data.attributes.tokenString, numbers are
+// captured as written
+func lookup(response *http.Response, value interface{}, path string) (string, bool) {
+	if strings.HasSuffix(path, ":token") {
+		url, found := lookup(response, value, strings.TrimSuffix(path, ":token"))
+		if !found {
+			return "", false
+		}
+		token := url[strings.LastIndex(url, "/")+1:]
+		if dot := strings.Index(token, "."); dot >= 0 {
+			token = token[:dot]
+		}
+		return token, token != ""
+	}
+	if strings.HasPrefix(path, "cookie.") {
+		for _, cookie := range response.Cookies() {
+			if cookie.Name == strings.TrimPrefix(path, "cookie.") {
+				return cookie.Value, true
+			}
+		}
+		return "", false
+	}
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		value, ok = object[key]
+		if !ok {
+			return "", false
+		}
+	}
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case float64:
+		return fmt.Sprintf("%v", value), true
+	}
+	return "", false
+}
+
+var (
+	uuidPattern = regexp.MustCompile(
+		`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	jwtPattern  = regexp.MustCompile(`^[\w-]+\.[\w-]+\.[\w-]+$`)
+	datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	// Trace ids, refresh tokens and webhook secrets
+	tokenPattern = regexp.MustCompile(`^[0-9a-f]{32,}$`)
+	// Times inside links and as the web pages print them
+	clockPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}(T\d{2}(:|%3A)\d{2}(:|%3A)\d{2}Z| \d{2}:\d{2})`)
+)
+
+func normalize(value interface{}, vars map[string]string) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			value[key] = normalize(field, vars)
+			// Goals default to the current year
+			if _, ok := field.(float64); ok && key == "year" {
+				value[key] = "{{year}}"
+			}
+		}
+		return value
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalize(item, vars)
+		}
+		return value
+	case string:
+		_, err := time.Parse(time.RFC3339, value)
+		if err == nil {
+			return "{{timestamp}}"
+		}
+		if datePattern.MatchString(value) {
+			return "{{date}}"
+		}
+		if tokenPattern.MatchString(value) {
+			return "{{token}}"
+		}
+		value = clockPattern.ReplaceAllString(placeholders(value, vars), "{{timestamp}}")
+		if jwtPattern.MatchString(value) {
+			return "{{jwt}}"
+		}
+		return uuidPattern.ReplaceAllString(value, "{{uuid}}")
+	}
+	return value
+}
+
+// Replaces captured values in text by their placeholder, longest first so
+// a value containing another is replaced whole. Short values such as numeric
+// ids are left as they are, every run starts from an empty database so they
+// come out the same and replacing them would hit every other number.
+func placeholders(text string, vars map[string]string) string {
+	var names []string
+	for name, value := range vars {
+		if len(value) >= minPlaceholderLength {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(vars[names[i]]) != len(vars[names[j]]) {
+			return len(vars[names[i]]) > len(vars[names[j]])
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		text = strings.Replace(text, vars[name], "{{"+name+"}}", -1)
+	}
+	return text
+}
+
+func check(t *testing.T, path string, actual golden) {
+	encoded, err := json.MarshalIndent(actual, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded = append(encoded, '\n')
+	if *update {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, encoded, 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("No golden file %s, run with -update to record it", path)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected, encoded) {
+		t.Fatalf("Response differs from %s:\n%s", path, encoded)
+	}
+}
+
+// Routes of the engine none of the requested paths matched
+func uncovered(engine *gin.Engine, paths []string) []string {
+	var routes []string
+	for _, route := range engine.Routes() {
+		covered := false
+		for _, path := range paths {
+			if matches(route.Method+" "+route.Path, path) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			routes = append(routes, route.Method+" "+route.Path)
+		}
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// Whether a request such as "GET /users/abc" is served by a route such as
+// "GET /users/:id"
+func matches(route, request string) bool {
+	routeParts := strings.Split(strings.TrimSuffix(route, "/"), "/")
+	requestParts := strings.Split(strings.TrimSuffix(request, "/"), "/")
+	for i, part := range routeParts {
+		if strings.HasPrefix(part, "*") {
+			return len(requestParts) >= i
+		}
+		if i >= len(requestParts) {
+			return false
+		}
+		if strings.HasPrefix(part, ":") && requestParts[i] != "" {
+			continue
+		}
+		if part != requestParts[i] {
+			return false
+		}
+	}
+	return len(routeParts) == len(requestParts)
+}
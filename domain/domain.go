@@ -1,21 +1,29 @@
 package domain
 
+import (
+	"time"
+)
+
 type PlayerRepository interface {
-	Store(player Player) error
+	Store(player Player) (int, error) //Returns the id of the player with that name
 	FindById(id int) (Player, error, int)
-	NameMatchesId(playerName string, id int) (bool, error)
+	FindByName(name string, ignoreCase bool) (Player, error, int)
 }
 
 type Player struct {
-	Id   int
-	Name string
+	Id        int
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 type Game struct {
-	Id       int
-	Name     string
-	Producer string
-	Value    []uint8
+	Id        int
+	Name      string
+	Producer  string
+	Value     []uint8
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 //Business rule: Player names cannot repeat (unique identification)
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"fmt"
+)
+
+type ErrorCode string
+
+const (
+	CodeInvalid      ErrorCode = "invalid_request"
+	CodeUnauthorized ErrorCode = "unauthorized"
+	CodeForbidden    ErrorCode = "forbidden"
+	CodeNotFound     ErrorCode = "not_found"
+	CodeConflict     ErrorCode = "conflict"
+	CodeRateLimited  ErrorCode = "rate_limited"
+	CodeUnavailable  ErrorCode = "unavailable"
+)
+
+// Format and Args are kept so the message can be translated, the English
+// format string is the key of the message catalogs
+type FieldError struct {
+	Field   string
+	Message string
+	Format  string
+	Args    []interface{}
+}
+
+// A business rule violation, the web layer picks the HTTP status from its code
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Format  string
+	Args    []interface{}
+	Fields  []FieldError
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+func NewError(code ErrorCode, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...), Format: format, Args: args}
+}
+
+func NewFieldError(field, format string, args ...interface{}) *Error {
+	message := fmt.Sprintf(format, args...)
+	return &Error{
+		Code:    CodeInvalid,
+		Message: message,
+		Format:  format,
+		Args:    args,
+		Fields:  []FieldError{{Field: field, Message: message, Format: format, Args: args}},
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+const (
+	EventUserRemoved          = "UserRemoved"
+	EventLibraryAdded         = "LibraryAdded"
+	EventLibraryRemoved       = "LibraryRemoved"
+	EventGameAdded            = "GameAdded"
+	EventGameRemoved          = "GameRemoved"
+	EventGameStatusChanged    = "GameStatusChanged"
+	EventReleaseMoved         = "ReleaseMoved"
+	EventReleaseLaunched      = "ReleaseLaunched"
+	EventSessionAdded         = "SessionAdded"
+	EventBadgeEarned          = "BadgeEarned"
+	EventGoalBehind           = "GoalBehind"
+	EventWarrantyExpiring     = "WarrantyExpiring"
+	EventSubscriptionRenewing = "SubscriptionRenewing"
+	EventCatalogLeaving       = "CatalogLeaving"
+	EventBackupStale          = "BackupStale"
+	EventPersonalBest         = "PersonalBest"
+	EventWebhookReceived      = "WebhookReceived"
+	EventRuleFired            = "RuleFired"
+	EventTagsChanged          = "TagsChanged"
+	EventDatabaseFailover     = "DatabaseFailover"
+)
+
+// Every event name, for subscribers that take them all
+var Events = []string{
+	EventUserRemoved,
+	EventLibraryAdded,
+	EventLibraryRemoved,
+	EventGameAdded,
+	EventGameRemoved,
+	EventGameStatusChanged,
+	EventReleaseMoved,
+	EventReleaseLaunched,
+	EventSessionAdded,
+	EventBadgeEarned,
+	EventGoalBehind,
+	EventWarrantyExpiring,
+	EventSubscriptionRenewing,
+	EventCatalogLeaving,
+	EventBackupStale,
+	EventPersonalBest,
+	EventWebhookReceived,
+	EventRuleFired,
+	EventTagsChanged,
+	EventDatabaseFailover,
+}
+
+// Something that happened to an entity owned by a user
+type Event struct {
+	Name     string
+	UserId   int
+	EntityId int
+	Payload  map[string]string
+}
+
+type EventHandler func(event Event)
+
+type EventBus interface {
+	Publish(event Event)
+	Subscribe(name string, handler EventHandler)
+}
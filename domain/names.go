@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+//Business rule: Usernames cannot repeat regardless of letter case or of how
+//their characters are composed, "Alice" and "alice" are the same user
+
+// Brings a name to NFC so names that look the same are stored the same way
+func NormalizeName(name string) string {
+	return norm.NFC.String(strings.TrimSpace(name))
+}
+
+// The form usernames are compared and indexed by
+func NameKey(name string) string {
+	return cases.Fold().String(NormalizeName(name))
+}
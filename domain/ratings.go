@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"strings"
+)
+
+//Business rule: A game's content rating decides the youngest age it is
+//suitable for, ESRB and PEGI ratings are both understood
+
+var ratingAges = map[string]int{
+	"ESRB EC":   3,
+	"ESRB E":    6,
+	"ESRB E10+": 10,
+	"ESRB T":    13,
+	"ESRB M":    17,
+	"ESRB AO":   18,
+	"PEGI 3":    3,
+	"PEGI 7":    7,
+	"PEGI 12":   12,
+	"PEGI 16":   16,
+	"PEGI 18":   18,
+}
+
+// Brings a rating such as "pegi  12" to the form it is stored in, reports
+// false for ratings that are not known
+func NormalizeRating(rating string) (string, bool) {
+	normalized := strings.ToUpper(strings.Join(strings.Fields(rating), " "))
+	_, known := ratingAges[normalized]
+	return normalized, known
+}
+
+// The youngest age a known rating allows, 0 for anything else
+func RatingMinAge(rating string) int {
+	return ratingAges[rating]
+}
@@ -0,0 +1,53 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+// Asks an HTTP barcode database what product a UPC/EAN code is on. The URL
+// holds a {barcode} placeholder and the service answers
+// {"name": "Super Mario Odyssey", "platform": "Nintendo Switch"}, a 404 or
+// an empty name means the barcode is unknown
+type HttpBarcodeProvider struct {
+	urlTemplate string
+	client      *http.Client
+}
+
+type barcodeProduct struct {
+	Name     string `json:"name"`
+	Platform string `json:"platform"`
+}
+
+func NewHttpBarcodeProvider(urlTemplate string) *HttpBarcodeProvider {
+	return &HttpBarcodeProvider{urlTemplate: urlTemplate,
+		client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (provider *HttpBarcodeProvider) Lookup(barcode string) (usecases.BarcodeProduct, bool, error) {
+	address := strings.Replace(provider.urlTemplate, "{barcode}", url.QueryEscape(barcode), -1)
+	response, err := provider.client.Get(address)
+	if err != nil {
+		return usecases.BarcodeProduct{}, false, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return usecases.BarcodeProduct{}, false, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return usecases.BarcodeProduct{}, false, fmt.Errorf("barcode provider answered %s", response.Status)
+	}
+
+	var product barcodeProduct
+	err = json.NewDecoder(response.Body).Decode(&product)
+	if err != nil || product.Name == "" {
+		return usecases.BarcodeProduct{}, false, err
+	}
+	return usecases.BarcodeProduct{Name: product.Name, Platform: product.Platform}, true, nil
+}
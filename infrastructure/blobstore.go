@@ -0,0 +1,77 @@
+package infrastructure
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Keeps blobs as files under a directory, keys map to relative paths
+type FileBlobStore struct {
+	Dir string
+}
+
+func NewFileBlobStore(dir string) (*FileBlobStore, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, err
+	}
+	return &FileBlobStore{Dir: dir}, nil
+}
+
+// Keys cannot climb out of the directory
+func (store *FileBlobStore) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(cleaned) || cleaned == ".." ||
+		strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("Blob key '%s' is invalid", key)
+	}
+	return filepath.Join(store.Dir, cleaned), nil
+}
+
+// Written to a temporary file first so readers never see half a blob
+func (store *FileBlobStore) Put(key string, data []byte) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return err
+	}
+	temporary := path + ".tmp"
+	err = os.WriteFile(temporary, data, 0o640)
+	if err != nil {
+		return err
+	}
+	return os.Rename(temporary, path)
+}
+
+func (store *FileBlobStore) Get(key string) ([]byte, bool, error) {
+	path, err := store.path(key)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Deleting a blob that is gone is not an error
+func (store *FileBlobStore) Delete(key string) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+// Asks an HTTP catalog service which games a subscription includes. The URL
+// holds a {service} placeholder and the service answers
+// {"games": [{"name": "Halo Infinite", "leavesOn": "2026-11-15"}]}, leavesOn
+// is left out for games staying in the catalog
+type HttpCatalogProvider struct {
+	urlTemplate string
+	client      *http.Client
+}
+
+type catalogListing struct {
+	Games []struct {
+		Name     string `json:"name"`
+		LeavesOn string `json:"leavesOn"`
+	} `json:"games"`
+}
+
+func NewHttpCatalogProvider(urlTemplate string) *HttpCatalogProvider {
+	return &HttpCatalogProvider{urlTemplate: urlTemplate,
+		client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (provider *HttpCatalogProvider) Catalog(service string) ([]usecases.CatalogEntry, error) {
+	address := strings.Replace(provider.urlTemplate, "{service}", url.QueryEscape(service), -1)
+	response, err := provider.client.Get(address)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("catalog provider answered %s", response.Status)
+	}
+
+	var listing catalogListing
+	err = json.NewDecoder(response.Body).Decode(&listing)
+	if err != nil {
+		return nil, err
+	}
+	var entries []usecases.CatalogEntry
+	for _, game := range listing.Games {
+		entry := usecases.CatalogEntry{Name: game.Name}
+		if game.LeavesOn != "" {
+			entry.LeavesOn, err = time.Parse("2006-01-02", game.LeavesOn)
+			if err != nil {
+				return nil, fmt.Errorf("catalog provider sent leaving date '%s' for %s", game.LeavesOn, game.Name)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,142 @@
+package infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+// Report templates are text/template files in a small line markup so the
+// same template prints to any renderer:
+//
+//	# Title
+//	## Section heading
+//	| Header | Cells |
+//	| Row    | Cells |
+//
+// Any other line is text, blank lines end a paragraph. {{t "format" args}}
+// translates text to the locale the document is printed in.
+type DocumentTemplates struct {
+	templates  *template.Template
+	translator usecases.Translator
+}
+
+var documentFuncs = template.FuncMap{
+	"t": fmt.Sprintf,
+	"cell": func(value interface{}) string {
+		return strings.ReplaceAll(fmt.Sprint(value), "|", "/")
+	},
+	"money": func(amount float64, currency string) string {
+		return fmt.Sprintf("%.2f %s", amount, currency)
+	},
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format("2006-01-02")
+	},
+	"currencies": func(totals map[string]float64) []string {
+		var currencies []string
+		for currency := range totals {
+			currencies = append(currencies, currency)
+		}
+		sort.Strings(currencies)
+		return currencies
+	},
+}
+
+// Loads every *.tmpl file of dir, a template is named after its file
+// without the extension. A nil translator prints every document in English.
+func LoadDocumentTemplates(dir string, translator usecases.Translator) (*DocumentTemplates, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	templates := template.New("").Funcs(documentFuncs)
+	for _, path := range paths {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".tmpl")
+		_, err = templates.New(name).Parse(string(source))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &DocumentTemplates{templates: templates, translator: translator}, nil
+}
+
+func (documents *DocumentTemplates) Execute(name, locale string, data interface{}) (usecases.Document, error) {
+	if documents.templates.Lookup(name) == nil {
+		return usecases.Document{}, fmt.Errorf("Document template %s does not exist", name)
+	}
+	templates := documents.templates
+	if documents.translator != nil && locale != "" {
+		var err error
+		templates, err = templates.Clone()
+		if err != nil {
+			return usecases.Document{}, err
+		}
+		templates.Funcs(template.FuncMap{"t": func(format string, args ...interface{}) string {
+			return documents.translator.Translate(locale, format, args...)
+		}})
+	}
+	var filled bytes.Buffer
+	err := templates.ExecuteTemplate(&filled, name, data)
+	if err != nil {
+		return usecases.Document{}, err
+	}
+	return parseDocument(filled.String()), nil
+}
+
+func parseDocument(text string) usecases.Document {
+	var document usecases.Document
+	var paragraph []string
+	section := func() *usecases.DocumentSection {
+		if len(document.Sections) == 0 {
+			document.Sections = append(document.Sections, usecases.DocumentSection{})
+		}
+		return &document.Sections[len(document.Sections)-1]
+	}
+	endParagraph := func() {
+		if len(paragraph) > 0 {
+			current := section()
+			current.Paragraphs = append(current.Paragraphs, strings.Join(paragraph, " "))
+			paragraph = nil
+		}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "## "):
+			endParagraph()
+			document.Sections = append(document.Sections,
+				usecases.DocumentSection{Heading: strings.TrimSpace(line[3:])})
+		case strings.HasPrefix(line, "# "):
+			endParagraph()
+			document.Title = strings.TrimSpace(line[2:])
+		case strings.HasPrefix(line, "|"):
+			endParagraph()
+			var cells []string
+			for _, cell := range strings.Split(strings.Trim(line, "|"), "|") {
+				cells = append(cells, strings.TrimSpace(cell))
+			}
+			current := section()
+			current.Table = append(current.Table, cells)
+		case line == "":
+			endParagraph()
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	endParagraph()
+	return document
+}
@@ -0,0 +1,33 @@
+package infrastructure
+
+import (
+	"sync"
+
+	"game-tracker/domain"
+)
+
+type InMemoryEventBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]domain.EventHandler
+}
+
+func NewInMemoryEventBus() *InMemoryEventBus {
+	bus := new(InMemoryEventBus)
+	bus.handlers = make(map[string][]domain.EventHandler)
+	return bus
+}
+
+func (bus *InMemoryEventBus) Publish(event domain.Event) {
+	bus.mu.RLock()
+	handlers := bus.handlers[event.Name]
+	bus.mu.RUnlock()
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+func (bus *InMemoryEventBus) Subscribe(name string, handler domain.EventHandler) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.handlers[name] = append(bus.handlers[name], handler)
+}
@@ -0,0 +1,117 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"game-tracker/interfaces"
+	"game-tracker/interfaces/query"
+)
+
+var errNotRun = errors.New("Statements that write are only explained")
+
+// A statement whose plan reads a table from start to end
+type SeqScan struct {
+	Statement string
+	Relations []string
+}
+
+// Stands in for the database handler of repositories and explains every
+// statement they send with sequential scans turned off, so a plan that still
+// has one found no index to use. Queries are then run, statements that write
+// are not.
+type PlanChecker struct {
+	handler  *PostgresqlHandler
+	SeqScans []SeqScan
+}
+
+func NewPlanChecker(handler *PostgresqlHandler) *PlanChecker {
+	return &PlanChecker{handler: handler}
+}
+
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	RelationName string     `json:"Relation Name"`
+	Plans        []planNode `json:"Plans"`
+}
+
+func (node planNode) seqScans() []string {
+	var relations []string
+	if node.NodeType == "Seq Scan" {
+		relations = append(relations, node.RelationName)
+	}
+	for _, child := range node.Plans {
+		relations = append(relations, child.seqScans()...)
+	}
+	return relations
+}
+
+func (checker *PlanChecker) explain(statement string, args []interface{}) error {
+	tx, err := checker.handler.DB().Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec("SET LOCAL enable_seqscan = off")
+	if err != nil {
+		return err
+	}
+	var plan string
+	err = tx.QueryRow("EXPLAIN (FORMAT JSON) "+statement, args...).Scan(&plan)
+	if err != nil {
+		return err
+	}
+	var plans []struct {
+		Plan planNode `json:"Plan"`
+	}
+	err = json.Unmarshal([]byte(plan), &plans)
+	if err != nil {
+		return err
+	}
+	for _, explained := range plans {
+		relations := explained.Plan.seqScans()
+		if len(relations) > 0 {
+			checker.SeqScans = append(checker.SeqScans, SeqScan{
+				Statement: strings.Join(strings.Fields(statement), " "), Relations: relations})
+		}
+	}
+	return nil
+}
+
+func (checker *PlanChecker) Execute(statement string, args ...interface{}) (sql.Result, error) {
+	err := checker.explain(statement, args)
+	if err != nil {
+		return nil, err
+	}
+	return nil, errNotRun
+}
+
+func (checker *PlanChecker) Query(statement string, args ...interface{}) (interfaces.Row, error) {
+	err := checker.explain(statement, args)
+	if err != nil {
+		return PostgresqlRow{}, err
+	}
+	return checker.handler.Query(statement, args...)
+}
+
+func (checker *PlanChecker) QueryRow(statement string, args ...interface{}) (int, error) {
+	err := checker.explain(statement, args)
+	if err != nil {
+		return 0, err
+	}
+	if !strings.HasPrefix(strings.TrimSpace(strings.ToUpper(statement)), "SELECT") {
+		return 0, errNotRun
+	}
+	return checker.handler.QueryRow(statement, args...)
+}
+
+func (checker *PlanChecker) Dialect() query.Dialect {
+	return query.Postgres
+}
+
+// Statements of a transaction are explained one by one, nothing is committed
+func (checker *PlanChecker) Transaction(fn func(tx interfaces.DbHandler) error) error {
+	return fn(checker)
+}
@@ -0,0 +1,105 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+// Key space of the advisory locks of jobs, the second key is the job name
+// hashed
+const jobLockSpace = 5230
+
+// Names this process in job_runs and Redis claims
+func JobInstance() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s/%d", host, os.Getpid())
+}
+
+// Claims runs with a session advisory lock held while the job runs and
+// job_runs remembering when it last started. A crashed instance loses its
+// lock with its connection.
+type PostgresqlJobLocks struct {
+	handler  *PostgresqlHandler
+	instance string
+}
+
+func NewPostgresqlJobLocks(handler *PostgresqlHandler, instance string) *PostgresqlJobLocks {
+	return &PostgresqlJobLocks{handler: handler, instance: instance}
+}
+
+func (locks *PostgresqlJobLocks) Claim(job string, interval time.Duration) (func(), bool, error) {
+	ctx := context.Background()
+	conn, err := locks.handler.DB().Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var locked bool
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1, hashtext($2))`,
+		jobLockSpace, job).Scan(&locked)
+	if err != nil || !locked {
+		conn.Close()
+		return nil, false, err
+	}
+	unlock := func() {
+		_, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1, hashtext($2))`, jobLockSpace, job)
+		if err != nil {
+			// The lock must not stay with a connection going back to the pool
+			discardConn(conn)
+			return
+		}
+		conn.Close()
+	}
+
+	claimPeriod := usecases.ClaimPeriod(interval).Seconds()
+	var started string
+	err = conn.QueryRowContext(ctx, `INSERT INTO job_runs (job_name, instance, started_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (job_name) DO UPDATE SET instance = EXCLUDED.instance,
+			started_at = EXCLUDED.started_at, finished_at = NULL
+		WHERE job_runs.started_at <= now() - make_interval(secs => $3)
+		RETURNING job_name`, job, locks.instance, claimPeriod).Scan(&started)
+	if err == sql.ErrNoRows {
+		unlock()
+		return nil, false, nil
+	}
+	if err != nil {
+		unlock()
+		return nil, false, err
+	}
+	release := func() {
+		conn.ExecContext(ctx, `UPDATE job_runs SET finished_at = now() WHERE job_name = $1`, job)
+		unlock()
+	}
+	return release, true, nil
+}
+
+// Claims runs with keys that expire after the claim period. A run outlasting
+// it no longer keeps others out.
+type RedisJobLocks struct {
+	handler  *RedisHandler
+	prefix   string
+	instance string
+}
+
+func NewRedisJobLocks(handler *RedisHandler, prefix, instance string) *RedisJobLocks {
+	return &RedisJobLocks{handler: handler, prefix: prefix, instance: instance}
+}
+
+func (locks *RedisJobLocks) Claim(job string, interval time.Duration) (func(), bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	claimed, err := locks.handler.Client.SetNX(ctx, locks.prefix+job, locks.instance,
+		usecases.ClaimPeriod(interval)).Result()
+	if err != nil || !claimed {
+		return nil, false, err
+	}
+	return func() {}, true, nil
+}
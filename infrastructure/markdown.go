@@ -0,0 +1,33 @@
+package infrastructure
+
+import (
+	"bytes"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Renders GitHub flavored Markdown, whatever HTML the author wrote is
+// stripped by the sanitizer afterwards
+type MarkdownRenderer struct {
+	markdown goldmark.Markdown
+	policy   *bluemonday.Policy
+}
+
+func NewMarkdownRenderer() *MarkdownRenderer {
+	policy := bluemonday.UGCPolicy()
+	policy.RequireNoFollowOnLinks(true)
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+	return &MarkdownRenderer{markdown: goldmark.New(goldmark.WithExtensions(extension.GFM)),
+		policy: policy}
+}
+
+func (renderer *MarkdownRenderer) Render(source string) (string, error) {
+	var rendered bytes.Buffer
+	err := renderer.markdown.Convert([]byte(source), &rendered)
+	if err != nil {
+		return "", err
+	}
+	return string(renderer.policy.SanitizeBytes(rendered.Bytes())), nil
+}
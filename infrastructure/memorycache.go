@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// Stands in for Redis when none is configured, entries live as long as the process
+type InMemoryCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+func NewInMemoryCache(ttl time.Duration) *InMemoryCache {
+	cache := new(InMemoryCache)
+	cache.ttl = ttl
+	cache.entries = make(map[string]cacheEntry)
+	return cache
+}
+
+func (cache *InMemoryCache) Get(key string) ([]byte, bool, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	entry, ok := cache.live(key)
+	return entry.value, ok, nil
+}
+
+func (cache *InMemoryCache) Set(key string, value []byte) error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.entries[key] = cacheEntry{value: value, expires: time.Now().Add(cache.ttl)}
+	return nil
+}
+
+func (cache *InMemoryCache) Delete(key string) error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	delete(cache.entries, key)
+	return nil
+}
+
+func (cache *InMemoryCache) Take(key string) ([]byte, bool, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	entry, ok := cache.live(key)
+	delete(cache.entries, key)
+	return entry.value, ok, nil
+}
+
+func (cache *InMemoryCache) Increment(key string) (int64, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	entry, ok := cache.live(key)
+	if !ok {
+		entry = cacheEntry{expires: time.Now().Add(cache.ttl)}
+	}
+	count, _ := strconv.ParseInt(string(entry.value), 10, 64)
+	count++
+	entry.value = []byte(strconv.FormatInt(count, 10))
+	cache.entries[key] = entry
+	return count, nil
+}
+
+// Drops the entry when it expired, callers hold the lock
+func (cache *InMemoryCache) live(key string) (cacheEntry, bool) {
+	entry, ok := cache.entries[key]
+	if ok && time.Now().After(entry.expires) {
+		delete(cache.entries, key)
+		return cacheEntry{}, false
+	}
+	return entry, ok
+}
+
+// Entries not expired yet, expired ones are dropped while counting
+func (cache *InMemoryCache) Len() int {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	count := 0
+	for key := range cache.entries {
+		if _, ok := cache.live(key); ok {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,774 @@
+package infrastructure
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"game-tracker/interfaces"
+)
+
+// A DocumentHandler keeping its collections in process memory, for runs
+// without a database such as the contract tests. It understands the query
+// and update operators the Mongo repositories use and enforces the unique
+// indexes of mongoIndexes. Documents go through BSON on the way in and out,
+// so they come back as they would from Mongo.
+type MemoryHandler struct {
+	mutex       sync.Mutex
+	collections map[string][]bson.M
+	counters    map[string]int64
+	unique      map[string][][]string //Collection to the fields of each unique index
+}
+
+func NewMemoryHandler() *MemoryHandler {
+	unique := make(map[string][][]string)
+	for _, index := range mongoIndexes {
+		if !index.unique {
+			continue
+		}
+		var fields []string
+		for _, key := range index.keys {
+			fields = append(fields, key.Key)
+		}
+		unique[index.collection] = append(unique[index.collection], fields)
+	}
+	return &MemoryHandler{collections: make(map[string][]bson.M), counters: make(map[string]int64),
+		unique: unique}
+}
+
+func (handler *MemoryHandler) Insert(collection string, document interface{}) error {
+	doc, err := memoryDocument(document)
+	if err != nil {
+		return err
+	}
+	if _, ok := doc["_id"]; !ok {
+		doc["_id"] = primitive.NewObjectID()
+	}
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	if handler.duplicate(collection, doc, -1) {
+		return interfaces.ErrDuplicateDocument
+	}
+	handler.collections[collection] = append(handler.collections[collection], doc)
+	return nil
+}
+
+func (handler *MemoryHandler) FindOne(collection string, filter interfaces.Document, result interface{}) (bool, error) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	index, err := handler.first(collection, filter)
+	if err != nil || index < 0 {
+		return false, err
+	}
+	return true, decodeDocument(handler.collections[collection][index], result)
+}
+
+func (handler *MemoryHandler) Find(collection string, filter interfaces.Document,
+	query interfaces.FindOptions, results interface{}) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	matched, err := handler.matching(collection, filter)
+	if err != nil {
+		return err
+	}
+	var found []bson.M
+	for _, index := range matched {
+		found = append(found, handler.collections[collection][index])
+	}
+	if len(query.Sort) > 0 {
+		sort.SliceStable(found, func(i, j int) bool {
+			return sortsBefore(found[i], found[j], query.Sort)
+		})
+	}
+	if query.Skip >= len(found) {
+		found = nil
+	} else {
+		found = found[query.Skip:]
+	}
+	if query.Limit > 0 && len(found) > query.Limit {
+		found = found[:query.Limit]
+	}
+
+	slice := reflect.ValueOf(results).Elem()
+	items := reflect.MakeSlice(slice.Type(), 0, len(found))
+	for _, doc := range found {
+		item := reflect.New(slice.Type().Elem())
+		err := decodeDocument(doc, item.Interface())
+		if err != nil {
+			return err
+		}
+		items = reflect.Append(items, item.Elem())
+	}
+	slice.Set(items)
+	return nil
+}
+
+// Applies update to every matching document, returns how many matched. A
+// document that would break a unique index stops the update there, as
+// UpdateMany does.
+func (handler *MemoryHandler) Update(collection string, filter, update interfaces.Document) (int64, error) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	matched, err := handler.matching(collection, filter)
+	if err != nil {
+		return 0, err
+	}
+	for _, index := range matched {
+		_, err := handler.update(collection, index, update)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(matched)), nil
+}
+
+// Updates the first matching document and decodes it as it is after the update
+func (handler *MemoryHandler) FindOneAndUpdate(collection string, filter, update interfaces.Document,
+	result interface{}) (bool, error) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	index, err := handler.first(collection, filter)
+	if err != nil || index < 0 {
+		return false, err
+	}
+	doc, err := handler.update(collection, index, update)
+	if err != nil {
+		return false, err
+	}
+	return true, decodeDocument(doc, result)
+}
+
+// Replaces the first matching document, or inserts document when none
+// matches. Like Mongo, only the _id of the filter carries over to the insert.
+func (handler *MemoryHandler) Upsert(collection string, filter interfaces.Document, document interface{}) error {
+	doc, err := memoryDocument(document)
+	if err != nil {
+		return err
+	}
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	index, err := handler.first(collection, filter)
+	if err != nil {
+		return err
+	}
+	if index >= 0 {
+		if _, ok := doc["_id"]; !ok {
+			doc["_id"] = handler.collections[collection][index]["_id"]
+		}
+		if handler.duplicate(collection, doc, index) {
+			return interfaces.ErrDuplicateDocument
+		}
+		handler.collections[collection][index] = doc
+		return nil
+	}
+	if _, ok := doc["_id"]; !ok {
+		id, err := memoryValue(filter["_id"])
+		if err != nil {
+			return err
+		}
+		if id == nil {
+			id = primitive.NewObjectID()
+		}
+		doc["_id"] = id
+	}
+	if handler.duplicate(collection, doc, -1) {
+		return interfaces.ErrDuplicateDocument
+	}
+	handler.collections[collection] = append(handler.collections[collection], doc)
+	return nil
+}
+
+func (handler *MemoryHandler) Delete(collection string, filter interfaces.Document) (int64, error) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	matched, err := handler.matching(collection, filter)
+	if err != nil {
+		return 0, err
+	}
+	removed := make(map[int]bool)
+	for _, index := range matched {
+		removed[index] = true
+	}
+	var kept []bson.M
+	for index, doc := range handler.collections[collection] {
+		if !removed[index] {
+			kept = append(kept, doc)
+		}
+	}
+	handler.collections[collection] = kept
+	return int64(len(matched)), nil
+}
+
+func (handler *MemoryHandler) Count(collection string, filter interfaces.Document) (int64, error) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	matched, err := handler.matching(collection, filter)
+	return int64(len(matched)), err
+}
+
+func (handler *MemoryHandler) NextSequence(name string) (int64, error) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	handler.counters[name]++
+	return handler.counters[name], nil
+}
+
+// Positions of the documents matching filter, in insertion order
+func (handler *MemoryHandler) matching(collection string, filter interfaces.Document) ([]int, error) {
+	query, err := memoryDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+	var matched []int
+	for index, doc := range handler.collections[collection] {
+		ok, err := matchesQuery(doc, query)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, index)
+		}
+	}
+	return matched, nil
+}
+
+// Position of the first document matching filter, -1 if there is none
+func (handler *MemoryHandler) first(collection string, filter interfaces.Document) (int, error) {
+	matched, err := handler.matching(collection, filter)
+	if err != nil || len(matched) == 0 {
+		return -1, err
+	}
+	return matched[0], nil
+}
+
+// Applies update to a copy of the document at index and stores the copy
+// unless it breaks a unique index
+func (handler *MemoryHandler) update(collection string, index int, update interfaces.Document) (bson.M, error) {
+	operators, err := memoryDocument(update)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := memoryDocument(handler.collections[collection][index])
+	if err != nil {
+		return nil, err
+	}
+	err = applyUpdate(doc, operators)
+	if err != nil {
+		return nil, err
+	}
+	if handler.duplicate(collection, doc, index) {
+		return nil, interfaces.ErrDuplicateDocument
+	}
+	handler.collections[collection][index] = doc
+	return doc, nil
+}
+
+// Whether doc has the _id or the keys of a unique index of another document
+// than the one at skip. Missing fields count as null, as they do in Mongo.
+func (handler *MemoryHandler) duplicate(collection string, doc bson.M, skip int) bool {
+	indexes := append([][]string{{"_id"}}, handler.unique[collection]...)
+	for index, other := range handler.collections[collection] {
+		if index == skip {
+			continue
+		}
+		for _, fields := range indexes {
+			same := true
+			for _, field := range fields {
+				if compareValues(fieldValue(doc, field), fieldValue(other, field)) != 0 {
+					same = false
+					break
+				}
+			}
+			if same {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// The document as the driver would store it, nested documents as bson.M and
+// arrays as primitive.A
+func memoryDocument(document interface{}) (bson.M, error) {
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	err = bson.Unmarshal(raw, &doc)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeValue(doc).(bson.M), nil
+}
+
+func memoryValue(value interface{}) (interface{}, error) {
+	doc, err := memoryDocument(bson.M{"value": value})
+	if err != nil {
+		return nil, err
+	}
+	return doc["value"], nil
+}
+
+func normalizeValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case bson.M:
+		for key, field := range typed {
+			typed[key] = normalizeValue(field)
+		}
+		return typed
+	case bson.D:
+		doc := bson.M{}
+		for _, field := range typed {
+			doc[field.Key] = normalizeValue(field.Value)
+		}
+		return doc
+	case bson.A:
+		for i, item := range typed {
+			typed[i] = normalizeValue(item)
+		}
+		return typed
+	}
+	return value
+}
+
+func decodeDocument(doc bson.M, result interface{}) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(raw, result)
+}
+
+func matchesQuery(doc bson.M, query bson.M) (bool, error) {
+	for key, condition := range query {
+		var ok bool
+		var err error
+		switch key {
+		case "$and", "$or":
+			clauses, _ := condition.(bson.A)
+			ok = key == "$and"
+			for _, clause := range clauses {
+				sub, _ := clause.(bson.M)
+				matched, err := matchesQuery(doc, sub)
+				if err != nil {
+					return false, err
+				}
+				if matched != ok {
+					ok = matched
+					break
+				}
+			}
+		default:
+			if strings.HasPrefix(key, "$") {
+				return false, fmt.Errorf("Memory handler does not support %s", key)
+			}
+			ok, err = matchesCondition(fieldValues(doc, strings.Split(key, ".")), condition)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Whether the values found at a field path meet condition, either a value
+// to equal or a document of operators
+func matchesCondition(values []interface{}, condition interface{}) (bool, error) {
+	operators, ok := condition.(bson.M)
+	if !ok || !isOperators(operators) {
+		return equalsAny(values, condition), nil
+	}
+	for operator, arg := range operators {
+		var ok bool
+		switch operator {
+		case "$ne":
+			ok = !equalsAny(values, arg)
+		case "$in":
+			items, _ := arg.(bson.A)
+			for _, item := range items {
+				if equalsAny(values, item) {
+					ok = true
+					break
+				}
+			}
+		case "$gt", "$gte", "$lt", "$lte":
+			for _, value := range expand(values) {
+				if typeRank(value) != typeRank(arg) {
+					continue
+				}
+				order := compareValues(value, arg)
+				if operator == "$gt" && order > 0 || operator == "$gte" && order >= 0 ||
+					operator == "$lt" && order < 0 || operator == "$lte" && order <= 0 {
+					ok = true
+					break
+				}
+			}
+		case "$exists":
+			exists, _ := arg.(bool)
+			ok = (len(values) > 0) == exists
+		case "$regex":
+			pattern, _ := arg.(string)
+			if options, _ := operators["$options"].(string); strings.Contains(options, "i") {
+				pattern = "(?i)" + pattern
+			}
+			expression, err := regexp.Compile(pattern)
+			if err != nil {
+				return false, err
+			}
+			for _, value := range expand(values) {
+				if text, isText := value.(string); isText && expression.MatchString(text) {
+					ok = true
+					break
+				}
+			}
+		case "$options":
+			ok = true
+		case "$elemMatch":
+			sub, _ := arg.(bson.M)
+			for _, value := range values {
+				items, _ := value.(bson.A)
+				for _, item := range items {
+					matched, err := matchesElement(item, sub)
+					if err != nil {
+						return false, err
+					}
+					if matched {
+						ok = true
+						break
+					}
+				}
+			}
+		default:
+			return false, fmt.Errorf("Memory handler does not support %s", operator)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Array elements are matched as documents, or as values when the condition
+// is only operators
+func matchesElement(item interface{}, condition bson.M) (bool, error) {
+	if isOperators(condition) {
+		return matchesCondition([]interface{}{item}, condition)
+	}
+	doc, ok := item.(bson.M)
+	if !ok {
+		return false, nil
+	}
+	return matchesQuery(doc, condition)
+}
+
+func isOperators(doc bson.M) bool {
+	for key := range doc {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return len(doc) > 0
+}
+
+// The values at path, following it into every element of the arrays on
+// the way. A numeric part also indexes into an array.
+func fieldValues(value interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		return []interface{}{value}
+	}
+	switch typed := value.(type) {
+	case bson.M:
+		field, ok := typed[path[0]]
+		if !ok {
+			return nil
+		}
+		return fieldValues(field, path[1:])
+	case bson.A:
+		var values []interface{}
+		if position, err := strconv.Atoi(path[0]); err == nil {
+			if position >= 0 && position < len(typed) {
+				values = append(values, fieldValues(typed[position], path[1:])...)
+			}
+			return values
+		}
+		for _, item := range typed {
+			if _, ok := item.(bson.M); ok {
+				values = append(values, fieldValues(item, path)...)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// The value at a dotted path, nil when it is missing
+func fieldValue(doc bson.M, path string) interface{} {
+	values := fieldValues(doc, strings.Split(path, "."))
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+// Arrays match a condition as a whole and by each of their elements
+func expand(values []interface{}) []interface{} {
+	var expanded []interface{}
+	for _, value := range values {
+		expanded = append(expanded, value)
+		if items, ok := value.(bson.A); ok {
+			expanded = append(expanded, items...)
+		}
+	}
+	return expanded
+}
+
+// A missing field equals null
+func equalsAny(values []interface{}, target interface{}) bool {
+	if target == nil && len(values) == 0 {
+		return true
+	}
+	for _, value := range expand(values) {
+		if compareValues(value, target) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func sortsBefore(a, b bson.M, fields []string) bool {
+	for _, field := range fields {
+		descending := strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(field, "-")
+		order := compareValues(fieldValue(a, field), fieldValue(b, field))
+		if order == 0 {
+			continue
+		}
+		return order < 0 != descending
+	}
+	return false
+}
+
+// Mongo's order of BSON types: null, numbers, strings, documents, arrays,
+// binary data, object ids, booleans and dates
+func typeRank(value interface{}) int {
+	switch value.(type) {
+	case nil, primitive.Null, primitive.Undefined:
+		return 1
+	case int32, int64, float64:
+		return 2
+	case string:
+		return 3
+	case bson.M:
+		return 4
+	case bson.A:
+		return 5
+	case primitive.Binary:
+		return 6
+	case primitive.ObjectID:
+		return 7
+	case bool:
+		return 8
+	case primitive.DateTime:
+		return 9
+	}
+	return 10
+}
+
+func compareValues(a, b interface{}) int {
+	rankA, rankB := typeRank(a), typeRank(b)
+	if rankA != rankB {
+		return compareInts(int64(rankA), int64(rankB))
+	}
+	switch x := a.(type) {
+	case int32, int64, float64:
+		return compareFloats(toFloat(x), toFloat(b))
+	case string:
+		return strings.Compare(x, b.(string))
+	case bson.M:
+		y := b.(bson.M)
+		keysA, keysB := sortedKeys(x), sortedKeys(y)
+		for i := 0; i < len(keysA) && i < len(keysB); i++ {
+			if order := strings.Compare(keysA[i], keysB[i]); order != 0 {
+				return order
+			}
+			if order := compareValues(x[keysA[i]], y[keysB[i]]); order != 0 {
+				return order
+			}
+		}
+		return compareInts(int64(len(keysA)), int64(len(keysB)))
+	case bson.A:
+		y := b.(bson.A)
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if order := compareValues(x[i], y[i]); order != 0 {
+				return order
+			}
+		}
+		return compareInts(int64(len(x)), int64(len(y)))
+	case primitive.Binary:
+		return strings.Compare(string(x.Data), string(b.(primitive.Binary).Data))
+	case primitive.ObjectID:
+		y := b.(primitive.ObjectID)
+		return strings.Compare(string(x[:]), string(y[:]))
+	case bool:
+		y := b.(bool)
+		if x == y {
+			return 0
+		}
+		if !x {
+			return -1
+		}
+		return 1
+	case primitive.DateTime:
+		return compareInts(int64(x), int64(b.(primitive.DateTime)))
+	}
+	if reflect.DeepEqual(a, b) {
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(value interface{}) float64 {
+	switch typed := value.(type) {
+	case int32:
+		return float64(typed)
+	case int64:
+		return float64(typed)
+	case float64:
+		return typed
+	}
+	return 0
+}
+
+func toInt(value interface{}) int64 {
+	switch typed := value.(type) {
+	case int32:
+		return int64(typed)
+	case int64:
+		return typed
+	}
+	return 0
+}
+
+func sortedKeys(doc bson.M) []string {
+	var keys []string
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func applyUpdate(doc bson.M, update bson.M) error {
+	for operator, arg := range update {
+		fields, ok := arg.(bson.M)
+		if !ok {
+			return fmt.Errorf("Memory handler expects a document for %s", operator)
+		}
+		for path, value := range fields {
+			parent, key := fieldParent(doc, path)
+			switch operator {
+			case "$set":
+				parent[key] = value
+			case "$unset":
+				delete(parent, key)
+			case "$inc":
+				parent[key] = addNumbers(parent[key], value)
+			case "$push", "$addToSet":
+				items, _ := parent[key].(bson.A)
+				added := bson.A{value}
+				if each, ok := value.(bson.M); ok && each["$each"] != nil {
+					added, _ = each["$each"].(bson.A)
+				}
+				for _, item := range added {
+					if operator == "$addToSet" && equalsAny(items, item) {
+						continue
+					}
+					items = append(items, item)
+				}
+				parent[key] = items
+			case "$pull":
+				items, _ := parent[key].(bson.A)
+				kept := bson.A{}
+				for _, item := range items {
+					pulled := compareValues(item, value) == 0
+					if condition, ok := value.(bson.M); ok {
+						var err error
+						pulled, err = matchesElement(item, condition)
+						if err != nil {
+							return err
+						}
+					}
+					if !pulled {
+						kept = append(kept, item)
+					}
+				}
+				if _, ok := parent[key]; ok {
+					parent[key] = kept
+				}
+			default:
+				return fmt.Errorf("Memory handler does not support %s", operator)
+			}
+		}
+	}
+	return nil
+}
+
+// The document holding the last part of a dotted path and that part,
+// creating the documents on the way
+func fieldParent(doc bson.M, path string) (bson.M, string) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := doc[part].(bson.M)
+		if !ok {
+			next = bson.M{}
+			doc[part] = next
+		}
+		doc = next
+	}
+	return doc, parts[len(parts)-1]
+}
+
+// Sums like Mongo's $inc, staying with 32 bit integers while the sum fits
+func addNumbers(current, delta interface{}) interface{} {
+	_, floatA := current.(float64)
+	_, floatB := delta.(float64)
+	if floatA || floatB {
+		return toFloat(current) + toFloat(delta)
+	}
+	sum := toInt(current) + toInt(delta)
+	_, longA := current.(int64)
+	_, longB := delta.(int64)
+	if longA || longB || sum > math.MaxInt32 || sum < math.MinInt32 {
+		return sum
+	}
+	return int32(sum)
+}
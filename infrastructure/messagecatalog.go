@@ -0,0 +1,98 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"game-tracker/usecases"
+)
+
+// Translations of the English format strings, one JSON object per locale
+// in files such as de.json. English needs no file.
+type MessageCatalogs struct {
+	catalogs map[string]map[string]string
+}
+
+func LoadMessageCatalogs(dir string) (*MessageCatalogs, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	catalogs := map[string]map[string]string{usecases.DefaultLocale: {}}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var catalog map[string]string
+		err = json.Unmarshal(content, &catalog)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read %s: %v", path, err)
+		}
+		catalogs[strings.ToLower(strings.TrimSuffix(filepath.Base(path), ".json"))] = catalog
+	}
+	return &MessageCatalogs{catalogs: catalogs}, nil
+}
+
+func (catalogs *MessageCatalogs) Translate(locale, format string, args ...interface{}) string {
+	if translated, ok := catalogs.catalogs[locale][format]; ok && translated != "" {
+		format = translated
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+func (catalogs *MessageCatalogs) Lookup(locale, text string) string {
+	if translated, ok := catalogs.catalogs[locale][text]; ok && translated != "" {
+		return translated
+	}
+	return text
+}
+
+func (catalogs *MessageCatalogs) Supports(locale string) bool {
+	_, ok := catalogs.catalogs[locale]
+	return ok
+}
+
+func (catalogs *MessageCatalogs) Locales() []string {
+	var locales []string
+	for locale := range catalogs.catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// The supported locale the client prefers most, by q value and then by
+// order. Regional tags such as de-AT fall back to their language.
+func (catalogs *MessageCatalogs) Match(acceptLanguage string) string {
+	best, bestQuality := usecases.DefaultLocale, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				parsed, err := strconv.ParseFloat(param[2:], 64)
+				if err == nil {
+					quality = parsed
+				}
+			}
+		}
+		if quality <= bestQuality {
+			continue
+		}
+		for _, locale := range []string{tag, strings.SplitN(tag, "-", 2)[0]} {
+			if catalogs.Supports(locale) {
+				best, bestQuality = locale, quality
+				break
+			}
+		}
+	}
+	return best
+}
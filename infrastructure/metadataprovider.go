@@ -0,0 +1,71 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Asks an HTTP metadata service for release dates and content ratings. The
+// URL holds a {name} placeholder and the service answers
+// {"releaseDate": "YYYY-MM-DD", "rating": "PEGI 12"}, a 404 or an empty
+// field means the value is unknown
+type HttpMetadataProvider struct {
+	urlTemplate string
+	client      *http.Client
+}
+
+type gameMetadata struct {
+	ReleaseDate string `json:"releaseDate"`
+	Rating      string `json:"rating"`
+}
+
+func NewHttpMetadataProvider(urlTemplate string) *HttpMetadataProvider {
+	return &HttpMetadataProvider{urlTemplate: urlTemplate,
+		client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (provider *HttpMetadataProvider) lookup(gameName string) (gameMetadata, bool, error) {
+	address := strings.Replace(provider.urlTemplate, "{name}", url.QueryEscape(gameName), -1)
+	response, err := provider.client.Get(address)
+	if err != nil {
+		return gameMetadata{}, false, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return gameMetadata{}, false, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return gameMetadata{}, false, fmt.Errorf("metadata provider answered %s", response.Status)
+	}
+
+	var metadata gameMetadata
+	err = json.NewDecoder(response.Body).Decode(&metadata)
+	if err != nil {
+		return gameMetadata{}, false, err
+	}
+	return metadata, true, nil
+}
+
+func (provider *HttpMetadataProvider) ReleaseDate(gameName string) (time.Time, bool, error) {
+	metadata, found, err := provider.lookup(gameName)
+	if err != nil || !found || metadata.ReleaseDate == "" {
+		return time.Time{}, false, err
+	}
+	date, err := time.Parse("2006-01-02", metadata.ReleaseDate)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("metadata provider sent an invalid date: %s", err)
+	}
+	return date, true, nil
+}
+
+func (provider *HttpMetadataProvider) Rating(gameName string) (string, bool, error) {
+	metadata, found, err := provider.lookup(gameName)
+	if err != nil || !found || metadata.Rating == "" {
+		return "", false, err
+	}
+	return metadata.Rating, true, nil
+}
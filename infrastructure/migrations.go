@@ -0,0 +1,167 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"game-tracker/domain"
+)
+
+// Work SQL cannot do, run in the transaction of the named file right after it
+var migrationSteps = map[string]func(tx *sql.Tx) error{
+	"0064_user_name_keys_fold.sql": foldUserNameKeys,
+}
+
+// Applies every *.sql file in dir that has not been applied yet, in name order
+func Migrate(handler *PostgresqlHandler, dir string) error {
+	_, err := handler.Execute(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		name TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now())`)
+	if err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		applied, err := migrationApplied(handler, name)
+		if err != nil {
+			return err
+		}
+		if applied {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		tx, err := handler.DB().Begin()
+		if err != nil {
+			return err
+		}
+		// Rewriting big tables may take longer than Queries.Timeout allows
+		_, err = tx.Exec("SET LOCAL statement_timeout = 0")
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		_, err = tx.Exec(string(content))
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("Migration %s failed: %v", name, err)
+		}
+		if step, ok := migrationSteps[name]; ok {
+			err = step(tx)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("Migration %s failed: %v", name, err)
+			}
+		}
+		_, err = tx.Exec(`INSERT INTO schema_migrations (name) VALUES ($1)`, name)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied migration %s\n", strings.TrimSuffix(name, ".sql"))
+	}
+	return nil
+}
+
+func migrationApplied(handler *PostgresqlHandler, name string) (bool, error) {
+	row, err := handler.Query(`SELECT name FROM schema_migrations WHERE name=$1 LIMIT 1`, name)
+	if err != nil {
+		return false, err
+	}
+	defer row.Close()
+	return row.Next(), nil
+}
+
+// Rewrites every user_name_key with domain.NameKey. As in 0013 the oldest
+// user keeps a name, later users whose names now share its key get their
+// id appended to both the username and the login, and a counter on top
+// while that is taken too.
+func foldUserNameKeys(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, user_name FROM users ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	type user struct {
+		id   int
+		name string
+	}
+	var users []user
+	names := make(map[string]bool)
+	for rows.Next() {
+		var u user
+		err = rows.Scan(&u.id, &u.name)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		users = append(users, u)
+		names[u.name] = true
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = tx.Query(`SELECT username FROM loginInfo`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var login string
+		err = rows.Scan(&login)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		names[login] = true
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	keys := make(map[string]bool)
+	for _, u := range users {
+		name := u.name
+		if keys[domain.NameKey(name)] {
+			base := u.name + "-" + strconv.Itoa(u.id)
+			name = base
+			for suffix := 2; names[name] || keys[domain.NameKey(name)]; suffix++ {
+				name = base + "-" + strconv.Itoa(suffix)
+			}
+			names[name] = true
+			_, err = tx.Exec(`UPDATE loginInfo SET username = $1 WHERE username = $2`, name, u.name)
+			if err != nil {
+				return err
+			}
+		}
+		key := domain.NameKey(name)
+		keys[key] = true
+		_, err = tx.Exec(`UPDATE users SET user_name = $1, user_name_key = $2 WHERE id = $3`,
+			name, key, u.id)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(`CREATE UNIQUE INDEX users_user_name_key_idx ON users (user_name_key)`)
+	return err
+}
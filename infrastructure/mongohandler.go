@@ -0,0 +1,149 @@
+package infrastructure
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"game-tracker/interfaces"
+)
+
+const mongoTimeout = 10 * time.Second
+
+type MongoHandler struct {
+	Client   *mongo.Client
+	Database *mongo.Database
+}
+
+func (handler *MongoHandler) Insert(collection string, document interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	_, err := handler.Database.Collection(collection).InsertOne(ctx, document)
+	if mongo.IsDuplicateKeyError(err) {
+		return interfaces.ErrDuplicateDocument
+	}
+	return err
+}
+
+func (handler *MongoHandler) FindOne(collection string, filter interfaces.Document, result interface{}) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	err := handler.Database.Collection(collection).FindOne(ctx, bson.M(filter)).Decode(result)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (handler *MongoHandler) Find(collection string, filter interfaces.Document,
+	query interfaces.FindOptions, results interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	opts := options.Find().SetSkip(int64(query.Skip))
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit))
+	}
+	if len(query.Sort) > 0 {
+		sort := bson.D{}
+		for _, field := range query.Sort {
+			if strings.HasPrefix(field, "-") {
+				sort = append(sort, bson.E{Key: field[1:], Value: -1})
+			} else {
+				sort = append(sort, bson.E{Key: field, Value: 1})
+			}
+		}
+		opts.SetSort(sort)
+	}
+	cursor, err := handler.Database.Collection(collection).Find(ctx, bson.M(filter), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, results)
+}
+
+// Applies update to every matching document, returns how many matched
+func (handler *MongoHandler) Update(collection string, filter, update interfaces.Document) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	res, err := handler.Database.Collection(collection).UpdateMany(ctx, bson.M(filter), bson.M(update))
+	if err != nil {
+		return 0, err
+	}
+	return res.MatchedCount, nil
+}
+
+// Updates the first matching document and decodes it as it is after the update
+func (handler *MongoHandler) FindOneAndUpdate(collection string, filter, update interfaces.Document,
+	result interface{}) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := handler.Database.Collection(collection).FindOneAndUpdate(ctx, bson.M(filter),
+		bson.M(update), opts).Decode(result)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return false, interfaces.ErrDuplicateDocument
+	}
+	return err == nil, err
+}
+
+func (handler *MongoHandler) Upsert(collection string, filter interfaces.Document, document interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	_, err := handler.Database.Collection(collection).ReplaceOne(ctx, bson.M(filter), document,
+		options.Replace().SetUpsert(true))
+	return err
+}
+
+func (handler *MongoHandler) Delete(collection string, filter interfaces.Document) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	res, err := handler.Database.Collection(collection).DeleteMany(ctx, bson.M(filter))
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+func (handler *MongoHandler) Count(collection string, filter interfaces.Document) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	return handler.Database.Collection(collection).CountDocuments(ctx, bson.M(filter))
+}
+
+// Hands out sequential ids from the counters collection, Mongo has no SERIAL
+func (handler *MongoHandler) NextSequence(name string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := handler.Database.Collection("counters").FindOneAndUpdate(ctx, bson.M{"_id": name},
+		bson.M{"$inc": bson.M{"seq": 1}}, opts).Decode(&counter)
+	return counter.Seq, err
+}
+
+func NewMongoHandler(uri, database string) (*MongoHandler, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return new(MongoHandler), err
+	}
+	err = client.Ping(ctx, nil)
+	if err != nil {
+		return new(MongoHandler), err
+	}
+	mongoHandler := new(MongoHandler)
+	mongoHandler.Client = client
+	mongoHandler.Database = client.Database(database)
+	return mongoHandler, nil
+}
@@ -0,0 +1,106 @@
+package infrastructure
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoIndex struct {
+	collection string
+	keys       bson.D
+	unique     bool
+}
+
+// The document counterpart of the SQL migrations, creating an index that
+// already exists is a no-op so this runs on every start
+var mongoIndexes = []mongoIndex{
+	{"users", bson.D{{Key: "external_id", Value: 1}}, true},
+	{"users", bson.D{{Key: "user_name", Value: 1}}, false},
+	{"users", bson.D{{Key: "user_name_key", Value: 1}}, true},
+	{"logins", bson.D{{Key: "username", Value: 1}}, false},
+	{"players", bson.D{{Key: "player_name", Value: 1}}, true},
+	{"libraries", bson.D{{Key: "external_id", Value: 1}}, true},
+	{"libraries", bson.D{{Key: "user_id", Value: 1}}, false},
+	{"games", bson.D{{Key: "external_id", Value: 1}}, true},
+	{"games", bson.D{{Key: "name", Value: 1}}, false},
+	{"notifications", bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1},
+		{Key: "_id", Value: -1}}, false},
+	{"activities", bson.D{{Key: "user_id", Value: 1}, {Key: "_id", Value: -1}}, false},
+	{"play_sessions", bson.D{{Key: "user_id", Value: 1}, {Key: "starts_at", Value: 1}}, false},
+	{"play_sessions", bson.D{{Key: "partner_ids", Value: 1}}, false},
+	{"play_sessions", bson.D{{Key: "starts_at", Value: 1}}, false},
+	{"session_months", bson.D{{Key: "user_id", Value: 1}}, false},
+	{"session_months", bson.D{{Key: "game_id", Value: 1}}, false},
+	{"stats", bson.D{{Key: "refreshed_at", Value: 1}}, false},
+	{"releases", bson.D{{Key: "user_id", Value: 1}, {Key: "release_date", Value: 1}}, false},
+	{"franchises", bson.D{{Key: "external_id", Value: 1}}, true},
+	{"child_accounts", bson.D{{Key: "parent_id", Value: 1}}, false},
+	{"personal_metadata", bson.D{{Key: "user_id", Value: 1}, {Key: "game_id", Value: 1}}, false},
+	{"journal_entries", bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1},
+		{Key: "_id", Value: 1}}, false},
+	{"physical_copies", bson.D{{Key: "library_id", Value: 1}, {Key: "game_id", Value: 1}}, false},
+	{"physical_copies", bson.D{{Key: "valued_at", Value: 1}}, false},
+	{"photo_imports", bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}, false},
+	{"photo_imports", bson.D{{Key: "library_id", Value: 1}}, false},
+	{"calendar_tokens", bson.D{{Key: "token_hash", Value: 1}}, true},
+	{"share_links", bson.D{{Key: "token_hash", Value: 1}}, true},
+	{"share_links", bson.D{{Key: "library_id", Value: 1}}, false},
+	{"library_members", bson.D{{Key: "library_id", Value: 1}}, false},
+	{"library_members", bson.D{{Key: "user_id", Value: 1}}, false},
+	{"trade_offers", bson.D{{Key: "proposer_id", Value: 1}, {Key: "created_at", Value: -1},
+		{Key: "_id", Value: -1}}, false},
+	{"trade_offers", bson.D{{Key: "recipient_id", Value: 1}, {Key: "created_at", Value: -1},
+		{Key: "_id", Value: -1}}, false},
+	{"user_badges", bson.D{{Key: "user_id", Value: 1}, {Key: "earned_at", Value: 1}}, false},
+	{"goals", bson.D{{Key: "user_id", Value: 1}}, false},
+	{"goals", bson.D{{Key: "ends_at", Value: 1}}, false},
+	{"hardware", bson.D{{Key: "user_id", Value: 1}}, false},
+	{"hardware", bson.D{{Key: "warranty_until", Value: 1}}, false},
+	{"subscriptions", bson.D{{Key: "user_id", Value: 1}}, false},
+	{"subscriptions", bson.D{{Key: "renews_on", Value: 1}}, false},
+	{"catalog_games", bson.D{{Key: "service", Value: 1}}, false},
+	{"catalog_games", bson.D{{Key: "name_key", Value: 1}}, false},
+	{"catalog_alerts", bson.D{{Key: "user_id", Value: 1}}, false},
+	{"game_addons", bson.D{{Key: "parent_id", Value: 1}}, false},
+	{"mods", bson.D{{Key: "library_id", Value: 1}, {Key: "game_id", Value: 1}}, false},
+	{"save_backups", bson.D{{Key: "library_id", Value: 1}, {Key: "game_id", Value: 1},
+		{Key: "backed_up_at", Value: -1}}, false},
+	{"save_backups", bson.D{{Key: "backed_up_at", Value: 1}}, false},
+	{"speedruns", bson.D{{Key: "user_id", Value: 1}, {Key: "game_id", Value: 1}}, false},
+	{"matches", bson.D{{Key: "user_id", Value: 1}, {Key: "played_at", Value: -1},
+		{Key: "_id", Value: -1}}, false},
+	{"agents", bson.D{{Key: "token_hash", Value: 1}}, true},
+	{"agents", bson.D{{Key: "user_id", Value: 1}}, false},
+	{"executable_games", bson.D{{Key: "user_id", Value: 1}, {Key: "executable", Value: 1}}, true},
+	{"agent_processes", bson.D{{Key: "user_id", Value: 1}, {Key: "executable", Value: 1}}, true},
+	{"webhooks", bson.D{{Key: "key_hash", Value: 1}}, true},
+	{"webhooks", bson.D{{Key: "user_id", Value: 1}}, false},
+	{"rules", bson.D{{Key: "user_id", Value: 1}, {Key: "event", Value: 1}}, false},
+	{"rule_runs", bson.D{{Key: "rule_id", Value: 1}, {Key: "_id", Value: -1}}, false},
+	{"scripts", bson.D{{Key: "event", Value: 1}, {Key: "enabled", Value: 1}}, false},
+	{"saved_searches", bson.D{{Key: "user_id", Value: 1}}, false},
+	{"saved_searches", bson.D{{Key: "share_token_hash", Value: 1}}, false},
+	{"default_searches", bson.D{{Key: "user_id", Value: 1}, {Key: "library_id", Value: 1}}, true},
+	{"default_searches", bson.D{{Key: "library_id", Value: 1}}, false},
+	{"changes", bson.D{{Key: "user_id", Value: 1}, {Key: "_id", Value: 1}}, false},
+	{"idempotency_keys", bson.D{{Key: "scope", Value: 1}, {Key: "key", Value: 1}}, true},
+	{"undo_actions", bson.D{{Key: "user_id", Value: 1}, {Key: "_id", Value: -1}}, false},
+	{"trash", bson.D{{Key: "user_id", Value: 1}, {Key: "_id", Value: -1}}, false},
+	{"trash", bson.D{{Key: "removed_at", Value: 1}}, false},
+}
+
+func EnsureMongoIndexes(handler *MongoHandler) error {
+	for _, index := range mongoIndexes {
+		ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+		_, err := handler.Database.Collection(index.collection).Indexes().CreateOne(ctx,
+			mongo.IndexModel{Keys: index.keys, Options: options.Index().SetUnique(index.unique)})
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package infrastructure
+
+import (
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Publishes on NATS core subjects. Messages nobody subscribes to are
+// dropped and failed handlers are only logged, NATS core keeps no messages.
+type NatsBus struct {
+	conn *nats.Conn
+}
+
+func NewNatsBus(url string) (*NatsBus, error) {
+	conn, err := nats.Connect(url, nats.Name("game-tracker"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	return &NatsBus{conn: conn}, nil
+}
+
+func (bus *NatsBus) Publish(subject string, payload []byte) error {
+	return bus.conn.Publish(subject, payload)
+}
+
+func (bus *NatsBus) Subscribe(subject, queue string, handle func(payload []byte) error) error {
+	_, err := bus.conn.QueueSubscribe(subject, queue, func(msg *nats.Msg) {
+		err := handle(msg.Data)
+		if err != nil {
+			fmt.Printf("Cannot handle message of %s: %v\n", msg.Subject, err)
+		}
+	})
+	return err
+}
+
+// Lets subscribers finish the messages they hold
+func (bus *NatsBus) Close() error {
+	return bus.conn.Drain()
+}
@@ -0,0 +1,46 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+// Posts each notification as JSON to an HTTP endpoint, such as a chat
+// service's incoming webhook or a relay in front of one
+type HttpNotificationChannel struct {
+	url    string
+	client *http.Client
+}
+
+type notificationMessage struct {
+	UserId    int       `json:"userId"`
+	Kind      string    `json:"kind"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func NewHttpNotificationChannel(url string) *HttpNotificationChannel {
+	return &HttpNotificationChannel{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (channel *HttpNotificationChannel) Send(notification usecases.Notification) error {
+	body, err := json.Marshal(notificationMessage{UserId: notification.UserId, Kind: notification.Kind,
+		Message: notification.Message, CreatedAt: notification.CreatedAt})
+	if err != nil {
+		return err
+	}
+	response, err := channel.client.Post(channel.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("notification channel answered %s", response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package infrastructure
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"game-tracker/usecases"
+)
+
+const (
+	pdfLineHeight = 6
+	pdfCellHeight = 7
+)
+
+// Prints documents as A4 PDF with the core Helvetica font. Core fonts only
+// know cp1252, characters outside of it print as question marks.
+type PdfRenderer struct{}
+
+func NewPdfRenderer() *PdfRenderer {
+	return &PdfRenderer{}
+}
+
+func (renderer *PdfRenderer) ContentType() string {
+	return "application/pdf"
+}
+
+func (renderer *PdfRenderer) Extension() string {
+	return "pdf"
+}
+
+func (renderer *PdfRenderer) Render(document usecases.Document) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	translate := pdf.UnicodeTranslatorFromDescriptor("cp1252")
+	pdf.SetTitle(document.Title, true)
+	pdf.SetCreator("game-tracker", true)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 10, fmt.Sprintf("- %d -", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	if document.Title != "" {
+		pdf.SetFont("Helvetica", "B", 18)
+		pdf.MultiCell(0, 10, translate(document.Title), "", "L", false)
+		pdf.Ln(4)
+	}
+	for _, section := range document.Sections {
+		if section.Heading != "" {
+			pdf.SetFont("Helvetica", "B", 13)
+			pdf.MultiCell(0, 8, translate(section.Heading), "", "L", false)
+			pdf.Ln(1)
+		}
+		pdf.SetFont("Helvetica", "", 10)
+		for _, paragraph := range section.Paragraphs {
+			pdf.MultiCell(0, pdfLineHeight, translate(paragraph), "", "L", false)
+			pdf.Ln(2)
+		}
+		if len(section.Table) > 0 {
+			renderer.table(pdf, translate, section.Table)
+		}
+		pdf.Ln(4)
+	}
+
+	var printed bytes.Buffer
+	err := pdf.Output(&printed)
+	if err != nil {
+		return nil, err
+	}
+	return printed.Bytes(), nil
+}
+
+// Columns share the page width equally, cells too long for theirs are cut
+func (renderer *PdfRenderer) table(pdf *gofpdf.Fpdf, translate func(string) string, rows [][]string) {
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	width := (pageWidth - left - right) / float64(columns)
+
+	pdf.SetFillColor(230, 230, 230)
+	for i, row := range rows {
+		header := i == 0
+		if header {
+			pdf.SetFont("Helvetica", "B", 10)
+		} else {
+			pdf.SetFont("Helvetica", "", 10)
+		}
+		for column := 0; column < columns; column++ {
+			text := ""
+			if column < len(row) {
+				text = fitCell(pdf, translate(row[column]), width-2)
+			}
+			pdf.CellFormat(width, pdfCellHeight, text, "1", 0, "L", header, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+func fitCell(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
@@ -0,0 +1,56 @@
+package infrastructure
+
+import (
+	"game-tracker/plugins"
+)
+
+// Registers the providers that ship with the tracker as plugins, third party
+// plugins register themselves the same way
+func RegisterPlugins() {
+	plugins.Register(plugins.Plugin{
+		Name: "http-metadata",
+		Kind: plugins.KindMetadata,
+		Schema: []plugins.Field{
+			{Name: "url", Type: plugins.TypeString, Required: true,
+				Description: "Metadata service URL with a {name} placeholder"},
+		},
+		Open: func(settings plugins.Settings) (interface{}, error) {
+			return NewHttpMetadataProvider(settings.String("url")), nil
+		},
+	})
+	plugins.Register(plugins.Plugin{
+		Name: "steam",
+		Kind: plugins.KindLibrarySync,
+		Schema: []plugins.Field{
+			{Name: "apiUrl", Type: plugins.TypeString, Default: "https://api.steampowered.com",
+				Description: "Steam Web API wishlists are read from"},
+			{Name: "storeUrl", Type: plugins.TypeString, Default: "https://store.steampowered.com",
+				Description: "Steam store app names are read from"},
+		},
+		Open: func(settings plugins.Settings) (interface{}, error) {
+			return NewSteamClient(settings.String("apiUrl"), settings.String("storeUrl")), nil
+		},
+	})
+	plugins.Register(plugins.Plugin{
+		Name: "http-pricing",
+		Kind: plugins.KindPricing,
+		Schema: []plugins.Field{
+			{Name: "url", Type: plugins.TypeString, Required: true,
+				Description: "Price guide URL with {name}, {platform} and {barcode} placeholders"},
+		},
+		Open: func(settings plugins.Settings) (interface{}, error) {
+			return NewHttpPricingProvider(settings.String("url")), nil
+		},
+	})
+	plugins.Register(plugins.Plugin{
+		Name: "http-notifications",
+		Kind: plugins.KindNotifications,
+		Schema: []plugins.Field{
+			{Name: "url", Type: plugins.TypeString, Required: true,
+				Description: "Endpoint every notification is posted to as JSON"},
+		},
+		Open: func(settings plugins.Settings) (interface{}, error) {
+			return NewHttpNotificationChannel(settings.String("url")), nil
+		},
+	})
+}
@@ -0,0 +1,140 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// How long a server may take to answer before failover passes it over
+const failoverProbeTimeout = 2 * time.Second
+
+// Pools of the servers one database is reachable at, the primary first.
+// Statements go to the current one until it is lost.
+type postgresqlCluster struct {
+	lock       sync.RWMutex
+	switching  sync.Mutex //One failover at a time
+	pools      []*sql.DB
+	current    int
+	failovers  int64
+	onFailover func(from, to int, cause error)
+}
+
+func (cluster *postgresqlCluster) pool() *sql.DB {
+	cluster.lock.RLock()
+	defer cluster.lock.RUnlock()
+	return cluster.pools[cluster.current]
+}
+
+func (cluster *postgresqlCluster) state() (int, int64) {
+	cluster.lock.RLock()
+	defer cluster.lock.RUnlock()
+	return cluster.current, cluster.failovers
+}
+
+func (cluster *postgresqlCluster) notify(fn func(from, to int, cause error)) {
+	cluster.lock.Lock()
+	defer cluster.lock.Unlock()
+	cluster.onFailover = fn
+}
+
+// Moves to the next server that accepts writes, wrapping around so a primary
+// that came back is found again. Statements that failed on a pool already
+// replaced meanwhile do not move on once more.
+func (cluster *postgresqlCluster) failover(failed *sql.DB, cause error) bool {
+	cluster.switching.Lock()
+	defer cluster.switching.Unlock()
+	from, _ := cluster.state()
+	if cluster.pools[from] != failed {
+		return true
+	}
+	for step := 1; step < len(cluster.pools); step++ {
+		to := (from + step) % len(cluster.pools)
+		if !acceptsWrites(cluster.pools[to]) {
+			continue
+		}
+		cluster.lock.Lock()
+		cluster.current = to
+		cluster.failovers++
+		notify := cluster.onFailover
+		cluster.lock.Unlock()
+		if notify != nil {
+			notify(from, to, cause)
+		}
+		return true
+	}
+	return false
+}
+
+func (cluster *postgresqlCluster) close() error {
+	var first error
+	for _, pool := range cluster.pools {
+		err := pool.Close()
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Standbys still replaying answer too, they are no use until promoted
+func acceptsWrites(pool *sql.DB) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), failoverProbeTimeout)
+	defer cancel()
+	var recovering bool
+	err := pool.QueryRowContext(ctx, "SELECT pg_is_in_recovery()").Scan(&recovering)
+	return err == nil && !recovering
+}
+
+// Errors of a server that is down, restarting or no longer the primary, as
+// opposed to errors of the statement. Statement timeouts are not failures
+// of the server.
+func serverLost(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		code := string(pqErr.Code)
+		// Connection exceptions, shutdowns and writes refused by a demoted primary
+		return strings.HasPrefix(code, "08") || code == "57P01" || code == "57P02" ||
+			code == "57P03" || code == "25006"
+	}
+	return false
+}
+
+// Lost servers whose error shows the statement never ran, those are safe to
+// send again. A connection dropped while a statement ran may have applied it.
+func notConnected(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "08001", "08004", "57P03", "25006":
+			return true
+		}
+	}
+	return false
+}
@@ -1,38 +1,281 @@
 package infrastructure
 
 import (
+	"context"
 	"database/sql"
-	_ "github.com/lib/pq"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
 
 	"game-tracker/interfaces"
+	"game-tracker/interfaces/query"
+	"game-tracker/usecases"
 )
 
 type PostgresqlHandler struct {
-	Conn *sql.DB
+	cluster *postgresqlCluster
+	Timeout time.Duration //Deadline of each statement, 0 leaves them unbounded
+	// Set on handlers of single repositories or methods, whose Timeout
+	// differs from the statement_timeout the connections were opened with
+	ownTimeout bool
+}
+
+// Satisfied by the pool itself and by a connection taken from it
+type postgresqlSession interface {
+	ExecContext(ctx context.Context, statement string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, statement string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, statement string, args ...interface{}) *sql.Row
+}
+
+// A handler sharing the pool whose statements get timeout instead, on the
+// client and on the server. Timeout 0 lets them run unbounded.
+func (handler *PostgresqlHandler) WithTimeout(timeout time.Duration) *PostgresqlHandler {
+	return &PostgresqlHandler{cluster: handler.cluster, Timeout: timeout, ownTimeout: true}
+}
+
+// The pool of the server in use, it changes on failover
+func (handler *PostgresqlHandler) DB() *sql.DB {
+	return handler.cluster.pool()
+}
+
+// Runs fn on the pool in use. When its server is lost the next one that
+// accepts writes takes over, and fn runs once more there if the statement
+// never reached the lost server.
+func (handler *PostgresqlHandler) withFailover(fn func(pool *sql.DB) error) error {
+	pool := handler.DB()
+	err := fn(pool)
+	if !handler.lost(pool, err) || !notConnected(err) {
+		return err
+	}
+	return fn(handler.DB())
+}
+
+// Fails over when err says the server of pool is gone, true once another
+// server took over
+func (handler *PostgresqlHandler) lost(pool *sql.DB, err error) bool {
+	return err != nil && serverLost(err) && handler.cluster.failover(pool, err)
+}
+
+func (handler *PostgresqlHandler) context() (context.Context, context.CancelFunc) {
+	return statementContext(handler.Timeout)
+}
+
+func statementContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// The pool, or a connection of it running with the statement_timeout of
+// this handler until release puts it back. Connections that cannot be
+// reset are closed rather than handed to the next caller.
+func (handler *PostgresqlHandler) session(ctx context.Context, pool *sql.DB) (postgresqlSession, func(), error) {
+	if !handler.ownTimeout {
+		return pool, func() {}, nil
+	}
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = conn.ExecContext(ctx, "SET statement_timeout = "+
+		strconv.FormatInt(handler.Timeout.Milliseconds(), 10))
+	if err != nil {
+		discardConn(conn)
+		return nil, nil, err
+	}
+	release := func() {
+		_, err := conn.ExecContext(context.Background(), "RESET statement_timeout")
+		if err != nil {
+			discardConn(conn)
+			return
+		}
+		conn.Close()
+	}
+	return conn, release, nil
+}
+
+func discardConn(conn *sql.Conn) {
+	conn.Raw(func(interface{}) error {
+		return driver.ErrBadConn
+	})
+	conn.Close()
 }
 
 func (handler *PostgresqlHandler) Execute(statement string, args ...interface{}) (sql.Result, error) {
-	res, err := handler.Conn.Exec(statement, args...)
-	return res, err
+	var res sql.Result
+	err := handler.withFailover(func(pool *sql.DB) error {
+		ctx, cancel := handler.context()
+		defer cancel()
+		session, release, err := handler.session(ctx, pool)
+		if err != nil {
+			return err
+		}
+		defer release()
+		res, err = session.ExecContext(ctx, statement, args...)
+		return err
+	})
+	return res, driverError(err)
 }
 
+// The deadline lasts until the rows are closed
 func (handler *PostgresqlHandler) Query(statement string, args ...interface{}) (interfaces.Row, error) {
-	rows, err := handler.Conn.Query(statement, args...)
+	r := PostgresqlRow{}
+	err := handler.withFailover(func(pool *sql.DB) error {
+		ctx, cancel := handler.context()
+		session, release, err := handler.session(ctx, pool)
+		if err != nil {
+			cancel()
+			return err
+		}
+		rows, err := session.QueryContext(ctx, statement, args...)
+		if err != nil {
+			release()
+			cancel()
+			return err
+		}
+		var once sync.Once
+		r = PostgresqlRow{Rows: rows, done: func() {
+			once.Do(func() {
+				release()
+				cancel()
+			})
+		}}
+		return nil
+	})
+	return r, driverError(err)
+}
+
+func (handler *PostgresqlHandler) QueryRow(statement string, args ...interface{}) (int, error) {
+	var id int
+	err := handler.withFailover(func(pool *sql.DB) error {
+		ctx, cancel := handler.context()
+		defer cancel()
+		session, release, err := handler.session(ctx, pool)
+		if err != nil {
+			return err
+		}
+		defer release()
+		return session.QueryRowContext(ctx, statement, args...).Scan(&id)
+	})
+	return id, driverError(err)
+}
+
+func (handler *PostgresqlHandler) Dialect() query.Dialect {
+	return query.Postgres
+}
+
+// Only beginning is retried on another server, a transaction that loses its
+// server fails
+func (handler *PostgresqlHandler) Transaction(fn func(tx interfaces.DbHandler) error) error {
+	var pool *sql.DB
+	var tx *sql.Tx
+	err := handler.withFailover(func(current *sql.DB) error {
+		var err error
+		pool = current
+		tx, err = pool.Begin()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	// Statements of the transaction each get the timeout, not all together
+	if handler.ownTimeout {
+		_, err = tx.Exec("SET LOCAL statement_timeout = " +
+			strconv.FormatInt(handler.Timeout.Milliseconds(), 10))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	err = fn(&PostgresqlTx{Tx: tx, Timeout: handler.Timeout})
 	if err != nil {
-		return PostgresqlRow{}, err
+		tx.Rollback()
+		handler.lost(pool, err)
+		return err
 	}
-	r := PostgresqlRow{Rows: rows}
-	return r, nil
+	err = tx.Commit()
+	handler.lost(pool, err)
+	return driverError(err)
 }
 
-func (handler *PostgresqlHandler) QueryRow(statement string, args ...interface{}) (int, error) {
+// Streams the rows with COPY in a transaction of their own
+func (handler *PostgresqlHandler) CopyIn(table string, columns []string, rows [][]interface{}) error {
+	return handler.Transaction(func(tx interfaces.DbHandler) error {
+		return tx.(*PostgresqlTx).CopyIn(table, columns, rows)
+	})
+}
+
+// Runs statements inside an open transaction, it satisfies DbHandler so
+// repositories use it like the handler itself
+type PostgresqlTx struct {
+	Tx      *sql.Tx
+	Timeout time.Duration //Deadline of each statement, as on the handler that began it
+}
+
+func (handler *PostgresqlTx) Execute(statement string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := statementContext(handler.Timeout)
+	defer cancel()
+	res, err := handler.Tx.ExecContext(ctx, statement, args...)
+	return res, driverError(err)
+}
+
+// The deadline lasts until the rows are closed
+func (handler *PostgresqlTx) Query(statement string, args ...interface{}) (interfaces.Row, error) {
+	ctx, cancel := statementContext(handler.Timeout)
+	rows, err := handler.Tx.QueryContext(ctx, statement, args...)
+	if err != nil {
+		cancel()
+		return PostgresqlRow{}, driverError(err)
+	}
+	var once sync.Once
+	return PostgresqlRow{Rows: rows, done: func() { once.Do(cancel) }}, nil
+}
+
+func (handler *PostgresqlTx) QueryRow(statement string, args ...interface{}) (int, error) {
+	ctx, cancel := statementContext(handler.Timeout)
+	defer cancel()
 	var id int
-	err := handler.Conn.QueryRow(statement, args...).Scan(&id)
-	return id, err
+	err := handler.Tx.QueryRowContext(ctx, statement, args...).Scan(&id)
+	return id, driverError(err)
+}
+
+func (handler *PostgresqlTx) Dialect() query.Dialect {
+	return query.Postgres
+}
+
+// Nested transactions join the one already open
+func (handler *PostgresqlTx) Transaction(fn func(tx interfaces.DbHandler) error) error {
+	return fn(handler)
+}
+
+// Rows are buffered by the driver and sent as one COPY when the statement is
+// executed without arguments
+func (handler *PostgresqlTx) CopyIn(table string, columns []string, rows [][]interface{}) error {
+	statement, err := handler.Tx.Prepare(pq.CopyIn(table, columns...))
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+	for _, row := range rows {
+		_, err = statement.Exec(row...)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = statement.Exec()
+	return err
 }
 
 type PostgresqlRow struct {
 	Rows *sql.Rows
+	done func() //Ends the deadline of the query
 }
 
 func (r PostgresqlRow) Scan(dest ...interface{}) error {
@@ -43,16 +286,84 @@ func (r PostgresqlRow) Next() bool {
 	return r.Rows.Next()
 }
 
+// Foreign keys are checked once the statement ran, after it may have sent
+// rows, so their violations end the rows instead of failing the query
 func (r PostgresqlRow) Close() error {
-	return r.Rows.Close()
+	err := r.Rows.Close()
+	if err == nil {
+		err = r.Rows.Err()
+	}
+	if r.done != nil {
+		r.done()
+	}
+	return driverError(err)
 }
 
-func NewPostgresqlHandler(dbfileAdr string) (*PostgresqlHandler, error) {
-	conn, err := sql.Open("postgres", dbfileAdr)
-	if err != nil {
-		return new(PostgresqlHandler), err
+// Wraps violations of foreign keys and unique indexes, the repositories
+// turn them into conflicts by the constraint's name
+func driverError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch pqErr.Code {
+	case "23503":
+		return &interfaces.ForeignKeyError{Constraint: pqErr.Constraint, Err: err}
+	case "23505":
+		return &interfaces.UniqueError{Constraint: pqErr.Constraint, Err: err}
+	}
+	return err
+}
+
+func (handler *PostgresqlHandler) PoolStats() usecases.PoolStats {
+	server, failovers := handler.cluster.state()
+	stats := handler.DB().Stats()
+	return usecases.PoolStats{MaxOpen: stats.MaxOpenConnections, Open: stats.OpenConnections,
+		InUse: stats.InUse, Idle: stats.Idle, WaitCount: stats.WaitCount,
+		WaitDuration: stats.WaitDuration, Server: server, Failovers: failovers}
+}
+
+// Called with the indexes of the DSNs after each failover, in the goroutine
+// of the statement that noticed
+func (handler *PostgresqlHandler) OnFailover(fn func(from, to int, cause error)) {
+	handler.cluster.notify(fn)
+}
+
+// Closes the pools of every server
+func (handler *PostgresqlHandler) Close() error {
+	return handler.cluster.close()
+}
+
+// Opens every connection with statement_timeout set, so the server stops
+// statements even when the client that sent them is gone. lib/pq passes
+// parameters it does not know on to the server.
+func StatementTimeoutDsn(dsn string, timeout time.Duration) string {
+	if timeout <= 0 {
+		return dsn
+	}
+	parameter := fmt.Sprintf("statement_timeout=%d", timeout.Milliseconds())
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		if strings.Contains(dsn, "?") {
+			return dsn + "&" + parameter
+		}
+		return dsn + "?" + parameter
+	}
+	return dsn + " " + parameter
+}
+
+// Statements go to dbfileAdr, the failover DSNs are further servers of the
+// same database taken in order when the one in use is lost
+func NewPostgresqlHandler(dbfileAdr string, failover ...string) (*PostgresqlHandler, error) {
+	cluster := new(postgresqlCluster)
+	for _, dsn := range append([]string{dbfileAdr}, failover...) {
+		conn, err := sql.Open("postgres", dsn)
+		if err != nil {
+			cluster.close()
+			return new(PostgresqlHandler), err
+		}
+		cluster.pools = append(cluster.pools, conn)
 	}
 	postgresqlHandler := new(PostgresqlHandler)
-	postgresqlHandler.Conn = conn
+	postgresqlHandler.cluster = cluster
 	return postgresqlHandler, nil
 }
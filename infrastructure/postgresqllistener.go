@@ -0,0 +1,111 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"game-tracker/usecases"
+)
+
+// Channel the triggers of migration 0055 notify on
+const ChangeChannel = "gametracker_changes"
+
+// Payload of the triggers, see migrations/0055_change_notifications.sql
+type changePayload struct {
+	Kind       string    `json:"kind"`
+	Id         int64     `json:"id"`
+	UserId     int       `json:"userId"`
+	Entity     string    `json:"entity"`
+	EntityId   string    `json:"entityId"`
+	ParentId   string    `json:"parentId"`
+	Action     string    `json:"action"`
+	ChangedAt  time.Time `json:"changedAt"`
+	GameId     int       `json:"gameId"`
+	ExternalId string    `json:"externalId"`
+}
+
+// Receives the notifications Postgres sends for writes of every instance,
+// with LISTEN on a connection of its own. A DSN that cannot be reached
+// hands over to the next one, as the handler does on failover.
+type PostgresqlChangeStream struct {
+	dsns []string
+}
+
+func NewPostgresqlChangeStream(dsn string, failover ...string) *PostgresqlChangeStream {
+	return &PostgresqlChangeStream{dsns: append([]string{dsn}, failover...)}
+}
+
+func (stream *PostgresqlChangeStream) Run(handle func(notice usecases.ChangeNotice)) {
+	for server := 0; ; server = (server + 1) % len(stream.dsns) {
+		err := stream.listen(stream.dsns[server], handle)
+		fmt.Printf("Change stream lost DSN %d: %v\n", server, err)
+		// Whatever was written meanwhile went unnoticed
+		handle(usecases.ChangeNotice{Kind: usecases.NoticeMissed})
+		time.Sleep(time.Second)
+	}
+}
+
+// Returns once the server cannot be reached anymore. The listener
+// reconnects by itself after short outages, notices of the gap are lost.
+func (stream *PostgresqlChangeStream) listen(dsn string, handle func(notice usecases.ChangeNotice)) error {
+	failed := make(chan error, 1)
+	listener := pq.NewListener(dsn, time.Second, 10*time.Second,
+		func(event pq.ListenerEventType, err error) {
+			switch event {
+			case pq.ListenerEventConnectionAttemptFailed:
+				select {
+				case failed <- err:
+				default:
+				}
+			case pq.ListenerEventReconnected:
+				handle(usecases.ChangeNotice{Kind: usecases.NoticeMissed})
+			}
+		})
+	defer listener.Close()
+	err := listener.Listen(ChangeChannel)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case err := <-failed:
+			return err
+		case notification := <-listener.Notify:
+			// Nil after a reconnect, which the event callback reported
+			if notification == nil {
+				continue
+			}
+			notice, err := changeNotice(notification.Extra)
+			if err != nil {
+				fmt.Printf("Cannot read change notification: %v\n", err)
+				continue
+			}
+			handle(notice)
+		case <-time.After(time.Minute):
+			// Notices stay away when the connection died silently
+			listener.Ping()
+		}
+	}
+}
+
+func changeNotice(extra string) (usecases.ChangeNotice, error) {
+	var payload changePayload
+	err := json.Unmarshal([]byte(extra), &payload)
+	if err != nil {
+		return usecases.ChangeNotice{}, err
+	}
+	switch payload.Kind {
+	case usecases.NoticeChange:
+		return usecases.ChangeNotice{Kind: payload.Kind, Change: usecases.Change{Id: payload.Id,
+			UserId: payload.UserId, Entity: payload.Entity, EntityId: payload.EntityId,
+			ParentId: payload.ParentId, Action: payload.Action, ChangedAt: payload.ChangedAt}}, nil
+	case usecases.NoticeGame:
+		return usecases.ChangeNotice{Kind: payload.Kind, GameId: payload.GameId,
+			GameExternalId: payload.ExternalId}, nil
+	}
+	return usecases.ChangeNotice{}, fmt.Errorf("Unknown notification kind '%s'", payload.Kind)
+}
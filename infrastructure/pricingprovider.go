@@ -0,0 +1,54 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+// Asks an HTTP price guide, PriceCharting style, what a boxed copy sells
+// for. The URL holds {name}, {platform} and {barcode} placeholders and the
+// service answers {"price": 24.99, "currency": "USD"}, a 404 means the game
+// is not listed
+type HttpPricingProvider struct {
+	urlTemplate string
+	client      *http.Client
+}
+
+type priceQuote struct {
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency"`
+}
+
+func NewHttpPricingProvider(urlTemplate string) *HttpPricingProvider {
+	return &HttpPricingProvider{urlTemplate: urlTemplate,
+		client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (provider *HttpPricingProvider) Estimate(name, platform, barcode string) (usecases.PriceEstimate, bool, error) {
+	address := strings.NewReplacer("{name}", url.QueryEscape(name), "{platform}", url.QueryEscape(platform),
+		"{barcode}", url.QueryEscape(barcode)).Replace(provider.urlTemplate)
+	response, err := provider.client.Get(address)
+	if err != nil {
+		return usecases.PriceEstimate{}, false, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return usecases.PriceEstimate{}, false, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return usecases.PriceEstimate{}, false, fmt.Errorf("pricing provider answered %s", response.Status)
+	}
+
+	var quote priceQuote
+	err = json.NewDecoder(response.Body).Decode(&quote)
+	if err != nil {
+		return usecases.PriceEstimate{}, false, err
+	}
+	return usecases.PriceEstimate{Value: quote.Price, Currency: strings.ToUpper(quote.Currency)}, true, nil
+}
@@ -0,0 +1,90 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Subjects are routing keys of one durable topic exchange
+const rabbitmqExchange = "gametracker"
+
+// Publishes persistent messages to RabbitMQ. Each queue of Subscribe is a
+// durable queue, so work waits for a worker to come up. Messages whose
+// handler fails are rejected without requeueing, a dead letter exchange
+// configured on the queue keeps them.
+type RabbitmqBus struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	lock    sync.Mutex //Channels must not publish concurrently
+}
+
+func NewRabbitmqBus(url string) (*RabbitmqBus, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	channel, err := conn.Channel()
+	if err == nil {
+		err = channel.ExchangeDeclare(rabbitmqExchange, "topic", true, false, false, false, nil)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &RabbitmqBus{conn: conn, channel: channel}, nil
+}
+
+func (bus *RabbitmqBus) Publish(subject string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	return bus.channel.PublishWithContext(ctx, rabbitmqExchange, subject, false, false,
+		amqp.Publishing{ContentType: "application/json", DeliveryMode: amqp.Persistent,
+			Timestamp: time.Now().UTC(), Body: payload})
+}
+
+// Consumes on a channel of its own, one message at a time
+func (bus *RabbitmqBus) Subscribe(subject, queue string, handle func(payload []byte) error) error {
+	channel, err := bus.conn.Channel()
+	if err != nil {
+		return err
+	}
+	name := queue + "." + subject
+	_, err = channel.QueueDeclare(name, true, false, false, false, nil)
+	if err == nil {
+		err = channel.QueueBind(name, subject, rabbitmqExchange, false, nil)
+	}
+	if err == nil {
+		err = channel.Qos(1, 0, false)
+	}
+	var deliveries <-chan amqp.Delivery
+	if err == nil {
+		deliveries, err = channel.Consume(name, "", false, false, false, false, nil)
+	}
+	if err != nil {
+		channel.Close()
+		return err
+	}
+
+	go func() {
+		for delivery := range deliveries {
+			err := handle(delivery.Body)
+			if err != nil {
+				fmt.Printf("Cannot handle message of %s: %v\n", delivery.RoutingKey, err)
+				delivery.Nack(false, false)
+				continue
+			}
+			delivery.Ack(false)
+		}
+	}()
+	return nil
+}
+
+func (bus *RabbitmqBus) Close() error {
+	return bus.conn.Close()
+}
@@ -0,0 +1,88 @@
+package infrastructure
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisTimeout = 2 * time.Second
+
+type RedisHandler struct {
+	Client *redis.Client
+}
+
+func NewRedisHandler(address, password string, db int) (*RedisHandler, error) {
+	client := redis.NewClient(&redis.Options{Addr: address, Password: password, DB: db})
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	err := client.Ping(ctx).Err()
+	if err != nil {
+		return new(RedisHandler), err
+	}
+	redisHandler := new(RedisHandler)
+	redisHandler.Client = client
+	return redisHandler, nil
+}
+
+// A key space of the Redis server, every key is prefixed and expires after ttl
+type RedisCache struct {
+	handler *RedisHandler
+	prefix  string
+	ttl     time.Duration
+}
+
+func NewRedisCache(handler *RedisHandler, prefix string, ttl time.Duration) *RedisCache {
+	return &RedisCache{handler: handler, prefix: prefix, ttl: ttl}
+}
+
+func (cache *RedisCache) Get(key string) ([]byte, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	value, err := cache.handler.Client.Get(ctx, cache.prefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	return value, err == nil, err
+}
+
+func (cache *RedisCache) Set(key string, value []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return cache.handler.Client.Set(ctx, cache.prefix+key, value, cache.ttl).Err()
+}
+
+func (cache *RedisCache) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return cache.handler.Client.Del(ctx, cache.prefix+key).Err()
+}
+
+// Only one of the clients taking the same key gets its value
+func (cache *RedisCache) Take(key string) ([]byte, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	value, err := cache.handler.Client.GetDel(ctx, cache.prefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	return value, err == nil, err
+}
+
+// Increments the key and starts its ttl on the first increment in one step,
+// so a client failing in between cannot leave a counter that never expires
+var incrementScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count`)
+
+// The ttl starts with the first increment, later ones do not extend it
+func (cache *RedisCache) Increment(key string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return incrementScript.Run(ctx, cache.handler.Client, []string{cache.prefix + key},
+		cache.ttl.Milliseconds()).Int64()
+}
@@ -0,0 +1,101 @@
+package infrastructure
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Reports errors to Sentry through its store endpoint, events are sent in
+// the background and dropped when Sentry cannot be reached
+type SentryReporter struct {
+	endpoint    string
+	auth        string
+	environment string
+	client      *http.Client
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sentryEvent struct {
+	EventId     string                       `json:"event_id"`
+	Timestamp   string                       `json:"timestamp"`
+	Level       string                       `json:"level"`
+	Platform    string                       `json:"platform"`
+	Environment string                       `json:"environment,omitempty"`
+	Exception   map[string][]sentryException `json:"exception"`
+	Tags        map[string]string            `json:"tags,omitempty"`
+	Extra       map[string]string            `json:"extra,omitempty"`
+}
+
+// dsn has the form https://<key>@<host>/<project>
+func NewSentryReporter(dsn, environment string) (*SentryReporter, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	project := strings.Trim(parsed.Path, "/")
+	if parsed.User == nil || parsed.User.Username() == "" || project == "" {
+		return nil, fmt.Errorf("Sentry DSN must name a key and a project")
+	}
+	endpoint := fmt.Sprintf("%s://%s/api/%s/store/", parsed.Scheme, parsed.Host, project)
+	auth := fmt.Sprintf("Sentry sentry_version=7, sentry_client=game-tracker/1.0, sentry_key=%s",
+		parsed.User.Username())
+	return &SentryReporter{endpoint: endpoint, auth: auth, environment: environment,
+		client: &http.Client{Timeout: 5 * time.Second}}, nil
+}
+
+func (reporter *SentryReporter) Report(err error, stack []byte, tags map[string]string) {
+	event := sentryEvent{
+		EventId:     newEventId(),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Level:       "error",
+		Platform:    "go",
+		Environment: reporter.environment,
+		Exception: map[string][]sentryException{"values": {{
+			Type: reflect.TypeOf(err).String(), Value: err.Error()}}},
+		Tags:  tags,
+		Extra: map[string]string{"stack": string(stack)},
+	}
+	go reporter.send(event)
+}
+
+func (reporter *SentryReporter) send(event sentryEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		fmt.Printf("Cannot encode Sentry event: %v\n", err)
+		return
+	}
+	request, err := http.NewRequest("POST", reporter.endpoint, bytes.NewReader(body))
+	if err != nil {
+		fmt.Printf("Cannot build Sentry request: %v\n", err)
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Sentry-Auth", reporter.auth)
+	response, err := reporter.client.Do(request)
+	if err != nil {
+		fmt.Printf("Cannot send event %s to Sentry: %v\n", event.EventId, err)
+		return
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		fmt.Printf("Sentry refused event %s: %s\n", event.EventId, response.Status)
+	}
+}
+
+func newEventId() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
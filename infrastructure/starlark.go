@@ -0,0 +1,113 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"game-tracker/usecases"
+)
+
+const scriptEntryPoint = "handle"
+
+// Runs admin scripts in Starlark. A script sees only the builtins it is
+// given, cannot load modules and is cancelled after its steps or time run
+// out. Starlark does not count allocations, memory is bounded by the step
+// limit and the timeout.
+type StarlarkEngine struct{}
+
+func NewStarlarkEngine() *StarlarkEngine {
+	return &StarlarkEngine{}
+}
+
+func (engine *StarlarkEngine) Check(name, source string, builtins []string) error {
+	predeclared := make(map[string]bool)
+	for _, builtin := range builtins {
+		predeclared[builtin] = true
+	}
+	file, _, err := starlark.SourceProgram(name, source, func(name string) bool {
+		return predeclared[name]
+	})
+	if err != nil {
+		return err
+	}
+	for _, statement := range file.Stmts {
+		def, ok := statement.(*syntax.DefStmt)
+		if ok && def.Name.Name == scriptEntryPoint {
+			if len(def.Params) != 1 {
+				return fmt.Errorf("%s must take the event as its only parameter", scriptEntryPoint)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("script must define %s(event)", scriptEntryPoint)
+}
+
+func (engine *StarlarkEngine) Run(name, source string, call usecases.ScriptCall) error {
+	thread := &starlark.Thread{
+		Name: name,
+		Load: func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+			return nil, errors.New("scripts cannot load modules")
+		},
+		Print: func(thread *starlark.Thread, message string) {
+			if call.Print != nil {
+				call.Print(message)
+			}
+		},
+	}
+	thread.SetMaxExecutionSteps(call.Steps)
+	timer := time.AfterFunc(call.Timeout, func() {
+		thread.Cancel(fmt.Sprintf("ran longer than %s", call.Timeout))
+	})
+	defer timer.Stop()
+
+	predeclared := make(starlark.StringDict)
+	for builtinName, builtin := range call.Builtins {
+		predeclared[builtinName] = starlark.NewBuiltin(builtinName, starlarkBuiltin(builtin))
+	}
+	globals, err := starlark.ExecFile(thread, name, source, predeclared)
+	if err != nil {
+		return err
+	}
+	handle, ok := globals[scriptEntryPoint].(starlark.Callable)
+	if !ok {
+		return fmt.Errorf("script must define %s(event)", scriptEntryPoint)
+	}
+
+	event := starlark.NewDict(len(call.Event))
+	for key, value := range call.Event {
+		err = event.SetKey(starlark.String(key), starlark.String(value))
+		if err != nil {
+			return err
+		}
+	}
+	event.Freeze()
+	_, err = starlark.Call(thread, handle, starlark.Tuple{event}, nil)
+	return err
+}
+
+// Builtins take strings by position and return None
+func starlarkBuiltin(builtin usecases.ScriptBuiltin) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if len(kwargs) > 0 {
+			return nil, fmt.Errorf("%s: takes no keyword arguments", b.Name())
+		}
+		texts := make([]string, len(args))
+		for i, arg := range args {
+			text, ok := starlark.AsString(arg)
+			if !ok {
+				return nil, fmt.Errorf("%s: argument %d must be a string, not %s", b.Name(), i+1,
+					arg.Type())
+			}
+			texts[i] = text
+		}
+		err := builtin(texts)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.None, nil
+	}
+}
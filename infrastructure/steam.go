@@ -0,0 +1,105 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+// Reads public wishlists from the Steam Web API and app names from the
+// store. Neither needs an API key.
+type SteamClient struct {
+	apiUrl   string
+	storeUrl string
+	client   *http.Client
+}
+
+type steamWishlist struct {
+	Response struct {
+		Items []struct {
+			AppId     int   `json:"appid"`
+			Priority  int   `json:"priority"`
+			DateAdded int64 `json:"date_added"`
+		} `json:"items"`
+	} `json:"response"`
+}
+
+type steamAppDetails struct {
+	Success bool `json:"success"`
+	Data    struct {
+		Name string `json:"name"`
+	} `json:"data"`
+}
+
+func NewSteamClient(apiUrl, storeUrl string) *SteamClient {
+	return &SteamClient{apiUrl: strings.TrimSuffix(apiUrl, "/"),
+		storeUrl: strings.TrimSuffix(storeUrl, "/"), client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (steam *SteamClient) get(address string, into interface{}) (bool, error) {
+	response, err := steam.client.Get(address)
+	if err != nil {
+		return false, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusUnauthorized ||
+		response.StatusCode == http.StatusForbidden {
+		return false, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("Steam answered %s", response.Status)
+	}
+	return true, json.NewDecoder(response.Body).Decode(into)
+}
+
+// Steam answers an empty response for private and unknown profiles.
+// Priority 0 marks games never ranked, they follow the ranked ones in the
+// order they were added.
+func (steam *SteamClient) Wishlist(steamId string) ([]usecases.SteamWishlistItem, bool, error) {
+	var wishlist steamWishlist
+	found, err := steam.get(steam.apiUrl+"/IWishlistService/GetWishlist/v1/?steamid="+
+		url.QueryEscape(steamId), &wishlist)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	items := wishlist.Response.Items
+	if len(items) == 0 {
+		return nil, false, nil
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if (a.Priority == 0) != (b.Priority == 0) {
+			return b.Priority == 0
+		}
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return a.DateAdded < b.DateAdded
+	})
+	ranked := make([]usecases.SteamWishlistItem, len(items))
+	for i, item := range items {
+		ranked[i] = usecases.SteamWishlistItem{AppId: item.AppId, Rank: i + 1}
+	}
+	return ranked, true, nil
+}
+
+func (steam *SteamClient) AppName(appId int) (string, bool, error) {
+	id := strconv.Itoa(appId)
+	var details map[string]steamAppDetails
+	found, err := steam.get(steam.storeUrl+"/api/appdetails?filters=basic&appids="+id, &details)
+	if err != nil || !found {
+		return "", false, err
+	}
+	app, found := details[id]
+	if !found || !app.Success {
+		return "", false, nil
+	}
+	return app.Data.Name, true, nil
+}
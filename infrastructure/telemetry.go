@@ -0,0 +1,88 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type usageBatch struct {
+	From         time.Time      `json:"from"`
+	To           time.Time      `json:"to"`
+	Usecases     map[string]int `json:"usecases"`
+	LibrarySizes map[string]int `json:"librarySizes"`
+}
+
+// Counts usage in memory and posts the totals to the endpoint once per
+// interval. A batch that fails to post is dropped, stats are best effort.
+type HttpReporter struct {
+	endpoint string
+	interval time.Duration
+	client   *http.Client
+	mu       sync.Mutex
+	batch    usageBatch
+}
+
+func NewHttpReporter(endpoint string, interval time.Duration) *HttpReporter {
+	reporter := &HttpReporter{endpoint: endpoint, interval: interval,
+		client: &http.Client{Timeout: 10 * time.Second}}
+	reporter.reset()
+	return reporter
+}
+
+func (reporter *HttpReporter) reset() {
+	reporter.batch = usageBatch{From: time.Now().UTC(), Usecases: make(map[string]int),
+		LibrarySizes: make(map[string]int)}
+}
+
+func (reporter *HttpReporter) Count(usecase string) {
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	reporter.batch.Usecases[usecase]++
+}
+
+func (reporter *HttpReporter) LibrarySize(bucket string) {
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	reporter.batch.LibrarySizes[bucket]++
+}
+
+// Posts a batch every interval, it never returns so run it in its own goroutine
+func (reporter *HttpReporter) Run() {
+	ticker := time.NewTicker(reporter.interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		err := reporter.Flush()
+		if err != nil {
+			fmt.Printf("Cannot send usage stats: %s\n", err)
+		}
+	}
+}
+
+func (reporter *HttpReporter) Flush() error {
+	reporter.mu.Lock()
+	batch := reporter.batch
+	reporter.reset()
+	reporter.mu.Unlock()
+	if len(batch.Usecases) == 0 && len(batch.LibrarySizes) == 0 {
+		return nil
+	}
+	batch.To = time.Now().UTC()
+
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	response, err := reporter.client.Post(reporter.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("endpoint answered %s", response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+// Posts photos to an HTTP vision service that reads game titles off spines
+// and cases. The service answers
+// {"titles": [{"title": "Halo 3", "confidence": 0.92}]}, titles it is unsure
+// of may still be listed with a low confidence
+type HttpVisionProvider struct {
+	url    string
+	client *http.Client
+}
+
+type recognizedTitles struct {
+	Titles []struct {
+		Title      string  `json:"title"`
+		Confidence float64 `json:"confidence"`
+	} `json:"titles"`
+}
+
+// Reading a photo takes longer than a metadata lookup
+func NewHttpVisionProvider(url string) *HttpVisionProvider {
+	return &HttpVisionProvider{url: url, client: &http.Client{Timeout: 60 * time.Second}}
+}
+
+func (provider *HttpVisionProvider) RecognizeTitles(contentType string, image []byte) ([]usecases.RecognizedTitle, error) {
+	response, err := provider.client.Post(provider.url, contentType, bytes.NewReader(image))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	// Photos the service refuses cannot be read at all, trying again will
+	// not help
+	if response.StatusCode >= 400 && response.StatusCode < 500 &&
+		response.StatusCode != http.StatusTooManyRequests {
+		return nil, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vision provider answered %s", response.Status)
+	}
+
+	var recognized recognizedTitles
+	err = json.NewDecoder(response.Body).Decode(&recognized)
+	if err != nil {
+		return nil, err
+	}
+	titles := make([]usecases.RecognizedTitle, len(recognized.Titles))
+	for i, title := range recognized.Titles {
+		titles[i] = usecases.RecognizedTitle{Title: title.Title, Confidence: title.Confidence}
+	}
+	return titles, nil
+}
@@ -0,0 +1,27 @@
+package infrastructure
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// Reads one word per line, blank lines and lines starting with # are skipped
+func LoadWordList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words = append(words, word)
+	}
+	return words, scanner.Err()
+}
@@ -0,0 +1,81 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type DbActivityRepo DbRepo
+
+func NewDbActivityRepo(dbHandlers map[string]DbHandler) *DbActivityRepo {
+	dbActivityRepo := new(DbActivityRepo)
+	dbActivityRepo.dbHandlers = dbHandlers
+	dbActivityRepo.dbHandler = dbHandlers["DbActivityRepo"]
+	return dbActivityRepo
+}
+
+func (repo DbActivityRepo) Store(activity usecases.Activity) error {
+	partners, err := json.Marshal(append([]string{}, activity.Partners...))
+	if err != nil {
+		return err
+	}
+	_, err = repo.dbHandler.Execute(`INSERT INTO activities (user_id, kind, game_id, game_name, partners)
+		VALUES ($1, $2, $3, $4, ARRAY(SELECT json_array_elements_text($5::json)))`,
+		activity.UserId, activity.Kind, activity.GameId, activity.GameName, string(partners))
+	return err
+}
+
+// Games removed since are still listed, under the name they had
+func (repo DbActivityRepo) FindByUser(userId, limit int) ([]usecases.Activity, error) {
+	statement, args := repo.dbHandler.Dialect().
+		Select("activities.id", "kind", "game_id", "coalesce(games.external_id::text, '')",
+			"game_name", "array_to_json(partners)", "activities.created_at").
+		From("activities").LeftJoin("games", "games.id = activities.game_id").
+		Where("activities.user_id = ?", userId).OrderBy("activities.id DESC").Limit(limit).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var activities []usecases.Activity
+	for row.Next() {
+		var (
+			id             int64
+			kind           string
+			gameId         int
+			gameExternalId string
+			gameName       string
+			partners       string
+			names          []string
+			createdAt      time.Time
+		)
+		err = row.Scan(&id, &kind, &gameId, &gameExternalId, &gameName, &partners, &createdAt)
+		if err == nil {
+			err = json.Unmarshal([]byte(partners), &names)
+		}
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, usecases.Activity{Id: id, UserId: userId, Kind: kind,
+			GameId: gameId, GameExternalId: gameExternalId, GameName: gameName, Partners: names,
+			CreatedAt: createdAt})
+	}
+	return activities, nil
+}
+
+func (repo DbActivityRepo) CountGames(userId int, kind string, from, to time.Time) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Select("count(DISTINCT game_id)").From("activities").
+		Where("user_id = ?", userId).Where("kind = ?", kind).
+		Where("created_at >= ?", from).Where("created_at < ?", to).Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbActivityRepo) RemoveAll(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("activities").
+		Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
@@ -0,0 +1,67 @@
+package interfaces
+
+import (
+	"game-tracker/usecases"
+)
+
+type DbAddonRepo DbRepo
+
+func NewDbAddonRepo(dbHandlers map[string]DbHandler) *DbAddonRepo {
+	dbAddonRepo := new(DbAddonRepo)
+	dbAddonRepo.dbHandlers = dbHandlers
+	dbAddonRepo.dbHandler = dbHandlers["DbAddonRepo"]
+	return dbAddonRepo
+}
+
+func (repo DbAddonRepo) Attach(addon usecases.Addon) error {
+	statement, args := repo.dbHandler.Dialect().Insert("game_addons").
+		Set("game_id", addon.GameId).Set("parent_id", addon.ParentId).Set("kind", addon.Kind).
+		OnConflict("(game_id)", "DO UPDATE SET parent_id = EXCLUDED.parent_id, kind = EXCLUDED.kind").Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbAddonRepo) Detach(gameId int) (bool, error) {
+	statement, args := repo.dbHandler.Dialect().Delete("game_addons").Where("game_id = ?", gameId).Build()
+	res, err := repo.dbHandler.Execute(statement, args...)
+	if err != nil {
+		return false, err
+	}
+	removed, err := res.RowsAffected()
+	return removed > 0, err
+}
+
+func (repo DbAddonRepo) FindByGame(gameId int) (usecases.Addon, bool, error) {
+	statement, args := repo.dbHandler.Dialect().Select("game_id", "parent_id", "kind", "created_at").
+		From("game_addons").Where("game_id = ?", gameId).Limit(1).Build()
+	addons, err := repo.query(statement, args)
+	if err != nil || len(addons) == 0 {
+		return usecases.Addon{}, false, err
+	}
+	return addons[0], true, nil
+}
+
+func (repo DbAddonRepo) FindByParent(parentId int) ([]usecases.Addon, error) {
+	statement, args := repo.dbHandler.Dialect().Select("game_id", "parent_id", "kind", "created_at").
+		From("game_addons").Where("parent_id = ?", parentId).OrderBy("created_at", "game_id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbAddonRepo) query(statement string, args []interface{}) ([]usecases.Addon, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var addons []usecases.Addon
+	for row.Next() {
+		var addon usecases.Addon
+		err = row.Scan(&addon.GameId, &addon.ParentId, &addon.Kind, &addon.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		addons = append(addons, addon)
+	}
+	return addons, nil
+}
@@ -0,0 +1,150 @@
+package interfaces
+
+import (
+	"database/sql"
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbAdminRepo DbRepo
+
+func NewDbAdminRepo(dbHandlers map[string]DbHandler) *DbAdminRepo {
+	dbAdminRepo := new(DbAdminRepo)
+	dbAdminRepo.dbHandlers = dbHandlers
+	dbAdminRepo.dbHandler = dbHandlers["DbAdminRepo"]
+	return dbAdminRepo
+}
+
+// Lists users without their players and libraries, oldest first
+func (repo DbAdminRepo) FindUsers(filter usecases.UserFilter) ([]usecases.User, error) {
+	sel := repo.dbHandler.Dialect().Select("id", "external_id", "user_name", "player_id", "role",
+		"status", "status_reason", "suspended_until", "created_at", "updated_at").From("users")
+	if filter.Name != "" {
+		sel.Where("strpos(user_name_key, ?) > 0", domain.NameKey(filter.Name))
+	}
+	if filter.Role != "" {
+		sel.Where("role = ?", filter.Role)
+	}
+	if filter.Status != "" {
+		sel.Where("status = ?", filter.Status)
+	}
+	statement, args := pageRows(sel, filter.Page, "", "id", nil, false).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var users []usecases.User
+	for row.Next() {
+		var user usecases.User
+		var suspendedUntil sql.NullTime
+		err = row.Scan(&user.Id, &user.ExternalId, &user.Name, &user.Player.Id, &user.Role,
+			&user.Status, &user.StatusReason, &suspendedUntil, &user.CreatedAt, &user.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		user.SuspendedUntil = nullTime(suspendedUntil)
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (repo DbAdminRepo) UserStats(userId int) (usecases.UserStats, error) {
+	row, err := repo.dbHandler.Query(`SELECT
+		(SELECT count(*) FROM libraries WHERE user_id = $1),
+		(SELECT count(*) FROM gamesInLib JOIN libraries ON libraries.id = gamesInLib.library_id
+			WHERE libraries.user_id = $1),
+		(SELECT count(*) FROM notifications WHERE user_id = $1),
+		(SELECT count(*) FROM changes WHERE user_id = $1),
+		(SELECT octet_length(personal_info) FROM users WHERE id = $1)`, userId)
+	if err != nil {
+		return usecases.UserStats{}, err
+	}
+	defer row.Close()
+	stats := usecases.UserStats{UserId: userId}
+	row.Next()
+	// NULL once the user is removed, its other rows may be left
+	var infoBytes sql.NullInt64
+	err = row.Scan(&stats.Libraries, &stats.Games, &stats.Notifications, &stats.Changes,
+		&infoBytes)
+	stats.InfoBytes = nullInt(infoBytes)
+	return stats, err
+}
+
+// The helpers below turn nullable columns into the pointers the domain uses,
+// nil for NULL
+
+func nullTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := value.Time.UTC()
+	return &t
+}
+
+func nullInt(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	n := int(value.Int64)
+	return &n
+}
+
+func (repo DbAdminRepo) Metrics() (usecases.Metrics, error) {
+	row, err := repo.dbHandler.Query(`SELECT
+		(SELECT count(*) FROM users),
+		(SELECT count(*) FROM players),
+		(SELECT count(*) FROM libraries),
+		(SELECT count(*) FROM games),
+		(SELECT count(*) FROM gamesInLib)`)
+	if err != nil {
+		return usecases.Metrics{}, err
+	}
+	defer row.Close()
+	metrics := usecases.Metrics{UsersByStatus: map[string]int{}}
+	row.Next()
+	err = row.Scan(&metrics.Users, &metrics.Players, &metrics.Libraries, &metrics.Games,
+		&metrics.GameEntries)
+	if err != nil {
+		return usecases.Metrics{}, err
+	}
+
+	byStatus, err := repo.dbHandler.Query("SELECT status, count(*) FROM users GROUP BY status")
+	if err != nil {
+		return usecases.Metrics{}, err
+	}
+	defer byStatus.Close()
+	for byStatus.Next() {
+		var status string
+		var count int
+		err = byStatus.Scan(&status, &count)
+		if err != nil {
+			return usecases.Metrics{}, err
+		}
+		metrics.UsersByStatus[status] = count
+	}
+	return metrics, nil
+}
+
+func (repo DbAdminRepo) SetStatus(userId int, change usecases.StatusChange) error {
+	var until sql.NullTime
+	if change.Until != nil {
+		until = sql.NullTime{Time: *change.Until, Valid: true}
+	}
+	statement, args := repo.dbHandler.Dialect().Update("users").Set("status", change.Status).
+		Set("status_reason", change.Reason).Set("suspended_until", until).
+		SetExpr("updated_at = now()").Where("id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbAdminRepo) Audit(entry usecases.AuditEntry) error {
+	statement, args := repo.dbHandler.Dialect().Insert("audit_log").Set("actor_id", entry.ActorId).
+		Set("action", entry.Action).Set("target_id", entry.TargetId).Set("detail", entry.Detail).
+		Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
@@ -0,0 +1,93 @@
+package interfaces_test
+
+import (
+	"testing"
+	"time"
+
+	"game-tracker/interfaces"
+	"game-tracker/testsupport"
+	"game-tracker/usecases"
+)
+
+func adminRepo(db *testsupport.Database) *interfaces.DbAdminRepo {
+	return interfaces.NewDbAdminRepo(map[string]interfaces.DbHandler{"DbAdminRepo": db.Handler})
+}
+
+func TestDbAdminRepoSuspendedUntil(t *testing.T) {
+	db := testsupport.Postgres(t)
+	fixtures := testsupport.NewFixtures(t, db)
+	admin := adminRepo(db)
+	alice := fixtures.User("alice")
+	bob := fixtures.User("bob")
+
+	until := time.Now().Add(time.Hour).Truncate(time.Second)
+	err := admin.SetStatus(bob.Id, usecases.StatusChange{Status: usecases.StatusSuspended,
+		Reason: "spam", Until: &until})
+	if err != nil {
+		t.Fatalf("SetStatus: %v", err)
+	}
+	users, err := admin.FindUsers(usecases.UserFilter{Page: usecases.Page{Limit: 10}})
+	if err != nil {
+		t.Fatalf("FindUsers: %v", err)
+	}
+	found := make(map[int]usecases.User)
+	for _, user := range users {
+		found[user.Id] = user
+	}
+	if found[alice.Id].SuspendedUntil != nil {
+		t.Fatalf("Active user is suspended until %v, want nil", *found[alice.Id].SuspendedUntil)
+	}
+	if found[bob.Id].SuspendedUntil == nil || !found[bob.Id].SuspendedUntil.Equal(until) {
+		t.Fatalf("Suspended user is suspended until %v, want %v", found[bob.Id].SuspendedUntil,
+			until)
+	}
+
+	user, err, _ := fixtures.Users.FindById(alice.Id)
+	if err != nil || user.SuspendedUntil != nil {
+		t.Fatalf("FindById of an active user: %+v, %v, want no suspension", user, err)
+	}
+}
+
+// The size of the info is that of the info stored last
+func TestDbAdminRepoUserStatsInfoBytes(t *testing.T) {
+	db := testsupport.Postgres(t)
+	fixtures := testsupport.NewFixtures(t, db)
+	admin := adminRepo(db)
+	user := fixtures.User("alice")
+
+	_, _, err := fixtures.Users.StoreInfo(user, "Plays on Sundays", 0)
+	if err != nil {
+		t.Fatalf("StoreInfo: %v", err)
+	}
+	stats, err := admin.UserStats(user.Id)
+	if err != nil {
+		t.Fatalf("UserStats: %v", err)
+	}
+	if stats.InfoBytes == nil || *stats.InfoBytes != len("Plays on Sundays") {
+		t.Fatalf("UserStats has %v bytes of info, want %d", stats.InfoBytes, len("Plays on Sundays"))
+	}
+}
+
+// Counting the rows of a removed user reads NULL for its info
+func TestDbAdminRepoUserStatsOfRemovedUser(t *testing.T) {
+	db := testsupport.Postgres(t)
+	fixtures := testsupport.NewFixtures(t, db)
+	admin := adminRepo(db)
+	user := fixtures.User("alice")
+
+	stats, err := admin.UserStats(user.Id)
+	if err != nil || stats.InfoBytes == nil {
+		t.Fatalf("UserStats: %+v, %v, want the size of the info", stats, err)
+	}
+	err = fixtures.Users.Remove(user)
+	if err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	stats, err = admin.UserStats(user.Id)
+	if err != nil {
+		t.Fatalf("UserStats: %v", err)
+	}
+	if stats.InfoBytes != nil {
+		t.Fatalf("Removed user has %d bytes of info, want nil", *stats.InfoBytes)
+	}
+}
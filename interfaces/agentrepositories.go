@@ -0,0 +1,196 @@
+package interfaces
+
+import (
+	"database/sql"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type DbAgentRepo DbRepo
+type DbExecutableRepo DbRepo
+
+func NewDbAgentRepo(dbHandlers map[string]DbHandler) *DbAgentRepo {
+	dbAgentRepo := new(DbAgentRepo)
+	dbAgentRepo.dbHandlers = dbHandlers
+	dbAgentRepo.dbHandler = dbHandlers["DbAgentRepo"]
+	return dbAgentRepo
+}
+
+func NewDbExecutableRepo(dbHandlers map[string]DbHandler) *DbExecutableRepo {
+	dbExecutableRepo := new(DbExecutableRepo)
+	dbExecutableRepo.dbHandlers = dbHandlers
+	dbExecutableRepo.dbHandler = dbHandlers["DbExecutableRepo"]
+	return dbExecutableRepo
+}
+
+var agentColumns = []string{"id", "user_id", "name", "token_hash", "last_seen_at", "created_at"}
+
+func (repo DbAgentRepo) Store(agent usecases.Agent) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("agents").
+		Set("user_id", agent.UserId).Set("name", agent.Name).
+		Set("token_hash", agent.TokenHash).Set("created_at", agent.CreatedAt).
+		Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbAgentRepo) FindByTokenHash(tokenHash string) (usecases.Agent, bool, error) {
+	statement, args := repo.dbHandler.Dialect().Select(agentColumns...).From("agents").
+		Where("token_hash = ?", tokenHash).Limit(1).Build()
+	agents, err := repo.query(statement, args)
+	if err != nil || len(agents) == 0 {
+		return usecases.Agent{}, false, err
+	}
+	return agents[0], true, nil
+}
+
+func (repo DbAgentRepo) FindByUser(userId int) ([]usecases.Agent, error) {
+	statement, args := repo.dbHandler.Dialect().Select(agentColumns...).From("agents").
+		Where("user_id = ?", userId).OrderBy("created_at", "id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbAgentRepo) query(statement string, args []interface{}) ([]usecases.Agent, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+	var agents []usecases.Agent
+	for row.Next() {
+		var agent usecases.Agent
+		var lastSeenAt sql.NullTime
+		err = row.Scan(&agent.Id, &agent.UserId, &agent.Name, &agent.TokenHash, &lastSeenAt,
+			&agent.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if lastSeenAt.Valid {
+			agent.LastSeenAt = lastSeenAt.Time
+		}
+		agents = append(agents, agent)
+	}
+	return agents, nil
+}
+
+func (repo DbAgentRepo) MarkSeen(id int, at time.Time) error {
+	statement, args := repo.dbHandler.Dialect().Update("agents").Set("last_seen_at", at).
+		Where("id = ?", id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbAgentRepo) Remove(agent usecases.Agent) error {
+	statement, args := repo.dbHandler.Dialect().Delete("agents").Where("id = ?", agent.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbAgentRepo) RemoveAll(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("agents").Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+var executableColumns = []string{"executable_games.user_id", "executable", "game_id",
+	"games.external_id", "games.name"}
+
+func (repo DbExecutableRepo) Map(mapping usecases.ExecutableGame) error {
+	statement, args := repo.dbHandler.Dialect().Insert("executable_games").
+		Set("user_id", mapping.UserId).Set("executable", mapping.Executable).
+		Set("game_id", mapping.GameId).
+		OnConflict("(user_id, executable)", "DO UPDATE SET game_id = EXCLUDED.game_id").Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbExecutableRepo) FindGame(userId int, executable string) (usecases.ExecutableGame, bool, error) {
+	statement, args := repo.dbHandler.Dialect().Select(executableColumns...).From("executable_games").
+		Join("games", "games.id = executable_games.game_id").
+		Where("executable_games.user_id = ?", userId).Where("executable = ?", executable).
+		Limit(1).Build()
+	mappings, err := repo.query(statement, args)
+	if err != nil || len(mappings) == 0 {
+		return usecases.ExecutableGame{}, false, err
+	}
+	return mappings[0], true, nil
+}
+
+func (repo DbExecutableRepo) FindByUser(userId int) ([]usecases.ExecutableGame, error) {
+	statement, args := repo.dbHandler.Dialect().Select(executableColumns...).From("executable_games").
+		Join("games", "games.id = executable_games.game_id").
+		Where("executable_games.user_id = ?", userId).OrderBy("executable").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbExecutableRepo) query(statement string, args []interface{}) ([]usecases.ExecutableGame, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+	var mappings []usecases.ExecutableGame
+	for row.Next() {
+		var mapping usecases.ExecutableGame
+		err = row.Scan(&mapping.UserId, &mapping.Executable, &mapping.GameId,
+			&mapping.GameExternalId, &mapping.GameName)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+func (repo DbExecutableRepo) Unmap(userId int, executable string) (bool, error) {
+	statement, args := repo.dbHandler.Dialect().Delete("executable_games").
+		Where("user_id = ?", userId).Where("executable = ?", executable).Build()
+	res, err := repo.dbHandler.Execute(statement, args...)
+	if err != nil {
+		return false, err
+	}
+	removed, err := res.RowsAffected()
+	return removed > 0, err
+}
+
+func (repo DbExecutableRepo) Start(process usecases.AgentProcess) error {
+	statement, args := repo.dbHandler.Dialect().Insert("agent_processes").
+		Set("user_id", process.UserId).Set("executable", process.Executable).
+		Set("game_id", process.GameId).Set("agent_id", process.AgentId).
+		Set("started_at", process.StartedAt).
+		OnConflict("(user_id, executable)", `DO UPDATE SET game_id = EXCLUDED.game_id,
+			agent_id = EXCLUDED.agent_id, started_at = EXCLUDED.started_at`).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbExecutableRepo) Stop(userId int, executable string) (usecases.AgentProcess, bool, error) {
+	statement, args := repo.dbHandler.Dialect().Delete("agent_processes").
+		Where("user_id = ?", userId).Where("executable = ?", executable).
+		Returning("user_id", "executable", "game_id", "coalesce(agent_id, 0)", "started_at").Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return usecases.AgentProcess{}, false, err
+	}
+	defer row.Close()
+	if !row.Next() {
+		return usecases.AgentProcess{}, false, nil
+	}
+	var process usecases.AgentProcess
+	err = row.Scan(&process.UserId, &process.Executable, &process.GameId, &process.AgentId,
+		&process.StartedAt)
+	return process, err == nil, err
+}
+
+func (repo DbExecutableRepo) RemoveAll(userId int) error {
+	return repo.dbHandler.Transaction(func(tx DbHandler) error {
+		for _, table := range []string{"agent_processes", "executable_games"} {
+			statement, args := tx.Dialect().Delete(table).Where("user_id = ?", userId).Build()
+			_, err := tx.Execute(statement, args...)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
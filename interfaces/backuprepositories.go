@@ -0,0 +1,98 @@
+package interfaces
+
+import (
+	"database/sql"
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbSaveBackupRepo DbRepo
+
+func NewDbSaveBackupRepo(dbHandlers map[string]DbHandler) *DbSaveBackupRepo {
+	dbSaveBackupRepo := new(DbSaveBackupRepo)
+	dbSaveBackupRepo.dbHandlers = dbHandlers
+	dbSaveBackupRepo.dbHandler = dbHandlers["DbSaveBackupRepo"]
+	return dbSaveBackupRepo
+}
+
+var saveBackupColumns = []string{"save_backups.id", "user_id", "library_id", "game_id",
+	"games.external_id", "games.name", "location", "size_bytes", "checksum", "backed_up_at",
+	"reminded_at", "save_backups.created_at"}
+
+func (repo DbSaveBackupRepo) Store(backup usecases.SaveBackup) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("save_backups").
+		Set("user_id", backup.UserId).Set("library_id", backup.LibraryId).Set("game_id", backup.GameId).
+		Set("location", backup.Location).Set("size_bytes", backup.SizeBytes).
+		Set("checksum", backup.Checksum).Set("backed_up_at", backup.BackedUpAt).Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbSaveBackupRepo) FindById(id int) (usecases.SaveBackup, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(saveBackupColumns...).From("save_backups").
+		Join("games", "games.id = save_backups.game_id").Where("save_backups.id = ?", id).Limit(1).Build()
+	backups, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.SaveBackup{}, err, 500
+	}
+	if len(backups) == 0 {
+		return usecases.SaveBackup{}, domain.NewError(domain.CodeNotFound,
+			"Backup #%d does not exist", id), 404
+	}
+	return backups[0], nil, 200
+}
+
+func (repo DbSaveBackupRepo) FindByGame(libraryId, gameId int) ([]usecases.SaveBackup, error) {
+	statement, args := repo.dbHandler.Dialect().Select(saveBackupColumns...).From("save_backups").
+		Join("games", "games.id = save_backups.game_id").Where("library_id = ?", libraryId).
+		Where("game_id = ?", gameId).OrderBy("backed_up_at DESC", "save_backups.id DESC").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbSaveBackupRepo) FindStale(before time.Time) ([]usecases.SaveBackup, error) {
+	statement, args := repo.dbHandler.Dialect().Select(saveBackupColumns...).From("save_backups").
+		Join("games", "games.id = save_backups.game_id").Where("reminded_at IS NULL").
+		Where("backed_up_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM save_backups newer WHERE newer.library_id = save_backups.library_id " +
+			"AND newer.game_id = save_backups.game_id " +
+			"AND (newer.backed_up_at, newer.id) > (save_backups.backed_up_at, save_backups.id))").
+		OrderBy("save_backups.id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbSaveBackupRepo) query(statement string, args []interface{}) ([]usecases.SaveBackup, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var backups []usecases.SaveBackup
+	for row.Next() {
+		var backup usecases.SaveBackup
+		var remindedAt sql.NullTime
+		err = row.Scan(&backup.Id, &backup.UserId, &backup.LibraryId, &backup.GameId,
+			&backup.GameExternalId, &backup.GameName, &backup.Location, &backup.SizeBytes,
+			&backup.Checksum, &backup.BackedUpAt, &remindedAt, &backup.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		backup.RemindedAt = remindedAt.Time
+		backups = append(backups, backup)
+	}
+	return backups, nil
+}
+
+func (repo DbSaveBackupRepo) MarkReminded(id int, at time.Time) error {
+	statement, args := repo.dbHandler.Dialect().Update("save_backups").Set("reminded_at", at).
+		Where("id = ?", id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbSaveBackupRepo) Remove(backup usecases.SaveBackup) error {
+	statement, args := repo.dbHandler.Dialect().Delete("save_backups").Where("id = ?", backup.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
@@ -0,0 +1,53 @@
+package interfaces
+
+import (
+	"game-tracker/usecases"
+)
+
+type DbBadgeRepo DbRepo
+
+func NewDbBadgeRepo(dbHandlers map[string]DbHandler) *DbBadgeRepo {
+	dbBadgeRepo := new(DbBadgeRepo)
+	dbBadgeRepo.dbHandlers = dbHandlers
+	dbBadgeRepo.dbHandler = dbHandlers["DbBadgeRepo"]
+	return dbBadgeRepo
+}
+
+func (repo DbBadgeRepo) Award(userId int, badgeId string) (bool, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("user_badges").
+		Set("user_id", userId).Set("badge_id", badgeId).
+		OnConflict("(user_id, badge_id)", "DO NOTHING").Build()
+	res, err := repo.dbHandler.Execute(statement, args...)
+	if err != nil {
+		return false, err
+	}
+	added, err := res.RowsAffected()
+	return added > 0, err
+}
+
+func (repo DbBadgeRepo) FindByUser(userId int) ([]usecases.EarnedBadge, error) {
+	statement, args := repo.dbHandler.Dialect().Select("badge_id", "earned_at").From("user_badges").
+		Where("user_id = ?", userId).OrderBy("earned_at", "badge_id").Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var badges []usecases.EarnedBadge
+	for row.Next() {
+		badge := usecases.EarnedBadge{UserId: userId}
+		err = row.Scan(&badge.BadgeId, &badge.EarnedAt)
+		if err != nil {
+			return nil, err
+		}
+		badges = append(badges, badge)
+	}
+	return badges, nil
+}
+
+func (repo DbBadgeRepo) RemoveAll(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("user_badges").Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
@@ -0,0 +1,56 @@
+package interfaces
+
+import (
+	"strconv"
+)
+
+type Cache interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte) error
+	Delete(key string) error
+	Take(key string) ([]byte, bool, error) //Gets and deletes the key in one step
+	Increment(key string) (int64, error)
+}
+
+// Maps refresh tokens to the user they were issued to
+type SessionStore interface {
+	Save(token string, userId int) error
+	Find(token string) (int, bool, error)
+	Take(token string) (int, bool, error) //Finds and revokes the token in one step
+	Revoke(token string) error
+}
+
+type CacheSessionStore struct {
+	cache Cache
+}
+
+func NewCacheSessionStore(cache Cache) *CacheSessionStore {
+	return &CacheSessionStore{cache: cache}
+}
+
+func (store CacheSessionStore) Save(token string, userId int) error {
+	return store.cache.Set(token, []byte(strconv.Itoa(userId)))
+}
+
+func (store CacheSessionStore) Find(token string) (int, bool, error) {
+	value, found, err := store.cache.Get(token)
+	return sessionUser(value, found, err)
+}
+
+// Only one of the callers taking the same token finds it
+func (store CacheSessionStore) Take(token string) (int, bool, error) {
+	value, found, err := store.cache.Take(token)
+	return sessionUser(value, found, err)
+}
+
+func sessionUser(value []byte, found bool, err error) (int, bool, error) {
+	if err != nil || !found {
+		return 0, false, err
+	}
+	userId, err := strconv.Atoi(string(value))
+	return userId, err == nil, err
+}
+
+func (store CacheSessionStore) Revoke(token string) error {
+	return store.cache.Delete(token)
+}
@@ -0,0 +1,95 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"game-tracker/usecases"
+)
+
+// Serves game lookups from the cache. Games are hardly edited once stored,
+// cached entries expire and the few edits that exist drop them.
+type CachedGameRepo struct {
+	usecases.GameRepository
+	cache  Cache
+	logger usecases.LoggerRepository //Nil prints plain lines
+}
+
+func NewCachedGameRepo(repo usecases.GameRepository, cache Cache) *CachedGameRepo {
+	return &CachedGameRepo{GameRepository: repo, cache: cache}
+}
+
+// A copy of the repo whose lines go to logger, the cache is shared
+func (repo CachedGameRepo) WithLogger(logger usecases.LoggerRepository) usecases.GameRepository {
+	repo.logger = logger
+	return &repo
+}
+
+func (repo CachedGameRepo) logf(format string, args ...interface{}) {
+	if repo.logger == nil {
+		fmt.Printf(format+"\n", args...)
+		return
+	}
+	repo.logger.Log(fmt.Sprintf(format, args...))
+}
+
+func (repo CachedGameRepo) FindById(id int) (usecases.Game, error, int) {
+	return repo.cached("game:"+strconv.Itoa(id), func() (usecases.Game, error, int) {
+		return repo.GameRepository.FindById(id)
+	})
+}
+
+func (repo CachedGameRepo) FindByExternalId(externalId string) (usecases.Game, error, int) {
+	return repo.cached("game:"+externalId, func() (usecases.Game, error, int) {
+		return repo.GameRepository.FindByExternalId(externalId)
+	})
+}
+
+func (repo CachedGameRepo) SetSpoilers(gameId int, containsSpoilers bool) error {
+	game, err, _ := repo.GameRepository.FindById(gameId)
+	if err != nil {
+		return err
+	}
+	err = repo.GameRepository.SetSpoilers(gameId, containsSpoilers)
+	if err != nil {
+		return err
+	}
+	repo.Forget(gameId, game.ExternalId)
+	return nil
+}
+
+// Drops the game under both its keys, also for edits other instances made
+func (repo CachedGameRepo) Forget(gameId int, externalId string) {
+	for _, key := range []string{"game:" + strconv.Itoa(gameId), "game:" + externalId} {
+		err := repo.cache.Delete(key)
+		if err != nil {
+			repo.logf("Cannot drop %s from cache: %v", key, err)
+		}
+	}
+}
+
+// A failing cache only costs the lookup, the repo still answers
+func (repo CachedGameRepo) cached(key string, load func() (usecases.Game, error, int)) (usecases.Game, error, int) {
+	value, found, err := repo.cache.Get(key)
+	if err != nil {
+		repo.logf("Cannot read %s from cache: %v", key, err)
+	}
+	var game usecases.Game
+	if found && json.Unmarshal(value, &game) == nil {
+		return game, nil, 200
+	}
+
+	game, err, code := load()
+	if err != nil {
+		return game, err, code
+	}
+	value, err = json.Marshal(game)
+	if err == nil {
+		err = repo.cache.Set(key, value)
+	}
+	if err != nil {
+		repo.logf("Cannot write %s to cache: %v", key, err)
+	}
+	return game, nil, code
+}
@@ -0,0 +1,467 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbPlaySessionRepo DbRepo
+type DbReleaseRepo DbRepo
+type DbCalendarTokenRepo DbRepo
+
+func NewDbPlaySessionRepo(dbHandlers map[string]DbHandler) *DbPlaySessionRepo {
+	dbPlaySessionRepo := new(DbPlaySessionRepo)
+	dbPlaySessionRepo.dbHandlers = dbHandlers
+	dbPlaySessionRepo.dbHandler = dbHandlers["DbPlaySessionRepo"]
+	return dbPlaySessionRepo
+}
+
+func NewDbReleaseRepo(dbHandlers map[string]DbHandler) *DbReleaseRepo {
+	dbReleaseRepo := new(DbReleaseRepo)
+	dbReleaseRepo.dbHandlers = dbHandlers
+	dbReleaseRepo.dbHandler = dbHandlers["DbReleaseRepo"]
+	return dbReleaseRepo
+}
+
+func NewDbCalendarTokenRepo(dbHandlers map[string]DbHandler) *DbCalendarTokenRepo {
+	dbCalendarTokenRepo := new(DbCalendarTokenRepo)
+	dbCalendarTokenRepo.dbHandlers = dbHandlers
+	dbCalendarTokenRepo.dbHandler = dbHandlers["DbCalendarTokenRepo"]
+	return dbCalendarTokenRepo
+}
+
+var playSessionColumns = []string{"play_sessions.id", "play_sessions.user_id", "game_id",
+	"games.external_id", "games.name", "starts_at", "minutes", "notes",
+	`coalesce((SELECT array_to_json(array_agg(user_id ORDER BY user_id)) FROM session_partners
+		WHERE session_id = play_sessions.id), '[]')`, "play_sessions.created_at"}
+
+func (repo DbPlaySessionRepo) Store(session usecases.PlaySession) (int, error) {
+	var id int
+	err := repo.dbHandler.Transaction(func(tx DbHandler) error {
+		statement, args := tx.Dialect().Insert("play_sessions").
+			Set("user_id", session.UserId).Set("game_id", session.GameId).
+			Set("starts_at", session.StartsAt).Set("minutes", session.Minutes).
+			Set("notes", session.Notes).Returning("id").Build()
+		var err error
+		id, err = tx.QueryRow(statement, args...)
+		if err != nil {
+			return err
+		}
+		return storePartners(tx, id, session.PartnerIds)
+	})
+	return id, err
+}
+
+func storePartners(tx DbHandler, sessionId int, partnerIds []int) error {
+	if len(partnerIds) == 0 {
+		return nil
+	}
+	_, err := tx.Execute(`INSERT INTO session_partners (session_id, user_id)
+		SELECT $1, unnest($2::int[]) ON CONFLICT DO NOTHING`, sessionId, intArray(partnerIds))
+	return err
+}
+
+// Sessions are found by id and start so that only their month's partition
+// is read
+func (repo DbPlaySessionRepo) SetDetails(session usecases.PlaySession, notes string, partnerIds []int) error {
+	return repo.dbHandler.Transaction(func(tx DbHandler) error {
+		statement, args := tx.Dialect().Update("play_sessions").Set("notes", notes).
+			Where("id = ?", session.Id).Where("starts_at = ?", session.StartsAt).Build()
+		_, err := tx.Execute(statement, args...)
+		if err != nil {
+			return err
+		}
+		statement, args = tx.Dialect().Delete("session_partners").
+			Where("session_id = ?", session.Id).Build()
+		_, err = tx.Execute(statement, args...)
+		if err != nil {
+			return err
+		}
+		return storePartners(tx, session.Id, partnerIds)
+	})
+}
+
+func (repo DbPlaySessionRepo) FindById(id int) (usecases.PlaySession, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(playSessionColumns...).From("play_sessions").
+		Join("games", "games.id = play_sessions.game_id").Where("play_sessions.id = ?", id).
+		Limit(1).Build()
+	sessions, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.PlaySession{}, err, 500
+	}
+	if len(sessions) == 0 {
+		return usecases.PlaySession{}, domain.NewError(domain.CodeNotFound,
+			"Session #%d does not exist", id), 404
+	}
+	return sessions[0], nil, 200
+}
+
+func (repo DbPlaySessionRepo) FindByUser(userId int, from, to time.Time) ([]usecases.PlaySession, error) {
+	statement, args := repo.dbHandler.Dialect().Select(playSessionColumns...).From("play_sessions").
+		Join("games", "games.id = play_sessions.game_id").Where("play_sessions.user_id = ?", userId).
+		Where("starts_at >= ?", from).Where("starts_at < ?", to).
+		OrderBy("starts_at", "play_sessions.id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbPlaySessionRepo) FindCoop(userId, partnerId int, until time.Time) ([]usecases.PlaySession, error) {
+	builder := repo.dbHandler.Dialect().Select(playSessionColumns...).From("play_sessions").
+		Join("games", "games.id = play_sessions.game_id").Where("play_sessions.user_id = ?", userId).
+		Where("starts_at < ?", until)
+	if partnerId == 0 {
+		builder.Where("EXISTS (SELECT 1 FROM session_partners WHERE session_id = play_sessions.id)")
+	} else {
+		builder.Where(`EXISTS (SELECT 1 FROM session_partners
+			WHERE session_id = play_sessions.id AND user_id = ?)`, partnerId)
+	}
+	statement, args := builder.OrderBy("starts_at", "play_sessions.id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbPlaySessionRepo) MinutesPerDay(userId int, from, to time.Time, timezone string) (map[time.Time]int, error) {
+	row, err := repo.dbHandler.Query(`SELECT (starts_at AT TIME ZONE $1)::date AS day, sum(minutes)
+		FROM play_sessions WHERE user_id = $2 AND starts_at >= $3 AND starts_at < $4
+		GROUP BY day`, timezone, userId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	minutes := make(map[time.Time]int)
+	for row.Next() {
+		var day time.Time
+		var played int
+		err = row.Scan(&day, &played)
+		if err != nil {
+			return nil, err
+		}
+		year, month, date := day.Date()
+		minutes[time.Date(year, month, date, 0, 0, 0, 0, time.UTC)] = played
+	}
+	return minutes, nil
+}
+
+func (repo DbPlaySessionRepo) query(statement string, args []interface{}) ([]usecases.PlaySession, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var sessions []usecases.PlaySession
+	for row.Next() {
+		var session usecases.PlaySession
+		var partners string
+		err = row.Scan(&session.Id, &session.UserId, &session.GameId, &session.GameExternalId,
+			&session.GameName, &session.StartsAt, &session.Minutes, &session.Notes, &partners,
+			&session.CreatedAt)
+		if err == nil {
+			err = json.Unmarshal([]byte(partners), &session.PartnerIds)
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// Partners cannot reference partitioned sessions by id alone, they are
+// removed along with them
+func (repo DbPlaySessionRepo) Remove(session usecases.PlaySession) error {
+	return repo.dbHandler.Transaction(func(tx DbHandler) error {
+		statement, args := tx.Dialect().Delete("session_partners").
+			Where("session_id = ?", session.Id).Build()
+		_, err := tx.Execute(statement, args...)
+		if err != nil {
+			return err
+		}
+		statement, args = tx.Dialect().Delete("play_sessions").Where("id = ?", session.Id).
+			Where("starts_at = ?", session.StartsAt).Build()
+		_, err = tx.Execute(statement, args...)
+		return err
+	})
+}
+
+func (repo DbPlaySessionRepo) RemoveAll(userId int) error {
+	return repo.dbHandler.Transaction(func(tx DbHandler) error {
+		statement, args := tx.Dialect().Delete("session_partners").Where(
+			"session_id IN (SELECT id FROM play_sessions WHERE user_id = ?)", userId).Build()
+		_, err := tx.Execute(statement, args...)
+		if err != nil {
+			return err
+		}
+		for _, table := range []string{"play_sessions", "play_session_months"} {
+			statement, args = tx.Dialect().Delete(table).Where("user_id = ?", userId).Build()
+			_, err = tx.Execute(statement, args...)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (repo DbPlaySessionRepo) RemovePartner(partnerId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("session_partners").
+		Where("user_id = ?", partnerId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+// Monthly partitions of play_sessions are named after the month they hold,
+// months start at midnight UTC
+const sessionPartitionPrefix = "play_sessions_"
+
+func startOfMonth(at time.Time) time.Time {
+	at = at.UTC()
+	return time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func sessionPartition(month time.Time) string {
+	return sessionPartitionPrefix + month.Format("2006_01")
+}
+
+// The months play_sessions has partitions for, keyed by their first day
+func (repo DbPlaySessionRepo) partitions() (map[time.Time]bool, error) {
+	row, err := repo.dbHandler.Query(`SELECT child.relname FROM pg_inherits
+		JOIN pg_class child ON child.oid = pg_inherits.inhrelid
+		WHERE pg_inherits.inhparent = 'play_sessions'::regclass`)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	months := make(map[time.Time]bool)
+	for row.Next() {
+		var name string
+		err = row.Scan(&name)
+		if err != nil {
+			return nil, err
+		}
+		month, err := time.Parse("2006_01", strings.TrimPrefix(name, sessionPartitionPrefix))
+		if err == nil {
+			months[month] = true
+		}
+	}
+	return months, nil
+}
+
+// Months without a partition also get one when sessions starting in them
+// wait in the default partition
+func (repo DbPlaySessionRepo) PrepareMonths(from, until time.Time) error {
+	months, err := repo.partitions()
+	if err != nil {
+		return err
+	}
+	var missing []time.Time
+	for month := startOfMonth(from); !month.After(startOfMonth(until)); month = month.AddDate(0, 1, 0) {
+		if !months[month] {
+			missing = append(missing, month)
+			months[month] = true
+		}
+	}
+	row, err := repo.dbHandler.Query(`SELECT DISTINCT date_trunc('month', starts_at AT TIME ZONE 'UTC')
+		FROM play_sessions_default`)
+	if err != nil {
+		return err
+	}
+	defer row.Close()
+	for row.Next() {
+		var month time.Time
+		err = row.Scan(&month)
+		if err != nil {
+			return err
+		}
+		month = startOfMonth(month)
+		if !months[month] {
+			missing = append(missing, month)
+			months[month] = true
+		}
+	}
+
+	for _, month := range missing {
+		err = repo.dbHandler.Transaction(func(tx DbHandler) error {
+			return createSessionPartition(tx, month)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A partition cannot be created for a range the default partition holds rows
+// of, they are moved into the new table before it is attached. Partners are
+// linked by session id and stay with the moved sessions.
+func createSessionPartition(tx DbHandler, month time.Time) error {
+	name, next := sessionPartition(month), month.AddDate(0, 1, 0)
+	_, err := tx.Execute("CREATE TABLE " + name +
+		" (LIKE play_sessions INCLUDING DEFAULTS INCLUDING CONSTRAINTS)")
+	if err != nil {
+		return err
+	}
+	_, err = tx.Execute(`WITH moved AS (DELETE FROM play_sessions_default
+			WHERE starts_at >= $1 AND starts_at < $2 RETURNING *)
+		INSERT INTO `+name+` SELECT * FROM moved`, month, next)
+	if err != nil {
+		return err
+	}
+	// Bounds of partitions cannot be bound parameters
+	_, err = tx.Execute(fmt.Sprintf("ALTER TABLE play_sessions ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')",
+		name, month.Format(time.RFC3339), next.Format(time.RFC3339)))
+	return err
+}
+
+// Whole partitions are archived and dropped, which leaves no dead rows
+// behind. Partners left by sessions removed with their game go too.
+func (repo DbPlaySessionRepo) Archive(before time.Time) error {
+	months, err := repo.partitions()
+	if err != nil {
+		return err
+	}
+	for month := range months {
+		if month.AddDate(0, 1, 0).After(before) {
+			continue
+		}
+		name := sessionPartition(month)
+		err = repo.dbHandler.Transaction(func(tx DbHandler) error {
+			_, err := tx.Execute(`INSERT INTO play_session_months (user_id, game_id, month, sessions, minutes)
+				SELECT user_id, game_id, $1::date, count(*), sum(minutes) FROM `+name+`
+				GROUP BY user_id, game_id
+				ON CONFLICT (user_id, game_id, month) DO UPDATE SET
+					sessions = play_session_months.sessions + EXCLUDED.sessions,
+					minutes = play_session_months.minutes + EXCLUDED.minutes`,
+				month.Format("2006-01-02"))
+			if err != nil {
+				return err
+			}
+			_, err = tx.Execute("DELETE FROM session_partners WHERE session_id IN (SELECT id FROM " +
+				name + ")")
+			if err != nil {
+				return err
+			}
+			_, err = tx.Execute("ALTER TABLE play_sessions DETACH PARTITION " + name)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Execute("DROP TABLE " + name)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	_, err = repo.dbHandler.Execute(`DELETE FROM session_partners WHERE NOT EXISTS (
+		SELECT 1 FROM play_sessions WHERE play_sessions.id = session_partners.session_id)`)
+	return err
+}
+
+// A moved date has to be announced again
+func (repo DbReleaseRepo) Store(release usecases.Release) error {
+	statement, args := repo.dbHandler.Dialect().Insert("releases").
+		Set("user_id", release.UserId).Set("game_id", release.GameId).
+		Set("release_date", release.Date.Format("2006-01-02")).Set("source", release.Source).
+		OnConflict("(user_id, game_id)", `DO UPDATE SET release_date = EXCLUDED.release_date,
+			source = EXCLUDED.source, updated_at = now(),
+			announced_at = CASE WHEN releases.release_date = EXCLUDED.release_date
+				THEN releases.announced_at END`).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+var releaseColumns = []string{"user_id", "game_id", "games.external_id", "games.name",
+	"release_date", "source", "releases.updated_at"}
+
+func (repo DbReleaseRepo) FindByUser(userId int, from time.Time) ([]usecases.Release, error) {
+	statement, args := repo.dbHandler.Dialect().Select(releaseColumns...).From("releases").
+		Join("games", "games.id = releases.game_id").Where("user_id = ?", userId).
+		Where("release_date >= ?", from.Format("2006-01-02")).OrderBy("release_date", "game_id").
+		Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbReleaseRepo) FindUnannounced() ([]usecases.Release, error) {
+	statement, args := repo.dbHandler.Dialect().Select(releaseColumns...).From("releases").
+		Join("games", "games.id = releases.game_id").Where("announced_at IS NULL").
+		OrderBy("release_date", "game_id", "user_id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbReleaseRepo) query(statement string, args []interface{}) ([]usecases.Release, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var releases []usecases.Release
+	for row.Next() {
+		var release usecases.Release
+		err = row.Scan(&release.UserId, &release.GameId, &release.GameExternalId, &release.GameName,
+			&release.Date, &release.Source, &release.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
+
+func (repo DbReleaseRepo) MarkAnnounced(userId, gameId int) error {
+	statement, args := repo.dbHandler.Dialect().Update("releases").SetExpr("announced_at = now()").
+		Where("user_id = ?", userId).Where("game_id = ?", gameId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbReleaseRepo) Remove(userId, gameId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("releases").Where("user_id = ?", userId).
+		Where("game_id = ?", gameId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbReleaseRepo) RemoveAll(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("releases").Where("user_id = ?", userId).
+		Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbCalendarTokenRepo) Store(userId int, tokenHash string) error {
+	statement, args := repo.dbHandler.Dialect().Insert("calendar_tokens").
+		Set("user_id", userId).Set("token_hash", tokenHash).
+		OnConflict("(user_id)", "DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = now()").
+		Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbCalendarTokenRepo) FindUserId(tokenHash string) (int, bool, error) {
+	statement, args := repo.dbHandler.Dialect().Select("user_id").From("calendar_tokens").
+		Where("token_hash = ?", tokenHash).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return 0, false, err
+	}
+	defer row.Close()
+	if !row.Next() {
+		return 0, false, nil
+	}
+	var userId int
+	err = row.Scan(&userId)
+	return userId, err == nil, err
+}
+
+func (repo DbCalendarTokenRepo) Remove(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("calendar_tokens").
+		Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
@@ -0,0 +1,105 @@
+package interfaces
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"game-tracker/usecases"
+)
+
+type DbCatalogRepo DbRepo
+
+func NewDbCatalogRepo(dbHandlers map[string]DbHandler) *DbCatalogRepo {
+	dbCatalogRepo := new(DbCatalogRepo)
+	dbCatalogRepo.dbHandlers = dbHandlers
+	dbCatalogRepo.dbHandler = dbHandlers["DbCatalogRepo"]
+	return dbCatalogRepo
+}
+
+type catalogRow struct {
+	Name     string      `json:"name"`
+	Key      string      `json:"key"`
+	LeavesOn interface{} `json:"leavesOn"`
+}
+
+func catalogKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// The entries go in as one JSON array rather than a statement per game
+func (repo DbCatalogRepo) Replace(service string, entries []usecases.CatalogEntry) error {
+	rows := []catalogRow{}
+	for _, entry := range entries {
+		rows = append(rows, catalogRow{Name: entry.Name, Key: catalogKey(entry.Name),
+			LeavesOn: nullDate(entry.LeavesOn)})
+	}
+	encoded, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	return repo.dbHandler.Transaction(func(tx DbHandler) error {
+		statement, args := tx.Dialect().Delete("catalog_games").Where("service = ?", service).Build()
+		_, err := tx.Execute(statement, args...)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Execute(`INSERT INTO catalog_games (service, name, name_key, leaves_on)
+			SELECT $1, entry->>'name', entry->>'key', (entry->>'leavesOn')::date
+			FROM json_array_elements($2::json) AS entry ON CONFLICT DO NOTHING`, service, string(encoded))
+		return err
+	})
+}
+
+func (repo DbCatalogRepo) FindByNames(names []string) ([]usecases.CatalogEntry, error) {
+	keys := []string{}
+	for _, name := range names {
+		keys = append(keys, catalogKey(name))
+	}
+	encoded, err := json.Marshal(keys)
+	if err != nil {
+		return nil, err
+	}
+	statement, args := repo.dbHandler.Dialect().
+		Select("service", "name", "leaves_on", "updated_at").From("catalog_games").
+		Where("name_key IN (SELECT json_array_elements_text(?::json))", string(encoded)).
+		OrderBy("service", "name_key").Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var entries []usecases.CatalogEntry
+	for row.Next() {
+		var entry usecases.CatalogEntry
+		var leavesOn sql.NullTime
+		err = row.Scan(&entry.Service, &entry.Name, &leavesOn, &entry.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entry.LeavesOn = leavesOn.Time
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (repo DbCatalogRepo) StoreAlert(userId int, entry usecases.CatalogEntry) (bool, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("catalog_alerts").
+		Set("user_id", userId).Set("service", entry.Service).Set("name_key", catalogKey(entry.Name)).
+		Set("leaves_on", nullDate(entry.LeavesOn)).
+		OnConflict("(user_id, service, name_key, leaves_on)", "DO NOTHING").Build()
+	res, err := repo.dbHandler.Execute(statement, args...)
+	if err != nil {
+		return false, err
+	}
+	added, err := res.RowsAffected()
+	return added > 0, err
+}
+
+func (repo DbCatalogRepo) RemoveAlerts(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("catalog_alerts").
+		Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
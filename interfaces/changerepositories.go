@@ -0,0 +1,114 @@
+package interfaces
+
+import (
+	"database/sql"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type DbChangeRepo DbRepo
+
+func NewDbChangeRepo(dbHandlers map[string]DbHandler) *DbChangeRepo {
+	dbChangeRepo := new(DbChangeRepo)
+	dbChangeRepo.dbHandlers = dbHandlers
+	dbChangeRepo.dbHandler = dbHandlers["DbChangeRepo"]
+	return dbChangeRepo
+}
+
+func (repo DbChangeRepo) FindSince(userId int, after int64, limit int) ([]usecases.Change, error) {
+	statement, args := repo.dbHandler.Dialect().Select("id", "entity", "entity_id", "parent_id",
+		"action", "changed_at").From("changes").Where("user_id = ?", userId).
+		Where("id > ?", after).OrderBy("id").Limit(limit).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var changes []usecases.Change
+	for row.Next() {
+		change := usecases.Change{UserId: userId}
+		var parentId sql.NullString
+		err = row.Scan(&change.Id, &change.Entity, &change.EntityId, &parentId, &change.Action,
+			&change.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		change.ParentId = parentId.String
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func (repo DbChangeRepo) FindByParent(parentId string, until time.Time) ([]usecases.Change, error) {
+	statement, args := repo.dbHandler.Dialect().Select("id", "user_id", "entity", "entity_id",
+		"action", "changed_at").From("changes").Where("parent_id = ?", parentId).
+		Where("changed_at < ?", until).OrderBy("id").Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var changes []usecases.Change
+	for row.Next() {
+		change := usecases.Change{ParentId: parentId}
+		err = row.Scan(&change.Id, &change.UserId, &change.Entity, &change.EntityId, &change.Action,
+			&change.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// The log helpers below are called by the other repositories on every write,
+// removals have to be logged before the row disappears or with removeLogged
+
+func logUserChange(dbHandler DbHandler, userId int, action string) error {
+	_, err := dbHandler.Execute(`INSERT INTO changes (user_id, entity, entity_id, action)
+		SELECT id, 'user', external_id, $2 FROM users WHERE id = $1`, userId, action)
+	return err
+}
+
+func logLibraryChange(dbHandler DbHandler, libraryId int, action string) error {
+	_, err := dbHandler.Execute(`INSERT INTO changes (user_id, entity, entity_id, action)
+		SELECT user_id, 'library', external_id, $2 FROM libraries WHERE id = $1`, libraryId, action)
+	return err
+}
+
+// Runs a DELETE returning the user id and external id of the row, then logs
+// its removal. Nothing is logged when the row was gone or the DELETE failed,
+// tx is meant to be a transaction so both happen or neither.
+func removeLogged(tx DbHandler, statement, entity string, id int) error {
+	row, err := tx.Query(statement, id)
+	if err != nil {
+		return err
+	}
+	var userId int
+	var externalId string
+	removed := row.Next()
+	if removed {
+		err = row.Scan(&userId, &externalId)
+	}
+	closeErr := row.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil || !removed {
+		return closeErr
+	}
+	_, err = tx.Execute(`INSERT INTO changes (user_id, entity, entity_id, action)
+		VALUES ($1, $2, $3, $4)`, userId, entity, externalId, usecases.ChangeDeleted)
+	return err
+}
+
+func logGameChange(dbHandler DbHandler, libraryId, gameId int, action string) error {
+	_, err := dbHandler.Execute(`INSERT INTO changes (user_id, entity, entity_id, parent_id, action)
+		SELECT libraries.user_id, 'game', games.external_id, libraries.external_id, $3
+		FROM libraries JOIN games ON games.id = $2 WHERE libraries.id = $1`,
+		libraryId, gameId, action)
+	return err
+}
@@ -0,0 +1,141 @@
+package interfaces
+
+import (
+	"database/sql"
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbPhysicalCopyRepo DbRepo
+
+func NewDbPhysicalCopyRepo(dbHandlers map[string]DbHandler) *DbPhysicalCopyRepo {
+	dbPhysicalCopyRepo := new(DbPhysicalCopyRepo)
+	dbPhysicalCopyRepo.dbHandlers = dbHandlers
+	dbPhysicalCopyRepo.dbHandler = dbHandlers["DbPhysicalCopyRepo"]
+	return dbPhysicalCopyRepo
+}
+
+var physicalCopyColumns = []string{"physical_copies.id", "library_id", "game_id", "games.external_id",
+	"games.name", "barcode", "platform", "paid_price", "paid_currency", "physical_copies.value",
+	"value_currency", "valued_at", "physical_copies.created_at"}
+
+func (repo DbPhysicalCopyRepo) Store(physical usecases.PhysicalCopy) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("physical_copies").
+		Set("library_id", physical.LibraryId).Set("game_id", physical.GameId).
+		Set("barcode", physical.Barcode).Set("platform", physical.Platform).Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbPhysicalCopyRepo) FindById(id int) (usecases.PhysicalCopy, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(physicalCopyColumns...).From("physical_copies").
+		Join("games", "games.id = physical_copies.game_id").Where("physical_copies.id = ?", id).
+		Limit(1).Build()
+	copies, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.PhysicalCopy{}, err, 500
+	}
+	if len(copies) == 0 {
+		return usecases.PhysicalCopy{}, domain.NewError(domain.CodeNotFound,
+			"Copy #%d does not exist", id), 404
+	}
+	return copies[0], nil, 200
+}
+
+func (repo DbPhysicalCopyRepo) FindByLib(libraryId int, page usecases.Page) ([]usecases.PhysicalCopy, error) {
+	selection := repo.dbHandler.Dialect().Select(physicalCopyColumns...).From("physical_copies").
+		Join("games", "games.id = physical_copies.game_id").Where("library_id = ?", libraryId)
+	statement, args := pageRows(selection, page, "games.name", "physical_copies.id", page.After.Key,
+		false).Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbPhysicalCopyRepo) query(statement string, args []interface{}) ([]usecases.PhysicalCopy, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var copies []usecases.PhysicalCopy
+	for row.Next() {
+		var physical usecases.PhysicalCopy
+		var valuedAt sql.NullTime
+		err = row.Scan(&physical.Id, &physical.LibraryId, &physical.GameId, &physical.GameExternalId,
+			&physical.GameName, &physical.Barcode, &physical.Platform, &physical.PaidPrice,
+			&physical.PaidCurrency, &physical.Value, &physical.ValueCurrency, &valuedAt,
+			&physical.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		physical.ValuedAt = valuedAt.Time
+		copies = append(copies, physical)
+	}
+	return copies, nil
+}
+
+func (repo DbPhysicalCopyRepo) Remove(physical usecases.PhysicalCopy) error {
+	statement, args := repo.dbHandler.Dialect().Delete("physical_copies").
+		Where("id = ?", physical.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbPhysicalCopyRepo) RemoveFromLib(libraryId, gameId int) error {
+	deletion := repo.dbHandler.Dialect().Delete("physical_copies").Where("library_id = ?", libraryId)
+	if gameId > 0 {
+		deletion.Where("game_id = ?", gameId)
+	}
+	statement, args := deletion.Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbPhysicalCopyRepo) FindBarcode(barcode string) (int, bool, error) {
+	statement, args := repo.dbHandler.Dialect().Select("game_id").From("barcodes").
+		Where("barcode = ?", barcode).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return 0, false, err
+	}
+	defer row.Close()
+
+	if !row.Next() {
+		return 0, false, nil
+	}
+	var gameId int
+	err = row.Scan(&gameId)
+	return gameId, err == nil, err
+}
+
+func (repo DbPhysicalCopyRepo) LinkBarcode(barcode string, gameId int) error {
+	statement, args := repo.dbHandler.Dialect().Insert("barcodes").Set("barcode", barcode).
+		Set("game_id", gameId).OnConflict("(barcode)", "DO UPDATE SET game_id = EXCLUDED.game_id").
+		Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbPhysicalCopyRepo) SetPaidPrice(copyId int, price float64, currency string) error {
+	statement, args := repo.dbHandler.Dialect().Update("physical_copies").Set("paid_price", price).
+		Set("paid_currency", currency).Where("id = ?", copyId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbPhysicalCopyRepo) FindStaleValues(before time.Time, limit int) ([]usecases.PhysicalCopy, error) {
+	statement, args := repo.dbHandler.Dialect().Select(physicalCopyColumns...).From("physical_copies").
+		Join("games", "games.id = physical_copies.game_id").
+		Where("(valued_at IS NULL OR valued_at < ?)", before).
+		OrderBy("valued_at NULLS FIRST", "physical_copies.id").Limit(limit).Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbPhysicalCopyRepo) StoreValue(copyId int, estimate usecases.PriceEstimate) error {
+	statement, args := repo.dbHandler.Dialect().Update("physical_copies").Set("value", estimate.Value).
+		Set("value_currency", estimate.Currency).SetExpr("valued_at = now()").
+		Where("id = ?", copyId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
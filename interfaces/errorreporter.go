@@ -0,0 +1,7 @@
+package interfaces
+
+// Receives panics recovered while serving requests, implementations must
+// not block the request they are called from
+type ErrorReporter interface {
+	Report(err error, stack []byte, tags map[string]string)
+}
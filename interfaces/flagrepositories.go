@@ -0,0 +1,60 @@
+package interfaces
+
+import (
+	"encoding/json"
+
+	"game-tracker/usecases"
+)
+
+type DbFlagRepo DbRepo
+
+func NewDbFlagRepo(dbHandlers map[string]DbHandler) *DbFlagRepo {
+	dbFlagRepo := new(DbFlagRepo)
+	dbFlagRepo.dbHandlers = dbHandlers
+	dbFlagRepo.dbHandler = dbHandlers["DbFlagRepo"]
+	return dbFlagRepo
+}
+
+func (repo DbFlagRepo) FindAll() ([]usecases.FeatureFlag, error) {
+	statement, args := repo.dbHandler.Dialect().Select("name", "description", "enabled",
+		"percentage", "array_to_json(user_ids)", "updated_at").From("feature_flags").
+		OrderBy("name").Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var flags []usecases.FeatureFlag
+	for row.Next() {
+		var flag usecases.FeatureFlag
+		var userIds string
+		err = row.Scan(&flag.Name, &flag.Description, &flag.Enabled, &flag.Percentage, &userIds,
+			&flag.UpdatedAt)
+		if err == nil {
+			err = json.Unmarshal([]byte(userIds), &flag.UserIds)
+		}
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, flag)
+	}
+	return flags, nil
+}
+
+func (repo DbFlagRepo) Store(flag usecases.FeatureFlag) error {
+	_, err := repo.dbHandler.Execute(`INSERT INTO feature_flags (name, description, enabled,
+		percentage, user_ids) VALUES ($1, $2, $3, $4, $5::int[])
+		ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description,
+		enabled = EXCLUDED.enabled, percentage = EXCLUDED.percentage,
+		user_ids = EXCLUDED.user_ids, updated_at = now()`,
+		flag.Name, flag.Description, flag.Enabled, flag.Percentage, intArray(flag.UserIds))
+	return err
+}
+
+func (repo DbFlagRepo) Remove(name string) error {
+	statement, args := repo.dbHandler.Dialect().Delete("feature_flags").Where("name = ?", name).
+		Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
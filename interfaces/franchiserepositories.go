@@ -0,0 +1,146 @@
+package interfaces
+
+import (
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbFranchiseRepo DbRepo
+
+func NewDbFranchiseRepo(dbHandlers map[string]DbHandler) *DbFranchiseRepo {
+	dbFranchiseRepo := new(DbFranchiseRepo)
+	dbFranchiseRepo.dbHandlers = dbHandlers
+	dbFranchiseRepo.dbHandler = dbHandlers["DbFranchiseRepo"]
+	return dbFranchiseRepo
+}
+
+func (repo DbFranchiseRepo) Store(franchise usecases.Franchise) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("franchises").Set("name", franchise.Name).
+		Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+// Entries go with the franchise through ON DELETE CASCADE
+func (repo DbFranchiseRepo) Remove(franchise usecases.Franchise) error {
+	statement, args := repo.dbHandler.Dialect().Delete("franchises").
+		Where("id = ?", franchise.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbFranchiseRepo) FindById(id int) (usecases.Franchise, error, int) {
+	return repo.findFranchise("id = ?", id)
+}
+
+func (repo DbFranchiseRepo) FindByExternalId(externalId string) (usecases.Franchise, error, int) {
+	return repo.findFranchise("external_id = ?", externalId)
+}
+
+func (repo DbFranchiseRepo) findFranchise(condition string, value interface{}) (usecases.Franchise, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select("id", "external_id", "name", "created_at").
+		From("franchises").Where(condition, value).Limit(1).Build()
+	franchises, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.Franchise{}, err, 500
+	}
+	if len(franchises) == 0 {
+		return usecases.Franchise{}, domain.NewError(domain.CodeNotFound,
+			"Franchise %v does not exist", value), 404
+	}
+	franchise := franchises[0]
+
+	statement, args = repo.dbHandler.Dialect().Select("game_id", "games.external_id", "games.name",
+		"position").From("franchise_games").Join("games", "games.id = franchise_games.game_id").
+		Where("franchise_id = ?", franchise.Id).OrderBy("position", "game_id").Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return usecases.Franchise{}, err, 500
+	}
+	defer row.Close()
+	for row.Next() {
+		var game usecases.FranchiseGame
+		err = row.Scan(&game.GameId, &game.GameExternalId, &game.GameName, &game.Position)
+		if err != nil {
+			return usecases.Franchise{}, err, 500
+		}
+		franchise.Games = append(franchise.Games, game)
+	}
+	return franchise, nil, 200
+}
+
+func (repo DbFranchiseRepo) FindAll() ([]usecases.Franchise, error) {
+	statement, args := repo.dbHandler.Dialect().Select("id", "external_id", "name", "created_at").
+		From("franchises").OrderBy("name", "id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbFranchiseRepo) query(statement string, args []interface{}) ([]usecases.Franchise, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var franchises []usecases.Franchise
+	for row.Next() {
+		var franchise usecases.Franchise
+		err = row.Scan(&franchise.Id, &franchise.ExternalId, &franchise.Name, &franchise.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		franchises = append(franchises, franchise)
+	}
+	return franchises, nil
+}
+
+func (repo DbFranchiseRepo) AddGame(franchiseId, gameId, position int) error {
+	statement, args := repo.dbHandler.Dialect().Insert("franchise_games").
+		Set("franchise_id", franchiseId).Set("game_id", gameId).Set("position", position).
+		OnConflict("(franchise_id, game_id)", "DO UPDATE SET position = EXCLUDED.position").Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbFranchiseRepo) RemoveGame(franchiseId, gameId int) (bool, error) {
+	statement, args := repo.dbHandler.Dialect().Delete("franchise_games").
+		Where("franchise_id = ?", franchiseId).Where("game_id = ?", gameId).Build()
+	res, err := repo.dbHandler.Execute(statement, args...)
+	if err != nil {
+		return false, err
+	}
+	removed, err := res.RowsAffected()
+	return removed > 0, err
+}
+
+// A game is owned when any library of the user holds it and completed when
+// it has the completed status in any of them
+func (repo DbFranchiseRepo) FindProgress(userId int) ([]usecases.FranchiseProgress, error) {
+	row, err := repo.dbHandler.Query(`SELECT franchises.id, franchises.external_id,
+			franchises.name, franchises.created_at, count(*), count(owned.game_id),
+			count(owned.game_id) FILTER (WHERE owned.completed)
+		FROM franchises
+		JOIN franchise_games ON franchise_games.franchise_id = franchises.id
+		LEFT JOIN (SELECT gamesInLib.game_id, bool_or(gamesInLib.status = 'completed') AS completed
+			FROM gamesInLib JOIN libraries ON libraries.id = gamesInLib.library_id
+			WHERE libraries.user_id = $1 GROUP BY gamesInLib.game_id) owned
+			ON owned.game_id = franchise_games.game_id
+		GROUP BY franchises.id
+		HAVING count(owned.game_id) > 0
+		ORDER BY franchises.name, franchises.id`, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var progress []usecases.FranchiseProgress
+	for row.Next() {
+		var entry usecases.FranchiseProgress
+		err = row.Scan(&entry.Franchise.Id, &entry.Franchise.ExternalId, &entry.Franchise.Name,
+			&entry.Franchise.CreatedAt, &entry.Total, &entry.Owned, &entry.Completed)
+		if err != nil {
+			return nil, err
+		}
+		progress = append(progress, entry)
+	}
+	return progress, nil
+}
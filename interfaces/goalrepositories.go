@@ -0,0 +1,102 @@
+package interfaces
+
+import (
+	"database/sql"
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbGoalRepo DbRepo
+
+func NewDbGoalRepo(dbHandlers map[string]DbHandler) *DbGoalRepo {
+	dbGoalRepo := new(DbGoalRepo)
+	dbGoalRepo.dbHandlers = dbHandlers
+	dbGoalRepo.dbHandler = dbHandlers["DbGoalRepo"]
+	return dbGoalRepo
+}
+
+var goalColumns = []string{"id", "user_id", "kind", "target", "period", "year", "quarter",
+	"starts_at", "ends_at", "reminded_at", "created_at"}
+
+func (repo DbGoalRepo) Store(goal usecases.Goal) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("goals").
+		Set("user_id", goal.UserId).Set("kind", goal.Kind).Set("target", goal.Target).
+		Set("period", goal.Period).Set("year", goal.Year).Set("quarter", goal.Quarter).
+		Set("starts_at", goal.StartsAt).Set("ends_at", goal.EndsAt).Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbGoalRepo) FindById(id int) (usecases.Goal, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(goalColumns...).From("goals").
+		Where("id = ?", id).Limit(1).Build()
+	goals, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.Goal{}, err, 500
+	}
+	if len(goals) == 0 {
+		return usecases.Goal{}, domain.NewError(domain.CodeNotFound, "Goal #%d does not exist", id), 404
+	}
+	return goals[0], nil, 200
+}
+
+func (repo DbGoalRepo) FindByUser(userId int) ([]usecases.Goal, error) {
+	statement, args := repo.dbHandler.Dialect().Select(goalColumns...).From("goals").
+		Where("user_id = ?", userId).OrderBy("starts_at DESC", "id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbGoalRepo) FindRunning(now time.Time) ([]usecases.Goal, error) {
+	statement, args := repo.dbHandler.Dialect().Select(goalColumns...).From("goals").
+		Where("starts_at <= ?", now).Where("ends_at > ?", now).OrderBy("id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbGoalRepo) query(statement string, args []interface{}) ([]usecases.Goal, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var goals []usecases.Goal
+	for row.Next() {
+		var goal usecases.Goal
+		var remindedAt sql.NullTime
+		err = row.Scan(&goal.Id, &goal.UserId, &goal.Kind, &goal.Target, &goal.Period, &goal.Year,
+			&goal.Quarter, &goal.StartsAt, &goal.EndsAt, &remindedAt, &goal.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		goal.RemindedAt = remindedAt.Time
+		goals = append(goals, goal)
+	}
+	return goals, nil
+}
+
+func (repo DbGoalRepo) SetTarget(id, target int) error {
+	statement, args := repo.dbHandler.Dialect().Update("goals").Set("target", target).
+		Where("id = ?", id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbGoalRepo) MarkReminded(id int, at time.Time) error {
+	statement, args := repo.dbHandler.Dialect().Update("goals").Set("reminded_at", at).
+		Where("id = ?", id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbGoalRepo) Remove(goal usecases.Goal) error {
+	statement, args := repo.dbHandler.Dialect().Delete("goals").Where("id = ?", goal.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbGoalRepo) RemoveAll(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("goals").Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
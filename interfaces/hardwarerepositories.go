@@ -0,0 +1,120 @@
+package interfaces
+
+import (
+	"database/sql"
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbHardwareRepo DbRepo
+
+func NewDbHardwareRepo(dbHandlers map[string]DbHandler) *DbHardwareRepo {
+	dbHardwareRepo := new(DbHardwareRepo)
+	dbHardwareRepo.dbHandlers = dbHandlers
+	dbHardwareRepo.dbHandler = dbHandlers["DbHardwareRepo"]
+	return dbHardwareRepo
+}
+
+var hardwareColumns = []string{"id", "user_id", "kind", "name", "model", "serial", "purchased_on",
+	"warranty_until", "reminded_at", "created_at", "updated_at"}
+
+// Dates the user left out are stored as NULL
+func nullDate(day time.Time) interface{} {
+	if day.IsZero() {
+		return nil
+	}
+	return day.Format("2006-01-02")
+}
+
+func (repo DbHardwareRepo) Store(item usecases.Hardware) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("hardware").
+		Set("user_id", item.UserId).Set("kind", item.Kind).Set("name", item.Name).
+		Set("model", item.Model).Set("serial", item.Serial).
+		Set("purchased_on", nullDate(item.PurchasedOn)).
+		Set("warranty_until", nullDate(item.WarrantyUntil)).Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbHardwareRepo) FindById(id int) (usecases.Hardware, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(hardwareColumns...).From("hardware").
+		Where("id = ?", id).Limit(1).Build()
+	items, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.Hardware{}, err, 500
+	}
+	if len(items) == 0 {
+		return usecases.Hardware{}, domain.NewError(domain.CodeNotFound,
+			"Hardware #%d does not exist", id), 404
+	}
+	return items[0], nil, 200
+}
+
+func (repo DbHardwareRepo) FindByUser(userId int) ([]usecases.Hardware, error) {
+	statement, args := repo.dbHandler.Dialect().Select(hardwareColumns...).From("hardware").
+		Where("user_id = ?", userId).OrderBy("kind", "name", "id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbHardwareRepo) FindExpiring(from, to time.Time) ([]usecases.Hardware, error) {
+	statement, args := repo.dbHandler.Dialect().Select(hardwareColumns...).From("hardware").
+		Where("reminded_at IS NULL").Where("warranty_until >= ?", from.Format("2006-01-02")).
+		Where("warranty_until <= ?", to.Format("2006-01-02")).OrderBy("id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbHardwareRepo) query(statement string, args []interface{}) ([]usecases.Hardware, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var items []usecases.Hardware
+	for row.Next() {
+		var item usecases.Hardware
+		var purchasedOn, warrantyUntil, remindedAt sql.NullTime
+		err = row.Scan(&item.Id, &item.UserId, &item.Kind, &item.Name, &item.Model, &item.Serial,
+			&purchasedOn, &warrantyUntil, &remindedAt, &item.CreatedAt, &item.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		item.PurchasedOn = purchasedOn.Time
+		item.WarrantyUntil = warrantyUntil.Time
+		item.RemindedAt = remindedAt.Time
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (repo DbHardwareRepo) Update(item usecases.Hardware) error {
+	statement, args := repo.dbHandler.Dialect().Update("hardware").
+		Set("kind", item.Kind).Set("name", item.Name).Set("model", item.Model).
+		Set("serial", item.Serial).Set("purchased_on", nullDate(item.PurchasedOn)).
+		Set("warranty_until", nullDate(item.WarrantyUntil)).
+		SetExpr("reminded_at = CASE WHEN warranty_until IS NOT DISTINCT FROM ?::date THEN reminded_at END",
+			nullDate(item.WarrantyUntil)).
+		SetExpr("updated_at = now()").Where("id = ?", item.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbHardwareRepo) MarkReminded(id int, at time.Time) error {
+	statement, args := repo.dbHandler.Dialect().Update("hardware").Set("reminded_at", at).
+		Where("id = ?", id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbHardwareRepo) Remove(item usecases.Hardware) error {
+	statement, args := repo.dbHandler.Dialect().Delete("hardware").Where("id = ?", item.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbHardwareRepo) RemoveAll(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("hardware").Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
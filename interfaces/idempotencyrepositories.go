@@ -0,0 +1,68 @@
+package interfaces
+
+type IdempotentResponse struct {
+	Fingerprint string
+	Status      int //0 while the original request is still being processed
+	ContentType string
+	Body        []byte
+}
+
+type DbIdempotencyRepo DbRepo
+
+func NewDbIdempotencyRepo(dbHandlers map[string]DbHandler) *DbIdempotencyRepo {
+	dbIdempotencyRepo := new(DbIdempotencyRepo)
+	dbIdempotencyRepo.dbHandlers = dbHandlers
+	dbIdempotencyRepo.dbHandler = dbHandlers["DbIdempotencyRepo"]
+	return dbIdempotencyRepo
+}
+
+// Claims the key for a new request, returns false when it is already taken
+func (repo DbIdempotencyRepo) Reserve(scope, key, fingerprint string) (bool, error) {
+	_, err := repo.dbHandler.Execute(`DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2
+		AND created_at < now() - interval '24 hours'`, scope, key)
+	if err != nil {
+		return false, err
+	}
+	res, err := repo.dbHandler.Execute(`INSERT INTO idempotency_keys (scope, key, fingerprint)
+		VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`, scope, key, fingerprint)
+	if err != nil {
+		return false, err
+	}
+	inserted, err := res.RowsAffected()
+	return inserted == 1, err
+}
+
+func (repo DbIdempotencyRepo) Find(scope, key string) (IdempotentResponse, bool, error) {
+	statement, args := repo.dbHandler.Dialect().Select("fingerprint", "status", "content_type",
+		"body").
+		From("idempotency_keys").Where("scope = ?", scope).Where("key = ?", key).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return IdempotentResponse{}, false, err
+	}
+	defer row.Close()
+	if !row.Next() {
+		return IdempotentResponse{}, false, nil
+	}
+	var response IdempotentResponse
+	err = row.Scan(&response.Fingerprint, &response.Status, &response.ContentType, &response.Body)
+	if err != nil {
+		return IdempotentResponse{}, true, err
+	}
+	return response, true, nil
+}
+
+func (repo DbIdempotencyRepo) Complete(scope, key string, status int, contentType string,
+	body []byte) error {
+	statement, args := repo.dbHandler.Dialect().Update("idempotency_keys").Set("status", status).
+		Set("content_type", contentType).Set("body", body).Where("scope = ?", scope).Where("key = ?", key).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbIdempotencyRepo) Release(scope, key string) error {
+	statement, args := repo.dbHandler.Dialect().Delete("idempotency_keys").
+		Where("scope = ?", scope).Where("key = ?", key).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
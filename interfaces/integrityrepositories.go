@@ -0,0 +1,112 @@
+package interfaces
+
+import (
+	"fmt"
+
+	"game-tracker/usecases"
+)
+
+type DbIntegrityRepo DbRepo
+
+// The rows breaking a check, as the FROM and WHERE of a query selecting
+// their id, and the statement repairing all of them
+type dbIntegrityCheck struct {
+	id     string
+	from   string
+	repair string
+}
+
+var dbIntegrityChecks = map[string]dbIntegrityCheck{
+	usecases.IntegrityUsersWithoutPlayers: {"users.id",
+		"users WHERE NOT EXISTS (SELECT 1 FROM players WHERE players.id = users.player_id)",
+		`WITH broken AS (
+			SELECT id, user_name FROM users
+			WHERE NOT EXISTS (SELECT 1 FROM players WHERE players.id = users.player_id)),
+		named AS (
+			INSERT INTO players (player_name) SELECT DISTINCT user_name FROM broken
+			ON CONFLICT (player_name) DO UPDATE SET player_name = EXCLUDED.player_name
+			RETURNING id, player_name)
+		UPDATE users SET player_id = named.id FROM broken, named
+		WHERE users.id = broken.id AND named.player_name = broken.user_name`},
+	usecases.IntegrityLibrariesWithoutUsers: {"libraries.id",
+		"libraries WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = libraries.user_id)",
+		`DELETE FROM libraries
+		WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = libraries.user_id)`},
+	usecases.IntegrityEntriesWithoutLibraries: {"gamesInLib.id",
+		"gamesInLib WHERE NOT EXISTS (SELECT 1 FROM libraries WHERE libraries.id = gamesInLib.library_id)",
+		`DELETE FROM gamesInLib
+		WHERE NOT EXISTS (SELECT 1 FROM libraries WHERE libraries.id = gamesInLib.library_id)`},
+	usecases.IntegrityEntriesWithoutGames: {"gamesInLib.id",
+		"gamesInLib WHERE NOT EXISTS (SELECT 1 FROM games WHERE games.id = gamesInLib.game_id)",
+		`DELETE FROM gamesInLib
+		WHERE NOT EXISTS (SELECT 1 FROM games WHERE games.id = gamesInLib.game_id)`},
+	usecases.IntegrityCopiesWithoutLibraries: {"physical_copies.id",
+		"physical_copies WHERE NOT EXISTS (SELECT 1 FROM libraries WHERE libraries.id = physical_copies.library_id)",
+		`DELETE FROM physical_copies
+		WHERE NOT EXISTS (SELECT 1 FROM libraries WHERE libraries.id = physical_copies.library_id)`},
+	usecases.IntegrityMembersWithoutOwners: {"library_members.user_id",
+		`library_members WHERE NOT EXISTS (SELECT 1 FROM libraries WHERE libraries.id = library_members.library_id)
+			OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = library_members.user_id)`,
+		`DELETE FROM library_members
+		WHERE NOT EXISTS (SELECT 1 FROM libraries WHERE libraries.id = library_members.library_id)
+			OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = library_members.user_id)`},
+	usecases.IntegrityNotificationsOrphaned: {"notifications.id",
+		"notifications WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = notifications.user_id)",
+		`DELETE FROM notifications
+		WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = notifications.user_id)`},
+	usecases.IntegritySettingsOrphaned: {"settings.user_id",
+		"settings WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = settings.user_id)",
+		`DELETE FROM settings
+		WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = settings.user_id)`},
+	usecases.IntegrityDefaultLibraryMissing: {"settings.user_id",
+		`settings WHERE default_library_id IS NOT NULL
+			AND NOT EXISTS (SELECT 1 FROM libraries WHERE libraries.id = settings.default_library_id)`,
+		`UPDATE settings SET default_library_id = NULL WHERE default_library_id IS NOT NULL
+			AND NOT EXISTS (SELECT 1 FROM libraries WHERE libraries.id = settings.default_library_id)`},
+	usecases.IntegrityLoginsWithoutUsers: {"loginInfo.id",
+		"loginInfo WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.user_name = loginInfo.username)",
+		`DELETE FROM loginInfo
+		WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.user_name = loginInfo.username)`},
+}
+
+func NewDbIntegrityRepo(dbHandlers map[string]DbHandler) *DbIntegrityRepo {
+	dbIntegrityRepo := new(DbIntegrityRepo)
+	dbIntegrityRepo.dbHandlers = dbHandlers
+	dbIntegrityRepo.dbHandler = dbHandlers["DbIntegrityRepo"]
+	return dbIntegrityRepo
+}
+
+func dbIntegrityCheckOf(name string) (dbIntegrityCheck, error) {
+	check, found := dbIntegrityChecks[name]
+	if !found {
+		return dbIntegrityCheck{}, fmt.Errorf("Unknown integrity check '%s'", name)
+	}
+	return check, nil
+}
+
+func (repo DbIntegrityRepo) FindBroken(name string, limit int) ([]int, int, error) {
+	check, err := dbIntegrityCheckOf(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	count, err := repo.dbHandler.QueryRow("SELECT count(*) FROM " + check.from)
+	if err != nil || count == 0 {
+		return nil, count, err
+	}
+	ids, err := queryIds(repo.dbHandler, fmt.Sprintf("SELECT %s FROM %s ORDER BY 1 LIMIT $1",
+		check.id, check.from), limit)
+	return ids, count, err
+}
+
+func (repo DbIntegrityRepo) Repair(name string) (int, error) {
+	check, err := dbIntegrityCheckOf(name)
+	if err != nil {
+		return 0, err
+	}
+	result, err := repo.dbHandler.Execute(check.repair)
+	if err != nil {
+		return 0, err
+	}
+	repaired, err := result.RowsAffected()
+	return int(repaired), err
+}
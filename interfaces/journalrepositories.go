@@ -0,0 +1,118 @@
+package interfaces
+
+import (
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbJournalRepo DbRepo
+
+func NewDbJournalRepo(dbHandlers map[string]DbHandler) *DbJournalRepo {
+	dbJournalRepo := new(DbJournalRepo)
+	dbJournalRepo.dbHandlers = dbHandlers
+	dbJournalRepo.dbHandler = dbHandlers["DbJournalRepo"]
+	return dbJournalRepo
+}
+
+var journalColumns = []string{"journal_entries.id", "user_id", "game_id", "games.external_id",
+	"games.name", "text", "text_html", "spoiler_free_html", "has_spoilers", "screenshot",
+	"screenshot_type", "journal_entries.created_at"}
+
+func (repo DbJournalRepo) Store(entry usecases.JournalEntry) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("journal_entries").
+		Set("user_id", entry.UserId).Set("game_id", entry.GameId).Set("text", entry.Text).
+		Set("text_html", entry.Html).Set("spoiler_free_html", entry.SpoilerFreeHtml).
+		Set("has_spoilers", entry.HasSpoilers).
+		Set("screenshot", entry.Screenshot).Set("screenshot_type", entry.ScreenshotType).
+		Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbJournalRepo) FindById(id int) (usecases.JournalEntry, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(journalColumns...).From("journal_entries").
+		Join("games", "games.id = journal_entries.game_id").Where("journal_entries.id = ?", id).
+		Limit(1).Build()
+	entries, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.JournalEntry{}, err, 500
+	}
+	if len(entries) == 0 {
+		return usecases.JournalEntry{}, domain.NewError(domain.CodeNotFound,
+			"Journal entry #%d does not exist", id), 404
+	}
+	return entries[0], nil, 200
+}
+
+func (repo DbJournalRepo) FindByUser(userId, gameId int, page usecases.Page) ([]usecases.JournalEntry, error) {
+	after, err := page.After.Time()
+	if err != nil {
+		return nil, err
+	}
+	selection := repo.dbHandler.Dialect().Select(journalColumns...).From("journal_entries").
+		Join("games", "games.id = journal_entries.game_id").Where("user_id = ?", userId)
+	if gameId > 0 {
+		selection.Where("game_id = ?", gameId)
+	}
+	statement, args := pageRows(selection, page, "journal_entries.created_at", "journal_entries.id",
+		after, false).Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbJournalRepo) query(statement string, args []interface{}) ([]usecases.JournalEntry, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var entries []usecases.JournalEntry
+	for row.Next() {
+		var entry usecases.JournalEntry
+		err = row.Scan(&entry.Id, &entry.UserId, &entry.GameId, &entry.GameExternalId,
+			&entry.GameName, &entry.Text, &entry.Html, &entry.SpoilerFreeHtml, &entry.HasSpoilers,
+			&entry.Screenshot, &entry.ScreenshotType, &entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (repo DbJournalRepo) StoreHtml(entry usecases.JournalEntry) error {
+	statement, args := repo.dbHandler.Dialect().Update("journal_entries").
+		Set("text_html", entry.Html).Set("spoiler_free_html", entry.SpoilerFreeHtml).
+		Set("has_spoilers", entry.HasSpoilers).Where("id = ?", entry.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbJournalRepo) Remove(entry usecases.JournalEntry) error {
+	statement, args := repo.dbHandler.Dialect().Delete("journal_entries").
+		Where("id = ?", entry.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbJournalRepo) RemoveAll(userId int) ([]string, error) {
+	statement, args := repo.dbHandler.Dialect().Delete("journal_entries").
+		Where("user_id = ?", userId).Returning("screenshot").Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var keys []string
+	for row.Next() {
+		var key string
+		err = row.Scan(&key)
+		if err != nil {
+			return nil, err
+		}
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,579 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+// An entry as library_events and library_snapshots keep it, the same shape
+// json_to_recordset reads when a replay rewrites gamesInLib
+type libraryEntryData struct {
+	GameId       int      `json:"gameId"`
+	Status       string   `json:"status"`
+	Platform     string   `json:"platform"`
+	Tags         []string `json:"tags"`
+	WishlistRank int      `json:"wishlistRank"`
+}
+
+// The data column of library_events, only the fields of the kind are set
+type libraryEventData struct {
+	UserId   int                `json:"userId,omitempty"`
+	Entries  []libraryEntryData `json:"entries,omitempty"`
+	GameIds  []int              `json:"gameIds,omitempty"`
+	Status   *string            `json:"status,omitempty"`
+	Platform *string            `json:"platform,omitempty"`
+	Tags     *[]string          `json:"tags,omitempty"`
+	Ranks    map[int]int        `json:"ranks,omitempty"`
+	From     []string           `json:"from,omitempty"`
+	To       string             `json:"to,omitempty"`
+}
+
+type librarySnapshotData struct {
+	UserId  int                `json:"userId"`
+	Removed bool               `json:"removed"`
+	Entries []libraryEntryData `json:"entries"`
+}
+
+func entriesData(entries []usecases.LibraryEntry) []libraryEntryData {
+	data := []libraryEntryData{}
+	for _, entry := range entries {
+		tags := entry.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		data = append(data, libraryEntryData{GameId: entry.GameId, Status: entry.Status,
+			Platform: entry.Platform, Tags: tags, WishlistRank: entry.WishlistRank})
+	}
+	return data
+}
+
+func entriesOf(data []libraryEntryData) []usecases.LibraryEntry {
+	var entries []usecases.LibraryEntry
+	for _, entry := range data {
+		entries = append(entries, usecases.LibraryEntry{GameId: entry.GameId, Status: entry.Status,
+			Platform: entry.Platform, Tags: entry.Tags, WishlistRank: entry.WishlistRank})
+	}
+	return entries
+}
+
+// Keeps the history of event sourced libraries in library_events, with a
+// snapshot in library_snapshots every snapshotEvery events
+type DbLibraryEventStore struct {
+	DbRepo
+	snapshotEvery int64 //0 takes no snapshots
+}
+
+func NewDbLibraryEventStore(dbHandlers map[string]DbHandler, snapshotEvery int) *DbLibraryEventStore {
+	dbLibraryEventStore := new(DbLibraryEventStore)
+	dbLibraryEventStore.dbHandlers = dbHandlers
+	dbLibraryEventStore.dbHandler = dbHandlers["DbLibraryEventStore"]
+	dbLibraryEventStore.snapshotEvery = int64(snapshotEvery)
+	return dbLibraryEventStore
+}
+
+// Locks the library for the write about to happen in tx, so its events are
+// numbered in the order they are written. A library without events gets
+// its current entries recorded first, its history starts from them.
+// Returns false when the library does not exist.
+func (store DbLibraryEventStore) begin(tx DbHandler, libraryId int) (bool, error) {
+	row, err := tx.Query(`SELECT EXISTS (SELECT 1 FROM library_events WHERE library_id = $1)
+		FROM libraries WHERE id = $1 FOR UPDATE`, libraryId)
+	if err != nil {
+		return false, err
+	}
+	if !row.Next() {
+		row.Close()
+		return false, nil
+	}
+	var recorded bool
+	err = row.Scan(&recorded)
+	row.Close()
+	if err != nil || recorded {
+		return err == nil, err
+	}
+
+	entries, err := findEntries(tx, libraryId)
+	if err != nil {
+		return false, err
+	}
+	return true, store.append(tx, usecases.LibraryEvent{LibraryId: libraryId,
+		Kind: usecases.LibrarySeeded, Entries: entries})
+}
+
+func findEntries(dbHandler DbHandler, libraryId int) ([]usecases.LibraryEntry, error) {
+	statement, args := dbHandler.Dialect().Select("game_id", "status", "platform",
+		"array_to_json(tags)", "wishlist_rank").From("gamesInLib").
+		Where("library_id = ?", libraryId).OrderBy("game_id").Build()
+	row, err := dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+	var entries []usecases.LibraryEntry
+	for row.Next() {
+		var entry usecases.LibraryEntry
+		var tags string
+		err = row.Scan(&entry.GameId, &entry.Status, &entry.Platform, &tags, &entry.WishlistRank)
+		if err == nil {
+			err = json.Unmarshal([]byte(tags), &entry.Tags)
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Appends the event after the last one of its library and takes a snapshot
+// when the sequence calls for one
+func (store DbLibraryEventStore) append(tx DbHandler, event usecases.LibraryEvent) error {
+	data, err := json.Marshal(libraryEventData{UserId: event.UserId,
+		Entries: entriesData(event.Entries), GameIds: event.GameIds, Status: event.Change.Status,
+		Platform: event.Change.Platform, Tags: event.Change.Tags, Ranks: event.Ranks,
+		From: event.From, To: event.To})
+	if err != nil {
+		return err
+	}
+	sequence, err := tx.QueryRow(`INSERT INTO library_events (library_id, sequence, kind, data)
+		SELECT $1, coalesce(max(sequence), 0) + 1, $2, $3::jsonb FROM library_events
+		WHERE library_id = $1
+		RETURNING sequence`, event.LibraryId, event.Kind, string(data))
+	if err != nil {
+		return err
+	}
+	if store.snapshotEvery == 0 || int64(sequence)%store.snapshotEvery != 0 {
+		return nil
+	}
+
+	state, err := store.load(tx, event.LibraryId)
+	if err != nil {
+		return err
+	}
+	snapshot, err := json.Marshal(librarySnapshotData{UserId: state.UserId, Removed: state.Removed,
+		Entries: entriesData(state.SortedEntries())})
+	if err != nil {
+		return err
+	}
+	statement, args := tx.Dialect().Insert("library_snapshots").Set("library_id", state.LibraryId).
+		Set("sequence", state.Sequence).Set("state", string(snapshot)).Build()
+	_, err = tx.Execute(statement, args...)
+	return err
+}
+
+func (store DbLibraryEventStore) FindEvents(libraryId int, after int64, limit int) ([]usecases.LibraryEvent, error) {
+	return store.findEvents(store.dbHandler, libraryId, after, limit)
+}
+
+// A limit of 0 finds every event after after
+func (store DbLibraryEventStore) findEvents(dbHandler DbHandler, libraryId int, after int64,
+	limit int) ([]usecases.LibraryEvent, error) {
+	selection := dbHandler.Dialect().Select("sequence", "kind", "data::text", "recorded_at").
+		From("library_events").Where("library_id = ?", libraryId).Where("sequence > ?", after).
+		OrderBy("sequence")
+	if limit > 0 {
+		selection.Limit(limit)
+	}
+	statement, args := selection.Build()
+	row, err := dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var events []usecases.LibraryEvent
+	for row.Next() {
+		event := usecases.LibraryEvent{LibraryId: libraryId}
+		var data string
+		err = row.Scan(&event.Sequence, &event.Kind, &data, &event.RecordedAt)
+		if err != nil {
+			return nil, err
+		}
+		var fields libraryEventData
+		err = json.Unmarshal([]byte(data), &fields)
+		if err != nil {
+			return nil, err
+		}
+		event.UserId = fields.UserId
+		event.Entries = entriesOf(fields.Entries)
+		event.GameIds = fields.GameIds
+		event.Change = usecases.GameChange{Status: fields.Status, Platform: fields.Platform,
+			Tags: fields.Tags}
+		event.Ranks = fields.Ranks
+		event.From = fields.From
+		event.To = fields.To
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (store DbLibraryEventStore) Load(libraryId int) (usecases.LibraryState, error) {
+	return store.load(store.dbHandler, libraryId)
+}
+
+func (store DbLibraryEventStore) load(dbHandler DbHandler, libraryId int) (usecases.LibraryState, error) {
+	return store.fold(dbHandler, libraryId, 0)
+}
+
+// Folds the events up to the sequence until onto the latest snapshot before
+// it, 0 folds every event
+func (store DbLibraryEventStore) fold(dbHandler DbHandler, libraryId int, until int64) (usecases.LibraryState, error) {
+	state := usecases.LibraryState{LibraryId: libraryId, Entries: make(map[int]usecases.LibraryEntry)}
+	selection := dbHandler.Dialect().Select("sequence", "state::text").From("library_snapshots").
+		Where("library_id = ?", libraryId)
+	if until > 0 {
+		selection.Where("sequence <= ?", until)
+	}
+	statement, args := selection.OrderBy("sequence DESC").Limit(1).Build()
+	row, err := dbHandler.Query(statement, args...)
+	if err != nil {
+		return state, err
+	}
+	if row.Next() {
+		var data string
+		err = row.Scan(&state.Sequence, &data)
+		var snapshot librarySnapshotData
+		if err == nil {
+			err = json.Unmarshal([]byte(data), &snapshot)
+		}
+		if err != nil {
+			row.Close()
+			return state, err
+		}
+		state.UserId = snapshot.UserId
+		state.Removed = snapshot.Removed
+		for _, entry := range entriesOf(snapshot.Entries) {
+			state.Entries[entry.GameId] = entry
+		}
+	}
+	row.Close()
+	if until > 0 && state.Sequence == until {
+		return state, nil
+	}
+
+	limit := 0
+	if until > 0 {
+		limit = int(until - state.Sequence)
+	}
+	events, err := store.findEvents(dbHandler, libraryId, state.Sequence, limit)
+	if err != nil {
+		return state, err
+	}
+	for _, event := range events {
+		state.Apply(event)
+	}
+	return state, nil
+}
+
+// False when the first event of the library was recorded at or after at
+func (store DbLibraryEventStore) LoadAt(libraryId int, at time.Time) (usecases.LibraryState, bool, error) {
+	statement, args := store.dbHandler.Dialect().Select("coalesce(max(sequence), 0)").
+		From("library_events").Where("library_id = ?", libraryId).Where("recorded_at < ?", at).Build()
+	last, err := store.dbHandler.QueryRow(statement, args...)
+	if err != nil || last == 0 {
+		return usecases.LibraryState{}, false, err
+	}
+	state, err := store.fold(store.dbHandler, libraryId, int64(last))
+	if err != nil {
+		return usecases.LibraryState{}, false, err
+	}
+	return state, true, nil
+}
+
+// The projection is rewritten in one transaction and the library's version
+// bumped, so sync clients load it anew
+func (store DbLibraryEventStore) Replay(libraryId int) (usecases.LibraryState, error, int) {
+	var state usecases.LibraryState
+	code := 500
+	err := store.dbHandler.Transaction(func(tx DbHandler) error {
+		row, err := tx.Query(`SELECT EXISTS (SELECT 1 FROM library_events WHERE library_id = $1)
+			FROM libraries WHERE id = $1 FOR UPDATE`, libraryId)
+		if err != nil {
+			return err
+		}
+		found := row.Next()
+		var recorded bool
+		if found {
+			err = row.Scan(&recorded)
+		}
+		row.Close()
+		if err != nil {
+			return err
+		}
+		if !found {
+			code = 404
+			return domain.NewError(domain.CodeNotFound, "Library #%d does not exist", libraryId)
+		}
+		if !recorded {
+			code = 404
+			return domain.NewError(domain.CodeNotFound, "Library #%d has no events to replay", libraryId)
+		}
+
+		state, err = store.load(tx, libraryId)
+		if err != nil {
+			return err
+		}
+		entries, err := json.Marshal(entriesData(state.SortedEntries()))
+		if err != nil {
+			return err
+		}
+		statement, args := tx.Dialect().Delete("gamesInLib").Where("library_id = ?", libraryId).Build()
+		_, err = tx.Execute(statement, args...)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Execute(`INSERT INTO gamesInLib (game_id, library_id, status, platform, tags,
+				wishlist_rank)
+			SELECT entry."gameId", $1, entry.status, entry.platform,
+				ARRAY(SELECT json_array_elements_text(entry.tags)), entry."wishlistRank"
+			FROM json_to_recordset($2::json) AS entry ("gameId" INTEGER, status TEXT,
+				platform TEXT, tags JSON, "wishlistRank" INTEGER)`, libraryId, string(entries))
+		if err != nil {
+			return err
+		}
+		bump, bumpArgs := tx.Dialect().Update("libraries").SetExpr("version = version + 1").
+			SetExpr("updated_at = now()").Where("id = ?", libraryId).Build()
+		_, err = tx.Execute(bump, bumpArgs...)
+		if err != nil {
+			return err
+		}
+		return logLibraryChange(tx, libraryId, usecases.ChangeUpdated)
+	})
+	if err != nil {
+		return usecases.LibraryState{}, err, code
+	}
+	return state, nil, 200
+}
+
+// Libraries whose writes are appended to the event store, DbLibraryRepo
+// keeps the libraries table as their projection in the same transaction
+type EventSourcedLibraryRepo struct {
+	*DbLibraryRepo
+	events *DbLibraryEventStore
+}
+
+func NewEventSourcedLibraryRepo(dbHandlers map[string]DbHandler, users usecases.UserRepository,
+	events *DbLibraryEventStore) *EventSourcedLibraryRepo {
+	return &EventSourcedLibraryRepo{DbLibraryRepo: NewDbLibraryRepo(dbHandlers, users), events: events}
+}
+
+func (repo EventSourcedLibraryRepo) projection(tx DbHandler) *DbLibraryRepo {
+	return NewDbLibraryRepo(map[string]DbHandler{"DbLibraryRepo": tx}, repo.users)
+}
+
+func (repo EventSourcedLibraryRepo) Store(library usecases.Library) (int, error) {
+	var id int
+	err := repo.dbHandler.Transaction(func(tx DbHandler) error {
+		var err error
+		id, err = repo.projection(tx).Store(library)
+		if err != nil {
+			return err
+		}
+		return repo.events.append(tx, usecases.LibraryEvent{LibraryId: id,
+			Kind: usecases.LibraryCreated, UserId: library.User.Id})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// The events stay, the history of removed libraries is kept
+func (repo EventSourcedLibraryRepo) Remove(library usecases.Library) error {
+	return repo.dbHandler.Transaction(func(tx DbHandler) error {
+		found, err := repo.events.begin(tx, library.Id)
+		if err != nil {
+			return err
+		}
+		if found {
+			err = repo.events.append(tx, usecases.LibraryEvent{LibraryId: library.Id,
+				Kind: usecases.LibraryRemoved})
+			if err != nil {
+				return err
+			}
+		}
+		return repo.projection(tx).Remove(library)
+	})
+}
+
+// Games whose library entries are event sourced, the other methods are
+// DbGameRepo's as games themselves are not part of the history
+type EventSourcedGameRepo struct {
+	*DbGameRepo
+	events *DbLibraryEventStore
+}
+
+func NewEventSourcedGameRepo(dbHandlers map[string]DbHandler, events *DbLibraryEventStore) *EventSourcedGameRepo {
+	return &EventSourcedGameRepo{DbGameRepo: NewDbGameRepo(dbHandlers), events: events}
+}
+
+// Runs write on a projection bound to one transaction and appends the
+// events it returns for the libraries that exist
+func (repo EventSourcedGameRepo) record(libraryIds []int,
+	write func(projection *DbGameRepo) ([]usecases.LibraryEvent, error)) error {
+	return repo.dbHandler.Transaction(func(tx DbHandler) error {
+		found := make(map[int]bool)
+		for _, libraryId := range libraryIds {
+			exists, err := repo.events.begin(tx, libraryId)
+			if err != nil {
+				return err
+			}
+			found[libraryId] = exists
+		}
+		events, err := write(NewDbGameRepo(map[string]DbHandler{"DbGameRepo": tx}))
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if !found[event.LibraryId] {
+				continue
+			}
+			err = repo.events.append(tx, event)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (repo EventSourcedGameRepo) AddToLib(gameId, libraryId int, baseVersion int64) (error, int) {
+	code := 500
+	err := repo.record([]int{libraryId}, func(projection *DbGameRepo) ([]usecases.LibraryEvent, error) {
+		var err error
+		err, code = projection.AddToLib(gameId, libraryId, baseVersion)
+		if err != nil {
+			return nil, err
+		}
+		return []usecases.LibraryEvent{{LibraryId: libraryId, Kind: usecases.GamesAdded,
+			Entries: []usecases.LibraryEntry{{GameId: gameId, Status: usecases.DefaultGameStatus}}}}, nil
+	})
+	if err != nil {
+		if code == 200 {
+			code = 500
+		}
+		return err, code
+	}
+	return nil, 200
+}
+
+func (repo EventSourcedGameRepo) AddBatchToLib(libraryId int, entries []usecases.Game) ([]int, error) {
+	var added []int
+	err := repo.record([]int{libraryId}, func(projection *DbGameRepo) ([]usecases.LibraryEvent, error) {
+		var err error
+		added, err = projection.AddBatchToLib(libraryId, entries)
+		if err != nil || len(added) == 0 {
+			return nil, err
+		}
+		byId := make(map[int]usecases.Game)
+		for _, entry := range entries {
+			if _, found := byId[entry.Id]; !found {
+				byId[entry.Id] = entry
+			}
+		}
+		event := usecases.LibraryEvent{LibraryId: libraryId, Kind: usecases.GamesAdded}
+		for _, gameId := range added {
+			status := byId[gameId].Status
+			if status == "" {
+				status = usecases.DefaultGameStatus
+			}
+			event.Entries = append(event.Entries, usecases.LibraryEntry{GameId: gameId, Status: status,
+				Platform: byId[gameId].Platform})
+		}
+		return []usecases.LibraryEvent{event}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+func (repo EventSourcedGameRepo) RemoveFromLib(game usecases.Game, libraryId int, baseVersion int64) (error, int) {
+	code := 500
+	err := repo.record([]int{libraryId}, func(projection *DbGameRepo) ([]usecases.LibraryEvent, error) {
+		var err error
+		err, code = projection.RemoveFromLib(game, libraryId, baseVersion)
+		if err != nil {
+			return nil, err
+		}
+		return []usecases.LibraryEvent{{LibraryId: libraryId, Kind: usecases.GameRemoved,
+			GameIds: []int{game.Id}}}, nil
+	})
+	if err != nil {
+		if code == 200 {
+			code = 500
+		}
+		return err, code
+	}
+	return nil, 200
+}
+
+func (repo EventSourcedGameRepo) UpdateBatch(libraryId int, gameIds []int, change usecases.GameChange, baseVersions map[int]int64) (map[int]int64, error) {
+	var versions map[int]int64
+	err := repo.record([]int{libraryId}, func(projection *DbGameRepo) ([]usecases.LibraryEvent, error) {
+		var err error
+		versions, err = projection.UpdateBatch(libraryId, gameIds, change, baseVersions)
+		if err != nil || len(versions) == 0 {
+			return nil, err
+		}
+		var updated []int
+		for _, gameId := range gameIds {
+			if _, found := versions[gameId]; found {
+				updated = append(updated, gameId)
+			}
+		}
+		return []usecases.LibraryEvent{{LibraryId: libraryId, Kind: usecases.GamesChanged,
+			GameIds: updated, Change: change}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (repo EventSourcedGameRepo) RankWishlist(libraryId int, ranks map[int]int) error {
+	return repo.record([]int{libraryId}, func(projection *DbGameRepo) ([]usecases.LibraryEvent, error) {
+		err := projection.RankWishlist(libraryId, ranks)
+		if err != nil {
+			return nil, err
+		}
+		return []usecases.LibraryEvent{{LibraryId: libraryId, Kind: usecases.WishlistRanked,
+			Ranks: ranks}}, nil
+	})
+}
+
+// Only libraries in which entries changed get an event
+func (repo EventSourcedGameRepo) ReplaceTags(libraryIds []int, from []string, to string) (map[int]int, error) {
+	var changed map[int]int
+	err := repo.record(libraryIds, func(projection *DbGameRepo) ([]usecases.LibraryEvent, error) {
+		var err error
+		changed, err = projection.ReplaceTags(libraryIds, from, to)
+		if err != nil {
+			return nil, err
+		}
+		var events []usecases.LibraryEvent
+		for libraryId := range changed {
+			events = append(events, usecases.LibraryEvent{LibraryId: libraryId,
+				Kind: usecases.TagsReplaced, From: from, To: to})
+		}
+		return events, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+func (repo EventSourcedGameRepo) RestoreEntries(libraryId int, gameIds []int, entries []usecases.LibraryEntry) error {
+	return repo.record([]int{libraryId}, func(projection *DbGameRepo) ([]usecases.LibraryEvent, error) {
+		err := projection.RestoreEntries(libraryId, gameIds, entries)
+		if err != nil {
+			return nil, err
+		}
+		return []usecases.LibraryEvent{{LibraryId: libraryId, Kind: usecases.EntriesRestored,
+			GameIds: gameIds, Entries: entries}}, nil
+	})
+}
@@ -0,0 +1,54 @@
+package interfaces
+
+import (
+	"sync"
+)
+
+type MaintenanceStatus struct {
+	Enabled    bool
+	RetryAfter int //Seconds clients are told to wait
+	Reason     string
+}
+
+// The runtime read-only switch, while it is on writes are rejected and
+// background jobs calling Wait hold off until it is turned off again
+type Maintenance struct {
+	mu      sync.Mutex
+	status  MaintenanceStatus
+	resumed chan struct{}
+}
+
+func NewMaintenance(status MaintenanceStatus) *Maintenance {
+	maintenance := &Maintenance{resumed: make(chan struct{})}
+	maintenance.Set(status)
+	return maintenance
+}
+
+func (maintenance *Maintenance) Status() MaintenanceStatus {
+	maintenance.mu.Lock()
+	defer maintenance.mu.Unlock()
+	return maintenance.status
+}
+
+func (maintenance *Maintenance) Set(status MaintenanceStatus) {
+	maintenance.mu.Lock()
+	defer maintenance.mu.Unlock()
+	if maintenance.status.Enabled && !status.Enabled {
+		close(maintenance.resumed)
+		maintenance.resumed = make(chan struct{})
+	}
+	maintenance.status = status
+}
+
+// Blocks while maintenance is on, jobs call it between units of work so
+// they pause at a safe point instead of failing halfway
+func (maintenance *Maintenance) Wait() {
+	maintenance.mu.Lock()
+	if !maintenance.status.Enabled {
+		maintenance.mu.Unlock()
+		return
+	}
+	resumed := maintenance.resumed
+	maintenance.mu.Unlock()
+	<-resumed
+}
@@ -0,0 +1,119 @@
+package interfaces
+
+import (
+	"encoding/json"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbMatchRepo DbRepo
+
+func NewDbMatchRepo(dbHandlers map[string]DbHandler) *DbMatchRepo {
+	dbMatchRepo := new(DbMatchRepo)
+	dbMatchRepo.dbHandlers = dbHandlers
+	dbMatchRepo.dbHandler = dbHandlers["DbMatchRepo"]
+	return dbMatchRepo
+}
+
+var matchColumns = []string{"matches.id", "user_id", "game_id", "games.external_id", "games.name",
+	"result", "score", "opponents", "played_at", "matches.created_at"}
+
+func (repo DbMatchRepo) StoreBatch(matches []usecases.Match) ([]int, error) {
+	var ids []int
+	err := repo.dbHandler.Transaction(func(tx DbHandler) error {
+		for _, match := range matches {
+			opponents, err := json.Marshal(match.Opponents)
+			if err != nil {
+				return err
+			}
+			if match.Opponents == nil {
+				opponents = []byte("[]")
+			}
+			statement, args := tx.Dialect().Insert("matches").
+				Set("user_id", match.UserId).Set("game_id", match.GameId).Set("result", match.Result).
+				Set("score", match.Score).Set("opponents", string(opponents)).
+				Set("played_at", match.PlayedAt).Returning("id").Build()
+			id, err := tx.QueryRow(statement, args...)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (repo DbMatchRepo) FindById(id int) (usecases.Match, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(matchColumns...).From("matches").
+		Join("games", "games.id = matches.game_id").Where("matches.id = ?", id).Limit(1).Build()
+	matches, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.Match{}, err, 500
+	}
+	if len(matches) == 0 {
+		return usecases.Match{}, domain.NewError(domain.CodeNotFound, "Match #%d does not exist", id), 404
+	}
+	return matches[0], nil, 200
+}
+
+func (repo DbMatchRepo) FindByUser(userId int, filter usecases.MatchFilter) ([]usecases.Match, error) {
+	after, err := filter.Page.After.Time()
+	if err != nil {
+		return nil, err
+	}
+	builder := repo.dbHandler.Dialect().Select(matchColumns...).From("matches").
+		Join("games", "games.id = matches.game_id").Where("user_id = ?", userId)
+	if filter.GameId != 0 {
+		builder.Where("game_id = ?", filter.GameId)
+	}
+	if !filter.From.IsZero() {
+		builder.Where("played_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		builder.Where("played_at < ?", filter.To)
+	}
+	statement, args := pageRows(builder, filter.Page, "played_at", "matches.id", after, true).Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbMatchRepo) query(statement string, args []interface{}) ([]usecases.Match, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var matches []usecases.Match
+	for row.Next() {
+		var match usecases.Match
+		var opponents string
+		err = row.Scan(&match.Id, &match.UserId, &match.GameId, &match.GameExternalId, &match.GameName,
+			&match.Result, &match.Score, &opponents, &match.PlayedAt, &match.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal([]byte(opponents), &match.Opponents)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+func (repo DbMatchRepo) Remove(match usecases.Match) error {
+	statement, args := repo.dbHandler.Dialect().Delete("matches").Where("id = ?", match.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbMatchRepo) RemoveAll(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("matches").Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
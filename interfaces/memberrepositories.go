@@ -0,0 +1,106 @@
+package interfaces
+
+import (
+	"database/sql"
+
+	"game-tracker/usecases"
+)
+
+type DbLibraryMemberRepo DbRepo
+
+func NewDbLibraryMemberRepo(dbHandlers map[string]DbHandler) *DbLibraryMemberRepo {
+	dbLibraryMemberRepo := new(DbLibraryMemberRepo)
+	dbLibraryMemberRepo.dbHandlers = dbHandlers
+	dbLibraryMemberRepo.dbHandler = dbHandlers["DbLibraryMemberRepo"]
+	return dbLibraryMemberRepo
+}
+
+var libraryMemberColumns = []string{"library_id", "user_id", "role", "created_at"}
+
+func (repo DbLibraryMemberRepo) Store(member usecases.LibraryMember) error {
+	statement, args := repo.dbHandler.Dialect().Insert("library_members").
+		Set("library_id", member.LibraryId).Set("user_id", member.User.Id).Set("role", member.Role).
+		OnConflict("(library_id, user_id)", "DO UPDATE SET role = EXCLUDED.role").Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbLibraryMemberRepo) FindByLib(libraryId int) ([]usecases.LibraryMember, error) {
+	statement, args := repo.dbHandler.Dialect().Select(libraryMemberColumns...).From("library_members").
+		Where("library_id = ?", libraryId).OrderBy("created_at", "user_id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbLibraryMemberRepo) FindByUser(userId int) ([]usecases.LibraryMember, error) {
+	statement, args := repo.dbHandler.Dialect().Select(libraryMemberColumns...).From("library_members").
+		Where("user_id = ?", userId).OrderBy("library_id").Build()
+	return repo.query(statement, args)
+}
+
+// One row either way: the creator matches libraries.user_id, anyone else
+// their membership
+func (repo DbLibraryMemberRepo) FindRole(libraryId, userId int) (string, error) {
+	statement, args := repo.dbHandler.Dialect().Select("libraries.user_id", "library_members.role").
+		From("libraries").LeftJoin("library_members", "library_members.library_id = libraries.id").
+		Where("libraries.id = ?", libraryId).
+		Where("(libraries.user_id = ? OR library_members.user_id = ?)", userId, userId).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return "", err
+	}
+	defer row.Close()
+
+	if !row.Next() {
+		return "", nil
+	}
+	var ownerId int
+	var role sql.NullString
+	err = row.Scan(&ownerId, &role)
+	if err != nil {
+		return "", err
+	}
+	if ownerId == userId {
+		return usecases.LibraryRoleOwner, nil
+	}
+	return role.String, nil
+}
+
+func (repo DbLibraryMemberRepo) query(statement string, args []interface{}) ([]usecases.LibraryMember, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var members []usecases.LibraryMember
+	for row.Next() {
+		var member usecases.LibraryMember
+		err = row.Scan(&member.LibraryId, &member.User.Id, &member.Role, &member.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func (repo DbLibraryMemberRepo) Remove(libraryId, userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("library_members").
+		Where("library_id = ?", libraryId).Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbLibraryMemberRepo) RemoveFromLib(libraryId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("library_members").
+		Where("library_id = ?", libraryId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbLibraryMemberRepo) RemoveFromUser(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("library_members").
+		Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
@@ -0,0 +1,87 @@
+package interfaces
+
+import (
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbModRepo DbRepo
+
+func NewDbModRepo(dbHandlers map[string]DbHandler) *DbModRepo {
+	dbModRepo := new(DbModRepo)
+	dbModRepo.dbHandlers = dbHandlers
+	dbModRepo.dbHandler = dbHandlers["DbModRepo"]
+	return dbModRepo
+}
+
+var modColumns = []string{"mods.id", "library_id", "game_id", "games.external_id", "games.name",
+	"mods.name", "source_url", "version", "enabled", "mods.created_at", "mods.updated_at"}
+
+func (repo DbModRepo) Store(mod usecases.Mod) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("mods").
+		Set("library_id", mod.LibraryId).Set("game_id", mod.GameId).Set("name", mod.Name).
+		Set("source_url", mod.SourceUrl).Set("version", mod.Version).Set("enabled", mod.Enabled).
+		Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbModRepo) FindById(id int) (usecases.Mod, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(modColumns...).From("mods").
+		Join("games", "games.id = mods.game_id").Where("mods.id = ?", id).Limit(1).Build()
+	mods, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.Mod{}, err, 500
+	}
+	if len(mods) == 0 {
+		return usecases.Mod{}, domain.NewError(domain.CodeNotFound, "Mod #%d does not exist", id), 404
+	}
+	return mods[0], nil, 200
+}
+
+func (repo DbModRepo) FindByGame(libraryId, gameId int) ([]usecases.Mod, error) {
+	statement, args := repo.dbHandler.Dialect().Select(modColumns...).From("mods").
+		Join("games", "games.id = mods.game_id").Where("library_id = ?", libraryId).
+		Where("game_id = ?", gameId).OrderBy("mods.name", "mods.id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbModRepo) FindByLib(libraryId int) ([]usecases.Mod, error) {
+	statement, args := repo.dbHandler.Dialect().Select(modColumns...).From("mods").
+		Join("games", "games.id = mods.game_id").Where("library_id = ?", libraryId).
+		OrderBy("games.name", "game_id", "mods.name", "mods.id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbModRepo) query(statement string, args []interface{}) ([]usecases.Mod, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var mods []usecases.Mod
+	for row.Next() {
+		var mod usecases.Mod
+		err = row.Scan(&mod.Id, &mod.LibraryId, &mod.GameId, &mod.GameExternalId, &mod.GameName,
+			&mod.Name, &mod.SourceUrl, &mod.Version, &mod.Enabled, &mod.CreatedAt, &mod.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		mods = append(mods, mod)
+	}
+	return mods, nil
+}
+
+func (repo DbModRepo) Update(mod usecases.Mod) error {
+	statement, args := repo.dbHandler.Dialect().Update("mods").
+		Set("name", mod.Name).Set("source_url", mod.SourceUrl).Set("version", mod.Version).
+		Set("enabled", mod.Enabled).SetExpr("updated_at = now()").Where("id = ?", mod.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbModRepo) Remove(mod usecases.Mod) error {
+	statement, args := repo.dbHandler.Dialect().Delete("mods").Where("id = ?", mod.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
@@ -0,0 +1,73 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoActivityRepo DocRepo
+
+// The game's external id is copied in since documents are not joined
+type activityDocument struct {
+	Id             int64     `bson:"_id"`
+	UserId         int       `bson:"user_id"`
+	Kind           string    `bson:"kind"`
+	GameId         int       `bson:"game_id"`
+	GameExternalId string    `bson:"game_external_id"`
+	GameName       string    `bson:"game_name"`
+	Partners       []string  `bson:"partners"`
+	CreatedAt      time.Time `bson:"created_at"`
+}
+
+func NewMongoActivityRepo(docHandlers map[string]DocumentHandler) *MongoActivityRepo {
+	mongoActivityRepo := new(MongoActivityRepo)
+	mongoActivityRepo.docHandlers = docHandlers
+	mongoActivityRepo.docHandler = docHandlers["MongoActivityRepo"]
+	return mongoActivityRepo
+}
+
+func (repo MongoActivityRepo) Store(activity usecases.Activity) error {
+	id, err := repo.docHandler.NextSequence("activities")
+	if err != nil {
+		return err
+	}
+	return repo.docHandler.Insert("activities", activityDocument{Id: id, UserId: activity.UserId,
+		Kind: activity.Kind, GameId: activity.GameId, GameExternalId: activity.GameExternalId,
+		GameName: activity.GameName, Partners: activity.Partners, CreatedAt: time.Now().UTC()})
+}
+
+func (repo MongoActivityRepo) FindByUser(userId, limit int) ([]usecases.Activity, error) {
+	var documents []activityDocument
+	err := repo.docHandler.Find("activities", Document{"user_id": userId},
+		FindOptions{Sort: []string{"-_id"}, Limit: limit}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var activities []usecases.Activity
+	for _, document := range documents {
+		activities = append(activities, usecases.Activity{Id: document.Id, UserId: userId,
+			Kind: document.Kind, GameId: document.GameId, GameExternalId: document.GameExternalId,
+			GameName: document.GameName, Partners: document.Partners, CreatedAt: document.CreatedAt})
+	}
+	return activities, nil
+}
+
+func (repo MongoActivityRepo) CountGames(userId int, kind string, from, to time.Time) (int, error) {
+	var documents []activityDocument
+	err := repo.docHandler.Find("activities", Document{"user_id": userId, "kind": kind,
+		"created_at": Document{"$gte": from, "$lt": to}}, FindOptions{}, &documents)
+	if err != nil {
+		return 0, err
+	}
+	games := make(map[int]bool)
+	for _, document := range documents {
+		games[document.GameId] = true
+	}
+	return len(games), nil
+}
+
+func (repo MongoActivityRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("activities", Document{"user_id": userId})
+	return err
+}
@@ -0,0 +1,58 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoAddonRepo DocRepo
+
+type addonDocument struct {
+	GameId    int       `bson:"_id"`
+	ParentId  int       `bson:"parent_id"`
+	Kind      string    `bson:"kind"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func NewMongoAddonRepo(docHandlers map[string]DocumentHandler) *MongoAddonRepo {
+	mongoAddonRepo := new(MongoAddonRepo)
+	mongoAddonRepo.docHandlers = docHandlers
+	mongoAddonRepo.docHandler = docHandlers["MongoAddonRepo"]
+	return mongoAddonRepo
+}
+
+func (repo MongoAddonRepo) Attach(addon usecases.Addon) error {
+	return repo.docHandler.Upsert("game_addons", Document{"_id": addon.GameId}, addonDocument{
+		GameId: addon.GameId, ParentId: addon.ParentId, Kind: addon.Kind, CreatedAt: time.Now().UTC()})
+}
+
+func (repo MongoAddonRepo) Detach(gameId int) (bool, error) {
+	removed, err := repo.docHandler.Delete("game_addons", Document{"_id": gameId})
+	return removed > 0, err
+}
+
+func (repo MongoAddonRepo) FindByGame(gameId int) (usecases.Addon, bool, error) {
+	var document addonDocument
+	found, err := repo.docHandler.FindOne("game_addons", Document{"_id": gameId}, &document)
+	if err != nil || !found {
+		return usecases.Addon{}, false, err
+	}
+	return usecases.Addon{GameId: document.GameId, ParentId: document.ParentId, Kind: document.Kind,
+		CreatedAt: document.CreatedAt}, true, nil
+}
+
+func (repo MongoAddonRepo) FindByParent(parentId int) ([]usecases.Addon, error) {
+	var documents []addonDocument
+	err := repo.docHandler.Find("game_addons", Document{"parent_id": parentId},
+		FindOptions{Sort: []string{"created_at", "_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var addons []usecases.Addon
+	for _, document := range documents {
+		addons = append(addons, usecases.Addon{GameId: document.GameId, ParentId: document.ParentId,
+			Kind: document.Kind, CreatedAt: document.CreatedAt})
+	}
+	return addons, nil
+}
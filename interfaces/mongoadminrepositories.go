@@ -0,0 +1,153 @@
+package interfaces
+
+import (
+	"regexp"
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoAdminRepo DocRepo
+
+type auditDocument struct {
+	Id        int64     `bson:"_id"`
+	ActorId   int       `bson:"actor_id"`
+	Action    string    `bson:"action"`
+	TargetId  int       `bson:"target_id"`
+	Detail    string    `bson:"detail"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func NewMongoAdminRepo(docHandlers map[string]DocumentHandler) *MongoAdminRepo {
+	mongoAdminRepo := new(MongoAdminRepo)
+	mongoAdminRepo.docHandlers = docHandlers
+	mongoAdminRepo.docHandler = docHandlers["MongoAdminRepo"]
+	return mongoAdminRepo
+}
+
+// Documents stored before roles and statuses existed lack the fields,
+// they match the defaults
+func orDefault(value, defaultValue string) interface{} {
+	if value == defaultValue {
+		return Document{"$in": []interface{}{value, nil}}
+	}
+	return value
+}
+
+func (repo MongoAdminRepo) FindUsers(filter usecases.UserFilter) ([]usecases.User, error) {
+	query := Document{}
+	if filter.Name != "" {
+		query["user_name_key"] = Document{"$regex": regexp.QuoteMeta(domain.NameKey(filter.Name))}
+	}
+	if filter.Role != "" {
+		query["role"] = orDefault(filter.Role, usecases.RoleUser)
+	}
+	if filter.Status != "" {
+		query["status"] = orDefault(filter.Status, usecases.StatusActive)
+	}
+	var documents []userDocument
+	err := repo.docHandler.Find("users", query, pageDocuments(query, filter.Page, "", nil, false),
+		&documents)
+	if err != nil {
+		return nil, err
+	}
+	var users []usecases.User
+	for _, document := range documents {
+		users = append(users, document.user())
+	}
+	return users, nil
+}
+
+func (repo MongoAdminRepo) UserStats(userId int) (usecases.UserStats, error) {
+	stats := usecases.UserStats{UserId: userId}
+	var libraries []libraryDocument
+	err := repo.docHandler.Find("libraries", Document{"user_id": userId}, FindOptions{}, &libraries)
+	if err != nil {
+		return stats, err
+	}
+	stats.Libraries = len(libraries)
+	for _, library := range libraries {
+		stats.Games += len(library.Games)
+	}
+	notifications, err := repo.docHandler.Count("notifications", Document{"user_id": userId})
+	if err != nil {
+		return stats, err
+	}
+	changes, err := repo.docHandler.Count("changes", Document{"user_id": userId})
+	if err != nil {
+		return stats, err
+	}
+	var user userDocument
+	found, err := repo.docHandler.FindOne("users", Document{"_id": userId}, &user)
+	stats.Notifications, stats.Changes = int(notifications), int(changes)
+	if found {
+		infoBytes := len(user.PersonalInfo)
+		stats.InfoBytes = &infoBytes
+	}
+	return stats, err
+}
+
+func (repo MongoAdminRepo) Metrics() (usecases.Metrics, error) {
+	metrics := usecases.Metrics{UsersByStatus: map[string]int{}}
+	counts := []struct {
+		collection string
+		count      *int
+	}{
+		{"users", &metrics.Users},
+		{"players", &metrics.Players},
+		{"libraries", &metrics.Libraries},
+		{"games", &metrics.Games},
+	}
+	for _, c := range counts {
+		count, err := repo.docHandler.Count(c.collection, Document{})
+		if err != nil {
+			return metrics, err
+		}
+		*c.count = int(count)
+	}
+
+	var libraries []libraryDocument
+	err := repo.docHandler.Find("libraries", Document{}, FindOptions{}, &libraries)
+	if err != nil {
+		return metrics, err
+	}
+	for _, library := range libraries {
+		metrics.GameEntries += len(library.Games)
+	}
+
+	for _, status := range []string{usecases.StatusActive, usecases.StatusSuspended,
+		usecases.StatusBanned} {
+		count, err := repo.docHandler.Count("users",
+			Document{"status": orDefault(status, usecases.StatusActive)})
+		if err != nil {
+			return metrics, err
+		}
+		if count > 0 {
+			metrics.UsersByStatus[status] = int(count)
+		}
+	}
+	return metrics, nil
+}
+
+func (repo MongoAdminRepo) SetStatus(userId int, change usecases.StatusChange) error {
+	update := Document{"$set": Document{"status": change.Status, "status_reason": change.Reason,
+		"updated_at": time.Now().UTC()}}
+	if change.Until == nil {
+		update["$unset"] = Document{"suspended_until": ""}
+	} else {
+		update["$set"].(Document)["suspended_until"] = change.Until.UTC()
+	}
+	_, err := repo.docHandler.Update("users", Document{"_id": userId}, update)
+	return err
+}
+
+func (repo MongoAdminRepo) Audit(entry usecases.AuditEntry) error {
+	id, err := repo.docHandler.NextSequence("audit_log")
+	if err != nil {
+		return err
+	}
+	return repo.docHandler.Insert("audit_log", auditDocument{Id: id, ActorId: entry.ActorId,
+		Action: entry.Action, TargetId: entry.TargetId, Detail: entry.Detail,
+		CreatedAt: time.Now().UTC()})
+}
@@ -0,0 +1,181 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoAgentRepo DocRepo
+type MongoExecutableRepo DocRepo
+
+type agentDocument struct {
+	Id         int       `bson:"_id"`
+	UserId     int       `bson:"user_id"`
+	Name       string    `bson:"name"`
+	TokenHash  string    `bson:"token_hash"`
+	LastSeenAt time.Time `bson:"last_seen_at"`
+	CreatedAt  time.Time `bson:"created_at"`
+}
+
+// The game name is copied in, as with play sessions
+type executableGameDocument struct {
+	UserId         int    `bson:"user_id"`
+	Executable     string `bson:"executable"`
+	GameId         int    `bson:"game_id"`
+	GameExternalId string `bson:"game_external_id"`
+	GameName       string `bson:"game_name"`
+}
+
+type agentProcessDocument struct {
+	UserId     int       `bson:"user_id"`
+	Executable string    `bson:"executable"`
+	GameId     int       `bson:"game_id"`
+	AgentId    int       `bson:"agent_id"`
+	StartedAt  time.Time `bson:"started_at"`
+}
+
+func NewMongoAgentRepo(docHandlers map[string]DocumentHandler) *MongoAgentRepo {
+	mongoAgentRepo := new(MongoAgentRepo)
+	mongoAgentRepo.docHandlers = docHandlers
+	mongoAgentRepo.docHandler = docHandlers["MongoAgentRepo"]
+	return mongoAgentRepo
+}
+
+func NewMongoExecutableRepo(docHandlers map[string]DocumentHandler) *MongoExecutableRepo {
+	mongoExecutableRepo := new(MongoExecutableRepo)
+	mongoExecutableRepo.docHandlers = docHandlers
+	mongoExecutableRepo.docHandler = docHandlers["MongoExecutableRepo"]
+	return mongoExecutableRepo
+}
+
+func (document agentDocument) agent() usecases.Agent {
+	return usecases.Agent{Id: document.Id, UserId: document.UserId, Name: document.Name,
+		TokenHash: document.TokenHash, LastSeenAt: document.LastSeenAt, CreatedAt: document.CreatedAt}
+}
+
+func (repo MongoAgentRepo) Store(agent usecases.Agent) (int, error) {
+	id, err := repo.docHandler.NextSequence("agents")
+	if err != nil {
+		return 0, err
+	}
+	err = repo.docHandler.Insert("agents", agentDocument{Id: int(id), UserId: agent.UserId,
+		Name: agent.Name, TokenHash: agent.TokenHash, CreatedAt: agent.CreatedAt})
+	return int(id), err
+}
+
+func (repo MongoAgentRepo) FindByTokenHash(tokenHash string) (usecases.Agent, bool, error) {
+	var document agentDocument
+	found, err := repo.docHandler.FindOne("agents", Document{"token_hash": tokenHash}, &document)
+	if err != nil || !found {
+		return usecases.Agent{}, false, err
+	}
+	return document.agent(), true, nil
+}
+
+func (repo MongoAgentRepo) FindByUser(userId int) ([]usecases.Agent, error) {
+	var documents []agentDocument
+	err := repo.docHandler.Find("agents", Document{"user_id": userId},
+		FindOptions{Sort: []string{"created_at", "_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	agents := make([]usecases.Agent, len(documents))
+	for i, document := range documents {
+		agents[i] = document.agent()
+	}
+	return agents, nil
+}
+
+func (repo MongoAgentRepo) MarkSeen(id int, at time.Time) error {
+	_, err := repo.docHandler.Update("agents", Document{"_id": id},
+		Document{"$set": Document{"last_seen_at": at}})
+	return err
+}
+
+func (repo MongoAgentRepo) Remove(agent usecases.Agent) error {
+	_, err := repo.docHandler.Delete("agents", Document{"_id": agent.Id})
+	return err
+}
+
+func (repo MongoAgentRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("agents", Document{"user_id": userId})
+	return err
+}
+
+func (document executableGameDocument) mapping() usecases.ExecutableGame {
+	return usecases.ExecutableGame{UserId: document.UserId, Executable: document.Executable,
+		GameId: document.GameId, GameExternalId: document.GameExternalId, GameName: document.GameName}
+}
+
+func (repo MongoExecutableRepo) Map(mapping usecases.ExecutableGame) error {
+	return repo.docHandler.Upsert("executable_games",
+		Document{"user_id": mapping.UserId, "executable": mapping.Executable},
+		executableGameDocument{UserId: mapping.UserId, Executable: mapping.Executable,
+			GameId: mapping.GameId, GameExternalId: mapping.GameExternalId, GameName: mapping.GameName})
+}
+
+func (repo MongoExecutableRepo) FindGame(userId int, executable string) (usecases.ExecutableGame, bool, error) {
+	var document executableGameDocument
+	found, err := repo.docHandler.FindOne("executable_games",
+		Document{"user_id": userId, "executable": executable}, &document)
+	if err != nil || !found {
+		return usecases.ExecutableGame{}, false, err
+	}
+	return document.mapping(), true, nil
+}
+
+func (repo MongoExecutableRepo) FindByUser(userId int) ([]usecases.ExecutableGame, error) {
+	var documents []executableGameDocument
+	err := repo.docHandler.Find("executable_games", Document{"user_id": userId},
+		FindOptions{Sort: []string{"executable"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	mappings := make([]usecases.ExecutableGame, len(documents))
+	for i, document := range documents {
+		mappings[i] = document.mapping()
+	}
+	return mappings, nil
+}
+
+func (repo MongoExecutableRepo) Unmap(userId int, executable string) (bool, error) {
+	removed, err := repo.docHandler.Delete("executable_games",
+		Document{"user_id": userId, "executable": executable})
+	return removed > 0, err
+}
+
+func (repo MongoExecutableRepo) Start(process usecases.AgentProcess) error {
+	return repo.docHandler.Upsert("agent_processes",
+		Document{"user_id": process.UserId, "executable": process.Executable},
+		agentProcessDocument{UserId: process.UserId, Executable: process.Executable,
+			GameId: process.GameId, AgentId: process.AgentId, StartedAt: process.StartedAt})
+}
+
+// Only the stop that deletes the start gets it, so a report sent twice does
+// not log the session twice
+func (repo MongoExecutableRepo) Stop(userId int, executable string) (usecases.AgentProcess, bool, error) {
+	var document agentProcessDocument
+	filter := Document{"user_id": userId, "executable": executable}
+	found, err := repo.docHandler.FindOne("agent_processes", filter, &document)
+	if err != nil || !found {
+		return usecases.AgentProcess{}, false, err
+	}
+	filter["started_at"] = document.StartedAt
+	removed, err := repo.docHandler.Delete("agent_processes", filter)
+	if err != nil || removed == 0 {
+		return usecases.AgentProcess{}, false, err
+	}
+	return usecases.AgentProcess{UserId: document.UserId, Executable: document.Executable,
+		GameId: document.GameId, AgentId: document.AgentId, StartedAt: document.StartedAt}, true, nil
+}
+
+func (repo MongoExecutableRepo) RemoveAll(userId int) error {
+	for _, collection := range []string{"agent_processes", "executable_games"} {
+		_, err := repo.docHandler.Delete(collection, Document{"user_id": userId})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
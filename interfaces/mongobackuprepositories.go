@@ -0,0 +1,117 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoSaveBackupRepo DocRepo
+
+type saveBackupDocument struct {
+	Id             int       `bson:"_id"`
+	UserId         int       `bson:"user_id"`
+	LibraryId      int       `bson:"library_id"`
+	GameId         int       `bson:"game_id"`
+	GameExternalId string    `bson:"game_external_id"`
+	GameName       string    `bson:"game_name"`
+	Location       string    `bson:"location"`
+	SizeBytes      int64     `bson:"size_bytes"`
+	Checksum       string    `bson:"checksum"`
+	BackedUpAt     time.Time `bson:"backed_up_at"`
+	RemindedAt     time.Time `bson:"reminded_at"`
+	CreatedAt      time.Time `bson:"created_at"`
+}
+
+func NewMongoSaveBackupRepo(docHandlers map[string]DocumentHandler) *MongoSaveBackupRepo {
+	mongoSaveBackupRepo := new(MongoSaveBackupRepo)
+	mongoSaveBackupRepo.docHandlers = docHandlers
+	mongoSaveBackupRepo.docHandler = docHandlers["MongoSaveBackupRepo"]
+	return mongoSaveBackupRepo
+}
+
+func (document saveBackupDocument) backup() usecases.SaveBackup {
+	return usecases.SaveBackup{Id: document.Id, UserId: document.UserId, LibraryId: document.LibraryId,
+		GameId: document.GameId, GameExternalId: document.GameExternalId, GameName: document.GameName,
+		Location: document.Location, SizeBytes: document.SizeBytes, Checksum: document.Checksum,
+		BackedUpAt: document.BackedUpAt, RemindedAt: document.RemindedAt, CreatedAt: document.CreatedAt}
+}
+
+func (repo MongoSaveBackupRepo) Store(backup usecases.SaveBackup) (int, error) {
+	id, err := repo.docHandler.NextSequence("save_backups")
+	if err != nil {
+		return 0, err
+	}
+	err = repo.docHandler.Insert("save_backups", saveBackupDocument{Id: int(id), UserId: backup.UserId,
+		LibraryId: backup.LibraryId, GameId: backup.GameId, GameExternalId: backup.GameExternalId,
+		GameName: backup.GameName, Location: backup.Location, SizeBytes: backup.SizeBytes,
+		Checksum: backup.Checksum, BackedUpAt: backup.BackedUpAt, CreatedAt: time.Now().UTC()})
+	return int(id), err
+}
+
+func (repo MongoSaveBackupRepo) FindById(id int) (usecases.SaveBackup, error, int) {
+	var document saveBackupDocument
+	found, err := repo.docHandler.FindOne("save_backups", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.SaveBackup{}, err, 500
+	}
+	if !found {
+		return usecases.SaveBackup{}, domain.NewError(domain.CodeNotFound,
+			"Backup #%d does not exist", id), 404
+	}
+	return document.backup(), nil, 200
+}
+
+func (repo MongoSaveBackupRepo) FindByGame(libraryId, gameId int) ([]usecases.SaveBackup, error) {
+	return repo.find(Document{"library_id": libraryId, "game_id": gameId},
+		FindOptions{Sort: []string{"-backed_up_at", "-_id"}})
+}
+
+// Candidates are dropped when a newer backup of the game exists
+func (repo MongoSaveBackupRepo) FindStale(before time.Time) ([]usecases.SaveBackup, error) {
+	candidates, err := repo.find(Document{"reminded_at": time.Time{}, "backed_up_at": Document{"$lt": before}},
+		FindOptions{Sort: []string{"_id"}})
+	if err != nil {
+		return nil, err
+	}
+	var backups []usecases.SaveBackup
+	for _, backup := range candidates {
+		var newer saveBackupDocument
+		found, err := repo.docHandler.FindOne("save_backups", Document{"library_id": backup.LibraryId,
+			"game_id": backup.GameId, "$or": []Document{
+				{"backed_up_at": Document{"$gt": backup.BackedUpAt}},
+				{"backed_up_at": backup.BackedUpAt, "_id": Document{"$gt": backup.Id}}}}, &newer)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			backups = append(backups, backup)
+		}
+	}
+	return backups, nil
+}
+
+func (repo MongoSaveBackupRepo) find(filter Document, options FindOptions) ([]usecases.SaveBackup, error) {
+	var documents []saveBackupDocument
+	err := repo.docHandler.Find("save_backups", filter, options, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var backups []usecases.SaveBackup
+	for _, document := range documents {
+		backups = append(backups, document.backup())
+	}
+	return backups, nil
+}
+
+func (repo MongoSaveBackupRepo) MarkReminded(id int, at time.Time) error {
+	_, err := repo.docHandler.Update("save_backups", Document{"_id": id},
+		Document{"$set": Document{"reminded_at": at}})
+	return err
+}
+
+func (repo MongoSaveBackupRepo) Remove(backup usecases.SaveBackup) error {
+	_, err := repo.docHandler.Delete("save_backups", Document{"_id": backup.Id})
+	return err
+}
@@ -0,0 +1,54 @@
+package interfaces
+
+import (
+	"fmt"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoBadgeRepo DocRepo
+
+type badgeDocument struct {
+	Id       string    `bson:"_id"` //User and badge id
+	UserId   int       `bson:"user_id"`
+	BadgeId  string    `bson:"badge_id"`
+	EarnedAt time.Time `bson:"earned_at"`
+}
+
+func NewMongoBadgeRepo(docHandlers map[string]DocumentHandler) *MongoBadgeRepo {
+	mongoBadgeRepo := new(MongoBadgeRepo)
+	mongoBadgeRepo.docHandlers = docHandlers
+	mongoBadgeRepo.docHandler = docHandlers["MongoBadgeRepo"]
+	return mongoBadgeRepo
+}
+
+// The _id holds the user and the badge, a second award is a duplicate
+func (repo MongoBadgeRepo) Award(userId int, badgeId string) (bool, error) {
+	err := repo.docHandler.Insert("user_badges", badgeDocument{Id: fmt.Sprintf("%d/%s", userId, badgeId),
+		UserId: userId, BadgeId: badgeId, EarnedAt: time.Now().UTC()})
+	if err == ErrDuplicateDocument {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (repo MongoBadgeRepo) FindByUser(userId int) ([]usecases.EarnedBadge, error) {
+	var documents []badgeDocument
+	err := repo.docHandler.Find("user_badges", Document{"user_id": userId},
+		FindOptions{Sort: []string{"earned_at", "badge_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var badges []usecases.EarnedBadge
+	for _, document := range documents {
+		badges = append(badges, usecases.EarnedBadge{UserId: document.UserId,
+			BadgeId: document.BadgeId, EarnedAt: document.EarnedAt})
+	}
+	return badges, nil
+}
+
+func (repo MongoBadgeRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("user_badges", Document{"user_id": userId})
+	return err
+}
@@ -0,0 +1,309 @@
+package interfaces
+
+import (
+	"fmt"
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoPlaySessionRepo DocRepo
+type MongoReleaseRepo DocRepo
+type MongoCalendarTokenRepo DocRepo
+
+// Games are never edited once stored, their external id and name are copied in
+type playSessionDocument struct {
+	Id             int       `bson:"_id"`
+	UserId         int       `bson:"user_id"`
+	GameId         int       `bson:"game_id"`
+	GameExternalId string    `bson:"game_external_id"`
+	GameName       string    `bson:"game_name"`
+	StartsAt       time.Time `bson:"starts_at"`
+	Minutes        int       `bson:"minutes"`
+	Notes          string    `bson:"notes"`
+	PartnerIds     []int     `bson:"partner_ids"`
+	CreatedAt      time.Time `bson:"created_at"`
+}
+
+type releaseDocument struct {
+	Id             string     `bson:"_id"` //"<user id>:<game id>"
+	UserId         int        `bson:"user_id"`
+	GameId         int        `bson:"game_id"`
+	GameExternalId string     `bson:"game_external_id"`
+	GameName       string     `bson:"game_name"`
+	Date           time.Time  `bson:"release_date"`
+	Source         string     `bson:"source"`
+	AnnouncedAt    *time.Time `bson:"announced_at"`
+	UpdatedAt      time.Time  `bson:"updated_at"`
+}
+
+// Sessions of an archived month, summed per user and game
+type sessionMonthDocument struct {
+	Id       string    `bson:"_id"` //"<user id>:<game id>:<month>"
+	UserId   int       `bson:"user_id"`
+	GameId   int       `bson:"game_id"`
+	Month    time.Time `bson:"month"`
+	Sessions int       `bson:"sessions"`
+	Minutes  int       `bson:"minutes"`
+}
+
+type calendarTokenDocument struct {
+	UserId    int       `bson:"_id"`
+	TokenHash string    `bson:"token_hash"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func NewMongoPlaySessionRepo(docHandlers map[string]DocumentHandler) *MongoPlaySessionRepo {
+	mongoPlaySessionRepo := new(MongoPlaySessionRepo)
+	mongoPlaySessionRepo.docHandlers = docHandlers
+	mongoPlaySessionRepo.docHandler = docHandlers["MongoPlaySessionRepo"]
+	return mongoPlaySessionRepo
+}
+
+func NewMongoReleaseRepo(docHandlers map[string]DocumentHandler) *MongoReleaseRepo {
+	mongoReleaseRepo := new(MongoReleaseRepo)
+	mongoReleaseRepo.docHandlers = docHandlers
+	mongoReleaseRepo.docHandler = docHandlers["MongoReleaseRepo"]
+	return mongoReleaseRepo
+}
+
+func NewMongoCalendarTokenRepo(docHandlers map[string]DocumentHandler) *MongoCalendarTokenRepo {
+	mongoCalendarTokenRepo := new(MongoCalendarTokenRepo)
+	mongoCalendarTokenRepo.docHandlers = docHandlers
+	mongoCalendarTokenRepo.docHandler = docHandlers["MongoCalendarTokenRepo"]
+	return mongoCalendarTokenRepo
+}
+
+func (document playSessionDocument) session() usecases.PlaySession {
+	return usecases.PlaySession{Id: document.Id, UserId: document.UserId, GameId: document.GameId,
+		GameExternalId: document.GameExternalId, GameName: document.GameName,
+		StartsAt: document.StartsAt, Minutes: document.Minutes, Notes: document.Notes,
+		PartnerIds: document.PartnerIds, CreatedAt: document.CreatedAt}
+}
+
+func (repo MongoPlaySessionRepo) Store(session usecases.PlaySession) (int, error) {
+	id, err := repo.docHandler.NextSequence("play_sessions")
+	if err != nil {
+		return 0, err
+	}
+	err = repo.docHandler.Insert("play_sessions", playSessionDocument{Id: int(id),
+		UserId: session.UserId, GameId: session.GameId, GameExternalId: session.GameExternalId,
+		GameName: session.GameName, StartsAt: session.StartsAt, Minutes: session.Minutes,
+		Notes: session.Notes, PartnerIds: session.PartnerIds, CreatedAt: time.Now().UTC()})
+	return int(id), err
+}
+
+func (repo MongoPlaySessionRepo) FindById(id int) (usecases.PlaySession, error, int) {
+	var document playSessionDocument
+	found, err := repo.docHandler.FindOne("play_sessions", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.PlaySession{}, err, 500
+	}
+	if !found {
+		return usecases.PlaySession{}, domain.NewError(domain.CodeNotFound,
+			"Session #%d does not exist", id), 404
+	}
+	return document.session(), nil, 200
+}
+
+func (repo MongoPlaySessionRepo) FindByUser(userId int, from, to time.Time) ([]usecases.PlaySession, error) {
+	var documents []playSessionDocument
+	err := repo.docHandler.Find("play_sessions", Document{"user_id": userId,
+		"starts_at": Document{"$gte": from, "$lt": to}},
+		FindOptions{Sort: []string{"starts_at", "_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var sessions []usecases.PlaySession
+	for _, document := range documents {
+		sessions = append(sessions, document.session())
+	}
+	return sessions, nil
+}
+
+func (repo MongoPlaySessionRepo) FindCoop(userId, partnerId int, until time.Time) ([]usecases.PlaySession, error) {
+	filter := Document{"user_id": userId, "starts_at": Document{"$lt": until},
+		"partner_ids.0": Document{"$exists": true}}
+	if partnerId != 0 {
+		filter["partner_ids"] = partnerId
+	}
+	var documents []playSessionDocument
+	err := repo.docHandler.Find("play_sessions", filter, FindOptions{Sort: []string{"starts_at", "_id"}},
+		&documents)
+	if err != nil {
+		return nil, err
+	}
+	var sessions []usecases.PlaySession
+	for _, document := range documents {
+		sessions = append(sessions, document.session())
+	}
+	return sessions, nil
+}
+
+// Documents are not aggregated, the days are summed up here
+func (repo MongoPlaySessionRepo) MinutesPerDay(userId int, from, to time.Time, timezone string) (map[time.Time]int, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	var documents []playSessionDocument
+	err = repo.docHandler.Find("play_sessions", Document{"user_id": userId,
+		"starts_at": Document{"$gte": from, "$lt": to}}, FindOptions{}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	minutes := make(map[time.Time]int)
+	for _, document := range documents {
+		year, month, day := document.StartsAt.In(location).Date()
+		minutes[time.Date(year, month, day, 0, 0, 0, 0, time.UTC)] += document.Minutes
+	}
+	return minutes, nil
+}
+
+func (repo MongoPlaySessionRepo) SetDetails(session usecases.PlaySession, notes string, partnerIds []int) error {
+	_, err := repo.docHandler.Update("play_sessions", Document{"_id": session.Id},
+		Document{"$set": Document{"notes": notes, "partner_ids": partnerIds}})
+	return err
+}
+
+func (repo MongoPlaySessionRepo) Remove(session usecases.PlaySession) error {
+	_, err := repo.docHandler.Delete("play_sessions", Document{"_id": session.Id})
+	return err
+}
+
+func (repo MongoPlaySessionRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("play_sessions", Document{"user_id": userId})
+	if err != nil {
+		return err
+	}
+	_, err = repo.docHandler.Delete("session_months", Document{"user_id": userId})
+	return err
+}
+
+func (repo MongoPlaySessionRepo) RemovePartner(partnerId int) error {
+	_, err := repo.docHandler.Update("play_sessions", Document{"partner_ids": partnerId},
+		Document{"$pull": Document{"partner_ids": partnerId}})
+	return err
+}
+
+// Collections are not partitioned, there is nothing to ready
+func (repo MongoPlaySessionRepo) PrepareMonths(from, until time.Time) error {
+	return nil
+}
+
+// Documents are not aggregated, the months are summed up here and added to
+// the totals of months archived before
+func (repo MongoPlaySessionRepo) Archive(before time.Time) error {
+	var documents []playSessionDocument
+	err := repo.docHandler.Find("play_sessions", Document{"starts_at": Document{"$lt": before}},
+		FindOptions{}, &documents)
+	if err != nil || len(documents) == 0 {
+		return err
+	}
+	months := make(map[string]sessionMonthDocument)
+	var ids []int
+	for _, document := range documents {
+		ids = append(ids, document.Id)
+		month := startOfMonth(document.StartsAt)
+		id := fmt.Sprintf("%d:%d:%s", document.UserId, document.GameId, month.Format("2006-01"))
+		total, known := months[id]
+		if !known {
+			found, err := repo.docHandler.FindOne("session_months", Document{"_id": id}, &total)
+			if err != nil {
+				return err
+			}
+			if !found {
+				total = sessionMonthDocument{Id: id, UserId: document.UserId,
+					GameId: document.GameId, Month: month}
+			}
+		}
+		total.Sessions++
+		total.Minutes += document.Minutes
+		months[id] = total
+	}
+	for id, total := range months {
+		err = repo.docHandler.Upsert("session_months", Document{"_id": id}, total)
+		if err != nil {
+			return err
+		}
+	}
+	// Sessions stored meanwhile are left for the next run
+	_, err = repo.docHandler.Delete("play_sessions", Document{"_id": Document{"$in": ids}})
+	return err
+}
+
+// A moved date has to be announced again
+func (repo MongoReleaseRepo) Store(release usecases.Release) error {
+	id := fmt.Sprintf("%d:%d", release.UserId, release.GameId)
+	var existing releaseDocument
+	found, err := repo.docHandler.FindOne("releases", Document{"_id": id}, &existing)
+	if err != nil {
+		return err
+	}
+	document := releaseDocument{Id: id, UserId: release.UserId, GameId: release.GameId,
+		GameExternalId: release.GameExternalId, GameName: release.GameName, Date: release.Date,
+		Source: release.Source, UpdatedAt: time.Now().UTC()}
+	if found && existing.Date.Equal(release.Date) {
+		document.AnnouncedAt = existing.AnnouncedAt
+	}
+	return repo.docHandler.Upsert("releases", Document{"_id": id}, document)
+}
+
+func (repo MongoReleaseRepo) FindByUser(userId int, from time.Time) ([]usecases.Release, error) {
+	return repo.find(Document{"user_id": userId, "release_date": Document{"$gte": from}})
+}
+
+func (repo MongoReleaseRepo) FindUnannounced() ([]usecases.Release, error) {
+	return repo.find(Document{"announced_at": nil})
+}
+
+func (repo MongoReleaseRepo) find(filter Document) ([]usecases.Release, error) {
+	var documents []releaseDocument
+	err := repo.docHandler.Find("releases", filter,
+		FindOptions{Sort: []string{"release_date", "game_id", "user_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var releases []usecases.Release
+	for _, document := range documents {
+		releases = append(releases, usecases.Release{UserId: document.UserId,
+			GameId: document.GameId, GameExternalId: document.GameExternalId,
+			GameName: document.GameName, Date: document.Date, Source: document.Source,
+			UpdatedAt: document.UpdatedAt})
+	}
+	return releases, nil
+}
+
+func (repo MongoReleaseRepo) MarkAnnounced(userId, gameId int) error {
+	_, err := repo.docHandler.Update("releases", Document{"user_id": userId, "game_id": gameId},
+		Document{"$set": Document{"announced_at": time.Now().UTC()}})
+	return err
+}
+
+func (repo MongoReleaseRepo) Remove(userId, gameId int) error {
+	_, err := repo.docHandler.Delete("releases", Document{"user_id": userId, "game_id": gameId})
+	return err
+}
+
+func (repo MongoReleaseRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("releases", Document{"user_id": userId})
+	return err
+}
+
+func (repo MongoCalendarTokenRepo) Store(userId int, tokenHash string) error {
+	return repo.docHandler.Upsert("calendar_tokens", Document{"_id": userId},
+		calendarTokenDocument{UserId: userId, TokenHash: tokenHash, CreatedAt: time.Now().UTC()})
+}
+
+func (repo MongoCalendarTokenRepo) FindUserId(tokenHash string) (int, bool, error) {
+	var document calendarTokenDocument
+	found, err := repo.docHandler.FindOne("calendar_tokens", Document{"token_hash": tokenHash},
+		&document)
+	return document.UserId, found, err
+}
+
+func (repo MongoCalendarTokenRepo) Remove(userId int) error {
+	_, err := repo.docHandler.Delete("calendar_tokens", Document{"_id": userId})
+	return err
+}
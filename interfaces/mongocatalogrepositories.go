@@ -0,0 +1,87 @@
+package interfaces
+
+import (
+	"fmt"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoCatalogRepo DocRepo
+
+type catalogDocument struct {
+	Id        string    `bson:"_id"` //Service and name key
+	Service   string    `bson:"service"`
+	Name      string    `bson:"name"`
+	NameKey   string    `bson:"name_key"`
+	LeavesOn  time.Time `bson:"leaves_on"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+type catalogAlertDocument struct {
+	Id        string    `bson:"_id"` //User, service, name key and leaving date
+	UserId    int       `bson:"user_id"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func NewMongoCatalogRepo(docHandlers map[string]DocumentHandler) *MongoCatalogRepo {
+	mongoCatalogRepo := new(MongoCatalogRepo)
+	mongoCatalogRepo.docHandlers = docHandlers
+	mongoCatalogRepo.docHandler = docHandlers["MongoCatalogRepo"]
+	return mongoCatalogRepo
+}
+
+// Documents cannot be replaced in one step, a reader may briefly see the
+// service with fewer games
+func (repo MongoCatalogRepo) Replace(service string, entries []usecases.CatalogEntry) error {
+	_, err := repo.docHandler.Delete("catalog_games", Document{"service": service})
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, entry := range entries {
+		key := catalogKey(entry.Name)
+		err = repo.docHandler.Upsert("catalog_games", Document{"_id": service + "/" + key},
+			catalogDocument{Id: service + "/" + key, Service: service, Name: entry.Name, NameKey: key,
+				LeavesOn: entry.LeavesOn, UpdatedAt: now})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo MongoCatalogRepo) FindByNames(names []string) ([]usecases.CatalogEntry, error) {
+	keys := []string{}
+	for _, name := range names {
+		keys = append(keys, catalogKey(name))
+	}
+	var documents []catalogDocument
+	err := repo.docHandler.Find("catalog_games", Document{"name_key": Document{"$in": keys}},
+		FindOptions{Sort: []string{"service", "name_key"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var entries []usecases.CatalogEntry
+	for _, document := range documents {
+		entries = append(entries, usecases.CatalogEntry{Service: document.Service, Name: document.Name,
+			LeavesOn: document.LeavesOn, UpdatedAt: document.UpdatedAt})
+	}
+	return entries, nil
+}
+
+func (repo MongoCatalogRepo) StoreAlert(userId int, entry usecases.CatalogEntry) (bool, error) {
+	err := repo.docHandler.Insert("catalog_alerts", catalogAlertDocument{
+		Id: fmt.Sprintf("%d/%s/%s/%s", userId, entry.Service, catalogKey(entry.Name),
+			entry.LeavesOn.Format("2006-01-02")),
+		UserId: userId, CreatedAt: time.Now().UTC()})
+	if err == ErrDuplicateDocument {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (repo MongoCatalogRepo) RemoveAlerts(userId int) error {
+	_, err := repo.docHandler.Delete("catalog_alerts", Document{"user_id": userId})
+	return err
+}
@@ -0,0 +1,69 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoChangeRepo DocRepo
+
+type changeDocument struct {
+	Id        int64     `bson:"_id"`
+	UserId    int       `bson:"user_id"`
+	Entity    string    `bson:"entity"`
+	EntityId  string    `bson:"entity_id"`
+	ParentId  string    `bson:"parent_id,omitempty"`
+	Action    string    `bson:"action"`
+	ChangedAt time.Time `bson:"changed_at"`
+}
+
+func NewMongoChangeRepo(docHandlers map[string]DocumentHandler) *MongoChangeRepo {
+	mongoChangeRepo := new(MongoChangeRepo)
+	mongoChangeRepo.docHandlers = docHandlers
+	mongoChangeRepo.docHandler = docHandlers["MongoChangeRepo"]
+	return mongoChangeRepo
+}
+
+func (repo MongoChangeRepo) FindSince(userId int, after int64, limit int) ([]usecases.Change, error) {
+	var documents []changeDocument
+	err := repo.docHandler.Find("changes", Document{"user_id": userId, "_id": Document{"$gt": after}},
+		FindOptions{Sort: []string{"_id"}, Limit: limit}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var changes []usecases.Change
+	for _, document := range documents {
+		changes = append(changes, usecases.Change{Id: document.Id, UserId: userId,
+			Entity: document.Entity, EntityId: document.EntityId, ParentId: document.ParentId,
+			Action: document.Action, ChangedAt: document.ChangedAt})
+	}
+	return changes, nil
+}
+
+func (repo MongoChangeRepo) FindByParent(parentId string, until time.Time) ([]usecases.Change, error) {
+	var documents []changeDocument
+	err := repo.docHandler.Find("changes", Document{"parent_id": parentId,
+		"changed_at": Document{"$lt": until}}, FindOptions{Sort: []string{"_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var changes []usecases.Change
+	for _, document := range documents {
+		changes = append(changes, usecases.Change{Id: document.Id, UserId: document.UserId,
+			Entity: document.Entity, EntityId: document.EntityId, ParentId: parentId,
+			Action: document.Action, ChangedAt: document.ChangedAt})
+	}
+	return changes, nil
+}
+
+// Document counterpart of logUserChange and friends, the caller already
+// holds the external ids so nothing has to be looked up
+func recordChange(docHandler DocumentHandler, userId int, entity, entityId, parentId, action string) error {
+	id, err := docHandler.NextSequence("changes")
+	if err != nil {
+		return err
+	}
+	return docHandler.Insert("changes", changeDocument{Id: id, UserId: userId, Entity: entity,
+		EntityId: entityId, ParentId: parentId, Action: action, ChangedAt: time.Now().UTC()})
+}
@@ -0,0 +1,141 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoPhysicalCopyRepo DocRepo
+
+type physicalCopyDocument struct {
+	Id             int       `bson:"_id"`
+	LibraryId      int       `bson:"library_id"`
+	GameId         int       `bson:"game_id"`
+	GameExternalId string    `bson:"game_external_id"`
+	GameName       string    `bson:"game_name"`
+	Barcode        string    `bson:"barcode"`
+	Platform       string    `bson:"platform"`
+	PaidPrice      float64   `bson:"paid_price"`
+	PaidCurrency   string    `bson:"paid_currency"`
+	Value          float64   `bson:"value"`
+	ValueCurrency  string    `bson:"value_currency"`
+	ValuedAt       time.Time `bson:"valued_at,omitempty"`
+	CreatedAt      time.Time `bson:"created_at"`
+}
+
+type barcodeDocument struct {
+	Barcode   string    `bson:"_id"`
+	GameId    int       `bson:"game_id"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func NewMongoPhysicalCopyRepo(docHandlers map[string]DocumentHandler) *MongoPhysicalCopyRepo {
+	mongoPhysicalCopyRepo := new(MongoPhysicalCopyRepo)
+	mongoPhysicalCopyRepo.docHandlers = docHandlers
+	mongoPhysicalCopyRepo.docHandler = docHandlers["MongoPhysicalCopyRepo"]
+	return mongoPhysicalCopyRepo
+}
+
+func (document physicalCopyDocument) physicalCopy() usecases.PhysicalCopy {
+	return usecases.PhysicalCopy{Id: document.Id, LibraryId: document.LibraryId, GameId: document.GameId,
+		GameExternalId: document.GameExternalId, GameName: document.GameName,
+		Barcode: document.Barcode, Platform: document.Platform, PaidPrice: document.PaidPrice,
+		PaidCurrency: document.PaidCurrency, Value: document.Value, ValueCurrency: document.ValueCurrency,
+		ValuedAt: document.ValuedAt, CreatedAt: document.CreatedAt}
+}
+
+func (repo MongoPhysicalCopyRepo) Store(physical usecases.PhysicalCopy) (int, error) {
+	id, err := repo.docHandler.NextSequence("physical_copies")
+	if err != nil {
+		return 0, err
+	}
+	err = repo.docHandler.Insert("physical_copies", physicalCopyDocument{Id: int(id),
+		LibraryId: physical.LibraryId, GameId: physical.GameId, GameExternalId: physical.GameExternalId,
+		GameName: physical.GameName, Barcode: physical.Barcode, Platform: physical.Platform,
+		CreatedAt: time.Now().UTC()})
+	return int(id), err
+}
+
+func (repo MongoPhysicalCopyRepo) FindById(id int) (usecases.PhysicalCopy, error, int) {
+	var document physicalCopyDocument
+	found, err := repo.docHandler.FindOne("physical_copies", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.PhysicalCopy{}, err, 500
+	}
+	if !found {
+		return usecases.PhysicalCopy{}, domain.NewError(domain.CodeNotFound,
+			"Copy #%d does not exist", id), 404
+	}
+	return document.physicalCopy(), nil, 200
+}
+
+func (repo MongoPhysicalCopyRepo) FindByLib(libraryId int, page usecases.Page) ([]usecases.PhysicalCopy, error) {
+	filter := Document{"library_id": libraryId}
+	options := pageDocuments(filter, page, "game_name", page.After.Key, false)
+	var documents []physicalCopyDocument
+	err := repo.docHandler.Find("physical_copies", filter, options, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var copies []usecases.PhysicalCopy
+	for _, document := range documents {
+		copies = append(copies, document.physicalCopy())
+	}
+	return copies, nil
+}
+
+func (repo MongoPhysicalCopyRepo) Remove(physical usecases.PhysicalCopy) error {
+	_, err := repo.docHandler.Delete("physical_copies", Document{"_id": physical.Id})
+	return err
+}
+
+func (repo MongoPhysicalCopyRepo) RemoveFromLib(libraryId, gameId int) error {
+	filter := Document{"library_id": libraryId}
+	if gameId > 0 {
+		filter["game_id"] = gameId
+	}
+	_, err := repo.docHandler.Delete("physical_copies", filter)
+	return err
+}
+
+func (repo MongoPhysicalCopyRepo) FindBarcode(barcode string) (int, bool, error) {
+	var document barcodeDocument
+	found, err := repo.docHandler.FindOne("barcodes", Document{"_id": barcode}, &document)
+	return document.GameId, found, err
+}
+
+func (repo MongoPhysicalCopyRepo) LinkBarcode(barcode string, gameId int) error {
+	return repo.docHandler.Upsert("barcodes", Document{"_id": barcode}, barcodeDocument{
+		Barcode: barcode, GameId: gameId, CreatedAt: time.Now().UTC()})
+}
+
+func (repo MongoPhysicalCopyRepo) SetPaidPrice(copyId int, price float64, currency string) error {
+	_, err := repo.docHandler.Update("physical_copies", Document{"_id": copyId},
+		Document{"$set": Document{"paid_price": price, "paid_currency": currency}})
+	return err
+}
+
+// Copies never valued lack valued_at, they sort first
+func (repo MongoPhysicalCopyRepo) FindStaleValues(before time.Time, limit int) ([]usecases.PhysicalCopy, error) {
+	var documents []physicalCopyDocument
+	err := repo.docHandler.Find("physical_copies", Document{"$or": []Document{
+		{"valued_at": Document{"$exists": false}}, {"valued_at": Document{"$lt": before}}}},
+		FindOptions{Sort: []string{"valued_at", "_id"}, Limit: limit}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var copies []usecases.PhysicalCopy
+	for _, document := range documents {
+		copies = append(copies, document.physicalCopy())
+	}
+	return copies, nil
+}
+
+func (repo MongoPhysicalCopyRepo) StoreValue(copyId int, estimate usecases.PriceEstimate) error {
+	_, err := repo.docHandler.Update("physical_copies", Document{"_id": copyId},
+		Document{"$set": Document{"value": estimate.Value, "value_currency": estimate.Currency,
+			"valued_at": time.Now().UTC()}})
+	return err
+}
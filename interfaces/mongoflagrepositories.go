@@ -0,0 +1,53 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoFlagRepo DocRepo
+
+type flagDocument struct {
+	Name        string    `bson:"_id"`
+	Description string    `bson:"description"`
+	Enabled     bool      `bson:"enabled"`
+	Percentage  int       `bson:"percentage"`
+	UserIds     []int     `bson:"user_ids"`
+	UpdatedAt   time.Time `bson:"updated_at"`
+}
+
+func NewMongoFlagRepo(docHandlers map[string]DocumentHandler) *MongoFlagRepo {
+	mongoFlagRepo := new(MongoFlagRepo)
+	mongoFlagRepo.docHandlers = docHandlers
+	mongoFlagRepo.docHandler = docHandlers["MongoFlagRepo"]
+	return mongoFlagRepo
+}
+
+func (repo MongoFlagRepo) FindAll() ([]usecases.FeatureFlag, error) {
+	var documents []flagDocument
+	err := repo.docHandler.Find("feature_flags", Document{}, FindOptions{Sort: []string{"_id"}},
+		&documents)
+	if err != nil {
+		return nil, err
+	}
+	var flags []usecases.FeatureFlag
+	for _, document := range documents {
+		flags = append(flags, usecases.FeatureFlag{Name: document.Name,
+			Description: document.Description, Enabled: document.Enabled,
+			Percentage: document.Percentage, UserIds: document.UserIds,
+			UpdatedAt: document.UpdatedAt})
+	}
+	return flags, nil
+}
+
+func (repo MongoFlagRepo) Store(flag usecases.FeatureFlag) error {
+	return repo.docHandler.Upsert("feature_flags", Document{"_id": flag.Name}, flagDocument{
+		Name: flag.Name, Description: flag.Description, Enabled: flag.Enabled,
+		Percentage: flag.Percentage, UserIds: flag.UserIds, UpdatedAt: time.Now().UTC()})
+}
+
+func (repo MongoFlagRepo) Remove(name string) error {
+	_, err := repo.docHandler.Delete("feature_flags", Document{"_id": name})
+	return err
+}
@@ -0,0 +1,190 @@
+package interfaces
+
+import (
+	"sort"
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoFranchiseRepo DocRepo
+
+// Franchises embed their entries, games are never edited once stored so
+// their external id and name are copied in
+type franchiseDocument struct {
+	Id         int                     `bson:"_id"`
+	ExternalId string                  `bson:"external_id"`
+	Name       string                  `bson:"name"`
+	Games      []franchiseGameDocument `bson:"games"`
+	CreatedAt  time.Time               `bson:"created_at"`
+}
+
+type franchiseGameDocument struct {
+	GameId         int    `bson:"game_id"`
+	GameExternalId string `bson:"game_external_id"`
+	GameName       string `bson:"game_name"`
+	Position       int    `bson:"position"`
+}
+
+func NewMongoFranchiseRepo(docHandlers map[string]DocumentHandler) *MongoFranchiseRepo {
+	mongoFranchiseRepo := new(MongoFranchiseRepo)
+	mongoFranchiseRepo.docHandlers = docHandlers
+	mongoFranchiseRepo.docHandler = docHandlers["MongoFranchiseRepo"]
+	return mongoFranchiseRepo
+}
+
+func (document franchiseDocument) franchise(withGames bool) usecases.Franchise {
+	franchise := usecases.Franchise{Id: document.Id, ExternalId: document.ExternalId,
+		Name: document.Name, CreatedAt: document.CreatedAt}
+	if !withGames {
+		return franchise
+	}
+	for _, game := range document.Games {
+		franchise.Games = append(franchise.Games, usecases.FranchiseGame{GameId: game.GameId,
+			GameExternalId: game.GameExternalId, GameName: game.GameName, Position: game.Position})
+	}
+	return franchise
+}
+
+func (repo MongoFranchiseRepo) Store(franchise usecases.Franchise) (int, error) {
+	id, err := repo.docHandler.NextSequence("franchises")
+	if err != nil {
+		return 0, err
+	}
+	err = repo.docHandler.Insert("franchises", franchiseDocument{Id: int(id),
+		ExternalId: newExternalId(), Name: franchise.Name, Games: []franchiseGameDocument{},
+		CreatedAt: time.Now().UTC()})
+	return int(id), err
+}
+
+func (repo MongoFranchiseRepo) Remove(franchise usecases.Franchise) error {
+	_, err := repo.docHandler.Delete("franchises", Document{"_id": franchise.Id})
+	return err
+}
+
+func (repo MongoFranchiseRepo) FindById(id int) (usecases.Franchise, error, int) {
+	return repo.findFranchise(Document{"_id": id})
+}
+
+func (repo MongoFranchiseRepo) FindByExternalId(externalId string) (usecases.Franchise, error, int) {
+	return repo.findFranchise(Document{"external_id": externalId})
+}
+
+func (repo MongoFranchiseRepo) findFranchise(filter Document) (usecases.Franchise, error, int) {
+	var document franchiseDocument
+	found, err := repo.docHandler.FindOne("franchises", filter, &document)
+	if err != nil {
+		return usecases.Franchise{}, err, 500
+	}
+	if !found {
+		return usecases.Franchise{}, domain.NewError(domain.CodeNotFound,
+			"No franchise matches %v", filter), 404
+	}
+	return document.franchise(true), nil, 200
+}
+
+func (repo MongoFranchiseRepo) FindAll() ([]usecases.Franchise, error) {
+	var documents []franchiseDocument
+	err := repo.docHandler.Find("franchises", Document{}, FindOptions{Sort: []string{"name", "_id"}},
+		&documents)
+	if err != nil {
+		return nil, err
+	}
+	var franchises []usecases.Franchise
+	for _, document := range documents {
+		franchises = append(franchises, document.franchise(false))
+	}
+	return franchises, nil
+}
+
+// Entries are kept sorted by position so reads need no sorting
+func (repo MongoFranchiseRepo) AddGame(franchiseId, gameId, position int) error {
+	var game gameDocument
+	found, err := repo.docHandler.FindOne("games", Document{"_id": gameId}, &game)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return domain.NewError(domain.CodeNotFound, "Game #%d does not exist", gameId)
+	}
+	var document franchiseDocument
+	found, err = repo.docHandler.FindOne("franchises", Document{"_id": franchiseId}, &document)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return domain.NewError(domain.CodeNotFound, "Franchise #%d does not exist", franchiseId)
+	}
+
+	games := []franchiseGameDocument{{GameId: game.Id, GameExternalId: game.ExternalId,
+		GameName: game.Name, Position: position}}
+	for _, entry := range document.Games {
+		if entry.GameId != gameId {
+			games = append(games, entry)
+		}
+	}
+	sort.SliceStable(games, func(i, j int) bool {
+		if games[i].Position != games[j].Position {
+			return games[i].Position < games[j].Position
+		}
+		return games[i].GameId < games[j].GameId
+	})
+	_, err = repo.docHandler.Update("franchises", Document{"_id": franchiseId},
+		Document{"$set": Document{"games": games}})
+	return err
+}
+
+func (repo MongoFranchiseRepo) RemoveGame(franchiseId, gameId int) (bool, error) {
+	removed, err := repo.docHandler.Update("franchises",
+		Document{"_id": franchiseId, "games.game_id": gameId},
+		Document{"$pull": Document{"games": Document{"game_id": gameId}}})
+	return removed > 0, err
+}
+
+// Mirrors the SQL query: a game is owned when any library of the user holds
+// it and completed when it has the completed status in any of them
+func (repo MongoFranchiseRepo) FindProgress(userId int) ([]usecases.FranchiseProgress, error) {
+	var libraries []libraryDocument
+	err := repo.docHandler.Find("libraries", Document{"user_id": userId}, FindOptions{}, &libraries)
+	if err != nil {
+		return nil, err
+	}
+	completed := make(map[int]bool)
+	for _, library := range libraries {
+		for _, game := range library.Games {
+			completed[game.GameId] = completed[game.GameId] || game.Status == "completed"
+		}
+	}
+
+	var documents []franchiseDocument
+	err = repo.docHandler.Find("franchises", Document{"games.game_id": Document{"$in": ownedIds(completed)}},
+		FindOptions{Sort: []string{"name", "_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var progress []usecases.FranchiseProgress
+	for _, document := range documents {
+		entry := usecases.FranchiseProgress{Franchise: document.franchise(false),
+			Total: len(document.Games)}
+		for _, game := range document.Games {
+			done, owned := completed[game.GameId]
+			if owned {
+				entry.Owned++
+			}
+			if done {
+				entry.Completed++
+			}
+		}
+		progress = append(progress, entry)
+	}
+	return progress, nil
+}
+
+func ownedIds(games map[int]bool) []int {
+	ids := []int{}
+	for id := range games {
+		ids = append(ids, id)
+	}
+	return ids
+}
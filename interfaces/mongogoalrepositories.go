@@ -0,0 +1,106 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoGoalRepo DocRepo
+
+type goalDocument struct {
+	Id         int       `bson:"_id"`
+	UserId     int       `bson:"user_id"`
+	Kind       string    `bson:"kind"`
+	Target     int       `bson:"target"`
+	Period     string    `bson:"period"`
+	Year       int       `bson:"year"`
+	Quarter    int       `bson:"quarter"`
+	StartsAt   time.Time `bson:"starts_at"`
+	EndsAt     time.Time `bson:"ends_at"`
+	RemindedAt time.Time `bson:"reminded_at"`
+	CreatedAt  time.Time `bson:"created_at"`
+}
+
+func NewMongoGoalRepo(docHandlers map[string]DocumentHandler) *MongoGoalRepo {
+	mongoGoalRepo := new(MongoGoalRepo)
+	mongoGoalRepo.docHandlers = docHandlers
+	mongoGoalRepo.docHandler = docHandlers["MongoGoalRepo"]
+	return mongoGoalRepo
+}
+
+func (document goalDocument) goal() usecases.Goal {
+	return usecases.Goal{Id: document.Id, UserId: document.UserId, Kind: document.Kind,
+		Target: document.Target, Period: document.Period, Year: document.Year,
+		Quarter: document.Quarter, StartsAt: document.StartsAt, EndsAt: document.EndsAt,
+		RemindedAt: document.RemindedAt, CreatedAt: document.CreatedAt}
+}
+
+func (repo MongoGoalRepo) Store(goal usecases.Goal) (int, error) {
+	id, err := repo.docHandler.NextSequence("goals")
+	if err != nil {
+		return 0, err
+	}
+	err = repo.docHandler.Insert("goals", goalDocument{Id: int(id), UserId: goal.UserId,
+		Kind: goal.Kind, Target: goal.Target, Period: goal.Period, Year: goal.Year,
+		Quarter: goal.Quarter, StartsAt: goal.StartsAt, EndsAt: goal.EndsAt,
+		CreatedAt: time.Now().UTC()})
+	return int(id), err
+}
+
+func (repo MongoGoalRepo) FindById(id int) (usecases.Goal, error, int) {
+	var document goalDocument
+	found, err := repo.docHandler.FindOne("goals", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.Goal{}, err, 500
+	}
+	if !found {
+		return usecases.Goal{}, domain.NewError(domain.CodeNotFound, "Goal #%d does not exist", id), 404
+	}
+	return document.goal(), nil, 200
+}
+
+func (repo MongoGoalRepo) FindByUser(userId int) ([]usecases.Goal, error) {
+	return repo.find(Document{"user_id": userId}, []string{"-starts_at", "_id"})
+}
+
+func (repo MongoGoalRepo) FindRunning(now time.Time) ([]usecases.Goal, error) {
+	return repo.find(Document{"starts_at": Document{"$lte": now}, "ends_at": Document{"$gt": now}},
+		[]string{"_id"})
+}
+
+func (repo MongoGoalRepo) find(filter Document, sort []string) ([]usecases.Goal, error) {
+	var documents []goalDocument
+	err := repo.docHandler.Find("goals", filter, FindOptions{Sort: sort}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var goals []usecases.Goal
+	for _, document := range documents {
+		goals = append(goals, document.goal())
+	}
+	return goals, nil
+}
+
+func (repo MongoGoalRepo) SetTarget(id, target int) error {
+	_, err := repo.docHandler.Update("goals", Document{"_id": id},
+		Document{"$set": Document{"target": target}})
+	return err
+}
+
+func (repo MongoGoalRepo) MarkReminded(id int, at time.Time) error {
+	_, err := repo.docHandler.Update("goals", Document{"_id": id},
+		Document{"$set": Document{"reminded_at": at}})
+	return err
+}
+
+func (repo MongoGoalRepo) Remove(goal usecases.Goal) error {
+	_, err := repo.docHandler.Delete("goals", Document{"_id": goal.Id})
+	return err
+}
+
+func (repo MongoGoalRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("goals", Document{"user_id": userId})
+	return err
+}
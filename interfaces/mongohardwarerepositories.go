@@ -0,0 +1,121 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoHardwareRepo DocRepo
+
+// Unknown dates are kept as the zero time
+type hardwareDocument struct {
+	Id            int       `bson:"_id"`
+	UserId        int       `bson:"user_id"`
+	Kind          string    `bson:"kind"`
+	Name          string    `bson:"name"`
+	Model         string    `bson:"model"`
+	Serial        string    `bson:"serial"`
+	PurchasedOn   time.Time `bson:"purchased_on"`
+	WarrantyUntil time.Time `bson:"warranty_until"`
+	RemindedAt    time.Time `bson:"reminded_at"`
+	CreatedAt     time.Time `bson:"created_at"`
+	UpdatedAt     time.Time `bson:"updated_at"`
+}
+
+func NewMongoHardwareRepo(docHandlers map[string]DocumentHandler) *MongoHardwareRepo {
+	mongoHardwareRepo := new(MongoHardwareRepo)
+	mongoHardwareRepo.docHandlers = docHandlers
+	mongoHardwareRepo.docHandler = docHandlers["MongoHardwareRepo"]
+	return mongoHardwareRepo
+}
+
+func (document hardwareDocument) item() usecases.Hardware {
+	return usecases.Hardware{Id: document.Id, UserId: document.UserId, Kind: document.Kind,
+		Name: document.Name, Model: document.Model, Serial: document.Serial,
+		PurchasedOn: document.PurchasedOn, WarrantyUntil: document.WarrantyUntil,
+		RemindedAt: document.RemindedAt, CreatedAt: document.CreatedAt, UpdatedAt: document.UpdatedAt}
+}
+
+func (repo MongoHardwareRepo) Store(item usecases.Hardware) (int, error) {
+	id, err := repo.docHandler.NextSequence("hardware")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	err = repo.docHandler.Insert("hardware", hardwareDocument{Id: int(id), UserId: item.UserId,
+		Kind: item.Kind, Name: item.Name, Model: item.Model, Serial: item.Serial,
+		PurchasedOn: item.PurchasedOn, WarrantyUntil: item.WarrantyUntil, CreatedAt: now,
+		UpdatedAt: now})
+	return int(id), err
+}
+
+func (repo MongoHardwareRepo) FindById(id int) (usecases.Hardware, error, int) {
+	var document hardwareDocument
+	found, err := repo.docHandler.FindOne("hardware", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.Hardware{}, err, 500
+	}
+	if !found {
+		return usecases.Hardware{}, domain.NewError(domain.CodeNotFound,
+			"Hardware #%d does not exist", id), 404
+	}
+	return document.item(), nil, 200
+}
+
+func (repo MongoHardwareRepo) FindByUser(userId int) ([]usecases.Hardware, error) {
+	return repo.find(Document{"user_id": userId}, []string{"kind", "name", "_id"})
+}
+
+func (repo MongoHardwareRepo) FindExpiring(from, to time.Time) ([]usecases.Hardware, error) {
+	return repo.find(Document{"reminded_at": time.Time{},
+		"warranty_until": Document{"$gte": from, "$lte": to}}, []string{"_id"})
+}
+
+func (repo MongoHardwareRepo) find(filter Document, sort []string) ([]usecases.Hardware, error) {
+	var documents []hardwareDocument
+	err := repo.docHandler.Find("hardware", filter, FindOptions{Sort: sort}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var items []usecases.Hardware
+	for _, document := range documents {
+		items = append(items, document.item())
+	}
+	return items, nil
+}
+
+func (repo MongoHardwareRepo) Update(item usecases.Hardware) error {
+	existing, err, code := repo.FindById(item.Id)
+	if code == 404 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	set := Document{"kind": item.Kind, "name": item.Name, "model": item.Model,
+		"serial": item.Serial, "purchased_on": item.PurchasedOn,
+		"warranty_until": item.WarrantyUntil, "updated_at": time.Now().UTC()}
+	if !existing.WarrantyUntil.Equal(item.WarrantyUntil) {
+		set["reminded_at"] = time.Time{}
+	}
+	_, err = repo.docHandler.Update("hardware", Document{"_id": item.Id}, Document{"$set": set})
+	return err
+}
+
+func (repo MongoHardwareRepo) MarkReminded(id int, at time.Time) error {
+	_, err := repo.docHandler.Update("hardware", Document{"_id": id},
+		Document{"$set": Document{"reminded_at": at}})
+	return err
+}
+
+func (repo MongoHardwareRepo) Remove(item usecases.Hardware) error {
+	_, err := repo.docHandler.Delete("hardware", Document{"_id": item.Id})
+	return err
+}
+
+func (repo MongoHardwareRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("hardware", Document{"user_id": userId})
+	return err
+}
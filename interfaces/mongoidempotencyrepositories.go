@@ -0,0 +1,63 @@
+package interfaces
+
+import (
+	"time"
+)
+
+type MongoIdempotencyRepo DocRepo
+
+type idempotencyDocument struct {
+	Scope       string    `bson:"scope"`
+	Key         string    `bson:"key"`
+	Fingerprint string    `bson:"fingerprint"`
+	Status      int       `bson:"status"`
+	ContentType string    `bson:"content_type"`
+	Body        []byte    `bson:"body"`
+	CreatedAt   time.Time `bson:"created_at"`
+}
+
+func NewMongoIdempotencyRepo(docHandlers map[string]DocumentHandler) *MongoIdempotencyRepo {
+	mongoIdempotencyRepo := new(MongoIdempotencyRepo)
+	mongoIdempotencyRepo.docHandlers = docHandlers
+	mongoIdempotencyRepo.docHandler = docHandlers["MongoIdempotencyRepo"]
+	return mongoIdempotencyRepo
+}
+
+// Claims the key for a new request, the unique index on scope and key
+// rejects the insert when it is already taken
+func (repo MongoIdempotencyRepo) Reserve(scope, key, fingerprint string) (bool, error) {
+	_, err := repo.docHandler.Delete("idempotency_keys", Document{"scope": scope, "key": key,
+		"created_at": Document{"$lt": time.Now().UTC().Add(-24 * time.Hour)}})
+	if err != nil {
+		return false, err
+	}
+	err = repo.docHandler.Insert("idempotency_keys", idempotencyDocument{Scope: scope, Key: key,
+		Fingerprint: fingerprint, CreatedAt: time.Now().UTC()})
+	if err == ErrDuplicateDocument {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (repo MongoIdempotencyRepo) Find(scope, key string) (IdempotentResponse, bool, error) {
+	var document idempotencyDocument
+	found, err := repo.docHandler.FindOne("idempotency_keys", Document{"scope": scope, "key": key},
+		&document)
+	if err != nil || !found {
+		return IdempotentResponse{}, found, err
+	}
+	return IdempotentResponse{Fingerprint: document.Fingerprint, Status: document.Status,
+		ContentType: document.ContentType, Body: document.Body}, true, nil
+}
+
+func (repo MongoIdempotencyRepo) Complete(scope, key string, status int, contentType string,
+	body []byte) error {
+	_, err := repo.docHandler.Update("idempotency_keys", Document{"scope": scope, "key": key},
+		Document{"$set": Document{"status": status, "content_type": contentType, "body": body}})
+	return err
+}
+
+func (repo MongoIdempotencyRepo) Release(scope, key string) error {
+	_, err := repo.docHandler.Delete("idempotency_keys", Document{"scope": scope, "key": key})
+	return err
+}
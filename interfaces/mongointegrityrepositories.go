@@ -0,0 +1,229 @@
+package interfaces
+
+import (
+	"fmt"
+	"sort"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+// Documents cannot be joined, so the checks load the ids of both sides and
+// compare them here
+type MongoIntegrityRepo DocRepo
+
+// Fields the checks read, any collection decodes into it
+type integrityDocument struct {
+	Id               interface{}              `bson:"_id"`
+	UserId           int                      `bson:"user_id"`
+	LibraryId        int                      `bson:"library_id"`
+	PlayerId         int                      `bson:"player_id"`
+	Name             string                   `bson:"user_name"`
+	DefaultLibraryId int                      `bson:"default_library_id"`
+	Games            []integrityEntryDocument `bson:"games"`
+}
+
+type integrityEntryDocument struct {
+	GameId int `bson:"game_id"`
+}
+
+func NewMongoIntegrityRepo(docHandlers map[string]DocumentHandler) *MongoIntegrityRepo {
+	mongoIntegrityRepo := new(MongoIntegrityRepo)
+	mongoIntegrityRepo.docHandlers = docHandlers
+	mongoIntegrityRepo.docHandler = docHandlers["MongoIntegrityRepo"]
+	return mongoIntegrityRepo
+}
+
+func (repo MongoIntegrityRepo) load(collection string) ([]integrityDocument, error) {
+	var documents []integrityDocument
+	err := repo.docHandler.Find(collection, Document{}, FindOptions{}, &documents)
+	return documents, err
+}
+
+func (repo MongoIntegrityRepo) ids(collection string) (map[int]bool, error) {
+	documents, err := repo.load(collection)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int]bool)
+	for _, document := range documents {
+		id, isInt := intId(document.Id)
+		if isInt {
+			ids[id] = true
+		}
+	}
+	return ids, nil
+}
+
+// Ids are stored as int32 or int64 depending on how they were written
+func intId(id interface{}) (int, bool) {
+	switch id := id.(type) {
+	case int:
+		return id, true
+	case int32:
+		return int(id), true
+	case int64:
+		return int(id), true
+	}
+	return 0, false
+}
+
+// The documents of collection breaking the check and the ids a finding
+// lists for them
+func (repo MongoIntegrityRepo) broken(name string) (string, []integrityDocument, []int, error) {
+	var collection, parent string
+	var orphaned func(document integrityDocument, parents map[int]bool) (int, bool)
+	switch name {
+	case usecases.IntegrityUsersWithoutPlayers:
+		collection, parent = "users", "players"
+		orphaned = func(document integrityDocument, players map[int]bool) (int, bool) {
+			id, _ := intId(document.Id)
+			return id, !players[document.PlayerId]
+		}
+	case usecases.IntegrityLibrariesWithoutUsers:
+		collection, parent = "libraries", "users"
+		orphaned = func(document integrityDocument, users map[int]bool) (int, bool) {
+			id, _ := intId(document.Id)
+			return id, !users[document.UserId]
+		}
+	case usecases.IntegrityEntriesWithoutLibraries:
+		// Entries are embedded in their library
+		return "", nil, nil, nil
+	case usecases.IntegrityEntriesWithoutGames:
+		// Found per library, the finding lists the libraries
+		collection, parent = "libraries", "games"
+		orphaned = func(document integrityDocument, games map[int]bool) (int, bool) {
+			id, _ := intId(document.Id)
+			for _, entry := range document.Games {
+				if !games[entry.GameId] {
+					return id, true
+				}
+			}
+			return id, false
+		}
+	case usecases.IntegrityCopiesWithoutLibraries:
+		collection, parent = "physical_copies", "libraries"
+		orphaned = func(document integrityDocument, libraries map[int]bool) (int, bool) {
+			id, _ := intId(document.Id)
+			return id, !libraries[document.LibraryId]
+		}
+	case usecases.IntegrityMembersWithoutOwners:
+		users, err := repo.ids("users")
+		if err != nil {
+			return "", nil, nil, err
+		}
+		collection, parent = "library_members", "libraries"
+		orphaned = func(document integrityDocument, libraries map[int]bool) (int, bool) {
+			return document.UserId, !libraries[document.LibraryId] || !users[document.UserId]
+		}
+	case usecases.IntegrityNotificationsOrphaned:
+		collection, parent = "notifications", "users"
+		orphaned = func(document integrityDocument, users map[int]bool) (int, bool) {
+			id, _ := intId(document.Id)
+			return id, !users[document.UserId]
+		}
+	case usecases.IntegritySettingsOrphaned:
+		collection, parent = "settings", "users"
+		orphaned = func(document integrityDocument, users map[int]bool) (int, bool) {
+			id, _ := intId(document.Id)
+			return id, !users[id]
+		}
+	case usecases.IntegrityDefaultLibraryMissing:
+		collection, parent = "settings", "libraries"
+		orphaned = func(document integrityDocument, libraries map[int]bool) (int, bool) {
+			id, _ := intId(document.Id)
+			return id, document.DefaultLibraryId != 0 && !libraries[document.DefaultLibraryId]
+		}
+	case usecases.IntegrityLoginsWithoutUsers:
+		// Logins share the id of their user
+		collection, parent = "logins", "users"
+		orphaned = func(document integrityDocument, users map[int]bool) (int, bool) {
+			id, _ := intId(document.Id)
+			return id, !users[id]
+		}
+	default:
+		return "", nil, nil, fmt.Errorf("Unknown integrity check '%s'", name)
+	}
+
+	parents, err := repo.ids(parent)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	documents, err := repo.load(collection)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	var broken []integrityDocument
+	var ids []int
+	for _, document := range documents {
+		id, isBroken := orphaned(document, parents)
+		if isBroken {
+			broken = append(broken, document)
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return collection, broken, ids, nil
+}
+
+func (repo MongoIntegrityRepo) FindBroken(name string, limit int) ([]int, int, error) {
+	_, _, ids, err := repo.broken(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	count := len(ids)
+	if count > limit {
+		ids = ids[:limit]
+	}
+	return ids, count, nil
+}
+
+func (repo MongoIntegrityRepo) Repair(name string) (int, error) {
+	collection, documents, _, err := repo.broken(name)
+	if err != nil {
+		return 0, err
+	}
+	var games map[int]bool
+	if name == usecases.IntegrityEntriesWithoutGames {
+		games, err = repo.ids("games")
+		if err != nil {
+			return 0, err
+		}
+	}
+	players := NewMongoPlayerRepo(repo.docHandlers)
+	repaired := 0
+	for _, document := range documents {
+		filter := Document{"_id": document.Id}
+		var changed int64
+		switch name {
+		case usecases.IntegrityUsersWithoutPlayers:
+			var playerId int
+			playerId, err = players.Store(domain.Player{Name: document.Name})
+			if err != nil {
+				return repaired, err
+			}
+			changed, err = repo.docHandler.Update(collection, filter,
+				Document{"$set": Document{"player_id": playerId}})
+		case usecases.IntegrityEntriesWithoutGames:
+			var missing []int
+			for _, entry := range document.Games {
+				if !games[entry.GameId] {
+					missing = append(missing, entry.GameId)
+				}
+			}
+			changed, err = repo.docHandler.Update(collection, filter, Document{
+				"$pull": Document{"games": Document{"game_id": Document{"$in": missing}}},
+				"$inc":  Document{"version": 1}})
+		case usecases.IntegrityDefaultLibraryMissing:
+			changed, err = repo.docHandler.Update(collection, filter,
+				Document{"$set": Document{"default_library_id": 0}})
+		default:
+			changed, err = repo.docHandler.Delete(collection, filter)
+		}
+		if err != nil {
+			return repaired, err
+		}
+		repaired += int(changed)
+	}
+	return repaired, nil
+}
@@ -0,0 +1,118 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoJournalRepo DocRepo
+
+type journalEntryDocument struct {
+	Id              int       `bson:"_id"`
+	UserId          int       `bson:"user_id"`
+	GameId          int       `bson:"game_id"`
+	GameExternalId  string    `bson:"game_external_id"`
+	GameName        string    `bson:"game_name"`
+	Text            string    `bson:"text"`
+	Html            string    `bson:"text_html"`
+	SpoilerFreeHtml string    `bson:"spoiler_free_html"`
+	HasSpoilers     bool      `bson:"has_spoilers"`
+	Screenshot      string    `bson:"screenshot"`
+	ScreenshotType  string    `bson:"screenshot_type"`
+	CreatedAt       time.Time `bson:"created_at"`
+}
+
+func NewMongoJournalRepo(docHandlers map[string]DocumentHandler) *MongoJournalRepo {
+	mongoJournalRepo := new(MongoJournalRepo)
+	mongoJournalRepo.docHandlers = docHandlers
+	mongoJournalRepo.docHandler = docHandlers["MongoJournalRepo"]
+	return mongoJournalRepo
+}
+
+func (document journalEntryDocument) entry() usecases.JournalEntry {
+	return usecases.JournalEntry{Id: document.Id, UserId: document.UserId, GameId: document.GameId,
+		GameExternalId: document.GameExternalId, GameName: document.GameName, Text: document.Text,
+		Html: document.Html, SpoilerFreeHtml: document.SpoilerFreeHtml, HasSpoilers: document.HasSpoilers,
+		Screenshot: document.Screenshot, ScreenshotType: document.ScreenshotType,
+		CreatedAt: document.CreatedAt}
+}
+
+func (repo MongoJournalRepo) Store(entry usecases.JournalEntry) (int, error) {
+	id, err := repo.docHandler.NextSequence("journal_entries")
+	if err != nil {
+		return 0, err
+	}
+	err = repo.docHandler.Insert("journal_entries", journalEntryDocument{Id: int(id),
+		UserId: entry.UserId, GameId: entry.GameId, GameExternalId: entry.GameExternalId,
+		GameName: entry.GameName, Text: entry.Text, Html: entry.Html,
+		SpoilerFreeHtml: entry.SpoilerFreeHtml, HasSpoilers: entry.HasSpoilers, Screenshot: entry.Screenshot,
+		ScreenshotType: entry.ScreenshotType, CreatedAt: time.Now().UTC()})
+	return int(id), err
+}
+
+func (repo MongoJournalRepo) FindById(id int) (usecases.JournalEntry, error, int) {
+	var document journalEntryDocument
+	found, err := repo.docHandler.FindOne("journal_entries", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.JournalEntry{}, err, 500
+	}
+	if !found {
+		return usecases.JournalEntry{}, domain.NewError(domain.CodeNotFound,
+			"Journal entry #%d does not exist", id), 404
+	}
+	return document.entry(), nil, 200
+}
+
+func (repo MongoJournalRepo) FindByUser(userId, gameId int, page usecases.Page) ([]usecases.JournalEntry, error) {
+	after, err := page.After.Time()
+	if err != nil {
+		return nil, err
+	}
+	filter := Document{"user_id": userId}
+	if gameId > 0 {
+		filter["game_id"] = gameId
+	}
+	options := pageDocuments(filter, page, "created_at", after, false)
+	var documents []journalEntryDocument
+	err = repo.docHandler.Find("journal_entries", filter, options, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var entries []usecases.JournalEntry
+	for _, document := range documents {
+		entries = append(entries, document.entry())
+	}
+	return entries, nil
+}
+
+func (repo MongoJournalRepo) StoreHtml(entry usecases.JournalEntry) error {
+	_, err := repo.docHandler.Update("journal_entries", Document{"_id": entry.Id},
+		Document{"$set": Document{"text_html": entry.Html, "spoiler_free_html": entry.SpoilerFreeHtml,
+			"has_spoilers": entry.HasSpoilers}})
+	return err
+}
+
+func (repo MongoJournalRepo) Remove(entry usecases.JournalEntry) error {
+	_, err := repo.docHandler.Delete("journal_entries", Document{"_id": entry.Id})
+	return err
+}
+
+func (repo MongoJournalRepo) RemoveAll(userId int) ([]string, error) {
+	var documents []journalEntryDocument
+	err := repo.docHandler.Find("journal_entries", Document{"user_id": userId,
+		"screenshot": Document{"$ne": ""}}, FindOptions{}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	_, err = repo.docHandler.Delete("journal_entries", Document{"user_id": userId})
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, document := range documents {
+		keys = append(keys, document.Screenshot)
+	}
+	return keys, nil
+}
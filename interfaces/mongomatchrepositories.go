@@ -0,0 +1,121 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoMatchRepo DocRepo
+
+type matchDocument struct {
+	Id             int       `bson:"_id"`
+	UserId         int       `bson:"user_id"`
+	GameId         int       `bson:"game_id"`
+	GameExternalId string    `bson:"game_external_id"`
+	GameName       string    `bson:"game_name"`
+	Result         string    `bson:"result"`
+	Score          string    `bson:"score"`
+	Opponents      []string  `bson:"opponents"`
+	PlayedAt       time.Time `bson:"played_at"`
+	CreatedAt      time.Time `bson:"created_at"`
+}
+
+func NewMongoMatchRepo(docHandlers map[string]DocumentHandler) *MongoMatchRepo {
+	mongoMatchRepo := new(MongoMatchRepo)
+	mongoMatchRepo.docHandlers = docHandlers
+	mongoMatchRepo.docHandler = docHandlers["MongoMatchRepo"]
+	return mongoMatchRepo
+}
+
+func (document matchDocument) match() usecases.Match {
+	return usecases.Match{Id: document.Id, UserId: document.UserId, GameId: document.GameId,
+		GameExternalId: document.GameExternalId, GameName: document.GameName, Result: document.Result,
+		Score: document.Score, Opponents: document.Opponents, PlayedAt: document.PlayedAt,
+		CreatedAt: document.CreatedAt}
+}
+
+// Documents are inserted one by one, those already stored are removed
+// again when one fails
+func (repo MongoMatchRepo) StoreBatch(matches []usecases.Match) ([]int, error) {
+	var ids []int
+	now := time.Now().UTC()
+	for _, match := range matches {
+		id, err := repo.docHandler.NextSequence("matches")
+		if err != nil {
+			repo.discard(ids)
+			return nil, err
+		}
+		err = repo.docHandler.Insert("matches", matchDocument{Id: int(id), UserId: match.UserId,
+			GameId: match.GameId, GameExternalId: match.GameExternalId, GameName: match.GameName,
+			Result: match.Result, Score: match.Score, Opponents: match.Opponents,
+			PlayedAt: match.PlayedAt, CreatedAt: now})
+		if err != nil {
+			repo.discard(ids)
+			return nil, err
+		}
+		ids = append(ids, int(id))
+	}
+	return ids, nil
+}
+
+func (repo MongoMatchRepo) discard(ids []int) {
+	if len(ids) > 0 {
+		repo.docHandler.Delete("matches", Document{"_id": Document{"$in": ids}})
+	}
+}
+
+func (repo MongoMatchRepo) FindById(id int) (usecases.Match, error, int) {
+	var document matchDocument
+	found, err := repo.docHandler.FindOne("matches", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.Match{}, err, 500
+	}
+	if !found {
+		return usecases.Match{}, domain.NewError(domain.CodeNotFound, "Match #%d does not exist", id), 404
+	}
+	return document.match(), nil, 200
+}
+
+func (repo MongoMatchRepo) FindByUser(userId int, filter usecases.MatchFilter) ([]usecases.Match, error) {
+	after, err := filter.Page.After.Time()
+	if err != nil {
+		return nil, err
+	}
+	query := Document{"user_id": userId}
+	if filter.GameId != 0 {
+		query["game_id"] = filter.GameId
+	}
+	played := Document{}
+	if !filter.From.IsZero() {
+		played["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		played["$lt"] = filter.To
+	}
+	if len(played) > 0 {
+		query["played_at"] = played
+	}
+	options := pageDocuments(query, filter.Page, "played_at", after, true)
+	var documents []matchDocument
+	err = repo.docHandler.Find("matches", query, options, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var matches []usecases.Match
+	for _, document := range documents {
+		matches = append(matches, document.match())
+	}
+	return matches, nil
+}
+
+func (repo MongoMatchRepo) Remove(match usecases.Match) error {
+	_, err := repo.docHandler.Delete("matches", Document{"_id": match.Id})
+	return err
+}
+
+func (repo MongoMatchRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("matches", Document{"user_id": userId})
+	return err
+}
@@ -0,0 +1,98 @@
+package interfaces
+
+import (
+	"fmt"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoLibraryMemberRepo DocRepo
+
+type libraryMemberDocument struct {
+	Id        string    `bson:"_id"` //Library and user id
+	LibraryId int       `bson:"library_id"`
+	UserId    int       `bson:"user_id"`
+	Role      string    `bson:"role"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func NewMongoLibraryMemberRepo(docHandlers map[string]DocumentHandler) *MongoLibraryMemberRepo {
+	mongoLibraryMemberRepo := new(MongoLibraryMemberRepo)
+	mongoLibraryMemberRepo.docHandlers = docHandlers
+	mongoLibraryMemberRepo.docHandler = docHandlers["MongoLibraryMemberRepo"]
+	return mongoLibraryMemberRepo
+}
+
+func libraryMemberId(libraryId, userId int) string {
+	return fmt.Sprintf("%d/%d", libraryId, userId)
+}
+
+func (document libraryMemberDocument) member() usecases.LibraryMember {
+	return usecases.LibraryMember{LibraryId: document.LibraryId, User: usecases.User{Id: document.UserId},
+		Role: document.Role, CreatedAt: document.CreatedAt}
+}
+
+// Members keep the time they joined when their role changes
+func (repo MongoLibraryMemberRepo) Store(member usecases.LibraryMember) error {
+	id := libraryMemberId(member.LibraryId, member.User.Id)
+	matched, err := repo.docHandler.Update("library_members", Document{"_id": id},
+		Document{"$set": Document{"role": member.Role}})
+	if err != nil || matched > 0 {
+		return err
+	}
+	return repo.docHandler.Insert("library_members", libraryMemberDocument{Id: id,
+		LibraryId: member.LibraryId, UserId: member.User.Id, Role: member.Role,
+		CreatedAt: time.Now().UTC()})
+}
+
+func (repo MongoLibraryMemberRepo) FindByLib(libraryId int) ([]usecases.LibraryMember, error) {
+	return repo.find(Document{"library_id": libraryId}, []string{"created_at", "user_id"})
+}
+
+func (repo MongoLibraryMemberRepo) FindByUser(userId int) ([]usecases.LibraryMember, error) {
+	return repo.find(Document{"user_id": userId}, []string{"library_id"})
+}
+
+func (repo MongoLibraryMemberRepo) find(filter Document, sort []string) ([]usecases.LibraryMember, error) {
+	var documents []libraryMemberDocument
+	err := repo.docHandler.Find("library_members", filter, FindOptions{Sort: sort}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var members []usecases.LibraryMember
+	for _, document := range documents {
+		members = append(members, document.member())
+	}
+	return members, nil
+}
+
+func (repo MongoLibraryMemberRepo) FindRole(libraryId, userId int) (string, error) {
+	var library libraryDocument
+	found, err := repo.docHandler.FindOne("libraries", Document{"_id": libraryId}, &library)
+	if err != nil || !found {
+		return "", err
+	}
+	if library.UserId == userId {
+		return usecases.LibraryRoleOwner, nil
+	}
+	var document libraryMemberDocument
+	_, err = repo.docHandler.FindOne("library_members",
+		Document{"_id": libraryMemberId(libraryId, userId)}, &document)
+	return document.Role, err
+}
+
+func (repo MongoLibraryMemberRepo) Remove(libraryId, userId int) error {
+	_, err := repo.docHandler.Delete("library_members", Document{"_id": libraryMemberId(libraryId, userId)})
+	return err
+}
+
+func (repo MongoLibraryMemberRepo) RemoveFromLib(libraryId int) error {
+	_, err := repo.docHandler.Delete("library_members", Document{"library_id": libraryId})
+	return err
+}
+
+func (repo MongoLibraryMemberRepo) RemoveFromUser(userId int) error {
+	_, err := repo.docHandler.Delete("library_members", Document{"user_id": userId})
+	return err
+}
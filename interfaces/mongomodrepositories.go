@@ -0,0 +1,95 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoModRepo DocRepo
+
+type modDocument struct {
+	Id             int       `bson:"_id"`
+	LibraryId      int       `bson:"library_id"`
+	GameId         int       `bson:"game_id"`
+	GameExternalId string    `bson:"game_external_id"`
+	GameName       string    `bson:"game_name"`
+	Name           string    `bson:"name"`
+	SourceUrl      string    `bson:"source_url"`
+	Version        string    `bson:"version"`
+	Enabled        bool      `bson:"enabled"`
+	CreatedAt      time.Time `bson:"created_at"`
+	UpdatedAt      time.Time `bson:"updated_at"`
+}
+
+func NewMongoModRepo(docHandlers map[string]DocumentHandler) *MongoModRepo {
+	mongoModRepo := new(MongoModRepo)
+	mongoModRepo.docHandlers = docHandlers
+	mongoModRepo.docHandler = docHandlers["MongoModRepo"]
+	return mongoModRepo
+}
+
+func (document modDocument) mod() usecases.Mod {
+	return usecases.Mod{Id: document.Id, LibraryId: document.LibraryId, GameId: document.GameId,
+		GameExternalId: document.GameExternalId, GameName: document.GameName, Name: document.Name,
+		SourceUrl: document.SourceUrl, Version: document.Version, Enabled: document.Enabled,
+		CreatedAt: document.CreatedAt, UpdatedAt: document.UpdatedAt}
+}
+
+func (repo MongoModRepo) Store(mod usecases.Mod) (int, error) {
+	id, err := repo.docHandler.NextSequence("mods")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	err = repo.docHandler.Insert("mods", modDocument{Id: int(id), LibraryId: mod.LibraryId,
+		GameId: mod.GameId, GameExternalId: mod.GameExternalId, GameName: mod.GameName, Name: mod.Name,
+		SourceUrl: mod.SourceUrl, Version: mod.Version, Enabled: mod.Enabled, CreatedAt: now, UpdatedAt: now})
+	return int(id), err
+}
+
+func (repo MongoModRepo) FindById(id int) (usecases.Mod, error, int) {
+	var document modDocument
+	found, err := repo.docHandler.FindOne("mods", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.Mod{}, err, 500
+	}
+	if !found {
+		return usecases.Mod{}, domain.NewError(domain.CodeNotFound, "Mod #%d does not exist", id), 404
+	}
+	return document.mod(), nil, 200
+}
+
+func (repo MongoModRepo) FindByGame(libraryId, gameId int) ([]usecases.Mod, error) {
+	return repo.find(Document{"library_id": libraryId, "game_id": gameId}, []string{"name", "_id"})
+}
+
+func (repo MongoModRepo) FindByLib(libraryId int) ([]usecases.Mod, error) {
+	return repo.find(Document{"library_id": libraryId}, []string{"game_name", "game_id", "name", "_id"})
+}
+
+func (repo MongoModRepo) find(filter Document, sort []string) ([]usecases.Mod, error) {
+	var documents []modDocument
+	err := repo.docHandler.Find("mods", filter, FindOptions{Sort: sort}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var mods []usecases.Mod
+	for _, document := range documents {
+		mods = append(mods, document.mod())
+	}
+	return mods, nil
+}
+
+func (repo MongoModRepo) Update(mod usecases.Mod) error {
+	_, err := repo.docHandler.Update("mods", Document{"_id": mod.Id}, Document{"$set": Document{
+		"name": mod.Name, "source_url": mod.SourceUrl, "version": mod.Version, "enabled": mod.Enabled,
+		"updated_at": time.Now().UTC()}})
+	return err
+}
+
+func (repo MongoModRepo) Remove(mod usecases.Mod) error {
+	_, err := repo.docHandler.Delete("mods", Document{"_id": mod.Id})
+	return err
+}
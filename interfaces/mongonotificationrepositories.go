@@ -0,0 +1,82 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoNotificationRepo DocRepo
+
+type notificationDocument struct {
+	Id        int       `bson:"_id"`
+	UserId    int       `bson:"user_id"`
+	Kind      string    `bson:"kind"`
+	Message   string    `bson:"message"`
+	Read      bool      `bson:"read"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+func NewMongoNotificationRepo(docHandlers map[string]DocumentHandler) *MongoNotificationRepo {
+	mongoNotificationRepo := new(MongoNotificationRepo)
+	mongoNotificationRepo.docHandlers = docHandlers
+	mongoNotificationRepo.docHandler = docHandlers["MongoNotificationRepo"]
+	return mongoNotificationRepo
+}
+
+func (repo MongoNotificationRepo) Store(notification usecases.Notification) (int, error) {
+	id, err := repo.docHandler.NextSequence("notifications")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	err = repo.docHandler.Insert("notifications", notificationDocument{Id: int(id),
+		UserId: notification.UserId, Kind: notification.Kind, Message: notification.Message,
+		CreatedAt: now, UpdatedAt: now})
+	return int(id), err
+}
+
+func (repo MongoNotificationRepo) FindByUser(userId int, page usecases.Page) ([]usecases.Notification, error) {
+	after, err := page.After.Time()
+	if err != nil {
+		return nil, err
+	}
+	filter := Document{"user_id": userId}
+	options := pageDocuments(filter, page, "created_at", after, true)
+	var documents []notificationDocument
+	err = repo.docHandler.Find("notifications", filter, options, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var notifications []usecases.Notification
+	for _, document := range documents {
+		notifications = append(notifications, usecases.Notification{Id: document.Id,
+			UserId: userId, Kind: document.Kind, Message: document.Message, Read: document.Read,
+			CreatedAt: document.CreatedAt})
+	}
+	return notifications, nil
+}
+
+func (repo MongoNotificationRepo) CountUnread(userId int) (int, error) {
+	count, err := repo.docHandler.Count("notifications", Document{"user_id": userId, "read": false})
+	return int(count), err
+}
+
+func (repo MongoNotificationRepo) MarkRead(userId int, ids []int) error {
+	_, err := repo.docHandler.Update("notifications",
+		Document{"user_id": userId, "_id": Document{"$in": ids}},
+		Document{"$set": Document{"read": true, "updated_at": time.Now().UTC()}})
+	return err
+}
+
+func (repo MongoNotificationRepo) MarkAllRead(userId int) error {
+	_, err := repo.docHandler.Update("notifications", Document{"user_id": userId, "read": false},
+		Document{"$set": Document{"read": true, "updated_at": time.Now().UTC()}})
+	return err
+}
+
+func (repo MongoNotificationRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("notifications", Document{"user_id": userId})
+	return err
+}
@@ -0,0 +1,79 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoChildAccountRepo DocRepo
+
+type childAccountDocument struct {
+	ChildId      int       `bson:"_id"`
+	ParentId     int       `bson:"parent_id"`
+	DailyMinutes int       `bson:"daily_minutes"`
+	RatingCap    int       `bson:"rating_cap"`
+	CreatedAt    time.Time `bson:"created_at"`
+	UpdatedAt    time.Time `bson:"updated_at"`
+}
+
+func NewMongoChildAccountRepo(docHandlers map[string]DocumentHandler) *MongoChildAccountRepo {
+	mongoChildAccountRepo := new(MongoChildAccountRepo)
+	mongoChildAccountRepo.docHandlers = docHandlers
+	mongoChildAccountRepo.docHandler = docHandlers["MongoChildAccountRepo"]
+	return mongoChildAccountRepo
+}
+
+func (document childAccountDocument) account() usecases.ChildAccount {
+	return usecases.ChildAccount{ParentId: document.ParentId, ChildId: document.ChildId,
+		DailyMinutes: document.DailyMinutes, RatingCap: document.RatingCap,
+		CreatedAt: document.CreatedAt, UpdatedAt: document.UpdatedAt}
+}
+
+// The parent is kept when the limits of a linked child are replaced
+func (repo MongoChildAccountRepo) Store(account usecases.ChildAccount) error {
+	now := time.Now().UTC()
+	updated, err := repo.docHandler.Update("child_accounts", Document{"_id": account.ChildId},
+		Document{"$set": Document{"daily_minutes": account.DailyMinutes,
+			"rating_cap": account.RatingCap, "updated_at": now}})
+	if err != nil || updated > 0 {
+		return err
+	}
+	return repo.docHandler.Insert("child_accounts", childAccountDocument{ChildId: account.ChildId,
+		ParentId: account.ParentId, DailyMinutes: account.DailyMinutes,
+		RatingCap: account.RatingCap, CreatedAt: now, UpdatedAt: now})
+}
+
+func (repo MongoChildAccountRepo) FindByChild(childId int) (usecases.ChildAccount, bool, error) {
+	var document childAccountDocument
+	found, err := repo.docHandler.FindOne("child_accounts", Document{"_id": childId}, &document)
+	if err != nil || !found {
+		return usecases.ChildAccount{}, false, err
+	}
+	return document.account(), true, nil
+}
+
+func (repo MongoChildAccountRepo) FindByParent(parentId int) ([]usecases.ChildAccount, error) {
+	var documents []childAccountDocument
+	err := repo.docHandler.Find("child_accounts", Document{"parent_id": parentId},
+		FindOptions{Sort: []string{"created_at", "_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var accounts []usecases.ChildAccount
+	for _, document := range documents {
+		accounts = append(accounts, document.account())
+	}
+	return accounts, nil
+}
+
+func (repo MongoChildAccountRepo) Remove(childId int) error {
+	_, err := repo.docHandler.Delete("child_accounts", Document{"_id": childId})
+	return err
+}
+
+func (repo MongoChildAccountRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("child_accounts", Document{"$or": []Document{
+		{"_id": userId}, {"parent_id": userId}}})
+	return err
+}
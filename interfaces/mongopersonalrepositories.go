@@ -0,0 +1,210 @@
+package interfaces
+
+import (
+	"fmt"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoPersonalMetadataRepo DocRepo
+
+type personalMetadataDocument struct {
+	Id            string    `bson:"_id"` //user id:game id
+	UserId        int       `bson:"user_id"`
+	GameId        int       `bson:"game_id"`
+	Difficulty    int       `bson:"difficulty"`
+	Replayability int       `bson:"replayability"`
+	Moods         []string  `bson:"moods"`
+	UpdatedAt     time.Time `bson:"updated_at"`
+}
+
+func NewMongoPersonalMetadataRepo(docHandlers map[string]DocumentHandler) *MongoPersonalMetadataRepo {
+	mongoPersonalMetadataRepo := new(MongoPersonalMetadataRepo)
+	mongoPersonalMetadataRepo.docHandlers = docHandlers
+	mongoPersonalMetadataRepo.docHandler = docHandlers["MongoPersonalMetadataRepo"]
+	return mongoPersonalMetadataRepo
+}
+
+func personalMetadataId(userId, gameId int) string {
+	return fmt.Sprintf("%d:%d", userId, gameId)
+}
+
+func (document personalMetadataDocument) metadata() usecases.PersonalMetadata {
+	moods := document.Moods
+	if moods == nil {
+		moods = []string{}
+	}
+	return usecases.PersonalMetadata{UserId: document.UserId, GameId: document.GameId,
+		Difficulty: document.Difficulty, Replayability: document.Replayability, Moods: moods,
+		UpdatedAt: document.UpdatedAt}
+}
+
+func (repo MongoPersonalMetadataRepo) Store(metadata usecases.PersonalMetadata) error {
+	id := personalMetadataId(metadata.UserId, metadata.GameId)
+	return repo.docHandler.Upsert("personal_metadata", Document{"_id": id}, personalMetadataDocument{
+		Id: id, UserId: metadata.UserId, GameId: metadata.GameId, Difficulty: metadata.Difficulty,
+		Replayability: metadata.Replayability, Moods: metadata.Moods, UpdatedAt: time.Now().UTC()})
+}
+
+func (repo MongoPersonalMetadataRepo) Find(userId, gameId int) (usecases.PersonalMetadata, bool, error) {
+	var document personalMetadataDocument
+	found, err := repo.docHandler.FindOne("personal_metadata",
+		Document{"_id": personalMetadataId(userId, gameId)}, &document)
+	if err != nil || !found {
+		return usecases.PersonalMetadata{}, false, err
+	}
+	return document.metadata(), true, nil
+}
+
+func (repo MongoPersonalMetadataRepo) Remove(userId, gameId int) (bool, error) {
+	removed, err := repo.docHandler.Delete("personal_metadata",
+		Document{"_id": personalMetadataId(userId, gameId)})
+	return removed > 0, err
+}
+
+func (repo MongoPersonalMetadataRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("personal_metadata", Document{"user_id": userId})
+	return err
+}
+
+// Library documents embed their games without the rating, which is read
+// from the games collection
+func (repo MongoPersonalMetadataRepo) FindCandidates(userId int, statuses []string) ([]usecases.Candidate, error) {
+	var libraries []libraryDocument
+	err := repo.docHandler.Find("libraries", Document{"user_id": userId}, FindOptions{}, &libraries)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool)
+	for _, status := range statuses {
+		wanted[status] = true
+	}
+	held := make(map[int]bool)
+	var entries []libraryGameDocument
+	for _, library := range libraries {
+		for _, game := range library.Games {
+			if wanted[game.Status] {
+				held[game.GameId] = true
+				entries = append(entries, game)
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	var games []gameDocument
+	err = repo.docHandler.Find("games", Document{"_id": Document{"$in": ownedIds(held)}},
+		FindOptions{}, &games)
+	if err != nil {
+		return nil, err
+	}
+	ratings := make(map[int]gameDocument)
+	for _, game := range games {
+		ratings[game.Id] = game
+	}
+	var documents []personalMetadataDocument
+	err = repo.docHandler.Find("personal_metadata", Document{"user_id": userId,
+		"game_id": Document{"$in": ownedIds(held)}}, FindOptions{}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	metadata := make(map[int]usecases.PersonalMetadata)
+	for _, document := range documents {
+		metadata[document.GameId] = document.metadata()
+	}
+
+	var candidates []usecases.Candidate
+	for _, entry := range entries {
+		candidate := usecases.Candidate{Game: usecases.Game{Id: entry.GameId,
+			ExternalId: entry.ExternalId, Name: entry.Name, Producer: entry.Producer,
+			Value: entry.Value, MinAge: ratings[entry.GameId].MinAge,
+			Rating: ratings[entry.GameId].Rating, Status: entry.Status, Platform: entry.Platform},
+			AddedAt: entry.AddedAt}
+		personal, found := metadata[entry.GameId]
+		if !found {
+			personal = usecases.PersonalMetadata{UserId: userId, GameId: entry.GameId,
+				Moods: []string{}}
+		}
+		candidate.Metadata = personal
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+// Completion time only counts the sessions of users who marked the game
+// completed, ratings only the replayability other users set
+func (repo MongoPersonalMetadataRepo) FindGameStats(userId int, gameIds []int) ([]usecases.GameStats, error) {
+	var libraries []libraryDocument
+	err := repo.docHandler.Find("libraries", Document{"games": Document{"$elemMatch": Document{
+		"game_id": Document{"$in": gameIds}, "status": "completed"}}}, FindOptions{}, &libraries)
+	if err != nil {
+		return nil, err
+	}
+	// Keyed by user id and game id
+	completed := make(map[[2]int]bool)
+	for _, library := range libraries {
+		for _, game := range library.Games {
+			if game.Status == "completed" {
+				completed[[2]int{library.UserId, game.GameId}] = true
+			}
+		}
+	}
+	var sessions []playSessionDocument
+	err = repo.docHandler.Find("play_sessions", Document{"game_id": Document{"$in": gameIds}},
+		FindOptions{}, &sessions)
+	if err != nil {
+		return nil, err
+	}
+	var months []sessionMonthDocument
+	err = repo.docHandler.Find("session_months", Document{"game_id": Document{"$in": gameIds}},
+		FindOptions{}, &months)
+	if err != nil {
+		return nil, err
+	}
+	played := make(map[[2]int]int)
+	for _, session := range sessions {
+		key := [2]int{session.UserId, session.GameId}
+		if completed[key] {
+			played[key] += session.Minutes
+		}
+	}
+	for _, month := range months {
+		key := [2]int{month.UserId, month.GameId}
+		if completed[key] {
+			played[key] += month.Minutes
+		}
+	}
+	minutes, players := make(map[int]int), make(map[int]int)
+	for key, total := range played {
+		minutes[key[1]] += total
+		players[key[1]]++
+	}
+	var documents []personalMetadataDocument
+	err = repo.docHandler.Find("personal_metadata", Document{"game_id": Document{"$in": gameIds},
+		"user_id": Document{"$ne": userId}, "replayability": Document{"$gt": 0}}, FindOptions{},
+		&documents)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]usecases.GameStats, len(gameIds))
+	for i, gameId := range gameIds {
+		stats[i].GameId = gameId
+		if count := players[gameId]; count > 0 {
+			stats[i].CompletionMinutes = (minutes[gameId] + count/2) / count
+		}
+		sum := 0
+		for _, document := range documents {
+			if document.GameId == gameId {
+				sum += document.Replayability
+				stats[i].Raters++
+			}
+		}
+		if stats[i].Raters > 0 {
+			stats[i].Rating = float64(sum) / float64(stats[i].Raters)
+		}
+	}
+	return stats, nil
+}
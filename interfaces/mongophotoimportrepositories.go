@@ -0,0 +1,165 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoPhotoImportRepo DocRepo
+
+type photoImportDocument struct {
+	Id        int                     `bson:"_id"`
+	UserId    int                     `bson:"user_id"`
+	LibraryId int                     `bson:"library_id"`
+	Status    string                  `bson:"status"`
+	Reason    string                  `bson:"reason"`
+	Photos    []importPhotoDocument   `bson:"photos"`
+	Proposals []photoProposalDocument `bson:"proposals"`
+	CreatedAt time.Time               `bson:"created_at"`
+	UpdatedAt time.Time               `bson:"updated_at"`
+}
+
+type importPhotoDocument struct {
+	Key         string `bson:"key"`
+	ContentType string `bson:"content_type"`
+}
+
+type photoProposalDocument struct {
+	Id         int     `bson:"id"`
+	Photo      int     `bson:"photo"`
+	Title      string  `bson:"title"`
+	Confidence float64 `bson:"confidence"`
+	Matched    bool    `bson:"matched"`
+	Reason     string  `bson:"reason"`
+	Accepted   bool    `bson:"accepted"`
+	GameId     int     `bson:"game_id"`
+}
+
+func NewMongoPhotoImportRepo(docHandlers map[string]DocumentHandler) *MongoPhotoImportRepo {
+	mongoPhotoImportRepo := new(MongoPhotoImportRepo)
+	mongoPhotoImportRepo.docHandlers = docHandlers
+	mongoPhotoImportRepo.docHandler = docHandlers["MongoPhotoImportRepo"]
+	return mongoPhotoImportRepo
+}
+
+func (document photoImportDocument) photoImport() usecases.PhotoImport {
+	photoImport := usecases.PhotoImport{Id: document.Id, UserId: document.UserId,
+		LibraryId: document.LibraryId, Status: document.Status, Reason: document.Reason,
+		CreatedAt: document.CreatedAt, UpdatedAt: document.UpdatedAt}
+	for _, photo := range document.Photos {
+		photoImport.Photos = append(photoImport.Photos, usecases.ImportPhoto{Key: photo.Key,
+			ContentType: photo.ContentType})
+	}
+	for _, proposal := range document.Proposals {
+		photoImport.Proposals = append(photoImport.Proposals, usecases.PhotoProposal{Id: proposal.Id,
+			Photo: proposal.Photo, Title: proposal.Title, Confidence: proposal.Confidence,
+			Matched: proposal.Matched, Reason: proposal.Reason, Accepted: proposal.Accepted,
+			GameId: proposal.GameId})
+	}
+	return photoImport
+}
+
+func (repo MongoPhotoImportRepo) Store(photoImport usecases.PhotoImport) (int, error) {
+	id, err := repo.docHandler.NextSequence("photo_imports")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	document := photoImportDocument{Id: int(id), UserId: photoImport.UserId,
+		LibraryId: photoImport.LibraryId, Status: photoImport.Status, Photos: []importPhotoDocument{},
+		Proposals: []photoProposalDocument{}, CreatedAt: now, UpdatedAt: now}
+	for _, photo := range photoImport.Photos {
+		document.Photos = append(document.Photos, importPhotoDocument{Key: photo.Key,
+			ContentType: photo.ContentType})
+	}
+	err = repo.docHandler.Insert("photo_imports", document)
+	return int(id), err
+}
+
+func (repo MongoPhotoImportRepo) FindById(id int) (usecases.PhotoImport, error, int) {
+	var document photoImportDocument
+	found, err := repo.docHandler.FindOne("photo_imports", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.PhotoImport{}, err, 500
+	}
+	if !found {
+		return usecases.PhotoImport{}, domain.NewError(domain.CodeNotFound,
+			"Photo import #%d does not exist", id), 404
+	}
+	return document.photoImport(), nil, 200
+}
+
+func (repo MongoPhotoImportRepo) FindPending(limit int) ([]usecases.PhotoImport, error) {
+	var documents []photoImportDocument
+	err := repo.docHandler.Find("photo_imports", Document{"status": usecases.PhotoImportPending},
+		FindOptions{Sort: []string{"created_at", "_id"}, Limit: limit}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var imports []usecases.PhotoImport
+	for _, document := range documents {
+		imports = append(imports, document.photoImport())
+	}
+	return imports, nil
+}
+
+func (repo MongoPhotoImportRepo) StoreProposals(importId int, proposals []usecases.PhotoProposal) error {
+	documents := []photoProposalDocument{}
+	for _, proposal := range proposals {
+		documents = append(documents, photoProposalDocument{Id: proposal.Id, Photo: proposal.Photo,
+			Title: proposal.Title, Confidence: proposal.Confidence, Matched: proposal.Matched,
+			Reason: proposal.Reason})
+	}
+	_, err := repo.docHandler.Update("photo_imports", Document{"_id": importId},
+		Document{"$set": Document{"proposals": documents, "status": usecases.PhotoImportProposed,
+			"reason": "", "updated_at": time.Now().UTC()}})
+	return err
+}
+
+func (repo MongoPhotoImportRepo) Fail(importId int, reason string) error {
+	_, err := repo.docHandler.Update("photo_imports", Document{"_id": importId},
+		Document{"$set": Document{"status": usecases.PhotoImportFailed, "reason": reason,
+			"updated_at": time.Now().UTC()}})
+	return err
+}
+
+// The photos are gone once confirmed, so their keys go as well
+func (repo MongoPhotoImportRepo) Confirm(importId int, accepted map[int]int) error {
+	var document photoImportDocument
+	found, err := repo.docHandler.FindOne("photo_imports", Document{"_id": importId}, &document)
+	if err != nil || !found {
+		return err
+	}
+	for i, proposal := range document.Proposals {
+		if gameId, picked := accepted[proposal.Id]; picked {
+			document.Proposals[i].Accepted = true
+			document.Proposals[i].GameId = gameId
+		}
+	}
+	_, err = repo.docHandler.Update("photo_imports", Document{"_id": importId},
+		Document{"$set": Document{"proposals": document.Proposals, "photos": []importPhotoDocument{},
+			"status": usecases.PhotoImportConfirmed, "updated_at": time.Now().UTC()}})
+	return err
+}
+
+func (repo MongoPhotoImportRepo) RemoveFromLib(libraryId int) ([]string, error) {
+	var documents []photoImportDocument
+	err := repo.docHandler.Find("photo_imports", Document{"library_id": libraryId}, FindOptions{},
+		&documents)
+	if err != nil {
+		return nil, err
+	}
+	_, err = repo.docHandler.Delete("photo_imports", Document{"library_id": libraryId})
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, document := range documents {
+		for _, photo := range document.Photos {
+			keys = append(keys, photo.Key)
+		}
+	}
+	return keys, nil
+}
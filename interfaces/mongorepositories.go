@@ -0,0 +1,1124 @@
+package interfaces
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+var ErrDuplicateDocument = errors.New("Document violates a unique index")
+
+type Document map[string]interface{}
+
+type FindOptions struct {
+	Sort  []string //Field names, prefixed with "-" for descending order
+	Skip  int
+	Limit int //0 returns every match
+}
+
+type DocumentHandler interface {
+	Insert(collection string, document interface{}) error
+	FindOne(collection string, filter Document, result interface{}) (bool, error)
+	Find(collection string, filter Document, query FindOptions, results interface{}) error
+	Update(collection string, filter, update Document) (int64, error)
+	FindOneAndUpdate(collection string, filter, update Document, result interface{}) (bool, error)
+	Upsert(collection string, filter Document, document interface{}) error
+	Delete(collection string, filter Document) (int64, error)
+	Count(collection string, filter Document) (int64, error)
+	NextSequence(name string) (int64, error)
+}
+
+type DocRepo struct {
+	docHandlers map[string]DocumentHandler
+	docHandler  DocumentHandler
+}
+
+type MongoPlayerRepo DocRepo
+
+// Users carry their player, the player repo is handed in once at wiring
+// time and shared by every call
+type MongoUserRepo struct {
+	DocRepo
+	players *MongoPlayerRepo
+}
+
+// Libraries carry their owner
+type MongoLibraryRepo struct {
+	DocRepo
+	users usecases.UserRepository
+}
+
+type MongoGameRepo DocRepo
+
+type userDocument struct {
+	Id             int        `bson:"_id"`
+	ExternalId     string     `bson:"external_id"`
+	Name           string     `bson:"user_name"`
+	NameKey        string     `bson:"user_name_key"` //See domain.NameKey
+	PlayerId       int        `bson:"player_id"`
+	PersonalInfo   string     `bson:"personal_info"`
+	Version        int64      `bson:"version"`
+	Role           string     `bson:"role"`
+	Status         string     `bson:"status"`
+	StatusReason   string     `bson:"status_reason"`
+	SuspendedUntil *time.Time `bson:"suspended_until,omitempty"`
+	CreatedAt      time.Time  `bson:"created_at"`
+	UpdatedAt      time.Time  `bson:"updated_at"`
+}
+
+type loginDocument struct {
+	Id       int    `bson:"_id"` //Same as the id of the user it logs in
+	Username string `bson:"username"`
+	Password string `bson:"password"`
+}
+
+type playerDocument struct {
+	Id        int       `bson:"_id"`
+	Name      string    `bson:"player_name"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+// Libraries embed a copy of each game they hold, so loading a library
+// never needs a second round trip
+type libraryDocument struct {
+	Id         int                   `bson:"_id"`
+	ExternalId string                `bson:"external_id"`
+	UserId     int                   `bson:"user_id"`
+	Games      []libraryGameDocument `bson:"games"`
+	Version    int64                 `bson:"version"`
+	CreatedAt  time.Time             `bson:"created_at"`
+	UpdatedAt  time.Time             `bson:"updated_at"`
+}
+
+type libraryGameDocument struct {
+	GameId       int       `bson:"game_id"`
+	ExternalId   string    `bson:"external_id"`
+	Name         string    `bson:"name"`
+	Producer     string    `bson:"producer"`
+	Value        float64   `bson:"value"`
+	Status       string    `bson:"status"`
+	Platform     string    `bson:"platform"`
+	Tags         []string  `bson:"tags"`
+	WishlistRank int       `bson:"wishlist_rank"`
+	Version      int64     `bson:"version"` //Missing on entries stored before versions
+	AddedAt      time.Time `bson:"added_at"`
+	UpdatedAt    time.Time `bson:"updated_at"`
+}
+
+// Entries stored before versions count as version 1, like the rows of the
+// gamesInLib table
+func (entry libraryGameDocument) version() int64 {
+	if entry.Version == 0 {
+		return 1
+	}
+	return entry.Version
+}
+
+type gameDocument struct {
+	Id               int       `bson:"_id"`
+	ExternalId       string    `bson:"external_id"`
+	Name             string    `bson:"name"`
+	Producer         string    `bson:"producer"`
+	Value            float64   `bson:"value"`
+	MinAge           int       `bson:"min_age"`
+	Rating           string    `bson:"rating"`
+	CreatedAt        time.Time `bson:"created_at"`
+	UpdatedAt        time.Time `bson:"updated_at"`
+	ContainsSpoilers bool      `bson:"contains_spoilers"`
+}
+
+func NewMongoUserRepo(docHandlers map[string]DocumentHandler, players *MongoPlayerRepo) *MongoUserRepo {
+	mongoUserRepo := new(MongoUserRepo)
+	mongoUserRepo.docHandlers = docHandlers
+	mongoUserRepo.docHandler = docHandlers["MongoUserRepo"]
+	mongoUserRepo.players = players
+	return mongoUserRepo
+}
+
+func (repo MongoUserRepo) Store(user usecases.User) (int, error) {
+	playerId, err := repo.players.Store(user.Player)
+	if err != nil {
+		return 0, err
+	}
+	id, err := repo.docHandler.NextSequence("users")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	document := userDocument{Id: int(id), ExternalId: newExternalId(), Name: user.Name,
+		NameKey: domain.NameKey(user.Name), PlayerId: playerId, PersonalInfo: user.PersonalInfo,
+		Version: 1, Role: usecases.RoleUser, Status: usecases.StatusActive, CreatedAt: now,
+		UpdatedAt: now}
+	err = repo.docHandler.Insert("users", document)
+	if err == ErrDuplicateDocument {
+		return 0, &usecases.ErrAlreadyExists{Field: "name", Name: user.Name}
+	}
+	if err != nil {
+		return 0, err
+	}
+	err = recordChange(repo.docHandler, document.Id, "user", document.ExternalId, "",
+		usecases.ChangeCreated)
+	if err != nil {
+		return document.Id, err
+	}
+	return document.Id, nil
+}
+
+func (repo MongoUserRepo) Remove(user usecases.User) error {
+	err := recordChange(repo.docHandler, user.Id, "user", user.ExternalId, "",
+		usecases.ChangeDeleted)
+	if err != nil {
+		return err
+	}
+	_, err = repo.docHandler.Delete("users", Document{"_id": user.Id})
+	return err
+}
+
+func (repo MongoUserRepo) FindById(id int) (usecases.User, error, int) {
+	return repo.findUser(Document{"_id": id})
+}
+
+func (repo MongoUserRepo) FindByExternalId(externalId string) (usecases.User, error, int) {
+	return repo.findUser(Document{"external_id": externalId})
+}
+
+func (repo MongoUserRepo) FindByName(name string, ignoreCase bool) (usecases.User, error, int) {
+	if ignoreCase {
+		return repo.findUser(Document{"user_name_key": domain.NameKey(name)})
+	}
+	return repo.findUser(Document{"user_name": name})
+}
+
+func (repo MongoUserRepo) findUser(filter Document) (usecases.User, error, int) {
+	var document userDocument
+	found, err := repo.docHandler.FindOne("users", filter, &document)
+	if err != nil {
+		return usecases.User{}, err, 500
+	}
+	if !found {
+		return usecases.User{}, fmt.Errorf("No user matches %v", filter), 404
+	}
+
+	player, err, code := repo.players.FindById(document.PlayerId)
+	if err != nil {
+		return usecases.User{}, err, code
+	}
+
+	user := document.user()
+	user.Player = player
+
+	var libraries []libraryDocument
+	err = repo.docHandler.Find("libraries", Document{"user_id": document.Id},
+		FindOptions{Sort: []string{"_id"}}, &libraries)
+	if err != nil {
+		return user, err, 500
+	}
+	for _, library := range libraries {
+		user.LibraryIds = append(user.LibraryIds, library.Id)
+		user.LibraryExternalIds = append(user.LibraryExternalIds, library.ExternalId)
+	}
+	return user, nil, 200
+}
+
+// Users stored before roles and statuses existed read as active users
+func (document userDocument) user() usecases.User {
+	user := usecases.User{Id: document.Id, ExternalId: document.ExternalId, Name: document.Name,
+		Player: domain.Player{Id: document.PlayerId}, PersonalInfo: document.PersonalInfo,
+		Version: document.Version, Role: document.Role, Status: document.Status,
+		StatusReason: document.StatusReason, SuspendedUntil: document.SuspendedUntil,
+		CreatedAt: document.CreatedAt, UpdatedAt: document.UpdatedAt}
+	if user.Role == "" {
+		user.Role = usecases.RoleUser
+	}
+	if user.Status == "" {
+		user.Status = usecases.StatusActive
+	}
+	return user
+}
+
+func (repo MongoUserRepo) UserExisted(userName string) (bool, error) {
+	count, err := repo.docHandler.Count("users", Document{"user_name_key": domain.NameKey(userName)})
+	return count > 0, err
+}
+
+func (repo MongoUserRepo) StoreInfo(user usecases.User, info string, baseVersion int64) (int64, bool, error) {
+	filter := Document{"_id": user.Id}
+	if baseVersion != 0 {
+		filter["version"] = baseVersion
+	}
+	var document userDocument
+	applied, err := repo.docHandler.FindOneAndUpdate("users", filter, Document{
+		"$set": Document{"personal_info": info, "updated_at": time.Now().UTC()},
+		"$inc": Document{"version": 1},
+	}, &document)
+	if err != nil || !applied {
+		return 0, applied, err
+	}
+	err = recordChange(repo.docHandler, user.Id, "user", document.ExternalId, "",
+		usecases.ChangeUpdated)
+	return document.Version, true, err
+}
+
+func (repo MongoUserRepo) Rename(user usecases.User, name string) error {
+	var document userDocument
+	_, err := repo.docHandler.FindOneAndUpdate("users", Document{"_id": user.Id}, Document{
+		"$set": Document{"user_name": name, "user_name_key": domain.NameKey(name),
+			"updated_at": time.Now().UTC()},
+	}, &document)
+	if err == ErrDuplicateDocument {
+		return &usecases.ErrAlreadyExists{Field: "name", Name: name}
+	}
+	if err != nil {
+		return err
+	}
+	_, err = repo.docHandler.Update("logins", Document{"_id": user.Id},
+		Document{"$set": Document{"username": name}})
+	if err != nil {
+		return err
+	}
+	return recordChange(repo.docHandler, user.Id, "user", document.ExternalId, "",
+		usecases.ChangeUpdated)
+}
+
+func (repo MongoUserRepo) LoadInfo(user usecases.User) (string, error) {
+	var document userDocument
+	found, err := repo.docHandler.FindOne("users", Document{"_id": user.Id}, &document)
+	if err == nil && !found {
+		err = fmt.Errorf("User #%d does not exist", user.Id)
+	}
+	return document.PersonalInfo, err
+}
+
+func (repo MongoUserRepo) AddLoginInfo(username, password string) error {
+	var user userDocument
+	found, err := repo.docHandler.FindOne("users", Document{"user_name": username}, &user)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("User '%s' does not exist", username)
+	}
+	return repo.docHandler.Insert("logins", loginDocument{Id: user.Id, Username: username,
+		Password: password})
+}
+
+func (repo MongoUserRepo) CheckLogin(username, password string) (bool, error) {
+	count, err := repo.docHandler.Count("logins",
+		Document{"username": username, "password": password})
+	return count > 0, err
+}
+
+func (repo MongoUserRepo) RemoveLoginInfo(user usecases.User) error {
+	_, err := repo.docHandler.Delete("logins", Document{"username": user.Name})
+	return err
+}
+
+func NewMongoPlayerRepo(docHandlers map[string]DocumentHandler) *MongoPlayerRepo {
+	mongoPlayerRepo := new(MongoPlayerRepo)
+	mongoPlayerRepo.docHandlers = docHandlers
+	mongoPlayerRepo.docHandler = docHandlers["MongoPlayerRepo"]
+	return mongoPlayerRepo
+}
+
+// The unique index on player_name settles races, the loser of an insert
+// race reads back the winner's id
+func (repo MongoPlayerRepo) Store(player domain.Player) (int, error) {
+	var existing playerDocument
+	found, err := repo.docHandler.FindOne("players", Document{"player_name": player.Name}, &existing)
+	if err != nil || found {
+		return existing.Id, err
+	}
+	id, err := repo.docHandler.NextSequence("players")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	err = repo.docHandler.Insert("players", playerDocument{Id: int(id), Name: player.Name,
+		CreatedAt: now, UpdatedAt: now})
+	if err == ErrDuplicateDocument {
+		_, err = repo.docHandler.FindOne("players", Document{"player_name": player.Name}, &existing)
+		return existing.Id, err
+	}
+	return int(id), err
+}
+
+func (repo MongoPlayerRepo) FindById(id int) (domain.Player, error, int) {
+	var document playerDocument
+	found, err := repo.docHandler.FindOne("players", Document{"_id": id}, &document)
+	if err != nil {
+		return domain.Player{}, err, 500
+	}
+	if !found {
+		return domain.Player{}, fmt.Errorf("Player #%d does not exist", id), 404
+	}
+	return domain.Player{Id: id, Name: document.Name, CreatedAt: document.CreatedAt,
+		UpdatedAt: document.UpdatedAt}, nil, 200
+}
+
+func (repo MongoPlayerRepo) FindByName(name string, ignoreCase bool) (domain.Player, error, int) {
+	var document playerDocument
+	found, err := repo.docHandler.FindOne("players",
+		Document{"player_name": nameFilter(name, ignoreCase)}, &document)
+	if err != nil {
+		return domain.Player{}, err, 500
+	}
+	if !found {
+		return domain.Player{}, fmt.Errorf("Player '%s' does not exist", name), 404
+	}
+	return domain.Player{Id: document.Id, Name: document.Name, CreatedAt: document.CreatedAt,
+		UpdatedAt: document.UpdatedAt}, nil, 200
+}
+
+// Matches the whole name, without case through an anchored regex
+func nameFilter(name string, ignoreCase bool) interface{} {
+	if !ignoreCase {
+		return name
+	}
+	return Document{"$regex": "^" + regexp.QuoteMeta(name) + "$", "$options": "i"}
+}
+
+func NewMongoLibraryRepo(docHandlers map[string]DocumentHandler, users usecases.UserRepository) *MongoLibraryRepo {
+	mongoLibraryRepo := new(MongoLibraryRepo)
+	mongoLibraryRepo.docHandlers = docHandlers
+	mongoLibraryRepo.docHandler = docHandlers["MongoLibraryRepo"]
+	mongoLibraryRepo.users = users
+	return mongoLibraryRepo
+}
+
+func (repo MongoLibraryRepo) Store(library usecases.Library) (int, error) {
+	id, err := repo.docHandler.NextSequence("libraries")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	document := libraryDocument{Id: int(id), ExternalId: newExternalId(), UserId: library.User.Id,
+		Games: []libraryGameDocument{}, Version: 1, CreatedAt: now, UpdatedAt: now}
+	err = repo.docHandler.Insert("libraries", document)
+	if err != nil {
+		return 0, err
+	}
+	return document.Id, recordChange(repo.docHandler, document.UserId, "library",
+		document.ExternalId, "", usecases.ChangeCreated)
+}
+
+func (repo MongoLibraryRepo) Remove(library usecases.Library) error {
+	err := recordChange(repo.docHandler, library.User.Id, "library", library.ExternalId, "",
+		usecases.ChangeDeleted)
+	if err != nil {
+		return err
+	}
+	_, err = repo.docHandler.Delete("libraries", Document{"_id": library.Id})
+	return err
+}
+
+func (repo MongoLibraryRepo) FindById(id int) (usecases.Library, error, int) {
+	return repo.findLibrary(Document{"_id": id})
+}
+
+func (repo MongoLibraryRepo) FindByExternalId(externalId string) (usecases.Library, error, int) {
+	return repo.findLibrary(Document{"external_id": externalId})
+}
+
+func (repo MongoLibraryRepo) findLibrary(filter Document) (usecases.Library, error, int) {
+	var document libraryDocument
+	found, err := repo.docHandler.FindOne("libraries", filter, &document)
+	if err != nil {
+		return usecases.Library{}, err, 500
+	}
+	if !found {
+		return usecases.Library{}, fmt.Errorf("No library matches %v", filter), 404
+	}
+	user, err, code := repo.users.FindById(document.UserId)
+	if err != nil {
+		return usecases.Library{}, err, code
+	}
+
+	library := usecases.Library{Id: document.Id, ExternalId: document.ExternalId, User: user,
+		Version: document.Version, CreatedAt: document.CreatedAt, UpdatedAt: document.UpdatedAt}
+	for _, game := range document.Games {
+		library.GameIds = append(library.GameIds, game.GameId)
+		library.GameExternalIds = append(library.GameExternalIds, game.ExternalId)
+	}
+	return library, nil, 200
+}
+
+func (repo MongoLibraryRepo) FindVersionsByUser(userId int) ([]usecases.Library, error) {
+	var documents []libraryDocument
+	err := repo.docHandler.Find("libraries", Document{"user_id": userId},
+		FindOptions{Sort: []string{"_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var libraries []usecases.Library
+	for _, document := range documents {
+		libraries = append(libraries, usecases.Library{Id: document.Id,
+			ExternalId: document.ExternalId, Version: document.Version,
+			UpdatedAt: document.UpdatedAt})
+	}
+	return libraries, nil
+}
+
+func NewMongoGameRepo(docHandlers map[string]DocumentHandler) *MongoGameRepo {
+	mongoGameRepo := new(MongoGameRepo)
+	mongoGameRepo.docHandlers = docHandlers
+	mongoGameRepo.docHandler = docHandlers["MongoGameRepo"]
+	return mongoGameRepo
+}
+
+func (repo MongoGameRepo) Store(game usecases.Game) (int, error) {
+	var existing gameDocument
+	found, err := repo.docHandler.FindOne("games", Document{"name": game.Name}, &existing)
+	if err != nil || found {
+		return existing.Id, err
+	}
+	id, err := repo.docHandler.NextSequence("games")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	err = repo.docHandler.Insert("games", gameDocument{Id: int(id), ExternalId: newExternalId(),
+		Name: game.Name, Producer: game.Producer, Value: game.Value, MinAge: game.MinAge,
+		Rating: game.Rating, CreatedAt: now, UpdatedAt: now})
+	return int(id), err
+}
+
+func (repo MongoGameRepo) AddToLib(gameId, libraryId int, baseVersion int64) (error, int) {
+	var game gameDocument
+	found, err := repo.docHandler.FindOne("games", Document{"_id": gameId}, &game)
+	if err != nil {
+		return err, 500
+	}
+	if !found {
+		return fmt.Errorf("Game #%d does not exist", gameId), 404
+	}
+
+	now := time.Now().UTC()
+	filter := Document{"_id": libraryId, "games.game_id": Document{"$ne": gameId}}
+	if baseVersion != 0 {
+		filter["version"] = baseVersion
+	}
+	var library libraryDocument
+	added, err := repo.docHandler.FindOneAndUpdate("libraries", filter,
+		Document{
+			"$push": Document{"games": libraryGameDocument{GameId: game.Id,
+				ExternalId: game.ExternalId, Name: game.Name, Producer: game.Producer,
+				Value: game.Value, Status: usecases.DefaultGameStatus, Tags: []string{}, Version: 1,
+				AddedAt: now, UpdatedAt: now}},
+			"$inc": Document{"version": 1},
+			"$set": Document{"updated_at": now},
+		}, &library)
+	if err != nil {
+		return err, 500
+	}
+	if !added {
+		existed, err := repo.docHandler.FindOne("libraries",
+			Document{"_id": libraryId, "games.game_id": gameId}, &library)
+		if err != nil {
+			return err, 500
+		}
+		if baseVersion != 0 && !existed {
+			return staleLibraryError(libraryId, baseVersion), 409
+		}
+		err = fmt.Errorf("Game already existed in library")
+		return err, 400
+	}
+	err = recordChange(repo.docHandler, library.UserId, "game", game.ExternalId,
+		library.ExternalId, usecases.ChangeCreated)
+	if err != nil {
+		return err, 500
+	}
+	return nil, 200
+}
+
+// Documents have no bulk path here, the names already stored are looked up
+// at once and the rest inserted one by one
+func (repo MongoGameRepo) StoreBatch(games []usecases.Game) ([]usecases.Game, error) {
+	var names []string
+	for _, game := range games {
+		names = append(names, game.Name)
+	}
+	var documents []gameDocument
+	err := repo.docHandler.Find("games", Document{"name": Document{"$in": names}},
+		FindOptions{Sort: []string{"_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]usecases.Game)
+	for _, document := range documents {
+		if _, known := byName[document.Name]; !known {
+			byName[document.Name] = document.game()
+		}
+	}
+
+	stored := make([]usecases.Game, len(games))
+	for i, game := range games {
+		existing, known := byName[game.Name]
+		if !known {
+			id, err := repo.docHandler.NextSequence("games")
+			if err != nil {
+				return nil, err
+			}
+			now := time.Now().UTC()
+			document := gameDocument{Id: int(id), ExternalId: newExternalId(), Name: game.Name,
+				Producer: game.Producer, Value: game.Value, MinAge: game.MinAge, Rating: game.Rating,
+				CreatedAt: now, UpdatedAt: now}
+			err = repo.docHandler.Insert("games", document)
+			if err != nil {
+				return nil, err
+			}
+			existing = document.game()
+			byName[game.Name] = existing
+		}
+		stored[i] = existing
+	}
+	return stored, nil
+}
+
+// All entries are pushed in one update of the library
+func (repo MongoGameRepo) AddBatchToLib(libraryId int, entries []usecases.Game) ([]int, error) {
+	var library libraryDocument
+	found, err := repo.docHandler.FindOne("libraries", Document{"_id": libraryId}, &library)
+	if err != nil || !found {
+		return nil, err
+	}
+	present := make(map[int]bool)
+	for _, game := range library.Games {
+		present[game.GameId] = true
+	}
+	var gameIds []int
+	for _, entry := range entries {
+		gameIds = append(gameIds, entry.Id)
+	}
+	var documents []gameDocument
+	err = repo.docHandler.Find("games", Document{"_id": Document{"$in": gameIds}}, FindOptions{},
+		&documents)
+	if err != nil {
+		return nil, err
+	}
+	games := make(map[int]gameDocument)
+	for _, document := range documents {
+		games[document.Id] = document
+	}
+
+	now := time.Now().UTC()
+	var added []int
+	var pushed []libraryGameDocument
+	for _, entry := range entries {
+		game, known := games[entry.Id]
+		if !known || present[entry.Id] {
+			continue
+		}
+		present[entry.Id] = true
+		status := entry.Status
+		if status == "" {
+			status = usecases.DefaultGameStatus
+		}
+		added = append(added, entry.Id)
+		pushed = append(pushed, libraryGameDocument{GameId: game.Id, ExternalId: game.ExternalId,
+			Name: game.Name, Producer: game.Producer, Value: game.Value, Status: status,
+			Platform: entry.Platform, Tags: []string{}, Version: 1, AddedAt: now, UpdatedAt: now})
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+	_, err = repo.docHandler.Update("libraries", Document{"_id": libraryId}, Document{
+		"$push": Document{"games": Document{"$each": pushed}},
+		"$inc":  Document{"version": 1},
+		"$set":  Document{"updated_at": now},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, gameId := range added {
+		err = recordChange(repo.docHandler, library.UserId, "game", games[gameId].ExternalId,
+			library.ExternalId, usecases.ChangeCreated)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return added, nil
+}
+
+// Removing a game that is not in the library changes nothing and is not
+// checked against baseVersion
+func (repo MongoGameRepo) RemoveFromLib(game usecases.Game, libraryId int, baseVersion int64) (error, int) {
+	filter := Document{"_id": libraryId, "games.game_id": game.Id}
+	if baseVersion != 0 {
+		filter["version"] = baseVersion
+	}
+	var library libraryDocument
+	removed, err := repo.docHandler.FindOneAndUpdate("libraries", filter,
+		Document{
+			"$pull": Document{"games": Document{"game_id": game.Id}},
+			"$inc":  Document{"version": 1},
+			"$set":  Document{"updated_at": time.Now().UTC()},
+		}, &library)
+	if err != nil {
+		return err, 500
+	}
+	if !removed && baseVersion != 0 {
+		held, err := repo.docHandler.FindOne("libraries",
+			Document{"_id": libraryId, "games.game_id": game.Id}, &library)
+		if err != nil {
+			return err, 500
+		}
+		if held {
+			return staleLibraryError(libraryId, baseVersion), 409
+		}
+	}
+	if !removed {
+		return nil, 200
+	}
+	err = recordChange(repo.docHandler, library.UserId, "game", game.ExternalId,
+		library.ExternalId, usecases.ChangeDeleted)
+	if err != nil {
+		return err, 500
+	}
+	return nil, 200
+}
+
+func (repo MongoGameRepo) FindInLib(gameId, libraryId int) (usecases.Game, error, int) {
+	game, err, code := repo.FindById(gameId)
+	if err != nil {
+		return game, err, code
+	}
+	var library libraryDocument
+	found, err := repo.docHandler.FindOne("libraries", Document{"_id": libraryId}, &library)
+	if err != nil {
+		return usecases.Game{}, err, 500
+	}
+	for _, entry := range library.Games {
+		if found && entry.GameId == gameId {
+			game.Status, game.Platform, game.Tags = entry.Status, entry.Platform, entry.Tags
+			game.WishlistRank, game.Version = entry.WishlistRank, entry.version()
+			return game, nil, 200
+		}
+	}
+	return usecases.Game{}, fmt.Errorf("Game #%d is not in library #%d", gameId, libraryId), 404
+}
+
+func (repo MongoGameRepo) UpdateBatch(libraryId int, gameIds []int, change usecases.GameChange, baseVersions map[int]int64) (map[int]int64, error) {
+	selected := make(map[int]bool)
+	for _, id := range gameIds {
+		selected[id] = true
+	}
+	versions := make(map[int]int64)
+	err := repo.updateEntries(libraryId, func(entry *libraryGameDocument) bool {
+		if !selected[entry.GameId] {
+			return false
+		}
+		base := baseVersions[entry.GameId]
+		if base != 0 && base != entry.version() {
+			return false
+		}
+		if change.Status != nil {
+			entry.Status = *change.Status
+		}
+		if change.Platform != nil {
+			entry.Platform = *change.Platform
+		}
+		if change.Tags != nil {
+			entry.Tags = *change.Tags
+		}
+		versions[entry.GameId] = entry.version() + 1
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (repo MongoGameRepo) FindTags(libraryIds []int) ([]usecases.TagCount, error) {
+	var libraries []libraryDocument
+	err := repo.docHandler.Find("libraries", Document{"_id": Document{"$in": libraryIds}}, FindOptions{},
+		&libraries)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, library := range libraries {
+		for _, entry := range library.Games {
+			for _, tag := range entry.Tags {
+				counts[tag]++
+			}
+		}
+	}
+	tags := make([]usecases.TagCount, 0, len(counts))
+	for tag, games := range counts {
+		tags = append(tags, usecases.TagCount{Tag: tag, Games: games})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+	return tags, nil
+}
+
+// Each library is a document of its own and updated on its own, a failure
+// leaves the libraries before it renamed
+func (repo MongoGameRepo) ReplaceTags(libraryIds []int, from []string, to string) (map[int]int, error) {
+	var tagged []libraryDocument
+	err := repo.docHandler.Find("libraries", Document{"_id": Document{"$in": libraryIds},
+		"games.tags": Document{"$in": from}}, FindOptions{Sort: []string{"_id"}}, &tagged)
+	if err != nil {
+		return nil, err
+	}
+	changed := make(map[int]int)
+	for _, library := range tagged {
+		libraryId, games := library.Id, 0
+		err := repo.updateEntries(libraryId, func(entry *libraryGameDocument) bool {
+			tags, replaced := replaceTags(entry.Tags, from, to)
+			if replaced {
+				entry.Tags = tags
+				games++
+			}
+			return replaced
+		})
+		if err != nil {
+			return nil, err
+		}
+		if games > 0 {
+			changed[libraryId] = games
+		}
+	}
+	return changed, nil
+}
+
+// The tags with every from tag replaced by to, in the place of the first
+// one. Reports whether anything was replaced.
+func replaceTags(tags []string, from []string, to string) ([]string, bool) {
+	replace := make(map[string]bool)
+	for _, tag := range from {
+		replace[tag] = true
+	}
+	replaced := false
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, tag := range tags {
+		if replace[tag] {
+			tag, replaced = to, true
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+	return result, replaced
+}
+
+func (repo MongoGameRepo) RankWishlist(libraryId int, ranks map[int]int) error {
+	return repo.updateEntries(libraryId, func(entry *libraryGameDocument) bool {
+		rank, ranked := ranks[entry.GameId]
+		entry.WishlistRank = rank
+		return ranked
+	})
+}
+
+// Rewrites the embedded games in a single document update, the version
+// guard makes a concurrent change fail instead of being overwritten. Only
+// the entries change reports as changed are stored.
+// The games array is replaced as a whole, guarded by the version as
+// updateEntries does
+func (repo MongoGameRepo) RestoreEntries(libraryId int, gameIds []int, entries []usecases.LibraryEntry) error {
+	var library libraryDocument
+	found, err := repo.docHandler.FindOne("libraries", Document{"_id": libraryId}, &library)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("Library #%d does not exist", libraryId)
+	}
+	listed := make(map[int]bool)
+	for _, gameId := range gameIds {
+		listed[gameId] = true
+	}
+	restoring := make(map[int]usecases.LibraryEntry)
+	for _, entry := range entries {
+		entry.Tags = append([]string{}, entry.Tags...)
+		restoring[entry.GameId] = entry
+	}
+
+	now := time.Now().UTC()
+	games := []libraryGameDocument{}
+	actions := make(map[string]string) //By external id of the game
+	for _, document := range library.Games {
+		if !listed[document.GameId] {
+			games = append(games, document)
+			continue
+		}
+		entry, restored := restoring[document.GameId]
+		if !restored {
+			actions[document.ExternalId] = usecases.ChangeDeleted
+			continue
+		}
+		delete(restoring, document.GameId)
+		document.Status, document.Platform, document.Tags = entry.Status, entry.Platform, entry.Tags
+		document.WishlistRank, document.UpdatedAt = entry.WishlistRank, now
+		document.Version = document.version() + 1
+		games = append(games, document)
+		actions[document.ExternalId] = usecases.ChangeUpdated
+	}
+	if len(restoring) > 0 {
+		var missing []int
+		for gameId := range restoring {
+			missing = append(missing, gameId)
+		}
+		var documents []gameDocument
+		err = repo.docHandler.Find("games", Document{"_id": Document{"$in": missing}}, FindOptions{},
+			&documents)
+		if err != nil {
+			return err
+		}
+		for _, game := range documents {
+			entry := restoring[game.Id]
+			games = append(games, libraryGameDocument{GameId: game.Id, ExternalId: game.ExternalId,
+				Name: game.Name, Producer: game.Producer, Value: game.Value, Status: entry.Status,
+				Platform: entry.Platform, Tags: entry.Tags, WishlistRank: entry.WishlistRank,
+				Version: 1, AddedAt: now, UpdatedAt: now})
+			actions[game.ExternalId] = usecases.ChangeCreated
+		}
+	}
+
+	var updated libraryDocument
+	applied, err := repo.docHandler.FindOneAndUpdate("libraries",
+		Document{"_id": libraryId, "version": library.Version},
+		Document{
+			"$set": Document{"games": games, "updated_at": now},
+			"$inc": Document{"version": 1},
+		}, &updated)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return fmt.Errorf("Library #%d changed during the update", libraryId)
+	}
+	for externalId, action := range actions {
+		err = recordChange(repo.docHandler, library.UserId, "game", externalId, library.ExternalId,
+			action)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo MongoGameRepo) updateEntries(libraryId int, change func(entry *libraryGameDocument) bool) error {
+	var library libraryDocument
+	found, err := repo.docHandler.FindOne("libraries", Document{"_id": libraryId}, &library)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("Library #%d does not exist", libraryId)
+	}
+
+	now := time.Now().UTC()
+	var changed []libraryGameDocument
+	for i, entry := range library.Games {
+		if !change(&entry) {
+			continue
+		}
+		entry.Version, entry.UpdatedAt = entry.version()+1, now
+		library.Games[i] = entry
+		changed = append(changed, entry)
+	}
+
+	var updated libraryDocument
+	applied, err := repo.docHandler.FindOneAndUpdate("libraries",
+		Document{"_id": libraryId, "version": library.Version},
+		Document{
+			"$set": Document{"games": library.Games, "updated_at": now},
+			"$inc": Document{"version": 1},
+		}, &updated)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return fmt.Errorf("Library #%d changed during the update", libraryId)
+	}
+	for _, entry := range changed {
+		err = recordChange(repo.docHandler, library.UserId, "game", entry.ExternalId,
+			library.ExternalId, usecases.ChangeUpdated)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo MongoGameRepo) FindById(id int) (usecases.Game, error, int) {
+	return repo.findGame(Document{"_id": id})
+}
+
+func (repo MongoGameRepo) FindByExternalId(externalId string) (usecases.Game, error, int) {
+	return repo.findGame(Document{"external_id": externalId})
+}
+
+func (repo MongoGameRepo) findGame(filter Document) (usecases.Game, error, int) {
+	var document gameDocument
+	found, err := repo.docHandler.FindOne("games", filter, &document)
+	if err != nil {
+		return usecases.Game{}, err, 500
+	}
+	if !found {
+		return usecases.Game{}, fmt.Errorf("No game matches %v", filter), 404
+	}
+	return document.game(), nil, 200
+}
+
+func (document gameDocument) game() usecases.Game {
+	return usecases.Game{Id: document.Id, ExternalId: document.ExternalId, Name: document.Name,
+		Producer: document.Producer, Value: document.Value, MinAge: document.MinAge,
+		Rating: document.Rating, CreatedAt: document.CreatedAt, UpdatedAt: document.UpdatedAt,
+		ContainsSpoilers: document.ContainsSpoilers}
+}
+
+func (repo MongoGameRepo) SetSpoilers(gameId int, containsSpoilers bool) error {
+	_, err := repo.docHandler.Update("games", Document{"_id": gameId},
+		Document{"$set": Document{"contains_spoilers": containsSpoilers, "updated_at": time.Now().UTC()}})
+	return err
+}
+
+// Ratings live on the game documents, the library only embeds copies of
+// games taken when they were added. The games are sorted here, so the page
+// is cut from the sorted list.
+func (repo MongoGameRepo) FindByLib(libraryId int, filter usecases.GameFilter, page usecases.Page) ([]usecases.Game, error) {
+	after, err := page.After.Game(filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+	var library libraryDocument
+	found, err := repo.docHandler.FindOne("libraries", Document{"_id": libraryId}, &library)
+	if err != nil || !found {
+		return nil, err
+	}
+	entries := make(map[int]libraryGameDocument)
+	ids := []int{}
+	for _, entry := range library.Games {
+		if !entryMatches(entry, filter) {
+			continue
+		}
+		entries[entry.GameId] = entry
+		ids = append(ids, entry.GameId)
+	}
+	query := Document{"_id": Document{"$in": ids}}
+	if filter.Rating != "" {
+		query["rating"] = filter.Rating
+	}
+	if filter.MaxAge > 0 {
+		query["min_age"] = Document{"$lte": filter.MaxAge}
+	}
+	if filter.Name != "" {
+		query["name"] = Document{"$regex": regexp.QuoteMeta(filter.Name), "$options": "i"}
+	}
+	var documents []gameDocument
+	err = repo.docHandler.Find("games", query, FindOptions{Sort: []string{"name", "_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var games []usecases.Game
+	for _, document := range documents {
+		game := document.game()
+		entry := entries[document.Id]
+		game.Status, game.Platform, game.Tags = entry.Status, entry.Platform, entry.Tags
+		game.WishlistRank, game.Version = entry.WishlistRank, entry.version()
+		game.EntryUpdatedAt = entry.UpdatedAt
+		games = append(games, game)
+	}
+	sortGames(games, filter.Sort)
+	if !page.After.IsZero() {
+		start := sort.Search(len(games), func(i int) bool {
+			return gameBefore(after, games[i], filter.Sort)
+		})
+		games = games[start:]
+	}
+	if page.Limit > 0 && len(games) > page.Limit {
+		games = games[:page.Limit]
+	}
+	return games, nil
+}
+
+func entryMatches(entry libraryGameDocument, filter usecases.GameFilter) bool {
+	if filter.Status != "" && entry.Status != filter.Status {
+		return false
+	}
+	if filter.Platform != "" && entry.Platform != filter.Platform {
+		return false
+	}
+	if filter.Tag == "" {
+		return true
+	}
+	for _, tag := range entry.Tags {
+		if tag == filter.Tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Sorts as Postgres does: by the key order names, ties by name and id.
+// Unranked games sort last both ways.
+func sortGames(games []usecases.Game, order string) {
+	sort.SliceStable(games, func(i, j int) bool {
+		return gameBefore(games[i], games[j], order)
+	})
+}
+
+// Whether a comes before b in a list sorted by order
+func gameBefore(a, b usecases.Game, order string) bool {
+	descending := strings.HasPrefix(order, "-")
+	var less func(a, b usecases.Game) bool
+	switch strings.TrimPrefix(order, "-") {
+	case "value":
+		less = func(a, b usecases.Game) bool { return a.Value < b.Value }
+	case "rating":
+		less = func(a, b usecases.Game) bool { return a.MinAge < b.MinAge }
+	case "status":
+		less = func(a, b usecases.Game) bool { return a.Status < b.Status }
+	case "updated":
+		less = func(a, b usecases.Game) bool { return a.EntryUpdatedAt.Before(b.EntryUpdatedAt) }
+	case "rank":
+		less = func(a, b usecases.Game) bool {
+			if a.WishlistRank == 0 || b.WishlistRank == 0 {
+				// Compared the other way round when descending
+				ranked := a
+				if descending {
+					ranked = b
+				}
+				return ranked.WishlistRank != 0 && a.WishlistRank != b.WishlistRank
+			}
+			return a.WishlistRank < b.WishlistRank
+		}
+	}
+	if less != nil {
+		first, second := a, b
+		if descending {
+			first, second = b, a
+		}
+		if less(first, second) {
+			return true
+		}
+		if less(second, first) {
+			return false
+		}
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.Id < b.Id
+}
+
+// Random version 4 UUID, Postgres generates these itself with gen_random_uuid
+func newExternalId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
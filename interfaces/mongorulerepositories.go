@@ -0,0 +1,161 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoRuleRepo DocRepo
+
+type ruleDocument struct {
+	Id        int       `bson:"_id"`
+	UserId    int       `bson:"user_id"`
+	Name      string    `bson:"name"`
+	Event     string    `bson:"event"`
+	Condition string    `bson:"condition"`
+	Actions   string    `bson:"actions"`
+	Enabled   bool      `bson:"enabled"`
+	DryRun    bool      `bson:"dry_run"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+type ruleRunDocument struct {
+	Id        int       `bson:"_id"`
+	RuleId    int       `bson:"rule_id"`
+	UserId    int       `bson:"user_id"`
+	Event     string    `bson:"event"`
+	EntityId  int       `bson:"entity_id"`
+	Actions   []string  `bson:"actions"`
+	DryRun    bool      `bson:"dry_run"`
+	Error     string    `bson:"error"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func NewMongoRuleRepo(docHandlers map[string]DocumentHandler) *MongoRuleRepo {
+	mongoRuleRepo := new(MongoRuleRepo)
+	mongoRuleRepo.docHandlers = docHandlers
+	mongoRuleRepo.docHandler = docHandlers["MongoRuleRepo"]
+	return mongoRuleRepo
+}
+
+func (document ruleDocument) rule() usecases.Rule {
+	return usecases.Rule{Id: document.Id, UserId: document.UserId, Name: document.Name,
+		Event: document.Event, Condition: document.Condition, Actions: document.Actions,
+		Enabled: document.Enabled, DryRun: document.DryRun, CreatedAt: document.CreatedAt,
+		UpdatedAt: document.UpdatedAt}
+}
+
+func (repo MongoRuleRepo) Store(rule usecases.Rule) (int, error) {
+	id, err := repo.docHandler.NextSequence("rules")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	err = repo.docHandler.Insert("rules", ruleDocument{Id: int(id), UserId: rule.UserId, Name: rule.Name,
+		Event: rule.Event, Condition: rule.Condition, Actions: rule.Actions, Enabled: rule.Enabled,
+		DryRun: rule.DryRun, CreatedAt: now, UpdatedAt: now})
+	return int(id), err
+}
+
+func (repo MongoRuleRepo) Update(rule usecases.Rule) error {
+	_, err := repo.docHandler.Update("rules", Document{"_id": rule.Id}, Document{"$set": Document{
+		"name": rule.Name, "event": rule.Event, "condition": rule.Condition, "actions": rule.Actions,
+		"enabled": rule.Enabled, "dry_run": rule.DryRun, "updated_at": time.Now().UTC()}})
+	return err
+}
+
+func (repo MongoRuleRepo) FindById(id int) (usecases.Rule, error, int) {
+	var document ruleDocument
+	found, err := repo.docHandler.FindOne("rules", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.Rule{}, err, 500
+	}
+	if !found {
+		return usecases.Rule{}, domain.NewError(domain.CodeNotFound, "Rule #%d does not exist", id), 404
+	}
+	return document.rule(), nil, 200
+}
+
+func (repo MongoRuleRepo) FindByUser(userId int) ([]usecases.Rule, error) {
+	return repo.find(Document{"user_id": userId})
+}
+
+func (repo MongoRuleRepo) FindEnabled(userId int, event string) ([]usecases.Rule, error) {
+	return repo.find(Document{"user_id": userId, "event": event, "enabled": true})
+}
+
+func (repo MongoRuleRepo) find(filter Document) ([]usecases.Rule, error) {
+	var documents []ruleDocument
+	err := repo.docHandler.Find("rules", filter, FindOptions{Sort: []string{"created_at", "_id"}},
+		&documents)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]usecases.Rule, len(documents))
+	for i, document := range documents {
+		rules[i] = document.rule()
+	}
+	return rules, nil
+}
+
+func (repo MongoRuleRepo) Remove(rule usecases.Rule) error {
+	_, err := repo.docHandler.Delete("rule_runs", Document{"rule_id": rule.Id})
+	if err != nil {
+		return err
+	}
+	_, err = repo.docHandler.Delete("rules", Document{"_id": rule.Id})
+	return err
+}
+
+func (repo MongoRuleRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("rule_runs", Document{"user_id": userId})
+	if err != nil {
+		return err
+	}
+	_, err = repo.docHandler.Delete("rules", Document{"user_id": userId})
+	return err
+}
+
+func (repo MongoRuleRepo) StoreRun(run usecases.RuleRun, keep int) error {
+	id, err := repo.docHandler.NextSequence("rule_runs")
+	if err != nil {
+		return err
+	}
+	err = repo.docHandler.Insert("rule_runs", ruleRunDocument{Id: int(id), RuleId: run.RuleId,
+		UserId: run.UserId, Event: run.Event, EntityId: run.EntityId, Actions: run.Actions,
+		DryRun: run.DryRun, Error: run.Error, CreatedAt: run.CreatedAt})
+	if err != nil {
+		return err
+	}
+	var older []ruleRunDocument
+	err = repo.docHandler.Find("rule_runs", Document{"rule_id": run.RuleId},
+		FindOptions{Sort: []string{"-_id"}, Skip: keep}, &older)
+	if err != nil || len(older) == 0 {
+		return err
+	}
+	ids := make([]int, len(older))
+	for i, document := range older {
+		ids[i] = document.Id
+	}
+	_, err = repo.docHandler.Delete("rule_runs", Document{"_id": Document{"$in": ids}})
+	return err
+}
+
+func (repo MongoRuleRepo) FindRuns(ruleId int, limit int) ([]usecases.RuleRun, error) {
+	var documents []ruleRunDocument
+	err := repo.docHandler.Find("rule_runs", Document{"rule_id": ruleId},
+		FindOptions{Sort: []string{"-_id"}, Limit: limit}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]usecases.RuleRun, len(documents))
+	for i, document := range documents {
+		runs[i] = usecases.RuleRun{Id: document.Id, RuleId: document.RuleId, UserId: document.UserId,
+			Event: document.Event, EntityId: document.EntityId, Actions: document.Actions,
+			DryRun: document.DryRun, Error: document.Error, CreatedAt: document.CreatedAt}
+	}
+	return runs, nil
+}
@@ -0,0 +1,97 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoScriptRepo DocRepo
+
+type scriptDocument struct {
+	Id        int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	Event     string    `bson:"event"`
+	Source    string    `bson:"source"`
+	UserId    int       `bson:"user_id"`
+	Enabled   bool      `bson:"enabled"`
+	CreatedBy int       `bson:"created_by"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+func NewMongoScriptRepo(docHandlers map[string]DocumentHandler) *MongoScriptRepo {
+	mongoScriptRepo := new(MongoScriptRepo)
+	mongoScriptRepo.docHandlers = docHandlers
+	mongoScriptRepo.docHandler = docHandlers["MongoScriptRepo"]
+	return mongoScriptRepo
+}
+
+func (document scriptDocument) script() usecases.Script {
+	return usecases.Script{Id: document.Id, Name: document.Name, Event: document.Event,
+		Source: document.Source, UserId: document.UserId, Enabled: document.Enabled,
+		CreatedBy: document.CreatedBy, CreatedAt: document.CreatedAt, UpdatedAt: document.UpdatedAt}
+}
+
+func (repo MongoScriptRepo) Store(script usecases.Script) (int, error) {
+	id, err := repo.docHandler.NextSequence("scripts")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	err = repo.docHandler.Insert("scripts", scriptDocument{Id: int(id), Name: script.Name,
+		Event: script.Event, Source: script.Source, UserId: script.UserId, Enabled: script.Enabled,
+		CreatedBy: script.CreatedBy, CreatedAt: now, UpdatedAt: now})
+	return int(id), err
+}
+
+func (repo MongoScriptRepo) Update(script usecases.Script) error {
+	_, err := repo.docHandler.Update("scripts", Document{"_id": script.Id}, Document{"$set": Document{
+		"name": script.Name, "event": script.Event, "source": script.Source, "user_id": script.UserId,
+		"enabled": script.Enabled, "created_by": script.CreatedBy, "updated_at": time.Now().UTC()}})
+	return err
+}
+
+func (repo MongoScriptRepo) FindById(id int) (usecases.Script, error, int) {
+	var document scriptDocument
+	found, err := repo.docHandler.FindOne("scripts", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.Script{}, err, 500
+	}
+	if !found {
+		return usecases.Script{}, domain.NewError(domain.CodeNotFound, "Script #%d does not exist", id), 404
+	}
+	return document.script(), nil, 200
+}
+
+func (repo MongoScriptRepo) FindAll() ([]usecases.Script, error) {
+	return repo.find(Document{})
+}
+
+func (repo MongoScriptRepo) FindEnabled(event string) ([]usecases.Script, error) {
+	return repo.find(Document{"event": event, "enabled": true})
+}
+
+func (repo MongoScriptRepo) find(filter Document) ([]usecases.Script, error) {
+	var documents []scriptDocument
+	err := repo.docHandler.Find("scripts", filter, FindOptions{Sort: []string{"_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	scripts := make([]usecases.Script, len(documents))
+	for i, document := range documents {
+		scripts[i] = document.script()
+	}
+	return scripts, nil
+}
+
+func (repo MongoScriptRepo) Remove(id int) error {
+	_, err := repo.docHandler.Delete("scripts", Document{"_id": id})
+	return err
+}
+
+func (repo MongoScriptRepo) RemoveByUser(userId int) error {
+	_, err := repo.docHandler.Delete("scripts", Document{"user_id": userId})
+	return err
+}
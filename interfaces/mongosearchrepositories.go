@@ -0,0 +1,144 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoSavedSearchRepo DocRepo
+
+type searchFilterDocument struct {
+	Rating   string `bson:"rating"`
+	MaxAge   int    `bson:"max_age"`
+	Name     string `bson:"name"`
+	Status   string `bson:"status"`
+	Platform string `bson:"platform"`
+	Tag      string `bson:"tag"`
+	Sort     string `bson:"sort"`
+}
+
+type savedSearchDocument struct {
+	Id             int                  `bson:"_id"`
+	UserId         int                  `bson:"user_id"`
+	Name           string               `bson:"name"`
+	Filter         searchFilterDocument `bson:"filter"`
+	ShareTokenHash string               `bson:"share_token_hash"`
+	CreatedAt      time.Time            `bson:"created_at"`
+	UpdatedAt      time.Time            `bson:"updated_at"`
+}
+
+type defaultSearchDocument struct {
+	UserId    int `bson:"user_id"`
+	LibraryId int `bson:"library_id"`
+	SearchId  int `bson:"search_id"`
+}
+
+func NewMongoSavedSearchRepo(docHandlers map[string]DocumentHandler) *MongoSavedSearchRepo {
+	mongoSavedSearchRepo := new(MongoSavedSearchRepo)
+	mongoSavedSearchRepo.docHandlers = docHandlers
+	mongoSavedSearchRepo.docHandler = docHandlers["MongoSavedSearchRepo"]
+	return mongoSavedSearchRepo
+}
+
+func (document savedSearchDocument) search() usecases.SavedSearch {
+	return usecases.SavedSearch{Id: document.Id, UserId: document.UserId, Name: document.Name,
+		Filter: usecases.GameFilter(document.Filter), ShareTokenHash: document.ShareTokenHash,
+		CreatedAt: document.CreatedAt, UpdatedAt: document.UpdatedAt}
+}
+
+func (repo MongoSavedSearchRepo) Store(search usecases.SavedSearch) (int, error) {
+	id, err := repo.docHandler.NextSequence("saved_searches")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	err = repo.docHandler.Insert("saved_searches", savedSearchDocument{Id: int(id), UserId: search.UserId,
+		Name: search.Name, Filter: searchFilterDocument(search.Filter), CreatedAt: now, UpdatedAt: now})
+	return int(id), err
+}
+
+func (repo MongoSavedSearchRepo) Update(search usecases.SavedSearch) error {
+	_, err := repo.docHandler.Update("saved_searches", Document{"_id": search.Id}, Document{"$set": Document{
+		"name": search.Name, "filter": searchFilterDocument(search.Filter),
+		"share_token_hash": search.ShareTokenHash, "updated_at": time.Now().UTC()}})
+	return err
+}
+
+func (repo MongoSavedSearchRepo) FindById(id int) (usecases.SavedSearch, error, int) {
+	var document savedSearchDocument
+	found, err := repo.docHandler.FindOne("saved_searches", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.SavedSearch{}, err, 500
+	}
+	if !found {
+		return usecases.SavedSearch{}, domain.NewError(domain.CodeNotFound,
+			"Saved search #%d does not exist", id), 404
+	}
+	return document.search(), nil, 200
+}
+
+func (repo MongoSavedSearchRepo) FindByUser(userId int) ([]usecases.SavedSearch, error) {
+	var documents []savedSearchDocument
+	err := repo.docHandler.Find("saved_searches", Document{"user_id": userId},
+		FindOptions{Sort: []string{"name", "_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	searches := make([]usecases.SavedSearch, len(documents))
+	for i, document := range documents {
+		searches[i] = document.search()
+	}
+	return searches, nil
+}
+
+func (repo MongoSavedSearchRepo) FindByShareToken(tokenHash string) (usecases.SavedSearch, bool, error) {
+	var document savedSearchDocument
+	found, err := repo.docHandler.FindOne("saved_searches", Document{"share_token_hash": tokenHash},
+		&document)
+	if err != nil || !found {
+		return usecases.SavedSearch{}, false, err
+	}
+	return document.search(), true, nil
+}
+
+func (repo MongoSavedSearchRepo) Remove(search usecases.SavedSearch) error {
+	_, err := repo.docHandler.Delete("default_searches", Document{"search_id": search.Id})
+	if err != nil {
+		return err
+	}
+	_, err = repo.docHandler.Delete("saved_searches", Document{"_id": search.Id})
+	return err
+}
+
+func (repo MongoSavedSearchRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("default_searches", Document{"user_id": userId})
+	if err != nil {
+		return err
+	}
+	_, err = repo.docHandler.Delete("saved_searches", Document{"user_id": userId})
+	return err
+}
+
+func (repo MongoSavedSearchRepo) SetDefault(userId, libraryId, searchId int) error {
+	filter := Document{"user_id": userId, "library_id": libraryId}
+	if searchId == 0 {
+		_, err := repo.docHandler.Delete("default_searches", filter)
+		return err
+	}
+	return repo.docHandler.Upsert("default_searches", filter,
+		defaultSearchDocument{UserId: userId, LibraryId: libraryId, SearchId: searchId})
+}
+
+func (repo MongoSavedSearchRepo) FindDefault(userId, libraryId int) (int, error) {
+	var document defaultSearchDocument
+	_, err := repo.docHandler.FindOne("default_searches",
+		Document{"user_id": userId, "library_id": libraryId}, &document)
+	return document.SearchId, err
+}
+
+func (repo MongoSavedSearchRepo) RemoveDefaults(libraryId int) error {
+	_, err := repo.docHandler.Delete("default_searches", Document{"library_id": libraryId})
+	return err
+}
@@ -0,0 +1,72 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoSettingsRepo DocRepo
+
+type settingsDocument struct {
+	UserId           int       `bson:"_id"`
+	DisplayCurrency  string    `bson:"display_currency"`
+	Timezone         string    `bson:"timezone"`
+	NotifyLibraries  bool      `bson:"notify_libraries"`
+	NotifyGames      bool      `bson:"notify_games"`
+	DefaultLibraryId int       `bson:"default_library_id"`
+	ProfilePublic    bool      `bson:"profile_public"`
+	LibrariesPublic  bool      `bson:"libraries_public"`
+	RatingLimit      int       `bson:"rating_limit"`
+	Locale           string    `bson:"locale"`
+	UpdatedAt        time.Time `bson:"updated_at"`
+}
+
+func NewMongoSettingsRepo(docHandlers map[string]DocumentHandler) *MongoSettingsRepo {
+	mongoSettingsRepo := new(MongoSettingsRepo)
+	mongoSettingsRepo.docHandlers = docHandlers
+	mongoSettingsRepo.docHandler = docHandlers["MongoSettingsRepo"]
+	return mongoSettingsRepo
+}
+
+func (repo MongoSettingsRepo) Load(userId int) (usecases.Settings, bool, error) {
+	var document settingsDocument
+	found, err := repo.docHandler.FindOne("settings", Document{"_id": userId}, &document)
+	if err != nil || !found {
+		return usecases.Settings{}, found, err
+	}
+	settings := usecases.Settings{UserId: userId, DisplayCurrency: document.DisplayCurrency,
+		Timezone: document.Timezone, NotifyLibraries: document.NotifyLibraries,
+		NotifyGames: document.NotifyGames, ProfilePublic: document.ProfilePublic,
+		LibrariesPublic: document.LibrariesPublic, RatingLimit: document.RatingLimit,
+		Locale: document.Locale}
+
+	// Mirrors the LEFT JOIN of the SQL repo, a removed library reads as no default
+	if document.DefaultLibraryId != 0 {
+		var library libraryDocument
+		found, err = repo.docHandler.FindOne("libraries", Document{"_id": document.DefaultLibraryId},
+			&library)
+		if err != nil {
+			return usecases.Settings{}, true, err
+		}
+		if found {
+			settings.DefaultLibraryId = library.Id
+			settings.DefaultLibraryExternalId = library.ExternalId
+		}
+	}
+	return settings, true, nil
+}
+
+func (repo MongoSettingsRepo) Store(settings usecases.Settings) error {
+	return repo.docHandler.Upsert("settings", Document{"_id": settings.UserId}, settingsDocument{
+		UserId: settings.UserId, DisplayCurrency: settings.DisplayCurrency,
+		Timezone: settings.Timezone, NotifyLibraries: settings.NotifyLibraries,
+		NotifyGames: settings.NotifyGames, DefaultLibraryId: settings.DefaultLibraryId,
+		ProfilePublic: settings.ProfilePublic, LibrariesPublic: settings.LibrariesPublic,
+		RatingLimit: settings.RatingLimit, Locale: settings.Locale, UpdatedAt: time.Now().UTC()})
+}
+
+func (repo MongoSettingsRepo) Remove(userId int) error {
+	_, err := repo.docHandler.Delete("settings", Document{"_id": userId})
+	return err
+}
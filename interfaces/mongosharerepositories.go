@@ -0,0 +1,86 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoShareLinkRepo DocRepo
+
+type shareLinkDocument struct {
+	Id           int       `bson:"_id"`
+	LibraryId    int       `bson:"library_id"`
+	TokenHash    string    `bson:"token_hash"`
+	PasswordHash string    `bson:"password_hash"`
+	ExpiresAt    time.Time `bson:"expires_at,omitempty"`
+	CreatedAt    time.Time `bson:"created_at"`
+}
+
+func NewMongoShareLinkRepo(docHandlers map[string]DocumentHandler) *MongoShareLinkRepo {
+	mongoShareLinkRepo := new(MongoShareLinkRepo)
+	mongoShareLinkRepo.docHandlers = docHandlers
+	mongoShareLinkRepo.docHandler = docHandlers["MongoShareLinkRepo"]
+	return mongoShareLinkRepo
+}
+
+func (document shareLinkDocument) link() usecases.ShareLink {
+	return usecases.ShareLink{Id: document.Id, LibraryId: document.LibraryId,
+		TokenHash: document.TokenHash, PasswordHash: document.PasswordHash,
+		ExpiresAt: document.ExpiresAt, CreatedAt: document.CreatedAt}
+}
+
+func (repo MongoShareLinkRepo) Store(link usecases.ShareLink) (int, error) {
+	id, err := repo.docHandler.NextSequence("share_links")
+	if err != nil {
+		return 0, err
+	}
+	err = repo.docHandler.Insert("share_links", shareLinkDocument{Id: int(id), LibraryId: link.LibraryId,
+		TokenHash: link.TokenHash, PasswordHash: link.PasswordHash, ExpiresAt: link.ExpiresAt,
+		CreatedAt: time.Now().UTC()})
+	return int(id), err
+}
+
+func (repo MongoShareLinkRepo) FindById(id int) (usecases.ShareLink, error, int) {
+	var document shareLinkDocument
+	found, err := repo.docHandler.FindOne("share_links", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.ShareLink{}, err, 500
+	}
+	if !found {
+		return usecases.ShareLink{}, domain.NewError(domain.CodeNotFound,
+			"Share link #%d does not exist", id), 404
+	}
+	return document.link(), nil, 200
+}
+
+func (repo MongoShareLinkRepo) FindByToken(tokenHash string) (usecases.ShareLink, bool, error) {
+	var document shareLinkDocument
+	found, err := repo.docHandler.FindOne("share_links", Document{"token_hash": tokenHash}, &document)
+	return document.link(), found, err
+}
+
+func (repo MongoShareLinkRepo) FindByLib(libraryId int) ([]usecases.ShareLink, error) {
+	var documents []shareLinkDocument
+	err := repo.docHandler.Find("share_links", Document{"library_id": libraryId},
+		FindOptions{Sort: []string{"-created_at", "-_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var links []usecases.ShareLink
+	for _, document := range documents {
+		links = append(links, document.link())
+	}
+	return links, nil
+}
+
+func (repo MongoShareLinkRepo) Remove(link usecases.ShareLink) error {
+	_, err := repo.docHandler.Delete("share_links", Document{"_id": link.Id})
+	return err
+}
+
+func (repo MongoShareLinkRepo) RemoveFromLib(libraryId int) error {
+	_, err := repo.docHandler.Delete("share_links", Document{"library_id": libraryId})
+	return err
+}
@@ -0,0 +1,114 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoSpeedrunRepo DocRepo
+
+type speedrunDocument struct {
+	Id             int       `bson:"_id"`
+	UserId         int       `bson:"user_id"`
+	GameId         int       `bson:"game_id"`
+	GameExternalId string    `bson:"game_external_id"`
+	GameName       string    `bson:"game_name"`
+	Category       string    `bson:"category"`
+	Milliseconds   int64     `bson:"milliseconds"`
+	RunAt          time.Time `bson:"run_at"`
+	CreatedAt      time.Time `bson:"created_at"`
+}
+
+func NewMongoSpeedrunRepo(docHandlers map[string]DocumentHandler) *MongoSpeedrunRepo {
+	mongoSpeedrunRepo := new(MongoSpeedrunRepo)
+	mongoSpeedrunRepo.docHandlers = docHandlers
+	mongoSpeedrunRepo.docHandler = docHandlers["MongoSpeedrunRepo"]
+	return mongoSpeedrunRepo
+}
+
+func (document speedrunDocument) run() usecases.SpeedRun {
+	return usecases.SpeedRun{Id: document.Id, UserId: document.UserId, GameId: document.GameId,
+		GameExternalId: document.GameExternalId, GameName: document.GameName,
+		Category: document.Category, Milliseconds: document.Milliseconds, RunAt: document.RunAt,
+		CreatedAt: document.CreatedAt}
+}
+
+func (repo MongoSpeedrunRepo) Store(run usecases.SpeedRun) (int, error) {
+	id, err := repo.docHandler.NextSequence("speedruns")
+	if err != nil {
+		return 0, err
+	}
+	err = repo.docHandler.Insert("speedruns", speedrunDocument{Id: int(id), UserId: run.UserId,
+		GameId: run.GameId, GameExternalId: run.GameExternalId, GameName: run.GameName,
+		Category: run.Category, Milliseconds: run.Milliseconds, RunAt: run.RunAt,
+		CreatedAt: time.Now().UTC()})
+	return int(id), err
+}
+
+func (repo MongoSpeedrunRepo) FindById(id int) (usecases.SpeedRun, error, int) {
+	var document speedrunDocument
+	found, err := repo.docHandler.FindOne("speedruns", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.SpeedRun{}, err, 500
+	}
+	if !found {
+		return usecases.SpeedRun{}, domain.NewError(domain.CodeNotFound, "Run #%d does not exist", id), 404
+	}
+	return document.run(), nil, 200
+}
+
+func (repo MongoSpeedrunRepo) FindByGame(userId, gameId int) ([]usecases.SpeedRun, error) {
+	return repo.find(Document{"user_id": userId, "game_id": gameId},
+		[]string{"category", "run_at", "_id"})
+}
+
+// Sorted fastest first, the first run of each user, game and category wins
+func (repo MongoSpeedrunRepo) FindBest(userIds []int, gameId int) ([]usecases.SpeedRun, error) {
+	filter := Document{"user_id": Document{"$in": userIds}}
+	if gameId != 0 {
+		filter["game_id"] = gameId
+	}
+	runs, err := repo.find(filter, []string{"game_id", "category", "milliseconds", "run_at", "_id"})
+	if err != nil {
+		return nil, err
+	}
+	type key struct {
+		userId, gameId int
+		category       string
+	}
+	seen := make(map[key]bool)
+	var best []usecases.SpeedRun
+	for _, run := range runs {
+		if seen[key{run.UserId, run.GameId, run.Category}] {
+			continue
+		}
+		seen[key{run.UserId, run.GameId, run.Category}] = true
+		best = append(best, run)
+	}
+	return best, nil
+}
+
+func (repo MongoSpeedrunRepo) find(filter Document, sort []string) ([]usecases.SpeedRun, error) {
+	var documents []speedrunDocument
+	err := repo.docHandler.Find("speedruns", filter, FindOptions{Sort: sort}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var runs []usecases.SpeedRun
+	for _, document := range documents {
+		runs = append(runs, document.run())
+	}
+	return runs, nil
+}
+
+func (repo MongoSpeedrunRepo) Remove(run usecases.SpeedRun) error {
+	_, err := repo.docHandler.Delete("speedruns", Document{"_id": run.Id})
+	return err
+}
+
+func (repo MongoSpeedrunRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("speedruns", Document{"user_id": userId})
+	return err
+}
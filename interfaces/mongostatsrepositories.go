@@ -0,0 +1,147 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoStatsRepo DocRepo
+
+// Totals of a user as of the last refresh, entries carry the values they
+// were stored with
+type statsDocument struct {
+	UserId      int                    `bson:"_id"`
+	Entries     []statsEntryDocument   `bson:"entries"`
+	Playtime    []statsMinutesDocument `bson:"playtime"`
+	RefreshedAt time.Time              `bson:"refreshed_at"`
+}
+
+type statsEntryDocument struct {
+	Status   string  `bson:"status"`
+	Platform string  `bson:"platform"`
+	Games    int     `bson:"games"`
+	Value    float64 `bson:"value"`
+}
+
+type statsMinutesDocument struct {
+	Platform string `bson:"platform"`
+	Minutes  int    `bson:"minutes"`
+}
+
+func NewMongoStatsRepo(docHandlers map[string]DocumentHandler) *MongoStatsRepo {
+	mongoStatsRepo := new(MongoStatsRepo)
+	mongoStatsRepo.docHandlers = docHandlers
+	mongoStatsRepo.docHandler = docHandlers["MongoStatsRepo"]
+	return mongoStatsRepo
+}
+
+func (repo MongoStatsRepo) FindByUser(userId int) (usecases.LibraryStats, error) {
+	stats := usecases.LibraryStats{UserId: userId, Statuses: make(map[string]int),
+		Platforms: make(map[string]int), PlatformMinutes: make(map[string]int)}
+	libraries, err := repo.docHandler.Count("libraries", Document{"user_id": userId})
+	if err != nil {
+		return usecases.LibraryStats{}, err
+	}
+	stats.Libraries = int(libraries)
+	var document statsDocument
+	found, err := repo.docHandler.FindOne("stats", Document{"_id": userId}, &document)
+	if err != nil || !found {
+		return stats, err
+	}
+	stats.RefreshedAt = &document.RefreshedAt
+	for _, entry := range document.Entries {
+		stats.Games += entry.Games
+		stats.Value += entry.Value
+		stats.Statuses[entry.Status] += entry.Games
+		stats.Platforms[entry.Platform] += entry.Games
+	}
+	for _, playtime := range document.Playtime {
+		stats.PlatformMinutes[playtime.Platform] = playtime.Minutes
+	}
+	return stats, nil
+}
+
+// Documents are not aggregated, every user's totals are summed up here.
+// Totals of users gone since the last refresh are dropped.
+func (repo MongoStatsRepo) Refresh() error {
+	// Stored times keep milliseconds, the ones just written must not look older
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	var libraries []libraryDocument
+	err := repo.docHandler.Find("libraries", Document{}, FindOptions{}, &libraries)
+	if err != nil {
+		return err
+	}
+	var sessions []playSessionDocument
+	err = repo.docHandler.Find("play_sessions", Document{"starts_at": Document{"$lt": now}},
+		FindOptions{}, &sessions)
+	if err != nil {
+		return err
+	}
+	var months []sessionMonthDocument
+	err = repo.docHandler.Find("session_months", Document{}, FindOptions{}, &months)
+	if err != nil {
+		return err
+	}
+
+	type entryKey struct {
+		userId           int
+		status, platform string
+	}
+	entries := make(map[entryKey]statsEntryDocument)
+	// Sessions count for the platform of the user's entry of the game
+	platforms := make(map[[2]int]string)
+	documents := make(map[int]*statsDocument)
+	for _, library := range libraries {
+		if documents[library.UserId] == nil {
+			documents[library.UserId] = &statsDocument{UserId: library.UserId, RefreshedAt: now}
+		}
+		for _, game := range library.Games {
+			key := entryKey{library.UserId, game.Status, game.Platform}
+			entry := entries[key]
+			entry.Status, entry.Platform = game.Status, game.Platform
+			entry.Games++
+			entry.Value += game.Value
+			entries[key] = entry
+			gameKey := [2]int{library.UserId, game.GameId}
+			if current, known := platforms[gameKey]; !known || game.Platform < current {
+				platforms[gameKey] = game.Platform
+			}
+		}
+	}
+	for key, entry := range entries {
+		documents[key.userId].Entries = append(documents[key.userId].Entries, entry)
+	}
+
+	minutes := make(map[int]map[string]int)
+	played := func(userId, gameId, played int) {
+		if minutes[userId] == nil {
+			minutes[userId] = make(map[string]int)
+		}
+		minutes[userId][platforms[[2]int{userId, gameId}]] += played
+	}
+	for _, session := range sessions {
+		played(session.UserId, session.GameId, session.Minutes)
+	}
+	for _, month := range months {
+		played(month.UserId, month.GameId, month.Minutes)
+	}
+	for userId, perPlatform := range minutes {
+		if documents[userId] == nil {
+			documents[userId] = &statsDocument{UserId: userId, RefreshedAt: now}
+		}
+		for platform, total := range perPlatform {
+			documents[userId].Playtime = append(documents[userId].Playtime,
+				statsMinutesDocument{Platform: platform, Minutes: total})
+		}
+	}
+
+	for userId, document := range documents {
+		err = repo.docHandler.Upsert("stats", Document{"_id": userId}, document)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = repo.docHandler.Delete("stats", Document{"refreshed_at": Document{"$lt": now}})
+	return err
+}
@@ -0,0 +1,37 @@
+package interfaces
+
+import "time"
+
+type MongoSteamRepo DocRepo
+
+type steamAppDocument struct {
+	AppId     int       `bson:"_id"`
+	GameId    int       `bson:"game_id"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func NewMongoSteamRepo(docHandlers map[string]DocumentHandler) *MongoSteamRepo {
+	mongoSteamRepo := new(MongoSteamRepo)
+	mongoSteamRepo.docHandlers = docHandlers
+	mongoSteamRepo.docHandler = docHandlers["MongoSteamRepo"]
+	return mongoSteamRepo
+}
+
+func (repo MongoSteamRepo) FindGameIds(appIds []int) (map[int]int, error) {
+	var documents []steamAppDocument
+	err := repo.docHandler.Find("steam_apps", Document{"_id": Document{"$in": appIds}},
+		FindOptions{}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	gameIds := make(map[int]int)
+	for _, document := range documents {
+		gameIds[document.AppId] = document.GameId
+	}
+	return gameIds, nil
+}
+
+func (repo MongoSteamRepo) Link(appId, gameId int) error {
+	return repo.docHandler.Upsert("steam_apps", Document{"_id": appId}, steamAppDocument{
+		AppId: appId, GameId: gameId, CreatedAt: time.Now().UTC()})
+}
@@ -0,0 +1,160 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoSubscriptionRepo DocRepo
+
+type subscriptionDocument struct {
+	Id            int       `bson:"_id"`
+	UserId        int       `bson:"user_id"`
+	Service       string    `bson:"service"`
+	MonthlyCost   float64   `bson:"monthly_cost"`
+	Currency      string    `bson:"currency"`
+	BillingMonths int       `bson:"billing_months"`
+	StartedOn     time.Time `bson:"started_on"`
+	RenewsOn      time.Time `bson:"renews_on"`
+	EndsOn        time.Time `bson:"ends_on"`
+	GameIds       []int     `bson:"game_ids"`
+	RemindedAt    time.Time `bson:"reminded_at"`
+	CreatedAt     time.Time `bson:"created_at"`
+	UpdatedAt     time.Time `bson:"updated_at"`
+}
+
+func NewMongoSubscriptionRepo(docHandlers map[string]DocumentHandler) *MongoSubscriptionRepo {
+	mongoSubscriptionRepo := new(MongoSubscriptionRepo)
+	mongoSubscriptionRepo.docHandlers = docHandlers
+	mongoSubscriptionRepo.docHandler = docHandlers["MongoSubscriptionRepo"]
+	return mongoSubscriptionRepo
+}
+
+func (document subscriptionDocument) subscription() usecases.Subscription {
+	return usecases.Subscription{Id: document.Id, UserId: document.UserId, Service: document.Service,
+		MonthlyCost: document.MonthlyCost, Currency: document.Currency,
+		BillingMonths: document.BillingMonths, StartedOn: document.StartedOn,
+		RenewsOn: document.RenewsOn, EndsOn: document.EndsOn, GameIds: document.GameIds,
+		RemindedAt: document.RemindedAt, CreatedAt: document.CreatedAt, UpdatedAt: document.UpdatedAt}
+}
+
+func (repo MongoSubscriptionRepo) Store(subscription usecases.Subscription) (int, error) {
+	id, err := repo.docHandler.NextSequence("subscriptions")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	err = repo.docHandler.Insert("subscriptions", subscriptionDocument{Id: int(id),
+		UserId: subscription.UserId, Service: subscription.Service,
+		MonthlyCost: subscription.MonthlyCost, Currency: subscription.Currency,
+		BillingMonths: subscription.BillingMonths, StartedOn: subscription.StartedOn,
+		RenewsOn: subscription.RenewsOn, EndsOn: subscription.EndsOn, GameIds: []int{},
+		CreatedAt: now, UpdatedAt: now})
+	return int(id), err
+}
+
+func (repo MongoSubscriptionRepo) FindById(id int) (usecases.Subscription, error, int) {
+	var document subscriptionDocument
+	found, err := repo.docHandler.FindOne("subscriptions", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.Subscription{}, err, 500
+	}
+	if !found {
+		return usecases.Subscription{}, domain.NewError(domain.CodeNotFound,
+			"Subscription #%d does not exist", id), 404
+	}
+	return document.subscription(), nil, 200
+}
+
+func (repo MongoSubscriptionRepo) FindByUser(userId int) ([]usecases.Subscription, error) {
+	return repo.find(Document{"user_id": userId}, []string{"service", "_id"})
+}
+
+// Ended subscriptions are left out here since documents cannot compare two fields
+func (repo MongoSubscriptionRepo) FindRenewing(until time.Time) ([]usecases.Subscription, error) {
+	subscriptions, err := repo.find(Document{"renews_on": Document{"$lte": until}}, []string{"_id"})
+	if err != nil {
+		return nil, err
+	}
+	var renewing []usecases.Subscription
+	for _, subscription := range subscriptions {
+		if subscription.EndsOn.IsZero() || subscription.EndsOn.After(subscription.RenewsOn) {
+			renewing = append(renewing, subscription)
+		}
+	}
+	return renewing, nil
+}
+
+func (repo MongoSubscriptionRepo) FindActive(day time.Time) ([]usecases.Subscription, error) {
+	return repo.find(Document{"started_on": Document{"$lte": day},
+		"$or": []Document{{"ends_on": time.Time{}}, {"ends_on": Document{"$gt": day}}}}, []string{"_id"})
+}
+
+func (repo MongoSubscriptionRepo) find(filter Document, sort []string) ([]usecases.Subscription, error) {
+	var documents []subscriptionDocument
+	err := repo.docHandler.Find("subscriptions", filter, FindOptions{Sort: sort}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var subscriptions []usecases.Subscription
+	for _, document := range documents {
+		subscriptions = append(subscriptions, document.subscription())
+	}
+	return subscriptions, nil
+}
+
+func (repo MongoSubscriptionRepo) Update(subscription usecases.Subscription) error {
+	existing, err, code := repo.FindById(subscription.Id)
+	if code == 404 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	set := Document{"service": subscription.Service, "monthly_cost": subscription.MonthlyCost,
+		"currency": subscription.Currency, "billing_months": subscription.BillingMonths,
+		"started_on": subscription.StartedOn, "renews_on": subscription.RenewsOn,
+		"ends_on": subscription.EndsOn, "updated_at": time.Now().UTC()}
+	if !existing.RenewsOn.Equal(subscription.RenewsOn) {
+		set["reminded_at"] = time.Time{}
+	}
+	_, err = repo.docHandler.Update("subscriptions", Document{"_id": subscription.Id},
+		Document{"$set": set})
+	return err
+}
+
+func (repo MongoSubscriptionRepo) SetRenewal(id int, renewsOn time.Time) error {
+	_, err := repo.docHandler.Update("subscriptions", Document{"_id": id},
+		Document{"$set": Document{"renews_on": renewsOn, "reminded_at": time.Time{}}})
+	return err
+}
+
+func (repo MongoSubscriptionRepo) MarkReminded(id int, at time.Time) error {
+	_, err := repo.docHandler.Update("subscriptions", Document{"_id": id},
+		Document{"$set": Document{"reminded_at": at}})
+	return err
+}
+
+func (repo MongoSubscriptionRepo) AddGame(id, gameId int) error {
+	_, err := repo.docHandler.Update("subscriptions", Document{"_id": id},
+		Document{"$addToSet": Document{"game_ids": gameId}})
+	return err
+}
+
+func (repo MongoSubscriptionRepo) RemoveGame(id, gameId int) error {
+	_, err := repo.docHandler.Update("subscriptions", Document{"_id": id},
+		Document{"$pull": Document{"game_ids": gameId}})
+	return err
+}
+
+func (repo MongoSubscriptionRepo) Remove(subscription usecases.Subscription) error {
+	_, err := repo.docHandler.Delete("subscriptions", Document{"_id": subscription.Id})
+	return err
+}
+
+func (repo MongoSubscriptionRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("subscriptions", Document{"user_id": userId})
+	return err
+}
@@ -0,0 +1,183 @@
+package interfaces
+
+import (
+	"fmt"
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoTradeRepo DocRepo
+
+type tradeOfferDocument struct {
+	Id                 int       `bson:"_id"`
+	ProposerId         int       `bson:"proposer_id"`
+	ProposerLibraryId  int       `bson:"proposer_library_id"`
+	RecipientId        int       `bson:"recipient_id"`
+	RecipientLibraryId int       `bson:"recipient_library_id"`
+	Offered            []int     `bson:"offered_copy_ids"`
+	Requested          []int     `bson:"requested_copy_ids"`
+	Status             string    `bson:"status"`
+	CounterOf          int       `bson:"counter_of"`
+	CreatedAt          time.Time `bson:"created_at"`
+	UpdatedAt          time.Time `bson:"updated_at"`
+}
+
+func NewMongoTradeRepo(docHandlers map[string]DocumentHandler) *MongoTradeRepo {
+	mongoTradeRepo := new(MongoTradeRepo)
+	mongoTradeRepo.docHandlers = docHandlers
+	mongoTradeRepo.docHandler = docHandlers["MongoTradeRepo"]
+	return mongoTradeRepo
+}
+
+func (document tradeOfferDocument) offer() usecases.TradeOffer {
+	return usecases.TradeOffer{Id: document.Id, ProposerId: document.ProposerId,
+		ProposerLibraryId: document.ProposerLibraryId, RecipientId: document.RecipientId,
+		RecipientLibraryId: document.RecipientLibraryId, Offered: document.Offered,
+		Requested: document.Requested, Status: document.Status, CounterOf: document.CounterOf,
+		CreatedAt: document.CreatedAt, UpdatedAt: document.UpdatedAt}
+}
+
+func (repo MongoTradeRepo) Store(offer usecases.TradeOffer) (int, error) {
+	id, err := repo.docHandler.NextSequence("trade_offers")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	err = repo.docHandler.Insert("trade_offers", tradeOfferDocument{Id: int(id),
+		ProposerId: offer.ProposerId, ProposerLibraryId: offer.ProposerLibraryId,
+		RecipientId: offer.RecipientId, RecipientLibraryId: offer.RecipientLibraryId,
+		Offered: offer.Offered, Requested: offer.Requested, Status: offer.Status,
+		CounterOf: offer.CounterOf, CreatedAt: now, UpdatedAt: now})
+	return int(id), err
+}
+
+func (repo MongoTradeRepo) FindById(id int) (usecases.TradeOffer, error, int) {
+	var document tradeOfferDocument
+	found, err := repo.docHandler.FindOne("trade_offers", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.TradeOffer{}, err, 500
+	}
+	if !found {
+		return usecases.TradeOffer{}, domain.NewError(domain.CodeNotFound,
+			"Trade #%d does not exist", id), 404
+	}
+	return document.offer(), nil, 200
+}
+
+func (repo MongoTradeRepo) FindByUser(userId int, page usecases.Page) ([]usecases.TradeOffer, error) {
+	after, err := page.After.Time()
+	if err != nil {
+		return nil, err
+	}
+	// The cursor brings an $or of its own
+	position := Document{}
+	options := pageDocuments(position, page, "created_at", after, true)
+	query := Document{"$and": []Document{
+		{"$or": []Document{{"proposer_id": userId}, {"recipient_id": userId}}}, position}}
+	var documents []tradeOfferDocument
+	err = repo.docHandler.Find("trade_offers", query, options, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var offers []usecases.TradeOffer
+	for _, document := range documents {
+		offers = append(offers, document.offer())
+	}
+	return offers, nil
+}
+
+func (repo MongoTradeRepo) SetStatus(id int, from, to string) (bool, error) {
+	updated, err := repo.docHandler.Update("trade_offers", Document{"_id": id, "status": from},
+		Document{"$set": Document{"status": to, "updated_at": time.Now().UTC()}})
+	return updated > 0, err
+}
+
+// Without transactions the offer is claimed first so it is accepted once,
+// and the copies are checked before any of them moves
+func (repo MongoTradeRepo) Transfer(offer usecases.TradeOffer, actorId int) (bool, error) {
+	claimed, err := repo.SetStatus(offer.Id, usecases.TradePending, usecases.TradeAccepted)
+	if err != nil || !claimed {
+		return false, err
+	}
+	for _, side := range []struct {
+		copyIds []int
+		from    int
+	}{{offer.Offered, offer.ProposerLibraryId}, {offer.Requested, offer.RecipientLibraryId}} {
+		if len(side.copyIds) == 0 {
+			continue
+		}
+		present, err := repo.docHandler.Count("physical_copies",
+			Document{"_id": Document{"$in": side.copyIds}, "library_id": side.from})
+		if err != nil {
+			return false, err
+		}
+		if int(present) != len(side.copyIds) {
+			_, err = repo.SetStatus(offer.Id, usecases.TradeAccepted, usecases.TradePending)
+			return false, err
+		}
+	}
+	err = repo.moveCopies(offer, offer.Offered, offer.ProposerLibraryId, offer.RecipientLibraryId,
+		actorId, offer.RecipientId)
+	if err != nil {
+		return false, err
+	}
+	err = repo.moveCopies(offer, offer.Requested, offer.RecipientLibraryId, offer.ProposerLibraryId,
+		actorId, offer.ProposerId)
+	return err == nil, err
+}
+
+func (repo MongoTradeRepo) moveCopies(offer usecases.TradeOffer, copyIds []int, from, to, actorId, receiverId int) error {
+	if len(copyIds) == 0 {
+		return nil
+	}
+	var copies []physicalCopyDocument
+	err := repo.docHandler.Find("physical_copies", Document{"_id": Document{"$in": copyIds}},
+		FindOptions{}, &copies)
+	if err != nil {
+		return err
+	}
+	_, err = repo.docHandler.Update("physical_copies",
+		Document{"_id": Document{"$in": copyIds}, "library_id": from},
+		Document{"$set": Document{"library_id": to}})
+	if err != nil {
+		return err
+	}
+
+	games := MongoGameRepo(repo)
+	for _, physical := range copies {
+		err, code := games.AddToLib(physical.GameId, to, 0)
+		if err != nil && code != 400 {
+			return err
+		}
+		left, err := repo.docHandler.Count("physical_copies",
+			Document{"game_id": physical.GameId, "library_id": from})
+		if err != nil {
+			return err
+		}
+		if left == 0 {
+			err, _ = games.RemoveFromLib(usecases.Game{Id: physical.GameId,
+				ExternalId: physical.GameExternalId}, from, 0)
+			if err != nil {
+				return err
+			}
+		}
+		err = MongoAdminRepo(repo).Audit(usecases.AuditEntry{ActorId: actorId,
+			Action: usecases.AuditTrade, TargetId: receiverId,
+			Detail: fmt.Sprintf("Trade #%d moved copy #%d from library #%d to library #%d",
+				offer.Id, physical.Id, from, to)})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo MongoTradeRepo) CancelForLib(libraryId int) error {
+	_, err := repo.docHandler.Update("trade_offers",
+		Document{"status": usecases.TradePending, "$or": []Document{
+			{"proposer_library_id": libraryId}, {"recipient_library_id": libraryId}}},
+		Document{"$set": Document{"status": usecases.TradeCancelled, "updated_at": time.Now().UTC()}})
+	return err
+}
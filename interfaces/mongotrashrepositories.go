@@ -0,0 +1,79 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type MongoTrashRepo DocRepo
+
+type trashDocument struct {
+	Id        int64               `bson:"_id"`
+	UserId    int                 `bson:"user_id"`
+	Kind      string              `bson:"kind"`
+	LibraryId int                 `bson:"library_id"`
+	Entries   []undoEntryDocument `bson:"entries"`
+	RemovedAt time.Time           `bson:"removed_at"`
+}
+
+func NewMongoTrashRepo(docHandlers map[string]DocumentHandler) *MongoTrashRepo {
+	mongoTrashRepo := new(MongoTrashRepo)
+	mongoTrashRepo.docHandlers = docHandlers
+	mongoTrashRepo.docHandler = docHandlers["MongoTrashRepo"]
+	return mongoTrashRepo
+}
+
+func (document trashDocument) item() usecases.TrashItem {
+	return usecases.TrashItem{Id: document.Id, UserId: document.UserId, Kind: document.Kind,
+		LibraryId: document.LibraryId, Entries: undoEntries(document.Entries),
+		RemovedAt: document.RemovedAt}
+}
+
+func (repo MongoTrashRepo) Store(item usecases.TrashItem) (int64, error) {
+	id, err := repo.docHandler.NextSequence("trash")
+	if err != nil {
+		return 0, err
+	}
+	err = repo.docHandler.Insert("trash", trashDocument{Id: id, UserId: item.UserId, Kind: item.Kind,
+		LibraryId: item.LibraryId, Entries: undoEntryDocuments(item.Entries),
+		RemovedAt: time.Now().UTC()})
+	return id, err
+}
+
+func (repo MongoTrashRepo) FindByUser(userId int) ([]usecases.TrashItem, error) {
+	var documents []trashDocument
+	err := repo.docHandler.Find("trash", Document{"user_id": userId},
+		FindOptions{Sort: []string{"-_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var items []usecases.TrashItem
+	for _, document := range documents {
+		items = append(items, document.item())
+	}
+	return items, nil
+}
+
+func (repo MongoTrashRepo) FindById(id int64) (usecases.TrashItem, error, int) {
+	var document trashDocument
+	found, err := repo.docHandler.FindOne("trash", Document{"_id": id}, &document)
+	if err != nil {
+		return usecases.TrashItem{}, err, 500
+	}
+	if !found {
+		return usecases.TrashItem{}, domain.NewError(domain.CodeNotFound,
+			"Item #%d is not in the trash", id), 404
+	}
+	return document.item(), nil, 200
+}
+
+func (repo MongoTrashRepo) Remove(id int64) (bool, error) {
+	removed, err := repo.docHandler.Delete("trash", Document{"_id": id})
+	return removed == 1, err
+}
+
+func (repo MongoTrashRepo) Purge(before time.Time) (int64, error) {
+	return repo.docHandler.Delete("trash", Document{"removed_at": Document{"$lt": before}})
+}
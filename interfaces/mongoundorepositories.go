@@ -0,0 +1,101 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoUndoRepo DocRepo
+
+type undoEntryDocument struct {
+	GameId       int      `bson:"game_id"`
+	Status       string   `bson:"status"`
+	Platform     string   `bson:"platform"`
+	Tags         []string `bson:"tags"`
+	WishlistRank int      `bson:"wishlist_rank"`
+}
+
+type undoActionDocument struct {
+	Id        int64               `bson:"_id"`
+	UserId    int                 `bson:"user_id"`
+	Kind      string              `bson:"kind"`
+	LibraryId int                 `bson:"library_id"`
+	GameIds   []int               `bson:"game_ids"`
+	Before    []undoEntryDocument `bson:"before"`
+	After     []undoEntryDocument `bson:"after"`
+	CreatedAt time.Time           `bson:"created_at"`
+	UndoneAt  time.Time           `bson:"undone_at"`
+}
+
+func NewMongoUndoRepo(docHandlers map[string]DocumentHandler) *MongoUndoRepo {
+	mongoUndoRepo := new(MongoUndoRepo)
+	mongoUndoRepo.docHandlers = docHandlers
+	mongoUndoRepo.docHandler = docHandlers["MongoUndoRepo"]
+	return mongoUndoRepo
+}
+
+func undoEntryDocuments(entries []usecases.LibraryEntry) []undoEntryDocument {
+	documents := []undoEntryDocument{}
+	for _, entry := range entries {
+		documents = append(documents, undoEntryDocument{GameId: entry.GameId, Status: entry.Status,
+			Platform: entry.Platform, Tags: entry.Tags, WishlistRank: entry.WishlistRank})
+	}
+	return documents
+}
+
+func undoEntries(documents []undoEntryDocument) []usecases.LibraryEntry {
+	var entries []usecases.LibraryEntry
+	for _, document := range documents {
+		entries = append(entries, usecases.LibraryEntry{GameId: document.GameId, Status: document.Status,
+			Platform: document.Platform, Tags: document.Tags, WishlistRank: document.WishlistRank})
+	}
+	return entries
+}
+
+func (repo MongoUndoRepo) Store(action usecases.UndoAction, since time.Time) (int64, error) {
+	_, err := repo.docHandler.Delete("undo_actions", Document{"user_id": action.UserId,
+		"$or": []Document{{"undone_at": Document{"$ne": time.Time{}}},
+			{"created_at": Document{"$lt": since}}}})
+	if err != nil {
+		return 0, err
+	}
+	id, err := repo.docHandler.NextSequence("undo_actions")
+	if err != nil {
+		return 0, err
+	}
+	err = repo.docHandler.Insert("undo_actions", undoActionDocument{Id: id, UserId: action.UserId,
+		Kind: action.Kind, LibraryId: action.LibraryId, GameIds: action.GameIds,
+		Before: undoEntryDocuments(action.Before), After: undoEntryDocuments(action.After),
+		CreatedAt: time.Now().UTC()})
+	return id, err
+}
+
+func (repo MongoUndoRepo) FindRecent(userId int, since time.Time) ([]usecases.UndoAction, error) {
+	var documents []undoActionDocument
+	err := repo.docHandler.Find("undo_actions", Document{"user_id": userId,
+		"created_at": Document{"$gte": since}}, FindOptions{Sort: []string{"-_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	var actions []usecases.UndoAction
+	for _, document := range documents {
+		actions = append(actions, usecases.UndoAction{Id: document.Id, UserId: document.UserId,
+			Kind: document.Kind, LibraryId: document.LibraryId, GameIds: document.GameIds,
+			Before: undoEntries(document.Before), After: undoEntries(document.After),
+			CreatedAt: document.CreatedAt, UndoneAt: document.UndoneAt})
+	}
+	return actions, nil
+}
+
+func (repo MongoUndoRepo) SetUndone(id int64, undone bool) (bool, error) {
+	filter := Document{"_id": id, "undone_at": time.Time{}}
+	undoneAt := time.Now().UTC()
+	if !undone {
+		filter["undone_at"] = Document{"$ne": time.Time{}}
+		undoneAt = time.Time{}
+	}
+	changed, err := repo.docHandler.Update("undo_actions", filter,
+		Document{"$set": Document{"undone_at": undoneAt}})
+	return changed == 1, err
+}
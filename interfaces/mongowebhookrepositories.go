@@ -0,0 +1,88 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type MongoWebhookRepo DocRepo
+
+type webhookDocument struct {
+	Id                int       `bson:"_id"`
+	UserId            int       `bson:"user_id"`
+	Name              string    `bson:"name"`
+	Action            string    `bson:"action"`
+	LibraryId         int       `bson:"library_id"`
+	LibraryExternalId string    `bson:"library_external_id"`
+	KeyHash           string    `bson:"key_hash"`
+	Secret            string    `bson:"secret"`
+	LastDeliveredAt   time.Time `bson:"last_delivered_at"`
+	CreatedAt         time.Time `bson:"created_at"`
+}
+
+func NewMongoWebhookRepo(docHandlers map[string]DocumentHandler) *MongoWebhookRepo {
+	mongoWebhookRepo := new(MongoWebhookRepo)
+	mongoWebhookRepo.docHandlers = docHandlers
+	mongoWebhookRepo.docHandler = docHandlers["MongoWebhookRepo"]
+	return mongoWebhookRepo
+}
+
+func (document webhookDocument) webhook() usecases.Webhook {
+	return usecases.Webhook{Id: document.Id, UserId: document.UserId, Name: document.Name,
+		Action: document.Action, LibraryId: document.LibraryId,
+		LibraryExternalId: document.LibraryExternalId, KeyHash: document.KeyHash,
+		Secret: document.Secret, LastDeliveredAt: document.LastDeliveredAt,
+		CreatedAt: document.CreatedAt}
+}
+
+func (repo MongoWebhookRepo) Store(webhook usecases.Webhook) (int, error) {
+	id, err := repo.docHandler.NextSequence("webhooks")
+	if err != nil {
+		return 0, err
+	}
+	err = repo.docHandler.Insert("webhooks", webhookDocument{Id: int(id), UserId: webhook.UserId,
+		Name: webhook.Name, Action: webhook.Action, LibraryId: webhook.LibraryId,
+		LibraryExternalId: webhook.LibraryExternalId, KeyHash: webhook.KeyHash,
+		Secret: webhook.Secret, CreatedAt: webhook.CreatedAt})
+	return int(id), err
+}
+
+func (repo MongoWebhookRepo) FindByKeyHash(keyHash string) (usecases.Webhook, bool, error) {
+	var document webhookDocument
+	found, err := repo.docHandler.FindOne("webhooks", Document{"key_hash": keyHash}, &document)
+	if err != nil || !found {
+		return usecases.Webhook{}, false, err
+	}
+	return document.webhook(), true, nil
+}
+
+func (repo MongoWebhookRepo) FindByUser(userId int) ([]usecases.Webhook, error) {
+	var documents []webhookDocument
+	err := repo.docHandler.Find("webhooks", Document{"user_id": userId},
+		FindOptions{Sort: []string{"created_at", "_id"}}, &documents)
+	if err != nil {
+		return nil, err
+	}
+	webhooks := make([]usecases.Webhook, len(documents))
+	for i, document := range documents {
+		webhooks[i] = document.webhook()
+	}
+	return webhooks, nil
+}
+
+func (repo MongoWebhookRepo) MarkDelivered(id int, at time.Time) error {
+	_, err := repo.docHandler.Update("webhooks", Document{"_id": id},
+		Document{"$set": Document{"last_delivered_at": at}})
+	return err
+}
+
+func (repo MongoWebhookRepo) Remove(webhook usecases.Webhook) error {
+	_, err := repo.docHandler.Delete("webhooks", Document{"_id": webhook.Id})
+	return err
+}
+
+func (repo MongoWebhookRepo) RemoveAll(userId int) error {
+	_, err := repo.docHandler.Delete("webhooks", Document{"user_id": userId})
+	return err
+}
@@ -0,0 +1,86 @@
+package interfaces
+
+import (
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type DbNotificationRepo DbRepo
+
+func NewDbNotificationRepo(dbHandlers map[string]DbHandler) *DbNotificationRepo {
+	dbNotificationRepo := new(DbNotificationRepo)
+	dbNotificationRepo.dbHandlers = dbHandlers
+	dbNotificationRepo.dbHandler = dbHandlers["DbNotificationRepo"]
+	return dbNotificationRepo
+}
+
+func (repo DbNotificationRepo) Store(notification usecases.Notification) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("notifications").
+		Set("user_id", notification.UserId).Set("kind", notification.Kind).
+		Set("message", notification.Message).Returning("id").Build()
+	id, err := repo.dbHandler.QueryRow(statement, args...)
+	return id, err
+}
+
+func (repo DbNotificationRepo) FindByUser(userId int, page usecases.Page) ([]usecases.Notification, error) {
+	after, err := page.After.Time()
+	if err != nil {
+		return nil, err
+	}
+	selection := repo.dbHandler.Dialect().Select("id", "kind", "message", "read", "created_at").
+		From("notifications").Where("user_id = ?", userId)
+	statement, args := pageRows(selection, page, "created_at", "id", after, true).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var notifications []usecases.Notification
+	for row.Next() {
+		var (
+			id        int
+			kind      string
+			message   string
+			read      bool
+			createdAt time.Time
+		)
+		err = row.Scan(&id, &kind, &message, &read, &createdAt)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, usecases.Notification{Id: id, UserId: userId,
+			Kind: kind, Message: message, Read: read, CreatedAt: createdAt})
+	}
+	return notifications, nil
+}
+
+func (repo DbNotificationRepo) CountUnread(userId int) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Select("count(*)").From("notifications").
+		Where("user_id = ?", userId).Where("NOT read").Build()
+	count, err := repo.dbHandler.QueryRow(statement, args...)
+	return count, err
+}
+
+func (repo DbNotificationRepo) MarkRead(userId int, ids []int) error {
+	statement, args := repo.dbHandler.Dialect().Update("notifications").Set("read", true).
+		SetExpr("updated_at = now()").Where("user_id = ?", userId).
+		Where("id = ANY(?::int[])", intArray(ids)).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbNotificationRepo) MarkAllRead(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Update("notifications").Set("read", true).
+		SetExpr("updated_at = now()").Where("user_id = ?", userId).Where("NOT read").Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbNotificationRepo) RemoveAll(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("notifications").
+		Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
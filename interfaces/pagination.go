@@ -0,0 +1,69 @@
+package interfaces
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/interfaces/query"
+	"game-tracker/usecases"
+)
+
+// Orders a selection by keyColumn and then idColumn and keeps the page of
+// rows after its cursor. Key is the cursor's key as the column holds it, an
+// empty keyColumn orders by id alone. A zero page keeps every row in order.
+func pageRows(selection *query.SelectBuilder, page usecases.Page, keyColumn, idColumn string, key interface{}, descending bool) *query.SelectBuilder {
+	direction, after := "", ">"
+	if descending {
+		direction, after = " DESC", "<"
+	}
+	if keyColumn == "" {
+		if !page.After.IsZero() {
+			selection.Where(idColumn+" "+after+" ?", page.After.Id)
+		}
+		return selection.OrderBy(idColumn + direction).Limit(page.Limit)
+	}
+	if !page.After.IsZero() {
+		selection.Where("("+keyColumn+", "+idColumn+") "+after+" (?, ?)", key, page.After.Id)
+	}
+	return selection.OrderBy(keyColumn+direction, idColumn+direction).Limit(page.Limit)
+}
+
+// Keeps the page of documents after its cursor, as pageRows does, and
+// returns the options to find them with
+func pageDocuments(filter Document, page usecases.Page, keyField string, key interface{}, descending bool) FindOptions {
+	direction, after := "", "$gt"
+	if descending {
+		direction, after = "-", "$lt"
+	}
+	if keyField == "" {
+		if !page.After.IsZero() {
+			filter["_id"] = Document{after: page.After.Id}
+		}
+		return FindOptions{Sort: []string{direction + "_id"}, Limit: page.Limit}
+	}
+	if !page.After.IsZero() {
+		filter["$or"] = []Document{
+			{keyField: Document{after: key}},
+			{keyField: key, "_id": Document{after: page.After.Id}},
+		}
+	}
+	return FindOptions{Sort: []string{direction + keyField, direction + "_id"}, Limit: page.Limit}
+}
+
+// Reads ?cursor and ?limit of a list request
+func pageQuery(c *gin.Context, defaultLimit int) (usecases.Page, error) {
+	after, err := usecases.ParseCursor(c.Query("cursor"))
+	if err != nil {
+		return usecases.Page{}, err
+	}
+	page := usecases.Page{After: after, Limit: defaultLimit}
+	if value := c.Query("limit"); value != "" {
+		page.Limit, err = strconv.Atoi(value)
+		if err != nil {
+			return usecases.Page{}, domain.NewFieldError("limit", "Must be a whole number")
+		}
+	}
+	return page, nil
+}
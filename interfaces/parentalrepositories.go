@@ -0,0 +1,79 @@
+package interfaces
+
+import (
+	"game-tracker/usecases"
+)
+
+type DbChildAccountRepo DbRepo
+
+func NewDbChildAccountRepo(dbHandlers map[string]DbHandler) *DbChildAccountRepo {
+	dbChildAccountRepo := new(DbChildAccountRepo)
+	dbChildAccountRepo.dbHandlers = dbHandlers
+	dbChildAccountRepo.dbHandler = dbHandlers["DbChildAccountRepo"]
+	return dbChildAccountRepo
+}
+
+// The parent is kept when the limits of a linked child are replaced
+func (repo DbChildAccountRepo) Store(account usecases.ChildAccount) error {
+	statement, args := repo.dbHandler.Dialect().Insert("child_accounts").
+		Set("child_id", account.ChildId).Set("parent_id", account.ParentId).
+		Set("daily_minutes", account.DailyMinutes).Set("rating_cap", account.RatingCap).
+		OnConflict("(child_id)", `DO UPDATE SET daily_minutes = EXCLUDED.daily_minutes,
+			rating_cap = EXCLUDED.rating_cap, updated_at = now()`).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+var childAccountColumns = []string{"parent_id", "child_id", "daily_minutes", "rating_cap",
+	"created_at", "updated_at"}
+
+func (repo DbChildAccountRepo) FindByChild(childId int) (usecases.ChildAccount, bool, error) {
+	statement, args := repo.dbHandler.Dialect().Select(childAccountColumns...).
+		From("child_accounts").Where("child_id = ?", childId).Limit(1).Build()
+	accounts, err := repo.query(statement, args)
+	if err != nil || len(accounts) == 0 {
+		return usecases.ChildAccount{}, false, err
+	}
+	return accounts[0], true, nil
+}
+
+func (repo DbChildAccountRepo) FindByParent(parentId int) ([]usecases.ChildAccount, error) {
+	statement, args := repo.dbHandler.Dialect().Select(childAccountColumns...).
+		From("child_accounts").Where("parent_id = ?", parentId).OrderBy("created_at", "child_id").
+		Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbChildAccountRepo) query(statement string, args []interface{}) ([]usecases.ChildAccount, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var accounts []usecases.ChildAccount
+	for row.Next() {
+		var account usecases.ChildAccount
+		err = row.Scan(&account.ParentId, &account.ChildId, &account.DailyMinutes,
+			&account.RatingCap, &account.CreatedAt, &account.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+func (repo DbChildAccountRepo) Remove(childId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("child_accounts").
+		Where("child_id = ?", childId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbChildAccountRepo) RemoveAll(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("child_accounts").
+		Where("child_id = ? OR parent_id = ?", userId, userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
@@ -0,0 +1,154 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"strings"
+
+	"game-tracker/usecases"
+)
+
+type DbPersonalMetadataRepo DbRepo
+
+func NewDbPersonalMetadataRepo(dbHandlers map[string]DbHandler) *DbPersonalMetadataRepo {
+	dbPersonalMetadataRepo := new(DbPersonalMetadataRepo)
+	dbPersonalMetadataRepo.dbHandlers = dbHandlers
+	dbPersonalMetadataRepo.dbHandler = dbHandlers["DbPersonalMetadataRepo"]
+	return dbPersonalMetadataRepo
+}
+
+// Moods are plain lowercase words, so they need no quoting in the array literal
+func (repo DbPersonalMetadataRepo) Store(metadata usecases.PersonalMetadata) error {
+	statement, args := repo.dbHandler.Dialect().Insert("personal_metadata").
+		Set("user_id", metadata.UserId).Set("game_id", metadata.GameId).
+		Set("difficulty", metadata.Difficulty).Set("replayability", metadata.Replayability).
+		Set("moods", "{"+strings.Join(metadata.Moods, ",")+"}").
+		OnConflict("(user_id, game_id)", `DO UPDATE SET difficulty = EXCLUDED.difficulty,
+			replayability = EXCLUDED.replayability, moods = EXCLUDED.moods, updated_at = now()`).
+		Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbPersonalMetadataRepo) Find(userId, gameId int) (usecases.PersonalMetadata, bool, error) {
+	statement, args := repo.dbHandler.Dialect().Select("user_id", "game_id", "difficulty",
+		"replayability", "array_to_json(moods)", "updated_at").From("personal_metadata").
+		Where("user_id = ?", userId).Where("game_id = ?", gameId).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return usecases.PersonalMetadata{}, false, err
+	}
+	defer row.Close()
+	if !row.Next() {
+		return usecases.PersonalMetadata{}, false, nil
+	}
+	var metadata usecases.PersonalMetadata
+	var moods string
+	err = row.Scan(&metadata.UserId, &metadata.GameId, &metadata.Difficulty,
+		&metadata.Replayability, &moods, &metadata.UpdatedAt)
+	if err == nil {
+		err = json.Unmarshal([]byte(moods), &metadata.Moods)
+	}
+	if err != nil {
+		return usecases.PersonalMetadata{}, false, err
+	}
+	return metadata, true, nil
+}
+
+func (repo DbPersonalMetadataRepo) Remove(userId, gameId int) (bool, error) {
+	statement, args := repo.dbHandler.Dialect().Delete("personal_metadata").
+		Where("user_id = ?", userId).Where("game_id = ?", gameId).Build()
+	result, err := repo.dbHandler.Execute(statement, args...)
+	if err != nil {
+		return false, err
+	}
+	removed, err := result.RowsAffected()
+	return removed > 0, err
+}
+
+func (repo DbPersonalMetadataRepo) RemoveAll(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("personal_metadata").
+		Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+// Entries the user set nothing on come with zero metadata
+func (repo DbPersonalMetadataRepo) FindCandidates(userId int, statuses []string) ([]usecases.Candidate, error) {
+	statement, args := repo.dbHandler.Dialect().Select("games.id", "games.external_id",
+		"games.name", "games.producer", "games.value", "games.min_age", "games.rating",
+		"gamesInLib.status", "gamesInLib.platform", "coalesce(personal_metadata.difficulty, 0)",
+		"coalesce(personal_metadata.replayability, 0)",
+		"array_to_json(coalesce(personal_metadata.moods, '{}'))", "gamesInLib.added_at").
+		From("gamesInLib").
+		Join("libraries", "libraries.id = gamesInLib.library_id").
+		Join("games", "games.id = gamesInLib.game_id").
+		LeftJoin("personal_metadata", `personal_metadata.game_id = gamesInLib.game_id
+			AND personal_metadata.user_id = libraries.user_id`).
+		Where("libraries.user_id = ?", userId).
+		Where("gamesInLib.status = ANY(?::text[])", "{"+strings.Join(statuses, ",")+"}").
+		OrderBy("games.id").Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var candidates []usecases.Candidate
+	for row.Next() {
+		candidate := usecases.Candidate{Metadata: usecases.PersonalMetadata{UserId: userId}}
+		var moods string
+		err = row.Scan(&candidate.Game.Id, &candidate.Game.ExternalId, &candidate.Game.Name,
+			&candidate.Game.Producer, &candidate.Game.Value, &candidate.Game.MinAge,
+			&candidate.Game.Rating, &candidate.Game.Status, &candidate.Game.Platform,
+			&candidate.Metadata.Difficulty, &candidate.Metadata.Replayability, &moods,
+			&candidate.AddedAt)
+		if err == nil {
+			err = json.Unmarshal([]byte(moods), &candidate.Metadata.Moods)
+		}
+		if err != nil {
+			return nil, err
+		}
+		candidate.Metadata.GameId = candidate.Game.Id
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+// Completion time only counts the sessions of users who marked the game
+// completed, archived months included, ratings only the replayability other
+// users set
+func (repo DbPersonalMetadataRepo) FindGameStats(userId int, gameIds []int) ([]usecases.GameStats, error) {
+	row, err := repo.dbHandler.Query(`SELECT games.id,
+			coalesce((SELECT round(avg(played.minutes)) FROM (
+				SELECT sum(sessions.minutes) AS minutes FROM (
+					SELECT user_id, minutes FROM play_sessions WHERE game_id = games.id
+					UNION ALL
+					SELECT user_id, minutes FROM play_session_months WHERE game_id = games.id) sessions
+				WHERE EXISTS (
+					SELECT 1 FROM gamesInLib JOIN libraries ON libraries.id = gamesInLib.library_id
+					WHERE libraries.user_id = sessions.user_id
+						AND gamesInLib.game_id = games.id AND gamesInLib.status = 'completed')
+				GROUP BY sessions.user_id) played), 0),
+			coalesce(rated.rating, 0), coalesce(rated.raters, 0)
+		FROM games
+		LEFT JOIN (SELECT game_id, avg(replayability) AS rating, count(*) AS raters
+			FROM personal_metadata WHERE user_id <> $2 AND replayability > 0
+			GROUP BY game_id) rated ON rated.game_id = games.id
+		WHERE games.id = ANY($1::int[])
+		ORDER BY games.id`, intArray(gameIds), userId)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var stats []usecases.GameStats
+	for row.Next() {
+		var entry usecases.GameStats
+		err = row.Scan(&entry.GameId, &entry.CompletionMinutes, &entry.Rating, &entry.Raters)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, entry)
+	}
+	return stats, nil
+}
@@ -0,0 +1,197 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"strings"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbPhotoImportRepo DbRepo
+
+func NewDbPhotoImportRepo(dbHandlers map[string]DbHandler) *DbPhotoImportRepo {
+	dbPhotoImportRepo := new(DbPhotoImportRepo)
+	dbPhotoImportRepo.dbHandlers = dbHandlers
+	dbPhotoImportRepo.dbHandler = dbHandlers["DbPhotoImportRepo"]
+	return dbPhotoImportRepo
+}
+
+var photoImportColumns = []string{"id", "user_id", "library_id", "status", "reason",
+	"array_to_json(photos)", "array_to_json(photo_types)", "created_at", "updated_at"}
+
+// Blob keys and image types are plain words and slashes, so they need no
+// quoting in the array literal
+func (repo DbPhotoImportRepo) Store(photoImport usecases.PhotoImport) (int, error) {
+	var keys, types []string
+	for _, photo := range photoImport.Photos {
+		keys = append(keys, photo.Key)
+		types = append(types, photo.ContentType)
+	}
+	statement, args := repo.dbHandler.Dialect().Insert("photo_imports").
+		Set("user_id", photoImport.UserId).Set("library_id", photoImport.LibraryId).
+		Set("status", photoImport.Status).Set("photos", "{"+strings.Join(keys, ",")+"}").
+		Set("photo_types", "{"+strings.Join(types, ",")+"}").Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbPhotoImportRepo) FindById(id int) (usecases.PhotoImport, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(photoImportColumns...).From("photo_imports").
+		Where("id = ?", id).Limit(1).Build()
+	imports, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.PhotoImport{}, err, 500
+	}
+	if len(imports) == 0 {
+		return usecases.PhotoImport{}, domain.NewError(domain.CodeNotFound,
+			"Photo import #%d does not exist", id), 404
+	}
+	photoImport := imports[0]
+
+	statement, args = repo.dbHandler.Dialect().Select("id", "photo", "title", "confidence", "matched",
+		"reason", "accepted", "game_id").From("photo_proposals").Where("import_id = ?", id).
+		OrderBy("id").Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return usecases.PhotoImport{}, err, 500
+	}
+	defer row.Close()
+	for row.Next() {
+		var proposal usecases.PhotoProposal
+		err = row.Scan(&proposal.Id, &proposal.Photo, &proposal.Title, &proposal.Confidence,
+			&proposal.Matched, &proposal.Reason, &proposal.Accepted, &proposal.GameId)
+		if err != nil {
+			return usecases.PhotoImport{}, err, 500
+		}
+		photoImport.Proposals = append(photoImport.Proposals, proposal)
+	}
+	return photoImport, nil, 200
+}
+
+func (repo DbPhotoImportRepo) FindPending(limit int) ([]usecases.PhotoImport, error) {
+	statement, args := repo.dbHandler.Dialect().Select(photoImportColumns...).From("photo_imports").
+		Where("status = ?", usecases.PhotoImportPending).OrderBy("created_at", "id").Limit(limit).Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbPhotoImportRepo) query(statement string, args []interface{}) ([]usecases.PhotoImport, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var imports []usecases.PhotoImport
+	for row.Next() {
+		var photoImport usecases.PhotoImport
+		var keys, types string
+		err = row.Scan(&photoImport.Id, &photoImport.UserId, &photoImport.LibraryId, &photoImport.Status,
+			&photoImport.Reason, &keys, &types, &photoImport.CreatedAt, &photoImport.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		photoImport.Photos, err = importPhotos(keys, types)
+		if err != nil {
+			return nil, err
+		}
+		imports = append(imports, photoImport)
+	}
+	return imports, nil
+}
+
+// Pairs the keys and types read back as JSON arrays
+func importPhotos(keys, types string) ([]usecases.ImportPhoto, error) {
+	var keyList, typeList []string
+	err := json.Unmarshal([]byte(keys), &keyList)
+	if err == nil {
+		err = json.Unmarshal([]byte(types), &typeList)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var photos []usecases.ImportPhoto
+	for i, key := range keyList {
+		photo := usecases.ImportPhoto{Key: key}
+		if i < len(typeList) {
+			photo.ContentType = typeList[i]
+		}
+		photos = append(photos, photo)
+	}
+	return photos, nil
+}
+
+func (repo DbPhotoImportRepo) StoreProposals(importId int, proposals []usecases.PhotoProposal) error {
+	return repo.dbHandler.Transaction(func(tx DbHandler) error {
+		for _, proposal := range proposals {
+			statement, args := tx.Dialect().Insert("photo_proposals").Set("import_id", importId).
+				Set("id", proposal.Id).Set("photo", proposal.Photo).Set("title", proposal.Title).
+				Set("confidence", proposal.Confidence).Set("matched", proposal.Matched).
+				Set("reason", proposal.Reason).Build()
+			_, err := tx.Execute(statement, args...)
+			if err != nil {
+				return err
+			}
+		}
+		return repo.setStatus(tx, importId, usecases.PhotoImportProposed, "")
+	})
+}
+
+func (repo DbPhotoImportRepo) Fail(importId int, reason string) error {
+	return repo.setStatus(repo.dbHandler, importId, usecases.PhotoImportFailed, reason)
+}
+
+func (repo DbPhotoImportRepo) setStatus(handler DbHandler, importId int, status, reason string) error {
+	statement, args := handler.Dialect().Update("photo_imports").Set("status", status).
+		Set("reason", reason).SetExpr("updated_at = now()").Where("id = ?", importId).Build()
+	_, err := handler.Execute(statement, args...)
+	return err
+}
+
+// The photos are gone once confirmed, so their keys go as well
+func (repo DbPhotoImportRepo) Confirm(importId int, accepted map[int]int) error {
+	var proposalIds, gameIds []int
+	for proposalId, gameId := range accepted {
+		proposalIds = append(proposalIds, proposalId)
+		gameIds = append(gameIds, gameId)
+	}
+	return repo.dbHandler.Transaction(func(tx DbHandler) error {
+		_, err := tx.Execute(`UPDATE photo_proposals SET accepted = true, game_id = picked.game_id
+			FROM unnest($2::int[], $3::int[]) AS picked (id, game_id)
+			WHERE photo_proposals.import_id = $1 AND photo_proposals.id = picked.id`,
+			importId, intArray(proposalIds), intArray(gameIds))
+		if err != nil {
+			return err
+		}
+		statement, args := tx.Dialect().Update("photo_imports").
+			Set("status", usecases.PhotoImportConfirmed).Set("photos", "{}").Set("photo_types", "{}").
+			SetExpr("updated_at = now()").Where("id = ?", importId).Build()
+		_, err = tx.Execute(statement, args...)
+		return err
+	})
+}
+
+func (repo DbPhotoImportRepo) RemoveFromLib(libraryId int) ([]string, error) {
+	statement, args := repo.dbHandler.Dialect().Delete("photo_imports").
+		Where("library_id = ?", libraryId).Returning("array_to_json(photos)").Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var keys []string
+	for row.Next() {
+		var photos string
+		err = row.Scan(&photos)
+		if err != nil {
+			return nil, err
+		}
+		var photoKeys []string
+		err = json.Unmarshal([]byte(photos), &photoKeys)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, photoKeys...)
+	}
+	return keys, nil
+}
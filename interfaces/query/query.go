@@ -0,0 +1,261 @@
+// Package query builds SQL statements for the repositories. Conditions are
+// written with ? placeholders which Build rewrites for the dialect, values
+// always travel as arguments and never end up in the statement text.
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Dialect int
+
+const (
+	Postgres Dialect = iota //$1, $2, ...
+	Question                //?, as used by MySQL and SQLite
+)
+
+type condition struct {
+	sql  string
+	args []interface{}
+}
+
+// Rewrites the ? placeholders of sql, so operators containing ? cannot be used
+func (dialect Dialect) bind(sql string) string {
+	if dialect == Question {
+		return sql
+	}
+	var builder strings.Builder
+	n := 0
+	for _, r := range sql {
+		if r == '?' {
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+func whereClause(conditions []condition) (string, []interface{}) {
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	var parts []string
+	var args []interface{}
+	for _, cond := range conditions {
+		parts = append(parts, "("+cond.sql+")")
+		args = append(args, cond.args...)
+	}
+	return " WHERE " + strings.Join(parts, " AND "), args
+}
+
+type SelectBuilder struct {
+	dialect Dialect
+	columns []string
+	from    string
+	joins   []string
+	where   []condition
+	orderBy []string
+	limit   int
+	offset  int
+}
+
+func (dialect Dialect) Select(columns ...string) *SelectBuilder {
+	return &SelectBuilder{dialect: dialect, columns: columns}
+}
+
+func (b *SelectBuilder) From(table string) *SelectBuilder {
+	b.from = table
+	return b
+}
+
+func (b *SelectBuilder) Join(table, on string) *SelectBuilder {
+	b.joins = append(b.joins, "JOIN "+table+" ON "+on)
+	return b
+}
+
+func (b *SelectBuilder) LeftJoin(table, on string) *SelectBuilder {
+	b.joins = append(b.joins, "LEFT JOIN "+table+" ON "+on)
+	return b
+}
+
+// Conditions of repeated calls are joined with AND
+func (b *SelectBuilder) Where(sql string, args ...interface{}) *SelectBuilder {
+	b.where = append(b.where, condition{sql, args})
+	return b
+}
+
+func (b *SelectBuilder) OrderBy(columns ...string) *SelectBuilder {
+	b.orderBy = append(b.orderBy, columns...)
+	return b
+}
+
+// Orders by a field named in a request, prefixed with "-" for descending
+// order. Only fields listed in columns are accepted.
+func (b *SelectBuilder) OrderByField(field string, columns map[string]string) error {
+	direction := " ASC"
+	if strings.HasPrefix(field, "-") {
+		field = field[1:]
+		direction = " DESC"
+	}
+	column, ok := columns[field]
+	if !ok {
+		return fmt.Errorf("Cannot sort by '%s'", field)
+	}
+	b.orderBy = append(b.orderBy, column+direction)
+	return nil
+}
+
+func (b *SelectBuilder) Limit(limit int) *SelectBuilder {
+	b.limit = limit
+	return b
+}
+
+func (b *SelectBuilder) Offset(offset int) *SelectBuilder {
+	b.offset = offset
+	return b
+}
+
+func (b *SelectBuilder) Build() (string, []interface{}) {
+	sql := "SELECT " + strings.Join(b.columns, ", ") + " FROM " + b.from
+	for _, join := range b.joins {
+		sql += " " + join
+	}
+	where, args := whereClause(b.where)
+	sql += where
+	if len(b.orderBy) > 0 {
+		sql += " ORDER BY " + strings.Join(b.orderBy, ", ")
+	}
+	if b.limit > 0 {
+		sql += " LIMIT ?"
+		args = append(args, b.limit)
+	}
+	if b.offset > 0 {
+		sql += " OFFSET ?"
+		args = append(args, b.offset)
+	}
+	return b.dialect.bind(sql), args
+}
+
+type InsertBuilder struct {
+	dialect    Dialect
+	table      string
+	columns    []string
+	values     []interface{}
+	onConflict string
+	returning  []string
+}
+
+func (dialect Dialect) Insert(table string) *InsertBuilder {
+	return &InsertBuilder{dialect: dialect, table: table}
+}
+
+func (b *InsertBuilder) Set(column string, value interface{}) *InsertBuilder {
+	b.columns = append(b.columns, column)
+	b.values = append(b.values, value)
+	return b
+}
+
+// Adds an ON CONFLICT clause, e.g. OnConflict("(player_name)", "DO NOTHING")
+func (b *InsertBuilder) OnConflict(target, action string) *InsertBuilder {
+	b.onConflict = " ON CONFLICT " + target + " " + action
+	return b
+}
+
+func (b *InsertBuilder) Returning(columns ...string) *InsertBuilder {
+	b.returning = columns
+	return b
+}
+
+func (b *InsertBuilder) Build() (string, []interface{}) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(b.columns)), ", ")
+	sql := "INSERT INTO " + b.table + " (" + strings.Join(b.columns, ", ") + ") VALUES (" +
+		placeholders + ")" + b.onConflict
+	if len(b.returning) > 0 {
+		sql += " RETURNING " + strings.Join(b.returning, ", ")
+	}
+	return b.dialect.bind(sql), b.values
+}
+
+type UpdateBuilder struct {
+	dialect   Dialect
+	table     string
+	sets      []condition
+	where     []condition
+	returning []string
+}
+
+func (dialect Dialect) Update(table string) *UpdateBuilder {
+	return &UpdateBuilder{dialect: dialect, table: table}
+}
+
+func (b *UpdateBuilder) Set(column string, value interface{}) *UpdateBuilder {
+	b.sets = append(b.sets, condition{column + " = ?", []interface{}{value}})
+	return b
+}
+
+// Assigns an expression such as "version = version + 1" or "updated_at = now()"
+func (b *UpdateBuilder) SetExpr(sql string, args ...interface{}) *UpdateBuilder {
+	b.sets = append(b.sets, condition{sql, args})
+	return b
+}
+
+func (b *UpdateBuilder) Where(sql string, args ...interface{}) *UpdateBuilder {
+	b.where = append(b.where, condition{sql, args})
+	return b
+}
+
+func (b *UpdateBuilder) Returning(columns ...string) *UpdateBuilder {
+	b.returning = columns
+	return b
+}
+
+func (b *UpdateBuilder) Build() (string, []interface{}) {
+	var sets []string
+	var args []interface{}
+	for _, set := range b.sets {
+		sets = append(sets, set.sql)
+		args = append(args, set.args...)
+	}
+	sql := "UPDATE " + b.table + " SET " + strings.Join(sets, ", ")
+	where, whereArgs := whereClause(b.where)
+	sql += where
+	args = append(args, whereArgs...)
+	if len(b.returning) > 0 {
+		sql += " RETURNING " + strings.Join(b.returning, ", ")
+	}
+	return b.dialect.bind(sql), args
+}
+
+type DeleteBuilder struct {
+	dialect   Dialect
+	table     string
+	where     []condition
+	returning []string
+}
+
+func (dialect Dialect) Delete(table string) *DeleteBuilder {
+	return &DeleteBuilder{dialect: dialect, table: table}
+}
+
+func (b *DeleteBuilder) Where(sql string, args ...interface{}) *DeleteBuilder {
+	b.where = append(b.where, condition{sql, args})
+	return b
+}
+
+func (b *DeleteBuilder) Returning(columns ...string) *DeleteBuilder {
+	b.returning = columns
+	return b
+}
+
+func (b *DeleteBuilder) Build() (string, []interface{}) {
+	where, args := whereClause(b.where)
+	sql := "DELETE FROM " + b.table + where
+	if len(b.returning) > 0 {
+		sql += " RETURNING " + strings.Join(b.returning, ", ")
+	}
+	return b.dialect.bind(sql), args
+}
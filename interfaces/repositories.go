@@ -2,9 +2,15 @@ package interfaces
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"game-tracker/domain"
+	"game-tracker/interfaces/query"
 	"game-tracker/usecases"
 )
 
@@ -12,12 +18,97 @@ type DbHandler interface {
 	Execute(statement string, args ...interface{}) (sql.Result, error)
 	Query(statement string, args ...interface{}) (Row, error)
 	QueryRow(statement string, args ...interface{}) (int, error)
+	Dialect() query.Dialect
+	Transaction(fn func(tx DbHandler) error) error //Commits when fn returns nil
+}
+
+// Returned by handlers when a statement breaks a foreign key, Constraint is
+// the name the migrations gave it
+type ForeignKeyError struct {
+	Constraint string
+	Err        error
+}
+
+func (err *ForeignKeyError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *ForeignKeyError) Unwrap() error {
+	return err.Err
+}
+
+// Returned by handlers when a statement stores a value a unique index
+// already holds, Constraint is the name of the index
+type UniqueError struct {
+	Constraint string
+	Err        error
+}
+
+func (err *UniqueError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *UniqueError) Unwrap() error {
+	return err.Err
+}
+
+func violates(err error, constraint string) bool {
+	var foreignKeyErr *ForeignKeyError
+	if errors.As(err, &foreignKeyErr) {
+		return foreignKeyErr.Constraint == constraint
+	}
+	var uniqueErr *UniqueError
+	return errors.As(err, &uniqueErr) && uniqueErr.Constraint == constraint
+}
+
+// Usernames are unique, with and without case. Player names are unique
+// too, but storing a user finds the player of a taken name instead.
+func nameTakenError(err error, user usecases.User) error {
+	if violates(err, "users_user_name_idx") || violates(err, "users_user_name_key_idx") {
+		return &usecases.ErrAlreadyExists{Field: "name", Name: user.Name}
+	}
+	return err
+}
+
+// Handlers that stream many rows into a table in one round trip, Postgres
+// does it with COPY. Handlers without it get the rows inserted one by one.
+type BulkLoader interface {
+	CopyIn(table string, columns []string, rows [][]interface{}) error
+}
+
+func copyRows(handler DbHandler, table string, columns []string, rows [][]interface{}) error {
+	if loader, ok := handler.(BulkLoader); ok {
+		return loader.CopyIn(table, columns, rows)
+	}
+	for _, row := range rows {
+		insert := handler.Dialect().Insert(table)
+		for i, column := range columns {
+			insert.Set(column, row[i])
+		}
+		statement, args := insert.Build()
+		_, err := handler.Execute(statement, args...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The handler wired for one method of a repository, such as
+// "DbGameRepo.FindByLib" with a statement timeout of its own, or the
+// repository's handler when there is none. Methods whose statements a caller
+// can make slow look theirs up.
+func methodHandler(handlers map[string]DbHandler, method string, fallback DbHandler) DbHandler {
+	if handler, found := handlers[method]; found {
+		return handler
+	}
+	return fallback
 }
 
 type Row interface {
 	Scan(dest ...interface{}) error
 	Next() bool
-	Close() error
+	Close() error //Also returns the error that ended the rows early
 }
 
 type DbRepo struct {
@@ -25,153 +116,239 @@ type DbRepo struct {
 	dbHandler  DbHandler
 }
 
-type DbUserRepo DbRepo
 type DbPlayerRepo DbRepo
-type DbLibraryRepo DbRepo
+
+// Users carry their player, the player repo is handed in once at wiring
+// time and shared by every call
+type DbUserRepo struct {
+	DbRepo
+	players *DbPlayerRepo
+}
+
+// Libraries carry their owner
+type DbLibraryRepo struct {
+	DbRepo
+	users usecases.UserRepository
+}
+
 type DbGameRepo DbRepo
 type LoggerRepo DbRepo
 
-func NewDbUserRepo(dbHandlers map[string]DbHandler) *DbUserRepo {
+func NewDbUserRepo(dbHandlers map[string]DbHandler, players *DbPlayerRepo) *DbUserRepo {
 	dbUserRepo := new(DbUserRepo)
 	dbUserRepo.dbHandlers = dbHandlers
 	dbUserRepo.dbHandler = dbHandlers["DbUserRepo"]
+	dbUserRepo.players = players
 	return dbUserRepo
 }
 
+// Stores the player first so the user references the id the player
+// really has, both rows are written in one transaction
 func (repo DbUserRepo) Store(user usecases.User) (int, error) {
-	id, err := repo.dbHandler.QueryRow(`INSERT INTO users (user_name, player_id, personal_info)
-		VALUES ($1, $2, $3) RETURNING id`, user.Name, user.Player.Id, user.PersonalInfo)
-	if err != nil {
-		return 0, err
-	}
-
-	playerRepo := NewDbPlayerRepo(repo.dbHandlers)
-	err = playerRepo.Store(user.Player)
+	var id int
+	err := repo.dbHandler.Transaction(func(tx DbHandler) error {
+		playerId, err := storePlayer(tx, user.Player)
+		if err != nil {
+			return err
+		}
+		statement, args := tx.Dialect().Insert("users").Set("user_name", user.Name).
+			Set("user_name_key", domain.NameKey(user.Name)).
+			Set("player_id", playerId).Set("personal_info", user.PersonalInfo).
+			Returning("id").Build()
+		id, err = tx.QueryRow(statement, args...)
+		if err != nil {
+			return err
+		}
+		return logUserChange(tx, id, usecases.ChangeCreated)
+	})
 	if err != nil {
-		return id, err
+		return 0, nameTakenError(err, user)
 	}
 	return id, nil
 }
 
+// The removal is only logged once the foreign keys let the row go
 func (repo DbUserRepo) Remove(user usecases.User) error {
-	_, err := repo.dbHandler.Execute(`DELETE FROM users WHERE id=$1`, user.Id)
+	err := repo.dbHandler.Transaction(func(tx DbHandler) error {
+		return removeLogged(tx, `DELETE FROM users WHERE id = $1 RETURNING id, external_id`,
+			"user", user.Id)
+	})
+	if violates(err, "libraries_user_id_fkey") {
+		return domain.NewError(domain.CodeConflict, "User #%d still has libraries", user.Id)
+	}
 	return err
 }
 
 func (repo DbUserRepo) FindById(id int) (usecases.User, error, int) {
-	row, err := repo.dbHandler.Query(`SELECT user_name, player_id, personal_info FROM users
-		WHERE id = $1 LIMIT 1`, id)
+	statement, args := repo.dbHandler.Dialect().Select("external_id", "user_name", "player_id",
+		"personal_info", "version", "role", "status", "status_reason", "suspended_until",
+		"created_at", "updated_at").From("users").Where("id = ?", id).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
 	if err != nil {
 		return usecases.User{}, err, 500
 	}
+	var externalId string
 	var userName string
 	var playerId int
-	var personalInfo string
+	var personalInfo sql.NullString
+	var version int64
+	var role, status, statusReason string
+	var suspendedUntil sql.NullTime
+	var createdAt, updatedAt time.Time
 	defer row.Close()
 	row.Next()
-	err = row.Scan(&userName, &playerId, &personalInfo)
+	err = row.Scan(&externalId, &userName, &playerId, &personalInfo, &version, &role, &status,
+		&statusReason, &suspendedUntil, &createdAt, &updatedAt)
 	if err != nil {
 		return usecases.User{}, err, 404
 	}
 
-	playerRepo := NewDbPlayerRepo(repo.dbHandlers)
-	player, err, code := playerRepo.FindById(playerId)
+	player, err, code := repo.players.FindById(playerId)
 	if err != nil {
 		return usecases.User{}, err, code
 	}
 
-	user := usecases.User{Id: id, Name: userName, Player: player, PersonalInfo: personalInfo}
+	user := usecases.User{Id: id, ExternalId: externalId, Name: userName, Player: player,
+		PersonalInfo: personalInfo.String, Version: version, Role: role, Status: status,
+		StatusReason: statusReason, SuspendedUntil: nullTime(suspendedUntil), CreatedAt: createdAt,
+		UpdatedAt: updatedAt}
 
 	var libraryId int
-	row, err = repo.dbHandler.Query(`SELECT id FROM libraries WHERE user_id = $1`, id)
+	var libraryExternalId string
+	statement, args = repo.dbHandler.Dialect().Select("id", "external_id").From("libraries").
+		Where("user_id = ?", id).OrderBy("id").Build()
+	row, err = repo.dbHandler.Query(statement, args...)
 	if err != nil {
 		return user, err, 500
 	}
 	defer row.Close()
 	for row.Next() {
-		err = row.Scan(&libraryId)
+		err = row.Scan(&libraryId, &libraryExternalId)
 		if err != nil {
 			return user, err, 500
 		}
 		user.LibraryIds = append(user.LibraryIds, libraryId)
+		user.LibraryExternalIds = append(user.LibraryExternalIds, libraryExternalId)
 	}
 	return user, nil, 200
 }
 
-func (repo DbUserRepo) UserExisted(userName string) (bool, error) {
-	row, err := repo.dbHandler.Query(`SELECT user_name FROM users
-		WHERE user_name=$1 LIMIT 1`, userName)
-	defer row.Close()
-	return row.Next(), err
+func (repo DbUserRepo) FindByExternalId(externalId string) (usecases.User, error, int) {
+	id, err, code := findIdByExternalId(repo.dbHandler, "users", externalId)
+	if err != nil {
+		return usecases.User{}, err, code
+	}
+	return repo.FindById(id)
 }
 
-func (repo DbUserRepo) StoreInfo(user usecases.User, info string) error {
-	_, err := repo.dbHandler.Execute(`UPDATE users SET personal_info=$1
-		WHERE id=$2`, info, user.Id)
-	return err
+func (repo DbUserRepo) FindByName(name string, ignoreCase bool) (usecases.User, error, int) {
+	column := "user_name"
+	if ignoreCase {
+		column, name = "user_name_key", domain.NameKey(name)
+	}
+	id, err, code := findIdByName(repo.dbHandler, "users", column, name, false)
+	if err != nil {
+		return usecases.User{}, err, code
+	}
+	return repo.FindById(id)
 }
 
-func (repo DbUserRepo) LoadInfo(user usecases.User) (string, error) {
-	row, err := repo.dbHandler.Query(`SELECT personal_info FROM users WHERE id=$1`, user.Id)
+func (repo DbUserRepo) UserExisted(userName string) (bool, error) {
+	statement, args := repo.dbHandler.Dialect().Select("user_name").From("users").
+		Where("user_name_key = ?", domain.NameKey(userName)).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
 	if err != nil {
-		return "", err
+		return false, err
 	}
-	var info string
 	defer row.Close()
-	row.Next()
-	err = row.Scan(&info)
-	return info, err
+	return row.Next(), err
 }
 
-func (repo DbUserRepo) PlayerNameMatchesId(user usecases.User) (bool, error) {
-	playerRepo := NewDbPlayerRepo(repo.dbHandlers)
-	existed, err := playerRepo.playerExisted(user.Player.Name)
+func (repo DbUserRepo) StoreInfo(user usecases.User, info string, baseVersion int64) (int64, bool, error) {
+	update := repo.dbHandler.Dialect().Update("users").Set("personal_info", info).
+		SetExpr("version = version + 1").SetExpr("updated_at = now()").Where("id = ?", user.Id).
+		Returning("version")
+	if baseVersion != 0 {
+		update.Where("version = ?", baseVersion)
+	}
+	statement, args := update.Build()
+	row, err := repo.dbHandler.Query(statement, args...)
 	if err != nil {
-		return false, err
+		return 0, false, err
 	}
-	if existed {
-		match, err := playerRepo.nameMatchesId(user.Player.Name, user.Player.Id)
+	defer row.Close()
+	if !row.Next() {
+		return 0, false, nil
+	}
+	var version int64
+	err = row.Scan(&version)
+	if err != nil {
+		return 0, true, err
+	}
+	return version, true, logUserChange(repo.dbHandler, user.Id, usecases.ChangeUpdated)
+}
+
+// The login is stored under the username, so both are renamed together
+func (repo DbUserRepo) Rename(user usecases.User, name string) error {
+	err := repo.dbHandler.Transaction(func(tx DbHandler) error {
+		statement, args := tx.Dialect().Update("users").Set("user_name", name).
+			Set("user_name_key", domain.NameKey(name)).SetExpr("updated_at = now()").
+			Where("id = ?", user.Id).Build()
+		_, err := tx.Execute(statement, args...)
 		if err != nil {
-			return false, err
+			return err
 		}
-		if !match {
-			return false, nil
+		statement, args = tx.Dialect().Update("loginInfo").Set("username", name).
+			Where("username = ?", user.Name).Build()
+		_, err = tx.Execute(statement, args...)
+		if err != nil {
+			return err
 		}
+		return logUserChange(tx, user.Id, usecases.ChangeUpdated)
+	})
+	user.Name = name
+	return nameTakenError(err, user)
+}
+
+func (repo DbUserRepo) LoadInfo(user usecases.User) (string, error) {
+	statement, args := repo.dbHandler.Dialect().Select("personal_info").From("users").
+		Where("id = ?", user.Id).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return "", err
 	}
-	return true, nil
+	var info sql.NullString
+	defer row.Close()
+	row.Next()
+	err = row.Scan(&info)
+	return info.String, err
 }
 
 func (repo DbUserRepo) AddLoginInfo(username, password string) error {
-	_, err := repo.dbHandler.Execute(`INSERT INTO loginInfo (username, password)
-		VALUES ($1, $2)`, username, password)
+	statement, args := repo.dbHandler.Dialect().Insert("loginInfo").Set("username", username).
+		Set("password", password).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (repo DbUserRepo) FindLoginId(username, password string) (int, bool, error) {
-	row, err := repo.dbHandler.Query(`SELECT id FROM loginInfo WHERE username=$1
-		AND password=$2 LIMIT 1`, username, password)
+func (repo DbUserRepo) CheckLogin(username, password string) (bool, error) {
+	statement, args := repo.dbHandler.Dialect().Select("id").From("loginInfo").
+		Where("username = ?", username).Where("password = ?", password).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
 	if err != nil {
-		return 0, false, err
+		return false, err
 	}
-
-	var id int
 	defer row.Close()
-	exist := row.Next()
-	if !exist {
-		return 0, false, nil
-	}
-	err = row.Scan(&id)
-	if err != nil {
-		return 0, true, err
-	}
-	return id, true, nil
+	return row.Next(), nil
 }
 
 func (repo DbUserRepo) RemoveLoginInfo(user usecases.User) error {
-	_, err := repo.dbHandler.Execute(`DELETE FROM loginInfo WHERE username=$1`, user.Name)
+	statement, args := repo.dbHandler.Dialect().Delete("loginInfo").
+		Where("username = ?", user.Name).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
 	return err
 }
 
@@ -182,102 +359,149 @@ func NewDbPlayerRepo(dbHandlers map[string]DbHandler) *DbPlayerRepo {
 	return dbPlayerRepo
 }
 
-func (repo DbPlayerRepo) Store(player domain.Player) error {
-	existed, err := repo.playerExisted(player.Name)
-	if err != nil {
-		return err
-	}
-	if existed {
-		return nil
-	}
-	_, err = repo.dbHandler.Execute(`INSERT INTO players (player_name)
-		VALUES ($1)`, player.Name)
-	return err
+func (repo DbPlayerRepo) Store(player domain.Player) (int, error) {
+	return storePlayer(repo.dbHandler, player)
+}
+
+// Inserts the player unless the name is taken, either way returns its id.
+// The no-op update makes RETURNING yield the existing row on conflict.
+func storePlayer(dbHandler DbHandler, player domain.Player) (int, error) {
+	statement, args := dbHandler.Dialect().Insert("players").Set("player_name", player.Name).
+		OnConflict("(player_name)", "DO UPDATE SET player_name = EXCLUDED.player_name").
+		Returning("id").Build()
+	return dbHandler.QueryRow(statement, args...)
 }
 
 func (repo DbPlayerRepo) FindById(id int) (domain.Player, error, int) {
-	row, err := repo.dbHandler.Query(`SELECT player_name FROM players WHERE id = $1 LIMIT 1`, id)
+	statement, args := repo.dbHandler.Dialect().Select("player_name", "created_at", "updated_at").
+		From("players").Where("id = ?", id).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
 	if err != nil {
 		return domain.Player{}, err, 500
 	}
 	var name string
+	var createdAt, updatedAt time.Time
 	defer row.Close()
 	row.Next()
-	err = row.Scan(&name)
+	err = row.Scan(&name, &createdAt, &updatedAt)
 	if err != nil {
 		return domain.Player{}, err, 404
 	}
-	return domain.Player{Id: id, Name: name}, nil, 200
+	return domain.Player{Id: id, Name: name, CreatedAt: createdAt, UpdatedAt: updatedAt}, nil, 200
 }
 
-func (repo DbPlayerRepo) playerExisted(playerName string) (bool, error) {
-	row, err := repo.dbHandler.Query(`SELECT player_name FROM players
-		WHERE player_name=$1 LIMIT 1`, playerName)
-	defer row.Close()
-	return row.Next(), err
-}
-
-func (repo DbPlayerRepo) nameMatchesId(playerName string, id int) (bool, error) {
-	row, err := repo.dbHandler.Query(`SELECT * FROM players
-		WHERE id=$1 AND player_name=$2 LIMIT 1`, id, playerName)
-	defer row.Close()
-	return row.Next(), err
+func (repo DbPlayerRepo) FindByName(name string, ignoreCase bool) (domain.Player, error, int) {
+	id, err, code := findIdByName(repo.dbHandler, "players", "player_name", name, ignoreCase)
+	if err != nil {
+		return domain.Player{}, err, code
+	}
+	return repo.FindById(id)
 }
 
-func NewDbLibraryRepo(dbHandlers map[string]DbHandler) *DbLibraryRepo {
+func NewDbLibraryRepo(dbHandlers map[string]DbHandler, users usecases.UserRepository) *DbLibraryRepo {
 	dbLibraryRepo := new(DbLibraryRepo)
 	dbLibraryRepo.dbHandlers = dbHandlers
 	dbLibraryRepo.dbHandler = dbHandlers["DbLibraryRepo"]
+	dbLibraryRepo.users = users
 	return dbLibraryRepo
 }
 
 func (repo DbLibraryRepo) Store(library usecases.Library) (int, error) {
-	id, err := repo.dbHandler.QueryRow(`INSERT INTO libraries (user_id) VALUES ($1) RETURNING id`,
-		library.User.Id)
-	return id, err
+	statement, args := repo.dbHandler.Dialect().Insert("libraries").Set("user_id", library.User.Id).
+		Returning("id").Build()
+	id, err := repo.dbHandler.QueryRow(statement, args...)
+	if violates(err, "libraries_user_id_fkey") {
+		return 0, domain.NewError(domain.CodeConflict, "User #%d does not exist", library.User.Id)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return id, logLibraryChange(repo.dbHandler, id, usecases.ChangeCreated)
 }
 
 func (repo DbLibraryRepo) Remove(library usecases.Library) error {
-	_, err := repo.dbHandler.Execute(`DELETE FROM libraries WHERE id=$1`, library.Id)
-	return err
+	return repo.dbHandler.Transaction(func(tx DbHandler) error {
+		return removeLogged(tx, `DELETE FROM libraries WHERE id = $1 RETURNING user_id, external_id`,
+			"library", library.Id)
+	})
 }
 
 func (repo DbLibraryRepo) FindById(id int) (usecases.Library, error, int) {
-	row, err := repo.dbHandler.Query(`SELECT user_id FROM libraries WHERE id = $1 LIMIT 1`, id)
+	statement, args := repo.dbHandler.Dialect().Select("external_id", "user_id", "version",
+		"created_at", "updated_at").From("libraries").Where("id = ?", id).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
 	if err != nil {
 		return usecases.Library{}, err, 500
 	}
 
+	var externalId string
 	var userId int
+	var version int64
+	var createdAt, updatedAt time.Time
 	defer row.Close()
 	row.Next()
-	err = row.Scan(&userId)
+	err = row.Scan(&externalId, &userId, &version, &createdAt, &updatedAt)
 	if err != nil {
 		return usecases.Library{}, err, 404
 	}
-	userRepo := NewDbUserRepo(repo.dbHandlers)
-	user, err, code := userRepo.FindById(userId)
+	user, err, code := repo.users.FindById(userId)
 	if err != nil {
 		return usecases.Library{}, err, code
 	}
-	library := usecases.Library{Id: id, User: user}
+	library := usecases.Library{Id: id, ExternalId: externalId, User: user, Version: version,
+		CreatedAt: createdAt, UpdatedAt: updatedAt}
 
 	var gameId int
-	row, err = repo.dbHandler.Query(`SELECT id FROM gamesInLib WHERE library_id = $1`, library.Id)
+	var gameExternalId string
+	statement, args = repo.dbHandler.Dialect().Select("games.id", "games.external_id").
+		From("gamesInLib").Join("games", "games.id = gamesInLib.game_id").
+		Where("gamesInLib.library_id = ?", library.Id).Build()
+	row, err = repo.dbHandler.Query(statement, args...)
 	if err != nil {
 		return library, err, 500
 	}
 	defer row.Close()
 	for row.Next() {
-		err = row.Scan(&gameId)
+		err = row.Scan(&gameId, &gameExternalId)
 		if err != nil {
 			return library, err, 404
 		}
 		library.GameIds = append(library.GameIds, gameId)
+		library.GameExternalIds = append(library.GameExternalIds, gameExternalId)
 	}
 	return library, err, 200
 }
 
+// Loads only what sync clients need to tell whether a library changed
+func (repo DbLibraryRepo) FindVersionsByUser(userId int) ([]usecases.Library, error) {
+	statement, args := repo.dbHandler.Dialect().Select("id", "external_id", "version", "updated_at").
+		From("libraries").Where("user_id = ?", userId).OrderBy("id").Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var libraries []usecases.Library
+	for row.Next() {
+		library := usecases.Library{}
+		err = row.Scan(&library.Id, &library.ExternalId, &library.Version, &library.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		libraries = append(libraries, library)
+	}
+	return libraries, nil
+}
+
+func (repo DbLibraryRepo) FindByExternalId(externalId string) (usecases.Library, error, int) {
+	id, err, code := findIdByExternalId(repo.dbHandler, "libraries", externalId)
+	if err != nil {
+		return usecases.Library{}, err, code
+	}
+	return repo.FindById(id)
+}
+
 func NewDbGameRepo(dbHandlers map[string]DbHandler) *DbGameRepo {
 	dbGameRepo := new(DbGameRepo)
 	dbGameRepo.dbHandlers = dbHandlers
@@ -288,14 +512,16 @@ func NewDbGameRepo(dbHandlers map[string]DbHandler) *DbGameRepo {
 func (repo DbGameRepo) Store(game usecases.Game) (int, error) {
 	id, existed, err := repo.gameExisted(game.Name)
 	if !existed {
-		id, err = repo.dbHandler.QueryRow(`INSERT INTO games (name, producer, value)
-    	VALUES ($1, $2, $3) RETURNING id`, game.Name, game.Producer, game.Value)
+		statement, args := repo.dbHandler.Dialect().Insert("games").Set("name", game.Name).
+			Set("producer", game.Producer).Set("value", game.Value).Set("min_age", game.MinAge).
+			Set("rating", game.Rating).Returning("id").Build()
+		id, err = repo.dbHandler.QueryRow(statement, args...)
 		return id, err
 	}
 	return id, nil
 }
 
-func (repo DbGameRepo) AddToLib(gameId, libraryId int) (error, int) {
+func (repo DbGameRepo) AddToLib(gameId, libraryId int, baseVersion int64) (error, int) {
 	existed, err := repo.gameExistedInLib(gameId, libraryId)
 	if err != nil {
 		return err, 500
@@ -304,23 +530,602 @@ func (repo DbGameRepo) AddToLib(gameId, libraryId int) (error, int) {
 		err = fmt.Errorf("Game already existed in library")
 		return err, 400
 	}
-	_, err = repo.dbHandler.Execute(`INSERT INTO gamesInLib (game_id, library_id)
-		VALUES ($1, $2)`, gameId, libraryId)
+	code := 500
+	err = repo.dbHandler.Transaction(func(tx DbHandler) error {
+		_, err := tx.Execute(`INSERT INTO gamesInLib (game_id, library_id) VALUES ($1, $2)`,
+			gameId, libraryId)
+		if err != nil {
+			err, code = entryConstraintError(err, libraryId)
+			return err
+		}
+		err, code = bumpLibrary(tx, libraryId, baseVersion)
+		if err != nil {
+			return err
+		}
+		return logGameChange(tx, libraryId, gameId, usecases.ChangeCreated)
+	})
+	if err != nil {
+		if code == 200 {
+			code = 500
+		}
+		return err, code
+	}
+	return nil, 200
+}
+
+// The games are copied into a temporary table and the names not stored yet
+// inserted from it in one statement, a name repeated in the batch is stored
+// once
+func (repo DbGameRepo) StoreBatch(games []usecases.Game) ([]usecases.Game, error) {
+	stored := make([]usecases.Game, len(games))
+	err := repo.dbHandler.Transaction(func(tx DbHandler) error {
+		_, err := tx.Execute(`CREATE TEMPORARY TABLE batch_games (batch_index INTEGER, name TEXT,
+			producer TEXT, value NUMERIC, min_age INTEGER, rating TEXT) ON COMMIT DROP`)
+		if err != nil {
+			return err
+		}
+		rows := make([][]interface{}, len(games))
+		for i, game := range games {
+			rows[i] = []interface{}{i, game.Name, game.Producer, game.Value, game.MinAge, game.Rating}
+		}
+		err = copyRows(tx, "batch_games", []string{"batch_index", "name", "producer", "value",
+			"min_age", "rating"}, rows)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Execute(`INSERT INTO games (name, producer, value, min_age, rating)
+			SELECT DISTINCT ON (name) name, producer, value, min_age, rating FROM batch_games
+			WHERE NOT EXISTS (SELECT 1 FROM games WHERE games.name = batch_games.name)
+			ORDER BY name, batch_index`)
+		if err != nil {
+			return err
+		}
+
+		row, err := tx.Query(`SELECT batch_games.batch_index, stored.id, stored.external_id,
+				stored.name, stored.producer, stored.value, stored.min_age, stored.rating,
+				stored.created_at, stored.updated_at, stored.contains_spoilers
+			FROM batch_games JOIN LATERAL (SELECT * FROM games WHERE games.name = batch_games.name
+				ORDER BY games.id LIMIT 1) stored ON true`)
+		if err != nil {
+			return err
+		}
+		defer row.Close()
+		for row.Next() {
+			var index int
+			var game usecases.Game
+			err = row.Scan(&index, &game.Id, &game.ExternalId, &game.Name, &game.Producer, &game.Value,
+				&game.MinAge, &game.Rating, &game.CreatedAt, &game.UpdatedAt, &game.ContainsSpoilers)
+			if err != nil {
+				return err
+			}
+			stored[index] = game
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// One version bump and one statement of changes cover the whole batch
+func (repo DbGameRepo) AddBatchToLib(libraryId int, entries []usecases.Game) ([]int, error) {
+	var added []int
+	err := repo.dbHandler.Transaction(func(tx DbHandler) error {
+		_, err := tx.Execute(`CREATE TEMPORARY TABLE batch_entries (game_id INTEGER, status TEXT,
+			platform TEXT) ON COMMIT DROP`)
+		if err != nil {
+			return err
+		}
+		rows := make([][]interface{}, len(entries))
+		for i, entry := range entries {
+			status := entry.Status
+			if status == "" {
+				status = usecases.DefaultGameStatus
+			}
+			rows[i] = []interface{}{entry.Id, status, entry.Platform}
+		}
+		err = copyRows(tx, "batch_entries", []string{"game_id", "status", "platform"}, rows)
+		if err != nil {
+			return err
+		}
+
+		row, err := tx.Query(`INSERT INTO gamesInLib (game_id, library_id, status, platform)
+			SELECT DISTINCT ON (game_id) game_id, $1, status, platform FROM batch_entries
+			WHERE NOT EXISTS (SELECT 1 FROM gamesInLib WHERE gamesInLib.library_id = $1
+				AND gamesInLib.game_id = batch_entries.game_id)
+			ORDER BY game_id
+			RETURNING game_id`, libraryId)
+		if err != nil {
+			return err
+		}
+		for row.Next() {
+			var gameId int
+			err = row.Scan(&gameId)
+			if err != nil {
+				row.Close()
+				return err
+			}
+			added = append(added, gameId)
+		}
+		err = row.Close()
+		if err != nil {
+			return err
+		}
+		if len(added) == 0 {
+			return nil
+		}
+
+		statement, args := tx.Dialect().Update("libraries").SetExpr("version = version + 1").
+			SetExpr("updated_at = now()").Where("id = ?", libraryId).Build()
+		_, err = tx.Execute(statement, args...)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Execute(`INSERT INTO changes (user_id, entity, entity_id, parent_id, action)
+			SELECT libraries.user_id, 'game', games.external_id, libraries.external_id, $3
+			FROM libraries JOIN games ON games.id = ANY($2::int[]) WHERE libraries.id = $1`,
+			libraryId, intArray(added), usecases.ChangeCreated)
+		return err
+	})
+	if err != nil {
+		err, _ = entryConstraintError(err, libraryId)
+		return nil, err
+	}
+	return added, nil
+}
+
+// Removing a game that is not in the library changes nothing and is not
+// checked against baseVersion
+func (repo DbGameRepo) RemoveFromLib(game usecases.Game, libraryId int, baseVersion int64) (error, int) {
+	code := 500
+	err := repo.dbHandler.Transaction(func(tx DbHandler) error {
+		removed, err := tx.Execute(`DELETE FROM gamesInLib WHERE game_id=$1 AND library_id=$2`,
+			game.Id, libraryId)
+		if err != nil {
+			return err
+		}
+		count, err := removed.RowsAffected()
+		if err != nil || count == 0 {
+			return err
+		}
+		err, code = bumpLibrary(tx, libraryId, baseVersion)
+		if err != nil {
+			return err
+		}
+		return logGameChange(tx, libraryId, game.Id, usecases.ChangeDeleted)
+	})
+	if err != nil {
+		if code == 200 {
+			code = 500
+		}
+		return err, code
+	}
+	return nil, 200
+}
+
+// Bumps the version of the library, unless baseVersion is not 0 and the
+// library is past it. The row stays locked until tx ends, so of two edits
+// based on the same version only the first one goes through.
+func bumpLibrary(tx DbHandler, libraryId int, baseVersion int64) (error, int) {
+	update := tx.Dialect().Update("libraries").SetExpr("version = version + 1").
+		SetExpr("updated_at = now()").Where("id = ?", libraryId)
+	if baseVersion != 0 {
+		update.Where("version = ?", baseVersion)
+	}
+	statement, args := update.Build()
+	bumped, err := tx.Execute(statement, args...)
 	if err != nil {
 		return err, 500
 	}
+	count, err := bumped.RowsAffected()
+	if err != nil {
+		return err, 500
+	}
+	if count == 0 {
+		return staleLibraryError(libraryId, baseVersion), 409
+	}
 	return nil, 200
 }
 
-func (repo DbGameRepo) RemoveFromLib(game usecases.Game, libraryId int) error {
-	_, err := repo.dbHandler.Execute(`DELETE FROM gamesInLib WHERE game_id=$1 AND library_id=$2`,
-		game.Id, libraryId)
+func staleLibraryError(libraryId int, baseVersion int64) error {
+	return domain.NewError(domain.CodeConflict,
+		"Library #%d was changed elsewhere, version %d is stale", libraryId, baseVersion)
+}
+
+func (repo DbGameRepo) FindInLib(gameId, libraryId int) (usecases.Game, error, int) {
+	game, err, code := repo.FindById(gameId)
+	if err != nil {
+		return game, err, code
+	}
+	statement, args := repo.dbHandler.Dialect().Select("status", "platform",
+		"array_to_json(tags)", "wishlist_rank", "version").From("gamesInLib").
+		Where("game_id = ?", gameId).Where("library_id = ?", libraryId).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return usecases.Game{}, err, 500
+	}
+	defer row.Close()
+	if !row.Next() {
+		return usecases.Game{}, fmt.Errorf("Game #%d is not in library #%d", gameId, libraryId), 404
+	}
+	var tags string
+	err = row.Scan(&game.Status, &game.Platform, &tags, &game.WishlistRank, &game.Version)
+	if err == nil {
+		err = json.Unmarshal([]byte(tags), &game.Tags)
+	}
+	if err != nil {
+		return usecases.Game{}, err, 500
+	}
+	return game, nil, 200
+}
+
+func (repo DbGameRepo) UpdateBatch(libraryId int, gameIds []int, change usecases.GameChange, baseVersions map[int]int64) (map[int]int64, error) {
+	versions := make([]int64, len(gameIds))
+	for i, gameId := range gameIds {
+		versions[i] = baseVersions[gameId]
+	}
+	update := repo.dbHandler.Dialect().Update("gamesInLib").SetExpr("version = version + 1").
+		SetExpr("updated_at = now()").Where("library_id = ?", libraryId).
+		Where("game_id = ANY(?::int[])", intArray(gameIds)).
+		Where(`EXISTS (SELECT 1 FROM unnest(?::int[], ?::bigint[]) AS base (game_id, version)
+			WHERE base.game_id = gamesInLib.game_id
+				AND (base.version = 0 OR base.version = gamesInLib.version))`,
+			intArray(gameIds), int64Array(versions)).
+		Returning("game_id", "version")
+	if change.Status != nil {
+		update.Set("status", *change.Status)
+	}
+	if change.Platform != nil {
+		update.Set("platform", *change.Platform)
+	}
+	if change.Tags != nil {
+		tags, err := json.Marshal(*change.Tags)
+		if err != nil {
+			return nil, err
+		}
+		update.SetExpr("tags = ARRAY(SELECT json_array_elements_text(?::json))", string(tags))
+	}
+	statement, args := update.Build()
+
+	updated := make(map[int]int64)
+	err := repo.dbHandler.Transaction(func(tx DbHandler) error {
+		row, err := tx.Query(statement, args...)
+		if err != nil {
+			return err
+		}
+		for row.Next() {
+			var gameId int
+			var version int64
+			err = row.Scan(&gameId, &version)
+			if err != nil {
+				row.Close()
+				return err
+			}
+			updated[gameId] = version
+		}
+		err = row.Close()
+		if err != nil || len(updated) == 0 {
+			return err
+		}
+		err, _ = bumpLibrary(tx, libraryId, 0)
+		if err != nil {
+			return err
+		}
+		for gameId := range updated {
+			err = logGameChange(tx, libraryId, gameId, usecases.ChangeUpdated)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (repo DbGameRepo) RankWishlist(libraryId int, ranks map[int]int) error {
+	var gameIds, positions []int
+	for gameId, rank := range ranks {
+		gameIds = append(gameIds, gameId)
+		positions = append(positions, rank)
+	}
+	return repo.dbHandler.Transaction(func(tx DbHandler) error {
+		_, err := tx.Execute(`UPDATE gamesInLib SET wishlist_rank = ranked.rank,
+				version = gamesInLib.version + 1, updated_at = now()
+			FROM unnest($2::int[], $3::int[]) AS ranked (game_id, rank)
+			WHERE gamesInLib.library_id = $1 AND gamesInLib.game_id = ranked.game_id`,
+			libraryId, intArray(gameIds), intArray(positions))
+		if err != nil {
+			return err
+		}
+		bump, bumpArgs := tx.Dialect().Update("libraries").SetExpr("version = version + 1").
+			SetExpr("updated_at = now()").Where("id = ?", libraryId).Build()
+		_, err = tx.Execute(bump, bumpArgs...)
+		if err != nil {
+			return err
+		}
+		for _, gameId := range gameIds {
+			err = logGameChange(tx, libraryId, gameId, usecases.ChangeUpdated)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (repo DbGameRepo) FindTags(libraryIds []int) ([]usecases.TagCount, error) {
+	row, err := repo.dbHandler.Query(`SELECT tag, count(*) FROM gamesInLib, unnest(gamesInLib.tags) AS tag
+		WHERE gamesInLib.library_id = ANY($1::int[]) GROUP BY tag ORDER BY tag`, intArray(libraryIds))
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+	tags := []usecases.TagCount{}
+	for row.Next() {
+		var tag usecases.TagCount
+		err = row.Scan(&tag.Tag, &tag.Games)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// Tags are replaced in place, an entry that already carries to keeps it
+// where it came first
+func (repo DbGameRepo) ReplaceTags(libraryIds []int, from []string, to string) (map[int]int, error) {
+	fromJson, err := json.Marshal(from)
+	if err != nil {
+		return nil, err
+	}
+	changed := make(map[int]int)
+	err = repo.dbHandler.Transaction(func(tx DbHandler) error {
+		row, err := tx.Query(`UPDATE gamesInLib SET version = version + 1, updated_at = now(), tags = ARRAY(
+				SELECT tag FROM (
+					SELECT CASE WHEN tag = ANY(ARRAY(SELECT json_array_elements_text($2::json)))
+						THEN $3 ELSE tag END AS tag, min(position) AS position
+					FROM unnest(gamesInLib.tags) WITH ORDINALITY AS entry (tag, position) GROUP BY 1
+				) AS replaced ORDER BY position)
+			WHERE library_id = ANY($1::int[])
+				AND tags && ARRAY(SELECT json_array_elements_text($2::json))
+			RETURNING library_id, game_id`, intArray(libraryIds), string(fromJson), to)
+		if err != nil {
+			return err
+		}
+		games := make(map[int][]int)
+		for row.Next() {
+			var libraryId, gameId int
+			err = row.Scan(&libraryId, &gameId)
+			if err != nil {
+				row.Close()
+				return err
+			}
+			games[libraryId] = append(games[libraryId], gameId)
+		}
+		row.Close()
+
+		for libraryId, gameIds := range games {
+			bump, bumpArgs := tx.Dialect().Update("libraries").SetExpr("version = version + 1").
+				SetExpr("updated_at = now()").Where("id = ?", libraryId).Build()
+			_, err = tx.Execute(bump, bumpArgs...)
+			if err != nil {
+				return err
+			}
+			for _, gameId := range gameIds {
+				err = logGameChange(tx, libraryId, gameId, usecases.ChangeUpdated)
+				if err != nil {
+					return err
+				}
+			}
+			changed[libraryId] = len(gameIds)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// Entries of gameIds missing from entries are deleted, the others are
+// updated in place or inserted when they were removed
+func (repo DbGameRepo) RestoreEntries(libraryId int, gameIds []int, entries []usecases.LibraryEntry) error {
+	data, err := json.Marshal(entriesData(entries))
+	if err != nil {
+		return err
+	}
+	var restored []int
+	for _, entry := range entries {
+		restored = append(restored, entry.GameId)
+	}
+	err = repo.dbHandler.Transaction(func(tx DbHandler) error {
+		removed, err := queryIds(tx, `DELETE FROM gamesInLib WHERE library_id = $1
+				AND game_id = ANY($2::int[]) AND NOT game_id = ANY($3::int[])
+			RETURNING game_id`, libraryId, intArray(gameIds), intArray(restored))
+		if err != nil {
+			return err
+		}
+		updated, err := queryIds(tx, `UPDATE gamesInLib SET status = entry.status,
+				platform = entry.platform, tags = ARRAY(SELECT json_array_elements_text(entry.tags)),
+				wishlist_rank = entry."wishlistRank", version = gamesInLib.version + 1,
+				updated_at = now()
+			FROM json_to_recordset($2::json) AS entry ("gameId" INTEGER, status TEXT,
+				platform TEXT, tags JSON, "wishlistRank" INTEGER)
+			WHERE gamesInLib.library_id = $1 AND gamesInLib.game_id = entry."gameId"
+			RETURNING gamesInLib.game_id`, libraryId, string(data))
+		if err != nil {
+			return err
+		}
+		added, err := queryIds(tx, `INSERT INTO gamesInLib (game_id, library_id, status, platform,
+				tags, wishlist_rank)
+			SELECT entry."gameId", $1, entry.status, entry.platform,
+				ARRAY(SELECT json_array_elements_text(entry.tags)), entry."wishlistRank"
+			FROM json_to_recordset($2::json) AS entry ("gameId" INTEGER, status TEXT,
+				platform TEXT, tags JSON, "wishlistRank" INTEGER)
+			WHERE NOT entry."gameId" = ANY($3::int[])
+			RETURNING game_id`, libraryId, string(data), intArray(updated))
+		if err != nil {
+			return err
+		}
+
+		bump, bumpArgs := tx.Dialect().Update("libraries").SetExpr("version = version + 1").
+			SetExpr("updated_at = now()").Where("id = ?", libraryId).Build()
+		_, err = tx.Execute(bump, bumpArgs...)
+		if err != nil {
+			return err
+		}
+		actions := []string{usecases.ChangeDeleted, usecases.ChangeUpdated, usecases.ChangeCreated}
+		for i, ids := range [][]int{removed, updated, added} {
+			for _, gameId := range ids {
+				err = logGameChange(tx, libraryId, gameId, actions[i])
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		err, _ = entryConstraintError(err, libraryId)
+	}
 	return err
 }
 
+// Entries reference their library and game, either may be removed while
+// they are written
+func entryConstraintError(err error, libraryId int) (error, int) {
+	if violates(err, "gamesinlib_library_id_fkey") {
+		return domain.NewError(domain.CodeConflict, "Library #%d was removed meanwhile", libraryId), 409
+	}
+	if violates(err, "gamesinlib_game_id_fkey") {
+		return domain.NewError(domain.CodeConflict,
+			"A game added to library #%d does not exist", libraryId), 409
+	}
+	return err, 500
+}
+
+// Escapes the wildcards of LIKE patterns, '\' is the escape character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Columns of the sort orders of game lists
+var gameSortColumns = map[string]string{
+	"value":   "games.value",
+	"rating":  "games.min_age",
+	"status":  "gamesInLib.status",
+	"updated": "gamesInLib.updated_at",
+	"rank":    "gamesInLib.wishlist_rank",
+}
+
+// The expression a game list sorted by sort orders by, of a column or of a
+// cursor's value. Unranked games have rank 0 and sort last both ways.
+func gameSortExpression(sort, operand string, descending bool) string {
+	if sort == "rank" && !descending {
+		return "COALESCE(NULLIF(" + operand + ", 0), 2147483647)"
+	}
+	return operand
+}
+
+// The value of the column a game list is sorted by
+func gameSortValue(game usecases.Game, sort string) interface{} {
+	switch sort {
+	case "value":
+		return game.Value
+	case "rating":
+		return game.MinAge
+	case "status":
+		return game.Status
+	case "updated":
+		return game.EntryUpdatedAt
+	}
+	return game.WishlistRank
+}
+
+func (repo DbGameRepo) FindByLib(libraryId int, filter usecases.GameFilter, page usecases.Page) ([]usecases.Game, error) {
+	after, err := page.After.Game(filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+	handler := methodHandler(repo.dbHandlers, "DbGameRepo.FindByLib", repo.dbHandler)
+	selection := handler.Dialect().Select("games.id", "games.external_id", "games.name",
+		"games.producer", "games.value", "games.min_age", "games.rating", "games.created_at",
+		"games.updated_at", "gamesInLib.status", "gamesInLib.platform",
+		"array_to_json(gamesInLib.tags)", "gamesInLib.wishlist_rank", "gamesInLib.version",
+		"gamesInLib.updated_at").
+		From("gamesInLib").Join("games", "games.id = gamesInLib.game_id").
+		Where("gamesInLib.library_id = ?", libraryId)
+	if filter.Rating != "" {
+		selection.Where("games.rating = ?", filter.Rating)
+	}
+	if filter.MaxAge > 0 {
+		selection.Where("games.min_age <= ?", filter.MaxAge)
+	}
+	if filter.Name != "" {
+		selection.Where(`games.name ILIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(filter.Name)+"%")
+	}
+	if filter.Status != "" {
+		selection.Where("gamesInLib.status = ?", filter.Status)
+	}
+	if filter.Platform != "" {
+		selection.Where("gamesInLib.platform = ?", filter.Platform)
+	}
+	if filter.Tag != "" {
+		selection.Where("? = ANY(gamesInLib.tags)", filter.Tag)
+	}
+	// Ties are broken by name and id, ascending whichever way the sort goes
+	order := []string{"games.name", "games.id"}
+	position := "(games.name, games.id) > (?, ?)"
+	sort := strings.TrimPrefix(filter.Sort, "-")
+	if column, found := gameSortColumns[sort]; found {
+		descending := strings.HasPrefix(filter.Sort, "-")
+		expression := gameSortExpression(sort, column, descending)
+		value := gameSortExpression(sort, "?", descending)
+		direction, beyond := "", " > "
+		if descending {
+			direction, beyond = " DESC", " < "
+		}
+		order = append([]string{expression + direction}, order...)
+		if !page.After.IsZero() {
+			sortValue := gameSortValue(after, sort)
+			selection.Where("("+expression+beyond+value+" OR ("+expression+" = "+value+" AND "+
+				position+"))", sortValue, sortValue, after.Name, after.Id)
+		}
+	} else if !page.After.IsZero() {
+		selection.Where(position, after.Name, after.Id)
+	}
+	statement, args := selection.OrderBy(order...).Limit(page.Limit).Build()
+	row, err := handler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var games []usecases.Game
+	for row.Next() {
+		var game usecases.Game
+		var tags string
+		err = row.Scan(&game.Id, &game.ExternalId, &game.Name, &game.Producer, &game.Value,
+			&game.MinAge, &game.Rating, &game.CreatedAt, &game.UpdatedAt, &game.Status,
+			&game.Platform, &tags, &game.WishlistRank, &game.Version, &game.EntryUpdatedAt)
+		if err == nil {
+			err = json.Unmarshal([]byte(tags), &game.Tags)
+		}
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, game)
+	}
+	return games, nil
+}
+
 func (repo DbGameRepo) gameExisted(name string) (int, bool, error) {
-	row, err := repo.dbHandler.Query(`SELECT id FROM games
-		WHERE name=$1 LIMIT 1`, name)
+	statement, args := repo.dbHandler.Dialect().Select("id").From("games").
+		Where("name = ?", name).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
 	if err != nil {
 		return 0, false, err
 	}
@@ -339,8 +1144,9 @@ func (repo DbGameRepo) gameExisted(name string) (int, bool, error) {
 }
 
 func (repo DbGameRepo) gameExistedInLib(gameId, libraryId int) (bool, error) {
-	row, err := repo.dbHandler.Query(`SELECT id FROM gamesInLib
-		WHERE game_id=$1 AND library_id=$2 LIMIT 1`, gameId, libraryId)
+	statement, args := repo.dbHandler.Dialect().Select("id").From("gamesInLib").
+		Where("game_id = ?", gameId).Where("library_id = ?", libraryId).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
 	if err != nil {
 		return false, err
 	}
@@ -349,29 +1155,137 @@ func (repo DbGameRepo) gameExistedInLib(gameId, libraryId int) (bool, error) {
 }
 
 func (repo DbGameRepo) FindById(id int) (usecases.Game, error, int) {
-	row, err := repo.dbHandler.Query(`SELECT name, producer, value FROM games
-    	WHERE id = $1 LIMIT 1`, id)
+	statement, args := repo.dbHandler.Dialect().Select("external_id", "name", "producer", "value",
+		"min_age", "rating", "created_at", "updated_at", "contains_spoilers").From("games").
+		Where("id = ?", id).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
 	if err != nil {
 		return usecases.Game{}, err, 500
 	}
 	var (
-		name     string
-		producer string
-		value    float64
+		externalId string
+		name       string
+		producer   string
+		value      float64
+		minAge     int
+		rating     string
+		createdAt  time.Time
+		updatedAt  time.Time
+		spoilers   bool
 	)
 
 	defer row.Close()
 	row.Next()
-	err = row.Scan(&name, &producer, &value)
+	err = row.Scan(&externalId, &name, &producer, &value, &minAge, &rating, &createdAt, &updatedAt,
+		&spoilers)
 	if err != nil {
 		return usecases.Game{}, err, 404
 	}
 
-	game := usecases.Game{Id: id, Name: name, Producer: producer, Value: value}
+	game := usecases.Game{Id: id, ExternalId: externalId, Name: name, Producer: producer,
+		Value: value, MinAge: minAge, Rating: rating, CreatedAt: createdAt, UpdatedAt: updatedAt,
+		ContainsSpoilers: spoilers}
 	return game, nil, 200
 }
 
+func (repo DbGameRepo) SetSpoilers(gameId int, containsSpoilers bool) error {
+	statement, args := repo.dbHandler.Dialect().Update("games").
+		Set("contains_spoilers", containsSpoilers).SetExpr("updated_at = now()").
+		Where("id = ?", gameId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbGameRepo) FindByExternalId(externalId string) (usecases.Game, error, int) {
+	id, err, code := findIdByExternalId(repo.dbHandler, "games", externalId)
+	if err != nil {
+		return usecases.Game{}, err, code
+	}
+	return repo.FindById(id)
+}
+
 func (repo LoggerRepo) Log(message string) error {
 	fmt.Println(message)
 	return nil
 }
+
+// Resolves the internal id behind an external id, table is never user supplied
+func findIdByExternalId(dbHandler DbHandler, table, externalId string) (int, error, int) {
+	statement, args := dbHandler.Dialect().Select("id").From(table).
+		Where("external_id = ?", externalId).Limit(1).Build()
+	row, err := dbHandler.Query(statement, args...)
+	if err != nil {
+		return 0, err, 500
+	}
+	defer row.Close()
+	if !row.Next() {
+		return 0, fmt.Errorf("No row in %s with id %s", table, externalId), 404
+	}
+	var id int
+	err = row.Scan(&id)
+	if err != nil {
+		return 0, err, 500
+	}
+	return id, nil, 200
+}
+
+// Names compared without case may match several rows, the oldest one wins
+func findIdByName(dbHandler DbHandler, table, column, name string, ignoreCase bool) (int, error, int) {
+	sel := dbHandler.Dialect().Select("id").From(table)
+	if ignoreCase {
+		sel.Where("lower("+column+") = lower(?)", name).OrderBy("id")
+	} else {
+		sel.Where(column+" = ?", name)
+	}
+	statement, args := sel.Limit(1).Build()
+	row, err := dbHandler.Query(statement, args...)
+	if err != nil {
+		return 0, err, 500
+	}
+	defer row.Close()
+	if !row.Next() {
+		return 0, fmt.Errorf("No row in %s named '%s'", table, name), 404
+	}
+	var id int
+	err = row.Scan(&id)
+	if err != nil {
+		return 0, err, 500
+	}
+	return id, nil, 200
+}
+
+// Runs a statement returning one id per row
+func queryIds(dbHandler DbHandler, statement string, args ...interface{}) ([]int, error) {
+	row, err := dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+	var ids []int
+	for row.Next() {
+		var id int
+		err = row.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, row.Close()
+}
+
+// Formats ids as a Postgres array literal, e.g. {1,2,3}
+func intArray(ids []int) string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = strconv.Itoa(id)
+	}
+	return "{" + strings.Join(values, ",") + "}"
+}
+
+func int64Array(numbers []int64) string {
+	values := make([]string, len(numbers))
+	for i, number := range numbers {
+		values[i] = strconv.FormatInt(number, 10)
+	}
+	return "{" + strings.Join(values, ",") + "}"
+}
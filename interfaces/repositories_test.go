@@ -0,0 +1,229 @@
+package interfaces_test
+
+import (
+	"errors"
+	"testing"
+
+	"game-tracker/domain"
+	"game-tracker/interfaces"
+	"game-tracker/testsupport"
+	"game-tracker/usecases"
+)
+
+func TestMain(m *testing.M) {
+	testsupport.Main(m)
+}
+
+func TestDbUserRepoStoreAndFind(t *testing.T) {
+	fixtures := testsupport.NewFixtures(t, testsupport.Postgres(t))
+	user := fixtures.User("alice")
+
+	found, err, code := fixtures.Users.FindByName("ALICE", true)
+	if err != nil {
+		t.Fatalf("FindByName: %v (%d)", err, code)
+	}
+	if found.Id != user.Id || found.Name != "alice" || found.Player.Name != "alice" {
+		t.Fatalf("FindByName returned %+v, want user #%d alice", found, user.Id)
+	}
+	_, err, code = fixtures.Users.FindById(user.Id + 1)
+	if code != 404 {
+		t.Fatalf("FindById of a missing user: %v (%d), want 404", err, code)
+	}
+}
+
+// Stored info reads back the same through both ways of loading it
+func TestDbUserRepoStoreInfoRoundTrip(t *testing.T) {
+	fixtures := testsupport.NewFixtures(t, testsupport.Postgres(t))
+	user := fixtures.User("alice")
+
+	version, stored, err := fixtures.Users.StoreInfo(user, "Plays on Sundays", user.Version)
+	if err != nil || !stored {
+		t.Fatalf("StoreInfo: %v, stored %v", err, stored)
+	}
+	info, err := fixtures.Users.LoadInfo(user)
+	if err != nil || info != "Plays on Sundays" {
+		t.Fatalf("LoadInfo: %q, %v, want the stored info", info, err)
+	}
+	found, err, _ := fixtures.Users.FindById(user.Id)
+	if err != nil || found.PersonalInfo != "Plays on Sundays" || found.Version != version {
+		t.Fatalf("FindById: %+v, %v, want the stored info at version %d", found, err, version)
+	}
+}
+
+func TestDbUserRepoStoreTakenName(t *testing.T) {
+	fixtures := testsupport.NewFixtures(t, testsupport.Postgres(t))
+	fixtures.User("alice")
+
+	_, err := fixtures.Users.Store(usecases.User{Name: "alice", Player: domain.Player{Name: "bob"}})
+	var exists *usecases.ErrAlreadyExists
+	if !errors.As(err, &exists) || exists.Field != "name" {
+		t.Fatalf("Store of a taken name: %v, want ErrAlreadyExists for name", err)
+	}
+}
+
+// A taken player name is not a conflict, the user joins that player
+func TestDbUserRepoStoreSharesPlayer(t *testing.T) {
+	fixtures := testsupport.NewFixtures(t, testsupport.Postgres(t))
+	alice := fixtures.User("alice")
+
+	id, err := fixtures.Users.Store(usecases.User{Name: "bob", Player: domain.Player{Name: "alice"}})
+	if err != nil {
+		t.Fatalf("Store with a taken player name: %v", err)
+	}
+	bob, err, _ := fixtures.Users.FindById(id)
+	if err != nil || bob.Player.Id != alice.Player.Id {
+		t.Fatalf("FindById: %+v %v, want player #%d", bob, err, alice.Player.Id)
+	}
+}
+
+func TestDbGameRepoRemoveFromLib(t *testing.T) {
+	fixtures := testsupport.NewFixtures(t, testsupport.Postgres(t))
+	library := fixtures.Library(fixtures.User("alice"))
+	game := fixtures.Game(library, usecases.Game{Name: "Hades"})
+
+	err, _ := fixtures.Games.RemoveFromLib(game, library.Id, 0)
+	if err != nil {
+		t.Fatalf("RemoveFromLib: %v", err)
+	}
+	_, err, code := fixtures.Games.FindInLib(game.Id, library.Id)
+	if code != 404 {
+		t.Fatalf("FindInLib after removing: %v (%d), want 404", err, code)
+	}
+	_, err, _ = fixtures.Games.FindById(game.Id)
+	if err != nil {
+		t.Fatalf("The game itself is gone: %v", err)
+	}
+}
+
+// Of two edits based on the same version only the first one is applied
+func TestDbGameRepoUpdateBatchStaleVersion(t *testing.T) {
+	fixtures := testsupport.NewFixtures(t, testsupport.Postgres(t))
+	library := fixtures.Library(fixtures.User("alice"))
+	game := fixtures.Game(library, usecases.Game{Name: "Hades"})
+	playing, completed := "playing", "completed"
+
+	versions, err := fixtures.Games.UpdateBatch(library.Id, []int{game.Id},
+		usecases.GameChange{Status: &playing}, map[int]int64{game.Id: 1})
+	if err != nil || versions[game.Id] != 2 {
+		t.Fatalf("UpdateBatch: %v %v, want version 2", versions, err)
+	}
+	versions, err = fixtures.Games.UpdateBatch(library.Id, []int{game.Id},
+		usecases.GameChange{Status: &completed}, map[int]int64{game.Id: 1})
+	if err != nil || len(versions) != 0 {
+		t.Fatalf("UpdateBatch of a stale version: %v %v, want nothing changed", versions, err)
+	}
+	found, err, _ := fixtures.Games.FindInLib(game.Id, library.Id)
+	if err != nil || found.Status != playing || found.Version != 2 {
+		t.Fatalf("FindInLib: %+v %v, want the first edit at version 2", found, err)
+	}
+}
+
+func TestDbGameRepoRemoveFromLibStaleVersion(t *testing.T) {
+	fixtures := testsupport.NewFixtures(t, testsupport.Postgres(t))
+	library := fixtures.Library(fixtures.User("alice"))
+	game := fixtures.Game(library, usecases.Game{Name: "Hades"})
+
+	err, code := fixtures.Games.RemoveFromLib(game, library.Id, library.Version)
+	if code != 409 {
+		t.Fatalf("RemoveFromLib based on version %d: %v (%d), want 409", library.Version, err, code)
+	}
+	_, err, _ = fixtures.Games.FindInLib(game.Id, library.Id)
+	if err != nil {
+		t.Fatalf("The game was removed anyway: %v", err)
+	}
+}
+
+// Games of equal value are ordered by name, the second page picks up between
+// them
+func TestDbGameRepoFindByLibPages(t *testing.T) {
+	fixtures := testsupport.NewFixtures(t, testsupport.Postgres(t))
+	library := fixtures.Library(fixtures.User("alice"))
+	for _, game := range []usecases.Game{{Name: "Celeste", Value: 10}, {Name: "Hades", Value: 20},
+		{Name: "Braid", Value: 20}, {Name: "Tunic", Value: 30}} {
+		fixtures.Game(library, game)
+	}
+	filter := usecases.GameFilter{Sort: "-value"}
+
+	first, err := fixtures.Games.FindByLib(library.Id, filter, usecases.Page{Limit: 2})
+	if err != nil {
+		t.Fatalf("FindByLib: %v", err)
+	}
+	if len(first) != 2 || first[0].Name != "Tunic" || first[1].Name != "Braid" {
+		t.Fatalf("First page is %+v, want Tunic and Braid", first)
+	}
+	after := usecases.Cursor{Key: "20\nBraid", Id: first[1].Id}
+	second, err := fixtures.Games.FindByLib(library.Id, filter, usecases.Page{After: after, Limit: 2})
+	if err != nil {
+		t.Fatalf("FindByLib after %v: %v", after, err)
+	}
+	if len(second) != 2 || second[0].Name != "Hades" || second[1].Name != "Celeste" {
+		t.Fatalf("Second page is %+v, want Hades and Celeste", second)
+	}
+}
+
+func TestDbGameRepoRestoreEntries(t *testing.T) {
+	fixtures := testsupport.NewFixtures(t, testsupport.Postgres(t))
+	library := fixtures.Library(fixtures.User("alice"))
+	hades := fixtures.Game(library, usecases.Game{Name: "Hades"})
+	celeste := fixtures.Game(library, usecases.Game{Name: "Celeste"})
+
+	// Games left out of gameIds are left alone
+	err := fixtures.Games.RestoreEntries(library.Id, []int{hades.Id}, nil)
+	if err != nil {
+		t.Fatalf("RestoreEntries: %v", err)
+	}
+	_, _, code := fixtures.Games.FindInLib(hades.Id, library.Id)
+	if code != 404 {
+		t.Fatalf("Hades is still in the library (%d)", code)
+	}
+	_, err, _ = fixtures.Games.FindInLib(celeste.Id, library.Id)
+	if err != nil {
+		t.Fatalf("Celeste was touched: %v", err)
+	}
+
+	entry := usecases.LibraryEntry{GameId: hades.Id, Status: "completed", Platform: "PC",
+		Tags: []string{"roguelike"}}
+	err = fixtures.Games.RestoreEntries(library.Id, []int{hades.Id}, []usecases.LibraryEntry{entry})
+	if err != nil {
+		t.Fatalf("RestoreEntries: %v", err)
+	}
+	restored, err, _ := fixtures.Games.FindInLib(hades.Id, library.Id)
+	if err != nil {
+		t.Fatalf("Hades was not restored: %v", err)
+	}
+	if restored.Status != "completed" || restored.Platform != "PC" || len(restored.Tags) != 1 {
+		t.Fatalf("Hades was restored as %+v, want %+v", restored, entry)
+	}
+}
+
+// Removals are logged only when the foreign keys let the row go
+func TestDbRemoveLogsChanges(t *testing.T) {
+	db := testsupport.Postgres(t)
+	fixtures := testsupport.NewFixtures(t, db)
+	changes := interfaces.NewDbChangeRepo(map[string]interfaces.DbHandler{"DbChangeRepo": db.Handler})
+	user := fixtures.User("alice")
+	library := fixtures.Library(user)
+
+	err := fixtures.Users.Remove(user)
+	if err == nil {
+		t.Fatalf("Removed user #%d who still has library #%d", user.Id, library.Id)
+	}
+	err = fixtures.Libraries.Remove(library)
+	if err != nil {
+		t.Fatalf("Remove library: %v", err)
+	}
+
+	logged, err := changes.FindSince(user.Id, 0, 100)
+	if err != nil {
+		t.Fatalf("FindSince: %v", err)
+	}
+	deleted := make(map[string]int)
+	for _, change := range logged {
+		if change.Action == usecases.ChangeDeleted {
+			deleted[change.Entity]++
+		}
+	}
+	if deleted["user"] != 0 || deleted["library"] != 1 {
+		t.Fatalf("Logged deletions %v, want only the library", deleted)
+	}
+}
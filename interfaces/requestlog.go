@@ -0,0 +1,137 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/usecases"
+)
+
+// Prints entry as one JSON line, the format every request scoped line shares
+func WriteLog(entry map[string]interface{}) {
+	entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	line, err := json.Marshal(entry)
+	if err != nil {
+		fmt.Printf("Cannot encode log entry: %v\n", err)
+		return
+	}
+	fmt.Println(string(line))
+}
+
+// Tags every line logged while serving a request with the request's id
+type RequestLogger struct {
+	RequestId string
+}
+
+func (logger RequestLogger) Log(message string) error {
+	WriteLog(map[string]interface{}{"requestId": logger.RequestId, "message": message})
+	return nil
+}
+
+func requestLogger(c *gin.Context) usecases.LoggerRepository {
+	return RequestLogger{RequestId: c.GetString("requestId")}
+}
+
+func logf(c *gin.Context, format string, args ...interface{}) {
+	requestLogger(c).Log(fmt.Sprintf(format, args...))
+}
+
+// The interactors handlers call, they log through the request's logger
+func (handler WebserviceHandler) profile(c *gin.Context) usecases.ProfileUsecase {
+	return handler.ProfileInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) admin(c *gin.Context) usecases.AdminUsecase {
+	return handler.AdminInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) activity(c *gin.Context) usecases.ActivityUsecase {
+	return handler.ActivityInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) agents(c *gin.Context) usecases.AgentUsecase {
+	return handler.AgentInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) badges(c *gin.Context) usecases.BadgeUsecase {
+	return handler.BadgeInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) calendar(c *gin.Context) usecases.CalendarUsecase {
+	return handler.CalendarInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) catalogs(c *gin.Context) usecases.CatalogUsecase {
+	return handler.CatalogInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) exports(c *gin.Context) usecases.ExportUsecase {
+	return handler.ExportInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) franchises(c *gin.Context) usecases.FranchiseUsecase {
+	return handler.FranchiseInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) goals(c *gin.Context) usecases.GoalUsecase {
+	return handler.GoalInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) hardware(c *gin.Context) usecases.HardwareUsecase {
+	return handler.HardwareInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) journal(c *gin.Context) usecases.JournalUsecase {
+	return handler.JournalInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) matches(c *gin.Context) usecases.MatchUsecase {
+	return handler.MatchInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) notifications(c *gin.Context) usecases.NotificationUsecase {
+	return handler.NotificationInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) parental(c *gin.Context) usecases.ParentalUsecase {
+	return handler.ParentalInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) personal(c *gin.Context) usecases.PersonalUsecase {
+	return handler.PersonalInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) rules(c *gin.Context) usecases.RuleUsecase {
+	return handler.RuleInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) scripts(c *gin.Context) usecases.ScriptUsecase {
+	return handler.ScriptInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) searches(c *gin.Context) usecases.SearchUsecase {
+	return handler.SearchInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) settings(c *gin.Context) usecases.SettingsUsecase {
+	return handler.SettingsInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) sharing(c *gin.Context) usecases.SharingUsecase {
+	return handler.SharingInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) speedruns(c *gin.Context) usecases.SpeedrunUsecase {
+	return handler.SpeedrunInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) subscriptions(c *gin.Context) usecases.SubscriptionUsecase {
+	return handler.SubscriptionInteractor.WithLogger(requestLogger(c))
+}
+
+func (handler WebserviceHandler) webhooks(c *gin.Context) usecases.WebhookUsecase {
+	return handler.WebhookInteractor.WithLogger(requestLogger(c))
+}
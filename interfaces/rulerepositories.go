@@ -0,0 +1,146 @@
+package interfaces
+
+import (
+	"encoding/json"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbRuleRepo DbRepo
+
+func NewDbRuleRepo(dbHandlers map[string]DbHandler) *DbRuleRepo {
+	dbRuleRepo := new(DbRuleRepo)
+	dbRuleRepo.dbHandlers = dbHandlers
+	dbRuleRepo.dbHandler = dbHandlers["DbRuleRepo"]
+	return dbRuleRepo
+}
+
+var ruleColumns = []string{"id", "user_id", "name", "event", "condition", "actions", "enabled",
+	"dry_run", "created_at", "updated_at"}
+
+func (repo DbRuleRepo) Store(rule usecases.Rule) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("rules").
+		Set("user_id", rule.UserId).Set("name", rule.Name).Set("event", rule.Event).
+		Set("condition", rule.Condition).Set("actions", rule.Actions).Set("enabled", rule.Enabled).
+		Set("dry_run", rule.DryRun).Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbRuleRepo) Update(rule usecases.Rule) error {
+	statement, args := repo.dbHandler.Dialect().Update("rules").
+		Set("name", rule.Name).Set("event", rule.Event).Set("condition", rule.Condition).
+		Set("actions", rule.Actions).Set("enabled", rule.Enabled).Set("dry_run", rule.DryRun).
+		SetExpr("updated_at = now()").Where("id = ?", rule.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbRuleRepo) FindById(id int) (usecases.Rule, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(ruleColumns...).From("rules").
+		Where("id = ?", id).Limit(1).Build()
+	rules, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.Rule{}, err, 500
+	}
+	if len(rules) == 0 {
+		return usecases.Rule{}, domain.NewError(domain.CodeNotFound, "Rule #%d does not exist", id), 404
+	}
+	return rules[0], nil, 200
+}
+
+func (repo DbRuleRepo) FindByUser(userId int) ([]usecases.Rule, error) {
+	statement, args := repo.dbHandler.Dialect().Select(ruleColumns...).From("rules").
+		Where("user_id = ?", userId).OrderBy("created_at", "id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbRuleRepo) FindEnabled(userId int, event string) ([]usecases.Rule, error) {
+	statement, args := repo.dbHandler.Dialect().Select(ruleColumns...).From("rules").
+		Where("user_id = ?", userId).Where("event = ?", event).Where("enabled").
+		OrderBy("created_at", "id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbRuleRepo) query(statement string, args []interface{}) ([]usecases.Rule, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+	var rules []usecases.Rule
+	for row.Next() {
+		var rule usecases.Rule
+		err = row.Scan(&rule.Id, &rule.UserId, &rule.Name, &rule.Event, &rule.Condition, &rule.Actions,
+			&rule.Enabled, &rule.DryRun, &rule.CreatedAt, &rule.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (repo DbRuleRepo) Remove(rule usecases.Rule) error {
+	statement, args := repo.dbHandler.Dialect().Delete("rules").Where("id = ?", rule.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbRuleRepo) RemoveAll(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("rules").Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbRuleRepo) StoreRun(run usecases.RuleRun, keep int) error {
+	actions, err := json.Marshal(run.Actions)
+	if err != nil {
+		return err
+	}
+	if run.Actions == nil {
+		actions = []byte("[]")
+	}
+	return repo.dbHandler.Transaction(func(tx DbHandler) error {
+		statement, args := tx.Dialect().Insert("rule_runs").
+			Set("rule_id", run.RuleId).Set("user_id", run.UserId).Set("event", run.Event).
+			Set("entity_id", run.EntityId).Set("actions", string(actions)).Set("dry_run", run.DryRun).
+			Set("error", run.Error).Set("created_at", run.CreatedAt).Build()
+		_, err := tx.Execute(statement, args...)
+		if err != nil {
+			return err
+		}
+		statement, args = tx.Dialect().Delete("rule_runs").Where("rule_id = ?", run.RuleId).
+			Where(`id NOT IN (SELECT id FROM rule_runs WHERE rule_id = ? ORDER BY id DESC LIMIT ?)`,
+				run.RuleId, keep).Build()
+		_, err = tx.Execute(statement, args...)
+		return err
+	})
+}
+
+func (repo DbRuleRepo) FindRuns(ruleId int, limit int) ([]usecases.RuleRun, error) {
+	statement, args := repo.dbHandler.Dialect().Select("id", "rule_id", "user_id", "event", "entity_id",
+		"actions", "dry_run", "error", "created_at").From("rule_runs").Where("rule_id = ?", ruleId).
+		OrderBy("id DESC").Limit(limit).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+	var runs []usecases.RuleRun
+	for row.Next() {
+		var run usecases.RuleRun
+		var actions string
+		err = row.Scan(&run.Id, &run.RuleId, &run.UserId, &run.Event, &run.EntityId, &actions,
+			&run.DryRun, &run.Error, &run.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal([]byte(actions), &run.Actions)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
@@ -0,0 +1,91 @@
+package interfaces
+
+import (
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbScriptRepo DbRepo
+
+func NewDbScriptRepo(dbHandlers map[string]DbHandler) *DbScriptRepo {
+	dbScriptRepo := new(DbScriptRepo)
+	dbScriptRepo.dbHandlers = dbHandlers
+	dbScriptRepo.dbHandler = dbHandlers["DbScriptRepo"]
+	return dbScriptRepo
+}
+
+var scriptColumns = []string{"id", "name", "event", "source", "user_id", "enabled", "created_by",
+	"created_at", "updated_at"}
+
+func (repo DbScriptRepo) Store(script usecases.Script) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("scripts").
+		Set("name", script.Name).Set("event", script.Event).Set("source", script.Source).
+		Set("user_id", script.UserId).Set("enabled", script.Enabled).Set("created_by", script.CreatedBy).
+		Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbScriptRepo) Update(script usecases.Script) error {
+	statement, args := repo.dbHandler.Dialect().Update("scripts").
+		Set("name", script.Name).Set("event", script.Event).Set("source", script.Source).
+		Set("user_id", script.UserId).Set("enabled", script.Enabled).Set("created_by", script.CreatedBy).
+		SetExpr("updated_at = now()").Where("id = ?", script.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbScriptRepo) FindById(id int) (usecases.Script, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(scriptColumns...).From("scripts").
+		Where("id = ?", id).Limit(1).Build()
+	scripts, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.Script{}, err, 500
+	}
+	if len(scripts) == 0 {
+		return usecases.Script{}, domain.NewError(domain.CodeNotFound, "Script #%d does not exist", id), 404
+	}
+	return scripts[0], nil, 200
+}
+
+func (repo DbScriptRepo) FindAll() ([]usecases.Script, error) {
+	statement, args := repo.dbHandler.Dialect().Select(scriptColumns...).From("scripts").
+		OrderBy("id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbScriptRepo) FindEnabled(event string) ([]usecases.Script, error) {
+	statement, args := repo.dbHandler.Dialect().Select(scriptColumns...).From("scripts").
+		Where("event = ?", event).Where("enabled").OrderBy("id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbScriptRepo) query(statement string, args []interface{}) ([]usecases.Script, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+	var scripts []usecases.Script
+	for row.Next() {
+		var script usecases.Script
+		err = row.Scan(&script.Id, &script.Name, &script.Event, &script.Source, &script.UserId,
+			&script.Enabled, &script.CreatedBy, &script.CreatedAt, &script.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts, nil
+}
+
+func (repo DbScriptRepo) Remove(id int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("scripts").Where("id = ?", id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbScriptRepo) RemoveByUser(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("scripts").Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
@@ -0,0 +1,173 @@
+package interfaces
+
+import (
+	"encoding/json"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbSavedSearchRepo DbRepo
+
+func NewDbSavedSearchRepo(dbHandlers map[string]DbHandler) *DbSavedSearchRepo {
+	dbSavedSearchRepo := new(DbSavedSearchRepo)
+	dbSavedSearchRepo.dbHandlers = dbHandlers
+	dbSavedSearchRepo.dbHandler = dbHandlers["DbSavedSearchRepo"]
+	return dbSavedSearchRepo
+}
+
+// How a saved filter is kept in the filter column
+type searchFilter struct {
+	Rating   string `json:"rating,omitempty"`
+	MaxAge   int    `json:"maxAge,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Platform string `json:"platform,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+	Sort     string `json:"sort,omitempty"`
+}
+
+var savedSearchColumns = []string{"id", "user_id", "name", "filter",
+	"COALESCE(share_token_hash, '')", "created_at", "updated_at"}
+
+func encodeFilter(filter usecases.GameFilter) (string, error) {
+	encoded, err := json.Marshal(searchFilter(filter))
+	return string(encoded), err
+}
+
+func (repo DbSavedSearchRepo) Store(search usecases.SavedSearch) (int, error) {
+	filter, err := encodeFilter(search.Filter)
+	if err != nil {
+		return 0, err
+	}
+	statement, args := repo.dbHandler.Dialect().Insert("saved_searches").
+		Set("user_id", search.UserId).Set("name", search.Name).Set("filter", filter).
+		Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbSavedSearchRepo) Update(search usecases.SavedSearch) error {
+	filter, err := encodeFilter(search.Filter)
+	if err != nil {
+		return err
+	}
+	statement, args := repo.dbHandler.Dialect().Update("saved_searches").
+		Set("name", search.Name).Set("filter", filter).
+		SetExpr("share_token_hash = NULLIF(?, '')", search.ShareTokenHash).
+		SetExpr("updated_at = now()").Where("id = ?", search.Id).Build()
+	_, err = repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbSavedSearchRepo) FindById(id int) (usecases.SavedSearch, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(savedSearchColumns...).From("saved_searches").
+		Where("id = ?", id).Limit(1).Build()
+	searches, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.SavedSearch{}, err, 500
+	}
+	if len(searches) == 0 {
+		return usecases.SavedSearch{}, domain.NewError(domain.CodeNotFound,
+			"Saved search #%d does not exist", id), 404
+	}
+	return searches[0], nil, 200
+}
+
+func (repo DbSavedSearchRepo) FindByUser(userId int) ([]usecases.SavedSearch, error) {
+	statement, args := repo.dbHandler.Dialect().Select(savedSearchColumns...).From("saved_searches").
+		Where("user_id = ?", userId).OrderBy("name", "id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbSavedSearchRepo) FindByShareToken(tokenHash string) (usecases.SavedSearch, bool, error) {
+	statement, args := repo.dbHandler.Dialect().Select(savedSearchColumns...).From("saved_searches").
+		Where("share_token_hash = ?", tokenHash).Limit(1).Build()
+	searches, err := repo.query(statement, args)
+	if err != nil || len(searches) == 0 {
+		return usecases.SavedSearch{}, false, err
+	}
+	return searches[0], true, nil
+}
+
+func (repo DbSavedSearchRepo) query(statement string, args []interface{}) ([]usecases.SavedSearch, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+	var searches []usecases.SavedSearch
+	for row.Next() {
+		var search usecases.SavedSearch
+		var filter string
+		err = row.Scan(&search.Id, &search.UserId, &search.Name, &filter, &search.ShareTokenHash,
+			&search.CreatedAt, &search.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		var decoded searchFilter
+		err = json.Unmarshal([]byte(filter), &decoded)
+		if err != nil {
+			return nil, err
+		}
+		search.Filter = usecases.GameFilter(decoded)
+		searches = append(searches, search)
+	}
+	return searches, nil
+}
+
+// Default searches go with it by the foreign key
+func (repo DbSavedSearchRepo) Remove(search usecases.SavedSearch) error {
+	statement, args := repo.dbHandler.Dialect().Delete("saved_searches").Where("id = ?", search.Id).
+		Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbSavedSearchRepo) RemoveAll(userId int) error {
+	return repo.dbHandler.Transaction(func(tx DbHandler) error {
+		statement, args := tx.Dialect().Delete("default_searches").Where("user_id = ?", userId).Build()
+		_, err := tx.Execute(statement, args...)
+		if err != nil {
+			return err
+		}
+		statement, args = tx.Dialect().Delete("saved_searches").Where("user_id = ?", userId).Build()
+		_, err = tx.Execute(statement, args...)
+		return err
+	})
+}
+
+func (repo DbSavedSearchRepo) SetDefault(userId, libraryId, searchId int) error {
+	if searchId == 0 {
+		statement, args := repo.dbHandler.Dialect().Delete("default_searches").
+			Where("user_id = ?", userId).Where("library_id = ?", libraryId).Build()
+		_, err := repo.dbHandler.Execute(statement, args...)
+		return err
+	}
+	statement, args := repo.dbHandler.Dialect().Insert("default_searches").
+		Set("user_id", userId).Set("library_id", libraryId).Set("search_id", searchId).
+		OnConflict("(user_id, library_id)", "DO UPDATE SET search_id = EXCLUDED.search_id").Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbSavedSearchRepo) FindDefault(userId, libraryId int) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Select("search_id").From("default_searches").
+		Where("user_id = ?", userId).Where("library_id = ?", libraryId).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer row.Close()
+	var searchId int
+	if row.Next() {
+		err = row.Scan(&searchId)
+	}
+	return searchId, err
+}
+
+func (repo DbSavedSearchRepo) RemoveDefaults(libraryId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("default_searches").
+		Where("library_id = ?", libraryId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
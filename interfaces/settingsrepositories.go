@@ -0,0 +1,71 @@
+package interfaces
+
+import (
+	"database/sql"
+
+	"game-tracker/usecases"
+)
+
+type DbSettingsRepo DbRepo
+
+func NewDbSettingsRepo(dbHandlers map[string]DbHandler) *DbSettingsRepo {
+	dbSettingsRepo := new(DbSettingsRepo)
+	dbSettingsRepo.dbHandlers = dbHandlers
+	dbSettingsRepo.dbHandler = dbHandlers["DbSettingsRepo"]
+	return dbSettingsRepo
+}
+
+func (repo DbSettingsRepo) Load(userId int) (usecases.Settings, bool, error) {
+	statement, args := repo.dbHandler.Dialect().Select("display_currency", "timezone",
+		"notify_libraries", "notify_games", "default_library_id", "libraries.external_id",
+		"profile_public", "libraries_public", "rating_limit", "locale").From("settings").
+		LeftJoin("libraries", "libraries.id = settings.default_library_id").
+		Where("settings.user_id = ?", userId).Limit(1).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return usecases.Settings{}, false, err
+	}
+	defer row.Close()
+	if !row.Next() {
+		return usecases.Settings{}, false, nil
+	}
+
+	settings := usecases.Settings{UserId: userId}
+	var defaultLibraryId sql.NullInt64
+	var defaultLibraryExternalId sql.NullString
+	err = row.Scan(&settings.DisplayCurrency, &settings.Timezone, &settings.NotifyLibraries,
+		&settings.NotifyGames, &defaultLibraryId, &defaultLibraryExternalId, &settings.ProfilePublic,
+		&settings.LibrariesPublic, &settings.RatingLimit, &settings.Locale)
+	if err != nil {
+		return usecases.Settings{}, true, err
+	}
+	settings.DefaultLibraryId = int(defaultLibraryId.Int64)
+	settings.DefaultLibraryExternalId = defaultLibraryExternalId.String
+	return settings, true, nil
+}
+
+func (repo DbSettingsRepo) Store(settings usecases.Settings) error {
+	defaultLibraryId := sql.NullInt64{
+		Int64: int64(settings.DefaultLibraryId),
+		Valid: settings.DefaultLibraryId != 0,
+	}
+	_, err := repo.dbHandler.Execute(`INSERT INTO settings (user_id, display_currency, timezone,
+		notify_libraries, notify_games, default_library_id, profile_public, libraries_public,
+		rating_limit, locale)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (user_id) DO UPDATE SET display_currency = EXCLUDED.display_currency,
+		timezone = EXCLUDED.timezone, notify_libraries = EXCLUDED.notify_libraries,
+		notify_games = EXCLUDED.notify_games, default_library_id = EXCLUDED.default_library_id,
+		profile_public = EXCLUDED.profile_public, libraries_public = EXCLUDED.libraries_public,
+		rating_limit = EXCLUDED.rating_limit, locale = EXCLUDED.locale, updated_at = now()`,
+		settings.UserId, settings.DisplayCurrency, settings.Timezone, settings.NotifyLibraries,
+		settings.NotifyGames, defaultLibraryId, settings.ProfilePublic, settings.LibrariesPublic,
+		settings.RatingLimit, settings.Locale)
+	return err
+}
+
+func (repo DbSettingsRepo) Remove(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("settings").Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
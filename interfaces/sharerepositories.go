@@ -0,0 +1,96 @@
+package interfaces
+
+import (
+	"database/sql"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbShareLinkRepo DbRepo
+
+func NewDbShareLinkRepo(dbHandlers map[string]DbHandler) *DbShareLinkRepo {
+	dbShareLinkRepo := new(DbShareLinkRepo)
+	dbShareLinkRepo.dbHandlers = dbHandlers
+	dbShareLinkRepo.dbHandler = dbHandlers["DbShareLinkRepo"]
+	return dbShareLinkRepo
+}
+
+var shareLinkColumns = []string{"id", "library_id", "token_hash", "password_hash", "expires_at",
+	"created_at"}
+
+func (repo DbShareLinkRepo) Store(link usecases.ShareLink) (int, error) {
+	var expiresAt sql.NullTime
+	if !link.ExpiresAt.IsZero() {
+		expiresAt = sql.NullTime{Time: link.ExpiresAt, Valid: true}
+	}
+	statement, args := repo.dbHandler.Dialect().Insert("share_links").Set("library_id", link.LibraryId).
+		Set("token_hash", link.TokenHash).Set("password_hash", link.PasswordHash).
+		Set("expires_at", expiresAt).Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbShareLinkRepo) FindById(id int) (usecases.ShareLink, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(shareLinkColumns...).From("share_links").
+		Where("id = ?", id).Limit(1).Build()
+	links, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.ShareLink{}, err, 500
+	}
+	if len(links) == 0 {
+		return usecases.ShareLink{}, domain.NewError(domain.CodeNotFound,
+			"Share link #%d does not exist", id), 404
+	}
+	return links[0], nil, 200
+}
+
+func (repo DbShareLinkRepo) FindByToken(tokenHash string) (usecases.ShareLink, bool, error) {
+	statement, args := repo.dbHandler.Dialect().Select(shareLinkColumns...).From("share_links").
+		Where("token_hash = ?", tokenHash).Limit(1).Build()
+	links, err := repo.query(statement, args)
+	if err != nil || len(links) == 0 {
+		return usecases.ShareLink{}, false, err
+	}
+	return links[0], true, nil
+}
+
+func (repo DbShareLinkRepo) FindByLib(libraryId int) ([]usecases.ShareLink, error) {
+	statement, args := repo.dbHandler.Dialect().Select(shareLinkColumns...).From("share_links").
+		Where("library_id = ?", libraryId).OrderBy("created_at DESC", "id DESC").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbShareLinkRepo) query(statement string, args []interface{}) ([]usecases.ShareLink, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var links []usecases.ShareLink
+	for row.Next() {
+		var link usecases.ShareLink
+		var expiresAt sql.NullTime
+		err = row.Scan(&link.Id, &link.LibraryId, &link.TokenHash, &link.PasswordHash, &expiresAt,
+			&link.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		link.ExpiresAt = expiresAt.Time
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+func (repo DbShareLinkRepo) Remove(link usecases.ShareLink) error {
+	statement, args := repo.dbHandler.Dialect().Delete("share_links").Where("id = ?", link.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbShareLinkRepo) RemoveFromLib(libraryId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("share_links").
+		Where("library_id = ?", libraryId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
@@ -0,0 +1,91 @@
+package interfaces
+
+import (
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbSpeedrunRepo DbRepo
+
+func NewDbSpeedrunRepo(dbHandlers map[string]DbHandler) *DbSpeedrunRepo {
+	dbSpeedrunRepo := new(DbSpeedrunRepo)
+	dbSpeedrunRepo.dbHandlers = dbHandlers
+	dbSpeedrunRepo.dbHandler = dbHandlers["DbSpeedrunRepo"]
+	return dbSpeedrunRepo
+}
+
+var speedrunColumns = []string{"speedruns.id", "user_id", "game_id", "games.external_id", "games.name",
+	"category", "milliseconds", "run_at", "speedruns.created_at"}
+
+func (repo DbSpeedrunRepo) Store(run usecases.SpeedRun) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("speedruns").
+		Set("user_id", run.UserId).Set("game_id", run.GameId).Set("category", run.Category).
+		Set("milliseconds", run.Milliseconds).Set("run_at", run.RunAt).Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbSpeedrunRepo) FindById(id int) (usecases.SpeedRun, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(speedrunColumns...).From("speedruns").
+		Join("games", "games.id = speedruns.game_id").Where("speedruns.id = ?", id).Limit(1).Build()
+	runs, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.SpeedRun{}, err, 500
+	}
+	if len(runs) == 0 {
+		return usecases.SpeedRun{}, domain.NewError(domain.CodeNotFound, "Run #%d does not exist", id), 404
+	}
+	return runs[0], nil, 200
+}
+
+func (repo DbSpeedrunRepo) FindByGame(userId, gameId int) ([]usecases.SpeedRun, error) {
+	statement, args := repo.dbHandler.Dialect().Select(speedrunColumns...).From("speedruns").
+		Join("games", "games.id = speedruns.game_id").Where("user_id = ?", userId).
+		Where("game_id = ?", gameId).OrderBy("category", "run_at", "speedruns.id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbSpeedrunRepo) FindBest(userIds []int, gameId int) ([]usecases.SpeedRun, error) {
+	query := repo.dbHandler.Dialect().Select(speedrunColumns...).From("speedruns").
+		Join("games", "games.id = speedruns.game_id").Where("user_id = ANY(?::int[])", intArray(userIds)).
+		Where("NOT EXISTS (SELECT 1 FROM speedruns faster WHERE faster.user_id = speedruns.user_id " +
+			"AND faster.game_id = speedruns.game_id AND faster.category = speedruns.category " +
+			"AND (faster.milliseconds, faster.run_at, faster.id) < " +
+			"(speedruns.milliseconds, speedruns.run_at, speedruns.id))")
+	if gameId != 0 {
+		query = query.Where("game_id = ?", gameId)
+	}
+	statement, args := query.OrderBy("game_id", "category", "milliseconds", "run_at").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbSpeedrunRepo) query(statement string, args []interface{}) ([]usecases.SpeedRun, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var runs []usecases.SpeedRun
+	for row.Next() {
+		var run usecases.SpeedRun
+		err = row.Scan(&run.Id, &run.UserId, &run.GameId, &run.GameExternalId, &run.GameName,
+			&run.Category, &run.Milliseconds, &run.RunAt, &run.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+func (repo DbSpeedrunRepo) Remove(run usecases.SpeedRun) error {
+	statement, args := repo.dbHandler.Dialect().Delete("speedruns").Where("id = ?", run.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbSpeedrunRepo) RemoveAll(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("speedruns").Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
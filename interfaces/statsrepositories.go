@@ -0,0 +1,99 @@
+package interfaces
+
+import (
+	"database/sql"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type DbStatsRepo DbRepo
+
+func NewDbStatsRepo(dbHandlers map[string]DbHandler) *DbStatsRepo {
+	dbStatsRepo := new(DbStatsRepo)
+	dbStatsRepo.dbHandlers = dbHandlers
+	dbStatsRepo.dbHandler = dbHandlers["DbStatsRepo"]
+	return dbStatsRepo
+}
+
+var statsViews = []string{"library_stats", "playtime_stats"}
+
+// Libraries are counted live, users with empty ones have no entries to sum
+func (repo DbStatsRepo) FindByUser(userId int) (usecases.LibraryStats, error) {
+	stats := usecases.LibraryStats{UserId: userId, Statuses: make(map[string]int),
+		Platforms: make(map[string]int), PlatformMinutes: make(map[string]int)}
+	handler := methodHandler(repo.dbHandlers, "DbStatsRepo.FindByUser", repo.dbHandler)
+	row, err := handler.Query(`SELECT (SELECT count(*) FROM libraries WHERE user_id = $1),
+		(SELECT min(refreshed_at) FROM stats_refreshes)`, userId)
+	if err != nil {
+		return usecases.LibraryStats{}, err
+	}
+	defer row.Close()
+	row.Next()
+	// NULL until the views were refreshed once
+	var refreshedAt sql.NullTime
+	err = row.Scan(&stats.Libraries, &refreshedAt)
+	if err != nil {
+		return usecases.LibraryStats{}, err
+	}
+	stats.RefreshedAt = nullTime(refreshedAt)
+
+	statement, args := handler.Dialect().Select("status", "platform", "games", "value").
+		From("library_stats").Where("user_id = ?", userId).Build()
+	entries, err := handler.Query(statement, args...)
+	if err != nil {
+		return usecases.LibraryStats{}, err
+	}
+	defer entries.Close()
+	for entries.Next() {
+		var status, platform string
+		var games int
+		var value float64
+		err = entries.Scan(&status, &platform, &games, &value)
+		if err != nil {
+			return usecases.LibraryStats{}, err
+		}
+		stats.Games += games
+		stats.Value += value
+		stats.Statuses[status] += games
+		stats.Platforms[platform] += games
+	}
+
+	statement, args = handler.Dialect().Select("platform", "minutes").From("playtime_stats").
+		Where("user_id = ?", userId).Build()
+	playtime, err := handler.Query(statement, args...)
+	if err != nil {
+		return usecases.LibraryStats{}, err
+	}
+	defer playtime.Close()
+	for playtime.Next() {
+		var platform string
+		var minutes int
+		err = playtime.Scan(&platform, &minutes)
+		if err != nil {
+			return usecases.LibraryStats{}, err
+		}
+		stats.PlatformMinutes[platform] = minutes
+	}
+	return stats, nil
+}
+
+// Concurrent refreshes keep the views readable meanwhile, they cannot run
+// in a transaction
+func (repo DbStatsRepo) Refresh() error {
+	handler := methodHandler(repo.dbHandlers, "DbStatsRepo.Refresh", repo.dbHandler)
+	for _, view := range statsViews {
+		_, err := handler.Execute("REFRESH MATERIALIZED VIEW CONCURRENTLY " + view)
+		if err != nil {
+			return err
+		}
+		statement, args := handler.Dialect().Insert("stats_refreshes").Set("view_name", view).
+			Set("refreshed_at", time.Now().UTC()).
+			OnConflict("(view_name)", "DO UPDATE SET refreshed_at = EXCLUDED.refreshed_at").Build()
+		_, err = handler.Execute(statement, args...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package interfaces_test
+
+import (
+	"testing"
+	"time"
+
+	"game-tracker/interfaces"
+	"game-tracker/testsupport"
+)
+
+func TestDbStatsRepoRefreshedAt(t *testing.T) {
+	db := testsupport.Postgres(t)
+	fixtures := testsupport.NewFixtures(t, db)
+	stats := interfaces.NewDbStatsRepo(map[string]interfaces.DbHandler{"DbStatsRepo": db.Handler})
+	user := fixtures.User("alice")
+
+	found, err := stats.FindByUser(user.Id)
+	if err != nil || found.RefreshedAt == nil {
+		t.Fatalf("FindByUser: %+v, %v, want the time of the migration's refresh", found, err)
+	}
+
+	// As before the views were refreshed once
+	_, err = db.Handler.Execute(`DELETE FROM stats_refreshes`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err = stats.FindByUser(user.Id)
+	if err != nil {
+		t.Fatalf("FindByUser: %v", err)
+	}
+	if found.RefreshedAt != nil {
+		t.Fatalf("Stats were refreshed at %v, want nil", *found.RefreshedAt)
+	}
+}
+
+// A refresh is read back as the time of the oldest view's refresh
+func TestDbStatsRepoRefreshRoundTrip(t *testing.T) {
+	db := testsupport.Postgres(t)
+	fixtures := testsupport.NewFixtures(t, db)
+	stats := interfaces.NewDbStatsRepo(map[string]interfaces.DbHandler{"DbStatsRepo": db.Handler})
+	user := fixtures.User("alice")
+
+	_, err := db.Handler.Execute(`DELETE FROM stats_refreshes`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now().Add(-time.Second)
+	err = stats.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	found, err := stats.FindByUser(user.Id)
+	if err != nil {
+		t.Fatalf("FindByUser: %v", err)
+	}
+	if found.RefreshedAt == nil || found.RefreshedAt.Before(before) {
+		t.Fatalf("Stats were refreshed at %v, want after %v", found.RefreshedAt, before)
+	}
+}
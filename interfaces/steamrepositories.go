@@ -0,0 +1,39 @@
+package interfaces
+
+type DbSteamRepo DbRepo
+
+func NewDbSteamRepo(dbHandlers map[string]DbHandler) *DbSteamRepo {
+	dbSteamRepo := new(DbSteamRepo)
+	dbSteamRepo.dbHandlers = dbHandlers
+	dbSteamRepo.dbHandler = dbHandlers["DbSteamRepo"]
+	return dbSteamRepo
+}
+
+func (repo DbSteamRepo) FindGameIds(appIds []int) (map[int]int, error) {
+	statement, args := repo.dbHandler.Dialect().Select("app_id", "game_id").From("steam_apps").
+		Where("app_id = ANY(?::int[])", intArray(appIds)).Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	gameIds := make(map[int]int)
+	for row.Next() {
+		var appId, gameId int
+		err = row.Scan(&appId, &gameId)
+		if err != nil {
+			return nil, err
+		}
+		gameIds[appId] = gameId
+	}
+	return gameIds, nil
+}
+
+func (repo DbSteamRepo) Link(appId, gameId int) error {
+	statement, args := repo.dbHandler.Dialect().Insert("steam_apps").Set("app_id", appId).
+		Set("game_id", gameId).OnConflict("(app_id)", "DO UPDATE SET game_id = EXCLUDED.game_id").
+		Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
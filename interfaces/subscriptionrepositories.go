@@ -0,0 +1,155 @@
+package interfaces
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbSubscriptionRepo DbRepo
+
+func NewDbSubscriptionRepo(dbHandlers map[string]DbHandler) *DbSubscriptionRepo {
+	dbSubscriptionRepo := new(DbSubscriptionRepo)
+	dbSubscriptionRepo.dbHandlers = dbHandlers
+	dbSubscriptionRepo.dbHandler = dbHandlers["DbSubscriptionRepo"]
+	return dbSubscriptionRepo
+}
+
+var subscriptionColumns = []string{"id", "user_id", "service", "monthly_cost", "currency",
+	"billing_months", "started_on", "renews_on", "ends_on",
+	`coalesce((SELECT array_to_json(array_agg(game_id ORDER BY game_id)) FROM subscription_games
+		WHERE subscription_id = subscriptions.id), '[]')`, "reminded_at", "created_at", "updated_at"}
+
+func (repo DbSubscriptionRepo) Store(subscription usecases.Subscription) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("subscriptions").
+		Set("user_id", subscription.UserId).Set("service", subscription.Service).
+		Set("monthly_cost", subscription.MonthlyCost).Set("currency", subscription.Currency).
+		Set("billing_months", subscription.BillingMonths).
+		Set("started_on", nullDate(subscription.StartedOn)).
+		Set("renews_on", nullDate(subscription.RenewsOn)).
+		Set("ends_on", nullDate(subscription.EndsOn)).Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbSubscriptionRepo) FindById(id int) (usecases.Subscription, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(subscriptionColumns...).From("subscriptions").
+		Where("id = ?", id).Limit(1).Build()
+	subscriptions, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.Subscription{}, err, 500
+	}
+	if len(subscriptions) == 0 {
+		return usecases.Subscription{}, domain.NewError(domain.CodeNotFound,
+			"Subscription #%d does not exist", id), 404
+	}
+	return subscriptions[0], nil, 200
+}
+
+func (repo DbSubscriptionRepo) FindByUser(userId int) ([]usecases.Subscription, error) {
+	statement, args := repo.dbHandler.Dialect().Select(subscriptionColumns...).From("subscriptions").
+		Where("user_id = ?", userId).OrderBy("service", "id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbSubscriptionRepo) FindRenewing(until time.Time) ([]usecases.Subscription, error) {
+	statement, args := repo.dbHandler.Dialect().Select(subscriptionColumns...).From("subscriptions").
+		Where("renews_on <= ?", until.Format("2006-01-02")).
+		Where("(ends_on IS NULL OR ends_on > renews_on)").OrderBy("id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbSubscriptionRepo) FindActive(day time.Time) ([]usecases.Subscription, error) {
+	statement, args := repo.dbHandler.Dialect().Select(subscriptionColumns...).From("subscriptions").
+		Where("started_on <= ?", day.Format("2006-01-02")).
+		Where("(ends_on IS NULL OR ends_on > ?)", day.Format("2006-01-02")).OrderBy("id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbSubscriptionRepo) query(statement string, args []interface{}) ([]usecases.Subscription, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var subscriptions []usecases.Subscription
+	for row.Next() {
+		var subscription usecases.Subscription
+		var endsOn, remindedAt sql.NullTime
+		var games string
+		err = row.Scan(&subscription.Id, &subscription.UserId, &subscription.Service,
+			&subscription.MonthlyCost, &subscription.Currency, &subscription.BillingMonths,
+			&subscription.StartedOn, &subscription.RenewsOn, &endsOn, &games, &remindedAt,
+			&subscription.CreatedAt, &subscription.UpdatedAt)
+		if err == nil {
+			err = json.Unmarshal([]byte(games), &subscription.GameIds)
+		}
+		if err != nil {
+			return nil, err
+		}
+		subscription.EndsOn = endsOn.Time
+		subscription.RemindedAt = remindedAt.Time
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, nil
+}
+
+func (repo DbSubscriptionRepo) Update(subscription usecases.Subscription) error {
+	statement, args := repo.dbHandler.Dialect().Update("subscriptions").
+		Set("service", subscription.Service).Set("monthly_cost", subscription.MonthlyCost).
+		Set("currency", subscription.Currency).Set("billing_months", subscription.BillingMonths).
+		Set("started_on", nullDate(subscription.StartedOn)).
+		Set("renews_on", nullDate(subscription.RenewsOn)).
+		Set("ends_on", nullDate(subscription.EndsOn)).
+		SetExpr("reminded_at = CASE WHEN renews_on = ?::date THEN reminded_at END",
+			nullDate(subscription.RenewsOn)).
+		SetExpr("updated_at = now()").Where("id = ?", subscription.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbSubscriptionRepo) SetRenewal(id int, renewsOn time.Time) error {
+	statement, args := repo.dbHandler.Dialect().Update("subscriptions").
+		Set("renews_on", nullDate(renewsOn)).SetExpr("reminded_at = NULL").Where("id = ?", id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbSubscriptionRepo) MarkReminded(id int, at time.Time) error {
+	statement, args := repo.dbHandler.Dialect().Update("subscriptions").Set("reminded_at", at).
+		Where("id = ?", id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbSubscriptionRepo) AddGame(id, gameId int) error {
+	statement, args := repo.dbHandler.Dialect().Insert("subscription_games").
+		Set("subscription_id", id).Set("game_id", gameId).
+		OnConflict("(subscription_id, game_id)", "DO NOTHING").Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbSubscriptionRepo) RemoveGame(id, gameId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("subscription_games").
+		Where("subscription_id = ?", id).Where("game_id = ?", gameId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbSubscriptionRepo) Remove(subscription usecases.Subscription) error {
+	statement, args := repo.dbHandler.Dialect().Delete("subscriptions").
+		Where("id = ?", subscription.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbSubscriptionRepo) RemoveAll(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("subscriptions").
+		Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
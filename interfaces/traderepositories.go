@@ -0,0 +1,214 @@
+package interfaces
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+// Rolls back a transfer whose copies changed, it is not reported as an error
+var errTradeChanged = errors.New("trade changed")
+
+type DbTradeRepo DbRepo
+
+func NewDbTradeRepo(dbHandlers map[string]DbHandler) *DbTradeRepo {
+	dbTradeRepo := new(DbTradeRepo)
+	dbTradeRepo.dbHandlers = dbHandlers
+	dbTradeRepo.dbHandler = dbHandlers["DbTradeRepo"]
+	return dbTradeRepo
+}
+
+var tradeOfferColumns = []string{"id", "proposer_id", "proposer_library_id", "recipient_id",
+	"recipient_library_id", "array_to_json(offered_copy_ids)", "array_to_json(requested_copy_ids)",
+	"status", "counter_of", "created_at", "updated_at"}
+
+func (repo DbTradeRepo) Store(offer usecases.TradeOffer) (int, error) {
+	statement, args := repo.dbHandler.Dialect().Insert("trade_offers").
+		Set("proposer_id", offer.ProposerId).Set("proposer_library_id", offer.ProposerLibraryId).
+		Set("recipient_id", offer.RecipientId).Set("recipient_library_id", offer.RecipientLibraryId).
+		Set("offered_copy_ids", intArray(offer.Offered)).Set("requested_copy_ids", intArray(offer.Requested)).
+		Set("status", offer.Status).Set("counter_of", offer.CounterOf).Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbTradeRepo) FindById(id int) (usecases.TradeOffer, error, int) {
+	statement, args := repo.dbHandler.Dialect().Select(tradeOfferColumns...).From("trade_offers").
+		Where("id = ?", id).Limit(1).Build()
+	offers, err := repo.query(statement, args)
+	if err != nil {
+		return usecases.TradeOffer{}, err, 500
+	}
+	if len(offers) == 0 {
+		return usecases.TradeOffer{}, domain.NewError(domain.CodeNotFound,
+			"Trade #%d does not exist", id), 404
+	}
+	return offers[0], nil, 200
+}
+
+func (repo DbTradeRepo) FindByUser(userId int, page usecases.Page) ([]usecases.TradeOffer, error) {
+	after, err := page.After.Time()
+	if err != nil {
+		return nil, err
+	}
+	selection := repo.dbHandler.Dialect().Select(tradeOfferColumns...).From("trade_offers").
+		Where("(proposer_id = ? OR recipient_id = ?)", userId, userId)
+	statement, args := pageRows(selection, page, "created_at", "id", after, true).Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbTradeRepo) query(statement string, args []interface{}) ([]usecases.TradeOffer, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var offers []usecases.TradeOffer
+	for row.Next() {
+		var offer usecases.TradeOffer
+		var offered, requested string
+		err = row.Scan(&offer.Id, &offer.ProposerId, &offer.ProposerLibraryId, &offer.RecipientId,
+			&offer.RecipientLibraryId, &offered, &requested, &offer.Status, &offer.CounterOf,
+			&offer.CreatedAt, &offer.UpdatedAt)
+		if err == nil {
+			err = json.Unmarshal([]byte(offered), &offer.Offered)
+		}
+		if err == nil {
+			err = json.Unmarshal([]byte(requested), &offer.Requested)
+		}
+		if err != nil {
+			return nil, err
+		}
+		offers = append(offers, offer)
+	}
+	return offers, nil
+}
+
+func (repo DbTradeRepo) SetStatus(id int, from, to string) (bool, error) {
+	statement, args := repo.dbHandler.Dialect().Update("trade_offers").Set("status", to).
+		SetExpr("updated_at = now()").Where("id = ?", id).Where("status = ?", from).Build()
+	res, err := repo.dbHandler.Execute(statement, args...)
+	if err != nil {
+		return false, err
+	}
+	updated, err := res.RowsAffected()
+	return updated > 0, err
+}
+
+func (repo DbTradeRepo) Transfer(offer usecases.TradeOffer, actorId int) (bool, error) {
+	err := repo.dbHandler.Transaction(func(tx DbHandler) error {
+		claimed, err := DbTradeRepo{dbHandler: tx}.SetStatus(offer.Id, usecases.TradePending,
+			usecases.TradeAccepted)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return errTradeChanged
+		}
+		err = moveCopies(tx, offer, offer.Offered, offer.ProposerLibraryId, offer.RecipientLibraryId,
+			actorId, offer.RecipientId)
+		if err != nil {
+			return err
+		}
+		return moveCopies(tx, offer, offer.Requested, offer.RecipientLibraryId, offer.ProposerLibraryId,
+			actorId, offer.ProposerId)
+	})
+	if err == errTradeChanged {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Moves every copy or fails with errTradeChanged, then fixes up the game
+// entries of both libraries
+func moveCopies(tx DbHandler, offer usecases.TradeOffer, copyIds []int, from, to, actorId, receiverId int) error {
+	if len(copyIds) == 0 {
+		return nil
+	}
+	statement, args := tx.Dialect().Update("physical_copies").Set("library_id", to).
+		Where("id = ANY(?::int[])", intArray(copyIds)).Where("library_id = ?", from).
+		Returning("id", "game_id").Build()
+	row, err := tx.Query(statement, args...)
+	if err != nil {
+		return err
+	}
+	games := make(map[int]bool)
+	var moved []int
+	for row.Next() {
+		var copyId, gameId int
+		err = row.Scan(&copyId, &gameId)
+		if err != nil {
+			row.Close()
+			return err
+		}
+		moved = append(moved, copyId)
+		games[gameId] = true
+	}
+	row.Close()
+	if len(moved) != len(copyIds) {
+		return errTradeChanged
+	}
+
+	for gameId := range games {
+		res, err := tx.Execute(`WITH added AS (
+				INSERT INTO gamesInLib (game_id, library_id) SELECT $1, $2
+				WHERE NOT EXISTS (SELECT 1 FROM gamesInLib WHERE game_id = $1 AND library_id = $2)
+				RETURNING library_id)
+			UPDATE libraries SET version = version + 1, updated_at = now()
+			WHERE id IN (SELECT library_id FROM added)`,
+			gameId, to)
+		if err != nil {
+			return err
+		}
+		err = logEntryChange(tx, res, to, gameId, usecases.ChangeCreated)
+		if err != nil {
+			return err
+		}
+		res, err = tx.Execute(`WITH removed AS (
+				DELETE FROM gamesInLib WHERE game_id = $1 AND library_id = $2
+				AND NOT EXISTS (SELECT 1 FROM physical_copies WHERE game_id = $1 AND library_id = $2)
+				RETURNING library_id)
+			UPDATE libraries SET version = version + 1, updated_at = now()
+			WHERE id IN (SELECT library_id FROM removed)`,
+			gameId, from)
+		if err != nil {
+			return err
+		}
+		err = logEntryChange(tx, res, from, gameId, usecases.ChangeDeleted)
+		if err != nil {
+			return err
+		}
+	}
+	for _, copyId := range moved {
+		err = DbAdminRepo{dbHandler: tx}.Audit(usecases.AuditEntry{ActorId: actorId,
+			Action: usecases.AuditTrade, TargetId: receiverId,
+			Detail: fmt.Sprintf("Trade #%d moved copy #%d from library #%d to library #%d",
+				offer.Id, copyId, from, to)})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Logs the change when the statement touched the library
+func logEntryChange(tx DbHandler, res sql.Result, libraryId, gameId int, action string) error {
+	changed, err := res.RowsAffected()
+	if err != nil || changed == 0 {
+		return err
+	}
+	return logGameChange(tx, libraryId, gameId, action)
+}
+
+func (repo DbTradeRepo) CancelForLib(libraryId int) error {
+	statement, args := repo.dbHandler.Dialect().Update("trade_offers").
+		Set("status", usecases.TradeCancelled).SetExpr("updated_at = now()").
+		Where("status = ?", usecases.TradePending).
+		Where("(proposer_library_id = ? OR recipient_library_id = ?)", libraryId, libraryId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
@@ -0,0 +1,93 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"time"
+
+	"game-tracker/domain"
+	"game-tracker/usecases"
+)
+
+type DbTrashRepo DbRepo
+
+func NewDbTrashRepo(dbHandlers map[string]DbHandler) *DbTrashRepo {
+	dbTrashRepo := new(DbTrashRepo)
+	dbTrashRepo.dbHandlers = dbHandlers
+	dbTrashRepo.dbHandler = dbHandlers["DbTrashRepo"]
+	return dbTrashRepo
+}
+
+func (repo DbTrashRepo) Store(item usecases.TrashItem) (int64, error) {
+	entries, err := json.Marshal(entriesData(item.Entries))
+	if err != nil {
+		return 0, err
+	}
+	statement, args := repo.dbHandler.Dialect().Insert("trash").Set("user_id", item.UserId).
+		Set("kind", item.Kind).Set("library_id", item.LibraryId).Set("entries", string(entries)).
+		Returning("id").Build()
+	id, err := repo.dbHandler.QueryRow(statement, args...)
+	return int64(id), err
+}
+
+func (repo DbTrashRepo) FindByUser(userId int) ([]usecases.TrashItem, error) {
+	return repo.find("user_id = ?", userId)
+}
+
+func (repo DbTrashRepo) FindById(id int64) (usecases.TrashItem, error, int) {
+	items, err := repo.find("id = ?", id)
+	if err != nil {
+		return usecases.TrashItem{}, err, 500
+	}
+	if len(items) == 0 {
+		return usecases.TrashItem{}, domain.NewError(domain.CodeNotFound,
+			"Item #%d is not in the trash", id), 404
+	}
+	return items[0], nil, 200
+}
+
+func (repo DbTrashRepo) find(condition string, arg interface{}) ([]usecases.TrashItem, error) {
+	statement, args := repo.dbHandler.Dialect().Select("id", "user_id", "kind", "library_id",
+		"entries::text", "removed_at").From("trash").Where(condition, arg).OrderBy("id DESC").Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var items []usecases.TrashItem
+	for row.Next() {
+		var item usecases.TrashItem
+		var entries string
+		err = row.Scan(&item.Id, &item.UserId, &item.Kind, &item.LibraryId, &entries, &item.RemovedAt)
+		if err != nil {
+			return nil, err
+		}
+		var data []libraryEntryData
+		err = json.Unmarshal([]byte(entries), &data)
+		if err != nil {
+			return nil, err
+		}
+		item.Entries = entriesOf(data)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (repo DbTrashRepo) Remove(id int64) (bool, error) {
+	statement, args := repo.dbHandler.Dialect().Delete("trash").Where("id = ?", id).Build()
+	result, err := repo.dbHandler.Execute(statement, args...)
+	if err != nil {
+		return false, err
+	}
+	removed, err := result.RowsAffected()
+	return removed == 1, err
+}
+
+func (repo DbTrashRepo) Purge(before time.Time) (int64, error) {
+	statement, args := repo.dbHandler.Dialect().Delete("trash").Where("removed_at < ?", before).Build()
+	result, err := repo.dbHandler.Execute(statement, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
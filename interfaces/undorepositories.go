@@ -0,0 +1,97 @@
+package interfaces
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type DbUndoRepo DbRepo
+
+// The data column of undo_actions
+type undoData struct {
+	GameIds []int              `json:"gameIds"`
+	Before  []libraryEntryData `json:"before"`
+	After   []libraryEntryData `json:"after"`
+}
+
+func NewDbUndoRepo(dbHandlers map[string]DbHandler) *DbUndoRepo {
+	dbUndoRepo := new(DbUndoRepo)
+	dbUndoRepo.dbHandlers = dbHandlers
+	dbUndoRepo.dbHandler = dbHandlers["DbUndoRepo"]
+	return dbUndoRepo
+}
+
+func (repo DbUndoRepo) Store(action usecases.UndoAction, since time.Time) (int64, error) {
+	data, err := json.Marshal(undoData{GameIds: action.GameIds, Before: entriesData(action.Before),
+		After: entriesData(action.After)})
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	err = repo.dbHandler.Transaction(func(tx DbHandler) error {
+		_, err := tx.Execute(`DELETE FROM undo_actions WHERE user_id = $1
+			AND (undone_at IS NOT NULL OR created_at < $2)`, action.UserId, since)
+		if err != nil {
+			return err
+		}
+		statement, args := tx.Dialect().Insert("undo_actions").Set("user_id", action.UserId).
+			Set("kind", action.Kind).Set("library_id", action.LibraryId).Set("data", string(data)).
+			Returning("id").Build()
+		id, err = tx.QueryRow(statement, args...)
+		return err
+	})
+	return int64(id), err
+}
+
+func (repo DbUndoRepo) FindRecent(userId int, since time.Time) ([]usecases.UndoAction, error) {
+	statement, args := repo.dbHandler.Dialect().Select("id", "kind", "library_id", "data::text",
+		"created_at", "undone_at").From("undo_actions").Where("user_id = ?", userId).
+		Where("created_at >= ?", since).OrderBy("id DESC").Build()
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var actions []usecases.UndoAction
+	for row.Next() {
+		action := usecases.UndoAction{UserId: userId}
+		var data string
+		var undoneAt sql.NullTime
+		err = row.Scan(&action.Id, &action.Kind, &action.LibraryId, &data, &action.CreatedAt,
+			&undoneAt)
+		if err != nil {
+			return nil, err
+		}
+		var fields undoData
+		err = json.Unmarshal([]byte(data), &fields)
+		if err != nil {
+			return nil, err
+		}
+		action.GameIds = fields.GameIds
+		action.Before = entriesOf(fields.Before)
+		action.After = entriesOf(fields.After)
+		action.UndoneAt = undoneAt.Time
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+func (repo DbUndoRepo) SetUndone(id int64, undone bool) (bool, error) {
+	update := repo.dbHandler.Dialect().Update("undo_actions").Where("id = ?", id)
+	if undone {
+		update.SetExpr("undone_at = now()").Where("undone_at IS NULL")
+	} else {
+		update.SetExpr("undone_at = NULL").Where("undone_at IS NOT NULL")
+	}
+	statement, args := update.Build()
+	result, err := repo.dbHandler.Execute(statement, args...)
+	if err != nil {
+		return false, err
+	}
+	changed, err := result.RowsAffected()
+	return changed == 1, err
+}
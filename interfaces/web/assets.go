@@ -0,0 +1,92 @@
+package web
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const assetsPath = "/web/assets/"
+
+//go:embed assets
+var assetFiles embed.FS
+
+type asset struct {
+	content     []byte
+	contentType string
+	etag        string
+}
+
+var (
+	// Fingerprinted name of every asset, keyed by its plain name
+	assetNames = map[string]string{}
+	// Assets by fingerprinted name, plain names are kept too for links from outside
+	assets = map[string]asset{}
+)
+
+func init() {
+	err := fs.WalkDir(assetFiles, "assets", func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := assetFiles.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(content)
+		hash := hex.EncodeToString(sum[:])[:12]
+		name := strings.TrimPrefix(file, "assets/")
+		extension := path.Ext(name)
+		fingerprinted := strings.TrimSuffix(name, extension) + "." + hash + extension
+
+		contentType := mime.TypeByExtension(extension)
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		assetNames[name] = fingerprinted
+		assets[fingerprinted] = asset{content: content, contentType: contentType, etag: `"` + hash + `"`}
+		assets[name] = assets[fingerprinted]
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// The URL pages link an asset by, it changes whenever the asset does
+func assetUrl(name string) string {
+	fingerprinted, ok := assetNames[name]
+	if !ok {
+		panic("unknown asset " + name)
+	}
+	return assetsPath + fingerprinted
+}
+
+// Fingerprinted names are cached for good, plain names have to be revalidated
+func (site Site) ServeAsset(c *gin.Context) {
+	name := strings.TrimPrefix(c.Param("file"), "/")
+	file, ok := assets[name]
+	if !ok {
+		c.AbortWithStatus(404)
+		return
+	}
+
+	if assetNames[name] != "" {
+		c.Header("Cache-Control", "no-cache")
+	} else {
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	}
+	c.Header("ETag", file.etag)
+	c.Header("X-Content-Type-Options", "nosniff")
+	if c.Request.Header.Get("If-None-Match") == file.etag {
+		c.Status(304)
+		return
+	}
+	c.Data(200, file.contentType, file.content)
+}
@@ -0,0 +1,14 @@
+// Filters the game tables of the libraries page by name while typing
+document.addEventListener("DOMContentLoaded", function () {
+	var input = document.querySelector("input[data-filter]");
+	if (!input) {
+		return;
+	}
+	var rows = document.querySelectorAll("tr[data-name]");
+	input.addEventListener("input", function () {
+		var query = input.value.trim().toLowerCase();
+		rows.forEach(function (row) {
+			row.hidden = query !== "" && row.dataset.name.indexOf(query) === -1;
+		});
+	});
+});
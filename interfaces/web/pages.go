@@ -0,0 +1,207 @@
+package web
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/usecases"
+)
+
+// Shared by every page, the navigation is only shown with a user signed in
+type pageHeader struct {
+	UserName string
+}
+
+type loginPage struct {
+	pageHeader
+	Username string
+	Error    string
+}
+
+type gameRow struct {
+	LibraryId string
+	GameId    string
+	Name      string
+	Status    string
+	Platform  string
+}
+
+type libraryRow struct {
+	Id        string
+	UpdatedAt time.Time
+	Games     []gameRow
+}
+
+type librariesPage struct {
+	pageHeader
+	Libraries []libraryRow
+}
+
+type gamePage struct {
+	pageHeader
+	LibraryId string
+	Game      usecases.Game
+}
+
+type countRow struct {
+	Name  string
+	Count int
+}
+
+type statsPage struct {
+	pageHeader
+	Libraries   int
+	Games       int
+	TotalValue  float64
+	Statuses    []countRow
+	Platforms   []countRow
+	Playtime    []countRow //Minutes per platform
+	RefreshedAt *time.Time //Nil before the first refresh
+}
+
+func (site Site) ShowLogin(c *gin.Context) {
+	render(c, 200, "login", loginPage{})
+}
+
+func (site Site) Login(c *gin.Context) {
+	username := c.PostForm("username")
+	page := loginPage{Username: username}
+	id, err, code := site.profile(c).FindLoginId(username, c.PostForm("password"))
+	if err == nil {
+		_, err, code = site.profile(c).ShowActiveUser(id)
+	}
+	if err != nil {
+		page.Error = err.Error()
+		render(c, code, "login", page)
+		return
+	}
+
+	token, err := newSessionId()
+	if err == nil {
+		err = site.Sessions.Save(sessionPrefix+token, id)
+	}
+	if err != nil {
+		page.Error = "Cannot sign you in right now"
+		render(c, 500, "login", page)
+		return
+	}
+	site.setCookie(c, token, site.SessionTtl)
+	c.Redirect(303, "/web/libraries")
+}
+
+func (site Site) Logout(c *gin.Context) {
+	site.Sessions.Revoke(sessionPrefix + c.GetString("session"))
+	site.clearCookie(c)
+	c.Redirect(303, "/web/login")
+}
+
+func (site Site) ShowLibraries(c *gin.Context) {
+	profile := site.profile(c)
+	user, err, code := profile.ShowActiveUser(c.GetInt("userId"))
+	if err != nil {
+		renderError(c, code, err)
+		return
+	}
+
+	page := librariesPage{pageHeader: pageHeader{user.Name}}
+	for _, libraryId := range user.LibraryIds {
+		library, err, code := profile.ShowLibrary(user.Id, libraryId)
+		if err != nil {
+			renderError(c, code, err)
+			return
+		}
+		row := libraryRow{Id: library.ExternalId, UpdatedAt: library.UpdatedAt}
+		for _, gameId := range library.GameIds {
+			game, err, code := profile.ShowGame(user.Id, libraryId, gameId)
+			if err != nil {
+				renderError(c, code, err)
+				return
+			}
+			row.Games = append(row.Games, gameRow{LibraryId: library.ExternalId,
+				GameId: game.ExternalId, Name: game.Name, Status: game.Status,
+				Platform: game.Platform})
+		}
+		page.Libraries = append(page.Libraries, row)
+	}
+	render(c, 200, "libraries", page)
+}
+
+func (site Site) ShowGame(c *gin.Context) {
+	profile := site.profile(c)
+	user, err, code := profile.ShowActiveUser(c.GetInt("userId"))
+	if err != nil {
+		renderError(c, code, err)
+		return
+	}
+	libraryId, err, code := profile.FindLibraryId(c.Param("libraryId"))
+	if err != nil {
+		renderError(c, code, err)
+		return
+	}
+	gameId, err, code := profile.FindGameId(c.Param("gameId"))
+	if err != nil {
+		renderError(c, code, err)
+		return
+	}
+	game, err, code := profile.ShowGame(user.Id, libraryId, gameId)
+	if err != nil {
+		renderError(c, code, err)
+		return
+	}
+	render(c, 200, "game", gamePage{pageHeader: pageHeader{user.Name}, LibraryId: c.Param("libraryId"),
+		Game: game})
+}
+
+// Totals come from the summaries the stats job refreshes, the page says
+// how old they are
+func (site Site) ShowStats(c *gin.Context) {
+	user, err, code := site.profile(c).ShowActiveUser(c.GetInt("userId"))
+	if err != nil {
+		renderError(c, code, err)
+		return
+	}
+	stats, err, code := site.StatsInteractor.ShowStats(user.Id)
+	if err != nil {
+		renderError(c, code, err)
+		return
+	}
+
+	page := statsPage{pageHeader: pageHeader{user.Name}, Libraries: stats.Libraries,
+		Games: stats.Games, TotalValue: stats.Value, RefreshedAt: stats.RefreshedAt}
+	page.Statuses = countRows(namedCounts(stats.Statuses))
+	page.Platforms = countRows(namedCounts(stats.Platforms))
+	page.Playtime = countRows(namedCounts(stats.PlatformMinutes))
+	render(c, 200, "stats", page)
+}
+
+func namedCounts(counts map[string]int) map[string]int {
+	named := make(map[string]int)
+	for name, count := range counts {
+		named[orNone(name)] += count
+	}
+	return named
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+// Most frequent first, ties by name so the page is stable
+func countRows(counts map[string]int) []countRow {
+	rows := []countRow{}
+	for name, count := range counts {
+		rows = append(rows, countRow{Name: name, Count: count})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Count != rows[j].Count {
+			return rows[i].Count > rows[j].Count
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows
+}
@@ -0,0 +1,132 @@
+package web
+
+import (
+	"bytes"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/interfaces"
+	"game-tracker/usecases"
+)
+
+const sessionCookie = "session"
+
+// Web sessions share the store with refresh tokens, the prefix keeps a
+// session id from being exchanged for API tokens
+const sessionPrefix = "web:"
+
+// Pages only load their own assets, the API keeps its stricter default
+const contentSecurityPolicy = "default-src 'none'; style-src 'self'; script-src 'self'; " +
+	"img-src 'self'; form-action 'self'; frame-ancestors 'none'"
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+var pages = map[string]*template.Template{}
+
+var functions = template.FuncMap{
+	"asset": assetUrl,
+	"lower": strings.ToLower,
+}
+
+func init() {
+	for _, name := range []string{"login", "libraries", "game", "stats", "error"} {
+		pages[name] = template.Must(template.New(name).Funcs(functions).ParseFS(templateFiles,
+			"templates/layout.html", "templates/"+name+".html"))
+	}
+}
+
+// Serves the server-rendered UI under /web, signed in users are kept in a
+// session cookie instead of a token
+type Site struct {
+	ProfileInteractor usecases.ProfileUsecase
+	StatsInteractor   usecases.StatsUsecase
+	Sessions          interfaces.SessionStore
+	SessionTtl        int //Seconds
+}
+
+func (site Site) profile(c *gin.Context) usecases.ProfileUsecase {
+	return site.ProfileInteractor.WithLogger(interfaces.RequestLogger{RequestId: c.GetString("requestId")})
+}
+
+func render(c *gin.Context, code int, page string, data interface{}) {
+	var body bytes.Buffer
+	err := pages[page].ExecuteTemplate(&body, "layout", data)
+	if err != nil {
+		c.AbortWithError(500, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Security-Policy", contentSecurityPolicy)
+	c.Data(code, "text/html; charset=utf-8", body.Bytes())
+}
+
+type errorPage struct {
+	pageHeader
+	Message string
+}
+
+// Failures are shown on a page rather than in the JSON error envelope
+func renderError(c *gin.Context, code int, err error) {
+	render(c, code, "error", errorPage{Message: err.Error()})
+}
+
+// Lets signed in users through with their id stored as "userId", everyone
+// else is sent to the login page
+func (site Site) RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, err := c.Cookie(sessionCookie)
+		if err != nil || token == "" {
+			c.Redirect(303, "/web/login")
+			c.Abort()
+			return
+		}
+		userId, found, err := site.Sessions.Find(sessionPrefix + token)
+		if err != nil {
+			renderError(c, 500, fmt.Errorf("Cannot load your session"))
+			c.Abort()
+			return
+		}
+		if !found {
+			site.clearCookie(c)
+			c.Redirect(303, "/web/login")
+			c.Abort()
+			return
+		}
+		c.Set("userId", userId)
+		c.Set("session", token)
+		c.Next()
+	}
+}
+
+func (site Site) setCookie(c *gin.Context, token string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/web",
+		MaxAge:   maxAge,
+		Secure:   c.Request.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func (site Site) clearCookie(c *gin.Context) {
+	site.setCookie(c, "", -1)
+}
+
+func newSessionId() (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
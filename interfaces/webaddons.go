@@ -0,0 +1,87 @@
+package interfaces
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func gameTreeResult(c *gin.Context, tree usecases.GameTree) result.GameTree {
+	message := result.GameTree{UserId: c.Param("id"), LibraryId: c.Param("libId"),
+		GameId: tree.Game.ExternalId, Name: tree.Game.Name, Status: tree.Game.Status,
+		Value: tree.Game.Value, TotalValue: tree.TotalValue, Completed: tree.Completed,
+		Total: tree.Total, Completion: tree.Completion()}
+	for _, addon := range tree.Addons {
+		message.Addons = append(message.Addons, result.GameAddon{GameId: addon.Game.ExternalId,
+			Name: addon.Game.Name, Kind: addon.Kind, Status: addon.Game.Status, Value: addon.Game.Value})
+	}
+	return message
+}
+
+// The user, library and game ids of add-on routes
+func (handler WebserviceHandler) addonTarget(c *gin.Context) (int, int, int, error, int) {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		return 0, 0, 0, err, code
+	}
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
+	if err != nil {
+		return 0, 0, 0, err, code
+	}
+	return userId, libraryId, gameId, nil, 200
+}
+
+func (handler WebserviceHandler) ShowGameTree(c *gin.Context) (int, result.GameTree) {
+	userId, libraryId, gameId, err, code := handler.addonTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.GameTree{}
+	}
+	tree, err, code := handler.profile(c).ShowGameTree(userId, libraryId, gameId)
+	if err != nil {
+		c.Error(err)
+		return code, result.GameTree{}
+	}
+	return 200, gameTreeResult(c, tree)
+}
+
+// Answers with the tree of the new parent
+func (handler WebserviceHandler) AttachAddon(c *gin.Context) (int, result.GameTree) {
+	userId, libraryId, gameId, err, code := handler.addonTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.GameTree{}
+	}
+	parent := request.GameParent{}
+	err = c.BindJSON(&parent)
+	if err != nil {
+		return 400, result.GameTree{}
+	}
+	parentId, err, code := handler.profile(c).FindGameId(parent.ParentId)
+	if err != nil {
+		c.Error(err)
+		return code, result.GameTree{}
+	}
+	tree, err, code := handler.profile(c).AttachAddon(userId, libraryId, gameId, parentId, parent.Kind)
+	if err != nil {
+		c.Error(err)
+		return code, result.GameTree{}
+	}
+	return 200, gameTreeResult(c, tree)
+}
+
+func (handler WebserviceHandler) DetachAddon(c *gin.Context) int {
+	userId, libraryId, gameId, err, code := handler.addonTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	err, code = handler.profile(c).DetachAddon(userId, libraryId, gameId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	return 204
+}
@@ -0,0 +1,300 @@
+package interfaces
+
+import (
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+// Admin routes identify the caller by the id auth.CheckRole stored, the
+// user acted upon is named by :userId
+
+func adminUser(user usecases.User) result.AdminUser {
+	return result.AdminUser{Id: user.ExternalId, Name: user.Name, Role: user.Role,
+		Status: user.Status, StatusReason: user.StatusReason, SuspendedUntil: user.SuspendedUntil,
+		CreatedAt: user.CreatedAt, UpdatedAt: user.UpdatedAt}
+}
+
+func (handler WebserviceHandler) ListUsers(c *gin.Context) (int, result.AdminUsers) {
+	page, err := pageQuery(c, 50)
+	if err != nil {
+		c.Error(err)
+		return 400, result.AdminUsers{}
+	}
+	filter := usecases.UserFilter{Name: c.Query("name"), Role: c.Query("role"),
+		Status: c.Query("status"), Page: page}
+
+	users, next, err, code := handler.admin(c).ListUsers(c.GetInt("userId"), filter)
+	if err != nil {
+		c.Error(err)
+		return code, result.AdminUsers{}
+	}
+
+	message := result.AdminUsers{Limit: page.Limit, Cursor: next.String(), HasMore: !next.IsZero()}
+	for _, user := range users {
+		message.Users = append(message.Users, adminUser(user))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) ShowUserAsAdmin(c *gin.Context) (int, result.AdminUser) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("userId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.AdminUser{}
+	}
+	user, err, code := handler.admin(c).ShowUser(c.GetInt("userId"), userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.AdminUser{}
+	}
+	return 200, adminUser(user)
+}
+
+func (handler WebserviceHandler) ShowUserStats(c *gin.Context) (int, result.UserStats) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("userId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.UserStats{}
+	}
+	stats, err, code := handler.admin(c).ShowUserStats(c.GetInt("userId"), userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.UserStats{}
+	}
+	return 200, result.UserStats{UserId: c.Param("userId"), Libraries: stats.Libraries,
+		Games: stats.Games, Notifications: stats.Notifications, Changes: stats.Changes,
+		InfoBytes: stats.InfoBytes}
+}
+
+func (handler WebserviceHandler) ShowMetrics(c *gin.Context) (int, result.Metrics) {
+	metrics, err, code := handler.admin(c).ShowMetrics(c.GetInt("userId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Metrics{}
+	}
+	return 200, result.Metrics{Users: metrics.Users, Players: metrics.Players,
+		Libraries: metrics.Libraries, Games: metrics.Games, GameEntries: metrics.GameEntries,
+		UsersByStatus: metrics.UsersByStatus}
+}
+
+func (handler WebserviceHandler) SuspendUser(c *gin.Context) (int, result.AdminUser) {
+	userId, change, err, code := handler.bindStatusChange(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.AdminUser{}
+	}
+	user, err, code := handler.admin(c).Suspend(c.GetInt("userId"), userId, change.Reason,
+		change.Until)
+	if err != nil {
+		c.Error(err)
+		return code, result.AdminUser{}
+	}
+	return 200, adminUser(user)
+}
+
+func (handler WebserviceHandler) BanUser(c *gin.Context) (int, result.AdminUser) {
+	userId, change, err, code := handler.bindStatusChange(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.AdminUser{}
+	}
+	user, err, code := handler.admin(c).Ban(c.GetInt("userId"), userId, change.Reason)
+	if err != nil {
+		c.Error(err)
+		return code, result.AdminUser{}
+	}
+	return 200, adminUser(user)
+}
+
+func (handler WebserviceHandler) ReinstateUser(c *gin.Context) (int, result.AdminUser) {
+	userId, change, err, code := handler.bindStatusChange(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.AdminUser{}
+	}
+	user, err, code := handler.admin(c).Reinstate(c.GetInt("userId"), userId,
+		change.Reason)
+	if err != nil {
+		c.Error(err)
+		return code, result.AdminUser{}
+	}
+	return 200, adminUser(user)
+}
+
+func (handler WebserviceHandler) bindStatusChange(c *gin.Context) (int, request.StatusChange, error, int) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("userId"))
+	if err != nil {
+		return 0, request.StatusChange{}, err, code
+	}
+	change := request.StatusChange{}
+	err = c.BindJSON(&change)
+	if err != nil {
+		return 0, request.StatusChange{}, err, 400
+	}
+	return userId, change, nil, 200
+}
+
+// Lets the routes of a user through unless the account may not write,
+// see suspension.BlockWrites
+func (handler WebserviceHandler) CheckWritable(c *gin.Context) (error, int) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		return err, code
+	}
+	return handler.profile(c).CheckWritable(userId)
+}
+
+// Issues a short-lived access token for the user, "act" names the admin
+// behind it. No refresh token is issued so the session ends with the token.
+func (handler WebserviceHandler) Impersonate(c *gin.Context) (result.Token, int) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("userId"))
+	if err != nil {
+		c.Error(err)
+		return result.Token{}, code
+	}
+	impersonation := request.Impersonation{}
+	err = c.BindJSON(&impersonation)
+	if err != nil {
+		return result.Token{}, 400
+	}
+
+	adminId := c.GetInt("userId")
+	user, err, code := handler.admin(c).Impersonate(adminId, userId, impersonation.Reason)
+	if err != nil {
+		c.Error(err)
+		return result.Token{}, code
+	}
+	tokenString, err := handler.createToken(user.Id, user.ExternalId, user.Role,
+		jwt.MapClaims{"act": adminId})
+	if err != nil {
+		c.Error(err)
+		return result.Token{}, 500
+	}
+	logf(c, "Issued impersonation token for user #%d", userId)
+	return result.Token{AccessToken: tokenString, ExpiresIn: int(accessTokenTtl.Seconds())}, 201
+}
+
+func maintenanceResult(status MaintenanceStatus) result.Maintenance {
+	return result.Maintenance{Enabled: status.Enabled, RetryAfter: status.RetryAfter,
+		Reason: status.Reason}
+}
+
+func (handler WebserviceHandler) ShowMaintenance(c *gin.Context) (int, result.Maintenance) {
+	err, code := handler.admin(c).Authorize(c.GetInt("userId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Maintenance{}
+	}
+	return 200, maintenanceResult(handler.Maintenance.Status())
+}
+
+func (handler WebserviceHandler) SetMaintenance(c *gin.Context) (int, result.Maintenance) {
+	change := request.Maintenance{}
+	err := c.BindJSON(&change)
+	if err != nil {
+		return 400, result.Maintenance{}
+	}
+	if change.RetryAfter < 0 {
+		c.Error(domain.NewFieldError("retryAfter", "Must not be negative"))
+		return 400, result.Maintenance{}
+	}
+
+	err, code := handler.admin(c).ToggleMaintenance(c.GetInt("userId"), change.Enabled,
+		change.Reason)
+	if err != nil {
+		c.Error(err)
+		return code, result.Maintenance{}
+	}
+	status := MaintenanceStatus{Enabled: change.Enabled, RetryAfter: change.RetryAfter,
+		Reason: change.Reason}
+	handler.Maintenance.Set(status)
+	return 200, maintenanceResult(status)
+}
+
+// Flags store internal ids, users that no longer exist are left out
+func (handler WebserviceHandler) flagResult(c *gin.Context, flag usecases.FeatureFlag) result.FeatureFlag {
+	message := result.FeatureFlag{Name: flag.Name, Description: flag.Description,
+		Enabled: flag.Enabled, Percentage: flag.Percentage, UserIds: []string{},
+		UpdatedAt: flag.UpdatedAt}
+	for _, userId := range flag.UserIds {
+		user, err, _ := handler.profile(c).ShowUser(userId)
+		if err == nil {
+			message.UserIds = append(message.UserIds, user.ExternalId)
+		}
+	}
+	return message
+}
+
+func (handler WebserviceHandler) ListFlags(c *gin.Context) (int, result.FeatureFlags) {
+	flags, err, code := handler.admin(c).ListFlags(c.GetInt("userId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.FeatureFlags{}
+	}
+	message := result.FeatureFlags{}
+	for _, flag := range flags {
+		message.Flags = append(message.Flags, handler.flagResult(c, flag))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) SetFlag(c *gin.Context) (int, result.FeatureFlag) {
+	change := request.FeatureFlag{}
+	err := c.BindJSON(&change)
+	if err != nil {
+		return 400, result.FeatureFlag{}
+	}
+	flag := usecases.FeatureFlag{Name: c.Param("name"), Description: change.Description,
+		Enabled: change.Enabled, Percentage: change.Percentage}
+	for _, externalId := range change.UserIds {
+		userId, err, code := handler.profile(c).FindUserId(externalId)
+		if err != nil {
+			c.Error(err)
+			return code, result.FeatureFlag{}
+		}
+		flag.UserIds = append(flag.UserIds, userId)
+	}
+
+	flag, err, code := handler.admin(c).SetFlag(c.GetInt("userId"), flag)
+	if err != nil {
+		c.Error(err)
+		return code, result.FeatureFlag{}
+	}
+	return 200, handler.flagResult(c, flag)
+}
+
+func (handler WebserviceHandler) RemoveFlag(c *gin.Context) int {
+	err, code := handler.admin(c).RemoveFlag(c.GetInt("userId"), c.Param("name"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	return 204
+}
+
+// Journal entries about a flagged game are hidden whole unless the reader
+// asks for spoilers
+func (handler WebserviceHandler) FlagSpoilers(c *gin.Context) (int, result.GameSpoilers) {
+	change := request.GameSpoilers{}
+	err := c.BindJSON(&change)
+	if err != nil {
+		return 400, result.GameSpoilers{}
+	}
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.GameSpoilers{}
+	}
+	game, err, code := handler.admin(c).FlagSpoilers(c.GetInt("userId"), gameId,
+		change.ContainsSpoilers)
+	if err != nil {
+		c.Error(err)
+		return code, result.GameSpoilers{}
+	}
+	return 200, result.GameSpoilers{GameId: game.ExternalId, ContainsSpoilers: game.ContainsSpoilers}
+}
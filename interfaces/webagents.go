@@ -0,0 +1,181 @@
+package interfaces
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+// Desktop agents authenticate with the token they were given when added
+const agentKeyHeader = "X-Agent-Key"
+
+func agentResult(userId string, agent usecases.Agent) result.Agent {
+	return result.Agent{Id: agent.Id, UserId: userId, Name: agent.Name, LastSeenAt: agent.LastSeenAt,
+		CreatedAt: agent.CreatedAt}
+}
+
+func executableResult(userId string, mapping usecases.ExecutableGame) result.Executable {
+	return result.Executable{UserId: userId, Executable: mapping.Executable,
+		GameId: mapping.GameExternalId, GameName: mapping.GameName}
+}
+
+func (handler WebserviceHandler) AddAgent(c *gin.Context) (int, result.Agent) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Agent{}
+	}
+	agent := request.Agent{}
+	err = c.BindJSON(&agent)
+	if err != nil {
+		return 400, result.Agent{}
+	}
+	added, token, err, code := handler.agents(c).AddAgent(userId, agent.Name)
+	if err != nil {
+		c.Error(err)
+		return code, result.Agent{}
+	}
+	logf(c, "Added agent #%d", added.Id)
+	message := agentResult(c.Param("id"), added)
+	message.Token = token
+	return code, message
+}
+
+func (handler WebserviceHandler) ShowAgents(c *gin.Context) (int, result.Agents) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Agents{}
+	}
+	agents, err, code := handler.agents(c).ShowAgents(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Agents{}
+	}
+	message := result.Agents{UserId: c.Param("id")}
+	for _, agent := range agents {
+		message.Agents = append(message.Agents, agentResult(c.Param("id"), agent))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) RemoveAgent(c *gin.Context) int {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	agentId, err := strconv.Atoi(c.Param("agentId"))
+	if err != nil {
+		c.Error(domain.NewError(domain.CodeNotFound, "Agent '%s' does not exist", c.Param("agentId")))
+		return 404
+	}
+	err, code = handler.agents(c).RemoveAgent(userId, agentId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Removed agent #%d", agentId)
+	return 204
+}
+
+func (handler WebserviceHandler) MapExecutable(c *gin.Context) (int, result.Executable) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Executable{}
+	}
+	mapping := request.ExecutableMapping{}
+	err = c.BindJSON(&mapping)
+	if err != nil {
+		return 400, result.Executable{}
+	}
+	gameId, err, code := handler.profile(c).FindGameId(mapping.GameId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Executable{}
+	}
+	mapped, err, code := handler.agents(c).MapExecutable(userId, c.Param("executable"), gameId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Executable{}
+	}
+	logf(c, "Mapped %s to game #%d", mapped.Executable, gameId)
+	return 200, executableResult(c.Param("id"), mapped)
+}
+
+func (handler WebserviceHandler) ShowExecutables(c *gin.Context) (int, result.Executables) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Executables{}
+	}
+	mappings, err, code := handler.agents(c).ShowExecutables(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Executables{}
+	}
+	message := result.Executables{UserId: c.Param("id")}
+	for _, mapping := range mappings {
+		message.Executables = append(message.Executables, executableResult(c.Param("id"), mapping))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) UnmapExecutable(c *gin.Context) int {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	err, code = handler.agents(c).UnmapExecutable(userId, c.Param("executable"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Unmapped %s", c.Param("executable"))
+	return 204
+}
+
+// Reports from desktop agents, they carry no user token and are not tied to
+// a user in the path, the agent token names the user
+func (handler WebserviceHandler) ReportAgentEvent(c *gin.Context) (int, result.AgentReport) {
+	token := c.GetHeader(agentKeyHeader)
+	if token == "" {
+		c.Error(domain.NewError(domain.CodeUnauthorized, "The %s header is missing", agentKeyHeader))
+		return 401, result.AgentReport{}
+	}
+	event := request.AgentEvent{}
+	err := c.BindJSON(&event)
+	if err != nil {
+		return 400, result.AgentReport{}
+	}
+	var at time.Time
+	if event.At != "" {
+		at, err = time.Parse(time.RFC3339, event.At)
+		if err != nil {
+			c.Error(domain.NewFieldError("at", "Must be an RFC 3339 time"))
+			return 400, result.AgentReport{}
+		}
+	}
+	report, err, code := handler.agents(c).ReportProcess(token, usecases.AgentEvent{
+		Executable: event.Executable, Event: event.Event, At: at})
+	if err != nil {
+		c.Error(err)
+		return code, result.AgentReport{}
+	}
+	message := result.AgentReport{Executable: report.Executable, Event: report.Event,
+		Mapped: report.Mapped, GameId: report.Game.GameExternalId, GameName: report.Game.GameName}
+	if report.Session.Id != 0 {
+		session := sessionResult(report.Session)
+		message.Session = &session
+		logf(c, "Agent logged session #%d", report.Session.Id)
+	}
+	return 200, message
+}
@@ -1,38 +1,104 @@
 package interfaces
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 
+	"game-tracker/domain"
 	"game-tracker/models/request"
+	"game-tracker/models/result"
 )
 
-func (handler WebserviceHandler) Login(c *gin.Context) (string, int) {
+const accessTokenTtl = 15 * time.Minute
+
+func (handler WebserviceHandler) Login(c *gin.Context) (result.Token, int) {
 	loginInfo := request.LoginInfo{}
 	err := c.BindJSON(&loginInfo)
 	if err != nil {
-		return "", 400
+		return result.Token{}, 400
+	}
+
+	id, err, code := handler.profile(c).FindLoginId(loginInfo.Username, loginInfo.Password)
+	if err != nil {
+		c.Error(err)
+		return result.Token{}, code
+	}
+	return handler.issueTokens(c, id)
+}
+
+// Exchanges a refresh token for a new token pair, the old refresh token
+// is revoked as it is read so each one can be used only once, even by
+// requests racing each other
+func (handler WebserviceHandler) Refresh(c *gin.Context) (result.Token, int) {
+	refresh := request.Refresh{}
+	err := c.BindJSON(&refresh)
+	if err != nil {
+		return result.Token{}, 400
+	}
+
+	id, found, err := handler.Sessions.Take(refresh.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return result.Token{}, 500
+	}
+	if !found {
+		c.Error(domain.NewError(domain.CodeUnauthorized, "Refresh token is invalid or expired"))
+		return result.Token{}, 401
 	}
+	return handler.issueTokens(c, id)
+}
 
-	id, err, code := handler.ProfileInteractor.FindLoginId(loginInfo.Username, loginInfo.Password)
+func (handler WebserviceHandler) issueTokens(c *gin.Context, id int) (result.Token, int) {
+	user, err, code := handler.profile(c).ShowActiveUser(id)
 	if err != nil {
 		c.Error(err)
-		return "", code
+		return result.Token{}, code
 	}
 
-	tokenString, err := createToken(id)
+	tokenString, err := handler.createToken(id, user.ExternalId, user.Role, nil)
+	if err != nil {
+		c.Error(err)
+		return result.Token{}, 500
+	}
+	refreshToken, err := newRefreshToken()
+	if err == nil {
+		err = handler.Sessions.Save(refreshToken, id)
+	}
 	if err != nil {
 		c.Error(err)
-		return "", 500
+		return result.Token{}, 500
 	}
 
-	return tokenString, 200
+	return result.Token{AccessToken: tokenString, RefreshToken: refreshToken,
+		ExpiresIn: int(accessTokenTtl.Seconds())}, 200
 }
 
-func createToken(id int) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id": id,
-	})
-	tokenString, err := token.SignedString([]byte("5230"))
+// The internal id stays inside the signed token, routes only ever see "sub".
+// Extra claims such as "act" for impersonation are added as given.
+func (handler WebserviceHandler) createToken(id int, externalId, role string, extra jwt.MapClaims) (string, error) {
+	claims := jwt.MapClaims{
+		"id":   id,
+		"sub":  externalId,
+		"role": role,
+		"exp":  time.Now().Add(accessTokenTtl).Unix(),
+	}
+	for name, value := range extra {
+		claims[name] = value
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(handler.TokenSecret)
 	return tokenString, err
 }
+
+func newRefreshToken() (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
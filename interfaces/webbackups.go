@@ -0,0 +1,88 @@
+package interfaces
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func saveBackupResult(c *gin.Context, backup usecases.SaveBackup) result.SaveBackup {
+	return result.SaveBackup{Id: backup.Id, UserId: c.Param("id"), LibraryId: c.Param("libId"),
+		GameId: backup.GameExternalId, GameName: backup.GameName, Location: backup.Location,
+		Size: backup.SizeBytes, Checksum: backup.Checksum, BackedUpAt: backup.BackedUpAt,
+		CreatedAt: backup.CreatedAt}
+}
+
+func (handler WebserviceHandler) AddBackup(c *gin.Context) (int, result.SaveBackup) {
+	userId, libraryId, gameId, err, code := handler.addonTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.SaveBackup{}
+	}
+	backup := request.SaveBackup{}
+	err = c.BindJSON(&backup)
+	if err != nil {
+		return 400, result.SaveBackup{}
+	}
+	var backedUpAt time.Time
+	if backup.BackedUpAt != "" {
+		backedUpAt, err = time.Parse(time.RFC3339, backup.BackedUpAt)
+		if err != nil {
+			c.Error(domain.NewFieldError("backedUpAt", "Must be an RFC 3339 time"))
+			return 400, result.SaveBackup{}
+		}
+	}
+
+	added, err, code := handler.profile(c).AddBackup(userId, libraryId, gameId, usecases.SaveBackup{
+		Location: backup.Location, SizeBytes: backup.Size, Checksum: backup.Checksum,
+		BackedUpAt: backedUpAt})
+	if err != nil {
+		c.Error(err)
+		return code, result.SaveBackup{}
+	}
+	return 201, saveBackupResult(c, added)
+}
+
+func (handler WebserviceHandler) ShowBackups(c *gin.Context) (int, result.SaveBackups) {
+	userId, libraryId, gameId, err, code := handler.addonTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.SaveBackups{}
+	}
+	backups, err, code := handler.profile(c).ShowBackups(userId, libraryId, gameId)
+	if err != nil {
+		c.Error(err)
+		return code, result.SaveBackups{}
+	}
+	message := result.SaveBackups{UserId: c.Param("id"), LibraryId: c.Param("libId"),
+		GameId: c.Param("gameId")}
+	for _, backup := range backups {
+		message.Backups = append(message.Backups, saveBackupResult(c, backup))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) RemoveBackup(c *gin.Context) int {
+	userId, libraryId, gameId, err, code := handler.addonTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	backupId, err := strconv.Atoi(c.Param("backupId"))
+	if err != nil {
+		c.Error(domain.NewError(domain.CodeNotFound, "Backup '%s' does not exist", c.Param("backupId")))
+		return 404
+	}
+	err, code = handler.profile(c).RemoveBackup(userId, libraryId, gameId, backupId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	return 204
+}
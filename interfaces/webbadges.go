@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/result"
+)
+
+func (handler WebserviceHandler) ShowBadges(c *gin.Context) (int, result.Badges) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Badges{}
+	}
+	badges, err, code := handler.badges(c).ShowBadges(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Badges{}
+	}
+	message := result.Badges{UserId: c.Param("id")}
+	for _, badge := range badges {
+		name := handler.translateText(c, badge.Rule.Name)
+		description := handler.translateText(c, badge.Rule.Description)
+		message.Badges = append(message.Badges, result.Badge{Id: badge.Rule.Id, Name: name,
+			Description: description, Goal: badge.Rule.Goal, Progress: badge.Progress,
+			Earned: badge.Earned, EarnedAt: badge.EarnedAt})
+	}
+	return 200, message
+}
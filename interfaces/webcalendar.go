@@ -0,0 +1,373 @@
+package interfaces
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+// Without a range sessions of the last month and the next year are listed
+const (
+	defaultSessionsPast   = 30 * 24 * time.Hour
+	defaultSessionsFuture = 365 * 24 * time.Hour
+)
+
+func sessionResult(session usecases.PlaySession) result.PlaySession {
+	message := result.PlaySession{Id: session.Id, GameId: session.GameExternalId,
+		GameName: session.GameName, StartsAt: session.StartsAt, EndsAt: session.EndsAt(),
+		Minutes: session.Minutes, Notes: session.Notes, CreatedAt: session.CreatedAt}
+	for _, partner := range session.Partners {
+		message.Partners = append(message.Partners, result.SessionPartner{UserId: partner.ExternalId,
+			UserName: partner.Name})
+	}
+	return message
+}
+
+func coopResult(userId string, partner usecases.CoopPartner) result.CoopPartner {
+	return result.CoopPartner{UserId: userId, PartnerId: partner.Partner.ExternalId,
+		PartnerName: partner.Partner.Name, Sessions: partner.Sessions, Minutes: partner.Minutes,
+		LastPlayedAt: partner.LastPlayedAt, Games: gameMinutesResult(partner.Games)}
+}
+
+func gameMinutesResult(games []usecases.GameMinutes) []result.GameMinutes {
+	var message []result.GameMinutes
+	for _, game := range games {
+		message = append(message, result.GameMinutes{GameId: game.GameExternalId,
+			GameName: game.GameName, Minutes: game.Minutes})
+	}
+	return message
+}
+
+// Partners are named by their user ids
+func (handler WebserviceHandler) partnerIds(c *gin.Context, partners []string) ([]int, error, int) {
+	var ids []int
+	for _, partner := range partners {
+		id, err, code := handler.profile(c).FindUserId(partner)
+		if err != nil {
+			return nil, err, code
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil, 200
+}
+
+func releaseResult(release usecases.Release) result.Release {
+	return result.Release{GameId: release.GameExternalId, GameName: release.GameName,
+		Date: release.Date.Format("2006-01-02"), Source: release.Source,
+		UpdatedAt: release.UpdatedAt}
+}
+
+// A day as a date, empty for the zero time
+func formatDay(day time.Time) string {
+	if day.IsZero() {
+		return ""
+	}
+	return day.Format("2006-01-02")
+}
+
+func (handler WebserviceHandler) AddSession(c *gin.Context) (int, result.PlaySession) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.PlaySession{}
+	}
+	session := request.PlaySession{}
+	err = c.BindJSON(&session)
+	if err != nil {
+		return 400, result.PlaySession{}
+	}
+	gameId, err, code := handler.profile(c).FindGameId(session.GameId)
+	if err != nil {
+		c.Error(err)
+		return code, result.PlaySession{}
+	}
+	partnerIds, err, code := handler.partnerIds(c, session.Partners)
+	if err != nil {
+		c.Error(err)
+		return code, result.PlaySession{}
+	}
+
+	added, err, code := handler.calendar(c).AddSession(userId, gameId, session.StartsAt,
+		session.Minutes, session.Notes, partnerIds)
+	if err != nil {
+		c.Error(err)
+		return code, result.PlaySession{}
+	}
+	logf(c, "Added session #%d", added.Id)
+	return code, sessionResult(added)
+}
+
+func (handler WebserviceHandler) ShowSessions(c *gin.Context) (int, result.PlaySessions) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.PlaySessions{}
+	}
+	now := time.Now().UTC()
+	from, err := timeQuery(c, "from", now.Add(-defaultSessionsPast))
+	if err != nil {
+		c.Error(err)
+		return 400, result.PlaySessions{}
+	}
+	to, err := timeQuery(c, "to", now.Add(defaultSessionsFuture))
+	if err != nil {
+		c.Error(err)
+		return 400, result.PlaySessions{}
+	}
+
+	sessions, err, code := handler.calendar(c).ShowSessions(userId, from, to)
+	if err != nil {
+		c.Error(err)
+		return code, result.PlaySessions{}
+	}
+	message := result.PlaySessions{UserId: c.Param("id"), From: from, To: to}
+	for _, session := range sessions {
+		message.Sessions = append(message.Sessions, sessionResult(session))
+	}
+	return 200, message
+}
+
+func timeQuery(c *gin.Context, name string, fallback time.Time) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, domain.NewFieldError(name, "Must be an RFC 3339 time")
+	}
+	return parsed, nil
+}
+
+func (handler WebserviceHandler) EditSession(c *gin.Context) (int, result.PlaySession) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.PlaySession{}
+	}
+	sessionId, err := strconv.Atoi(c.Param("sessionId"))
+	if err != nil {
+		c.Error(domain.NewError(domain.CodeNotFound, "Session '%s' does not exist",
+			c.Param("sessionId")))
+		return 404, result.PlaySession{}
+	}
+	details := request.SessionDetails{}
+	err = c.BindJSON(&details)
+	if err != nil {
+		return 400, result.PlaySession{}
+	}
+	partnerIds, err, code := handler.partnerIds(c, details.Partners)
+	if err != nil {
+		c.Error(err)
+		return code, result.PlaySession{}
+	}
+
+	session, err, code := handler.calendar(c).EditSession(userId, sessionId, details.Notes,
+		partnerIds)
+	if err != nil {
+		c.Error(err)
+		return code, result.PlaySession{}
+	}
+	logf(c, "Edited session #%d", sessionId)
+	return 200, sessionResult(session)
+}
+
+// Co-op totals with every partner, or with the one in the path
+func (handler WebserviceHandler) ShowCoop(c *gin.Context) (int, result.Coop) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Coop{}
+	}
+	partnerId := 0
+	if c.Param("partnerId") != "" {
+		partnerId, err, code = handler.profile(c).FindUserId(c.Param("partnerId"))
+		if err != nil {
+			c.Error(err)
+			return code, result.Coop{}
+		}
+	}
+
+	partners, err, code := handler.calendar(c).ShowCoop(userId, partnerId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Coop{}
+	}
+	message := result.Coop{UserId: c.Param("id")}
+	for _, partner := range partners {
+		message.Partners = append(message.Partners, coopResult(c.Param("id"), partner))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) RemoveSession(c *gin.Context) int {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	sessionId, err := strconv.Atoi(c.Param("sessionId"))
+	if err != nil {
+		c.Error(domain.NewError(domain.CodeNotFound, "Session '%s' does not exist",
+			c.Param("sessionId")))
+		return 404
+	}
+
+	err, code = handler.calendar(c).RemoveSession(userId, sessionId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Deleted session #%d", sessionId)
+	return 204
+}
+
+func (handler WebserviceHandler) TrackRelease(c *gin.Context) (int, result.Release) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Release{}
+	}
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Release{}
+	}
+	release := request.Release{}
+	err = c.BindJSON(&release)
+	if err != nil {
+		return 400, result.Release{}
+	}
+	// Without a date the metadata provider is asked for one
+	var date time.Time
+	if release.Date != "" {
+		date, err = time.Parse("2006-01-02", release.Date)
+		if err != nil {
+			c.Error(domain.NewFieldError("date", "Must be a date such as 2026-11-01"))
+			return 400, result.Release{}
+		}
+	}
+
+	tracked, err, code := handler.calendar(c).TrackRelease(userId, gameId, date)
+	if err != nil {
+		c.Error(err)
+		return code, result.Release{}
+	}
+	return 200, releaseResult(tracked)
+}
+
+func (handler WebserviceHandler) UntrackRelease(c *gin.Context) int {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	err, code = handler.calendar(c).UntrackRelease(userId, gameId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	return 204
+}
+
+func (handler WebserviceHandler) ShowStreak(c *gin.Context) (int, result.Streak) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Streak{}
+	}
+	streak, err, code := handler.calendar(c).ShowStreak(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Streak{}
+	}
+	return 200, result.Streak{UserId: c.Param("id"), Current: streak.Current,
+		CurrentSince: formatDay(streak.CurrentSince), Longest: streak.Longest,
+		LongestFrom: formatDay(streak.LongestFrom), LongestTo: formatDay(streak.LongestTo),
+		PlayedToday: streak.PlayedToday, Milestones: streak.Milestones,
+		NextMilestone: streak.NextMilestone}
+}
+
+func (handler WebserviceHandler) ShowHeatmap(c *gin.Context) (int, result.Heatmap) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Heatmap{}
+	}
+	heatmap, err, code := handler.calendar(c).ShowHeatmap(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Heatmap{}
+	}
+	message := result.Heatmap{UserId: c.Param("id"), From: formatDay(heatmap.From),
+		To: formatDay(heatmap.To), Minutes: heatmap.Minutes, ActiveDays: heatmap.ActiveDays,
+		MaxMinutes: heatmap.MaxMinutes}
+	for _, day := range heatmap.Days {
+		message.Days = append(message.Days, result.HeatmapDay{Date: formatDay(day.Day),
+			Minutes: day.Minutes, Level: day.Level})
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) ShowReleases(c *gin.Context) (int, result.Releases) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Releases{}
+	}
+	releases, err, code := handler.calendar(c).ShowReleases(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Releases{}
+	}
+	message := result.Releases{UserId: c.Param("id")}
+	for _, release := range releases {
+		message.Releases = append(message.Releases, releaseResult(release))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) IssueCalendarToken(c *gin.Context) (int, result.CalendarLink) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.CalendarLink{}
+	}
+	token, err, code := handler.calendar(c).IssueCalendarToken(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.CalendarLink{}
+	}
+	return 201, result.CalendarLink{UserId: c.Param("id"), Token: token}
+}
+
+// Calendar apps subscribe to /calendar/{token}.ics, the token is the only credential
+func (handler WebserviceHandler) ShowCalendar(c *gin.Context) (int, result.Calendar) {
+	token := strings.TrimSuffix(c.Param("file"), ".ics")
+	user, sessions, releases, err, code := handler.calendar(c).ShowCalendar(token)
+	if err != nil {
+		c.Error(err)
+		return code, result.Calendar{}
+	}
+	message := result.Calendar{UserName: user.Name}
+	for _, session := range sessions {
+		message.Sessions = append(message.Sessions, sessionResult(session))
+	}
+	for _, release := range releases {
+		message.Releases = append(message.Releases, releaseResult(release))
+	}
+	logf(c, "Printed calendar of user #%d", user.Id)
+	return 200, message
+}
@@ -0,0 +1,67 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/result"
+)
+
+// Keeps proxies from closing sockets that have nothing to push
+const websocketPingInterval = 30 * time.Second
+
+// Upgrades to a WebSocket pushing the changes of the user as any instance
+// writes them, until either side closes. A socket closed by the server
+// means changes may have been missed, clients sync from their cursor.
+func (handler WebserviceHandler) WatchChanges(c *gin.Context) int {
+	if !isWebsocketUpgrade(c.Request) {
+		c.Error(domain.NewError(domain.CodeInvalid, "Request is not a WebSocket upgrade"))
+		return 400
+	}
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	changes, stop, err, code := handler.ChangeStreamInteractor.Watch(userId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	defer stop()
+
+	socket, err := upgradeWebsocket(c.Writer, c.Request)
+	if err != nil {
+		c.Error(err)
+		return 400
+	}
+	defer socket.Close()
+	logf(c, "Watching changes of user #%d", userId)
+
+	for {
+		select {
+		case change, open := <-changes:
+			if !open {
+				return 101
+			}
+			message, err := json.Marshal(result.WatchedChange{
+				Cursor: strconv.FormatInt(change.Id, 10),
+				Change: result.Change{Entity: change.Entity, EntityId: change.EntityId,
+					ParentId: change.ParentId, Action: change.Action, ChangedAt: change.ChangedAt},
+			})
+			if err != nil || socket.WriteText(message) != nil {
+				return 101
+			}
+		case <-socket.Done():
+			return 101
+		case <-time.After(websocketPingInterval):
+			if socket.writeFrame(websocketPing, nil) != nil {
+				return 101
+			}
+		}
+	}
+}
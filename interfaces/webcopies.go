@@ -0,0 +1,262 @@
+package interfaces
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func physicalCopyResult(physical usecases.PhysicalCopy) result.PhysicalCopy {
+	return result.PhysicalCopy{Id: physical.Id, GameId: physical.GameExternalId,
+		GameName: physical.GameName, Barcode: physical.Barcode, Platform: physical.Platform,
+		PaidPrice: physical.PaidPrice, PaidCurrency: physical.PaidCurrency, Value: physical.Value,
+		ValueCurrency: physical.ValueCurrency, ValuedAt: physical.ValuedAt, CreatedAt: physical.CreatedAt}
+}
+
+// The user and library ids of copy routes
+func (handler WebserviceHandler) copyTarget(c *gin.Context) (int, int, error, int) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		return 0, 0, err, code
+	}
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
+	if err != nil {
+		return 0, 0, err, code
+	}
+	return userId, libraryId, nil, 200
+}
+
+func (handler WebserviceHandler) ScanBarcode(c *gin.Context) (int, result.PhysicalCopy) {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.PhysicalCopy{}
+	}
+	scan := request.BarcodeScan{}
+	err = c.BindJSON(&scan)
+	if err != nil {
+		return 400, result.PhysicalCopy{}
+	}
+
+	physical, err, code := handler.profile(c).ScanBarcode(userId, libraryId, scan.Barcode)
+	if err != nil {
+		c.Error(err)
+		return code, result.PhysicalCopy{}
+	}
+	logf(c, "Scanned %s into library #%d as copy #%d", physical.Barcode, libraryId, physical.Id)
+	return 201, physicalCopyResult(physical)
+}
+
+func (handler WebserviceHandler) ShowCopies(c *gin.Context) (int, result.PhysicalCopies) {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.PhysicalCopies{}
+	}
+	page, err := pageQuery(c, 50)
+	if err != nil {
+		c.Error(err)
+		return 400, result.PhysicalCopies{}
+	}
+	copies, next, err, code := handler.profile(c).ShowCopies(userId, libraryId, page)
+	if err != nil {
+		c.Error(err)
+		return code, result.PhysicalCopies{}
+	}
+	message := result.PhysicalCopies{UserId: c.Param("id"), LibraryId: c.Param("libId"),
+		Limit: page.Limit, Cursor: next.String(), HasMore: !next.IsZero()}
+	for _, physical := range copies {
+		message.Copies = append(message.Copies, physicalCopyResult(physical))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) RemoveCopy(c *gin.Context) int {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	copyId, err := strconv.Atoi(c.Param("copyId"))
+	if err != nil {
+		c.Error(domain.NewError(domain.CodeNotFound, "Copy '%s' does not exist", c.Param("copyId")))
+		return 404
+	}
+	err, code = handler.profile(c).RemoveCopy(userId, libraryId, copyId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Deleted copy #%d", copyId)
+	return 204
+}
+
+func (handler WebserviceHandler) SetCopyPrice(c *gin.Context) (int, result.PhysicalCopy) {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.PhysicalCopy{}
+	}
+	copyId, err := strconv.Atoi(c.Param("copyId"))
+	if err != nil {
+		c.Error(domain.NewError(domain.CodeNotFound, "Copy '%s' does not exist", c.Param("copyId")))
+		return 404, result.PhysicalCopy{}
+	}
+	price := request.CopyPrice{}
+	err = c.BindJSON(&price)
+	if err != nil {
+		return 400, result.PhysicalCopy{}
+	}
+	physical, err, code := handler.profile(c).SetCopyPrice(userId, libraryId, copyId, *price.PaidPrice,
+		price.Currency)
+	if err != nil {
+		c.Error(err)
+		return code, result.PhysicalCopy{}
+	}
+	return 200, physicalCopyResult(physical)
+}
+
+func (handler WebserviceHandler) ShowCollectionWorth(c *gin.Context) (int, result.CollectionWorth) {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.CollectionWorth{}
+	}
+	worth, err, code := handler.profile(c).ShowCollectionWorth(userId, libraryId)
+	if err != nil {
+		c.Error(err)
+		return code, result.CollectionWorth{}
+	}
+	message := result.CollectionWorth{UserId: c.Param("id"), LibraryId: c.Param("libId"),
+		Paid: worth.Paid, Value: worth.Value, Unpaid: worth.Unpaid, Unvalued: worth.Unvalued}
+	for _, physical := range worth.Copies {
+		message.Copies = append(message.Copies, physicalCopyResult(physical))
+	}
+	return 200, message
+}
+
+// The collection worth as a PDF for insurers
+func (handler WebserviceHandler) PrintCollectionWorth(c *gin.Context) (int, usecases.ExportedFile) {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, usecases.ExportedFile{}
+	}
+	file, err, code := handler.profile(c).PrintCollectionWorth(userId, libraryId, c.GetString("locale"))
+	if err != nil {
+		c.Error(err)
+		return code, usecases.ExportedFile{}
+	}
+	logf(c, "Printed the worth of library #%d", libraryId)
+	return 200, file
+}
+
+func photoImportResult(c *gin.Context, photoImport usecases.PhotoImport) result.PhotoImport {
+	message := result.PhotoImport{Id: photoImport.Id, UserId: c.Param("id"), LibraryId: c.Param("libId"),
+		Status: photoImport.Status, Reason: photoImport.Reason, Photos: len(photoImport.Photos),
+		CreatedAt: photoImport.CreatedAt, UpdatedAt: photoImport.UpdatedAt}
+	for _, proposal := range photoImport.Proposals {
+		message.Proposals = append(message.Proposals, result.PhotoProposal{Id: proposal.Id,
+			Photo: proposal.Photo, Title: proposal.Title, Confidence: proposal.Confidence,
+			Matched: proposal.Matched, Reason: proposal.Reason, Accepted: proposal.Accepted})
+	}
+	return message
+}
+
+func photoImportId(c *gin.Context) (int, error) {
+	importId, err := strconv.Atoi(c.Param("importId"))
+	if err != nil {
+		return 0, domain.NewError(domain.CodeNotFound, "Photo import '%s' does not exist",
+			c.Param("importId"))
+	}
+	return importId, nil
+}
+
+// Takes the photos as multipart/form-data, repeated under "photos"
+func (handler WebserviceHandler) StartPhotoImport(c *gin.Context) (int, result.PhotoImport) {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.PhotoImport{}
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.Error(domain.NewFieldError("photos", "Upload the photos as multipart/form-data"))
+		return 400, result.PhotoImport{}
+	}
+	var photos []usecases.Screenshot
+	for _, header := range form.File["photos"] {
+		photo, err := readUpload(header)
+		if err != nil {
+			c.Error(domain.NewFieldError("photos", "Cannot read the upload"))
+			return 400, result.PhotoImport{}
+		}
+		photos = append(photos, photo)
+	}
+
+	photoImport, err, code := handler.profile(c).StartPhotoImport(userId, libraryId, photos)
+	if err != nil {
+		c.Error(err)
+		return code, result.PhotoImport{}
+	}
+	logf(c, "Queued photo import #%d with %d photos", photoImport.Id, len(photos))
+	return 202, photoImportResult(c, photoImport)
+}
+
+func (handler WebserviceHandler) ShowPhotoImport(c *gin.Context) (int, result.PhotoImport) {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.PhotoImport{}
+	}
+	importId, err := photoImportId(c)
+	if err != nil {
+		c.Error(err)
+		return 404, result.PhotoImport{}
+	}
+	photoImport, err, code := handler.profile(c).ShowPhotoImport(userId, libraryId, importId)
+	if err != nil {
+		c.Error(err)
+		return code, result.PhotoImport{}
+	}
+	return 200, photoImportResult(c, photoImport)
+}
+
+func (handler WebserviceHandler) ConfirmPhotoImport(c *gin.Context) (int, result.PhysicalCopies) {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.PhysicalCopies{}
+	}
+	importId, err := photoImportId(c)
+	if err != nil {
+		c.Error(err)
+		return 404, result.PhysicalCopies{}
+	}
+	confirmation := request.PhotoConfirmation{}
+	err = c.BindJSON(&confirmation)
+	if err != nil {
+		return 400, result.PhysicalCopies{}
+	}
+	accepted := make(map[int]string)
+	for _, choice := range confirmation.Accept {
+		accepted[choice.Id] = choice.Title
+	}
+
+	copies, err, code := handler.profile(c).ConfirmPhotoImport(userId, libraryId, importId, accepted)
+	if err != nil {
+		c.Error(err)
+		return code, result.PhysicalCopies{}
+	}
+	logf(c, "Confirmed photo import #%d into %d copies", importId, len(copies))
+	message := result.PhysicalCopies{UserId: c.Param("id"), LibraryId: c.Param("libId")}
+	for _, physical := range copies {
+		message.Copies = append(message.Copies, physicalCopyResult(physical))
+	}
+	return 200, message
+}
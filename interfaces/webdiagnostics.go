@@ -0,0 +1,55 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func diagnosticsResult(diagnostics usecases.Diagnostics) result.Diagnostics {
+	message := result.Diagnostics{Goroutines: diagnostics.Goroutines,
+		HeapBytes: diagnostics.HeapBytes, GcRuns: diagnostics.GcRuns,
+		GcPauseMs: diagnostics.GcPause.Milliseconds(), Caches: []result.DiagnosticsCache{},
+		UptimeSeconds: int64(diagnostics.Uptime / time.Second),
+		CollectedAt:   diagnostics.CollectedAt.Format(time.RFC3339)}
+	if diagnostics.Pool != nil {
+		message.Pool = &result.DatabasePool{MaxOpen: diagnostics.Pool.MaxOpen,
+			Open: diagnostics.Pool.Open, InUse: diagnostics.Pool.InUse, Idle: diagnostics.Pool.Idle,
+			Waits: diagnostics.Pool.WaitCount, WaitMs: diagnostics.Pool.WaitDuration.Milliseconds(),
+			Server: diagnostics.Pool.Server, Failovers: diagnostics.Pool.Failovers}
+	}
+	for _, cache := range diagnostics.Caches {
+		message.Caches = append(message.Caches, result.DiagnosticsCache{Name: cache.Name,
+			Entries: cache.Entries})
+	}
+	return message
+}
+
+func (handler WebserviceHandler) ShowDiagnostics(c *gin.Context) (int, result.Diagnostics) {
+	diagnostics, err, code := handler.DiagnosticsInteractor.ShowDiagnostics(c.GetInt("userId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Diagnostics{}
+	}
+	return 200, diagnosticsResult(diagnostics)
+}
+
+// The figures expvar publishes, in the shape the API answers with
+func (handler WebserviceHandler) DiagnosticsVar() interface{} {
+	return diagnosticsResult(handler.DiagnosticsInteractor.Collect())
+}
+
+// Lets through callers whose stored role is admin, for the pprof and expvar
+// handlers of the diagnostics port which know nothing of users. Runs after
+// auth.CheckRole.
+func (handler WebserviceHandler) RequireAdmin(c *gin.Context) {
+	err, code := handler.admin(c).Authorize(c.GetInt("userId"))
+	if err != nil {
+		c.AbortWithError(code, err)
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/result"
+)
+
+// The public activity of the user named in the path, it is addressed by
+// name like the /u/{username} profile
+func (handler WebserviceHandler) ShowFeed(c *gin.Context) (int, result.Feed) {
+	user, activities, err, code := handler.activity(c).ShowFeed(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Feed{}
+	}
+
+	message := result.Feed{UserId: user.ExternalId, UserName: user.Name,
+		UpdatedAt: user.UpdatedAt}
+	for _, activity := range activities {
+		message.Entries = append(message.Entries, result.FeedEntry{Id: activity.Id,
+			Kind: activity.Kind, GameId: activity.GameExternalId, GameName: activity.GameName,
+			Partners: activity.Partners, CreatedAt: activity.CreatedAt})
+	}
+	if len(activities) > 0 {
+		message.UpdatedAt = activities[0].CreatedAt
+	}
+	logf(c, "Printed feed of user #%d", user.Id)
+	return 200, message
+}
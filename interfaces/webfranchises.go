@@ -0,0 +1,142 @@
+package interfaces
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func franchiseResult(franchise usecases.Franchise) result.Franchise {
+	message := result.Franchise{Id: franchise.ExternalId, Name: franchise.Name,
+		CreatedAt: franchise.CreatedAt}
+	for _, game := range franchise.Games {
+		message.Games = append(message.Games, result.FranchiseGame{GameId: game.GameExternalId,
+			GameName: game.GameName, Position: game.Position})
+	}
+	return message
+}
+
+func (handler WebserviceHandler) ShowFranchises(c *gin.Context) (int, result.Franchises) {
+	franchises, err, code := handler.franchises(c).ShowFranchises()
+	if err != nil {
+		c.Error(err)
+		return code, result.Franchises{}
+	}
+	message := result.Franchises{}
+	for _, franchise := range franchises {
+		message.Franchises = append(message.Franchises, franchiseResult(franchise))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) ShowFranchise(c *gin.Context) (int, result.Franchise) {
+	franchiseId, err, code := handler.franchises(c).FindFranchiseId(c.Param("franchiseId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Franchise{}
+	}
+	franchise, err, code := handler.franchises(c).ShowFranchise(franchiseId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Franchise{}
+	}
+	return 200, franchiseResult(franchise)
+}
+
+func (handler WebserviceHandler) AddFranchise(c *gin.Context) (int, result.Franchise) {
+	franchise := request.Franchise{}
+	err := c.BindJSON(&franchise)
+	if err != nil {
+		return 400, result.Franchise{}
+	}
+	added, err, code := handler.franchises(c).AddFranchise(franchise.Name)
+	if err != nil {
+		c.Error(err)
+		return code, result.Franchise{}
+	}
+	logf(c, "Added franchise #%d", added.Id)
+	return 201, franchiseResult(added)
+}
+
+func (handler WebserviceHandler) RemoveFranchise(c *gin.Context) int {
+	franchiseId, err, code := handler.franchises(c).FindFranchiseId(c.Param("franchiseId"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	err, code = handler.franchises(c).RemoveFranchise(franchiseId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Deleted franchise #%d", franchiseId)
+	return 204
+}
+
+func (handler WebserviceHandler) AddFranchiseGame(c *gin.Context) (int, result.Franchise) {
+	franchiseId, err, code := handler.franchises(c).FindFranchiseId(c.Param("franchiseId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Franchise{}
+	}
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Franchise{}
+	}
+	entry := request.FranchiseGame{}
+	err = c.BindJSON(&entry)
+	if err != nil {
+		return 400, result.Franchise{}
+	}
+
+	franchise, err, code := handler.franchises(c).AddGame(franchiseId, gameId, entry.Position)
+	if err != nil {
+		c.Error(err)
+		return code, result.Franchise{}
+	}
+	logf(c, "Added game #%d to franchise #%d", gameId, franchiseId)
+	return 200, franchiseResult(franchise)
+}
+
+func (handler WebserviceHandler) RemoveFranchiseGame(c *gin.Context) int {
+	franchiseId, err, code := handler.franchises(c).FindFranchiseId(c.Param("franchiseId"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	err, code = handler.franchises(c).RemoveGame(franchiseId, gameId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Removed game #%d from franchise #%d", gameId, franchiseId)
+	return 204
+}
+
+func (handler WebserviceHandler) ShowFranchiseProgress(c *gin.Context) (int, result.FranchiseProgresses) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.FranchiseProgresses{}
+	}
+	progress, err, code := handler.franchises(c).ShowProgress(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.FranchiseProgresses{}
+	}
+	message := result.FranchiseProgresses{UserId: c.Param("id")}
+	for _, entry := range progress {
+		message.Progress = append(message.Progress, result.FranchiseProgress{
+			Franchise: franchiseResult(entry.Franchise), Total: entry.Total, Owned: entry.Owned,
+			Completed: entry.Completed})
+	}
+	return 200, message
+}
@@ -0,0 +1,221 @@
+package interfaces
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+// Filtered with ?rating=PEGI 12, ?maxAge=12, ?name, ?status, ?platform and
+// ?tag, sorted with ?sort=-value. ?search=3 starts from a saved search. Paged
+// with ?cursor and ?limit, a cursor only fits the sort it was made for.
+func (handler WebserviceHandler) ShowGames(c *gin.Context) (int, result.LibraryGames) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryGames{}
+	}
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryGames{}
+	}
+	filter, err, code := handler.gameFilter(c, userId, libraryId)
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryGames{}
+	}
+
+	page, err := pageQuery(c, 100)
+	if err != nil {
+		c.Error(err)
+		return 400, result.LibraryGames{}
+	}
+
+	games, next, err, code := handler.profile(c).ShowGames(userId, libraryId, filter, page)
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryGames{}
+	}
+	message := result.LibraryGames{UserId: c.Param("id"), LibraryId: c.Param("libId"),
+		Limit: page.Limit, Cursor: next.String(), HasMore: !next.IsZero()}
+	for _, game := range games {
+		message.Games = append(message.Games, result.Game{Id: game.ExternalId,
+			LibraryId: c.Param("libId"), UserId: c.Param("id"), Name: game.Name,
+			Producer: game.Producer, Value: game.Value, MinAge: game.MinAge, Rating: game.Rating,
+			Status: game.Status, Platform: game.Platform, Tags: game.Tags, WishlistRank: game.WishlistRank,
+			Version: game.Version, CreatedAt: game.CreatedAt, UpdatedAt: game.UpdatedAt})
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) UpdateGames(c *gin.Context) (int, result.GameBatch) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.GameBatch{}
+	}
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.GameBatch{}
+	}
+	batch := request.GameBatch{}
+	err = c.BindJSON(&batch)
+	if err != nil {
+		return 400, result.GameBatch{}
+	}
+
+	// Ids that do not resolve are reported with the other per-item errors
+	message := result.GameBatch{UserId: c.Param("id"), LibraryId: c.Param("libId")}
+	externalIds := make(map[int]string)
+	baseVersions := make(map[int]int64)
+	var gameIds []int
+	for _, externalId := range batch.Ids {
+		gameId, err, code := handler.profile(c).FindGameId(externalId)
+		if err != nil {
+			message.Items = append(message.Items, batchItem(externalId, err, code))
+			continue
+		}
+		externalIds[gameId] = externalId
+		baseVersions[gameId] = batch.Versions[externalId]
+		gameIds = append(gameIds, gameId)
+	}
+	if len(gameIds) == 0 && len(message.Items) > 0 {
+		return 200, message
+	}
+
+	change := usecases.GameChange{Status: batch.Status, Platform: batch.Platform, Tags: batch.Tags}
+	items, err, code := handler.profile(c).UpdateGames(userId, libraryId, gameIds, change,
+		baseVersions)
+	if err != nil {
+		c.Error(err)
+		return code, result.GameBatch{}
+	}
+	for _, item := range items {
+		batched := batchItem(externalIds[item.GameId], item.Error, item.Code)
+		batched.Version = item.Version
+		message.Items = append(message.Items, batched)
+	}
+	logf(c, "Updated %d games in library #%d", len(gameIds), libraryId)
+	return 200, message
+}
+
+func batchItem(gameId string, err error, code int) result.BatchItem {
+	item := result.BatchItem{GameId: gameId, Status: code}
+	if err == nil {
+		return item
+	}
+	item.Code = string(domain.CodeInvalid)
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		item.Code = string(domainErr.Code)
+	}
+	item.Message = err.Error()
+	return item
+}
+
+// Takes multipart/form-data with the export as file and its format, one of
+// backloggd, hltb or grouvee
+func (handler WebserviceHandler) ImportGames(c *gin.Context) (int, result.ImportReport) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.ImportReport{}
+	}
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.ImportReport{}
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.Error(domain.NewFieldError("file", "Is required"))
+		return 400, result.ImportReport{}
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.Error(domain.NewFieldError("file", "Cannot read the upload"))
+		return 400, result.ImportReport{}
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.Error(domain.NewFieldError("file", "Cannot read the upload"))
+		return 400, result.ImportReport{}
+	}
+
+	report, err, code := handler.profile(c).ImportGames(userId, libraryId, c.PostForm("format"), data)
+	if err != nil {
+		c.Error(err)
+		return code, result.ImportReport{}
+	}
+	logf(c, "Imported %d games into library #%d", len(report.Imported), libraryId)
+	return 200, importResult(c, report)
+}
+
+func importResult(c *gin.Context, report usecases.ImportReport) result.ImportReport {
+	message := result.ImportReport{UserId: c.Param("id"), LibraryId: c.Param("libId"),
+		Format: report.Format, Imported: []result.ImportedGame{}, Skipped: []result.ImportRow{},
+		Unmatched: []result.ImportRow{}}
+	for _, imported := range report.Imported {
+		message.Imported = append(message.Imported, result.ImportedGame{Line: imported.Row.Line,
+			GameId: imported.Game.ExternalId, Title: imported.Game.Name, Status: imported.Game.Status,
+			Platform: imported.Game.Platform, Rating: imported.Game.Rating})
+	}
+	for _, row := range report.Skipped {
+		message.Skipped = append(message.Skipped, result.ImportRow{Line: row.Line, Title: row.Title})
+	}
+	for _, unmatched := range report.Unmatched {
+		message.Unmatched = append(message.Unmatched, result.ImportRow{Line: unmatched.Row.Line,
+			Title: unmatched.Row.Title, Reason: unmatched.Reason})
+	}
+	return message
+}
+
+// Lines of the report are wishlist ranks
+func (handler WebserviceHandler) ImportSteamWishlist(c *gin.Context) (int, result.ImportReport) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.ImportReport{}
+	}
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.ImportReport{}
+	}
+	steam := request.SteamImport{}
+	err = c.BindJSON(&steam)
+	if err != nil {
+		return 400, result.ImportReport{}
+	}
+
+	report, err, code := handler.profile(c).ImportSteamWishlist(userId, libraryId, steam.SteamId)
+	if err != nil {
+		c.Error(err)
+		return code, result.ImportReport{}
+	}
+	logf(c, "Imported %d wishlisted Steam games into library #%d", len(report.Imported), libraryId)
+	return 200, importResult(c, report)
+}
+
+// Library version an edit is based on, 0 when ?version= is left out
+func versionQuery(c *gin.Context) (int64, error) {
+	value := c.Query("version")
+	if value == "" {
+		return 0, nil
+	}
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, domain.NewFieldError("version", "Must be a whole number")
+	}
+	return version, nil
+}
@@ -0,0 +1,121 @@
+package interfaces
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func goalResult(c *gin.Context, progress usecases.GoalProgress) result.Goal {
+	goal := progress.Goal
+	return result.Goal{Id: goal.Id, UserId: c.Param("id"), Kind: goal.Kind, Target: goal.Target,
+		Period: goal.Period, Year: goal.Year, Quarter: goal.Quarter, StartsAt: goal.StartsAt,
+		EndsAt: goal.EndsAt, Current: progress.Current, Expected: progress.Expected,
+		Achieved: progress.Achieved, Behind: progress.Behind, CreatedAt: goal.CreatedAt}
+}
+
+// The user and goal of the request
+func (handler WebserviceHandler) goalTarget(c *gin.Context) (int, int, error, int) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		return 0, 0, err, code
+	}
+	goalId, err := strconv.Atoi(c.Param("goalId"))
+	if err != nil {
+		return 0, 0, domain.NewError(domain.CodeNotFound, "Goal '%s' does not exist", c.Param("goalId")), 404
+	}
+	return userId, goalId, nil, 200
+}
+
+func (handler WebserviceHandler) AddGoal(c *gin.Context) (int, result.Goal) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Goal{}
+	}
+	goal := request.Goal{}
+	err = c.BindJSON(&goal)
+	if err != nil {
+		return 400, result.Goal{}
+	}
+	added, err, code := handler.goals(c).AddGoal(userId, usecases.Goal{Kind: goal.Kind,
+		Target: goal.Target, Period: goal.Period, Year: goal.Year, Quarter: goal.Quarter})
+	if err != nil {
+		c.Error(err)
+		return code, result.Goal{}
+	}
+	logf(c, "Set goal #%d", added.Goal.Id)
+	return 201, goalResult(c, added)
+}
+
+func (handler WebserviceHandler) ShowGoals(c *gin.Context) (int, result.Goals) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Goals{}
+	}
+	goals, err, code := handler.goals(c).ShowGoals(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Goals{}
+	}
+	message := result.Goals{UserId: c.Param("id")}
+	for _, goal := range goals {
+		message.Goals = append(message.Goals, goalResult(c, goal))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) ShowGoal(c *gin.Context) (int, result.Goal) {
+	userId, goalId, err, code := handler.goalTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.Goal{}
+	}
+	goal, err, code := handler.goals(c).ShowGoal(userId, goalId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Goal{}
+	}
+	return 200, goalResult(c, goal)
+}
+
+func (handler WebserviceHandler) EditGoal(c *gin.Context) (int, result.Goal) {
+	userId, goalId, err, code := handler.goalTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.Goal{}
+	}
+	target := request.GoalTarget{}
+	err = c.BindJSON(&target)
+	if err != nil {
+		return 400, result.Goal{}
+	}
+	goal, err, code := handler.goals(c).EditGoal(userId, goalId, target.Target)
+	if err != nil {
+		c.Error(err)
+		return code, result.Goal{}
+	}
+	logf(c, "Changed the target of goal #%d", goalId)
+	return 200, goalResult(c, goal)
+}
+
+func (handler WebserviceHandler) RemoveGoal(c *gin.Context) int {
+	userId, goalId, err, code := handler.goalTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	err, code = handler.goals(c).RemoveGoal(userId, goalId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Removed goal #%d", goalId)
+	return 204
+}
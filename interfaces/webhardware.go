@@ -0,0 +1,147 @@
+package interfaces
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func hardwareResult(c *gin.Context, item usecases.Hardware) result.Hardware {
+	return result.Hardware{Id: item.Id, UserId: c.Param("id"), Kind: item.Kind, Name: item.Name,
+		Model: item.Model, Serial: item.Serial, PurchasedOn: formatDay(item.PurchasedOn),
+		WarrantyUntil: formatDay(item.WarrantyUntil),
+		UnderWarranty: item.UnderWarranty(time.Now().UTC().Truncate(24 * time.Hour)), CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt}
+}
+
+// Turns the request into hardware, dates left out stay zero
+func hardwareRequest(c *gin.Context) (usecases.Hardware, error) {
+	item := request.Hardware{}
+	err := c.BindJSON(&item)
+	if err != nil {
+		return usecases.Hardware{}, err
+	}
+	hardware := usecases.Hardware{Kind: item.Kind, Name: item.Name, Model: item.Model,
+		Serial: item.Serial}
+	for _, date := range []struct {
+		field string
+		value string
+		day   *time.Time
+	}{{"purchasedOn", item.PurchasedOn, &hardware.PurchasedOn},
+		{"warrantyUntil", item.WarrantyUntil, &hardware.WarrantyUntil}} {
+		if date.value == "" {
+			continue
+		}
+		*date.day, err = time.Parse("2006-01-02", date.value)
+		if err != nil {
+			err = domain.NewFieldError(date.field, "Must be a date such as 2026-11-01")
+			c.Error(err)
+			return usecases.Hardware{}, err
+		}
+	}
+	return hardware, nil
+}
+
+// The user and hardware of the request
+func (handler WebserviceHandler) hardwareTarget(c *gin.Context) (int, int, error, int) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		return 0, 0, err, code
+	}
+	itemId, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return 0, 0, domain.NewError(domain.CodeNotFound, "Hardware '%s' does not exist", c.Param("itemId")), 404
+	}
+	return userId, itemId, nil, 200
+}
+
+func (handler WebserviceHandler) AddHardware(c *gin.Context) (int, result.Hardware) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Hardware{}
+	}
+	item, err := hardwareRequest(c)
+	if err != nil {
+		return 400, result.Hardware{}
+	}
+	added, err, code := handler.hardware(c).AddHardware(userId, item)
+	if err != nil {
+		c.Error(err)
+		return code, result.Hardware{}
+	}
+	logf(c, "Added hardware #%d", added.Id)
+	return 201, hardwareResult(c, added)
+}
+
+func (handler WebserviceHandler) ShowHardware(c *gin.Context) (int, result.HardwareList) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.HardwareList{}
+	}
+	items, err, code := handler.hardware(c).ShowHardware(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.HardwareList{}
+	}
+	message := result.HardwareList{UserId: c.Param("id")}
+	for _, item := range items {
+		message.Items = append(message.Items, hardwareResult(c, item))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) ShowHardwareItem(c *gin.Context) (int, result.Hardware) {
+	userId, itemId, err, code := handler.hardwareTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.Hardware{}
+	}
+	item, err, code := handler.hardware(c).ShowHardwareItem(userId, itemId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Hardware{}
+	}
+	return 200, hardwareResult(c, item)
+}
+
+func (handler WebserviceHandler) EditHardware(c *gin.Context) (int, result.Hardware) {
+	userId, itemId, err, code := handler.hardwareTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.Hardware{}
+	}
+	changed, err := hardwareRequest(c)
+	if err != nil {
+		return 400, result.Hardware{}
+	}
+	item, err, code := handler.hardware(c).EditHardware(userId, itemId, changed)
+	if err != nil {
+		c.Error(err)
+		return code, result.Hardware{}
+	}
+	logf(c, "Edited hardware #%d", itemId)
+	return 200, hardwareResult(c, item)
+}
+
+func (handler WebserviceHandler) RemoveHardware(c *gin.Context) int {
+	userId, itemId, err, code := handler.hardwareTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	err, code = handler.hardware(c).RemoveHardware(userId, itemId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Removed hardware #%d", itemId)
+	return 204
+}
@@ -0,0 +1,93 @@
+package interfaces
+
+import (
+	"database/sql"
+	"time"
+
+	"game-tracker/usecases"
+)
+
+type DbWebhookRepo DbRepo
+
+func NewDbWebhookRepo(dbHandlers map[string]DbHandler) *DbWebhookRepo {
+	dbWebhookRepo := new(DbWebhookRepo)
+	dbWebhookRepo.dbHandlers = dbHandlers
+	dbWebhookRepo.dbHandler = dbHandlers["DbWebhookRepo"]
+	return dbWebhookRepo
+}
+
+var webhookColumns = []string{"webhooks.id", "webhooks.user_id", "name", "action", "library_id",
+	"libraries.external_id", "key_hash", "secret", "last_delivered_at", "webhooks.created_at"}
+
+func (repo DbWebhookRepo) Store(webhook usecases.Webhook) (int, error) {
+	libraryId := sql.NullInt64{Int64: int64(webhook.LibraryId), Valid: webhook.LibraryId != 0}
+	statement, args := repo.dbHandler.Dialect().Insert("webhooks").
+		Set("user_id", webhook.UserId).Set("name", webhook.Name).Set("action", webhook.Action).
+		Set("library_id", libraryId).Set("key_hash", webhook.KeyHash).Set("secret", webhook.Secret).
+		Set("created_at", webhook.CreatedAt).Returning("id").Build()
+	return repo.dbHandler.QueryRow(statement, args...)
+}
+
+func (repo DbWebhookRepo) FindByKeyHash(keyHash string) (usecases.Webhook, bool, error) {
+	statement, args := repo.dbHandler.Dialect().Select(webhookColumns...).From("webhooks").
+		LeftJoin("libraries", "libraries.id = webhooks.library_id").
+		Where("key_hash = ?", keyHash).Limit(1).Build()
+	webhooks, err := repo.query(statement, args)
+	if err != nil || len(webhooks) == 0 {
+		return usecases.Webhook{}, false, err
+	}
+	return webhooks[0], true, nil
+}
+
+func (repo DbWebhookRepo) FindByUser(userId int) ([]usecases.Webhook, error) {
+	statement, args := repo.dbHandler.Dialect().Select(webhookColumns...).From("webhooks").
+		LeftJoin("libraries", "libraries.id = webhooks.library_id").
+		Where("webhooks.user_id = ?", userId).OrderBy("webhooks.created_at", "webhooks.id").Build()
+	return repo.query(statement, args)
+}
+
+func (repo DbWebhookRepo) query(statement string, args []interface{}) ([]usecases.Webhook, error) {
+	row, err := repo.dbHandler.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+	var webhooks []usecases.Webhook
+	for row.Next() {
+		var webhook usecases.Webhook
+		var libraryId sql.NullInt64
+		var libraryExternalId sql.NullString
+		var lastDeliveredAt sql.NullTime
+		err = row.Scan(&webhook.Id, &webhook.UserId, &webhook.Name, &webhook.Action, &libraryId,
+			&libraryExternalId, &webhook.KeyHash, &webhook.Secret, &lastDeliveredAt, &webhook.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		webhook.LibraryId = int(libraryId.Int64)
+		webhook.LibraryExternalId = libraryExternalId.String
+		if lastDeliveredAt.Valid {
+			webhook.LastDeliveredAt = lastDeliveredAt.Time
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+func (repo DbWebhookRepo) MarkDelivered(id int, at time.Time) error {
+	statement, args := repo.dbHandler.Dialect().Update("webhooks").Set("last_delivered_at", at).
+		Where("id = ?", id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbWebhookRepo) Remove(webhook usecases.Webhook) error {
+	statement, args := repo.dbHandler.Dialect().Delete("webhooks").Where("id = ?", webhook.Id).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
+
+func (repo DbWebhookRepo) RemoveAll(userId int) error {
+	statement, args := repo.dbHandler.Dialect().Delete("webhooks").Where("user_id = ?", userId).Build()
+	_, err := repo.dbHandler.Execute(statement, args...)
+	return err
+}
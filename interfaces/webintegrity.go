@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/result"
+)
+
+func (handler WebserviceHandler) CheckIntegrity(c *gin.Context) (int, result.Integrity) {
+	return handler.integrity(c, false)
+}
+
+func (handler WebserviceHandler) RepairIntegrity(c *gin.Context) (int, result.Integrity) {
+	return handler.integrity(c, true)
+}
+
+func (handler WebserviceHandler) integrity(c *gin.Context, repair bool) (int, result.Integrity) {
+	findings, err, code := handler.IntegrityInteractor.CheckIntegrity(c.GetInt("userId"), repair)
+	if err != nil {
+		c.Error(err)
+		return code, result.Integrity{}
+	}
+	message := result.Integrity{Repaired: repair}
+	for _, finding := range findings {
+		message.Findings = append(message.Findings, result.IntegrityFinding{Check: finding.Name,
+			Description: finding.Description, Repair: finding.Repair, Count: finding.Count,
+			Ids: finding.Ids, Repaired: finding.Repaired})
+	}
+	return 200, message
+}
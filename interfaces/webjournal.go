@@ -0,0 +1,248 @@
+package interfaces
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func journalResult(entry usecases.JournalEntry) result.JournalEntry {
+	return result.JournalEntry{Id: entry.Id, GameId: entry.GameExternalId, GameName: entry.GameName,
+		Text: entry.Text, Html: entry.Html, HasScreenshot: entry.Screenshot != "",
+		HasSpoilers: entry.HasSpoilers, SpoilersHidden: entry.SpoilersHidden, CreatedAt: entry.CreatedAt}
+}
+
+// Spoilers stay hidden unless the reader opts in with ?spoilers=show
+func showSpoilers(c *gin.Context) bool {
+	return c.Query("spoilers") == "show"
+}
+
+// Takes JSON, or multipart/form-data when a screenshot comes along
+func (handler WebserviceHandler) AddJournalEntry(c *gin.Context) (int, result.JournalEntry) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.JournalEntry{}
+	}
+	entry := request.JournalEntry{}
+	var screenshot *usecases.Screenshot
+	if c.ContentType() == "multipart/form-data" {
+		err = c.ShouldBind(&entry)
+		if err != nil {
+			c.Error(domain.NewFieldError("gameId", "Is required"))
+			return 400, result.JournalEntry{}
+		}
+		screenshot, err = formScreenshot(c)
+		if err != nil {
+			c.Error(err)
+			return 400, result.JournalEntry{}
+		}
+	} else {
+		err = c.BindJSON(&entry)
+		if err != nil {
+			return 400, result.JournalEntry{}
+		}
+	}
+	gameId, err, code := handler.profile(c).FindGameId(entry.GameId)
+	if err != nil {
+		c.Error(err)
+		return code, result.JournalEntry{}
+	}
+
+	added, err, code := handler.journal(c).AddEntry(userId, gameId, entry.Text, screenshot)
+	if err != nil {
+		c.Error(err)
+		return code, result.JournalEntry{}
+	}
+	logf(c, "Added journal entry #%d", added.Id)
+	return 201, journalResult(added)
+}
+
+func formScreenshot(c *gin.Context) (*usecases.Screenshot, error) {
+	header, err := c.FormFile("screenshot")
+	if err == http.ErrMissingFile {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, domain.NewFieldError("screenshot", "Cannot read the upload")
+	}
+	screenshot, err := readUpload(header)
+	if err != nil {
+		return nil, domain.NewFieldError("screenshot", "Cannot read the upload")
+	}
+	return &screenshot, nil
+}
+
+// The type is sniffed from the bytes, the one the client sent is not trusted
+func readUpload(header *multipart.FileHeader) (usecases.Screenshot, error) {
+	file, err := header.Open()
+	if err != nil {
+		return usecases.Screenshot{}, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return usecases.Screenshot{}, err
+	}
+	return usecases.Screenshot{ContentType: http.DetectContentType(data), Data: data}, nil
+}
+
+// Filtered to one game with ?gameId=, spoilers are shown with ?spoilers=show.
+// Paged with ?cursor and ?limit.
+func (handler WebserviceHandler) ShowJournal(c *gin.Context) (int, result.Journal) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Journal{}
+	}
+	gameId := 0
+	if value := c.Query("gameId"); value != "" {
+		gameId, err, code = handler.profile(c).FindGameId(value)
+		if err != nil {
+			c.Error(err)
+			return code, result.Journal{}
+		}
+	}
+
+	page, err := pageQuery(c, 50)
+	if err != nil {
+		c.Error(err)
+		return 400, result.Journal{}
+	}
+
+	entries, next, err, code := handler.journal(c).ShowEntries(userId, gameId, showSpoilers(c),
+		page)
+	if err != nil {
+		c.Error(err)
+		return code, result.Journal{}
+	}
+	message := result.Journal{UserId: c.Param("id"), Limit: page.Limit, Cursor: next.String(),
+		HasMore: !next.IsZero()}
+	for _, entry := range entries {
+		message.Entries = append(message.Entries, journalResult(entry))
+	}
+	return 200, message
+}
+
+func journalEntryId(c *gin.Context) (int, error) {
+	entryId, err := strconv.Atoi(c.Param("entryId"))
+	if err != nil {
+		return 0, domain.NewError(domain.CodeNotFound, "Journal entry '%s' does not exist",
+			c.Param("entryId"))
+	}
+	return entryId, nil
+}
+
+func (handler WebserviceHandler) ShowScreenshot(c *gin.Context) (int, usecases.Screenshot) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, usecases.Screenshot{}
+	}
+	entryId, err := journalEntryId(c)
+	if err != nil {
+		c.Error(err)
+		return 404, usecases.Screenshot{}
+	}
+	screenshot, err, code := handler.journal(c).ShowScreenshot(userId, entryId)
+	if err != nil {
+		c.Error(err)
+		return code, usecases.Screenshot{}
+	}
+	return 200, screenshot
+}
+
+func (handler WebserviceHandler) RemoveJournalEntry(c *gin.Context) int {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	entryId, err := journalEntryId(c)
+	if err != nil {
+		c.Error(err)
+		return 404
+	}
+	err, code = handler.journal(c).RemoveEntry(userId, entryId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Deleted journal entry #%d", entryId)
+	return 204
+}
+
+func (handler WebserviceHandler) ExportProfile(c *gin.Context) (int, result.ProfileExport) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.ProfileExport{}
+	}
+	export, err, code := handler.exports(c).ExportProfile(userId, showSpoilers(c))
+	if err != nil {
+		c.Error(err)
+		return code, result.ProfileExport{}
+	}
+
+	message := result.ProfileExport{UserId: export.User.ExternalId, UserName: export.User.Name,
+		CreatedAt: export.User.CreatedAt, ExportedAt: export.ExportedAt}
+	for _, library := range export.Libraries {
+		exported := result.LibraryExport{Id: library.Library.ExternalId}
+		for _, game := range library.Games {
+			exported.Games = append(exported.Games, result.Game{Id: game.ExternalId,
+				LibraryId: library.Library.ExternalId, UserId: export.User.ExternalId,
+				Name: game.Name, Producer: game.Producer, Value: game.Value, MinAge: game.MinAge,
+				Rating: game.Rating, Status: game.Status, Platform: game.Platform, Tags: game.Tags,
+				CreatedAt: game.CreatedAt, UpdatedAt: game.UpdatedAt})
+		}
+		for _, mod := range library.Mods {
+			exported.Mods = append(exported.Mods, modResult(export.User.ExternalId,
+				library.Library.ExternalId, mod))
+		}
+		message.Libraries = append(message.Libraries, exported)
+	}
+	for _, entry := range export.Journal {
+		message.Journal = append(message.Journal, journalResult(entry))
+	}
+	logf(c, "Exported profile of user #%d", userId)
+	return 200, message
+}
+
+// The games of every library in the CSV shape another tracker imports,
+// ?format= names the tracker
+func (handler WebserviceHandler) ExportFile(c *gin.Context) (int, usecases.ExportedFile) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, usecases.ExportedFile{}
+	}
+	file, err, code := handler.exports(c).ExportFile(userId, c.Query("format"))
+	if err != nil {
+		c.Error(err)
+		return code, usecases.ExportedFile{}
+	}
+	logf(c, "Exported games of user #%d as %s", userId, c.Query("format"))
+	return 200, file
+}
+
+func (handler WebserviceHandler) RenderText(c *gin.Context) (int, result.Rendered) {
+	render := request.Render{}
+	err := c.BindJSON(&render)
+	if err != nil {
+		return 400, result.Rendered{}
+	}
+	rendered, err, code := handler.RenderInteractor.Render(render.Text)
+	if err != nil {
+		c.Error(err)
+		return code, result.Rendered{}
+	}
+	return 200, result.Rendered{Html: rendered}
+}
@@ -0,0 +1,183 @@
+package interfaces
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func libraryEventResult(event usecases.LibraryEvent) result.LibraryEvent {
+	message := result.LibraryEvent{Sequence: event.Sequence, Kind: event.Kind,
+		Status: event.Change.Status, Platform: event.Change.Platform, Tags: event.Change.Tags,
+		From: event.From, To: event.To, RecordedAt: event.RecordedAt}
+	for _, entry := range event.Entries {
+		message.Games = append(message.Games, result.LibraryEventGame{
+			GameId: event.GameExternalIds[entry.GameId], Status: entry.Status,
+			Platform: entry.Platform, Tags: entry.Tags, WishlistRank: entry.WishlistRank})
+	}
+	for _, gameId := range event.GameIds {
+		message.Games = append(message.Games, result.LibraryEventGame{
+			GameId: event.GameExternalIds[gameId]})
+	}
+	var ranked []int
+	for gameId := range event.Ranks {
+		ranked = append(ranked, gameId)
+	}
+	sort.Ints(ranked)
+	for _, gameId := range ranked {
+		message.Games = append(message.Games, result.LibraryEventGame{
+			GameId: event.GameExternalIds[gameId], WishlistRank: event.Ranks[gameId]})
+	}
+	return message
+}
+
+// Pages through the history with ?cursor and ?limit
+func (handler WebserviceHandler) ShowLibraryEvents(c *gin.Context) (int, result.LibraryEvents) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryEvents{}
+	}
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryEvents{}
+	}
+	page, err := pageQuery(c, 100)
+	if err != nil {
+		c.Error(err)
+		return 400, result.LibraryEvents{}
+	}
+
+	events, next, err, code := handler.LibraryEventsInteractor.ShowLibraryEvents(userId, libraryId,
+		page)
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryEvents{}
+	}
+	message := result.LibraryEvents{UserId: c.Param("id"), LibraryId: c.Param("libId"),
+		Limit: page.Limit, Cursor: next.String(), HasMore: !next.IsZero(),
+		Events: []result.LibraryEvent{}}
+	for _, event := range events {
+		message.Events = append(message.Events, libraryEventResult(event))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) ReplayLibrary(c *gin.Context) (int, result.LibraryReplay) {
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryReplay{}
+	}
+	state, err, code := handler.LibraryEventsInteractor.ReplayLibrary(c.GetInt("userId"), libraryId)
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryReplay{}
+	}
+	logf(c, "Replayed %d events of library #%d", state.Sequence, libraryId)
+	return 200, result.LibraryReplay{LibraryId: c.Param("libId"), Sequence: state.Sequence,
+		Games: len(state.Entries)}
+}
+
+func libraryAsOfResult(asOf usecases.LibraryAsOf) result.LibraryAsOf {
+	message := result.LibraryAsOf{At: asOf.At, Detailed: asOf.Detailed,
+		Games: []result.LibraryEventGame{}}
+	for _, entry := range asOf.Entries {
+		message.Games = append(message.Games, libraryEntryResult(asOf, entry))
+	}
+	return message
+}
+
+// Games removed from the catalog since keep an empty id
+func libraryEntryResult(asOf usecases.LibraryAsOf, entry usecases.LibraryEntry) result.LibraryEventGame {
+	game := asOf.Games[entry.GameId]
+	return result.LibraryEventGame{GameId: game.ExternalId, Name: game.Name, Status: entry.Status,
+		Platform: entry.Platform, Tags: entry.Tags, WishlistRank: entry.WishlistRank}
+}
+
+// Days of the query, such as ?date=2023-12-31
+func queryDays(c *gin.Context, fields ...string) ([]time.Time, error) {
+	var days []time.Time
+	for _, field := range fields {
+		day, err := time.Parse("2006-01-02", c.Query(field))
+		if err != nil {
+			return nil, domain.NewFieldError(field, "Must be a date such as 2026-11-01")
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func (handler WebserviceHandler) ShowLibraryAt(c *gin.Context) (int, result.LibraryAsOf) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryAsOf{}
+	}
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryAsOf{}
+	}
+	days, err := queryDays(c, "date")
+	if err != nil {
+		c.Error(err)
+		return 400, result.LibraryAsOf{}
+	}
+
+	asOf, err, code := handler.LibraryEventsInteractor.ShowLibraryAt(userId, libraryId, days[0])
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryAsOf{}
+	}
+	message := libraryAsOfResult(asOf)
+	message.UserId = c.Param("id")
+	message.LibraryId = c.Param("libId")
+	return 200, message
+}
+
+func (handler WebserviceHandler) DiffLibrary(c *gin.Context) (int, result.LibraryDiff) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryDiff{}
+	}
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryDiff{}
+	}
+	days, err := queryDays(c, "from", "to")
+	if err != nil {
+		c.Error(err)
+		return 400, result.LibraryDiff{}
+	}
+
+	diff, err, code := handler.LibraryEventsInteractor.DiffLibrary(userId, libraryId, days[0], days[1])
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryDiff{}
+	}
+	message := result.LibraryDiff{UserId: c.Param("id"), LibraryId: c.Param("libId"),
+		From: diff.From.At, To: diff.To.At, Detailed: diff.Detailed,
+		Added: []result.LibraryEventGame{}, Removed: []result.LibraryEventGame{},
+		Changed: []result.LibraryEntryChange{}}
+	for _, entry := range diff.Added {
+		message.Added = append(message.Added, libraryEntryResult(diff.To, entry))
+	}
+	for _, entry := range diff.Removed {
+		message.Removed = append(message.Removed, libraryEntryResult(diff.From, entry))
+	}
+	for _, change := range diff.Changed {
+		message.Changed = append(message.Changed, result.LibraryEntryChange{
+			Before: libraryEntryResult(diff.From, change.Before),
+			After:  libraryEntryResult(diff.To, change.After)})
+	}
+	return 200, message
+}
@@ -0,0 +1,195 @@
+package interfaces
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func matchResult(c *gin.Context, match usecases.Match) result.Match {
+	return result.Match{Id: match.Id, UserId: c.Param("id"), GameId: match.GameExternalId,
+		GameName: match.GameName, Result: match.Result, Score: match.Score, Opponents: match.Opponents,
+		PlayedAt: match.PlayedAt}
+}
+
+func matchRecord(record usecases.MatchRecord) result.MatchRecord {
+	return result.MatchRecord{Matches: record.Matches, Wins: record.Wins, Losses: record.Losses,
+		Draws: record.Draws, WinRate: record.WinRate()}
+}
+
+// The user and the gameId, from and to query filters of match routes
+func (handler WebserviceHandler) matchTarget(c *gin.Context) (int, usecases.MatchFilter, error, int) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		return 0, usecases.MatchFilter{}, err, code
+	}
+	filter := usecases.MatchFilter{}
+	if c.Query("gameId") != "" {
+		filter.GameId, err, code = handler.profile(c).FindGameId(c.Query("gameId"))
+		if err != nil {
+			return 0, usecases.MatchFilter{}, err, code
+		}
+	}
+	filter.From, err = timeQuery(c, "from", time.Time{})
+	if err != nil {
+		return 0, usecases.MatchFilter{}, err, 400
+	}
+	filter.To, err = timeQuery(c, "to", time.Time{})
+	if err != nil {
+		return 0, usecases.MatchFilter{}, err, 400
+	}
+	return userId, filter, nil, 200
+}
+
+// Matches whose game does not resolve are reported with the other per-item
+// errors
+func (handler WebserviceHandler) IngestMatches(c *gin.Context) (int, result.MatchBatch) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.MatchBatch{}
+	}
+	batch := request.MatchBatch{}
+	err = c.BindJSON(&batch)
+	if err != nil {
+		return 400, result.MatchBatch{}
+	}
+
+	message := result.MatchBatch{UserId: c.Param("id"), Items: make([]result.MatchBatchItem,
+		len(batch.Matches))}
+	var matches []usecases.Match
+	var indexes []int //Of the matches passed on in the batch
+	for i, match := range batch.Matches {
+		gameId, err, code := handler.profile(c).FindGameId(match.GameId)
+		if err != nil {
+			message.Items[i] = matchBatchItem(i, err, code)
+			continue
+		}
+		var playedAt time.Time
+		if match.PlayedAt != "" {
+			playedAt, err = time.Parse(time.RFC3339, match.PlayedAt)
+			if err != nil {
+				message.Items[i] = matchBatchItem(i, domain.NewFieldError("playedAt",
+					"Must be an RFC 3339 time"), 400)
+				continue
+			}
+		}
+		matches = append(matches, usecases.Match{GameId: gameId, Result: match.Result,
+			Score: match.Score, Opponents: match.Opponents, PlayedAt: playedAt})
+		indexes = append(indexes, i)
+	}
+	// An empty batch still goes through so it is rejected
+	if len(matches) == 0 && len(batch.Matches) > 0 {
+		return 200, message
+	}
+
+	items, err, code := handler.matches(c).IngestMatches(userId, matches)
+	if err != nil {
+		c.Error(err)
+		return code, result.MatchBatch{}
+	}
+	stored := 0
+	for _, item := range items {
+		i := indexes[item.Index]
+		message.Items[i] = matchBatchItem(i, item.Error, item.Code)
+		if item.Error == nil {
+			message.Items[i].MatchId = item.Match.Id
+			stored++
+		}
+	}
+	logf(c, "Added %d matches", stored)
+	return 200, message
+}
+
+func matchBatchItem(index int, err error, code int) result.MatchBatchItem {
+	item := batchItem("", err, code)
+	return result.MatchBatchItem{Index: index, Status: item.Status, Code: item.Code, Message: item.Message}
+}
+
+func (handler WebserviceHandler) ShowMatches(c *gin.Context) (int, result.Matches) {
+	userId, filter, err, code := handler.matchTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.Matches{}
+	}
+	filter.Page, err = pageQuery(c, 50)
+	if err != nil {
+		c.Error(err)
+		return 400, result.Matches{}
+	}
+	matches, next, err, code := handler.matches(c).ShowMatches(userId, filter)
+	if err != nil {
+		c.Error(err)
+		return code, result.Matches{}
+	}
+	message := result.Matches{UserId: c.Param("id"), Limit: filter.Page.Limit,
+		Cursor: next.String(), HasMore: !next.IsZero()}
+	for _, match := range matches {
+		message.Matches = append(message.Matches, matchResult(c, match))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) ShowMatchStats(c *gin.Context) (int, result.MatchStats) {
+	userId, filter, err, code := handler.matchTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.MatchStats{}
+	}
+	stats, err, code := handler.matches(c).ShowMatchStats(userId, filter)
+	if err != nil {
+		c.Error(err)
+		return code, result.MatchStats{}
+	}
+	message := result.MatchStats{UserId: c.Param("id"), MatchRecord: matchRecord(stats.MatchRecord)}
+	for _, game := range stats.Games {
+		message.Games = append(message.Games, result.GameMatchRecord{GameId: game.GameExternalId,
+			GameName: game.GameName, MatchRecord: matchRecord(game.MatchRecord)})
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) ShowHeadToHead(c *gin.Context) (int, result.HeadToHeads) {
+	userId, filter, err, code := handler.matchTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.HeadToHeads{}
+	}
+	records, err, code := handler.matches(c).ShowHeadToHead(userId, filter)
+	if err != nil {
+		c.Error(err)
+		return code, result.HeadToHeads{}
+	}
+	message := result.HeadToHeads{UserId: c.Param("id")}
+	for _, record := range records {
+		message.Records = append(message.Records, result.HeadToHead{Opponent: record.Opponent,
+			LastPlayedAt: record.LastPlayedAt, MatchRecord: matchRecord(record.MatchRecord)})
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) RemoveMatch(c *gin.Context) int {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	matchId, err := strconv.Atoi(c.Param("matchId"))
+	if err != nil {
+		c.Error(domain.NewError(domain.CodeNotFound, "Match '%s' does not exist", c.Param("matchId")))
+		return 404
+	}
+	err, code = handler.matches(c).RemoveMatch(userId, matchId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Removed match #%d", matchId)
+	return 204
+}
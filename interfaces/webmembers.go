@@ -0,0 +1,92 @@
+package interfaces
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func libraryMemberResult(member usecases.LibraryMember) result.LibraryMember {
+	return result.LibraryMember{UserId: member.User.ExternalId, UserName: member.User.Name,
+		Role: member.Role, CreatedAt: member.CreatedAt}
+}
+
+func (handler WebserviceHandler) ShowMembers(c *gin.Context) (int, result.LibraryMembers) {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryMembers{}
+	}
+	members, err, code := handler.profile(c).ShowMembers(userId, libraryId)
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryMembers{}
+	}
+	message := result.LibraryMembers{UserId: c.Param("id"), LibraryId: c.Param("libId")}
+	for _, member := range members {
+		message.Members = append(message.Members, libraryMemberResult(member))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) SetMember(c *gin.Context) (int, result.LibraryMember) {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryMember{}
+	}
+	member := request.LibraryMember{}
+	err = c.BindJSON(&member)
+	if err != nil {
+		return 400, result.LibraryMember{}
+	}
+	added, err, code := handler.profile(c).SetMember(userId, libraryId, member.UserName, member.Role)
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryMember{}
+	}
+	logf(c, "Made user #%d %s of library #%d", added.User.Id, added.Role, libraryId)
+	return 200, libraryMemberResult(added)
+}
+
+// Members may remove themselves to leave a library
+func (handler WebserviceHandler) RemoveMember(c *gin.Context) int {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	memberId, err, code := handler.profile(c).FindUserId(c.Param("memberId"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	err, code = handler.profile(c).RemoveMember(userId, libraryId, memberId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Removed user #%d from library #%d", memberId, libraryId)
+	return 204
+}
+
+func (handler WebserviceHandler) ShowMemberships(c *gin.Context) (int, result.Memberships) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Memberships{}
+	}
+	memberships, err, code := handler.profile(c).ShowMemberships(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Memberships{}
+	}
+	message := result.Memberships{UserId: c.Param("id")}
+	for _, membership := range memberships {
+		message.Libraries = append(message.Libraries, result.Membership{
+			LibraryId: membership.LibraryExternalId, Role: membership.Role, CreatedAt: membership.CreatedAt})
+	}
+	return 200, message
+}
@@ -0,0 +1,149 @@
+package interfaces
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func modResult(userId, libraryId string, mod usecases.Mod) result.Mod {
+	return result.Mod{Id: mod.Id, UserId: userId, LibraryId: libraryId, GameId: mod.GameExternalId,
+		GameName: mod.GameName, Name: mod.Name, SourceUrl: mod.SourceUrl, Version: mod.Version,
+		Enabled: mod.Enabled, CreatedAt: mod.CreatedAt, UpdatedAt: mod.UpdatedAt}
+}
+
+func modRequest(c *gin.Context) (usecases.Mod, error) {
+	mod := request.Mod{}
+	err := c.BindJSON(&mod)
+	if err != nil {
+		return usecases.Mod{}, err
+	}
+	return usecases.Mod{Name: mod.Name, SourceUrl: mod.SourceUrl, Version: mod.Version,
+		Enabled: mod.Enabled}, nil
+}
+
+// The user, library, game and mod ids of mod routes
+func (handler WebserviceHandler) modTarget(c *gin.Context) (int, int, int, int, error, int) {
+	userId, libraryId, gameId, err, code := handler.addonTarget(c)
+	if err != nil {
+		return 0, 0, 0, 0, err, code
+	}
+	modId, err := strconv.Atoi(c.Param("modId"))
+	if err != nil {
+		return 0, 0, 0, 0, domain.NewError(domain.CodeNotFound, "Mod '%s' does not exist",
+			c.Param("modId")), 404
+	}
+	return userId, libraryId, gameId, modId, nil, 200
+}
+
+func (handler WebserviceHandler) AddMod(c *gin.Context) (int, result.Mod) {
+	userId, libraryId, gameId, err, code := handler.addonTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.Mod{}
+	}
+	mod, err := modRequest(c)
+	if err != nil {
+		return 400, result.Mod{}
+	}
+	added, err, code := handler.profile(c).AddMod(userId, libraryId, gameId, mod)
+	if err != nil {
+		c.Error(err)
+		return code, result.Mod{}
+	}
+	return 201, modResult(c.Param("id"), c.Param("libId"), added)
+}
+
+func (handler WebserviceHandler) ShowMods(c *gin.Context) (int, result.Mods) {
+	userId, libraryId, gameId, err, code := handler.addonTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.Mods{}
+	}
+	mods, err, code := handler.profile(c).ShowMods(userId, libraryId, gameId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Mods{}
+	}
+	message := result.Mods{UserId: c.Param("id"), LibraryId: c.Param("libId"), GameId: c.Param("gameId")}
+	for _, mod := range mods {
+		message.Mods = append(message.Mods, modResult(c.Param("id"), c.Param("libId"), mod))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) EditMod(c *gin.Context) (int, result.Mod) {
+	userId, libraryId, gameId, modId, err, code := handler.modTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.Mod{}
+	}
+	changed, err := modRequest(c)
+	if err != nil {
+		return 400, result.Mod{}
+	}
+	mod, err, code := handler.profile(c).EditMod(userId, libraryId, gameId, modId, changed)
+	if err != nil {
+		c.Error(err)
+		return code, result.Mod{}
+	}
+	return 200, modResult(c.Param("id"), c.Param("libId"), mod)
+}
+
+func (handler WebserviceHandler) RemoveMod(c *gin.Context) int {
+	userId, libraryId, gameId, modId, err, code := handler.modTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	err, code = handler.profile(c).RemoveMod(userId, libraryId, gameId, modId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	return 204
+}
+
+// Takes the JSON profile export as the uploaded file
+func (handler WebserviceHandler) ImportMods(c *gin.Context) (int, result.ModImportReport) {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.ModImportReport{}
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.Error(domain.NewFieldError("file", "Is required"))
+		return 400, result.ModImportReport{}
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.Error(domain.NewFieldError("file", "Cannot read the upload"))
+		return 400, result.ModImportReport{}
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.Error(domain.NewFieldError("file", "Cannot read the upload"))
+		return 400, result.ModImportReport{}
+	}
+
+	report, err, code := handler.profile(c).ImportMods(userId, libraryId, data)
+	if err != nil {
+		c.Error(err)
+		return code, result.ModImportReport{}
+	}
+	logf(c, "Imported %d mods into library #%d", len(report.Imported), libraryId)
+	message := result.ModImportReport{UserId: c.Param("id"), LibraryId: c.Param("libId"),
+		Skipped: report.Skipped, Unmatched: report.Unmatched}
+	for _, mod := range report.Imported {
+		message.Imported = append(message.Imported, modResult(c.Param("id"), c.Param("libId"), mod))
+	}
+	return 200, message
+}
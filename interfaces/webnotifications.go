@@ -0,0 +1,79 @@
+package interfaces
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+)
+
+func (handler WebserviceHandler) ShowNotifications(c *gin.Context) (int, result.Notifications) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Notifications{}
+	}
+	page, err := pageQuery(c, 20)
+	if err != nil {
+		c.Error(err)
+		return 400, result.Notifications{}
+	}
+
+	notifications, unread, next, err, code := handler.notifications(c).ShowNotifications(userId,
+		page)
+	if err != nil {
+		c.Error(err)
+		return code, result.Notifications{}
+	}
+
+	message := result.Notifications{UserId: c.Param("id"), Limit: page.Limit, Cursor: next.String(),
+		HasMore: !next.IsZero(), Unread: unread}
+	for _, notification := range notifications {
+		message.Notifications = append(message.Notifications, result.Notification{
+			Id:        notification.Id,
+			Kind:      notification.Kind,
+			Message:   notification.Message,
+			Read:      notification.Read,
+			CreatedAt: notification.CreatedAt,
+		})
+	}
+	logf(c, "Printed notifications of user #%d", userId)
+	return 200, message
+}
+
+func (handler WebserviceHandler) MarkNotificationsRead(c *gin.Context) (int, result.NotificationsRead) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.NotificationsRead{}
+	}
+	notificationIds := request.NotificationIds{}
+	err = c.BindJSON(&notificationIds)
+	if err != nil {
+		return 400, result.NotificationsRead{}
+	}
+
+	err, code = handler.notifications(c).MarkNotificationsRead(userId, notificationIds.Ids)
+	if err != nil {
+		c.Error(err)
+		return code, result.NotificationsRead{}
+	}
+
+	message := result.NotificationsRead{UserId: c.Param("id"), Ids: notificationIds.Ids}
+	return 200, message
+}
+
+func (handler WebserviceHandler) ClearNotifications(c *gin.Context) (int, result.NotificationsRead) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.NotificationsRead{}
+	}
+
+	err, code = handler.notifications(c).ClearNotifications(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.NotificationsRead{}
+	}
+	return 200, result.NotificationsRead{UserId: c.Param("id")}
+}
@@ -0,0 +1,152 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+// Without a range reports cover the last week
+const defaultReportPast = 7 * 24 * time.Hour
+
+func childResult(account usecases.ChildAccount) result.ChildAccount {
+	return result.ChildAccount{Id: account.Child.ExternalId, Name: account.Child.Name,
+		DailyMinutes: account.DailyMinutes, RatingCap: account.RatingCap,
+		CreatedAt: account.CreatedAt, UpdatedAt: account.UpdatedAt}
+}
+
+func (handler WebserviceHandler) LinkChild(c *gin.Context) (int, result.ChildAccount) {
+	parentId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.ChildAccount{}
+	}
+	link := request.ChildLink{}
+	err = c.BindJSON(&link)
+	if err != nil {
+		return 400, result.ChildAccount{}
+	}
+	childId, err, code := handler.profile(c).FindLoginId(link.Username, link.Password)
+	if err != nil {
+		c.Error(err)
+		return code, result.ChildAccount{}
+	}
+
+	account, err, code := handler.parental(c).LinkChild(parentId, childId)
+	if err != nil {
+		c.Error(err)
+		return code, result.ChildAccount{}
+	}
+	logf(c, "Linked child account #%d", childId)
+	return code, childResult(account)
+}
+
+func (handler WebserviceHandler) ShowChildren(c *gin.Context) (int, result.ChildAccounts) {
+	parentId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.ChildAccounts{}
+	}
+	children, err, code := handler.parental(c).ShowChildren(parentId)
+	if err != nil {
+		c.Error(err)
+		return code, result.ChildAccounts{}
+	}
+	message := result.ChildAccounts{UserId: c.Param("id")}
+	for _, account := range children {
+		message.Children = append(message.Children, childResult(account))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) SetChildLimits(c *gin.Context) (int, result.ChildAccount) {
+	parentId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.ChildAccount{}
+	}
+	childId, err, code := handler.profile(c).FindUserId(c.Param("childId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.ChildAccount{}
+	}
+	limits := request.ChildLimits{}
+	err = c.BindJSON(&limits)
+	if err != nil {
+		return 400, result.ChildAccount{}
+	}
+
+	account, err, code := handler.parental(c).SetLimits(parentId, childId,
+		limits.DailyMinutes, limits.RatingCap)
+	if err != nil {
+		c.Error(err)
+		return code, result.ChildAccount{}
+	}
+	logf(c, "Set limits of child account #%d", childId)
+	return 200, childResult(account)
+}
+
+func (handler WebserviceHandler) UnlinkChild(c *gin.Context) int {
+	parentId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	childId, err, code := handler.profile(c).FindUserId(c.Param("childId"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	err, code = handler.parental(c).UnlinkChild(parentId, childId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Unlinked child account #%d", childId)
+	return 204
+}
+
+func (handler WebserviceHandler) ShowChildReport(c *gin.Context) (int, result.ChildReport) {
+	parentId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.ChildReport{}
+	}
+	childId, err, code := handler.profile(c).FindUserId(c.Param("childId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.ChildReport{}
+	}
+	now := time.Now().UTC()
+	from, err := timeQuery(c, "from", now.Add(-defaultReportPast))
+	if err != nil {
+		c.Error(err)
+		return 400, result.ChildReport{}
+	}
+	to, err := timeQuery(c, "to", now)
+	if err != nil {
+		c.Error(err)
+		return 400, result.ChildReport{}
+	}
+
+	report, err, code := handler.parental(c).ShowReport(parentId, childId, from, to)
+	if err != nil {
+		c.Error(err)
+		return code, result.ChildReport{}
+	}
+	message := result.ChildReport{UserId: c.Param("id"), Child: childResult(report.Account),
+		From: report.From, To: report.To, TotalMinutes: report.TotalMinutes}
+	for _, day := range report.Days {
+		message.Days = append(message.Days, result.DayPlaytime{Day: day.Day.Format("2006-01-02"),
+			Minutes: day.Minutes, OverLimit: day.OverLimit})
+	}
+	for _, game := range report.Games {
+		message.Games = append(message.Games, result.GamePlaytime{GameId: game.GameExternalId,
+			GameName: game.GameName, Minutes: game.Minutes})
+	}
+	return 200, message
+}
@@ -0,0 +1,162 @@
+package interfaces
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func personalResult(externalId string, metadata usecases.PersonalMetadata) result.PersonalMetadata {
+	return result.PersonalMetadata{GameId: externalId, Difficulty: metadata.Difficulty,
+		Replayability: metadata.Replayability, Moods: metadata.Moods, UpdatedAt: metadata.UpdatedAt}
+}
+
+func (handler WebserviceHandler) SetPersonalMetadata(c *gin.Context) (int, result.PersonalMetadata) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.PersonalMetadata{}
+	}
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.PersonalMetadata{}
+	}
+	personal := request.PersonalMetadata{}
+	err = c.BindJSON(&personal)
+	if err != nil {
+		return 400, result.PersonalMetadata{}
+	}
+
+	metadata, err, code := handler.personal(c).SetMetadata(usecases.PersonalMetadata{
+		UserId: userId, GameId: gameId, Difficulty: personal.Difficulty,
+		Replayability: personal.Replayability, Moods: personal.Moods})
+	if err != nil {
+		c.Error(err)
+		return code, result.PersonalMetadata{}
+	}
+	return 200, personalResult(c.Param("gameId"), metadata)
+}
+
+func (handler WebserviceHandler) ShowPersonalMetadata(c *gin.Context) (int, result.PersonalMetadata) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.PersonalMetadata{}
+	}
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.PersonalMetadata{}
+	}
+	metadata, err, code := handler.personal(c).ShowMetadata(userId, gameId)
+	if err != nil {
+		c.Error(err)
+		return code, result.PersonalMetadata{}
+	}
+	return 200, personalResult(c.Param("gameId"), metadata)
+}
+
+func (handler WebserviceHandler) RemovePersonalMetadata(c *gin.Context) int {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	err, code = handler.personal(c).RemoveMetadata(userId, gameId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	return 204
+}
+
+// Filtered with ?mood=cozy&mood=quick, ?maxDifficulty=2, ?minReplayability=3
+// and ?status=backlog
+func (handler WebserviceHandler) ShowTonight(c *gin.Context) (int, result.Tonight) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Tonight{}
+	}
+	filter := usecases.TonightFilter{Moods: c.QueryArray("mood"), Statuses: c.QueryArray("status")}
+	for name, score := range map[string]*int{"maxDifficulty": &filter.MaxDifficulty,
+		"minReplayability": &filter.MinReplayability} {
+		if value := c.Query(name); value != "" {
+			*score, err = strconv.Atoi(value)
+			if err != nil {
+				c.Error(domain.NewFieldError(name, "Must be a whole number"))
+				return 400, result.Tonight{}
+			}
+		}
+	}
+
+	candidates, err, code := handler.personal(c).ShowTonight(userId, filter)
+	if err != nil {
+		c.Error(err)
+		return code, result.Tonight{}
+	}
+	message := result.Tonight{UserId: c.Param("id")}
+	for _, candidate := range candidates {
+		message.Games = append(message.Games, tonightResult(candidate))
+	}
+	return 200, message
+}
+
+func tonightResult(candidate usecases.Candidate) result.TonightGame {
+	return result.TonightGame{GameId: candidate.Game.ExternalId, GameName: candidate.Game.Name,
+		Producer: candidate.Game.Producer, Rating: candidate.Game.Rating,
+		Status: candidate.Game.Status, Platform: candidate.Game.Platform,
+		Difficulty: candidate.Metadata.Difficulty, Replayability: candidate.Metadata.Replayability,
+		Moods: candidate.Metadata.Moods}
+}
+
+// Weighted with ?shortestWeight=, ?oldestWeight=, ?ratingWeight= and
+// ?moodWeight= together with ?mood=cozy, ?seed= repeats an earlier pick
+func (handler WebserviceHandler) PickNext(c *gin.Context) (int, result.Suggestion) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Suggestion{}
+	}
+	options := usecases.PickOptions{Mood: c.Query("mood")}
+	for name, weight := range map[string]*float64{"shortestWeight": &options.Weights.Shortest,
+		"oldestWeight": &options.Weights.Oldest, "ratingWeight": &options.Weights.Rating,
+		"moodWeight": &options.Weights.Mood} {
+		if value := c.Query(name); value != "" {
+			*weight, err = strconv.ParseFloat(value, 64)
+			if err != nil {
+				c.Error(domain.NewFieldError(name, "Must be a number"))
+				return 400, result.Suggestion{}
+			}
+		}
+	}
+	if value := c.Query("seed"); value != "" {
+		options.Seed, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.Error(domain.NewFieldError("seed", "Must be a whole number"))
+			return 400, result.Suggestion{}
+		}
+	}
+
+	suggestion, err, code := handler.personal(c).PickNext(userId, options)
+	if err != nil {
+		c.Error(err)
+		return code, result.Suggestion{}
+	}
+	logf(c, "Suggested game #%d with seed %d", suggestion.Candidate.Game.Id, suggestion.Seed)
+	return 200, result.Suggestion{UserId: c.Param("id"), Game: tonightResult(suggestion.Candidate),
+		AddedAt: suggestion.Candidate.AddedAt, CompletionMinutes: suggestion.Stats.CompletionMinutes,
+		UserRating: suggestion.Stats.Rating, Raters: suggestion.Stats.Raters,
+		Score: suggestion.Score, Seed: suggestion.Seed}
+}
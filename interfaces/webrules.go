@@ -0,0 +1,160 @@
+package interfaces
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func ruleResult(userId string, rule usecases.Rule) result.Rule {
+	return result.Rule{Id: rule.Id, UserId: userId, Name: rule.Name, Event: rule.Event,
+		Condition: rule.Condition, Actions: rule.Actions, Enabled: rule.Enabled, DryRun: rule.DryRun,
+		CreatedAt: rule.CreatedAt, UpdatedAt: rule.UpdatedAt}
+}
+
+func ruleFromRequest(rule request.Rule) usecases.Rule {
+	enabled := rule.Enabled == nil || *rule.Enabled
+	return usecases.Rule{Name: rule.Name, Event: rule.Event, Condition: rule.Condition,
+		Actions: rule.Actions, Enabled: enabled, DryRun: rule.DryRun}
+}
+
+func ruleId(c *gin.Context) (int, error) {
+	ruleId, err := strconv.Atoi(c.Param("ruleId"))
+	if err != nil {
+		return 0, domain.NewError(domain.CodeNotFound, "Rule '%s' does not exist", c.Param("ruleId"))
+	}
+	return ruleId, nil
+}
+
+func (handler WebserviceHandler) AddRule(c *gin.Context) (int, result.Rule) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Rule{}
+	}
+	rule := request.Rule{}
+	err = c.BindJSON(&rule)
+	if err != nil {
+		return 400, result.Rule{}
+	}
+	added, err, code := handler.rules(c).AddRule(userId, ruleFromRequest(rule))
+	if err != nil {
+		c.Error(err)
+		return code, result.Rule{}
+	}
+	logf(c, "Added rule #%d", added.Id)
+	return 201, ruleResult(c.Param("id"), added)
+}
+
+func (handler WebserviceHandler) ShowRules(c *gin.Context) (int, result.Rules) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Rules{}
+	}
+	rules, err, code := handler.rules(c).ShowRules(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Rules{}
+	}
+	message := result.Rules{UserId: c.Param("id")}
+	for _, rule := range rules {
+		message.Rules = append(message.Rules, ruleResult(c.Param("id"), rule))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) EditRule(c *gin.Context) (int, result.Rule) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Rule{}
+	}
+	ruleId, err := ruleId(c)
+	if err != nil {
+		c.Error(err)
+		return 404, result.Rule{}
+	}
+	rule := request.Rule{}
+	err = c.BindJSON(&rule)
+	if err != nil {
+		return 400, result.Rule{}
+	}
+	edited, err, code := handler.rules(c).EditRule(userId, ruleId, ruleFromRequest(rule))
+	if err != nil {
+		c.Error(err)
+		return code, result.Rule{}
+	}
+	logf(c, "Edited rule #%d", ruleId)
+	return 200, ruleResult(c.Param("id"), edited)
+}
+
+func (handler WebserviceHandler) RemoveRule(c *gin.Context) int {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	ruleId, err := ruleId(c)
+	if err != nil {
+		c.Error(err)
+		return 404
+	}
+	err, code = handler.rules(c).RemoveRule(userId, ruleId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Removed rule #%d", ruleId)
+	return 204
+}
+
+func (handler WebserviceHandler) ShowRuleRuns(c *gin.Context) (int, result.RuleRuns) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.RuleRuns{}
+	}
+	ruleId, err := ruleId(c)
+	if err != nil {
+		c.Error(err)
+		return 404, result.RuleRuns{}
+	}
+	runs, err, code := handler.rules(c).ShowRuns(userId, ruleId)
+	if err != nil {
+		c.Error(err)
+		return code, result.RuleRuns{}
+	}
+	message := result.RuleRuns{UserId: c.Param("id"), RuleId: ruleId}
+	for _, run := range runs {
+		message.Runs = append(message.Runs, result.RuleRun{Id: run.Id, Event: run.Event,
+			EntityId: run.EntityId, Actions: run.Actions, DryRun: run.DryRun, Error: run.Error,
+			CreatedAt: run.CreatedAt})
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) TestRule(c *gin.Context) (int, result.RuleTest) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.RuleTest{}
+	}
+	test := request.RuleTest{}
+	err = c.BindJSON(&test)
+	if err != nil {
+		return 400, result.RuleTest{}
+	}
+	rule := usecases.Rule{Event: test.Event, Condition: test.Condition, Actions: test.Actions}
+	tested, err, code := handler.rules(c).TestRule(userId, rule, test.Fields)
+	if err != nil {
+		c.Error(err)
+		return code, result.RuleTest{}
+	}
+	return 200, result.RuleTest{UserId: c.Param("id"), Matched: tested.Matched, Actions: tested.Actions}
+}
@@ -0,0 +1,119 @@
+package interfaces
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+// Users are shown by their external id, a removed user is left out
+func (handler WebserviceHandler) scriptResult(c *gin.Context, script usecases.Script) result.Script {
+	message := result.Script{Id: script.Id, Name: script.Name, Event: script.Event,
+		Source: script.Source, Enabled: script.Enabled, CreatedAt: script.CreatedAt,
+		UpdatedAt: script.UpdatedAt}
+	if script.UserId != 0 {
+		user, err, _ := handler.profile(c).ShowUser(script.UserId)
+		if err == nil {
+			message.UserId = user.ExternalId
+		}
+	}
+	admin, err, _ := handler.profile(c).ShowUser(script.CreatedBy)
+	if err == nil {
+		message.CreatedBy = admin.ExternalId
+	}
+	return message
+}
+
+func (handler WebserviceHandler) bindScript(c *gin.Context) (usecases.Script, error, int) {
+	script := request.Script{}
+	err := c.BindJSON(&script)
+	if err != nil {
+		return usecases.Script{}, err, 400
+	}
+	enabled := script.Enabled == nil || *script.Enabled
+	bound := usecases.Script{Name: script.Name, Event: script.Event, Source: script.Source,
+		Enabled: enabled}
+	if script.UserId != "" {
+		var code int
+		bound.UserId, err, code = handler.profile(c).FindUserId(script.UserId)
+		if err != nil {
+			c.Error(err)
+			return usecases.Script{}, err, code
+		}
+	}
+	return bound, nil, 200
+}
+
+func scriptId(c *gin.Context) (int, error) {
+	scriptId, err := strconv.Atoi(c.Param("scriptId"))
+	if err != nil {
+		return 0, domain.NewError(domain.CodeNotFound, "Script '%s' does not exist", c.Param("scriptId"))
+	}
+	return scriptId, nil
+}
+
+func (handler WebserviceHandler) AddScript(c *gin.Context) (int, result.Script) {
+	script, err, code := handler.bindScript(c)
+	if err != nil {
+		return code, result.Script{}
+	}
+	added, err, code := handler.scripts(c).AddScript(c.GetInt("userId"), script)
+	if err != nil {
+		c.Error(err)
+		return code, result.Script{}
+	}
+	logf(c, "Added script #%d", added.Id)
+	return 201, handler.scriptResult(c, added)
+}
+
+func (handler WebserviceHandler) ShowScripts(c *gin.Context) (int, result.Scripts) {
+	scripts, err, code := handler.scripts(c).ShowScripts(c.GetInt("userId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Scripts{}
+	}
+	message := result.Scripts{}
+	for _, script := range scripts {
+		message.Scripts = append(message.Scripts, handler.scriptResult(c, script))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) EditScript(c *gin.Context) (int, result.Script) {
+	scriptId, err := scriptId(c)
+	if err != nil {
+		c.Error(err)
+		return 404, result.Script{}
+	}
+	script, err, code := handler.bindScript(c)
+	if err != nil {
+		return code, result.Script{}
+	}
+	edited, err, code := handler.scripts(c).EditScript(c.GetInt("userId"), scriptId, script)
+	if err != nil {
+		c.Error(err)
+		return code, result.Script{}
+	}
+	logf(c, "Edited script #%d", scriptId)
+	return 200, handler.scriptResult(c, edited)
+}
+
+func (handler WebserviceHandler) RemoveScript(c *gin.Context) int {
+	scriptId, err := scriptId(c)
+	if err != nil {
+		c.Error(err)
+		return 404
+	}
+	err, code := handler.scripts(c).RemoveScript(c.GetInt("userId"), scriptId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Removed script #%d", scriptId)
+	return 204
+}
@@ -0,0 +1,242 @@
+package interfaces
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func savedSearchResult(userId string, search usecases.SavedSearch, token string) result.SavedSearch {
+	return result.SavedSearch{Id: search.Id, UserId: userId, Name: search.Name,
+		Filter: result.SearchFilter(search.Filter), Shared: search.ShareTokenHash != "", Token: token,
+		CreatedAt: search.CreatedAt, UpdatedAt: search.UpdatedAt}
+}
+
+func savedSearchFromRequest(search request.SavedSearch) usecases.SavedSearch {
+	return usecases.SavedSearch{Name: search.Name, Filter: usecases.GameFilter(search.Filter)}
+}
+
+func searchId(c *gin.Context) (int, error) {
+	searchId, err := strconv.Atoi(c.Param("searchId"))
+	if err != nil {
+		return 0, domain.NewError(domain.CodeNotFound, "Saved search '%s' does not exist",
+			c.Param("searchId"))
+	}
+	return searchId, nil
+}
+
+// The filter of a game list: the saved search named by ?search, or the
+// library's default search when no query parameter is given, overridden by
+// the parameters that are
+func (handler WebserviceHandler) gameFilter(c *gin.Context, userId, libraryId int) (usecases.GameFilter, error, int) {
+	var filter usecases.GameFilter
+	var err error
+	code := 200
+	if value := c.Query("search"); value != "" {
+		searchId, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return filter, domain.NewError(domain.CodeNotFound, "Saved search '%s' does not exist", value), 404
+		}
+		filter, err, code = handler.searches(c).SearchFilter(userId, searchId)
+	} else if len(c.Request.URL.Query()) == 0 {
+		filter, err, code = handler.searches(c).DefaultFilter(userId, libraryId)
+	}
+	if err != nil {
+		return filter, err, code
+	}
+
+	if value := c.Query("maxAge"); value != "" {
+		filter.MaxAge, err = strconv.Atoi(value)
+		if err != nil {
+			return filter, domain.NewFieldError("maxAge", "Must be a whole number of years"), 400
+		}
+	}
+	for param, field := range map[string]*string{"rating": &filter.Rating, "name": &filter.Name,
+		"status": &filter.Status, "platform": &filter.Platform, "tag": &filter.Tag, "sort": &filter.Sort} {
+		if value := c.Query(param); value != "" {
+			*field = value
+		}
+	}
+	return filter, nil, 200
+}
+
+func (handler WebserviceHandler) AddSearch(c *gin.Context) (int, result.SavedSearch) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.SavedSearch{}
+	}
+	search := request.SavedSearch{}
+	err = c.BindJSON(&search)
+	if err != nil {
+		return 400, result.SavedSearch{}
+	}
+	added, err, code := handler.searches(c).AddSearch(userId, savedSearchFromRequest(search))
+	if err != nil {
+		c.Error(err)
+		return code, result.SavedSearch{}
+	}
+	logf(c, "Saved search #%d", added.Id)
+	return 201, savedSearchResult(c.Param("id"), added, "")
+}
+
+func (handler WebserviceHandler) ShowSearches(c *gin.Context) (int, result.SavedSearches) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.SavedSearches{}
+	}
+	searches, err, code := handler.searches(c).ShowSearches(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.SavedSearches{}
+	}
+	message := result.SavedSearches{UserId: c.Param("id")}
+	for _, search := range searches {
+		message.Searches = append(message.Searches, savedSearchResult(c.Param("id"), search, ""))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) EditSearch(c *gin.Context) (int, result.SavedSearch) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.SavedSearch{}
+	}
+	searchId, err := searchId(c)
+	if err != nil {
+		c.Error(err)
+		return 404, result.SavedSearch{}
+	}
+	search := request.SavedSearch{}
+	err = c.BindJSON(&search)
+	if err != nil {
+		return 400, result.SavedSearch{}
+	}
+	edited, err, code := handler.searches(c).EditSearch(userId, searchId,
+		savedSearchFromRequest(search))
+	if err != nil {
+		c.Error(err)
+		return code, result.SavedSearch{}
+	}
+	logf(c, "Edited saved search #%d", searchId)
+	return 200, savedSearchResult(c.Param("id"), edited, "")
+}
+
+func (handler WebserviceHandler) RemoveSearch(c *gin.Context) int {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	searchId, err := searchId(c)
+	if err != nil {
+		c.Error(err)
+		return 404
+	}
+	err, code = handler.searches(c).RemoveSearch(userId, searchId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Removed saved search #%d", searchId)
+	return 204
+}
+
+func (handler WebserviceHandler) SetDefaultSearch(c *gin.Context) int {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	search := request.DefaultSearch{}
+	err = c.BindJSON(&search)
+	if err != nil {
+		return 400
+	}
+	err, code = handler.searches(c).SetDefaultSearch(userId, libraryId, search.SearchId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Made search #%d the default of library #%d", search.SearchId, libraryId)
+	return 204
+}
+
+func (handler WebserviceHandler) ShareSearch(c *gin.Context) (int, result.SavedSearch) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.SavedSearch{}
+	}
+	searchId, err := searchId(c)
+	if err != nil {
+		c.Error(err)
+		return 404, result.SavedSearch{}
+	}
+	search, token, err, code := handler.searches(c).ShareSearch(userId, searchId)
+	if err != nil {
+		c.Error(err)
+		return code, result.SavedSearch{}
+	}
+	logf(c, "Shared saved search #%d", searchId)
+	return 200, savedSearchResult(c.Param("id"), search, token)
+}
+
+func (handler WebserviceHandler) UnshareSearch(c *gin.Context) int {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	searchId, err := searchId(c)
+	if err != nil {
+		c.Error(err)
+		return 404
+	}
+	err, code = handler.searches(c).UnshareSearch(userId, searchId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Unshared saved search #%d", searchId)
+	return 204
+}
+
+func (handler WebserviceHandler) ImportSearch(c *gin.Context) (int, result.SavedSearch) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.SavedSearch{}
+	}
+	searchImport := request.SearchImport{}
+	err = c.BindJSON(&searchImport)
+	if err != nil {
+		return 400, result.SavedSearch{}
+	}
+	imported, err, code := handler.searches(c).ImportSearch(userId, searchImport.Token)
+	if err != nil {
+		c.Error(err)
+		return code, result.SavedSearch{}
+	}
+	logf(c, "Imported a shared search as #%d", imported.Id)
+	return 201, savedSearchResult(c.Param("id"), imported, "")
+}
+
+func (handler WebserviceHandler) ShowSharedSearch(c *gin.Context) (int, result.SharedSearch) {
+	token := c.Param("token")
+	search, err, code := handler.searches(c).ShowSharedSearch(token)
+	if err != nil {
+		c.Error(err)
+		return code, result.SharedSearch{}
+	}
+	logf(c, "Showed saved search #%d through its link", search.Id)
+	return 200, result.SharedSearch{Token: token, Name: search.Name,
+		Filter: result.SearchFilter(search.Filter)}
+}
@@ -1,33 +1,52 @@
 package interfaces
 
 import (
-	"fmt"
 	"github.com/gin-gonic/gin"
-	"strconv"
 
-	"game-tracker/domain"
 	"game-tracker/models/request"
 	"game-tracker/models/result"
 	"game-tracker/usecases"
 )
 
-type ProfileInteractor interface {
-	AddUser(player domain.Player, userName, password string) (int, error, int)
-	ShowUser(userId int) (string, []int, error, int)
-	RemoveUser(userId int) (error, int)
-	ShowUserInfo(userId int) (string, error, int)
-	EditUserInfo(userId int, info string) (error, int)
-	AddLibrary(userId int) (error, int)
-	ShowLibrary(userId, libraryId int) ([]domain.Game, error, int)
-	RemoveLibrary(userId, libraryId int) (error, int)
-	ShowGame(userId, libraryId, gameId int) (usecases.Game, error, int)
-	AddGame(userId, libraryId int, gameName, gameProducer string, gameValue float64) (int, error, int)
-	RemoveGame(userId, libraryId, gameId int) (error, int)
-	FindLoginId(username, password string) (int, error, int)
-}
-
 type WebserviceHandler struct {
-	ProfileInteractor usecases.ProfileInteractor
+	ProfileInteractor       usecases.ProfileUsecase
+	NotificationInteractor  usecases.NotificationUsecase
+	SettingsInteractor      usecases.SettingsUsecase
+	SyncInteractor          usecases.SyncUsecase
+	AdminInteractor         usecases.AdminUsecase
+	ActivityInteractor      usecases.ActivityUsecase
+	CalendarInteractor      usecases.CalendarUsecase
+	FranchiseInteractor     usecases.FranchiseUsecase
+	ParentalInteractor      usecases.ParentalUsecase
+	PersonalInteractor      usecases.PersonalUsecase
+	JournalInteractor       usecases.JournalUsecase
+	ExportInteractor        usecases.ExportUsecase
+	SharingInteractor       usecases.SharingUsecase
+	BadgeInteractor         usecases.BadgeUsecase
+	GoalInteractor          usecases.GoalUsecase
+	HardwareInteractor      usecases.HardwareUsecase
+	SubscriptionInteractor  usecases.SubscriptionUsecase
+	CatalogInteractor       usecases.CatalogUsecase
+	SpeedrunInteractor      usecases.SpeedrunUsecase
+	MatchInteractor         usecases.MatchUsecase
+	AgentInteractor         usecases.AgentUsecase
+	WebhookInteractor       usecases.WebhookUsecase
+	RuleInteractor          usecases.RuleUsecase
+	ScriptInteractor        usecases.ScriptUsecase
+	SearchInteractor        usecases.SearchUsecase
+	StatsInteractor         usecases.StatsUsecase
+	RenderInteractor        usecases.RenderUsecase
+	DiagnosticsInteractor   usecases.DiagnosticsUsecase
+	ChangeStreamInteractor  usecases.ChangeStreamUsecase
+	LibraryEventsInteractor usecases.LibraryEventsUsecase
+	UndoInteractor          usecases.UndoUsecase
+	TrashInteractor         usecases.TrashUsecase
+	IntegrityInteractor     usecases.IntegrityUsecase
+	Sessions                SessionStore
+	TokenSecret             []byte //Signs access tokens, auth checks them with the same key
+	Maintenance             *Maintenance
+	ErrorReporter           ErrorReporter       //Nil only logs recovered panics
+	Translator              usecases.Translator //Nil answers in English
 }
 
 func (handler WebserviceHandler) AddUser(c *gin.Context) (int, result.UserAdd) {
@@ -37,82 +56,118 @@ func (handler WebserviceHandler) AddUser(c *gin.Context) (int, result.UserAdd) {
 		return 400, result.UserAdd{}
 	}
 
-	player := domain.Player{Id: user.PlayerId, Name: user.PlayerName}
-	id, err, code := handler.ProfileInteractor.AddUser(player, user.Name, user.Password)
+	added, err, code := handler.profile(c).AddUser(user.PlayerName, user.Name, user.Password)
 	if err != nil {
 		c.Error(err)
 		return code, result.UserAdd{}
 	}
 
-	message := result.UserAdd{Id: id, Name: user.Name}
-	fmt.Printf("Created user #%d\n", id)
+	message := result.UserAdd{Id: added.ExternalId, Name: added.Name, PlayerId: added.Player.Id,
+		PlayerName: added.Player.Name}
+	logf(c, "Created user #%d", added.Id)
 	return 201, message
 }
 
 func (handler WebserviceHandler) ShowUser(c *gin.Context) (int, result.User) {
-	userId, err := strconv.Atoi(c.Param("id"))
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.User{}
+		return code, result.User{}
 	}
 
-	name, libraryIds, err, code := handler.ProfileInteractor.ShowUser(userId)
+	return handler.showUser(c, userId)
+}
+
+// Profile URLs such as /u/{username} name the user instead of using its id
+func (handler WebserviceHandler) ShowUserByName(c *gin.Context) (int, result.User) {
+	userId, err, code := handler.profile(c).FindUserIdByName(c.Param("username"))
+	if err != nil {
+		c.Error(err)
+		return code, result.User{}
+	}
+	return handler.showUser(c, userId)
+}
+
+func (handler WebserviceHandler) showUser(c *gin.Context, userId int) (int, result.User) {
+	user, err, code := handler.profile(c).ShowUser(userId)
 	if err != nil {
 		c.Error(err)
 		return code, result.User{}
 	}
 
 	var message result.User
-	message.Name = name
-	message.Id = userId
-	for _, libraryId := range libraryIds {
+	message.Name = user.Name
+	message.Id = user.ExternalId
+	message.CreatedAt = user.CreatedAt
+	message.UpdatedAt = user.UpdatedAt
+	for _, libraryId := range user.LibraryExternalIds {
 		message.LibraryIds = append(message.LibraryIds, libraryId)
 	}
-	fmt.Printf("Printed user #%d\n", userId)
+	logf(c, "Printed user #%d", userId)
 	return 200, message
 }
 
+func (handler WebserviceHandler) RenameUser(c *gin.Context) (int, result.User) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.User{}
+	}
+	rename := request.UserName{}
+	err = c.BindJSON(&rename)
+	if err != nil {
+		return 400, result.User{}
+	}
+
+	_, err, code = handler.profile(c).RenameUser(userId, rename.Name)
+	if err != nil {
+		c.Error(err)
+		return code, result.User{}
+	}
+	return handler.showUser(c, userId)
+}
+
 func (handler WebserviceHandler) RemoveUser(c *gin.Context) (int, result.UserDelete) {
-	userId, err := strconv.Atoi(c.Param("id"))
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.UserDelete{}
+		return code, result.UserDelete{}
 	}
 
-	err, code := handler.ProfileInteractor.RemoveUser(userId)
+	err, code = handler.profile(c).RemoveUser(userId)
 	if err != nil {
 		c.Error(err)
 		return code, result.UserDelete{}
 	}
 
-	message := result.UserDelete{Id: userId}
-	fmt.Printf("Deleted user #%d\n", userId)
+	message := result.UserDelete{Id: c.Param("id")}
+	logf(c, "Deleted user #%d", userId)
 	return 200, message
 }
 
 func (handler WebserviceHandler) ShowUserInfo(c *gin.Context) (int, result.UserInfo) {
-	userId, err := strconv.Atoi(c.Param("id"))
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.UserInfo{}
+		return code, result.UserInfo{}
 	}
 
-	info, err, code := handler.ProfileInteractor.ShowUserInfo(userId)
+	info, version, err, code := handler.profile(c).ShowUserInfo(userId)
 	if err != nil {
 		c.Error(err)
 		return code, result.UserInfo{}
 	}
 
-	message := result.UserInfo{Id: userId, Info: info}
-	fmt.Printf("Printed info of user #%d\n", userId)
+	message := result.UserInfo{Id: c.Param("id"), Info: info, Version: version}
+	logf(c, "Printed info of user #%d", userId)
 	return 200, message
 }
 
 func (handler WebserviceHandler) EditUserInfo(c *gin.Context) (int, result.UserInfo) {
-	userId, err := strconv.Atoi(c.Param("id"))
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.UserInfo{}
+		return code, result.UserInfo{}
 	}
 	userInfo := request.UserInfo{}
 	err = c.BindJSON(&userInfo)
@@ -120,121 +175,159 @@ func (handler WebserviceHandler) EditUserInfo(c *gin.Context) (int, result.UserI
 		return 400, result.UserInfo{}
 	}
 
-	err, code := handler.ProfileInteractor.EditUserInfo(userId, userInfo.Info)
+	version, err, code := handler.profile(c).EditUserInfo(userId, userInfo.Info,
+		userInfo.Version)
 	if err != nil {
 		c.Error(err)
 		return code, result.UserInfo{}
 	}
 
-	message := result.UserInfo{Id: userId, Info: userInfo.Info}
-	fmt.Printf("Editted info of user #%d\n", userId)
+	message := result.UserInfo{Id: c.Param("id"), Info: userInfo.Info, Version: version}
+	logf(c, "Editted info of user #%d", userId)
 	return 200, message
 }
 
 func (handler WebserviceHandler) AddLibrary(c *gin.Context) (int, result.LibraryAdd) {
-	userId, err := strconv.Atoi(c.Param("id"))
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.LibraryAdd{}
+		return code, result.LibraryAdd{}
 	}
-	id, err, code := handler.ProfileInteractor.AddLibrary(userId)
+	library, err, code := handler.profile(c).AddLibrary(userId)
 	if err != nil {
 		c.Error(err)
 		return code, result.LibraryAdd{}
 	}
 
-	message := result.LibraryAdd{Id: id, UserId: userId}
-	fmt.Printf("Added library #%d\n", id)
+	message := result.LibraryAdd{Id: library.ExternalId, UserId: c.Param("id"),
+		Version: library.Version}
+	logf(c, "Added library #%d", library.Id)
 	return 201, message
 }
 
 func (handler WebserviceHandler) ShowLibrary(c *gin.Context) (int, result.Library) {
-	userId, err := strconv.Atoi(c.Param("id"))
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.Library{}
+		return code, result.Library{}
 	}
-	libraryId, err := strconv.Atoi(c.Param("libId"))
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.Library{}
+		return code, result.Library{}
 	}
 
-	gameIds, err, code := handler.ProfileInteractor.ShowLibrary(userId, libraryId)
+	library, err, code := handler.profile(c).ShowLibrary(userId, libraryId)
 	if err != nil {
 		c.Error(err)
 		return code, result.Library{}
 	}
 
 	var message result.Library
-	message.Id = libraryId
-	message.UserId = userId
-	for _, gameId := range gameIds {
+	message.Id = library.ExternalId
+	message.UserId = library.User.ExternalId
+	message.Version = library.Version
+	message.CreatedAt = library.CreatedAt
+	message.UpdatedAt = library.UpdatedAt
+	for _, gameId := range library.GameExternalIds {
 		message.GamesIds = append(message.GamesIds, gameId)
 	}
-	fmt.Printf("Printed library #%d\n", libraryId)
+	logf(c, "Printed library #%d", libraryId)
+	return 200, message
+}
+
+func (handler WebserviceHandler) ShowLibraryVersions(c *gin.Context) (int, result.LibraryVersions) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryVersions{}
+	}
+
+	libraries, err, code := handler.profile(c).ShowLibraryVersions(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryVersions{}
+	}
+
+	message := result.LibraryVersions{UserId: c.Param("id")}
+	for _, library := range libraries {
+		message.Libraries = append(message.Libraries, result.LibraryVersion{
+			Id:        library.ExternalId,
+			Version:   library.Version,
+			UpdatedAt: library.UpdatedAt,
+		})
+	}
 	return 200, message
 }
 
 func (handler WebserviceHandler) RemoveLibrary(c *gin.Context) (int, result.LibraryDelete) {
-	userId, err := strconv.Atoi(c.Param("id"))
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.LibraryDelete{}
+		return code, result.LibraryDelete{}
 	}
-	libraryId, err := strconv.Atoi(c.Param("libId"))
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.LibraryDelete{}
+		return code, result.LibraryDelete{}
 	}
 
-	err, code := handler.ProfileInteractor.RemoveLibrary(userId, libraryId)
+	err, code = handler.profile(c).RemoveLibrary(userId, libraryId)
 	if err != nil {
 		c.Error(err)
 		return code, result.LibraryDelete{}
 	}
 
-	message := result.LibraryDelete{Id: libraryId}
-	fmt.Printf("Deleted library #%d\n", libraryId)
+	message := result.LibraryDelete{Id: c.Param("libId")}
+	logf(c, "Deleted library #%d", libraryId)
 	return 200, message
 }
 
 func (handler WebserviceHandler) ShowGame(c *gin.Context) (int, result.Game) {
-	userId, err := strconv.Atoi(c.Param("id"))
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.Game{}
+		return code, result.Game{}
 	}
-	libraryId, err := strconv.Atoi(c.Param("libId"))
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.Game{}
+		return code, result.Game{}
 	}
-	gameId, err := strconv.Atoi(c.Param("gameId"))
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.Game{}
+		return code, result.Game{}
 	}
 
-	game, err, code := handler.ProfileInteractor.ShowGame(userId, libraryId, gameId)
+	game, err, code := handler.profile(c).ShowGame(userId, libraryId, gameId)
 	if err != nil {
 		c.Error(err)
 		return code, result.Game{}
 	}
 
-	message := result.Game{Id: game.Id, LibraryId: libraryId, UserId: userId,
-		Name: game.Name, Producer: game.Producer, Value: game.Value}
-	fmt.Printf("Printed game #%d\n", game.Id)
+	message := result.Game{Id: game.ExternalId, LibraryId: c.Param("libId"), UserId: c.Param("id"),
+		Name: game.Name, Producer: game.Producer, Value: game.Value, MinAge: game.MinAge,
+		Rating: game.Rating, Status: game.Status, Platform: game.Platform, Tags: game.Tags, CreatedAt: game.CreatedAt,
+		UpdatedAt: game.UpdatedAt, WishlistRank: game.WishlistRank, Version: game.Version}
+	logf(c, "Printed game #%d", game.Id)
 	return 200, message
 }
 
+// ?version= is the library version the edit is based on, as for picking and
+// removing games
 func (handler WebserviceHandler) AddGame(c *gin.Context) (int, result.Game) {
-	userId, err := strconv.Atoi(c.Param("id"))
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.Game{}
+		return code, result.Game{}
+	}
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Game{}
 	}
-	libraryId, err := strconv.Atoi(c.Param("libId"))
+	baseVersion, err := versionQuery(c)
 	if err != nil {
 		c.Error(err)
 		return 400, result.Game{}
@@ -245,70 +338,98 @@ func (handler WebserviceHandler) AddGame(c *gin.Context) (int, result.Game) {
 		return 400, result.Game{}
 	}
 
-	id, err, code := handler.ProfileInteractor.AddGame(userId, libraryId, game.Name, game.Producer, game.Value)
+	added, err, code := handler.profile(c).AddGame(userId, libraryId, usecases.Game{Name: game.Name,
+		Producer: game.Producer, Value: game.Value, MinAge: game.MinAge, Rating: game.Rating},
+		baseVersion)
 	if err != nil {
 		c.Error(err)
 		return code, result.Game{}
 	}
 
-	message := result.Game{Id: id, LibraryId: libraryId, UserId: userId, Name: game.Name,
-		Producer: game.Producer, Value: game.Value}
-	fmt.Printf("Added game #%d\n", id)
+	message := result.Game{Id: added.ExternalId, LibraryId: c.Param("libId"), UserId: c.Param("id"),
+		Name: game.Name, Producer: game.Producer, Value: game.Value, MinAge: added.MinAge,
+		Rating: added.Rating}
+	logf(c, "Added game #%d", added.Id)
 	return 201, message
 }
 
 func (handler WebserviceHandler) PickGame(c *gin.Context) (int, result.GameToLib) {
-	userId, err := strconv.Atoi(c.Param("id"))
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.GameToLib{}
+		return code, result.GameToLib{}
 	}
-	libraryId, err := strconv.Atoi(c.Param("libId"))
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.GameToLib{}
+		return code, result.GameToLib{}
+	}
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.GameToLib{}
 	}
-	gameId, err := strconv.Atoi(c.Param("gameId"))
+	baseVersion, err := versionQuery(c)
 	if err != nil {
 		c.Error(err)
 		return 400, result.GameToLib{}
 	}
 
-	err, code := handler.ProfileInteractor.PickGame(userId, libraryId, gameId)
+	err, code = handler.profile(c).PickGame(userId, libraryId, gameId, baseVersion)
 	if err != nil {
 		c.Error(err)
 		return code, result.GameToLib{}
 	}
 
-	message := result.GameToLib{Id: gameId, LibraryId: libraryId, UserId: userId}
-	fmt.Printf("Added game #%d\n", gameId)
+	message := result.GameToLib{Id: c.Param("gameId"), LibraryId: c.Param("libId"),
+		UserId: c.Param("id")}
+	logf(c, "Added game #%d", gameId)
 	return 201, message
 }
 
 func (handler WebserviceHandler) RemoveGame(c *gin.Context) (int, result.GameToLib) {
-	userId, err := strconv.Atoi(c.Param("id"))
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.GameToLib{}
+		return code, result.GameToLib{}
 	}
-	libraryId, err := strconv.Atoi(c.Param("libId"))
+	libraryId, err, code := handler.profile(c).FindLibraryId(c.Param("libId"))
 	if err != nil {
 		c.Error(err)
-		return 400, result.GameToLib{}
+		return code, result.GameToLib{}
 	}
-	gameId, err := strconv.Atoi(c.Param("gameId"))
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.GameToLib{}
+	}
+	baseVersion, err := versionQuery(c)
 	if err != nil {
 		c.Error(err)
 		return 400, result.GameToLib{}
 	}
 
-	err, code := handler.ProfileInteractor.RemoveGame(userId, libraryId, gameId)
+	err, code = handler.profile(c).RemoveGame(userId, libraryId, gameId, baseVersion)
 	if err != nil {
 		c.Error(err)
 		return code, result.GameToLib{}
 	}
 
-	message := result.GameToLib{Id: gameId, LibraryId: libraryId}
-	fmt.Printf("Deleted game #%d\n", gameId)
+	message := result.GameToLib{Id: c.Param("gameId"), LibraryId: c.Param("libId")}
+	logf(c, "Deleted game #%d", gameId)
 	return 200, message
 }
+
+func (handler WebserviceHandler) ShowFeatures(c *gin.Context) (int, result.Features) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Features{}
+	}
+	features, err, code := handler.profile(c).ShowFeatures(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Features{}
+	}
+	return 200, result.Features{UserId: c.Param("id"), Features: features}
+}
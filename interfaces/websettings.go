@@ -0,0 +1,143 @@
+package interfaces
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func (handler WebserviceHandler) ShowSettings(c *gin.Context) (int, result.Settings) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Settings{}
+	}
+
+	settings, err, code := handler.settings(c).ShowSettings(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Settings{}
+	}
+	return 200, settingsResult(c.Param("id"), settings)
+}
+
+func (handler WebserviceHandler) EditSettings(c *gin.Context) (int, result.Settings) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Settings{}
+	}
+	changes := request.Settings{}
+	err = c.BindJSON(&changes)
+	if err != nil {
+		return 400, result.Settings{}
+	}
+
+	settings, err, code := handler.settings(c).ShowSettings(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Settings{}
+	}
+	applySettingsChanges(&settings, changes)
+	if changes.DefaultLibraryId != nil {
+		settings.DefaultLibraryId = 0
+		if *changes.DefaultLibraryId != "" {
+			settings.DefaultLibraryId, err, code = handler.profile(c).FindLibraryId(
+				*changes.DefaultLibraryId)
+			if err != nil {
+				c.Error(err)
+				return code, result.Settings{}
+			}
+		}
+	}
+
+	settings, err, code = handler.settings(c).EditSettings(userId, settings)
+	if err != nil {
+		c.Error(err)
+		return code, result.Settings{}
+	}
+	logf(c, "Editted settings of user #%d", userId)
+	return 200, settingsResult(c.Param("id"), settings)
+}
+
+// Only the fields present in the request body replace the stored settings,
+// the default library is resolved separately from its external id
+func applySettingsChanges(settings *usecases.Settings, changes request.Settings) {
+	if changes.DisplayCurrency != nil {
+		settings.DisplayCurrency = *changes.DisplayCurrency
+	}
+	if changes.Timezone != nil {
+		settings.Timezone = *changes.Timezone
+	}
+	if changes.NotifyLibraries != nil {
+		settings.NotifyLibraries = *changes.NotifyLibraries
+	}
+	if changes.NotifyGames != nil {
+		settings.NotifyGames = *changes.NotifyGames
+	}
+	if changes.ProfilePublic != nil {
+		settings.ProfilePublic = *changes.ProfilePublic
+	}
+	if changes.LibrariesPublic != nil {
+		settings.LibrariesPublic = *changes.LibrariesPublic
+	}
+	if changes.RatingLimit != nil {
+		settings.RatingLimit = *changes.RatingLimit
+	}
+	if changes.Locale != nil {
+		settings.Locale = *changes.Locale
+	}
+}
+
+// The locale of the signed in user's settings, else the best match of
+// Accept-Language
+func (handler WebserviceHandler) Locale(c *gin.Context) string {
+	if handler.Translator == nil {
+		return usecases.DefaultLocale
+	}
+	if userId := c.GetInt("userId"); userId != 0 {
+		locale, err := handler.settings(c).UserLocale(userId)
+		if err != nil {
+			logf(c, "Cannot load locale of user #%d: %v", userId, err)
+		}
+		if locale != "" && handler.Translator.Supports(locale) {
+			return locale
+		}
+	}
+	return handler.Translator.Match(c.GetHeader("Accept-Language"))
+}
+
+// Text the usecases write in English, in the locale of the request
+func (handler WebserviceHandler) translate(c *gin.Context, format string, args ...interface{}) string {
+	if handler.Translator == nil {
+		return fmt.Sprintf(format, args...)
+	}
+	return handler.Translator.Translate(c.GetString("locale"), format, args...)
+}
+
+// Names and descriptions the usecases hold in English, which are not formats
+func (handler WebserviceHandler) translateText(c *gin.Context, text string) string {
+	if handler.Translator == nil {
+		return text
+	}
+	return handler.Translator.Lookup(c.GetString("locale"), text)
+}
+
+func settingsResult(userId string, settings usecases.Settings) result.Settings {
+	return result.Settings{
+		UserId:           userId,
+		DisplayCurrency:  settings.DisplayCurrency,
+		Timezone:         settings.Timezone,
+		NotifyLibraries:  settings.NotifyLibraries,
+		NotifyGames:      settings.NotifyGames,
+		DefaultLibraryId: settings.DefaultLibraryExternalId,
+		ProfilePublic:    settings.ProfilePublic,
+		LibrariesPublic:  settings.LibrariesPublic,
+		RatingLimit:      settings.RatingLimit,
+		Locale:           settings.Locale,
+	}
+}
@@ -0,0 +1,106 @@
+package interfaces
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func shareLinkResult(c *gin.Context, link usecases.ShareLink, token string) result.ShareLink {
+	return result.ShareLink{Id: link.Id, UserId: c.Param("id"), LibraryId: c.Param("libId"),
+		Token: token, HasPassword: link.PasswordHash != "", ExpiresAt: link.ExpiresAt,
+		CreatedAt: link.CreatedAt}
+}
+
+func (handler WebserviceHandler) ShareLibrary(c *gin.Context) (int, result.ShareLink) {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.ShareLink{}
+	}
+	share := request.ShareLink{}
+	err = c.BindJSON(&share)
+	if err != nil {
+		return 400, result.ShareLink{}
+	}
+	var expiresAt time.Time
+	if share.ExpiresAt != "" {
+		expiresAt, err = time.Parse(time.RFC3339, share.ExpiresAt)
+		if err != nil {
+			c.Error(domain.NewFieldError("expiresAt", "Must be an RFC 3339 time"))
+			return 400, result.ShareLink{}
+		}
+	}
+
+	link, token, err, code := handler.sharing(c).ShareLibrary(userId, libraryId, expiresAt,
+		share.Password)
+	if err != nil {
+		c.Error(err)
+		return code, result.ShareLink{}
+	}
+	logf(c, "Shared library #%d as link #%d", libraryId, link.Id)
+	return 201, shareLinkResult(c, link, token)
+}
+
+func (handler WebserviceHandler) ShowShareLinks(c *gin.Context) (int, result.ShareLinks) {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.ShareLinks{}
+	}
+	links, err, code := handler.sharing(c).ShowShareLinks(userId, libraryId)
+	if err != nil {
+		c.Error(err)
+		return code, result.ShareLinks{}
+	}
+	message := result.ShareLinks{UserId: c.Param("id"), LibraryId: c.Param("libId")}
+	for _, link := range links {
+		message.Links = append(message.Links, shareLinkResult(c, link, ""))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) RevokeShareLink(c *gin.Context) int {
+	userId, libraryId, err, code := handler.copyTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	linkId, err := strconv.Atoi(c.Param("shareId"))
+	if err != nil {
+		c.Error(domain.NewError(domain.CodeNotFound, "Share link '%s' does not exist", c.Param("shareId")))
+		return 404
+	}
+	err, code = handler.sharing(c).RevokeShareLink(userId, libraryId, linkId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Revoked share link #%d", linkId)
+	return 204
+}
+
+// Anyone holding the link may read, the token is the only credential. A
+// password goes in the X-Share-Password header so it stays out of URLs and logs.
+func (handler WebserviceHandler) ShowSharedLibrary(c *gin.Context) (int, result.SharedLibrary) {
+	token := c.Param("token")
+	owner, link, games, err, code := handler.sharing(c).ShowSharedLibrary(token,
+		c.GetHeader("X-Share-Password"))
+	if err != nil {
+		c.Error(err)
+		return code, result.SharedLibrary{}
+	}
+	message := result.SharedLibrary{Token: token, OwnerName: owner.Name, ExpiresAt: link.ExpiresAt}
+	for _, game := range games {
+		message.Games = append(message.Games, result.Game{Name: game.Name, Producer: game.Producer,
+			Rating: game.Rating, Status: game.Status, Platform: game.Platform, Tags: game.Tags})
+	}
+	logf(c, "Showed library #%d through share link #%d", link.LibraryId, link.Id)
+	return 200, message
+}
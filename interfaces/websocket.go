@@ -0,0 +1,172 @@
+package interfaces
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"game-tracker/domain"
+)
+
+// Appended to the key of the client before hashing, see RFC 6455
+const websocketGuid = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Subprotocol browsers name to carry the auth token in
+// Sec-WebSocket-Protocol, as they cannot set X-Auth-Key
+const WebsocketProtocol = "gametracker"
+
+// Frames clients may send, longer ones end the connection
+const maxWebsocketFrame = 4096
+
+const (
+	websocketText  = 0x1
+	websocketClose = 0x8
+	websocketPing  = 0x9
+	websocketPong  = 0xA
+)
+
+// A server side WebSocket that only pushes text messages, what clients send
+// besides pings and close frames is ignored
+type websocketConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	lock   sync.Mutex //Writes of the pusher and the reader interleave
+	closed chan struct{}
+}
+
+func isWebsocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
+// Answers the handshake on the connection taken over from the server
+func upgradeWebsocket(w http.ResponseWriter, r *http.Request) (*websocketConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !isWebsocketUpgrade(r) || key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, domain.NewError(domain.CodeInvalid, "Request is not a WebSocket upgrade")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("Cannot take over the connection")
+	}
+	conn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha1.Sum([]byte(key + websocketGuid))
+	response := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(hash[:]) + "\r\n"
+	for _, protocol := range strings.Split(r.Header.Get("Sec-WebSocket-Protocol"), ",") {
+		if strings.TrimSpace(protocol) == WebsocketProtocol {
+			response += "Sec-WebSocket-Protocol: " + WebsocketProtocol + "\r\n"
+			break
+		}
+	}
+	_, err = conn.Write([]byte(response + "\r\n"))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	socket := &websocketConn{conn: conn, reader: buffered.Reader, closed: make(chan struct{})}
+	go socket.read()
+	return socket, nil
+}
+
+func (socket *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+	switch {
+	case len(payload) < 126:
+		header = append(header, byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(len(payload)))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(len(payload)))
+	}
+	socket.lock.Lock()
+	defer socket.lock.Unlock()
+	socket.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := socket.conn.Write(append(header, payload...))
+	return err
+}
+
+func (socket *websocketConn) WriteText(message []byte) error {
+	return socket.writeFrame(websocketText, message)
+}
+
+// Closed once the client went away or closed the socket
+func (socket *websocketConn) Done() <-chan struct{} {
+	return socket.closed
+}
+
+func (socket *websocketConn) Close() error {
+	socket.writeFrame(websocketClose, nil)
+	return socket.conn.Close()
+}
+
+// Answers pings until the client closes, frames of clients are masked
+func (socket *websocketConn) read() {
+	defer close(socket.closed)
+	for {
+		opcode, payload, err := socket.readFrame()
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case websocketClose:
+			socket.writeFrame(websocketClose, nil)
+			return
+		case websocketPing:
+			socket.writeFrame(websocketPong, payload)
+		}
+	}
+}
+
+func (socket *websocketConn) readFrame() (byte, []byte, error) {
+	header := make([]byte, 2)
+	_, err := io.ReadFull(socket.reader, header)
+	if err != nil {
+		return 0, nil, err
+	}
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		_, err = io.ReadFull(socket.reader, extended)
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		_, err = io.ReadFull(socket.reader, extended)
+		length = binary.BigEndian.Uint64(extended)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if header[1]&0x80 == 0 || length > maxWebsocketFrame {
+		return 0, nil, errors.New("Frame is unmasked or too long")
+	}
+	mask := make([]byte, 4)
+	_, err = io.ReadFull(socket.reader, mask)
+	if err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, length)
+	_, err = io.ReadFull(socket.reader, payload)
+	if err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return header[0] & 0x0F, payload, nil
+}
@@ -0,0 +1,156 @@
+package interfaces
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func speedRunResult(c *gin.Context, run usecases.SpeedRun) result.SpeedRun {
+	message := result.SpeedRun{Id: run.Id, UserId: c.Param("id"), GameId: run.GameExternalId,
+		GameName: run.GameName, Category: run.Category, Milliseconds: run.Milliseconds, Time: run.Time(),
+		RunAt: run.RunAt, PersonalBest: run.PersonalBest}
+	if run.Runner.Id != 0 {
+		message.UserId, message.Runner = run.Runner.ExternalId, run.Runner.Name
+	}
+	return message
+}
+
+// The user and game of speedrun routes
+func (handler WebserviceHandler) speedrunTarget(c *gin.Context) (int, int, error, int) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		return 0, 0, err, code
+	}
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
+	if err != nil {
+		return 0, 0, err, code
+	}
+	return userId, gameId, nil, 200
+}
+
+func (handler WebserviceHandler) AddSpeedRun(c *gin.Context) (int, result.SpeedRun) {
+	userId, gameId, err, code := handler.speedrunTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.SpeedRun{}
+	}
+	run := request.SpeedRun{}
+	err = c.BindJSON(&run)
+	if err != nil {
+		return 400, result.SpeedRun{}
+	}
+	var runAt time.Time
+	if run.RunAt != "" {
+		runAt, err = time.Parse(time.RFC3339, run.RunAt)
+		if err != nil {
+			c.Error(domain.NewFieldError("runAt", "Must be an RFC 3339 time"))
+			return 400, result.SpeedRun{}
+		}
+	}
+
+	added, err, code := handler.speedruns(c).AddRun(userId, gameId, usecases.SpeedRun{
+		Category: run.Category, Milliseconds: run.Milliseconds, RunAt: runAt})
+	if err != nil {
+		c.Error(err)
+		return code, result.SpeedRun{}
+	}
+	logf(c, "Recorded run #%d", added.Id)
+	return 201, speedRunResult(c, added)
+}
+
+func (handler WebserviceHandler) ShowSpeedRuns(c *gin.Context) (int, result.SpeedRuns) {
+	userId, gameId, err, code := handler.speedrunTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.SpeedRuns{}
+	}
+	page, err := pageQuery(c, 50)
+	if err != nil {
+		c.Error(err)
+		return 400, result.SpeedRuns{}
+	}
+	runs, next, err, code := handler.speedruns(c).ShowRuns(userId, gameId, c.Query("category"),
+		page)
+	if err != nil {
+		c.Error(err)
+		return code, result.SpeedRuns{}
+	}
+	message := result.SpeedRuns{UserId: c.Param("id"), GameId: c.Param("gameId"),
+		Limit: page.Limit, Cursor: next.String(), HasMore: !next.IsZero()}
+	for _, run := range runs {
+		message.Runs = append(message.Runs, speedRunResult(c, run))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) ShowPersonalBests(c *gin.Context) (int, result.SpeedRuns) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.SpeedRuns{}
+	}
+	runs, err, code := handler.speedruns(c).ShowPersonalBests(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.SpeedRuns{}
+	}
+	message := result.SpeedRuns{UserId: c.Param("id")}
+	for _, run := range runs {
+		message.Runs = append(message.Runs, speedRunResult(c, run))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) ComparePersonalBests(c *gin.Context) (int, result.SpeedrunComparisons) {
+	userId, gameId, err, code := handler.speedrunTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.SpeedrunComparisons{}
+	}
+	comparisons, err, code := handler.speedruns(c).ComparePersonalBests(userId, gameId)
+	if err != nil {
+		c.Error(err)
+		return code, result.SpeedrunComparisons{}
+	}
+	message := result.SpeedrunComparisons{UserId: c.Param("id"), GameId: c.Param("gameId")}
+	for _, comparison := range comparisons {
+		compared := result.SpeedrunComparison{Category: comparison.Category}
+		if comparison.Own.Id != 0 {
+			own := speedRunResult(c, comparison.Own)
+			compared.Own = &own
+		}
+		for _, run := range comparison.Runs {
+			compared.Runs = append(compared.Runs, speedRunResult(c, run))
+			compared.Behind = append(compared.Behind, run.Milliseconds-comparison.Own.Milliseconds)
+		}
+		message.Comparisons = append(message.Comparisons, compared)
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) RemoveSpeedRun(c *gin.Context) int {
+	userId, gameId, err, code := handler.speedrunTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	runId, err := strconv.Atoi(c.Param("runId"))
+	if err != nil {
+		c.Error(domain.NewError(domain.CodeNotFound, "Run '%s' does not exist", c.Param("runId")))
+		return 404
+	}
+	err, code = handler.speedruns(c).RemoveRun(userId, gameId, runId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Removed run #%d", runId)
+	return 204
+}
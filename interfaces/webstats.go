@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/result"
+)
+
+func (handler WebserviceHandler) ShowLibraryStats(c *gin.Context) (int, result.LibraryStats) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryStats{}
+	}
+	stats, err, code := handler.StatsInteractor.ShowStats(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.LibraryStats{}
+	}
+	return 200, result.LibraryStats{UserId: c.Param("id"), Libraries: stats.Libraries,
+		Games: stats.Games, Value: stats.Value, Statuses: stats.Statuses, Platforms: stats.Platforms,
+		PlatformMinutes: stats.PlatformMinutes, RefreshedAt: stats.RefreshedAt}
+}
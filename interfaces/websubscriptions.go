@@ -0,0 +1,254 @@
+package interfaces
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func subscriptionResult(c *gin.Context, subscription usecases.Subscription) result.Subscription {
+	message := result.Subscription{Id: subscription.Id, UserId: c.Param("id"),
+		Service: subscription.Service, MonthlyCost: subscription.MonthlyCost,
+		Currency: subscription.Currency, BillingMonths: subscription.BillingMonths,
+		StartedOn: formatDay(subscription.StartedOn), RenewsOn: formatDay(subscription.RenewsOn),
+		EndsOn: formatDay(subscription.EndsOn), CreatedAt: subscription.CreatedAt,
+		UpdatedAt: subscription.UpdatedAt}
+	for _, game := range subscription.Games {
+		message.GameIds = append(message.GameIds, game.ExternalId)
+	}
+	return message
+}
+
+// Turns the request into a subscription, dates left out stay zero
+func subscriptionRequest(c *gin.Context) (usecases.Subscription, error) {
+	changed := request.Subscription{}
+	err := c.BindJSON(&changed)
+	if err != nil {
+		return usecases.Subscription{}, err
+	}
+	subscription := usecases.Subscription{Service: changed.Service, MonthlyCost: changed.MonthlyCost,
+		Currency: changed.Currency, BillingMonths: changed.BillingMonths}
+	for _, date := range []struct {
+		field string
+		value string
+		day   *time.Time
+	}{{"startedOn", changed.StartedOn, &subscription.StartedOn},
+		{"endsOn", changed.EndsOn, &subscription.EndsOn}} {
+		if date.value == "" {
+			continue
+		}
+		*date.day, err = time.Parse("2006-01-02", date.value)
+		if err != nil {
+			err = domain.NewFieldError(date.field, "Must be a date such as 2026-11-01")
+			c.Error(err)
+			return usecases.Subscription{}, err
+		}
+	}
+	return subscription, nil
+}
+
+// The user and subscription of the request
+func (handler WebserviceHandler) subscriptionTarget(c *gin.Context) (int, int, error, int) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		return 0, 0, err, code
+	}
+	subscriptionId, err := strconv.Atoi(c.Param("subscriptionId"))
+	if err != nil {
+		return 0, 0, domain.NewError(domain.CodeNotFound, "Subscription '%s' does not exist",
+			c.Param("subscriptionId")), 404
+	}
+	return userId, subscriptionId, nil, 200
+}
+
+func (handler WebserviceHandler) AddSubscription(c *gin.Context) (int, result.Subscription) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Subscription{}
+	}
+	subscription, err := subscriptionRequest(c)
+	if err != nil {
+		return 400, result.Subscription{}
+	}
+	added, err, code := handler.subscriptions(c).AddSubscription(userId, subscription)
+	if err != nil {
+		c.Error(err)
+		return code, result.Subscription{}
+	}
+	logf(c, "Added subscription #%d", added.Id)
+	return 201, subscriptionResult(c, added)
+}
+
+func (handler WebserviceHandler) ShowSubscriptions(c *gin.Context) (int, result.Subscriptions) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Subscriptions{}
+	}
+	subscriptions, err, code := handler.subscriptions(c).ShowSubscriptions(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Subscriptions{}
+	}
+	message := result.Subscriptions{UserId: c.Param("id")}
+	for _, subscription := range subscriptions {
+		message.Subscriptions = append(message.Subscriptions, subscriptionResult(c, subscription))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) ShowSubscription(c *gin.Context) (int, result.Subscription) {
+	userId, subscriptionId, err, code := handler.subscriptionTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.Subscription{}
+	}
+	subscription, err, code := handler.subscriptions(c).ShowSubscription(userId, subscriptionId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Subscription{}
+	}
+	return 200, subscriptionResult(c, subscription)
+}
+
+func (handler WebserviceHandler) EditSubscription(c *gin.Context) (int, result.Subscription) {
+	userId, subscriptionId, err, code := handler.subscriptionTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.Subscription{}
+	}
+	changed, err := subscriptionRequest(c)
+	if err != nil {
+		return 400, result.Subscription{}
+	}
+	subscription, err, code := handler.subscriptions(c).EditSubscription(userId, subscriptionId, changed)
+	if err != nil {
+		c.Error(err)
+		return code, result.Subscription{}
+	}
+	logf(c, "Edited subscription #%d", subscriptionId)
+	return 200, subscriptionResult(c, subscription)
+}
+
+func (handler WebserviceHandler) RemoveSubscription(c *gin.Context) int {
+	userId, subscriptionId, err, code := handler.subscriptionTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	err, code = handler.subscriptions(c).RemoveSubscription(userId, subscriptionId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Removed subscription #%d", subscriptionId)
+	return 204
+}
+
+// The game of the path was played through the subscription
+func (handler WebserviceHandler) AddSubscriptionGame(c *gin.Context) (int, result.Subscription) {
+	userId, subscriptionId, err, code := handler.subscriptionTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.Subscription{}
+	}
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Subscription{}
+	}
+	subscription, err, code := handler.subscriptions(c).AddSubscriptionGame(userId,
+		subscriptionId, gameId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Subscription{}
+	}
+	return 200, subscriptionResult(c, subscription)
+}
+
+func (handler WebserviceHandler) RemoveSubscriptionGame(c *gin.Context) (int, result.Subscription) {
+	userId, subscriptionId, err, code := handler.subscriptionTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.Subscription{}
+	}
+	gameId, err, code := handler.profile(c).FindGameId(c.Param("gameId"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Subscription{}
+	}
+	subscription, err, code := handler.subscriptions(c).RemoveSubscriptionGame(userId,
+		subscriptionId, gameId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Subscription{}
+	}
+	return 200, subscriptionResult(c, subscription)
+}
+
+// The range defaults to the last twelve months
+func (handler WebserviceHandler) ShowSubscriptionReport(c *gin.Context) (int, result.SubscriptionReport) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.SubscriptionReport{}
+	}
+	now := time.Now().UTC()
+	from, err := timeQuery(c, "from", now.AddDate(-1, 0, 0))
+	if err != nil {
+		c.Error(err)
+		return 400, result.SubscriptionReport{}
+	}
+	to, err := timeQuery(c, "to", now)
+	if err != nil {
+		c.Error(err)
+		return 400, result.SubscriptionReport{}
+	}
+
+	report, err, code := handler.subscriptions(c).ShowSubscriptionReport(userId, from, to)
+	if err != nil {
+		c.Error(err)
+		return code, result.SubscriptionReport{}
+	}
+	message := result.SubscriptionReport{UserId: c.Param("id"), From: report.From, To: report.To,
+		Cost: report.Cost, Minutes: report.Minutes}
+	for _, usage := range report.Subscriptions {
+		message.Subscriptions = append(message.Subscriptions, result.SubscriptionUsage{
+			Subscription: subscriptionResult(c, usage.Subscription), Cost: usage.Cost,
+			Minutes: usage.Minutes, CostPerHour: usage.CostPerHour,
+			Games: gameMinutesResult(usage.Games)})
+	}
+	return 200, message
+}
+
+// Wishlist and backlog games included in a subscription catalog
+func (handler WebserviceHandler) ShowCatalogGames(c *gin.Context) (int, result.CatalogGames) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.CatalogGames{}
+	}
+	games, err, code := handler.catalogs(c).ShowCatalogGames(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.CatalogGames{}
+	}
+	message := result.CatalogGames{UserId: c.Param("id")}
+	for _, game := range games {
+		flagged := result.CatalogGame{GameId: game.Game.ExternalId, GameName: game.Game.Name,
+			Status: game.Game.Status}
+		for _, entry := range game.Catalogs {
+			flagged.Catalogs = append(flagged.Catalogs, result.CatalogAvailability{
+				Service: entry.Service, LeavesOn: formatDay(entry.LeavesOn)})
+		}
+		message.Games = append(message.Games, flagged)
+	}
+	return 200, message
+}
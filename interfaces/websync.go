@@ -0,0 +1,41 @@
+package interfaces
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/result"
+)
+
+func (handler WebserviceHandler) Sync(c *gin.Context) (int, result.Sync) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Sync{}
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		c.Error(err)
+		return 400, result.Sync{}
+	}
+
+	changes, cursor, hasMore, err, code := handler.SyncInteractor.Sync(userId, c.Query("cursor"), limit)
+	if err != nil {
+		c.Error(err)
+		return code, result.Sync{}
+	}
+
+	message := result.Sync{UserId: c.Param("id"), Cursor: cursor, HasMore: hasMore}
+	for _, change := range changes {
+		message.Changes = append(message.Changes, result.Change{
+			Entity:    change.Entity,
+			EntityId:  change.EntityId,
+			ParentId:  change.ParentId,
+			Action:    change.Action,
+			ChangedAt: change.ChangedAt,
+		})
+	}
+	logf(c, "Synced %d changes of user #%d", len(changes), userId)
+	return 200, message
+}
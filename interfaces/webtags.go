@@ -0,0 +1,72 @@
+package interfaces
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func tagChangeResult(userId string, change usecases.TagChange) result.TagChange {
+	return result.TagChange{UserId: userId, From: change.From, To: change.To, Games: change.Games,
+		Libraries: change.Libraries}
+}
+
+func (handler WebserviceHandler) ShowTags(c *gin.Context) (int, result.Tags) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Tags{}
+	}
+	tags, err, code := handler.profile(c).ShowTags(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Tags{}
+	}
+	message := result.Tags{UserId: c.Param("id")}
+	for _, tag := range tags {
+		message.Tags = append(message.Tags, result.Tag{Tag: tag.Tag, Games: tag.Games})
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) RenameTag(c *gin.Context) (int, result.TagChange) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.TagChange{}
+	}
+	rename := request.TagRename{}
+	err = c.BindJSON(&rename)
+	if err != nil {
+		return 400, result.TagChange{}
+	}
+	change, err, code := handler.profile(c).RenameTag(userId, rename.From, rename.To)
+	if err != nil {
+		c.Error(err)
+		return code, result.TagChange{}
+	}
+	logf(c, "Renamed tag on %d games", change.Games)
+	return 200, tagChangeResult(c.Param("id"), change)
+}
+
+func (handler WebserviceHandler) MergeTags(c *gin.Context) (int, result.TagChange) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.TagChange{}
+	}
+	merge := request.TagMerge{}
+	err = c.BindJSON(&merge)
+	if err != nil {
+		return 400, result.TagChange{}
+	}
+	change, err, code := handler.profile(c).MergeTags(userId, merge.From, merge.Into)
+	if err != nil {
+		c.Error(err)
+		return code, result.TagChange{}
+	}
+	logf(c, "Merged tags on %d games", change.Games)
+	return 200, tagChangeResult(c.Param("id"), change)
+}
@@ -0,0 +1,140 @@
+package interfaces
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func tradeOfferResult(c *gin.Context, offer usecases.TradeOffer) result.TradeOffer {
+	return result.TradeOffer{Id: offer.Id, UserId: c.Param("id"), ProposerId: offer.ProposerExternalId,
+		ProposerLibraryId: offer.ProposerLibraryExternalId, RecipientId: offer.RecipientExternalId,
+		RecipientLibraryId: offer.RecipientLibraryExternalId, Offered: offer.Offered,
+		Requested: offer.Requested, Status: offer.Status, CounterOf: offer.CounterOf,
+		CreatedAt: offer.CreatedAt, UpdatedAt: offer.UpdatedAt}
+}
+
+// The user and trade of the request
+func (handler WebserviceHandler) tradeTarget(c *gin.Context) (int, int, error, int) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		return 0, 0, err, code
+	}
+	tradeId, err := strconv.Atoi(c.Param("tradeId"))
+	if err != nil {
+		return 0, 0, domain.NewError(domain.CodeNotFound, "Trade '%s' does not exist", c.Param("tradeId")), 404
+	}
+	return userId, tradeId, nil, 200
+}
+
+func (handler WebserviceHandler) ProposeTrade(c *gin.Context) (int, result.TradeOffer) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.TradeOffer{}
+	}
+	trade := request.TradeOffer{}
+	err = c.BindJSON(&trade)
+	if err != nil {
+		return 400, result.TradeOffer{}
+	}
+	offer := usecases.TradeOffer{Offered: trade.Offered, Requested: trade.Requested}
+	offer.ProposerLibraryId, err, code = handler.profile(c).FindLibraryId(trade.LibraryId)
+	if err == nil {
+		offer.RecipientId, err, code = handler.profile(c).FindUserId(trade.RecipientId)
+	}
+	if err == nil {
+		offer.RecipientLibraryId, err, code = handler.profile(c).FindLibraryId(trade.RecipientLibraryId)
+	}
+	if err != nil {
+		c.Error(err)
+		return code, result.TradeOffer{}
+	}
+
+	offer, err, code = handler.profile(c).ProposeTrade(userId, offer)
+	if err != nil {
+		c.Error(err)
+		return code, result.TradeOffer{}
+	}
+	logf(c, "Proposed trade #%d", offer.Id)
+	return 201, tradeOfferResult(c, offer)
+}
+
+func (handler WebserviceHandler) ShowTrades(c *gin.Context) (int, result.TradeOffers) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.TradeOffers{}
+	}
+	page, err := pageQuery(c, 50)
+	if err != nil {
+		c.Error(err)
+		return 400, result.TradeOffers{}
+	}
+	offers, next, err, code := handler.profile(c).ShowTrades(userId, page)
+	if err != nil {
+		c.Error(err)
+		return code, result.TradeOffers{}
+	}
+	message := result.TradeOffers{UserId: c.Param("id"), Limit: page.Limit,
+		Cursor: next.String(), HasMore: !next.IsZero()}
+	for _, offer := range offers {
+		message.Offers = append(message.Offers, tradeOfferResult(c, offer))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) ShowTrade(c *gin.Context) (int, result.TradeOffer) {
+	return handler.answerTrade(c, handler.profile(c).ShowTrade)
+}
+
+func (handler WebserviceHandler) AcceptTrade(c *gin.Context) (int, result.TradeOffer) {
+	return handler.answerTrade(c, handler.profile(c).AcceptTrade)
+}
+
+func (handler WebserviceHandler) DeclineTrade(c *gin.Context) (int, result.TradeOffer) {
+	return handler.answerTrade(c, handler.profile(c).DeclineTrade)
+}
+
+func (handler WebserviceHandler) CancelTrade(c *gin.Context) (int, result.TradeOffer) {
+	return handler.answerTrade(c, handler.profile(c).CancelTrade)
+}
+
+func (handler WebserviceHandler) answerTrade(c *gin.Context, answer func(userId, tradeId int) (usecases.TradeOffer, error, int)) (int, result.TradeOffer) {
+	userId, tradeId, err, code := handler.tradeTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.TradeOffer{}
+	}
+	offer, err, code := answer(userId, tradeId)
+	if err != nil {
+		c.Error(err)
+		return code, result.TradeOffer{}
+	}
+	return 200, tradeOfferResult(c, offer)
+}
+
+func (handler WebserviceHandler) CounterTrade(c *gin.Context) (int, result.TradeOffer) {
+	userId, tradeId, err, code := handler.tradeTarget(c)
+	if err != nil {
+		c.Error(err)
+		return code, result.TradeOffer{}
+	}
+	counter := request.TradeCounter{}
+	err = c.BindJSON(&counter)
+	if err != nil {
+		return 400, result.TradeOffer{}
+	}
+	offer, err, code := handler.profile(c).CounterTrade(userId, tradeId, counter.Offered, counter.Requested)
+	if err != nil {
+		c.Error(err)
+		return code, result.TradeOffer{}
+	}
+	logf(c, "Countered trade #%d with trade #%d", tradeId, offer.Id)
+	return 201, tradeOfferResult(c, offer)
+}
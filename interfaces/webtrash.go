@@ -0,0 +1,62 @@
+package interfaces
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+// Games removed from the catalog since keep an empty id
+func trashItemResult(item usecases.TrashItem) result.TrashItem {
+	message := result.TrashItem{Id: item.Id, Kind: item.Kind, LibraryId: item.LibraryExternalId,
+		RemovedAt: item.RemovedAt}
+	for _, entry := range item.Entries {
+		game := item.Games[entry.GameId]
+		message.Games = append(message.Games, result.LibraryEventGame{GameId: game.ExternalId,
+			Name: game.Name, Status: entry.Status, Platform: entry.Platform, Tags: entry.Tags,
+			WishlistRank: entry.WishlistRank})
+	}
+	return message
+}
+
+func (handler WebserviceHandler) ShowTrash(c *gin.Context) (int, result.Trash) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Trash{}
+	}
+	items, err, code := handler.TrashInteractor.ShowTrash(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Trash{}
+	}
+	message := result.Trash{UserId: c.Param("id")}
+	for _, item := range items {
+		message.Items = append(message.Items, trashItemResult(item))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) RestoreFromTrash(c *gin.Context) (int, result.TrashItem) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.TrashItem{}
+	}
+	itemId, err := strconv.ParseInt(c.Param("itemId"), 10, 64)
+	if err != nil {
+		c.Error(domain.NewError(domain.CodeNotFound, "Item '%s' is not in the trash", c.Param("itemId")))
+		return 404, result.TrashItem{}
+	}
+	item, err, code := handler.TrashInteractor.RestoreFromTrash(userId, itemId)
+	if err != nil {
+		c.Error(err)
+		return code, result.TrashItem{}
+	}
+	logf(c, "Restored %s #%d into library #%d", item.Kind, item.Id, item.LibraryId)
+	return 200, trashItemResult(item)
+}
@@ -0,0 +1,68 @@
+package interfaces
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+func undoActionResult(action usecases.UndoAction) result.UndoAction {
+	message := result.UndoAction{Id: action.Id, Kind: action.Kind, LibraryId: action.LibraryExternalId,
+		CreatedAt: action.CreatedAt, UndoneAt: action.UndoneAt}
+	for _, entry := range action.Before {
+		message.Before = append(message.Before, undoEntryResult(action, entry))
+	}
+	for _, entry := range action.After {
+		message.After = append(message.After, undoEntryResult(action, entry))
+	}
+	return message
+}
+
+// Games removed from the catalog since keep an empty id
+func undoEntryResult(action usecases.UndoAction, entry usecases.LibraryEntry) result.LibraryEventGame {
+	game := action.Games[entry.GameId]
+	return result.LibraryEventGame{GameId: game.ExternalId, Name: game.Name, Status: entry.Status,
+		Platform: entry.Platform, Tags: entry.Tags, WishlistRank: entry.WishlistRank}
+}
+
+func (handler WebserviceHandler) ShowUndoStack(c *gin.Context) (int, result.UndoStack) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.UndoStack{}
+	}
+	actions, err, code := handler.UndoInteractor.ShowUndoStack(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.UndoStack{}
+	}
+	message := result.UndoStack{UserId: c.Param("id")}
+	for _, action := range actions {
+		message.Actions = append(message.Actions, undoActionResult(action))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) Undo(c *gin.Context) (int, result.UndoAction) {
+	return handler.undoOrRedo(c, handler.UndoInteractor.Undo)
+}
+
+func (handler WebserviceHandler) Redo(c *gin.Context) (int, result.UndoAction) {
+	return handler.undoOrRedo(c, handler.UndoInteractor.Redo)
+}
+
+func (handler WebserviceHandler) undoOrRedo(c *gin.Context,
+	restore func(userId int) (usecases.UndoAction, error, int)) (int, result.UndoAction) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.UndoAction{}
+	}
+	action, err, code := restore(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.UndoAction{}
+	}
+	return 200, undoActionResult(action)
+}
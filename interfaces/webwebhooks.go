@@ -0,0 +1,128 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/models/request"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
+)
+
+// Deliveries are signed with the webhook's secret, as sha256=<hex HMAC of the body>
+const webhookSignatureHeader = "X-Webhook-Signature"
+
+func webhookResult(userId string, webhook usecases.Webhook) result.Webhook {
+	return result.Webhook{Id: webhook.Id, UserId: userId, Name: webhook.Name, Action: webhook.Action,
+		LibraryId: webhook.LibraryExternalId, LastDeliveredAt: webhook.LastDeliveredAt,
+		CreatedAt: webhook.CreatedAt}
+}
+
+func (handler WebserviceHandler) AddWebhook(c *gin.Context) (int, result.Webhook) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Webhook{}
+	}
+	webhook := request.Webhook{}
+	err = c.BindJSON(&webhook)
+	if err != nil {
+		return 400, result.Webhook{}
+	}
+	libraryId := 0
+	if webhook.LibraryId != "" {
+		libraryId, err, code = handler.profile(c).FindLibraryId(webhook.LibraryId)
+		if err != nil {
+			c.Error(err)
+			return code, result.Webhook{}
+		}
+	}
+	added, key, err, code := handler.webhooks(c).AddWebhook(userId, webhook.Name, webhook.Action,
+		libraryId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Webhook{}
+	}
+	logf(c, "Added webhook #%d", added.Id)
+	message := webhookResult(c.Param("id"), added)
+	message.Key, message.Secret = key, added.Secret
+	return code, message
+}
+
+func (handler WebserviceHandler) ShowWebhooks(c *gin.Context) (int, result.Webhooks) {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code, result.Webhooks{}
+	}
+	webhooks, err, code := handler.webhooks(c).ShowWebhooks(userId)
+	if err != nil {
+		c.Error(err)
+		return code, result.Webhooks{}
+	}
+	message := result.Webhooks{UserId: c.Param("id")}
+	for _, webhook := range webhooks {
+		message.Webhooks = append(message.Webhooks, webhookResult(c.Param("id"), webhook))
+	}
+	return 200, message
+}
+
+func (handler WebserviceHandler) RemoveWebhook(c *gin.Context) int {
+	userId, err, code := handler.profile(c).FindUserId(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	webhookId, err := strconv.Atoi(c.Param("webhookId"))
+	if err != nil {
+		c.Error(domain.NewError(domain.CodeNotFound, "Webhook '%s' does not exist", c.Param("webhookId")))
+		return 404
+	}
+	err, code = handler.webhooks(c).RemoveWebhook(userId, webhookId)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Removed webhook #%d", webhookId)
+	return 204
+}
+
+// Deliveries from external systems, the key in the path names the webhook
+// and the signature is checked against the raw body before it is read
+func (handler WebserviceHandler) ReceiveWebhook(c *gin.Context) int {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.Error(err)
+		return 400
+	}
+	webhook, err, code := handler.webhooks(c).VerifyDelivery(c.Param("key"), body,
+		c.GetHeader(webhookSignatureHeader))
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	payload := request.WebhookDelivery{}
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		c.Error(domain.NewError(domain.CodeInvalid, "Body must be a JSON object"))
+		return 400
+	}
+	delivery := usecases.WebhookDelivery{Message: payload.Message, Status: payload.Status}
+	if payload.GameId != "" {
+		delivery.GameId, err, code = handler.profile(c).FindGameId(payload.GameId)
+		if err != nil {
+			c.Error(err)
+			return code
+		}
+	}
+	err, code = handler.webhooks(c).Deliver(webhook, delivery)
+	if err != nil {
+		c.Error(err)
+		return code
+	}
+	logf(c, "Received delivery for webhook #%d", webhook.Id)
+	return 204
+}
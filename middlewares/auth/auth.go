@@ -2,55 +2,97 @@ package auth
 
 import (
 	"fmt"
+	"strings"
+
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
-	"strconv"
 )
 
-func CheckToken() gin.HandlerFunc {
+// Tokens are verified with secret, the key they were signed with
+func CheckToken(secret []byte) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString := c.Request.Header.Get("X-Auth-Key")
-		if tokenString == "" {
-			err := fmt.Errorf("Token cannot be empty")
-			c.AbortWithError(400, err)
-			return
-		}
-
-		token, err := jwt.Parse(tokenString,
-			func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-				}
-				return []byte("5230"), nil
-			})
+		claims, err := parseToken(c, secret)
 		if err != nil {
 			c.AbortWithError(400, err)
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !(ok && token.Valid) {
-			if !ok {
-				err = fmt.Errorf("Error parsing claims")
-			}
-			if !token.Valid {
-				err = fmt.Errorf("Token invalid")
-			}
+		subject, ok := claims["sub"].(string)
+		if !ok || subject != c.Param("id") {
+			err := fmt.Errorf("Id in token and query mismatch")
 			c.AbortWithError(400, err)
 			return
 		}
+		if id, ok := claims["id"].(float64); ok {
+			c.Set("userId", int(id))
+		}
+		c.Next()
+	}
+}
 
-		id, err := strconv.Atoi(c.Param("id"))
+// Lets through tokens carrying role, the id of the caller is stored as
+// "userId" for the handlers to check against the stored role
+func CheckRole(role string, secret []byte) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := parseToken(c, secret)
 		if err != nil {
 			c.AbortWithError(400, err)
 			return
 		}
 
-		if int(claims["id"].(float64)) != id {
-			err := fmt.Errorf("Id in token and query mismatch")
-			c.AbortWithError(400, err)
+		id, ok := claims["id"].(float64)
+		if !ok {
+			c.AbortWithError(400, fmt.Errorf("Token has no id"))
 			return
 		}
+		if claims["role"] != role {
+			c.AbortWithError(403, fmt.Errorf("Role '%s' required", role))
+			return
+		}
+		c.Set("userId", int(id))
 		c.Next()
 	}
 }
+
+func parseToken(c *gin.Context, secret []byte) (jwt.MapClaims, error) {
+	tokenString := c.Request.Header.Get("X-Auth-Key")
+	if tokenString == "" {
+		tokenString = protocolToken(c)
+	}
+	if tokenString == "" {
+		return nil, fmt.Errorf("Token cannot be empty")
+	}
+
+	token, err := jwt.Parse(tokenString,
+		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+			}
+			return secret, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !(ok && token.Valid) {
+		if !ok {
+			err = fmt.Errorf("Error parsing claims")
+		}
+		if !token.Valid {
+			err = fmt.Errorf("Token invalid")
+		}
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Browsers cannot set headers on WebSocket upgrades, they offer the
+// subprotocols "gametracker" and the token instead
+func protocolToken(c *gin.Context) string {
+	protocols := strings.Split(c.Request.Header.Get("Sec-WebSocket-Protocol"), ",")
+	if len(protocols) != 2 || strings.TrimSpace(protocols[0]) != "gametracker" {
+		return ""
+	}
+	return strings.TrimSpace(protocols[1])
+}
@@ -0,0 +1,57 @@
+package bodycheck
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Routes of the HTML pages, browsers post their forms url-encoded
+var forms = map[string]bool{
+	"/web/login":  true,
+	"/web/logout": true,
+}
+
+// Routes taking file uploads as multipart/form-data, with the largest body
+// each accepts
+var uploads = map[string]int64{
+	"/users/:id/journal":                      6 << 20,
+	"/users/:id/libraries/:libId/import":      2 << 20,
+	"/users/:id/libraries/:libId/mods/import": 2 << 20,
+	"/users/:id/libraries/:libId/photos":      51 << 20,
+}
+
+// Rejects request bodies that are not JSON or larger than maxBytes
+func CheckBody(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength == 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+		allowed := mediaType == "application/json" ||
+			(forms[c.FullPath()] && mediaType == "application/x-www-form-urlencoded")
+		limit := maxBytes
+		if upload, ok := uploads[c.FullPath()]; ok && mediaType == "multipart/form-data" {
+			allowed, limit = true, upload
+		}
+		if err != nil || !allowed {
+			abort(c, 415, fmt.Errorf("Content-Type must be application/json"))
+			return
+		}
+		if c.Request.ContentLength > limit {
+			abort(c, 413, fmt.Errorf("Request body cannot be larger than %d bytes", limit))
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+func abort(c *gin.Context, code int, err error) {
+	c.Set("code", code)
+	c.AbortWithError(code, err)
+}
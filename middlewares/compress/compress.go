@@ -0,0 +1,85 @@
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	writer   io.WriteCloser
+}
+
+// The encoder is only created once there is a body, so 204 and 304
+// responses go out untouched
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.writer == nil {
+		w.Header().Del("Content-Length")
+		w.Header().Set("Content-Encoding", w.encoding)
+		if w.encoding == "br" {
+			w.writer = brotli.NewWriterLevel(w.ResponseWriter, brotli.DefaultCompression)
+		} else {
+			w.writer = gzip.NewWriter(w.ResponseWriter)
+		}
+	}
+	return w.writer.Write(b)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Compresses responses with brotli or gzip, whichever the client prefers
+func Compress() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := preferredEncoding(c.Request.Header.Get("Accept-Encoding"))
+		c.Header("Vary", "Accept-Encoding")
+		if encoding == "" || c.Request.Method == "HEAD" {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{ResponseWriter: c.Writer, encoding: encoding}
+		c.Writer = writer
+		c.Next()
+		if writer.writer != nil {
+			writer.writer.Close()
+		}
+	}
+}
+
+func preferredEncoding(acceptEncoding string) string {
+	best, bestQuality := "", 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		encoding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if encoding != "br" && encoding != "gzip" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				q, err := strconv.ParseFloat(param[2:], 64)
+				if err == nil {
+					quality = q
+				}
+			}
+		}
+		// q=0 refuses the encoding
+		if quality <= 0 {
+			continue
+		}
+		// Ties go to brotli, it compresses JSON better
+		if quality > bestQuality || (quality == bestQuality && encoding == "br") {
+			best, bestQuality = encoding, quality
+		}
+	}
+	return best
+}
@@ -1,18 +1,137 @@
 package errres
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
 	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	res "game-tracker/models/responses"
 )
 
-func ErrorHandle() gin.HandlerFunc {
+var statusOfCode = map[domain.ErrorCode]int{
+	domain.CodeInvalid:      400,
+	domain.CodeUnauthorized: 401,
+	domain.CodeForbidden:    403,
+	domain.CodeNotFound:     404,
+	domain.CodeConflict:     409,
+	domain.CodeRateLimited:  429,
+	domain.CodeUnavailable:  503,
+}
+
+var codeOfStatus = map[int]string{
+	400: string(domain.CodeInvalid),
+	401: string(domain.CodeUnauthorized),
+	403: string(domain.CodeForbidden),
+	404: string(domain.CodeNotFound),
+	409: string(domain.CodeConflict),
+	413: "payload_too_large",
+	415: "unsupported_media_type",
+	422: "unprocessable_entity",
+	429: "rate_limited",
+}
+
+type Translator interface {
+	Translate(locale, format string, args ...interface{}) string
+}
+
+// Renders the last error of the request in the standard error envelope.
+// Domain errors are translated to the request's "locale", a nil translator
+// leaves them in English.
+func ErrorHandle(translator Translator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
-		if c.Errors.Last() != nil {
-			code := c.MustGet("code").(int)
-			c.JSON(code, gin.H{
-				"errors": c.Errors,
-			})
-			c.Abort()
+		last := c.Errors.Last()
+		if last == nil {
+			return
+		}
+
+		// The request log's id, so an error report leads straight to its lines
+		traceId := c.GetString("requestId")
+		if traceId == "" {
+			traceId = newTraceId()
+		}
+		code := statusOf(c, last.Err)
+		translate := fmt.Sprintf
+		if locale := c.GetString("locale"); translator != nil && locale != "" {
+			translate = func(format string, args ...interface{}) string {
+				return translator.Translate(locale, format, args...)
+			}
+		}
+		body := bodyOf(code, last.Err, translate)
+		body.TraceId = traceId
+		if code >= 500 {
+			fmt.Printf("[%s] %s %s: %v\n", traceId, c.Request.Method, c.Request.URL.Path, c.Errors)
+		}
+
+		c.Header("X-Trace-Id", traceId)
+		c.JSON(code, res.Error{Error: body})
+		c.Abort()
+	}
+}
+
+func statusOf(c *gin.Context, err error) int {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		if status, ok := statusOfCode[domainErr.Code]; ok {
+			return status
+		}
+	}
+	if code, ok := c.Get("code"); ok {
+		if status, ok := code.(int); ok && status >= 400 {
+			return status
+		}
+	}
+	if c.Writer.Status() >= 400 {
+		return c.Writer.Status()
+	}
+	return 500
+}
+
+// Server errors are hidden unless they are domain errors such as maintenance.
+// Errors that are not domain errors keep their English message.
+func bodyOf(status int, err error, translate func(format string, args ...interface{}) string) res.ErrorBody {
+	var domainErr *domain.Error
+	if status >= 500 && !errors.As(err, &domainErr) {
+		return res.ErrorBody{Code: "internal", Message: translate("Internal server error")}
+	}
+
+	body := res.ErrorBody{Code: codeOfStatus[status], Message: err.Error()}
+	if body.Code == "" {
+		body.Code = string(domain.CodeInvalid)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &domainErr):
+		body.Code = string(domainErr.Code)
+		if domainErr.Format != "" {
+			body.Message = translate(domainErr.Format, domainErr.Args...)
 		}
+		for _, field := range domainErr.Fields {
+			message := field.Message
+			if field.Format != "" {
+				message = translate(field.Format, field.Args...)
+			}
+			body.Fields = append(body.Fields, res.FieldError{Field: field.Field, Message: message})
+		}
+	case errors.As(err, &typeErr):
+		body.Message = translate("Request body has a field of the wrong type")
+		body.Fields = []res.FieldError{{Field: typeErr.Field,
+			Message: translate("Must be of type %s", typeErr.Type)}}
+	}
+	return body
+}
+
+func newTraceId() string {
+	bytes := make([]byte, 8)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "unknown"
 	}
+	return hex.EncodeToString(bytes)
 }
@@ -0,0 +1,93 @@
+package headers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/models/postgres"
+)
+
+const (
+	allowedMethods = "GET, POST, PUT, DELETE"
+	allowedHeaders = "Content-Type, X-Auth-Key, Idempotency-Key, X-Request-Id, X-Share-Password"
+	exposedHeaders = "X-Trace-Id, X-Request-Id, Idempotent-Replayed"
+)
+
+const defaultContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+func Cors(config postgres.Cors) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.Request.Header.Get("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		preflight := c.Request.Method == "OPTIONS" &&
+			c.Request.Header.Get("Access-Control-Request-Method") != ""
+
+		c.Header("Vary", "Origin")
+		listed, allowed := originAllowed(config.AllowedOrigins, origin)
+		if !allowed {
+			if preflight {
+				c.Set("code", 403)
+				c.AbortWithError(403, fmt.Errorf("Origin '%s' is not allowed", origin))
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		// Credentials are never shared with origins only matched by "*"
+		if config.AllowCredentials && listed {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			c.Header("Access-Control-Expose-Headers", exposedHeaders)
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Methods", allowedMethods)
+		c.Header("Access-Control-Allow-Headers", allowedHeaders)
+		if config.MaxAge > 0 {
+			c.Header("Access-Control-Max-Age", strconv.Itoa(config.MaxAge))
+		}
+		c.AbortWithStatus(204)
+	}
+}
+
+// Reports whether origin is listed explicitly, and whether it is allowed at all
+func originAllowed(allowedOrigins []string, origin string) (bool, bool) {
+	wildcard := false
+	for _, allowed := range allowedOrigins {
+		if strings.EqualFold(allowed, origin) {
+			return true, true
+		}
+		if allowed == "*" {
+			wildcard = true
+		}
+	}
+	return false, wildcard
+}
+
+func Security(config postgres.Security) gin.HandlerFunc {
+	contentSecurityPolicy := config.ContentSecurityPolicy
+	if contentSecurityPolicy == "" {
+		contentSecurityPolicy = defaultContentSecurityPolicy
+	}
+	return func(c *gin.Context) {
+		c.Header("Content-Security-Policy", contentSecurityPolicy)
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("X-Frame-Options", "DENY")
+		c.Header("Referrer-Policy", "no-referrer")
+		if config.HstsMaxAge > 0 {
+			c.Header("Strict-Transport-Security",
+				fmt.Sprintf("max-age=%d; includeSubDomains", config.HstsMaxAge))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,146 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/interfaces"
+)
+
+const maxKeyLength = 255
+
+type Store interface {
+	Reserve(scope, key, fingerprint string) (bool, error)
+	Find(scope, key string) (interfaces.IdempotentResponse, bool, error)
+	Complete(scope, key string, status int, contentType string, body []byte) error
+	Release(scope, key string) error
+}
+
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Replays the stored response when a POST is retried with the same
+// Idempotency-Key, keys are scoped to the signed in user and the route
+func Replay(store Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.Request.Header.Get("Idempotency-Key")
+		if c.Request.Method != "POST" || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			abort(c, 400, fmt.Errorf("Idempotency-Key cannot be longer than %d characters",
+				maxKeyLength))
+			return
+		}
+
+		body, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			abort(c, 400, err)
+			return
+		}
+		c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+
+		scope := scopeOf(c)
+		fingerprint := fingerprintOf(c.Request.Method, c.Request.URL.Path, body)
+		reserved, err := store.Reserve(scope, key, fingerprint)
+		if err != nil {
+			abort(c, 500, err)
+			return
+		}
+		if !reserved {
+			replay(c, store, scope, key, fingerprint)
+			return
+		}
+
+		// A retry runs a handler that panicked again, recovery answers this one
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err := store.Release(scope, key)
+				if err != nil {
+					fmt.Printf("Cannot release idempotency key '%s': %v\n", key, err)
+				}
+				panic(recovered)
+			}
+		}()
+
+		recorder := bodyRecorder{ResponseWriter: c.Writer, body: new(bytes.Buffer)}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if c.Errors.Last() != nil || status < 200 || status >= 300 {
+			err = store.Release(scope, key)
+		} else {
+			err = store.Complete(scope, key, status, recorder.Header().Get("Content-Type"),
+				recorder.body.Bytes())
+		}
+		if err != nil {
+			fmt.Printf("Cannot record idempotency key '%s': %v\n", key, err)
+		}
+	}
+}
+
+func replay(c *gin.Context, store Store, scope, key, fingerprint string) {
+	response, found, err := store.Find(scope, key)
+	if err != nil {
+		abort(c, 500, err)
+		return
+	}
+	if !found || response.Status == 0 {
+		abort(c, 409, fmt.Errorf("A request with this Idempotency-Key is still in progress"))
+		return
+	}
+	if response.Fingerprint != fingerprint {
+		abort(c, 422, fmt.Errorf("Idempotency-Key was already used for a different request"))
+		return
+	}
+	contentType := response.ContentType
+	if contentType == "" {
+		// Stored before content types were
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(response.Status, contentType, response.Body)
+	c.Abort()
+}
+
+// Keys of signed in users are theirs on each route. Anonymous clients have
+// no subject, so their keys are scoped to the address on each route. A key
+// reused there with another body is refused by the fingerprint.
+func scopeOf(c *gin.Context) string {
+	route := c.Request.Method + " " + c.Request.URL.Path
+	if userId := c.GetInt("userId"); userId != 0 {
+		return fmt.Sprintf("user:%d %s", userId, route)
+	}
+	return fmt.Sprintf("ip:%s %s", c.ClientIP(), route)
+}
+
+func fingerprintOf(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func abort(c *gin.Context, code int, err error) {
+	c.Set("code", code)
+	c.AbortWithError(code, err)
+}
@@ -0,0 +1,22 @@
+package locale
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type Detector interface {
+	Locale(c *gin.Context) string
+}
+
+// Stores the locale of the request as "locale" for handlers and the error
+// handler. Used again once the caller is known, their settings win over
+// Accept-Language.
+func Detect(detector Detector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, detected := c.Get("locale"); !detected {
+			c.Writer.Header().Add("Vary", "Accept-Language")
+		}
+		c.Set("locale", detector.Locale(c))
+		c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package maintenance
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/domain"
+	"game-tracker/interfaces"
+)
+
+type Mode interface {
+	Status() interfaces.MaintenanceStatus
+}
+
+// Routes that keep working during maintenance, tokens live outside the
+// database and the switch itself has to stay reachable
+var exempt = map[string]bool{
+	"/login":             true,
+	"/refresh":           true,
+	"/admin/maintenance": true,
+	"/web/login":         true,
+	"/web/logout":        true,
+}
+
+// Puts the API into read-only mode while maintenance is on, writes are
+// answered with 503 and a Retry-After header
+func ReadOnly(mode Mode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case "GET", "HEAD", "OPTIONS":
+			c.Next()
+			return
+		}
+		status := mode.Status()
+		if !status.Enabled || exempt[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		if status.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(status.RetryAfter))
+		}
+		message := "The API is read-only during maintenance"
+		if status.Reason != "" {
+			message += ": " + status.Reason
+		}
+		c.AbortWithError(503, domain.NewError(domain.CodeUnavailable, "%s", message))
+	}
+}
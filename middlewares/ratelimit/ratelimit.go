@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/interfaces"
+)
+
+// Allows each client ip at most requests per window, counters live in a
+// cache whose ttl is the window. A failing counter lets requests through.
+func Limit(counter interfaces.Cache, requests int, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if requests <= 0 {
+			c.Next()
+			return
+		}
+		count, err := counter.Increment(c.ClientIP())
+		if err != nil {
+			fmt.Printf("Cannot count requests of %s: %v\n", c.ClientIP(), err)
+			c.Next()
+			return
+		}
+
+		remaining := int64(requests) - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Header("X-RateLimit-Limit", strconv.Itoa(requests))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		if count > int64(requests) {
+			c.Header("Retry-After", strconv.Itoa(int(window.Seconds())))
+			c.AbortWithError(429, fmt.Errorf("Too many requests, limit is %d per %s",
+				requests, window))
+			return
+		}
+		c.Next()
+	}
+}
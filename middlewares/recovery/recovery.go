@@ -0,0 +1,47 @@
+package recovery
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/interfaces"
+)
+
+// Turns a panicking handler into a 500, ErrorHandle renders it as long as
+// it runs before this middleware. reporter may be nil.
+func Recover(reporter interfaces.ErrorReporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			err, ok := recovered.(error)
+			if !ok {
+				err = fmt.Errorf("%v", recovered)
+			}
+			stack := debug.Stack()
+
+			requestId := c.GetString("requestId")
+			interfaces.WriteLog(map[string]interface{}{
+				"requestId": requestId,
+				"message":   "Recovered from panic",
+				"panic":     err.Error(),
+				"stack":     string(stack),
+			})
+			if reporter != nil {
+				reporter.Report(err, stack, map[string]string{
+					"requestId": requestId,
+					"method":    c.Request.Method,
+					"route":     c.FullPath(),
+				})
+			}
+
+			c.Set("code", 500)
+			c.AbortWithError(500, fmt.Errorf("panic: %v", err))
+		}()
+		c.Next()
+	}
+}
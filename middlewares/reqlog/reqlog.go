@@ -0,0 +1,58 @@
+package reqlog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/interfaces"
+)
+
+const header = "X-Request-Id"
+
+// Ids passed in by a proxy are kept when they are safe to echo and log
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Assigns the request id handlers log with as "requestId" and writes one
+// line per request once it is answered
+func Log() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		id := c.Request.Header.Get(header)
+		if !idPattern.MatchString(id) {
+			id = newId()
+		}
+		c.Set("requestId", id)
+		c.Header(header, id)
+
+		c.Next()
+
+		entry := map[string]interface{}{
+			"requestId":  id,
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"status":     c.Writer.Status(),
+			"durationMs": float64(time.Since(start).Microseconds()) / 1000,
+			"ip":         c.ClientIP(),
+		}
+		if userId := c.GetInt("userId"); userId != 0 {
+			entry["userId"] = userId
+		}
+		if len(c.Errors) > 0 {
+			entry["error"] = c.Errors.Last().Error()
+		}
+		interfaces.WriteLog(entry)
+	}
+}
+
+func newId() string {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,27 @@
+package suspension
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type Checker interface {
+	CheckWritable(c *gin.Context) (error, int)
+}
+
+// Rejects requests that would change the data of a suspended or banned
+// account, reads are let through so users can still see and export it
+func BlockWrites(checker Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case "GET", "HEAD", "OPTIONS":
+			c.Next()
+			return
+		}
+		err, code := checker.CheckWritable(c)
+		if err != nil {
+			c.AbortWithError(code, err)
+			return
+		}
+		c.Next()
+	}
+}
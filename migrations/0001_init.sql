@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS players (
+	id SERIAL PRIMARY KEY,
+	player_name TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS users (
+	id SERIAL PRIMARY KEY,
+	user_name TEXT NOT NULL,
+	player_id INTEGER NOT NULL,
+	personal_info TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS loginInfo (
+	id SERIAL PRIMARY KEY,
+	username TEXT NOT NULL,
+	password TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS libraries (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS games (
+	id SERIAL PRIMARY KEY,
+	name TEXT NOT NULL,
+	producer TEXT NOT NULL,
+	value NUMERIC NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS gamesInLib (
+	id SERIAL PRIMARY KEY,
+	game_id INTEGER NOT NULL,
+	library_id INTEGER NOT NULL
+);
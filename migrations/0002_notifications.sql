@@ -0,0 +1,10 @@
+CREATE TABLE notifications (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	message TEXT NOT NULL,
+	read BOOLEAN NOT NULL DEFAULT false,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id, read);
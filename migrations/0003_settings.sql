@@ -0,0 +1,10 @@
+CREATE TABLE settings (
+	user_id INTEGER PRIMARY KEY,
+	display_currency TEXT NOT NULL DEFAULT 'USD',
+	timezone TEXT NOT NULL DEFAULT 'UTC',
+	notify_libraries BOOLEAN NOT NULL DEFAULT true,
+	notify_games BOOLEAN NOT NULL DEFAULT true,
+	default_library_id INTEGER,
+	profile_public BOOLEAN NOT NULL DEFAULT true,
+	libraries_public BOOLEAN NOT NULL DEFAULT false
+);
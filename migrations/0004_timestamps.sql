@@ -0,0 +1,29 @@
+ALTER TABLE players
+	ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE users
+	ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE loginInfo
+	ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE libraries
+	ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE games
+	ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE gamesInLib
+	ADD COLUMN added_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE notifications
+	ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE settings
+	ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
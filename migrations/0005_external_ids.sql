@@ -0,0 +1,10 @@
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+ALTER TABLE users ADD COLUMN external_id UUID NOT NULL DEFAULT gen_random_uuid();
+CREATE UNIQUE INDEX users_external_id_idx ON users (external_id);
+
+ALTER TABLE libraries ADD COLUMN external_id UUID NOT NULL DEFAULT gen_random_uuid();
+CREATE UNIQUE INDEX libraries_external_id_idx ON libraries (external_id);
+
+ALTER TABLE games ADD COLUMN external_id UUID NOT NULL DEFAULT gen_random_uuid();
+CREATE UNIQUE INDEX games_external_id_idx ON games (external_id);
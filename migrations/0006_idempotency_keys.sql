@@ -0,0 +1,9 @@
+CREATE TABLE idempotency_keys (
+	scope TEXT NOT NULL,
+	key TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	status INTEGER NOT NULL DEFAULT 0,
+	body BYTEA,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (scope, key)
+);
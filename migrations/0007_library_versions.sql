@@ -0,0 +1 @@
+ALTER TABLE libraries ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
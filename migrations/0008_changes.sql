@@ -0,0 +1,11 @@
+CREATE TABLE changes (
+	id BIGSERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	entity TEXT NOT NULL,
+	entity_id UUID NOT NULL,
+	parent_id UUID,
+	action TEXT NOT NULL,
+	changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX changes_user_id_idx ON changes (user_id, id);
@@ -0,0 +1,5 @@
+ALTER TABLE gamesInLib
+	ADD COLUMN status TEXT NOT NULL DEFAULT 'owned',
+	ADD COLUMN platform TEXT NOT NULL DEFAULT '',
+	ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}',
+	ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
@@ -0,0 +1,11 @@
+-- Point users at the oldest player of each name before dropping duplicates
+UPDATE users SET player_id = keep.id
+FROM players dup
+JOIN (SELECT player_name, min(id) AS id FROM players GROUP BY player_name) keep
+	ON keep.player_name = dup.player_name
+WHERE users.player_id = dup.id AND dup.id <> keep.id;
+
+DELETE FROM players dup USING players keep
+WHERE dup.player_name = keep.player_name AND dup.id > keep.id;
+
+CREATE UNIQUE INDEX players_player_name_idx ON players (player_name);
@@ -0,0 +1,3 @@
+CREATE UNIQUE INDEX users_user_name_idx ON users (user_name);
+CREATE INDEX users_lower_user_name_idx ON users (lower(user_name));
+CREATE INDEX players_lower_player_name_idx ON players (lower(player_name));
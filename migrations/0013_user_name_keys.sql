@@ -0,0 +1,37 @@
+-- The application writes keys with Unicode case folding, lower() over NFC
+-- is the closest SQL can get for existing rows
+ALTER TABLE users ADD COLUMN user_name_key TEXT;
+UPDATE users SET user_name_key = lower(normalize(user_name, NFC));
+
+-- The oldest user keeps a name, later users sharing its key get their id
+-- appended to both the username and the login. A name taken already, by a
+-- user or a login, gets a counter appended until it is free.
+DO $$
+DECLARE
+	duplicate RECORD;
+	candidate TEXT;
+	suffix INTEGER;
+BEGIN
+	FOR duplicate IN SELECT u.id, u.user_name FROM users u
+		WHERE EXISTS (SELECT 1 FROM users o WHERE o.user_name_key = u.user_name_key AND o.id < u.id)
+		ORDER BY u.id
+	LOOP
+		candidate := duplicate.user_name || '-' || duplicate.id;
+		suffix := 1;
+		WHILE EXISTS (SELECT 1 FROM users WHERE user_name = candidate
+				OR user_name_key = lower(normalize(candidate, NFC)))
+			OR EXISTS (SELECT 1 FROM loginInfo WHERE username = candidate)
+		LOOP
+			suffix := suffix + 1;
+			candidate := duplicate.user_name || '-' || duplicate.id || '-' || suffix;
+		END LOOP;
+		UPDATE loginInfo SET username = candidate WHERE username = duplicate.user_name;
+		UPDATE users SET user_name = candidate, user_name_key = lower(normalize(candidate, NFC))
+		WHERE id = duplicate.id;
+	END LOOP;
+END
+$$;
+
+ALTER TABLE users ALTER COLUMN user_name_key SET NOT NULL;
+DROP INDEX users_lower_user_name_idx;
+CREATE UNIQUE INDEX users_user_name_key_idx ON users (user_name_key);
@@ -0,0 +1,14 @@
+-- Admins are promoted by hand, e.g. UPDATE users SET role = 'admin' WHERE user_name = '...'
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
+
+CREATE TABLE audit_log (
+	id BIGSERIAL PRIMARY KEY,
+	actor_id INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	target_id INTEGER NOT NULL,
+	detail TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX audit_log_target_id_idx ON audit_log (target_id, id);
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN status_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN suspended_until TIMESTAMPTZ;
@@ -0,0 +1,8 @@
+CREATE TABLE feature_flags (
+	name TEXT PRIMARY KEY,
+	description TEXT NOT NULL DEFAULT '',
+	enabled BOOLEAN NOT NULL DEFAULT false,
+	percentage INTEGER NOT NULL DEFAULT 0 CHECK (percentage BETWEEN 0 AND 100),
+	user_ids INTEGER[] NOT NULL DEFAULT '{}',
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,10 @@
+CREATE TABLE activities (
+	id BIGSERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	game_id INTEGER NOT NULL,
+	game_name TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX activities_user_id_idx ON activities (user_id, id DESC);
@@ -0,0 +1,24 @@
+CREATE TABLE play_sessions (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	starts_at TIMESTAMPTZ NOT NULL,
+	minutes INTEGER NOT NULL CHECK (minutes > 0),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX play_sessions_user_id_idx ON play_sessions (user_id, starts_at);
+
+CREATE TABLE releases (
+	user_id INTEGER NOT NULL,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	release_date DATE NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (user_id, game_id)
+);
+
+CREATE TABLE calendar_tokens (
+	user_id INTEGER PRIMARY KEY,
+	token_hash TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,4 @@
+ALTER TABLE releases ADD COLUMN source TEXT NOT NULL DEFAULT 'manual';
+ALTER TABLE releases ADD COLUMN announced_at TIMESTAMPTZ;
+
+CREATE INDEX releases_unannounced_idx ON releases (release_date) WHERE announced_at IS NULL;
@@ -0,0 +1,17 @@
+CREATE TABLE franchises (
+	id SERIAL PRIMARY KEY,
+	external_id UUID NOT NULL DEFAULT gen_random_uuid(),
+	name TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX franchises_external_id_idx ON franchises (external_id);
+
+CREATE TABLE franchise_games (
+	franchise_id INTEGER NOT NULL REFERENCES franchises (id) ON DELETE CASCADE,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	PRIMARY KEY (franchise_id, game_id)
+);
+
+CREATE INDEX franchise_games_game_id_idx ON franchise_games (game_id);
@@ -0,0 +1,12 @@
+ALTER TABLE games ADD COLUMN min_age INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE child_accounts (
+	child_id INTEGER PRIMARY KEY,
+	parent_id INTEGER NOT NULL,
+	daily_minutes INTEGER NOT NULL DEFAULT 0,
+	rating_cap INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX child_accounts_parent_id_idx ON child_accounts (parent_id);
@@ -0,0 +1,3 @@
+ALTER TABLE games ADD COLUMN rating TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE settings ADD COLUMN rating_limit INTEGER NOT NULL DEFAULT 0;
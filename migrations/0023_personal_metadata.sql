@@ -0,0 +1,9 @@
+CREATE TABLE personal_metadata (
+	user_id INTEGER NOT NULL,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	difficulty SMALLINT NOT NULL DEFAULT 0 CHECK (difficulty BETWEEN 0 AND 5),
+	replayability SMALLINT NOT NULL DEFAULT 0 CHECK (replayability BETWEEN 0 AND 5),
+	moods TEXT[] NOT NULL DEFAULT '{}',
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (user_id, game_id)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE journal_entries (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	text TEXT NOT NULL DEFAULT '',
+	screenshot TEXT NOT NULL DEFAULT '',
+	screenshot_type TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX journal_entries_user_id_idx ON journal_entries (user_id, created_at);
@@ -0,0 +1 @@
+ALTER TABLE journal_entries ADD COLUMN text_html TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE journal_entries ADD COLUMN spoiler_free_html TEXT NOT NULL DEFAULT '';
+ALTER TABLE journal_entries ADD COLUMN has_spoilers BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE games ADD COLUMN contains_spoilers BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,7 @@
+CREATE TABLE steam_apps (
+	app_id INTEGER PRIMARY KEY,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE gamesInLib ADD COLUMN wishlist_rank INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+CREATE TABLE barcodes (
+	barcode TEXT PRIMARY KEY,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE physical_copies (
+	id SERIAL PRIMARY KEY,
+	library_id INTEGER NOT NULL,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	barcode TEXT NOT NULL,
+	platform TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX physical_copies_library_id_idx ON physical_copies (library_id, game_id);
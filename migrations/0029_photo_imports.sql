@@ -0,0 +1,27 @@
+CREATE TABLE photo_imports (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	library_id INTEGER NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	reason TEXT NOT NULL DEFAULT '',
+	photos TEXT[] NOT NULL DEFAULT '{}',
+	photo_types TEXT[] NOT NULL DEFAULT '{}',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX photo_imports_status_idx ON photo_imports (status, created_at);
+CREATE INDEX photo_imports_library_id_idx ON photo_imports (library_id);
+
+CREATE TABLE photo_proposals (
+	import_id INTEGER NOT NULL REFERENCES photo_imports (id) ON DELETE CASCADE,
+	id INTEGER NOT NULL,
+	photo INTEGER NOT NULL,
+	title TEXT NOT NULL,
+	confidence DOUBLE PRECISION NOT NULL DEFAULT 0,
+	matched BOOLEAN NOT NULL DEFAULT false,
+	reason TEXT NOT NULL DEFAULT '',
+	accepted BOOLEAN NOT NULL DEFAULT false,
+	game_id INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (import_id, id)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE share_links (
+	id SERIAL PRIMARY KEY,
+	library_id INTEGER NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL DEFAULT '',
+	expires_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX share_links_library_id_idx ON share_links (library_id);
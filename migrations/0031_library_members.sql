@@ -0,0 +1,10 @@
+-- The creator of a library is not listed, libraries.user_id always owns it
+CREATE TABLE library_members (
+	library_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	role TEXT NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (library_id, user_id)
+);
+
+CREATE INDEX library_members_user_id_idx ON library_members (user_id);
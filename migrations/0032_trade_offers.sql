@@ -0,0 +1,16 @@
+CREATE TABLE trade_offers (
+	id SERIAL PRIMARY KEY,
+	proposer_id INTEGER NOT NULL,
+	proposer_library_id INTEGER NOT NULL,
+	recipient_id INTEGER NOT NULL,
+	recipient_library_id INTEGER NOT NULL,
+	offered_copy_ids INTEGER[] NOT NULL DEFAULT '{}',
+	requested_copy_ids INTEGER[] NOT NULL DEFAULT '{}',
+	status TEXT NOT NULL DEFAULT 'pending',
+	counter_of INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX trade_offers_proposer_id_idx ON trade_offers (proposer_id);
+CREATE INDEX trade_offers_recipient_id_idx ON trade_offers (recipient_id);
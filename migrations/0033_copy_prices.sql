@@ -0,0 +1,8 @@
+-- Paid prices and estimates keep their own currencies, nothing is converted
+ALTER TABLE physical_copies ADD COLUMN paid_price NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE physical_copies ADD COLUMN paid_currency TEXT NOT NULL DEFAULT '';
+ALTER TABLE physical_copies ADD COLUMN value NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE physical_copies ADD COLUMN value_currency TEXT NOT NULL DEFAULT '';
+ALTER TABLE physical_copies ADD COLUMN valued_at TIMESTAMPTZ;
+
+CREATE INDEX physical_copies_valued_at_idx ON physical_copies (valued_at NULLS FIRST);
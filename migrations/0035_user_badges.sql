@@ -0,0 +1,6 @@
+CREATE TABLE user_badges (
+	user_id INTEGER NOT NULL,
+	badge_id TEXT NOT NULL,
+	earned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (user_id, badge_id)
+);
@@ -0,0 +1,16 @@
+CREATE TABLE goals (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	target INTEGER NOT NULL,
+	period TEXT NOT NULL CHECK (period IN ('year', 'quarter')),
+	year INTEGER NOT NULL,
+	quarter INTEGER NOT NULL DEFAULT 0,
+	starts_at TIMESTAMPTZ NOT NULL,
+	ends_at TIMESTAMPTZ NOT NULL,
+	reminded_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX goals_user_id_idx ON goals (user_id);
+CREATE INDEX goals_ends_at_idx ON goals (ends_at);
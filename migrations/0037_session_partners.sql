@@ -0,0 +1,11 @@
+ALTER TABLE play_sessions ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE session_partners (
+	session_id INTEGER NOT NULL REFERENCES play_sessions (id) ON DELETE CASCADE,
+	user_id INTEGER NOT NULL,
+	PRIMARY KEY (session_id, user_id)
+);
+
+CREATE INDEX session_partners_user_id_idx ON session_partners (user_id);
+
+ALTER TABLE activities ADD COLUMN partners TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,16 @@
+CREATE TABLE hardware (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	kind TEXT NOT NULL CHECK (kind IN ('console', 'handheld', 'peripheral')),
+	name TEXT NOT NULL,
+	model TEXT NOT NULL DEFAULT '',
+	serial TEXT NOT NULL DEFAULT '',
+	purchased_on DATE,
+	warranty_until DATE,
+	reminded_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX hardware_user_id_idx ON hardware (user_id);
+CREATE INDEX hardware_warranty_until_idx ON hardware (warranty_until) WHERE reminded_at IS NULL;
@@ -0,0 +1,24 @@
+CREATE TABLE subscriptions (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	service TEXT NOT NULL,
+	monthly_cost NUMERIC NOT NULL DEFAULT 0,
+	currency TEXT NOT NULL,
+	billing_months INTEGER NOT NULL DEFAULT 1 CHECK (billing_months IN (1, 3, 6, 12)),
+	started_on DATE NOT NULL,
+	renews_on DATE NOT NULL,
+	ends_on DATE,
+	reminded_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX subscriptions_user_id_idx ON subscriptions (user_id);
+CREATE INDEX subscriptions_renews_on_idx ON subscriptions (renews_on);
+
+-- Games played through a subscription, for its cost per hour
+CREATE TABLE subscription_games (
+	subscription_id INTEGER NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	PRIMARY KEY (subscription_id, game_id)
+);
@@ -0,0 +1,22 @@
+-- Games included in subscription services, replaced whenever the catalog
+-- job fetches a service again
+CREATE TABLE catalog_games (
+	service TEXT NOT NULL,
+	name TEXT NOT NULL,
+	name_key TEXT NOT NULL,
+	leaves_on DATE,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (service, name_key)
+);
+
+CREATE INDEX catalog_games_name_key_idx ON catalog_games (name_key);
+
+-- Users told that a game leaves a catalog, a new leaving date is told again
+CREATE TABLE catalog_alerts (
+	user_id INTEGER NOT NULL,
+	service TEXT NOT NULL,
+	name_key TEXT NOT NULL,
+	leaves_on DATE NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (user_id, service, name_key, leaves_on)
+);
@@ -0,0 +1,11 @@
+-- DLCs, expansions and season passes of a base game, an add-on belongs to
+-- one base game at a time
+CREATE TABLE game_addons (
+	game_id INTEGER PRIMARY KEY REFERENCES games (id) ON DELETE CASCADE,
+	parent_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	kind TEXT NOT NULL CHECK (kind IN ('dlc', 'expansion', 'season_pass')),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CHECK (game_id <> parent_id)
+);
+
+CREATE INDEX game_addons_parent_id_idx ON game_addons (parent_id);
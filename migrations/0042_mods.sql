@@ -0,0 +1,13 @@
+CREATE TABLE mods (
+	id SERIAL PRIMARY KEY,
+	library_id INTEGER NOT NULL REFERENCES libraries (id) ON DELETE CASCADE,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	source_url TEXT NOT NULL DEFAULT '',
+	version TEXT NOT NULL DEFAULT '',
+	enabled BOOLEAN NOT NULL DEFAULT true,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX mods_library_id_game_id_idx ON mods (library_id, game_id);
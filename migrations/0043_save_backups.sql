@@ -0,0 +1,15 @@
+CREATE TABLE save_backups (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	library_id INTEGER NOT NULL REFERENCES libraries (id) ON DELETE CASCADE,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	location TEXT NOT NULL,
+	size_bytes BIGINT NOT NULL DEFAULT 0 CHECK (size_bytes >= 0),
+	checksum TEXT NOT NULL DEFAULT '',
+	backed_up_at TIMESTAMPTZ NOT NULL,
+	reminded_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX save_backups_library_id_game_id_idx ON save_backups (library_id, game_id, backed_up_at DESC);
+CREATE INDEX save_backups_backed_up_at_idx ON save_backups (backed_up_at) WHERE reminded_at IS NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE speedruns (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	category TEXT NOT NULL,
+	milliseconds BIGINT NOT NULL CHECK (milliseconds > 0),
+	run_at TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX speedruns_user_id_game_id_idx ON speedruns (user_id, game_id, category, milliseconds);
+CREATE INDEX speedruns_game_id_idx ON speedruns (game_id);
@@ -0,0 +1,12 @@
+CREATE TABLE matches (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	result TEXT NOT NULL CHECK (result IN ('win', 'loss', 'draw')),
+	score TEXT NOT NULL DEFAULT '',
+	opponents JSONB NOT NULL DEFAULT '[]',
+	played_at TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX matches_user_id_played_at_idx ON matches (user_id, played_at DESC);
@@ -0,0 +1,26 @@
+CREATE TABLE agents (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	last_seen_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX agents_user_id_idx ON agents (user_id);
+
+CREATE TABLE executable_games (
+	user_id INTEGER NOT NULL,
+	executable TEXT NOT NULL,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	PRIMARY KEY (user_id, executable)
+);
+
+CREATE TABLE agent_processes (
+	user_id INTEGER NOT NULL,
+	executable TEXT NOT NULL,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	agent_id INTEGER REFERENCES agents (id) ON DELETE SET NULL,
+	started_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (user_id, executable)
+);
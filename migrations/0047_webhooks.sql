@@ -0,0 +1,13 @@
+CREATE TABLE webhooks (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	action TEXT NOT NULL,
+	library_id INTEGER REFERENCES libraries (id) ON DELETE CASCADE,
+	key_hash TEXT NOT NULL UNIQUE,
+	secret TEXT NOT NULL,
+	last_delivered_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX webhooks_user_id_idx ON webhooks (user_id);
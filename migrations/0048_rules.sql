@@ -0,0 +1,28 @@
+CREATE TABLE rules (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	event TEXT NOT NULL,
+	condition TEXT NOT NULL DEFAULT '',
+	actions TEXT NOT NULL,
+	enabled BOOLEAN NOT NULL DEFAULT true,
+	dry_run BOOLEAN NOT NULL DEFAULT false,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX rules_user_id_event_idx ON rules (user_id, event) WHERE enabled;
+
+CREATE TABLE rule_runs (
+	id SERIAL PRIMARY KEY,
+	rule_id INTEGER NOT NULL REFERENCES rules (id) ON DELETE CASCADE,
+	user_id INTEGER NOT NULL,
+	event TEXT NOT NULL,
+	entity_id INTEGER NOT NULL DEFAULT 0,
+	actions JSONB NOT NULL DEFAULT '[]',
+	dry_run BOOLEAN NOT NULL,
+	error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX rule_runs_rule_id_idx ON rule_runs (rule_id, id DESC);
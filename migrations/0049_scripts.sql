@@ -0,0 +1,14 @@
+-- user_id 0 runs the script on the events of every user
+CREATE TABLE scripts (
+	id SERIAL PRIMARY KEY,
+	name TEXT NOT NULL,
+	event TEXT NOT NULL,
+	source TEXT NOT NULL,
+	user_id INTEGER NOT NULL DEFAULT 0,
+	enabled BOOLEAN NOT NULL DEFAULT true,
+	created_by INTEGER NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX scripts_event_idx ON scripts (event) WHERE enabled;
@@ -0,0 +1,22 @@
+CREATE TABLE saved_searches (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	filter JSONB NOT NULL DEFAULT '{}',
+	share_token_hash TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX saved_searches_user_id_idx ON saved_searches (user_id);
+CREATE UNIQUE INDEX saved_searches_share_token_hash_idx ON saved_searches (share_token_hash);
+
+-- The search a user's game list of a library starts from
+CREATE TABLE default_searches (
+	user_id INTEGER NOT NULL,
+	library_id INTEGER NOT NULL,
+	search_id INTEGER NOT NULL REFERENCES saved_searches (id) ON DELETE CASCADE,
+	PRIMARY KEY (user_id, library_id)
+);
+
+CREATE INDEX default_searches_library_id_idx ON default_searches (library_id);
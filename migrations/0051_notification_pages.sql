@@ -0,0 +1,2 @@
+-- Pages of notifications continue after a (created_at, id) cursor
+CREATE INDEX notifications_user_id_created_at_idx ON notifications (user_id, created_at DESC, id DESC);
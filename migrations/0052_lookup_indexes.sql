@@ -0,0 +1,8 @@
+-- Foreign keys and hot lookups, checked by go run ./cmd/explain. Names and
+-- name keys of users and players are indexed since 0011 to 0013.
+CREATE INDEX gamesinlib_library_id_game_id_idx ON gamesInLib (library_id, game_id);
+CREATE INDEX gamesinlib_game_id_idx ON gamesInLib (game_id);
+CREATE INDEX libraries_user_id_idx ON libraries (user_id);
+CREATE INDEX users_player_id_idx ON users (player_id);
+CREATE INDEX games_name_idx ON games (name);
+CREATE INDEX logininfo_username_idx ON loginInfo (username);
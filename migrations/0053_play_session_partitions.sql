@@ -0,0 +1,43 @@
+-- Sessions are split into monthly partitions on starts_at. The session job
+-- creates the partitions of coming months, sessions of months without one
+-- land in play_sessions_default until it does. Keys of a partitioned table
+-- have to hold starts_at, so partners lose their foreign key and are removed
+-- with their sessions by the repository.
+ALTER TABLE session_partners DROP CONSTRAINT session_partners_session_id_fkey;
+
+ALTER TABLE play_sessions RENAME TO play_sessions_unpartitioned;
+ALTER TABLE play_sessions_unpartitioned RENAME CONSTRAINT play_sessions_pkey TO play_sessions_unpartitioned_pkey;
+ALTER INDEX play_sessions_user_id_idx RENAME TO play_sessions_unpartitioned_user_id_idx;
+
+CREATE TABLE play_sessions (
+	id INTEGER NOT NULL DEFAULT nextval('play_sessions_id_seq'),
+	user_id INTEGER NOT NULL,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	starts_at TIMESTAMPTZ NOT NULL,
+	minutes INTEGER NOT NULL CHECK (minutes > 0),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	notes TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (id, starts_at)
+) PARTITION BY RANGE (starts_at);
+
+CREATE TABLE play_sessions_default PARTITION OF play_sessions DEFAULT;
+
+INSERT INTO play_sessions (id, user_id, game_id, starts_at, minutes, created_at, notes)
+	SELECT id, user_id, game_id, starts_at, minutes, created_at, notes FROM play_sessions_unpartitioned;
+
+ALTER SEQUENCE play_sessions_id_seq OWNED BY play_sessions.id;
+DROP TABLE play_sessions_unpartitioned;
+
+CREATE INDEX play_sessions_user_id_idx ON play_sessions (user_id, starts_at);
+
+-- Sessions of archived months, summed per user, game and month (UTC)
+CREATE TABLE play_session_months (
+	user_id INTEGER NOT NULL,
+	game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	month DATE NOT NULL,
+	sessions INTEGER NOT NULL,
+	minutes INTEGER NOT NULL,
+	PRIMARY KEY (user_id, game_id, month)
+);
+
+CREATE INDEX play_session_months_game_id_idx ON play_session_months (game_id);
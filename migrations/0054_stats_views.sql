@@ -0,0 +1,33 @@
+-- Totals the stats pages read, refreshed by the stats job instead of summed
+-- per request. The unique indexes let them be refreshed concurrently.
+CREATE MATERIALIZED VIEW library_stats AS
+	SELECT libraries.user_id, gamesInLib.status, gamesInLib.platform,
+		count(*) AS games, coalesce(sum(games.value), 0) AS value
+	FROM gamesInLib
+	JOIN libraries ON libraries.id = gamesInLib.library_id
+	JOIN games ON games.id = gamesInLib.game_id
+	GROUP BY libraries.user_id, gamesInLib.status, gamesInLib.platform;
+
+CREATE UNIQUE INDEX library_stats_user_id_idx ON library_stats (user_id, status, platform);
+
+-- Sessions count for the platform of the user's entry of the game, planned
+-- sessions do not count until they started
+CREATE MATERIALIZED VIEW playtime_stats AS
+	SELECT played.user_id, coalesce(entries.platform, '') AS platform,
+		sum(played.minutes) AS minutes
+	FROM (SELECT user_id, game_id, minutes FROM play_sessions WHERE starts_at < now()
+		UNION ALL
+		SELECT user_id, game_id, minutes FROM play_session_months) played
+	LEFT JOIN LATERAL (SELECT min(gamesInLib.platform) AS platform FROM gamesInLib
+		JOIN libraries ON libraries.id = gamesInLib.library_id
+		WHERE libraries.user_id = played.user_id AND gamesInLib.game_id = played.game_id) entries ON true
+	GROUP BY 1, 2;
+
+CREATE UNIQUE INDEX playtime_stats_user_id_idx ON playtime_stats (user_id, platform);
+
+CREATE TABLE stats_refreshes (
+	view_name TEXT PRIMARY KEY,
+	refreshed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO stats_refreshes (view_name) VALUES ('library_stats'), ('playtime_stats');
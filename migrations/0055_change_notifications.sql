@@ -0,0 +1,26 @@
+-- Tells every instance listening on gametracker_changes what was written, so
+-- caches and WebSocket watchers follow writes of other instances. Postgres
+-- delivers the notifications once the transaction commits.
+CREATE FUNCTION notify_change() RETURNS trigger AS $$
+BEGIN
+	PERFORM pg_notify('gametracker_changes', json_build_object('kind', 'change',
+		'id', NEW.id, 'userId', NEW.user_id, 'entity', NEW.entity,
+		'entityId', NEW.entity_id, 'parentId', NEW.parent_id, 'action', NEW.action,
+		'changedAt', NEW.changed_at)::text);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER changes_notify AFTER INSERT ON changes
+	FOR EACH ROW EXECUTE FUNCTION notify_change();
+
+CREATE FUNCTION notify_game() RETURNS trigger AS $$
+BEGIN
+	PERFORM pg_notify('gametracker_changes', json_build_object('kind', 'game',
+		'gameId', OLD.id, 'externalId', OLD.external_id)::text);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER games_notify AFTER UPDATE OR DELETE ON games
+	FOR EACH ROW EXECUTE FUNCTION notify_game();
@@ -0,0 +1,8 @@
+-- When each background job last started and on which instance, so the
+-- instances of a deployment take turns instead of all running it
+CREATE TABLE job_runs (
+	job_name TEXT PRIMARY KEY,
+	instance TEXT NOT NULL,
+	started_at TIMESTAMPTZ NOT NULL,
+	finished_at TIMESTAMPTZ
+);
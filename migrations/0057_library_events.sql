@@ -0,0 +1,21 @@
+-- Append-only history of libraries when they are event sourced, gamesInLib
+-- is then a projection the repositories keep in step with it
+CREATE TABLE library_events (
+	id BIGSERIAL PRIMARY KEY,
+	library_id INTEGER NOT NULL,
+	sequence BIGINT NOT NULL,
+	kind TEXT NOT NULL,
+	data JSONB NOT NULL DEFAULT '{}',
+	recorded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	UNIQUE (library_id, sequence)
+);
+
+-- The state after every few events, replays fold the events after the
+-- latest one
+CREATE TABLE library_snapshots (
+	library_id INTEGER NOT NULL,
+	sequence BIGINT NOT NULL,
+	state JSONB NOT NULL,
+	taken_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (library_id, sequence)
+);
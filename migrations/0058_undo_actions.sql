@@ -0,0 +1,14 @@
+-- Recent actions users can undo, with the library entries they touched
+-- before and after. Rows older than the undo window are dropped whenever the
+-- user records a new action.
+CREATE TABLE undo_actions (
+	id BIGSERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	library_id INTEGER NOT NULL,
+	data JSONB NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	undone_at TIMESTAMPTZ
+);
+
+CREATE INDEX undo_actions_user_id_idx ON undo_actions (user_id, id);
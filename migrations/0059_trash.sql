@@ -0,0 +1,13 @@
+-- Games and libraries users removed, with the entries they had, until they
+-- are restored or the trash job purges them
+CREATE TABLE trash (
+	id BIGSERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	library_id INTEGER NOT NULL,
+	entries JSONB NOT NULL,
+	removed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX trash_user_id_idx ON trash (user_id, id);
+CREATE INDEX trash_removed_at_idx ON trash (removed_at);
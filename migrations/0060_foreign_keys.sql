@@ -0,0 +1,29 @@
+-- Foreign keys between users, players, libraries and their games. Rows
+-- breaking them are repaired first, the way cmd/integrity -repair does.
+WITH broken AS (
+	SELECT id, user_name FROM users
+	WHERE NOT EXISTS (SELECT 1 FROM players WHERE players.id = users.player_id)),
+named AS (
+	INSERT INTO players (player_name) SELECT DISTINCT user_name FROM broken
+	ON CONFLICT (player_name) DO UPDATE SET player_name = EXCLUDED.player_name
+	RETURNING id, player_name)
+UPDATE users SET player_id = named.id FROM broken, named
+WHERE users.id = broken.id AND named.player_name = broken.user_name;
+
+DELETE FROM libraries
+WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = libraries.user_id);
+DELETE FROM gamesInLib
+WHERE NOT EXISTS (SELECT 1 FROM libraries WHERE libraries.id = gamesInLib.library_id)
+	OR NOT EXISTS (SELECT 1 FROM games WHERE games.id = gamesInLib.game_id);
+
+-- Players are shared by users of the same player name and never removed
+ALTER TABLE users ADD CONSTRAINT users_player_id_fkey
+	FOREIGN KEY (player_id) REFERENCES players (id) ON DELETE RESTRICT;
+-- Users are removed after their libraries, one left over stops the removal
+ALTER TABLE libraries ADD CONSTRAINT libraries_user_id_fkey
+	FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT;
+ALTER TABLE gamesInLib ADD CONSTRAINT gamesinlib_library_id_fkey
+	FOREIGN KEY (library_id) REFERENCES libraries (id) ON DELETE CASCADE;
+-- Games of the catalog stay while a library holds them
+ALTER TABLE gamesInLib ADD CONSTRAINT gamesinlib_game_id_fkey
+	FOREIGN KEY (game_id) REFERENCES games (id) ON DELETE RESTRICT;
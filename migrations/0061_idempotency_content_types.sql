@@ -0,0 +1,5 @@
+-- Responses are replayed with the content type they were sent with. Keys
+-- were scoped by the user in the path, which is empty on POST /users, so
+-- the keys stored so far are dropped rather than kept under the old scopes.
+ALTER TABLE idempotency_keys ADD COLUMN content_type TEXT NOT NULL DEFAULT '';
+DELETE FROM idempotency_keys;
@@ -0,0 +1,5 @@
+-- Pages of journal entries and matches continue after a (time, id) cursor
+DROP INDEX journal_entries_user_id_idx;
+CREATE INDEX journal_entries_user_id_idx ON journal_entries (user_id, created_at, id);
+DROP INDEX matches_user_id_played_at_idx;
+CREATE INDEX matches_user_id_played_at_idx ON matches (user_id, played_at DESC, id DESC);
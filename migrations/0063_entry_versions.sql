@@ -0,0 +1 @@
+ALTER TABLE gamesInLib ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
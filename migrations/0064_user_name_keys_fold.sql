@@ -0,0 +1,5 @@
+-- 0013 filled user_name_key with lower() over NFC, which neither trims nor
+-- folds like domain.NameKey ("Straße" stays apart from "STRASSE"). The keys
+-- are recomputed in Go right after this file runs, see
+-- infrastructure.migrationSteps; the index is rebuilt once they are.
+DROP INDEX users_user_name_key_idx;
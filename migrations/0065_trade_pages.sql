@@ -0,0 +1,5 @@
+-- Pages of trade offers continue after a (time, id) cursor, newest first
+DROP INDEX trade_offers_proposer_id_idx;
+CREATE INDEX trade_offers_proposer_id_idx ON trade_offers (proposer_id, created_at DESC, id DESC);
+DROP INDEX trade_offers_recipient_id_idx;
+CREATE INDEX trade_offers_recipient_id_idx ON trade_offers (recipient_id, created_at DESC, id DESC);
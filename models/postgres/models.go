@@ -1,5 +1,263 @@
 package postgres
 
 type Configuration struct {
-	PostgresAdr string
+	Database         string //"postgres" (default), "mongo" or "memory", which keeps nothing past a restart
+	PostgresAdr      string
+	PostgresFailover []string //DSNs of standbys of the same database, tried in order
+	MongoAdr         string
+	MongoDatabase    string
+	Redis            Redis
+	Cors             Cors
+	Security         Security
+	Tokens           Tokens
+	Names            Names
+	Maintenance      Maintenance
+	Telemetry        Telemetry
+	Errors           Errors
+	Releases         Releases
+	Blobs            Blobs
+	Steam            Steam
+	Barcodes         Barcodes
+	Vision           Vision
+	Pricing          Pricing
+	Documents        Documents
+	Locales          Locales
+	Goals            Goals
+	Hardware         Hardware
+	Subscriptions    Subscriptions
+	Catalogs         Catalogs
+	Backups          Backups
+	PlaySessions     PlaySessions
+	Stats            Stats
+	Scripts          Scripts
+	Diagnostics      Diagnostics
+	Queries          Queries
+	ChangeStream     ChangeStream
+	Messaging        Messaging
+	Libraries        Libraries
+	Undo             Undo
+	Trash            Trash
+	Plugins          map[string]Plugin //Keyed by plugin name
+}
+
+type Cors struct {
+	AllowedOrigins   []string //"*" allows every origin
+	AllowCredentials bool
+	MaxAge           int //Seconds browsers may cache a preflight response
+}
+
+type Security struct {
+	ContentSecurityPolicy string
+	HstsMaxAge            int //0 disables Strict-Transport-Security
+}
+
+// Access tokens are signed with Secret, GAME_TRACKER_TOKEN_SECRET overrides
+// it. The API does not start without one.
+type Tokens struct {
+	Secret string
+}
+
+// Starts the API in maintenance mode, admins can toggle it at runtime
+type Maintenance struct {
+	Enabled    bool
+	RetryAfter int //Seconds
+	Reason     string
+}
+
+// Where recovered panics are reported, an empty SentryDsn only logs them
+type Errors struct {
+	SentryDsn   string
+	Environment string
+}
+
+// Anonymous usage stats, nothing is collected or sent unless Enabled is set
+type Telemetry struct {
+	Enabled  bool
+	Endpoint string
+	Interval int //Seconds between batches
+}
+
+// ProviderUrl holds a {name} placeholder, left empty release dates must be
+// entered by hand and games stay unrated unless rated when added
+type Releases struct {
+	ProviderUrl string
+	Interval    int //Seconds between checks
+}
+
+// Where public Steam data is read from, an empty ApiUrl turns wishlist
+// imports off
+type Steam struct {
+	ApiUrl   string
+	StoreUrl string
+}
+
+// ProviderUrl holds a {barcode} placeholder, left empty only barcodes
+// resolved before can be scanned
+type Barcodes struct {
+	ProviderUrl string
+}
+
+// ProviderUrl receives each photo of a photo import, left empty photo
+// imports are off
+type Vision struct {
+	ProviderUrl string
+	Interval    int //Seconds between runs of the import job
+}
+
+// ProviderUrl holds {name}, {platform} and {barcode} placeholders, left
+// empty physical copies are not valued
+type Pricing struct {
+	ProviderUrl string
+	Interval    int //Seconds between refreshes, estimates older than that are asked again
+}
+
+// Templates is the directory of the *.tmpl report templates
+type Documents struct {
+	Templates string
+}
+
+// Message catalogs are the *.json files of Dir named after their locale,
+// left empty every message is in English
+type Locales struct {
+	Dir string
+}
+
+// Reminds users behind on a goal, 0 turns reminders off
+type Goals struct {
+	Interval int //Seconds between checks
+}
+
+// Reminds owners of hardware before its warranty ends, 0 turns reminders off
+type Hardware struct {
+	Interval     int //Seconds between checks
+	ReminderDays int //How long before the end of a warranty the owner is reminded
+}
+
+// Renews subscriptions and reminds owners before they renew, 0 turns the job off
+type Subscriptions struct {
+	Interval     int //Seconds between checks
+	ReminderDays int //How long before a renewal the owner is reminded
+}
+
+// ProviderUrl holds a {service} placeholder, left empty the catalogs of
+// Services are never fetched
+type Catalogs struct {
+	ProviderUrl string
+	Services    []string
+	Interval    int //Seconds between fetches
+}
+
+// Reminds users of games whose saves were not backed up lately, 0 turns
+// reminders off
+type Backups struct {
+	Interval int //Seconds between checks
+	MaxAge   int //Days after the latest backup of a game its maker is reminded
+}
+
+// Readies monthly partitions of play sessions MonthsAhead months ahead and
+// archives months older than RetainMonths into monthly totals, 0 keeps
+// sessions forever. Archived sessions only count in game stats.
+type PlaySessions struct {
+	Interval     int //Seconds between runs
+	MonthsAhead  int
+	RetainMonths int
+}
+
+// Refreshes the totals stats pages read, they lag behind edits by up to
+// Interval
+type Stats struct {
+	Interval int //Seconds between refreshes
+}
+
+// Serves pprof, expvar and runtime diagnostics to admin tokens on a port of
+// its own, an empty Address keeps it closed. Only loopback addresses are
+// accepted.
+type Diagnostics struct {
+	Address string //Such as "127.0.0.1:6060"
+}
+
+// Bounds how long statements may run on Postgres, so a runaway search fails
+// instead of holding its connection. Overrides are keyed by repository, such
+// as "DbStatsRepo", or by repository method, such as "DbGameRepo.FindByLib".
+type Queries struct {
+	Timeout   int            //Milliseconds, 0 leaves statements unbounded
+	Overrides map[string]int //Milliseconds, 0 leaves the key unbounded
+}
+
+// Follows the writes of every instance through Postgres LISTEN/NOTIFY, to
+// drop stale cached games and push changes to WebSocket watchers. Ignored
+// on MongoDB.
+type ChangeStream struct {
+	Enabled bool
+}
+
+// A message bus shared with worker processes and other services, an empty
+// Driver keeps work and events in process
+type Messaging struct {
+	Driver        string //"nats", "rabbitmq" or ""
+	Url           string
+	Prefix        string //Of every subject, such as "gametracker."
+	ForwardEvents bool   //Publishes domain events under Prefix + "events."
+}
+
+// How libraries are persisted on Postgres. "events" appends every write to
+// an event log the tables are projected from, keeping the full history of
+// each library, with a snapshot every SnapshotEvery events.
+type Libraries struct {
+	Persistence   string //"tables" (default) or "events"
+	SnapshotEvery int    //0 takes no snapshots
+}
+
+// How long removed and batch-updated games can be undone, in seconds. 0 turns
+// undo off.
+type Undo struct {
+	Window int
+}
+
+// Keeps removed games and libraries for RetainDays, 0 turns the trash off.
+// Purged every Interval seconds.
+type Trash struct {
+	Interval   int
+	RetainDays int
+}
+
+// Lets admins bind Starlark scripts to events, off unless Enabled is set
+type Scripts struct {
+	Enabled bool
+}
+
+// Uploaded files such as journal screenshots are kept under Dir
+type Blobs struct {
+	Dir string
+}
+
+type Names struct {
+	MinLength     int
+	MaxLength     int    //0 leaves names unbounded
+	ReservedWords string //Path of a word list, empty skips the check
+	Profanity     string //Path of a word list, empty skips the check
+}
+
+type Redis struct {
+	Address           string //Empty keeps caches and sessions in process memory
+	Password          string
+	Db                int
+	Cache             KeySpace
+	Sessions          KeySpace //Ttl is how long a refresh token stays valid
+	RateLimit         KeySpace //Ttl is the window requests are counted in
+	RequestsPerWindow int      //0 disables rate limiting
+	JobPrefix         string   //Of the keys claiming runs of background jobs
+}
+
+type KeySpace struct {
+	Prefix string
+	Ttl    int //Seconds
+}
+
+// Turns a registered plugin on, settings are checked against the plugin's
+// schema. The Releases, Steam and Pricing URLs stand in for the built-in
+// plugins that are not listed here.
+type Plugin struct {
+	Enabled  bool
+	Settings map[string]interface{}
 }
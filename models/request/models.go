@@ -1,23 +1,362 @@
 package request
 
+import (
+	"time"
+)
+
 type LoginInfo struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
 
+type Refresh struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
 type UserInfo struct {
-	Info string `json:"info" binding:"required"`
+	Info    string `json:"info" binding:"required"`
+	Version int64  `json:"version"` //Version the edit is based on, omit to overwrite
 }
 
 type Game struct {
 	Name     string  `json:"name" binding:"required"`
 	Producer string  `json:"producer" binding:"required"`
 	Value    float64 `json:"value" binding:"required"`
+	MinAge   int     `json:"minAge"` //Ignored when a rating is given
+	Rating   string  `json:"rating"`
+}
+
+type GameBatch struct {
+	Ids      []string         `json:"ids" binding:"required"`
+	Status   *string          `json:"status"`
+	Platform *string          `json:"platform"`
+	Tags     *[]string        `json:"tags"`
+	Versions map[string]int64 `json:"versions"` //Version each edit is based on by game id, omit to overwrite
+}
+
+type TagRename struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+type TagMerge struct {
+	From string `json:"from" binding:"required"`
+	Into string `json:"into" binding:"required"`
 }
 
 type User struct {
-	PlayerId   int    `json:"playerId" binding:"required"`
 	PlayerName string `json:"playerName" binding:"required"`
 	Name       string `json:"name" binding:"required"`
 	Password   string `json:"password" binding:"required"`
 }
+
+type UserName struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type StatusChange struct {
+	Reason string     `json:"reason" binding:"required"`
+	Until  *time.Time `json:"until"` //Suspensions only, omitted suspends indefinitely
+}
+
+type Impersonation struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type Maintenance struct {
+	Enabled    bool   `json:"enabled"`
+	RetryAfter int    `json:"retryAfter"`
+	Reason     string `json:"reason"`
+}
+
+type FeatureFlag struct {
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	Percentage  int      `json:"percentage"`
+	UserIds     []string `json:"userIds"`
+}
+
+type PlaySession struct {
+	GameId   string    `json:"gameId"`
+	StartsAt time.Time `json:"startsAt"`
+	Minutes  int       `json:"minutes"`
+	Notes    string    `json:"notes"`
+	Partners []string  `json:"partners"` //Ids of the users played with
+}
+
+// Replaces both, an empty list removes every partner
+type SessionDetails struct {
+	Notes    string   `json:"notes"`
+	Partners []string `json:"partners"`
+}
+
+type Release struct {
+	Date string `json:"date"` //YYYY-MM-DD, looked up when empty
+}
+
+type Franchise struct {
+	Name string `json:"name"`
+}
+
+type FranchiseGame struct {
+	Position int `json:"position"` //0 appends the game
+}
+
+// Credentials of the child account, proving the parent controls it
+type ChildLink struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ChildLimits struct {
+	DailyMinutes int `json:"dailyMinutes"` //0 for no limit
+	RatingCap    int `json:"ratingCap"`    //0 for no limit
+}
+
+// Scores run from 1 to 5, 0 clears them
+type PersonalMetadata struct {
+	Difficulty    int      `json:"difficulty"`
+	Replayability int      `json:"replayability"`
+	Moods         []string `json:"moods"`
+}
+
+// Entries with a screenshot are posted as multipart/form-data with the same
+// fields and a "screenshot" file
+type JournalEntry struct {
+	GameId string `json:"gameId" form:"gameId" binding:"required"`
+	Text   string `json:"text" form:"text"`
+}
+
+type Render struct {
+	Text string `json:"text"`
+}
+
+type SteamImport struct {
+	SteamId string `json:"steamId" binding:"required"`
+}
+
+type BarcodeScan struct {
+	Barcode string `json:"barcode" binding:"required"`
+}
+
+type CopyPrice struct {
+	PaidPrice *float64 `json:"paidPrice" binding:"required"`
+	Currency  string   `json:"currency"` //ISO 4217, the display currency when empty
+}
+
+type ProposalChoice struct {
+	Id    int    `json:"id" binding:"required"`
+	Title string `json:"title"` //Corrects the title read from the photo
+}
+
+type PhotoConfirmation struct {
+	Accept []ProposalChoice `json:"accept"` //Proposals left out are rejected
+}
+
+type ShareLink struct {
+	ExpiresAt string `json:"expiresAt"` //RFC 3339, empty never expires
+	Password  string `json:"password"`
+}
+
+// Adds the user to the library or changes the role they have
+type LibraryMember struct {
+	UserName string `json:"userName" binding:"required"`
+	Role     string `json:"role" binding:"required"` //owner, editor or viewer
+}
+
+// Copy ids of the proposer's library given for copies of the recipient's
+type TradeOffer struct {
+	LibraryId          string `json:"libraryId" binding:"required"`
+	RecipientId        string `json:"recipientId" binding:"required"`
+	RecipientLibraryId string `json:"recipientLibraryId" binding:"required"`
+	Offered            []int  `json:"offered"`
+	Requested          []int  `json:"requested"`
+}
+
+// Answers an offer the other way round, Offered are copies of the answering user
+type TradeCounter struct {
+	Offered   []int `json:"offered"`
+	Requested []int `json:"requested"`
+}
+
+// Year defaults to the current one, Quarter to the current quarter of it
+type Goal struct {
+	Kind    string `json:"kind" binding:"required"`
+	Target  int    `json:"target" binding:"required"`
+	Period  string `json:"period" binding:"required"`
+	Year    int    `json:"year"`
+	Quarter int    `json:"quarter"`
+}
+
+type GoalTarget struct {
+	Target int `json:"target" binding:"required"`
+}
+
+// Dates are YYYY-MM-DD and may be left out
+type Hardware struct {
+	Kind          string `json:"kind" binding:"required"`
+	Name          string `json:"name" binding:"required"`
+	Model         string `json:"model"`
+	Serial        string `json:"serial"`
+	PurchasedOn   string `json:"purchasedOn"`
+	WarrantyUntil string `json:"warrantyUntil"`
+}
+
+// Dates are YYYY-MM-DD, the start defaults to today and the currency to
+// the display currency
+type Subscription struct {
+	Service       string  `json:"service" binding:"required"`
+	MonthlyCost   float64 `json:"monthlyCost"`
+	Currency      string  `json:"currency"`
+	BillingMonths int     `json:"billingMonths"`
+	StartedOn     string  `json:"startedOn"`
+	EndsOn        string  `json:"endsOn"`
+}
+
+type Mod struct {
+	Name      string `json:"name" binding:"required"`
+	SourceUrl string `json:"sourceUrl"`
+	Version   string `json:"version"`
+	Enabled   bool   `json:"enabled"`
+}
+
+// Size is in bytes, the time is RFC 3339 and defaults to now
+type SaveBackup struct {
+	Location   string `json:"location" binding:"required"`
+	Size       int64  `json:"size"`
+	Checksum   string `json:"checksum"`
+	BackedUpAt string `json:"backedUpAt"`
+}
+
+// The category defaults to any%, the time is RFC 3339 and defaults to now
+type SpeedRun struct {
+	Category     string `json:"category"`
+	Milliseconds int64  `json:"milliseconds" binding:"required"`
+	RunAt        string `json:"runAt"`
+}
+
+// The game is named by its game id, the time is RFC 3339 and defaults to now
+type Match struct {
+	GameId    string   `json:"gameId" binding:"required"`
+	Result    string   `json:"result" binding:"required"`
+	Score     string   `json:"score"`
+	Opponents []string `json:"opponents"`
+	PlayedAt  string   `json:"playedAt"`
+}
+
+type MatchBatch struct {
+	Matches []Match `json:"matches" binding:"required"`
+}
+
+type Agent struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// The game of an executable is named by its game id
+type ExecutableMapping struct {
+	GameId string `json:"gameId" binding:"required"`
+}
+
+// Sent by a desktop agent, event is started or stopped and the time is
+// RFC 3339 and defaults to now
+type AgentEvent struct {
+	Executable string `json:"executable" binding:"required"`
+	Event      string `json:"event" binding:"required"`
+	At         string `json:"at"`
+}
+
+// Action is notify or game_status, the library is named by its library id
+// and only used by game_status
+type Webhook struct {
+	Name      string `json:"name" binding:"required"`
+	Action    string `json:"action" binding:"required"`
+	LibraryId string `json:"libraryId"`
+}
+
+// What external systems post to a webhook, notify reads the message and
+// game_status the game id and status
+type WebhookDelivery struct {
+	Message string `json:"message"`
+	GameId  string `json:"gameId"`
+	Status  string `json:"status"`
+}
+
+// Enabled defaults to true, a dry run rule only records what it would do
+type Rule struct {
+	Name      string `json:"name" binding:"required"`
+	Event     string `json:"event" binding:"required"`
+	Condition string `json:"condition"`
+	Actions   string `json:"actions" binding:"required"`
+	Enabled   *bool  `json:"enabled"`
+	DryRun    bool   `json:"dryRun"`
+}
+
+// A rule tried on an event made up of fields, nothing is stored or run
+type RuleTest struct {
+	Event     string            `json:"event" binding:"required"`
+	Condition string            `json:"condition"`
+	Actions   string            `json:"actions" binding:"required"`
+	Fields    map[string]string `json:"fields"`
+}
+
+// Without a userId the script handles the events of every user, enabled
+// defaults to true
+type Script struct {
+	Name    string `json:"name" binding:"required"`
+	Event   string `json:"event" binding:"required"`
+	Source  string `json:"source" binding:"required"`
+	UserId  string `json:"userId"`
+	Enabled *bool  `json:"enabled"`
+}
+
+// Zero fields do not filter, sort is a field of the games such as value,
+// prefixed with "-" for descending order
+type SearchFilter struct {
+	Rating   string `json:"rating"`
+	MaxAge   int    `json:"maxAge"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Platform string `json:"platform"`
+	Tag      string `json:"tag"`
+	Sort     string `json:"sort"`
+}
+
+type SavedSearch struct {
+	Name   string       `json:"name" binding:"required"`
+	Filter SearchFilter `json:"filter"`
+}
+
+// A searchId of 0 clears the default
+type DefaultSearch struct {
+	SearchId int `json:"searchId"`
+}
+
+type SearchImport struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// The parent is named by its game id, kind is dlc, expansion or season_pass
+type GameParent struct {
+	ParentId string `json:"parentId" binding:"required"`
+	Kind     string `json:"kind" binding:"required"`
+}
+
+type GameSpoilers struct {
+	ContainsSpoilers bool `json:"containsSpoilers"`
+}
+
+type NotificationIds struct {
+	Ids []int `json:"ids"`
+}
+
+type Settings struct {
+	DisplayCurrency  *string `json:"displayCurrency"`
+	Timezone         *string `json:"timezone"`
+	NotifyLibraries  *bool   `json:"notifyLibraries"`
+	NotifyGames      *bool   `json:"notifyGames"`
+	DefaultLibraryId *string `json:"defaultLibraryId"`
+	ProfilePublic    *bool   `json:"profilePublic"`
+	LibrariesPublic  *bool   `json:"librariesPublic"`
+	RatingLimit      *int    `json:"ratingLimit"`
+	Locale           *string `json:"locale"`
+}
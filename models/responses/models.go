@@ -1,7 +1,18 @@
 package responses
 
 import (
+	"bytes"
+	"encoding/xml"
 	"fmt"
+	"html/template"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"game-tracker/models/result"
 )
 
 type Links struct {
@@ -11,17 +22,32 @@ type Links struct {
 
 type Data struct {
 	Type          string `json:"type,omitempty"`
-	Id            int    `json:"id,omitempty"`
+	Id            string `json:"id,omitempty"`
 	Attributes    `json:"attributes,omitempty"`
 	Relationships `json:"type, omitempty"`
 }
 
 type Attributes struct {
-	TokenString string  `json:"tokenString,omitempty"`
-	Name        string  `json:"name,omitempty"`
-	Content     string  `json:"content,omitempty"`
-	Producer    string  `json:"producer,omitempty"`
-	Value       float64 `json:"value,omitempty"`
+	TokenString  string   `json:"tokenString,omitempty"`
+	RefreshToken string   `json:"refreshToken,omitempty"`
+	ExpiresIn    int      `json:"expiresIn,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	Content      string   `json:"content,omitempty"`
+	Producer     string   `json:"producer,omitempty"`
+	Value        float64  `json:"value,omitempty"`
+	MinAge       int      `json:"minAge,omitempty"`
+	Rating       string   `json:"rating,omitempty"`
+	Kind         string   `json:"kind,omitempty"`
+	Message      string   `json:"message,omitempty"`
+	Status       string   `json:"status,omitempty"`
+	CreatedAt    string   `json:"createdAt,omitempty"`
+	UpdatedAt    string   `json:"updatedAt,omitempty"`
+	Version      int64    `json:"version,omitempty"`
+	Platform     string   `json:"platform,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	WishlistRank int      `json:"wishlistRank,omitempty"`
+	PlayerId     int      `json:"playerId,omitempty"`
+	PlayerName   string   `json:"playerName,omitempty"`
 }
 
 type Relationships struct {
@@ -33,7 +59,7 @@ type Relationships struct {
 
 type DataLv2 struct {
 	Type       string `json:"type,omitempty"`
-	Id         int    `json:"id,omitempty"`
+	Id         string `json:"id,omitempty"`
 	Attributes `json:"attributes,omitempty"`
 }
 
@@ -65,136 +91,3957 @@ type Game struct {
 	Data  `json:"data, omitempty"`
 }
 
-type Info struct {
+type LibraryGames struct {
 	Links `json:"links,omitempty"`
-	Data  `json:"data, omitempty"`
+	Data  []Data   `json:"data"`
+	Meta  PageMeta `json:"meta"`
 }
 
-func ViewToken(tokenString string) Token {
-	return Token{
-		Data: Data{
-			Type: "token",
-			Attributes: Attributes{
-				TokenString: tokenString,
-			},
-		},
-	}
+type Meta struct {
+	PageMeta
+	Unread int `json:"unread"`
 }
 
-func ViewUser(id int, name string, libraries []Library) User {
-	return User{
-		Links: Links{
-			Self: fmt.Sprintf("http://localhost:8080/users/%d", id),
-		},
-		Data: Data{
-			Type: "users",
-			Id:   id,
-			Attributes: Attributes{
-				Name: name,
-			},
-			Relationships: Relationships{
-				Libraries: libraries,
-			},
-		},
-	}
+type Notifications struct {
+	Links `json:"links,omitempty"`
+	Data  []DataLv2 `json:"data"`
+	Meta  `json:"meta"`
 }
 
-func ViewInfo(info string, userId int) Info {
-	return Info{
-		Links: Links{
-			Self:    fmt.Sprintf("http://localhost:8080/users/%d/info", userId),
-			Related: fmt.Sprintf("http://localhost:8080/users/%d", userId),
-		},
-		Data: Data{
-			Type: "info",
-			Id:   userId,
-			Attributes: Attributes{
-				Content: info,
-			},
-			Relationships: Relationships{
-				Owner: Owner{
-					DataLv2: DataLv2{
-						Type: "users",
-						Id:   userId,
-					},
-				},
-			},
-		},
-	}
+type BatchItemData struct {
+	Type    string `json:"type"`
+	Id      string `json:"id"`
+	Status  int    `json:"status"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+	Version int64  `json:"version,omitempty"`
 }
 
-func ViewLibrary(userId, libId int, games []Game) Library {
-	return Library{
-		Links: Links{
-			Self:    fmt.Sprintf("http://localhost:8080/users/%d/libraries/%d", userId, libId),
-			Related: fmt.Sprintf("http://localhost:8080/users/%d", userId),
-		},
-		Data: Data{
-			Type: "libraries",
-			Id:   libId,
-			Relationships: Relationships{
-				Games: games,
-				Owner: Owner{
-					DataLv2: DataLv2{
-						Type: "users",
-						Id:   userId,
-					},
-				},
-			},
-		},
-	}
+type GameBatch struct {
+	Links `json:"links,omitempty"`
+	Data  []BatchItemData `json:"data"`
 }
 
-func ViewGame(userId, libId, gameId int, name, producer string, value float64) Game {
-	return Game{
-		Links: Links{
-			Self: fmt.Sprintf("http://localhost:8080/users/%d/libraries/%d/games/%d",
-				userId, libId, gameId),
-			Related: fmt.Sprintf("http://localhost:8080/users/%d/libraries/%d/",
-				userId, libId),
-		},
-		Data: Data{
-			Type: "games",
-			Id:   gameId,
-			Attributes: Attributes{
-				Name:     name,
-				Producer: producer,
-				Value:    value,
-			},
-			Relationships: Relationships{
-				Library: LibOfGame{
-					DataLv2: DataLv2{
-						Type: "libraries",
-						Id:   libId,
-					},
-				},
-			},
-		},
-	}
+type ImportReportData struct {
+	Type       string              `json:"type"`
+	Attributes result.ImportReport `json:"attributes"`
 }
 
-func ViewLibraries(libraryIds []int) []Library {
-	var libraries []Library
-	for _, id := range libraryIds {
-		libraries = append(libraries, Library{
-			Data: Data{
-				Type: "libraries",
-				Id:   id,
-			},
-		})
-	}
-	return libraries
+type ImportReport struct {
+	Links `json:"links,omitempty"`
+	Data  ImportReportData `json:"data"`
 }
 
-func ViewGames(gameIds []int) []Game {
-	var games []Game
-	for _, id := range gameIds {
-		games = append(games, Game{
-			Data: Data{
-				Type: "games",
-				Id:   id,
-			},
-		})
-	}
-	return games
+type SyncMeta struct {
+	Cursor  string `json:"cursor"`
+	HasMore bool   `json:"hasMore"`
+}
+
+type ChangeAttributes struct {
+	Entity    string `json:"entity"`
+	EntityId  string `json:"entityId"`
+	ParentId  string `json:"parentId,omitempty"`
+	Action    string `json:"action"`
+	ChangedAt string `json:"changedAt"`
+}
+
+type ChangeData struct {
+	Type       string           `json:"type"`
+	Attributes ChangeAttributes `json:"attributes"`
+}
+
+type Sync struct {
+	Links `json:"links,omitempty"`
+	Data  []ChangeData `json:"data"`
+	Meta  SyncMeta     `json:"meta"`
+}
+
+type LibraryEventAttributes struct {
+	Sequence   int64                     `json:"sequence"`
+	Kind       string                    `json:"kind"`
+	Games      []result.LibraryEventGame `json:"games,omitempty"`
+	Status     *string                   `json:"status,omitempty"`
+	Platform   *string                   `json:"platform,omitempty"`
+	Tags       *[]string                 `json:"tags,omitempty"`
+	From       []string                  `json:"from,omitempty"`
+	To         string                    `json:"to,omitempty"`
+	RecordedAt string                    `json:"recordedAt"`
+}
+
+type LibraryEventData struct {
+	Type       string                 `json:"type"`
+	Id         string                 `json:"id"`
+	Attributes LibraryEventAttributes `json:"attributes"`
+}
+
+type LibraryEvents struct {
+	Links `json:"links,omitempty"`
+	Data  []LibraryEventData `json:"data"`
+	Meta  PageMeta           `json:"meta"`
+}
+
+type LibraryAsOfAttributes struct {
+	At       string                    `json:"at"`
+	Detailed bool                      `json:"detailed"`
+	Games    []result.LibraryEventGame `json:"games"`
+}
+
+type LibraryAsOfData struct {
+	Type       string                `json:"type"`
+	Attributes LibraryAsOfAttributes `json:"attributes"`
+}
+
+type LibraryAsOf struct {
+	Links `json:"links,omitempty"`
+	Data  LibraryAsOfData `json:"data"`
+}
+
+type LibraryDiffAttributes struct {
+	From     string                      `json:"from"`
+	To       string                      `json:"to"`
+	Detailed bool                        `json:"detailed"`
+	Added    []result.LibraryEventGame   `json:"added"`
+	Removed  []result.LibraryEventGame   `json:"removed"`
+	Changed  []result.LibraryEntryChange `json:"changed"`
+}
+
+type LibraryDiffData struct {
+	Type       string                `json:"type"`
+	Attributes LibraryDiffAttributes `json:"attributes"`
+}
+
+type LibraryDiff struct {
+	Links `json:"links,omitempty"`
+	Data  LibraryDiffData `json:"data"`
+}
+
+type UndoActionAttributes struct {
+	Kind      string                    `json:"kind"`
+	LibraryId string                    `json:"libraryId"`
+	Before    []result.LibraryEventGame `json:"before"`
+	After     []result.LibraryEventGame `json:"after"`
+	CreatedAt string                    `json:"createdAt"`
+	UndoneAt  string                    `json:"undoneAt,omitempty"`
+}
+
+type UndoActionData struct {
+	Type       string               `json:"type"`
+	Id         string               `json:"id"`
+	Attributes UndoActionAttributes `json:"attributes"`
+}
+
+type UndoStack struct {
+	Links `json:"links,omitempty"`
+	Data  []UndoActionData `json:"data"`
+}
+
+type UndoAction struct {
+	Links `json:"links,omitempty"`
+	Data  UndoActionData `json:"data"`
+}
+
+type TrashItemAttributes struct {
+	Kind      string                    `json:"kind"`
+	LibraryId string                    `json:"libraryId,omitempty"`
+	Games     []result.LibraryEventGame `json:"games"`
+	RemovedAt string                    `json:"removedAt"`
+}
+
+type TrashItemData struct {
+	Type       string              `json:"type"`
+	Id         string              `json:"id"`
+	Attributes TrashItemAttributes `json:"attributes"`
+}
+
+type Trash struct {
+	Links `json:"links,omitempty"`
+	Data  []TrashItemData `json:"data"`
+}
+
+type TrashItem struct {
+	Links `json:"links,omitempty"`
+	Data  TrashItemData `json:"data"`
+}
+
+type LibraryReplayData struct {
+	Type       string               `json:"type"`
+	Attributes result.LibraryReplay `json:"attributes"`
+}
+
+type LibraryReplay struct {
+	Links `json:"links,omitempty"`
+	Data  LibraryReplayData `json:"data"`
+}
+
+type SessionAttributes struct {
+	GameId    string           `json:"gameId"`
+	GameName  string           `json:"gameName"`
+	StartsAt  string           `json:"startsAt"`
+	EndsAt    string           `json:"endsAt"`
+	Minutes   int              `json:"minutes"`
+	Notes     string           `json:"notes,omitempty"`
+	Partners  []SessionPartner `json:"partners"`
+	CreatedAt string           `json:"createdAt,omitempty"`
+}
+
+type SessionPartner struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type SessionData struct {
+	Type       string            `json:"type"`
+	Id         string            `json:"id"`
+	Attributes SessionAttributes `json:"attributes"`
+}
+
+type PlaySession struct {
+	Links `json:"links,omitempty"`
+	Data  SessionData `json:"data"`
+}
+
+type PlaySessions struct {
+	Links `json:"links,omitempty"`
+	Data  []SessionData `json:"data"`
+}
+
+type GameMinutes struct {
+	GameId   string  `json:"gameId"`
+	GameName string  `json:"gameName"`
+	Minutes  int     `json:"minutes"`
+	Hours    float64 `json:"hours"`
+}
+
+type CoopAttributes struct {
+	PartnerName  string        `json:"partnerName"`
+	Sessions     int           `json:"sessions"`
+	Minutes      int           `json:"minutes"`
+	Hours        float64       `json:"hours"`
+	LastPlayedAt string        `json:"lastPlayedAt,omitempty"`
+	Games        []GameMinutes `json:"games"`
+}
+
+type CoopData struct {
+	Type       string         `json:"type"`
+	Id         string         `json:"id"` //The partner's user id
+	Attributes CoopAttributes `json:"attributes"`
+}
+
+type CoopPartner struct {
+	Links `json:"links,omitempty"`
+	Data  CoopData `json:"data"`
+}
+
+type Coop struct {
+	Links `json:"links,omitempty"`
+	Data  []CoopData `json:"data"`
+}
+
+type HeatmapDay struct {
+	Date    string `json:"date"`
+	Minutes int    `json:"minutes"`
+	Level   int    `json:"level"` //0 to 4
+}
+
+type HeatmapAttributes struct {
+	From       string       `json:"from"` //A Sunday, a year before to
+	To         string       `json:"to"`   //Today in the user's timezone
+	Minutes    int          `json:"minutes"`
+	ActiveDays int          `json:"activeDays"`
+	MaxMinutes int          `json:"maxMinutes"`
+	Days       []HeatmapDay `json:"days"`
+}
+
+type HeatmapData struct {
+	Type       string            `json:"type"`
+	Attributes HeatmapAttributes `json:"attributes"`
+}
+
+type Heatmap struct {
+	Links `json:"links,omitempty"`
+	Data  HeatmapData `json:"data"`
+}
+
+type LibraryStatsAttributes struct {
+	Libraries       int            `json:"libraries"`
+	Games           int            `json:"games"` //Entries, a game in two libraries counts twice
+	Value           float64        `json:"value"`
+	Statuses        map[string]int `json:"statuses"`
+	Platforms       map[string]int `json:"platforms"`             //"" for entries without a platform
+	PlatformMinutes map[string]int `json:"platformMinutes"`       //Minutes played per platform
+	RefreshedAt     string         `json:"refreshedAt,omitempty"` //Totals lag behind edits until the next refresh
+}
+
+type LibraryStatsData struct {
+	Type       string                 `json:"type"`
+	Attributes LibraryStatsAttributes `json:"attributes"`
+}
+
+type LibraryStats struct {
+	Links `json:"links,omitempty"`
+	Data  LibraryStatsData `json:"data"`
+}
+
+type StreakAttributes struct {
+	Current       int    `json:"current"`
+	CurrentSince  string `json:"currentSince,omitempty"` //Date, empty without a running streak
+	Longest       int    `json:"longest"`
+	LongestFrom   string `json:"longestFrom,omitempty"`
+	LongestTo     string `json:"longestTo,omitempty"`
+	PlayedToday   bool   `json:"playedToday"`
+	Milestones    []int  `json:"milestones"`              //Streak lengths in days reached so far
+	NextMilestone int    `json:"nextMilestone,omitempty"` //Absent once all are reached
+}
+
+type StreakData struct {
+	Type       string           `json:"type"`
+	Attributes StreakAttributes `json:"attributes"`
+}
+
+type Streak struct {
+	Links `json:"links,omitempty"`
+	Data  StreakData `json:"data"`
+}
+
+type BadgeAttributes struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Goal        int    `json:"goal"`
+	Progress    int    `json:"progress"` //Equals the goal once earned
+	Earned      bool   `json:"earned"`
+	EarnedAt    string `json:"earnedAt,omitempty"`
+}
+
+type BadgeData struct {
+	Type       string          `json:"type"`
+	Id         string          `json:"id"`
+	Attributes BadgeAttributes `json:"attributes"`
+}
+
+type Badges struct {
+	Links `json:"links,omitempty"`
+	Data  []BadgeData `json:"data"`
+}
+
+type ReleaseAttributes struct {
+	GameName  string `json:"gameName"`
+	Date      string `json:"date"`
+	Source    string `json:"source"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+}
+
+type ReleaseData struct {
+	Type       string            `json:"type"`
+	Id         string            `json:"id"` //Id of the game
+	Attributes ReleaseAttributes `json:"attributes"`
+}
+
+type Release struct {
+	Links `json:"links,omitempty"`
+	Data  ReleaseData `json:"data"`
+}
+
+type Releases struct {
+	Links `json:"links,omitempty"`
+	Data  []ReleaseData `json:"data"`
+}
+
+type FranchiseGameAttributes struct {
+	GameId   string `json:"gameId"`
+	GameName string `json:"gameName"`
+	Position int    `json:"position"`
+}
+
+type FranchiseAttributes struct {
+	Name      string                    `json:"name"`
+	Games     []FranchiseGameAttributes `json:"games,omitempty"`
+	CreatedAt string                    `json:"createdAt,omitempty"`
+}
+
+type FranchiseData struct {
+	Type       string              `json:"type"`
+	Id         string              `json:"id"`
+	Attributes FranchiseAttributes `json:"attributes"`
+}
+
+type Franchise struct {
+	Links `json:"links,omitempty"`
+	Data  FranchiseData `json:"data"`
+}
+
+type Franchises struct {
+	Links `json:"links,omitempty"`
+	Data  []FranchiseData `json:"data"`
+}
+
+type FranchiseProgressAttributes struct {
+	Name      string `json:"name"`
+	Total     int    `json:"total"`
+	Owned     int    `json:"owned"`
+	Completed int    `json:"completed"`
+	Percent   int    `json:"percent"` //Of the entries owned, rounded down
+}
+
+type FranchiseProgressData struct {
+	Type       string                      `json:"type"`
+	Id         string                      `json:"id"` //Id of the franchise
+	Attributes FranchiseProgressAttributes `json:"attributes"`
+}
+
+type FranchiseProgress struct {
+	Links `json:"links,omitempty"`
+	Data  []FranchiseProgressData `json:"data"`
+}
+
+type ChildAccountAttributes struct {
+	Name         string `json:"name"`
+	DailyMinutes int    `json:"dailyMinutes"`
+	RatingCap    int    `json:"ratingCap"`
+	CreatedAt    string `json:"createdAt,omitempty"`
+	UpdatedAt    string `json:"updatedAt,omitempty"`
+}
+
+type ChildAccountData struct {
+	Type       string                 `json:"type"`
+	Id         string                 `json:"id"` //Id of the child's user
+	Attributes ChildAccountAttributes `json:"attributes"`
+}
+
+type ChildAccount struct {
+	Links `json:"links,omitempty"`
+	Data  ChildAccountData `json:"data"`
+}
+
+type ChildAccounts struct {
+	Links `json:"links,omitempty"`
+	Data  []ChildAccountData `json:"data"`
+}
+
+type DayPlaytimeAttributes struct {
+	Day       string `json:"day"`
+	Minutes   int    `json:"minutes"`
+	OverLimit bool   `json:"overLimit"`
+}
+
+type GamePlaytimeAttributes struct {
+	GameId   string `json:"gameId"`
+	GameName string `json:"gameName"`
+	Minutes  int    `json:"minutes"`
+}
+
+type ChildReportAttributes struct {
+	From         string                   `json:"from"`
+	To           string                   `json:"to"`
+	DailyMinutes int                      `json:"dailyMinutes"`
+	RatingCap    int                      `json:"ratingCap"`
+	TotalMinutes int                      `json:"totalMinutes"`
+	Days         []DayPlaytimeAttributes  `json:"days"`
+	Games        []GamePlaytimeAttributes `json:"games"`
+}
+
+type ChildReportData struct {
+	Type       string                `json:"type"`
+	Id         string                `json:"id"` //Id of the child's user
+	Attributes ChildReportAttributes `json:"attributes"`
+}
+
+type ChildReport struct {
+	Links `json:"links,omitempty"`
+	Data  ChildReportData `json:"data"`
+}
+
+type PersonalMetadataAttributes struct {
+	Difficulty    int      `json:"difficulty"`
+	Replayability int      `json:"replayability"`
+	Moods         []string `json:"moods"`
+	UpdatedAt     string   `json:"updatedAt,omitempty"`
+}
+
+type PersonalMetadataData struct {
+	Type       string                     `json:"type"`
+	Id         string                     `json:"id"` //Id of the game
+	Attributes PersonalMetadataAttributes `json:"attributes"`
+}
+
+type PersonalMetadata struct {
+	Links `json:"links,omitempty"`
+	Data  PersonalMetadataData `json:"data"`
+}
+
+type TonightGameAttributes struct {
+	Name          string   `json:"name"`
+	Producer      string   `json:"producer"`
+	Rating        string   `json:"rating,omitempty"`
+	Status        string   `json:"status"`
+	Platform      string   `json:"platform,omitempty"`
+	Difficulty    int      `json:"difficulty"`
+	Replayability int      `json:"replayability"`
+	Moods         []string `json:"moods"`
+}
+
+type TonightGameData struct {
+	Type       string                `json:"type"`
+	Id         string                `json:"id"` //Id of the game
+	Attributes TonightGameAttributes `json:"attributes"`
+}
+
+type Tonight struct {
+	Links `json:"links,omitempty"`
+	Data  []TonightGameData `json:"data"`
+}
+
+type SuggestionAttributes struct {
+	TonightGameAttributes
+	AddedAt           string  `json:"addedAt,omitempty"`
+	CompletionMinutes int     `json:"completionMinutes"` //0 when nobody completed the game
+	UserRating        float64 `json:"userRating"`        //Average replayability other users gave
+	Raters            int     `json:"raters"`
+	Score             float64 `json:"score"`
+	Seed              string  `json:"seed"` //Picks the same game again, a string as it overflows JS numbers
+}
+
+type SuggestionData struct {
+	Type       string               `json:"type"`
+	Id         string               `json:"id"` //Id of the game
+	Attributes SuggestionAttributes `json:"attributes"`
+}
+
+type Suggestion struct {
+	Links `json:"links,omitempty"`
+	Data  SuggestionData `json:"data"`
+}
+
+type JournalEntryAttributes struct {
+	GameId         string `json:"gameId"`
+	GameName       string `json:"gameName"`
+	Text           string `json:"text"`                 //Markdown
+	Html           string `json:"html"`                 //Sanitized, safe to show as it is
+	Screenshot     string `json:"screenshot,omitempty"` //Url of the image
+	HasSpoilers    bool   `json:"hasSpoilers"`
+	SpoilersHidden bool   `json:"spoilersHidden"` //Left out, ?spoilers=show brings them back
+	CreatedAt      string `json:"createdAt"`
+}
+
+type JournalEntryData struct {
+	Type       string                 `json:"type"`
+	Id         int                    `json:"id"`
+	Attributes JournalEntryAttributes `json:"attributes"`
+}
+
+type JournalEntry struct {
+	Links `json:"links,omitempty"`
+	Data  JournalEntryData `json:"data"`
+}
+
+type Journal struct {
+	Links `json:"links,omitempty"`
+	Data  []JournalEntryData `json:"data"`
+	Meta  PageMeta           `json:"meta"`
+}
+
+type PhysicalCopyAttributes struct {
+	GameId        string  `json:"gameId"`
+	GameName      string  `json:"gameName"`
+	Barcode       string  `json:"barcode"` //EAN-13 or EAN-8
+	Platform      string  `json:"platform,omitempty"`
+	PaidPrice     float64 `json:"paidPrice,omitempty"`
+	PaidCurrency  string  `json:"paidCurrency,omitempty"`
+	Value         float64 `json:"value,omitempty"` //Estimated resale value
+	ValueCurrency string  `json:"valueCurrency,omitempty"`
+	ValuedAt      string  `json:"valuedAt,omitempty"`
+	CreatedAt     string  `json:"createdAt"`
+}
+
+type PhysicalCopyData struct {
+	Type       string                 `json:"type"`
+	Id         int                    `json:"id"`
+	Attributes PhysicalCopyAttributes `json:"attributes"`
+}
+
+type PhysicalCopy struct {
+	Links `json:"links,omitempty"`
+	Data  PhysicalCopyData `json:"data"`
+}
+
+type PhysicalCopies struct {
+	Links `json:"links,omitempty"`
+	Data  []PhysicalCopyData `json:"data"`
+	Meta  PageMeta           `json:"meta"`
+}
+
+type CollectionWorthAttributes struct {
+	Paid     map[string]float64 `json:"paid"`  //By currency
+	Value    map[string]float64 `json:"value"` //By currency
+	Unpaid   int                `json:"unpaid"`
+	Unvalued int                `json:"unvalued"`
+	Copies   []PhysicalCopyData `json:"copies"`
+}
+
+type CollectionWorthData struct {
+	Type       string                    `json:"type"`
+	Attributes CollectionWorthAttributes `json:"attributes"`
+}
+
+type CollectionWorth struct {
+	Links `json:"links,omitempty"`
+	Data  CollectionWorthData `json:"data"`
+}
+
+type PhotoProposalAttributes struct {
+	Id         int     `json:"id"`
+	Photo      int     `json:"photo"` //Index of the uploaded photo
+	Title      string  `json:"title"`
+	Confidence float64 `json:"confidence"`
+	Matched    bool    `json:"matched"` //Known to the metadata provider
+	Reason     string  `json:"reason,omitempty"`
+	Accepted   bool    `json:"accepted"`
+}
+
+type PhotoImportAttributes struct {
+	Status    string                    `json:"status"` //pending, proposed, failed or confirmed
+	Reason    string                    `json:"reason,omitempty"`
+	Photos    int                       `json:"photos"`
+	Proposals []PhotoProposalAttributes `json:"proposals"`
+	CreatedAt string                    `json:"createdAt"`
+	UpdatedAt string                    `json:"updatedAt"`
+}
+
+type PhotoImportData struct {
+	Type       string                `json:"type"`
+	Id         int                   `json:"id"`
+	Attributes PhotoImportAttributes `json:"attributes"`
+}
+
+type PhotoImport struct {
+	Links `json:"links,omitempty"`
+	Data  PhotoImportData `json:"data"`
+}
+
+type ShareLinkAttributes struct {
+	Url         string `json:"url,omitempty"` //Only shown when the link is issued
+	HasPassword bool   `json:"hasPassword"`
+	ExpiresAt   string `json:"expiresAt,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type ShareLinkData struct {
+	Type       string              `json:"type"`
+	Id         int                 `json:"id"`
+	Attributes ShareLinkAttributes `json:"attributes"`
+}
+
+type ShareLink struct {
+	Links `json:"links,omitempty"`
+	Data  ShareLinkData `json:"data"`
+}
+
+type ShareLinks struct {
+	Links `json:"links,omitempty"`
+	Data  []ShareLinkData `json:"data"`
+}
+
+type LibraryMemberAttributes struct {
+	UserName  string `json:"userName"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type LibraryMemberData struct {
+	Type       string                  `json:"type"`
+	Id         string                  `json:"id"`
+	Attributes LibraryMemberAttributes `json:"attributes"`
+}
+
+type LibraryMember struct {
+	Links `json:"links,omitempty"`
+	Data  LibraryMemberData `json:"data"`
+}
+
+type LibraryMembers struct {
+	Links `json:"links,omitempty"`
+	Data  []LibraryMemberData `json:"data"`
+}
+
+type MembershipAttributes struct {
+	Role      string `json:"role"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type MembershipData struct {
+	Type       string               `json:"type"`
+	Id         string               `json:"id"` //The library
+	Attributes MembershipAttributes `json:"attributes"`
+}
+
+type Memberships struct {
+	Links `json:"links,omitempty"`
+	Data  []MembershipData `json:"data"`
+}
+
+type TradeOfferAttributes struct {
+	ProposerId         string `json:"proposerId"`
+	ProposerLibraryId  string `json:"proposerLibraryId"`
+	RecipientId        string `json:"recipientId"`
+	RecipientLibraryId string `json:"recipientLibraryId"`
+	Offered            []int  `json:"offered"` //Copy ids
+	Requested          []int  `json:"requested"`
+	Status             string `json:"status"`
+	CounterOf          int    `json:"counterOf,omitempty"`
+	CreatedAt          string `json:"createdAt"`
+	UpdatedAt          string `json:"updatedAt"`
+}
+
+type TradeOfferData struct {
+	Type       string               `json:"type"`
+	Id         int                  `json:"id"`
+	Attributes TradeOfferAttributes `json:"attributes"`
+}
+
+type TradeOffer struct {
+	Links `json:"links,omitempty"`
+	Data  TradeOfferData `json:"data"`
+}
+
+type TradeOffers struct {
+	Links `json:"links,omitempty"`
+	Data  []TradeOfferData `json:"data"`
+	Meta  PageMeta         `json:"meta"`
+}
+
+type GoalAttributes struct {
+	Kind      string `json:"kind"` //complete_games, play_hours or backlog_under
+	Target    int    `json:"target"`
+	Period    string `json:"period"` //year or quarter
+	Year      int    `json:"year"`
+	Quarter   int    `json:"quarter,omitempty"`
+	StartsAt  string `json:"startsAt"`
+	EndsAt    string `json:"endsAt"`
+	Current   int    `json:"current"`
+	Expected  int    `json:"expected"` //Where a steady pace would be by now
+	Achieved  bool   `json:"achieved"`
+	Behind    bool   `json:"behind"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type GoalData struct {
+	Type       string         `json:"type"`
+	Id         int            `json:"id"`
+	Attributes GoalAttributes `json:"attributes"`
+}
+
+type Goal struct {
+	Links `json:"links,omitempty"`
+	Data  GoalData `json:"data"`
+}
+
+type Goals struct {
+	Links `json:"links,omitempty"`
+	Data  []GoalData `json:"data"`
+}
+
+type HardwareAttributes struct {
+	Kind          string `json:"kind"` //console, handheld or peripheral
+	Name          string `json:"name"`
+	Model         string `json:"model,omitempty"`
+	Serial        string `json:"serial,omitempty"`
+	PurchasedOn   string `json:"purchasedOn,omitempty"`
+	WarrantyUntil string `json:"warrantyUntil,omitempty"`
+	UnderWarranty bool   `json:"underWarranty"`
+	CreatedAt     string `json:"createdAt"`
+	UpdatedAt     string `json:"updatedAt"`
+}
+
+type HardwareData struct {
+	Type       string             `json:"type"`
+	Id         int                `json:"id"`
+	Attributes HardwareAttributes `json:"attributes"`
+}
+
+type Hardware struct {
+	Links `json:"links,omitempty"`
+	Data  HardwareData `json:"data"`
+}
+
+type HardwareList struct {
+	Links `json:"links,omitempty"`
+	Data  []HardwareData `json:"data"`
+}
+
+type SubscriptionAttributes struct {
+	Service       string   `json:"service"`
+	MonthlyCost   float64  `json:"monthlyCost"`
+	Currency      string   `json:"currency"`
+	BillingMonths int      `json:"billingMonths"`
+	StartedOn     string   `json:"startedOn"`
+	RenewsOn      string   `json:"renewsOn"`
+	EndsOn        string   `json:"endsOn,omitempty"`
+	Games         []string `json:"games"` //Game ids
+	CreatedAt     string   `json:"createdAt"`
+	UpdatedAt     string   `json:"updatedAt"`
+}
+
+type SubscriptionData struct {
+	Type       string                 `json:"type"`
+	Id         int                    `json:"id"`
+	Attributes SubscriptionAttributes `json:"attributes"`
+}
+
+type Subscription struct {
+	Links `json:"links,omitempty"`
+	Data  SubscriptionData `json:"data"`
+}
+
+type Subscriptions struct {
+	Links `json:"links,omitempty"`
+	Data  []SubscriptionData `json:"data"`
+}
+
+type SubscriptionUsage struct {
+	SubscriptionId int           `json:"subscriptionId"`
+	Service        string        `json:"service"`
+	Currency       string        `json:"currency"`
+	Cost           float64       `json:"cost"`
+	Minutes        int           `json:"minutes"`
+	Hours          float64       `json:"hours"`
+	CostPerHour    float64       `json:"costPerHour"` //0 without play
+	Games          []GameMinutes `json:"games"`
+}
+
+type SubscriptionReportAttributes struct {
+	From          string              `json:"from"`
+	To            string              `json:"to"`
+	Cost          map[string]float64  `json:"cost"` //By currency
+	Minutes       int                 `json:"minutes"`
+	Hours         float64             `json:"hours"`
+	Subscriptions []SubscriptionUsage `json:"subscriptions"`
+}
+
+type SubscriptionReportData struct {
+	Type       string                       `json:"type"`
+	Id         string                       `json:"id"`
+	Attributes SubscriptionReportAttributes `json:"attributes"`
+}
+
+type SubscriptionReport struct {
+	Links `json:"links,omitempty"`
+	Data  SubscriptionReportData `json:"data"`
+}
+
+type ModAttributes struct {
+	GameId    string `json:"gameId"`
+	GameName  string `json:"gameName"`
+	Name      string `json:"name"`
+	SourceUrl string `json:"sourceUrl,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Enabled   bool   `json:"enabled"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type ModData struct {
+	Type       string        `json:"type"`
+	Id         int           `json:"id"`
+	Attributes ModAttributes `json:"attributes"`
+}
+
+type Mod struct {
+	Links `json:"links,omitempty"`
+	Data  ModData `json:"data"`
+}
+
+type Mods struct {
+	Links `json:"links,omitempty"`
+	Data  []ModData `json:"data"`
+}
+
+type ModImportAttributes struct {
+	Imported  []ModData `json:"imported"`
+	Skipped   int       `json:"skipped"`
+	Unmatched []string  `json:"unmatched"` //Games of the export missing from the library
+}
+
+type ModImportData struct {
+	Type       string              `json:"type"`
+	Attributes ModImportAttributes `json:"attributes"`
+}
+
+type ModImportReport struct {
+	Links `json:"links,omitempty"`
+	Data  ModImportData `json:"data"`
+}
+
+type SaveBackupAttributes struct {
+	GameId     string `json:"gameId"`
+	GameName   string `json:"gameName"`
+	Location   string `json:"location"`
+	Size       int64  `json:"size"` //Bytes
+	Checksum   string `json:"checksum,omitempty"`
+	BackedUpAt string `json:"backedUpAt"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type SaveBackupData struct {
+	Type       string               `json:"type"`
+	Id         int                  `json:"id"`
+	Attributes SaveBackupAttributes `json:"attributes"`
+}
+
+type SaveBackup struct {
+	Links `json:"links,omitempty"`
+	Data  SaveBackupData `json:"data"`
+}
+
+type SaveBackups struct {
+	Links `json:"links,omitempty"`
+	Data  []SaveBackupData `json:"data"`
+}
+
+type SpeedRunAttributes struct {
+	Runner       string `json:"runner,omitempty"`
+	GameId       string `json:"gameId"`
+	GameName     string `json:"gameName"`
+	Category     string `json:"category"`
+	Milliseconds int64  `json:"milliseconds"`
+	Time         string `json:"time"`
+	RunAt        string `json:"runAt"`
+	PersonalBest bool   `json:"personalBest"`
+}
+
+type SpeedRunData struct {
+	Type       string             `json:"type"`
+	Id         int                `json:"id"`
+	Attributes SpeedRunAttributes `json:"attributes"`
+}
+
+type SpeedRun struct {
+	Links `json:"links,omitempty"`
+	Data  SpeedRunData `json:"data"`
+}
+
+type SpeedRuns struct {
+	Links `json:"links,omitempty"`
+	Data  []SpeedRunData `json:"data"`
+	Meta  *PageMeta      `json:"meta,omitempty"` //Only on the pages of a game's runs
+}
+
+type ComparedRun struct {
+	SpeedRunData
+	Behind *int64 `json:"behind,omitempty"` //Milliseconds slower than the own best, negative when faster
+}
+
+type SpeedrunComparisonData struct {
+	Category string        `json:"category"`
+	Own      *SpeedRunData `json:"own"`
+	Runs     []ComparedRun `json:"runs"`
+}
+
+type SpeedrunComparisons struct {
+	Links `json:"links,omitempty"`
+	Data  []SpeedrunComparisonData `json:"data"`
+}
+
+type MatchData struct {
+	Type       string       `json:"type"`
+	Id         int          `json:"id"`
+	Attributes result.Match `json:"attributes"`
+}
+
+type Matches struct {
+	Links `json:"links,omitempty"`
+	Data  []MatchData `json:"data"`
+	Meta  PageMeta    `json:"meta"`
+}
+
+type MatchBatch struct {
+	Links `json:"links,omitempty"`
+	Data  []result.MatchBatchItem `json:"data"`
+}
+
+type MatchStatsData struct {
+	Type       string            `json:"type"`
+	Attributes result.MatchStats `json:"attributes"`
+}
+
+type MatchStats struct {
+	Links `json:"links,omitempty"`
+	Data  MatchStatsData `json:"data"`
+}
+
+type HeadToHeads struct {
+	Links `json:"links,omitempty"`
+	Data  []result.HeadToHead `json:"data"`
+}
+
+type AgentAttributes struct {
+	Name       string `json:"name"`
+	Token      string `json:"token,omitempty"` //Shown once, when the agent is added
+	LastSeenAt string `json:"lastSeenAt,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type AgentData struct {
+	Type       string          `json:"type"`
+	Id         int             `json:"id"`
+	Attributes AgentAttributes `json:"attributes"`
+}
+
+type Agent struct {
+	Links `json:"links,omitempty"`
+	Data  AgentData `json:"data"`
+}
+
+type Agents struct {
+	Links `json:"links,omitempty"`
+	Data  []AgentData `json:"data"`
+}
+
+type ExecutableAttributes struct {
+	GameId   string `json:"gameId"`
+	GameName string `json:"gameName"`
+}
+
+type ExecutableData struct {
+	Type       string               `json:"type"`
+	Id         string               `json:"id"`
+	Attributes ExecutableAttributes `json:"attributes"`
+}
+
+type Executable struct {
+	Links `json:"links,omitempty"`
+	Data  ExecutableData `json:"data"`
+}
+
+type Executables struct {
+	Links `json:"links,omitempty"`
+	Data  []ExecutableData `json:"data"`
+}
+
+type AgentReportAttributes struct {
+	Executable string       `json:"executable"`
+	Event      string       `json:"event"`
+	Mapped     bool         `json:"mapped"`
+	GameId     string       `json:"gameId,omitempty"`
+	GameName   string       `json:"gameName,omitempty"`
+	Session    *SessionData `json:"session,omitempty"`
+}
+
+type AgentReportData struct {
+	Type       string                `json:"type"`
+	Attributes AgentReportAttributes `json:"attributes"`
+}
+
+type AgentReport struct {
+	Data AgentReportData `json:"data"`
+}
+
+type WebhookAttributes struct {
+	Name            string `json:"name"`
+	Action          string `json:"action"`
+	LibraryId       string `json:"libraryId,omitempty"`
+	Url             string `json:"url,omitempty"` //Url and Secret are shown once, when the webhook is added
+	Secret          string `json:"secret,omitempty"`
+	LastDeliveredAt string `json:"lastDeliveredAt,omitempty"`
+	CreatedAt       string `json:"createdAt"`
+}
+
+type WebhookData struct {
+	Type       string            `json:"type"`
+	Id         int               `json:"id"`
+	Attributes WebhookAttributes `json:"attributes"`
+}
+
+type Webhook struct {
+	Links `json:"links,omitempty"`
+	Data  WebhookData `json:"data"`
+}
+
+type Webhooks struct {
+	Links `json:"links,omitempty"`
+	Data  []WebhookData `json:"data"`
+}
+
+type RuleAttributes struct {
+	Name      string `json:"name"`
+	Event     string `json:"event"`
+	Condition string `json:"condition"`
+	Actions   string `json:"actions"`
+	Enabled   bool   `json:"enabled"`
+	DryRun    bool   `json:"dryRun"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type RuleData struct {
+	Type       string         `json:"type"`
+	Id         int            `json:"id"`
+	Attributes RuleAttributes `json:"attributes"`
+}
+
+type Rule struct {
+	Links `json:"links,omitempty"`
+	Data  RuleData `json:"data"`
+}
+
+type Rules struct {
+	Links `json:"links,omitempty"`
+	Data  []RuleData `json:"data"`
+}
+
+type RuleRunAttributes struct {
+	Event     string   `json:"event"`
+	EntityId  int      `json:"entityId"`
+	Actions   []string `json:"actions"` //With the fields of the event filled in
+	DryRun    bool     `json:"dryRun"`
+	Error     string   `json:"error,omitempty"`
+	CreatedAt string   `json:"createdAt"`
+}
+
+type RuleRunData struct {
+	Type       string            `json:"type"`
+	Id         int               `json:"id"`
+	Attributes RuleRunAttributes `json:"attributes"`
+}
+
+type RuleRuns struct {
+	Links `json:"links,omitempty"`
+	Data  []RuleRunData `json:"data"`
+}
+
+type RuleTestAttributes struct {
+	Matched bool     `json:"matched"`
+	Actions []string `json:"actions"`
+}
+
+type RuleTestData struct {
+	Type       string             `json:"type"`
+	Attributes RuleTestAttributes `json:"attributes"`
+}
+
+type RuleTest struct {
+	Links `json:"links,omitempty"`
+	Data  RuleTestData `json:"data"`
+}
+
+type ScriptAttributes struct {
+	Name      string `json:"name"`
+	Event     string `json:"event"`
+	Source    string `json:"source"`
+	UserId    string `json:"userId,omitempty"` //Empty when the script handles every user
+	Enabled   bool   `json:"enabled"`
+	CreatedBy string `json:"createdBy"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type ScriptData struct {
+	Type       string           `json:"type"`
+	Id         int              `json:"id"`
+	Attributes ScriptAttributes `json:"attributes"`
+}
+
+type Script struct {
+	Links `json:"links,omitempty"`
+	Data  ScriptData `json:"data"`
+}
+
+type Scripts struct {
+	Links `json:"links,omitempty"`
+	Data  []ScriptData `json:"data"`
+}
+
+type TagAttributes struct {
+	Games int `json:"games"` //Entries over all of the user's libraries
+}
+
+type TagData struct {
+	Type       string        `json:"type"`
+	Id         string        `json:"id"` //The tag
+	Attributes TagAttributes `json:"attributes"`
+}
+
+type Tags struct {
+	Links `json:"links,omitempty"`
+	Data  []TagData `json:"data"`
+}
+
+type TagChangeAttributes struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Games     int    `json:"games"`
+	Libraries int    `json:"libraries"`
+}
+
+type TagChangeData struct {
+	Type       string              `json:"type"`
+	Attributes TagChangeAttributes `json:"attributes"`
+}
+
+type TagChange struct {
+	Links `json:"links,omitempty"`
+	Data  TagChangeData `json:"data"`
+}
+
+type SearchFilter struct {
+	Rating   string `json:"rating,omitempty"`
+	MaxAge   int    `json:"maxAge,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Platform string `json:"platform,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+	Sort     string `json:"sort,omitempty"`
+}
+
+type SavedSearchAttributes struct {
+	Name      string       `json:"name"`
+	Filter    SearchFilter `json:"filter"`
+	Shared    bool         `json:"shared"`
+	Url       string       `json:"url,omitempty"` //Only right after the search is shared
+	CreatedAt string       `json:"createdAt"`
+	UpdatedAt string       `json:"updatedAt"`
+}
+
+type SavedSearchData struct {
+	Type       string                `json:"type"`
+	Id         int                   `json:"id"`
+	Attributes SavedSearchAttributes `json:"attributes"`
+}
+
+type SavedSearch struct {
+	Links `json:"links,omitempty"`
+	Data  SavedSearchData `json:"data"`
+}
+
+type SavedSearches struct {
+	Links `json:"links,omitempty"`
+	Data  []SavedSearchData `json:"data"`
+}
+
+type SharedSearchAttributes struct {
+	Name   string       `json:"name"`
+	Filter SearchFilter `json:"filter"`
+}
+
+type SharedSearchData struct {
+	Type       string                 `json:"type"`
+	Attributes SharedSearchAttributes `json:"attributes"`
+}
+
+type SharedSearch struct {
+	Links `json:"links,omitempty"`
+	Data  SharedSearchData `json:"data"`
+}
+
+type GameAddon struct {
+	GameId string  `json:"gameId"`
+	Name   string  `json:"name"`
+	Kind   string  `json:"kind"` //dlc, expansion or season_pass
+	Status string  `json:"status"`
+	Value  float64 `json:"value"`
+}
+
+type GameTreeAttributes struct {
+	Name       string      `json:"name"`
+	Status     string      `json:"status"`
+	Value      float64     `json:"value"`
+	Addons     []GameAddon `json:"addons"`
+	TotalValue float64     `json:"totalValue"` //The base game and every add-on
+	Completed  int         `json:"completed"`
+	Total      int         `json:"total"`
+	Completion int         `json:"completion"` //Percent
+}
+
+type GameTreeData struct {
+	Type       string             `json:"type"`
+	Id         string             `json:"id"`
+	Attributes GameTreeAttributes `json:"attributes"`
+}
+
+type GameTree struct {
+	Links `json:"links,omitempty"`
+	Data  GameTreeData `json:"data"`
+}
+
+type CatalogAvailability struct {
+	Service  string `json:"service"`
+	LeavesOn string `json:"leavesOn,omitempty"` //Set once the game is announced to leave
+}
+
+type CatalogGameAttributes struct {
+	Name     string                `json:"name"`
+	Status   string                `json:"status"` //wishlist or backlog
+	Catalogs []CatalogAvailability `json:"catalogs"`
+}
+
+type CatalogGameData struct {
+	Type       string                `json:"type"`
+	Id         string                `json:"id"`
+	Attributes CatalogGameAttributes `json:"attributes"`
+}
+
+type CatalogGames struct {
+	Links `json:"links,omitempty"`
+	Data  []CatalogGameData `json:"data"`
+}
+
+// Games seen through a share link leave out everything that identifies the
+// owner's account
+type SharedGame struct {
+	Name     string   `json:"name"`
+	Producer string   `json:"producer,omitempty"`
+	Rating   string   `json:"rating,omitempty"`
+	Status   string   `json:"status,omitempty"`
+	Platform string   `json:"platform,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+type SharedLibraryAttributes struct {
+	Owner     string       `json:"owner"`
+	ExpiresAt string       `json:"expiresAt,omitempty"`
+	Games     []SharedGame `json:"games"`
+}
+
+type SharedLibraryData struct {
+	Type       string                  `json:"type"`
+	Attributes SharedLibraryAttributes `json:"attributes"`
+}
+
+type SharedLibrary struct {
+	Links `json:"links,omitempty"`
+	Data  SharedLibraryData `json:"data"`
+}
+
+// Exports are a plain document meant to be saved, not an API resource
+type ProfileExport struct {
+	User       ExportedUser             `json:"user"`
+	Libraries  []ExportedLibrary        `json:"libraries"`
+	Journal    []JournalEntryAttributes `json:"journal"`
+	ExportedAt string                   `json:"exportedAt"`
+}
+
+type ExportedUser struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"createdAt,omitempty"`
+}
+
+type ExportedLibrary struct {
+	Id    string          `json:"id"`
+	Games []result.Game   `json:"games"`
+	Mods  []ModAttributes `json:"mods"`
+}
+
+type RenderedData struct {
+	Type       string            `json:"type"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+type Rendered struct {
+	Data RenderedData `json:"data"`
+}
+
+type CalendarLinkData struct {
+	Type       string            `json:"type"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+type CalendarLink struct {
+	Links `json:"links,omitempty"`
+	Data  CalendarLinkData `json:"data"`
+}
+
+type AdminUserAttributes struct {
+	Name           string `json:"name"`
+	Role           string `json:"role"`
+	Status         string `json:"status"`
+	StatusReason   string `json:"statusReason,omitempty"`
+	SuspendedUntil string `json:"suspendedUntil,omitempty"`
+	CreatedAt      string `json:"createdAt,omitempty"`
+	UpdatedAt      string `json:"updatedAt,omitempty"`
+}
+
+type AdminUserData struct {
+	Type       string              `json:"type"`
+	Id         string              `json:"id"`
+	Attributes AdminUserAttributes `json:"attributes"`
+}
+
+// Cursor is handed back as ?cursor for the next page, it is empty on the
+// last page
+type PageMeta struct {
+	Limit   int    `json:"limit"`
+	Cursor  string `json:"cursor,omitempty"`
+	HasMore bool   `json:"hasMore"`
+}
+
+type AdminUser struct {
+	Links `json:"links,omitempty"`
+	Data  AdminUserData `json:"data"`
+}
+
+type AdminUsers struct {
+	Links `json:"links,omitempty"`
+	Data  []AdminUserData `json:"data"`
+	Meta  PageMeta        `json:"meta"`
+}
+
+type UserStatsAttributes struct {
+	Libraries     int  `json:"libraries"`
+	Games         int  `json:"games"`
+	Notifications int  `json:"notifications"`
+	Changes       int  `json:"changes"`
+	InfoBytes     *int `json:"infoBytes"` //Null once the user is removed
+}
+
+type UserStatsData struct {
+	Type       string              `json:"type"`
+	Id         string              `json:"id"`
+	Attributes UserStatsAttributes `json:"attributes"`
+}
+
+type UserStats struct {
+	Links `json:"links,omitempty"`
+	Data  UserStatsData `json:"data"`
+}
+
+type MetricsData struct {
+	Type       string         `json:"type"`
+	Attributes result.Metrics `json:"attributes"`
+}
+
+type Metrics struct {
+	Links `json:"links,omitempty"`
+	Data  MetricsData `json:"data"`
+}
+
+type IntegrityData struct {
+	Type       string                  `json:"type"`
+	Id         string                  `json:"id"`
+	Attributes result.IntegrityFinding `json:"attributes"`
+}
+
+type Integrity struct {
+	Links `json:"links,omitempty"`
+	Data  []IntegrityData `json:"data"`
+}
+
+type MaintenanceData struct {
+	Type       string             `json:"type"`
+	Attributes result.Maintenance `json:"attributes"`
+}
+
+type Maintenance struct {
+	Links `json:"links,omitempty"`
+	Data  MaintenanceData `json:"data"`
+}
+
+type DiagnosticsData struct {
+	Type       string             `json:"type"`
+	Attributes result.Diagnostics `json:"attributes"`
+}
+
+type Diagnostics struct {
+	Links `json:"links,omitempty"`
+	Data  DiagnosticsData `json:"data"`
+}
+
+type FlagAttributes struct {
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	Percentage  int      `json:"percentage"`
+	UserIds     []string `json:"userIds"`
+	UpdatedAt   string   `json:"updatedAt,omitempty"`
+}
+
+type FlagData struct {
+	Type       string         `json:"type"`
+	Id         string         `json:"id"`
+	Attributes FlagAttributes `json:"attributes"`
+}
+
+type FeatureFlag struct {
+	Links `json:"links,omitempty"`
+	Data  FlagData `json:"data"`
+}
+
+type GameSpoilersData struct {
+	Type       string          `json:"type"`
+	Id         string          `json:"id"`
+	Attributes map[string]bool `json:"attributes"`
+}
+
+type GameSpoilers struct {
+	Links `json:"links,omitempty"`
+	Data  GameSpoilersData `json:"data"`
+}
+
+type FeatureFlags struct {
+	Links `json:"links,omitempty"`
+	Data  []FlagData `json:"data"`
+}
+
+type FeaturesData struct {
+	Type       string          `json:"type"`
+	Id         string          `json:"id"`
+	Attributes map[string]bool `json:"attributes"`
+}
+
+type Features struct {
+	Links `json:"links,omitempty"`
+	Data  FeaturesData `json:"data"`
+}
+
+type SettingsAttributes struct {
+	DisplayCurrency  string `json:"displayCurrency"`
+	Timezone         string `json:"timezone"`
+	NotifyLibraries  bool   `json:"notifyLibraries"`
+	NotifyGames      bool   `json:"notifyGames"`
+	DefaultLibraryId string `json:"defaultLibraryId,omitempty"`
+	ProfilePublic    bool   `json:"profilePublic"`
+	LibrariesPublic  bool   `json:"librariesPublic"`
+	RatingLimit      int    `json:"ratingLimit"`
+	Locale           string `json:"locale"`
+}
+
+type SettingsData struct {
+	Type       string             `json:"type"`
+	Id         string             `json:"id"`
+	Attributes SettingsAttributes `json:"attributes"`
+}
+
+type Settings struct {
+	Links `json:"links,omitempty"`
+	Data  SettingsData `json:"data"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ErrorBody struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+	TraceId string       `json:"traceId"`
+}
+
+type Error struct {
+	Error ErrorBody `json:"error"`
+}
+
+type LibraryVersions struct {
+	Links `json:"links,omitempty"`
+	Data  []DataLv2 `json:"data"`
+}
+
+type Info struct {
+	Links `json:"links,omitempty"`
+	Data  `json:"data, omitempty"`
+}
+
+// Formats t with its offset, leaving unknown times out of the response
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func optionalTimestamp(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return timestamp(*t)
+}
+
+func ViewToken(token result.Token) Token {
+	return Token{
+		Data: Data{
+			Type: "token",
+			Attributes: Attributes{
+				TokenString:  token.AccessToken,
+				RefreshToken: token.RefreshToken,
+				ExpiresIn:    token.ExpiresIn,
+			},
+		},
+	}
+}
+
+func ViewUser(id, name string, libraries []Library, createdAt, updatedAt time.Time) User {
+	return User{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s", id),
+		},
+		Data: Data{
+			Type: "users",
+			Id:   id,
+			Attributes: Attributes{
+				Name:      name,
+				CreatedAt: timestamp(createdAt),
+				UpdatedAt: timestamp(updatedAt),
+			},
+			Relationships: Relationships{
+				Libraries: libraries,
+			},
+		},
+	}
+}
+
+// A new user is shown with the player it was linked to
+func ViewAddedUser(user result.UserAdd) User {
+	added := ViewUser(user.Id, user.Name, nil, time.Time{}, time.Time{})
+	added.Data.Attributes.PlayerId = user.PlayerId
+	added.Data.Attributes.PlayerName = user.PlayerName
+	return added
+}
+
+func ViewInfo(info, userId string, version int64) Info {
+	return Info{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/info", userId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", userId),
+		},
+		Data: Data{
+			Type: "info",
+			Id:   userId,
+			Attributes: Attributes{
+				Content: info,
+				Version: version,
+			},
+			Relationships: Relationships{
+				Owner: Owner{
+					DataLv2: DataLv2{
+						Type: "users",
+						Id:   userId,
+					},
+				},
+			},
+		},
+	}
+}
+
+func ViewLibrary(userId, libId string, games []Game, version int64,
+	createdAt, updatedAt time.Time) Library {
+	return Library{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s", userId, libId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", userId),
+		},
+		Data: Data{
+			Type: "libraries",
+			Id:   libId,
+			Attributes: Attributes{
+				Version:   version,
+				CreatedAt: timestamp(createdAt),
+				UpdatedAt: timestamp(updatedAt),
+			},
+			Relationships: Relationships{
+				Games: games,
+				Owner: Owner{
+					DataLv2: DataLv2{
+						Type: "users",
+						Id:   userId,
+					},
+				},
+			},
+		},
+	}
+}
+
+func ViewLibraryGames(message result.LibraryGames) LibraryGames {
+	data := []Data{}
+	for _, game := range message.Games {
+		data = append(data, ViewGame(game).Data)
+	}
+	return LibraryGames{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games",
+				message.UserId, message.LibraryId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s",
+				message.UserId, message.LibraryId),
+		},
+		Data: data,
+		Meta: PageMeta{Limit: message.Limit, Cursor: message.Cursor, HasMore: message.HasMore},
+	}
+}
+
+func ViewGame(game result.Game) Game {
+	return Game{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games/%s",
+				game.UserId, game.LibraryId, game.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/",
+				game.UserId, game.LibraryId),
+		},
+		Data: Data{
+			Type: "games",
+			Id:   game.Id,
+			Attributes: Attributes{
+				Name:         game.Name,
+				Producer:     game.Producer,
+				Value:        game.Value,
+				MinAge:       game.MinAge,
+				Rating:       game.Rating,
+				Status:       game.Status,
+				Platform:     game.Platform,
+				Tags:         game.Tags,
+				WishlistRank: game.WishlistRank,
+				Version:      game.Version,
+				CreatedAt:    timestamp(game.CreatedAt),
+				UpdatedAt:    timestamp(game.UpdatedAt),
+			},
+			Relationships: Relationships{
+				Library: LibOfGame{
+					DataLv2: DataLv2{
+						Type: "libraries",
+						Id:   game.LibraryId,
+					},
+				},
+			},
+		},
+	}
+}
+
+func ViewImportReport(report result.ImportReport) ImportReport {
+	return ImportReport{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/import",
+				report.UserId, report.LibraryId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games",
+				report.UserId, report.LibraryId),
+		},
+		Data: ImportReportData{Type: "imports", Attributes: report},
+	}
+}
+
+func ViewGameBatch(batch result.GameBatch) GameBatch {
+	data := []BatchItemData{}
+	for _, item := range batch.Items {
+		data = append(data, BatchItemData{Type: "games", Id: item.GameId, Status: item.Status,
+			Code: item.Code, Message: item.Message, Version: item.Version})
+	}
+	return GameBatch{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games",
+				batch.UserId, batch.LibraryId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s",
+				batch.UserId, batch.LibraryId),
+		},
+		Data: data,
+	}
+}
+
+func ViewLibraries(libraryIds []string) []Library {
+	var libraries []Library
+	for _, id := range libraryIds {
+		libraries = append(libraries, Library{
+			Data: Data{
+				Type: "libraries",
+				Id:   id,
+			},
+		})
+	}
+	return libraries
+}
+
+func ViewGames(gameIds []string) []Game {
+	var games []Game
+	for _, id := range gameIds {
+		games = append(games, Game{
+			Data: Data{
+				Type: "games",
+				Id:   id,
+			},
+		})
+	}
+	return games
+}
+
+func ViewNotifications(message result.Notifications) Notifications {
+	data := []DataLv2{}
+	for _, notification := range message.Notifications {
+		status := "unread"
+		if notification.Read {
+			status = "read"
+		}
+		data = append(data, DataLv2{
+			Type: "notifications",
+			Id:   strconv.Itoa(notification.Id),
+			Attributes: Attributes{
+				Kind:      notification.Kind,
+				Message:   notification.Message,
+				Status:    status,
+				CreatedAt: timestamp(notification.CreatedAt),
+			},
+		})
+	}
+	return Notifications{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/notifications", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+		Meta: Meta{
+			PageMeta: PageMeta{Limit: message.Limit, Cursor: message.Cursor, HasMore: message.HasMore},
+			Unread:   message.Unread,
+		},
+	}
+}
+
+func ViewSync(message result.Sync) Sync {
+	data := []ChangeData{}
+	for _, change := range message.Changes {
+		data = append(data, ChangeData{
+			Type: "changes",
+			Attributes: ChangeAttributes{
+				Entity:    change.Entity,
+				EntityId:  change.EntityId,
+				ParentId:  change.ParentId,
+				Action:    change.Action,
+				ChangedAt: timestamp(change.ChangedAt),
+			},
+		})
+	}
+	return Sync{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/sync?cursor=%s", message.UserId, message.Cursor),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+		Meta: SyncMeta{Cursor: message.Cursor, HasMore: message.HasMore},
+	}
+}
+
+func ViewLibraryEvents(message result.LibraryEvents) LibraryEvents {
+	data := []LibraryEventData{}
+	for _, event := range message.Events {
+		data = append(data, LibraryEventData{
+			Type: "libraryEvents",
+			Id:   strconv.FormatInt(event.Sequence, 10),
+			Attributes: LibraryEventAttributes{
+				Sequence:   event.Sequence,
+				Kind:       event.Kind,
+				Games:      event.Games,
+				Status:     event.Status,
+				Platform:   event.Platform,
+				Tags:       event.Tags,
+				From:       event.From,
+				To:         event.To,
+				RecordedAt: timestamp(event.RecordedAt),
+			},
+		})
+	}
+	return LibraryEvents{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/events", message.UserId,
+				message.LibraryId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s", message.UserId,
+				message.LibraryId),
+		},
+		Data: data,
+		Meta: PageMeta{Limit: message.Limit, Cursor: message.Cursor, HasMore: message.HasMore},
+	}
+}
+
+func ViewLibraryAsOf(message result.LibraryAsOf) LibraryAsOf {
+	return LibraryAsOf{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/history", message.UserId,
+				message.LibraryId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s", message.UserId,
+				message.LibraryId),
+		},
+		Data: LibraryAsOfData{Type: "libraryHistories", Attributes: LibraryAsOfAttributes{
+			At: timestamp(message.At), Detailed: message.Detailed, Games: message.Games}},
+	}
+}
+
+func ViewLibraryDiff(message result.LibraryDiff) LibraryDiff {
+	return LibraryDiff{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/history/diff",
+				message.UserId, message.LibraryId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s", message.UserId,
+				message.LibraryId),
+		},
+		Data: LibraryDiffData{Type: "libraryDiffs", Attributes: LibraryDiffAttributes{
+			From: timestamp(message.From), To: timestamp(message.To), Detailed: message.Detailed,
+			Added: message.Added, Removed: message.Removed, Changed: message.Changed}},
+	}
+}
+
+func undoActionData(action result.UndoAction) UndoActionData {
+	before, after := action.Before, action.After
+	if before == nil {
+		before = []result.LibraryEventGame{}
+	}
+	if after == nil {
+		after = []result.LibraryEventGame{}
+	}
+	return UndoActionData{
+		Type: "undoActions",
+		Id:   strconv.FormatInt(action.Id, 10),
+		Attributes: UndoActionAttributes{Kind: action.Kind, LibraryId: action.LibraryId,
+			Before: before, After: after, CreatedAt: timestamp(action.CreatedAt),
+			UndoneAt: timestamp(action.UndoneAt)},
+	}
+}
+
+func ViewUndoStack(message result.UndoStack) UndoStack {
+	data := []UndoActionData{}
+	for _, action := range message.Actions {
+		data = append(data, undoActionData(action))
+	}
+	return UndoStack{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/undo", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func ViewUndoAction(userId string, action result.UndoAction) UndoAction {
+	return UndoAction{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/undo", userId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s", userId,
+				action.LibraryId),
+		},
+		Data: undoActionData(action),
+	}
+}
+
+func trashItemData(item result.TrashItem) TrashItemData {
+	games := item.Games
+	if games == nil {
+		games = []result.LibraryEventGame{}
+	}
+	return TrashItemData{
+		Type: "trashItems",
+		Id:   strconv.FormatInt(item.Id, 10),
+		Attributes: TrashItemAttributes{Kind: item.Kind, LibraryId: item.LibraryId, Games: games,
+			RemovedAt: timestamp(item.RemovedAt)},
+	}
+}
+
+func ViewTrash(message result.Trash) Trash {
+	data := []TrashItemData{}
+	for _, item := range message.Items {
+		data = append(data, trashItemData(item))
+	}
+	return Trash{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/trash", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+// Related points at the library the item was restored into
+func ViewTrashItem(userId string, item result.TrashItem) TrashItem {
+	return TrashItem{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/trash/%d", userId, item.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s", userId,
+				item.LibraryId),
+		},
+		Data: trashItemData(item),
+	}
+}
+
+func ViewLibraryReplay(message result.LibraryReplay) LibraryReplay {
+	return LibraryReplay{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/admin/libraries/%s/replay", message.LibraryId),
+		},
+		Data: LibraryReplayData{Type: "libraryReplays", Attributes: message},
+	}
+}
+
+func ViewSettings(settings result.Settings) Settings {
+	return Settings{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/settings", settings.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", settings.UserId),
+		},
+		Data: SettingsData{
+			Type: "settings",
+			Id:   settings.UserId,
+			Attributes: SettingsAttributes{
+				DisplayCurrency:  settings.DisplayCurrency,
+				Timezone:         settings.Timezone,
+				NotifyLibraries:  settings.NotifyLibraries,
+				NotifyGames:      settings.NotifyGames,
+				DefaultLibraryId: settings.DefaultLibraryId,
+				ProfilePublic:    settings.ProfilePublic,
+				LibrariesPublic:  settings.LibrariesPublic,
+				RatingLimit:      settings.RatingLimit,
+				Locale:           settings.Locale,
+			},
+		},
+	}
+}
+
+func ViewLibraryVersions(message result.LibraryVersions) LibraryVersions {
+	data := []DataLv2{}
+	for _, library := range message.Libraries {
+		data = append(data, DataLv2{
+			Type: "libraries",
+			Id:   library.Id,
+			Attributes: Attributes{
+				Version:   library.Version,
+				UpdatedAt: timestamp(library.UpdatedAt),
+			},
+		})
+	}
+	return LibraryVersions{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/libraries", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func adminUserData(user result.AdminUser) AdminUserData {
+	return AdminUserData{
+		Type: "users",
+		Id:   user.Id,
+		Attributes: AdminUserAttributes{
+			Name:           user.Name,
+			Role:           user.Role,
+			Status:         user.Status,
+			StatusReason:   user.StatusReason,
+			SuspendedUntil: optionalTimestamp(user.SuspendedUntil),
+			CreatedAt:      timestamp(user.CreatedAt),
+			UpdatedAt:      timestamp(user.UpdatedAt),
+		},
+	}
+}
+
+func ViewAdminUser(user result.AdminUser) AdminUser {
+	return AdminUser{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/admin/users/%s", user.Id),
+		},
+		Data: adminUserData(user),
+	}
+}
+
+func ViewAdminUsers(message result.AdminUsers) AdminUsers {
+	data := []AdminUserData{}
+	for _, user := range message.Users {
+		data = append(data, adminUserData(user))
+	}
+	return AdminUsers{
+		Links: Links{
+			Self: "http://localhost:8080/admin/users",
+		},
+		Data: data,
+		Meta: PageMeta{Limit: message.Limit, Cursor: message.Cursor, HasMore: message.HasMore},
+	}
+}
+
+func ViewUserStats(stats result.UserStats) UserStats {
+	return UserStats{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/admin/users/%s/stats", stats.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/admin/users/%s", stats.UserId),
+		},
+		Data: UserStatsData{
+			Type: "userStats",
+			Id:   stats.UserId,
+			Attributes: UserStatsAttributes{
+				Libraries:     stats.Libraries,
+				Games:         stats.Games,
+				Notifications: stats.Notifications,
+				Changes:       stats.Changes,
+				InfoBytes:     stats.InfoBytes,
+			},
+		},
+	}
+}
+
+func ViewMetrics(metrics result.Metrics) Metrics {
+	return Metrics{
+		Links: Links{
+			Self: "http://localhost:8080/admin/metrics",
+		},
+		Data: MetricsData{Type: "metrics", Attributes: metrics},
+	}
+}
+
+func ViewIntegrity(message result.Integrity) Integrity {
+	data := []IntegrityData{}
+	for _, finding := range message.Findings {
+		if finding.Ids == nil {
+			finding.Ids = []int{}
+		}
+		data = append(data, IntegrityData{Type: "integrityFindings", Id: finding.Check,
+			Attributes: finding})
+	}
+	self := "http://localhost:8080/admin/integrity"
+	if message.Repaired {
+		self += "/repair"
+	}
+	return Integrity{Links: Links{Self: self}, Data: data}
+}
+
+func ViewMaintenance(status result.Maintenance) Maintenance {
+	return Maintenance{
+		Links: Links{
+			Self: "http://localhost:8080/admin/maintenance",
+		},
+		Data: MaintenanceData{Type: "maintenance", Attributes: status},
+	}
+}
+
+func ViewDiagnostics(diagnostics result.Diagnostics) Diagnostics {
+	return Diagnostics{
+		Links: Links{
+			Self: "http://localhost:8080/admin/diagnostics",
+		},
+		Data: DiagnosticsData{Type: "diagnostics", Attributes: diagnostics},
+	}
+}
+
+func flagData(flag result.FeatureFlag) FlagData {
+	return FlagData{
+		Type: "flags",
+		Id:   flag.Name,
+		Attributes: FlagAttributes{
+			Description: flag.Description,
+			Enabled:     flag.Enabled,
+			Percentage:  flag.Percentage,
+			UserIds:     flag.UserIds,
+			UpdatedAt:   timestamp(flag.UpdatedAt),
+		},
+	}
+}
+
+func ViewFeatureFlag(flag result.FeatureFlag) FeatureFlag {
+	return FeatureFlag{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/admin/flags/%s", flag.Name),
+		},
+		Data: flagData(flag),
+	}
+}
+
+func ViewGameSpoilers(message result.GameSpoilers) GameSpoilers {
+	return GameSpoilers{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/admin/games/%s/spoilers", message.GameId),
+		},
+		Data: GameSpoilersData{
+			Type:       "gameSpoilers",
+			Id:         message.GameId,
+			Attributes: map[string]bool{"containsSpoilers": message.ContainsSpoilers},
+		},
+	}
+}
+
+func ViewFeatureFlags(message result.FeatureFlags) FeatureFlags {
+	data := []FlagData{}
+	for _, flag := range message.Flags {
+		data = append(data, flagData(flag))
+	}
+	return FeatureFlags{
+		Links: Links{
+			Self: "http://localhost:8080/admin/flags",
+		},
+		Data: data,
+	}
+}
+
+func ViewFeatures(message result.Features) Features {
+	return Features{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/features", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: FeaturesData{Type: "features", Id: message.UserId, Attributes: message.Features},
+	}
+}
+
+type AtomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type AtomAuthor struct {
+	Name string `xml:"name"`
+	Uri  string `xml:"uri"`
+}
+
+type AtomEntry struct {
+	Id      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Link    AtomLink `xml:"link"`
+	Summary string   `xml:"summary"`
+}
+
+type AtomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Id      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  AtomAuthor  `xml:"author"`
+	Links   []AtomLink  `xml:"link"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+var feedVerbs = map[string]string{
+	"game_added":     "added",
+	"game_completed": "completed",
+	"played_with":    "played",
+}
+
+// Feed and entry ids stay the same however the user is renamed, as Atom requires
+func ViewFeed(message result.Feed) AtomFeed {
+	self := fmt.Sprintf("http://localhost:8080/users/%s/feed.atom", url.PathEscape(message.UserName))
+	profile := fmt.Sprintf("http://localhost:8080/u/%s", url.PathEscape(message.UserName))
+	feed := AtomFeed{
+		Id:      fmt.Sprintf("tag:game-tracker,2026:user:%s", message.UserId),
+		Title:   fmt.Sprintf("%s on Game Tracker", message.UserName),
+		Updated: message.UpdatedAt.UTC().Format(time.RFC3339),
+		Author:  AtomAuthor{Name: message.UserName, Uri: profile},
+		Links: []AtomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: self},
+			{Rel: "alternate", Type: "application/json", Href: profile},
+		},
+		Entries: []AtomEntry{},
+	}
+	for _, entry := range message.Entries {
+		verb := feedVerbs[entry.Kind]
+		if verb == "" {
+			verb = entry.Kind
+		}
+		title := fmt.Sprintf("%s %s %s", message.UserName, verb, entry.GameName)
+		if entry.Kind == "played_with" {
+			if len(entry.Partners) == 0 {
+				title += " in co-op"
+			} else {
+				title += " with " + strings.Join(entry.Partners, ", ")
+			}
+		}
+		feed.Entries = append(feed.Entries, AtomEntry{
+			Id:      fmt.Sprintf("tag:game-tracker,2026:activity:%d", entry.Id),
+			Title:   title,
+			Updated: entry.CreatedAt.UTC().Format(time.RFC3339),
+			Link:    AtomLink{Rel: "alternate", Href: profile},
+			Summary: title + ".",
+		})
+	}
+	return feed
+}
+
+func sessionData(session result.PlaySession) SessionData {
+	partners := []SessionPartner{}
+	for _, partner := range session.Partners {
+		partners = append(partners, SessionPartner{Id: partner.UserId, Name: partner.UserName})
+	}
+	return SessionData{
+		Type: "sessions",
+		Id:   strconv.Itoa(session.Id),
+		Attributes: SessionAttributes{
+			GameId:    session.GameId,
+			GameName:  session.GameName,
+			StartsAt:  timestamp(session.StartsAt),
+			EndsAt:    timestamp(session.EndsAt),
+			Minutes:   session.Minutes,
+			Notes:     session.Notes,
+			Partners:  partners,
+			CreatedAt: timestamp(session.CreatedAt),
+		},
+	}
+}
+
+func ViewPlaySession(userId string, session result.PlaySession) PlaySession {
+	return PlaySession{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/sessions/%d", userId, session.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/sessions", userId),
+		},
+		Data: sessionData(session),
+	}
+}
+
+func coopData(partner result.CoopPartner) CoopData {
+	return CoopData{
+		Type: "coop",
+		Id:   partner.PartnerId,
+		Attributes: CoopAttributes{
+			PartnerName:  partner.PartnerName,
+			Sessions:     partner.Sessions,
+			Minutes:      partner.Minutes,
+			Hours:        hours(partner.Minutes),
+			LastPlayedAt: timestamp(partner.LastPlayedAt),
+			Games:        gameMinutes(partner.Games),
+		},
+	}
+}
+
+func gameMinutes(games []result.GameMinutes) []GameMinutes {
+	data := []GameMinutes{}
+	for _, game := range games {
+		data = append(data, GameMinutes{GameId: game.GameId, GameName: game.GameName,
+			Minutes: game.Minutes, Hours: hours(game.Minutes)})
+	}
+	return data
+}
+
+// Hours rounded to one decimal
+func hours(minutes int) float64 {
+	return math.Round(float64(minutes)/6) / 10
+}
+
+func ViewCoopPartner(partner result.CoopPartner) CoopPartner {
+	return CoopPartner{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/coop/%s", partner.UserId, partner.PartnerId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", partner.PartnerId),
+		},
+		Data: coopData(partner),
+	}
+}
+
+func ViewCoop(message result.Coop) Coop {
+	data := []CoopData{}
+	for _, partner := range message.Partners {
+		data = append(data, coopData(partner))
+	}
+	return Coop{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/coop", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/sessions", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func ViewPlaySessions(message result.PlaySessions) PlaySessions {
+	data := []SessionData{}
+	for _, session := range message.Sessions {
+		data = append(data, sessionData(session))
+	}
+	return PlaySessions{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/sessions?from=%s&to=%s", message.UserId,
+				url.QueryEscape(timestamp(message.From)), url.QueryEscape(timestamp(message.To))),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func ViewLibraryStats(message result.LibraryStats) LibraryStats {
+	return LibraryStats{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/stats", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries", message.UserId),
+		},
+		Data: LibraryStatsData{
+			Type: "stats",
+			Attributes: LibraryStatsAttributes{
+				Libraries:       message.Libraries,
+				Games:           message.Games,
+				Value:           message.Value,
+				Statuses:        message.Statuses,
+				Platforms:       message.Platforms,
+				PlatformMinutes: message.PlatformMinutes,
+				RefreshedAt:     optionalTimestamp(message.RefreshedAt),
+			},
+		},
+	}
+}
+
+func ViewHeatmap(message result.Heatmap) Heatmap {
+	days := []HeatmapDay{}
+	for _, day := range message.Days {
+		days = append(days, HeatmapDay{Date: day.Date, Minutes: day.Minutes, Level: day.Level})
+	}
+	return Heatmap{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/heatmap", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/sessions", message.UserId),
+		},
+		Data: HeatmapData{
+			Type: "heatmaps",
+			Attributes: HeatmapAttributes{
+				From:       message.From,
+				To:         message.To,
+				Minutes:    message.Minutes,
+				ActiveDays: message.ActiveDays,
+				MaxMinutes: message.MaxMinutes,
+				Days:       days,
+			},
+		},
+	}
+}
+
+func ViewStreak(message result.Streak) Streak {
+	milestones := message.Milestones
+	if milestones == nil {
+		milestones = []int{}
+	}
+	return Streak{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/streak", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/sessions", message.UserId),
+		},
+		Data: StreakData{
+			Type: "streaks",
+			Attributes: StreakAttributes{
+				Current:       message.Current,
+				CurrentSince:  message.CurrentSince,
+				Longest:       message.Longest,
+				LongestFrom:   message.LongestFrom,
+				LongestTo:     message.LongestTo,
+				PlayedToday:   message.PlayedToday,
+				Milestones:    milestones,
+				NextMilestone: message.NextMilestone,
+			},
+		},
+	}
+}
+
+// Earned badges come first, then the upcoming ones closest to their goal
+func ViewBadges(message result.Badges) Badges {
+	data := []BadgeData{}
+	for _, badge := range message.Badges {
+		data = append(data, BadgeData{
+			Type: "badges",
+			Id:   badge.Id,
+			Attributes: BadgeAttributes{
+				Name:        badge.Name,
+				Description: badge.Description,
+				Goal:        badge.Goal,
+				Progress:    badge.Progress,
+				Earned:      badge.Earned,
+				EarnedAt:    timestamp(badge.EarnedAt),
+			},
+		})
+	}
+	return Badges{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/badges", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func releaseData(release result.Release) ReleaseData {
+	return ReleaseData{
+		Type: "releases",
+		Id:   release.GameId,
+		Attributes: ReleaseAttributes{
+			GameName:  release.GameName,
+			Date:      release.Date,
+			Source:    release.Source,
+			UpdatedAt: timestamp(release.UpdatedAt),
+		},
+	}
+}
+
+func ViewRelease(userId string, release result.Release) Release {
+	return Release{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/releases/%s", userId, release.GameId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/releases", userId),
+		},
+		Data: releaseData(release),
+	}
+}
+
+func ViewReleases(message result.Releases) Releases {
+	data := []ReleaseData{}
+	for _, release := range message.Releases {
+		data = append(data, releaseData(release))
+	}
+	return Releases{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/releases", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func franchiseData(franchise result.Franchise) FranchiseData {
+	data := FranchiseData{
+		Type: "franchises",
+		Id:   franchise.Id,
+		Attributes: FranchiseAttributes{
+			Name:      franchise.Name,
+			CreatedAt: timestamp(franchise.CreatedAt),
+		},
+	}
+	for _, game := range franchise.Games {
+		data.Attributes.Games = append(data.Attributes.Games, FranchiseGameAttributes{
+			GameId:   game.GameId,
+			GameName: game.GameName,
+			Position: game.Position,
+		})
+	}
+	return data
+}
+
+func ViewFranchise(franchise result.Franchise) Franchise {
+	return Franchise{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/franchises/%s", franchise.Id),
+			Related: "http://localhost:8080/franchises",
+		},
+		Data: franchiseData(franchise),
+	}
+}
+
+func ViewFranchises(message result.Franchises) Franchises {
+	data := []FranchiseData{}
+	for _, franchise := range message.Franchises {
+		data = append(data, franchiseData(franchise))
+	}
+	return Franchises{
+		Links: Links{
+			Self: "http://localhost:8080/franchises",
+		},
+		Data: data,
+	}
+}
+
+func ViewFranchiseProgress(message result.FranchiseProgresses) FranchiseProgress {
+	data := []FranchiseProgressData{}
+	for _, progress := range message.Progress {
+		percent := 0
+		if progress.Total > 0 {
+			percent = progress.Owned * 100 / progress.Total
+		}
+		data = append(data, FranchiseProgressData{
+			Type: "franchiseProgress",
+			Id:   progress.Franchise.Id,
+			Attributes: FranchiseProgressAttributes{
+				Name:      progress.Franchise.Name,
+				Total:     progress.Total,
+				Owned:     progress.Owned,
+				Completed: progress.Completed,
+				Percent:   percent,
+			},
+		})
+	}
+	return FranchiseProgress{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/franchises", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func childAccountData(child result.ChildAccount) ChildAccountData {
+	return ChildAccountData{
+		Type: "childAccounts",
+		Id:   child.Id,
+		Attributes: ChildAccountAttributes{
+			Name:         child.Name,
+			DailyMinutes: child.DailyMinutes,
+			RatingCap:    child.RatingCap,
+			CreatedAt:    timestamp(child.CreatedAt),
+			UpdatedAt:    timestamp(child.UpdatedAt),
+		},
+	}
+}
+
+func ViewChildAccount(userId string, child result.ChildAccount) ChildAccount {
+	return ChildAccount{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/children/%s", userId, child.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/children", userId),
+		},
+		Data: childAccountData(child),
+	}
+}
+
+func ViewChildAccounts(message result.ChildAccounts) ChildAccounts {
+	data := []ChildAccountData{}
+	for _, child := range message.Children {
+		data = append(data, childAccountData(child))
+	}
+	return ChildAccounts{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/children", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func ViewChildReport(message result.ChildReport) ChildReport {
+	attributes := ChildReportAttributes{
+		From:         timestamp(message.From),
+		To:           timestamp(message.To),
+		DailyMinutes: message.Child.DailyMinutes,
+		RatingCap:    message.Child.RatingCap,
+		TotalMinutes: message.TotalMinutes,
+		Days:         []DayPlaytimeAttributes{},
+		Games:        []GamePlaytimeAttributes{},
+	}
+	for _, day := range message.Days {
+		attributes.Days = append(attributes.Days, DayPlaytimeAttributes{
+			Day:       day.Day,
+			Minutes:   day.Minutes,
+			OverLimit: day.OverLimit,
+		})
+	}
+	for _, game := range message.Games {
+		attributes.Games = append(attributes.Games, GamePlaytimeAttributes{
+			GameId:   game.GameId,
+			GameName: game.GameName,
+			Minutes:  game.Minutes,
+		})
+	}
+	return ChildReport{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/children/%s/report", message.UserId,
+				message.Child.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/children/%s", message.UserId,
+				message.Child.Id),
+		},
+		Data: ChildReportData{
+			Type:       "childReports",
+			Id:         message.Child.Id,
+			Attributes: attributes,
+		},
+	}
+}
+
+func ViewPersonalMetadata(userId string, metadata result.PersonalMetadata) PersonalMetadata {
+	return PersonalMetadata{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/personal/%s", userId, metadata.GameId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/tonight", userId),
+		},
+		Data: PersonalMetadataData{
+			Type: "personalMetadata",
+			Id:   metadata.GameId,
+			Attributes: PersonalMetadataAttributes{
+				Difficulty:    metadata.Difficulty,
+				Replayability: metadata.Replayability,
+				Moods:         metadata.Moods,
+				UpdatedAt:     timestamp(metadata.UpdatedAt),
+			},
+		},
+	}
+}
+
+func ViewTonight(message result.Tonight) Tonight {
+	data := []TonightGameData{}
+	for _, game := range message.Games {
+		data = append(data, TonightGameData{
+			Type:       "tonightGames",
+			Id:         game.GameId,
+			Attributes: tonightGameAttributes(game),
+		})
+	}
+	return Tonight{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/tonight", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func tonightGameAttributes(game result.TonightGame) TonightGameAttributes {
+	return TonightGameAttributes{
+		Name:          game.GameName,
+		Producer:      game.Producer,
+		Rating:        game.Rating,
+		Status:        game.Status,
+		Platform:      game.Platform,
+		Difficulty:    game.Difficulty,
+		Replayability: game.Replayability,
+		Moods:         game.Moods,
+	}
+}
+
+func ViewSuggestion(message result.Suggestion) Suggestion {
+	return Suggestion{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/suggestion?seed=%d", message.UserId, message.Seed),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/personal/%s", message.UserId, message.Game.GameId),
+		},
+		Data: SuggestionData{
+			Type: "suggestions",
+			Id:   message.Game.GameId,
+			Attributes: SuggestionAttributes{
+				TonightGameAttributes: tonightGameAttributes(message.Game),
+				AddedAt:               timestamp(message.AddedAt),
+				CompletionMinutes:     message.CompletionMinutes,
+				UserRating:            message.UserRating,
+				Raters:                message.Raters,
+				Score:                 message.Score,
+				Seed:                  strconv.FormatInt(message.Seed, 10),
+			},
+		},
+	}
+}
+
+func journalEntryAttributes(userId string, entry result.JournalEntry) JournalEntryAttributes {
+	attributes := JournalEntryAttributes{
+		GameId:         entry.GameId,
+		GameName:       entry.GameName,
+		Text:           entry.Text,
+		Html:           entry.Html,
+		HasSpoilers:    entry.HasSpoilers,
+		SpoilersHidden: entry.SpoilersHidden,
+		CreatedAt:      timestamp(entry.CreatedAt),
+	}
+	if entry.HasScreenshot {
+		attributes.Screenshot = fmt.Sprintf("http://localhost:8080/users/%s/journal/%d/screenshot",
+			userId, entry.Id)
+	}
+	return attributes
+}
+
+func ViewJournalEntry(userId string, entry result.JournalEntry) JournalEntry {
+	return JournalEntry{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/journal/%d", userId, entry.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/journal", userId),
+		},
+		Data: JournalEntryData{
+			Type:       "journalEntries",
+			Id:         entry.Id,
+			Attributes: journalEntryAttributes(userId, entry),
+		},
+	}
+}
+
+func ViewJournal(message result.Journal) Journal {
+	data := []JournalEntryData{}
+	for _, entry := range message.Entries {
+		data = append(data, ViewJournalEntry(message.UserId, entry).Data)
+	}
+	return Journal{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/journal", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+		Meta: PageMeta{Limit: message.Limit, Cursor: message.Cursor, HasMore: message.HasMore},
+	}
+}
+
+func ViewPhysicalCopy(userId, libraryId string, physical result.PhysicalCopy) PhysicalCopy {
+	return PhysicalCopy{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/copies/%d",
+				userId, libraryId, physical.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games/%s",
+				userId, libraryId, physical.GameId),
+		},
+		Data: PhysicalCopyData{
+			Type: "copies",
+			Id:   physical.Id,
+			Attributes: PhysicalCopyAttributes{
+				GameId:        physical.GameId,
+				GameName:      physical.GameName,
+				Barcode:       physical.Barcode,
+				Platform:      physical.Platform,
+				PaidPrice:     physical.PaidPrice,
+				PaidCurrency:  physical.PaidCurrency,
+				Value:         physical.Value,
+				ValueCurrency: physical.ValueCurrency,
+				ValuedAt:      timestamp(physical.ValuedAt),
+				CreatedAt:     timestamp(physical.CreatedAt),
+			},
+		},
+	}
+}
+
+func ViewPhysicalCopies(message result.PhysicalCopies) PhysicalCopies {
+	data := []PhysicalCopyData{}
+	for _, physical := range message.Copies {
+		data = append(data, ViewPhysicalCopy(message.UserId, message.LibraryId, physical).Data)
+	}
+	return PhysicalCopies{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/copies",
+				message.UserId, message.LibraryId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s",
+				message.UserId, message.LibraryId),
+		},
+		Data: data,
+		Meta: PageMeta{Limit: message.Limit, Cursor: message.Cursor, HasMore: message.HasMore},
+	}
+}
+
+func ViewCollectionWorth(message result.CollectionWorth) CollectionWorth {
+	attributes := CollectionWorthAttributes{
+		Paid:     message.Paid,
+		Value:    message.Value,
+		Unpaid:   message.Unpaid,
+		Unvalued: message.Unvalued,
+		Copies:   []PhysicalCopyData{},
+	}
+	for _, physical := range message.Copies {
+		attributes.Copies = append(attributes.Copies,
+			ViewPhysicalCopy(message.UserId, message.LibraryId, physical).Data)
+	}
+	return CollectionWorth{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/worth",
+				message.UserId, message.LibraryId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/copies",
+				message.UserId, message.LibraryId),
+		},
+		Data: CollectionWorthData{Type: "collectionWorth", Attributes: attributes},
+	}
+}
+
+func ViewPhotoImport(message result.PhotoImport) PhotoImport {
+	attributes := PhotoImportAttributes{
+		Status:    message.Status,
+		Reason:    message.Reason,
+		Photos:    message.Photos,
+		Proposals: []PhotoProposalAttributes{},
+		CreatedAt: timestamp(message.CreatedAt),
+		UpdatedAt: timestamp(message.UpdatedAt),
+	}
+	for _, proposal := range message.Proposals {
+		attributes.Proposals = append(attributes.Proposals, PhotoProposalAttributes{
+			Id:         proposal.Id,
+			Photo:      proposal.Photo,
+			Title:      proposal.Title,
+			Confidence: proposal.Confidence,
+			Matched:    proposal.Matched,
+			Reason:     proposal.Reason,
+			Accepted:   proposal.Accepted,
+		})
+	}
+	return PhotoImport{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/photos/%d",
+				message.UserId, message.LibraryId, message.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/copies",
+				message.UserId, message.LibraryId),
+		},
+		Data: PhotoImportData{Type: "photoImports", Id: message.Id, Attributes: attributes},
+	}
+}
+
+func ViewShareLink(link result.ShareLink) ShareLink {
+	attributes := ShareLinkAttributes{
+		HasPassword: link.HasPassword,
+		ExpiresAt:   timestamp(link.ExpiresAt),
+		CreatedAt:   timestamp(link.CreatedAt),
+	}
+	if link.Token != "" {
+		attributes.Url = fmt.Sprintf("http://localhost:8080/shared/%s", link.Token)
+	}
+	return ShareLink{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/shares/%d",
+				link.UserId, link.LibraryId, link.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s",
+				link.UserId, link.LibraryId),
+		},
+		Data: ShareLinkData{Type: "shareLinks", Id: link.Id, Attributes: attributes},
+	}
+}
+
+func ViewShareLinks(message result.ShareLinks) ShareLinks {
+	data := []ShareLinkData{}
+	for _, link := range message.Links {
+		data = append(data, ViewShareLink(link).Data)
+	}
+	return ShareLinks{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/shares",
+				message.UserId, message.LibraryId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s",
+				message.UserId, message.LibraryId),
+		},
+		Data: data,
+	}
+}
+
+func ViewSharedLibrary(message result.SharedLibrary) SharedLibrary {
+	attributes := SharedLibraryAttributes{
+		Owner:     message.OwnerName,
+		ExpiresAt: timestamp(message.ExpiresAt),
+		Games:     []SharedGame{},
+	}
+	for _, game := range message.Games {
+		attributes.Games = append(attributes.Games, SharedGame{Name: game.Name, Producer: game.Producer,
+			Rating: game.Rating, Status: game.Status, Platform: game.Platform, Tags: game.Tags})
+	}
+	return SharedLibrary{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/shared/%s", message.Token),
+		},
+		Data: SharedLibraryData{Type: "sharedLibraries", Attributes: attributes},
+	}
+}
+
+func ViewLibraryMember(userId, libraryId string, member result.LibraryMember) LibraryMember {
+	return LibraryMember{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/members/%s",
+				userId, libraryId, member.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s", userId, libraryId),
+		},
+		Data: LibraryMemberData{
+			Type: "members",
+			Id:   member.UserId,
+			Attributes: LibraryMemberAttributes{
+				UserName:  member.UserName,
+				Role:      member.Role,
+				CreatedAt: timestamp(member.CreatedAt),
+			},
+		},
+	}
+}
+
+func ViewLibraryMembers(message result.LibraryMembers) LibraryMembers {
+	data := []LibraryMemberData{}
+	for _, member := range message.Members {
+		data = append(data, ViewLibraryMember(message.UserId, message.LibraryId, member).Data)
+	}
+	return LibraryMembers{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/members",
+				message.UserId, message.LibraryId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s",
+				message.UserId, message.LibraryId),
+		},
+		Data: data,
+	}
+}
+
+// Shared libraries are reached under the member's own user, like their own
+func ViewMemberships(message result.Memberships) Memberships {
+	data := []MembershipData{}
+	for _, membership := range message.Libraries {
+		data = append(data, MembershipData{Type: "memberships", Id: membership.LibraryId,
+			Attributes: MembershipAttributes{Role: membership.Role,
+				CreatedAt: timestamp(membership.CreatedAt)}})
+	}
+	return Memberships{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/memberships", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func ViewTradeOffer(offer result.TradeOffer) TradeOffer {
+	offered, requested := offer.Offered, offer.Requested
+	if offered == nil {
+		offered = []int{}
+	}
+	if requested == nil {
+		requested = []int{}
+	}
+	return TradeOffer{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/trades/%d", offer.UserId, offer.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/trades", offer.UserId),
+		},
+		Data: TradeOfferData{
+			Type: "trades",
+			Id:   offer.Id,
+			Attributes: TradeOfferAttributes{
+				ProposerId:         offer.ProposerId,
+				ProposerLibraryId:  offer.ProposerLibraryId,
+				RecipientId:        offer.RecipientId,
+				RecipientLibraryId: offer.RecipientLibraryId,
+				Offered:            offered,
+				Requested:          requested,
+				Status:             offer.Status,
+				CounterOf:          offer.CounterOf,
+				CreatedAt:          timestamp(offer.CreatedAt),
+				UpdatedAt:          timestamp(offer.UpdatedAt),
+			},
+		},
+	}
+}
+
+func ViewTradeOffers(message result.TradeOffers) TradeOffers {
+	data := []TradeOfferData{}
+	for _, offer := range message.Offers {
+		data = append(data, ViewTradeOffer(offer).Data)
+	}
+	return TradeOffers{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/trades", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+		Meta: PageMeta{Limit: message.Limit, Cursor: message.Cursor, HasMore: message.HasMore},
+	}
+}
+
+func ViewGoal(goal result.Goal) Goal {
+	return Goal{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/goals/%d", goal.UserId, goal.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/goals", goal.UserId),
+		},
+		Data: GoalData{
+			Type: "goals",
+			Id:   goal.Id,
+			Attributes: GoalAttributes{
+				Kind:      goal.Kind,
+				Target:    goal.Target,
+				Period:    goal.Period,
+				Year:      goal.Year,
+				Quarter:   goal.Quarter,
+				StartsAt:  timestamp(goal.StartsAt),
+				EndsAt:    timestamp(goal.EndsAt),
+				Current:   goal.Current,
+				Expected:  goal.Expected,
+				Achieved:  goal.Achieved,
+				Behind:    goal.Behind,
+				CreatedAt: timestamp(goal.CreatedAt),
+			},
+		},
+	}
+}
+
+func ViewGoals(message result.Goals) Goals {
+	data := []GoalData{}
+	for _, goal := range message.Goals {
+		data = append(data, ViewGoal(goal).Data)
+	}
+	return Goals{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/goals", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func ViewHardware(item result.Hardware) Hardware {
+	return Hardware{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/hardware/%d", item.UserId, item.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/hardware", item.UserId),
+		},
+		Data: HardwareData{
+			Type: "hardware",
+			Id:   item.Id,
+			Attributes: HardwareAttributes{
+				Kind:          item.Kind,
+				Name:          item.Name,
+				Model:         item.Model,
+				Serial:        item.Serial,
+				PurchasedOn:   item.PurchasedOn,
+				WarrantyUntil: item.WarrantyUntil,
+				UnderWarranty: item.UnderWarranty,
+				CreatedAt:     timestamp(item.CreatedAt),
+				UpdatedAt:     timestamp(item.UpdatedAt),
+			},
+		},
+	}
+}
+
+func ViewHardwareList(message result.HardwareList) HardwareList {
+	data := []HardwareData{}
+	for _, item := range message.Items {
+		data = append(data, ViewHardware(item).Data)
+	}
+	return HardwareList{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/hardware", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func ViewSubscription(subscription result.Subscription) Subscription {
+	games := subscription.GameIds
+	if games == nil {
+		games = []string{}
+	}
+	return Subscription{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/subscriptions/%d", subscription.UserId,
+				subscription.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/subscriptions", subscription.UserId),
+		},
+		Data: SubscriptionData{
+			Type: "subscriptions",
+			Id:   subscription.Id,
+			Attributes: SubscriptionAttributes{
+				Service:       subscription.Service,
+				MonthlyCost:   subscription.MonthlyCost,
+				Currency:      subscription.Currency,
+				BillingMonths: subscription.BillingMonths,
+				StartedOn:     subscription.StartedOn,
+				RenewsOn:      subscription.RenewsOn,
+				EndsOn:        subscription.EndsOn,
+				Games:         games,
+				CreatedAt:     timestamp(subscription.CreatedAt),
+				UpdatedAt:     timestamp(subscription.UpdatedAt),
+			},
+		},
+	}
+}
+
+func ViewSubscriptions(message result.Subscriptions) Subscriptions {
+	data := []SubscriptionData{}
+	for _, subscription := range message.Subscriptions {
+		data = append(data, ViewSubscription(subscription).Data)
+	}
+	return Subscriptions{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/subscriptions", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func ViewSubscriptionReport(report result.SubscriptionReport) SubscriptionReport {
+	usages := []SubscriptionUsage{}
+	for _, usage := range report.Subscriptions {
+		usages = append(usages, SubscriptionUsage{
+			SubscriptionId: usage.Subscription.Id,
+			Service:        usage.Subscription.Service,
+			Currency:       usage.Subscription.Currency,
+			Cost:           usage.Cost,
+			Minutes:        usage.Minutes,
+			Hours:          hours(usage.Minutes),
+			CostPerHour:    usage.CostPerHour,
+			Games:          gameMinutes(usage.Games),
+		})
+	}
+	return SubscriptionReport{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/subscriptions/report", report.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/subscriptions", report.UserId),
+		},
+		Data: SubscriptionReportData{
+			Type: "subscription-reports",
+			Id:   report.UserId,
+			Attributes: SubscriptionReportAttributes{
+				From:          timestamp(report.From),
+				To:            timestamp(report.To),
+				Cost:          report.Cost,
+				Minutes:       report.Minutes,
+				Hours:         hours(report.Minutes),
+				Subscriptions: usages,
+			},
+		},
+	}
+}
+
+func modAttributes(mod result.Mod) ModAttributes {
+	return ModAttributes{
+		GameId:    mod.GameId,
+		GameName:  mod.GameName,
+		Name:      mod.Name,
+		SourceUrl: mod.SourceUrl,
+		Version:   mod.Version,
+		Enabled:   mod.Enabled,
+		CreatedAt: timestamp(mod.CreatedAt),
+		UpdatedAt: timestamp(mod.UpdatedAt),
+	}
+}
+
+func ViewMod(mod result.Mod) Mod {
+	return Mod{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games/%s/mods/%d",
+				mod.UserId, mod.LibraryId, mod.GameId, mod.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games/%s",
+				mod.UserId, mod.LibraryId, mod.GameId),
+		},
+		Data: ModData{Type: "mods", Id: mod.Id, Attributes: modAttributes(mod)},
+	}
+}
+
+func ViewMods(message result.Mods) Mods {
+	data := []ModData{}
+	for _, mod := range message.Mods {
+		data = append(data, ViewMod(mod).Data)
+	}
+	return Mods{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games/%s/mods",
+				message.UserId, message.LibraryId, message.GameId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games/%s",
+				message.UserId, message.LibraryId, message.GameId),
+		},
+		Data: data,
+	}
+}
+
+func ViewModImportReport(report result.ModImportReport) ModImportReport {
+	imported := []ModData{}
+	for _, mod := range report.Imported {
+		imported = append(imported, ViewMod(mod).Data)
+	}
+	unmatched := report.Unmatched
+	if unmatched == nil {
+		unmatched = []string{}
+	}
+	return ModImportReport{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/mods/import",
+				report.UserId, report.LibraryId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games",
+				report.UserId, report.LibraryId),
+		},
+		Data: ModImportData{
+			Type: "mod-imports",
+			Attributes: ModImportAttributes{
+				Imported:  imported,
+				Skipped:   report.Skipped,
+				Unmatched: unmatched,
+			},
+		},
+	}
+}
+
+func ViewSaveBackup(backup result.SaveBackup) SaveBackup {
+	return SaveBackup{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games/%s/backups/%d",
+				backup.UserId, backup.LibraryId, backup.GameId, backup.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games/%s",
+				backup.UserId, backup.LibraryId, backup.GameId),
+		},
+		Data: SaveBackupData{
+			Type: "save-backups",
+			Id:   backup.Id,
+			Attributes: SaveBackupAttributes{
+				GameId:     backup.GameId,
+				GameName:   backup.GameName,
+				Location:   backup.Location,
+				Size:       backup.Size,
+				Checksum:   backup.Checksum,
+				BackedUpAt: timestamp(backup.BackedUpAt),
+				CreatedAt:  timestamp(backup.CreatedAt),
+			},
+		},
+	}
+}
+
+func ViewSaveBackups(message result.SaveBackups) SaveBackups {
+	data := []SaveBackupData{}
+	for _, backup := range message.Backups {
+		data = append(data, ViewSaveBackup(backup).Data)
+	}
+	return SaveBackups{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games/%s/backups",
+				message.UserId, message.LibraryId, message.GameId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games/%s",
+				message.UserId, message.LibraryId, message.GameId),
+		},
+		Data: data,
+	}
+}
+
+func speedRunData(run result.SpeedRun) SpeedRunData {
+	return SpeedRunData{
+		Type: "speedruns",
+		Id:   run.Id,
+		Attributes: SpeedRunAttributes{
+			Runner:       run.Runner,
+			GameId:       run.GameId,
+			GameName:     run.GameName,
+			Category:     run.Category,
+			Milliseconds: run.Milliseconds,
+			Time:         run.Time,
+			RunAt:        timestamp(run.RunAt),
+			PersonalBest: run.PersonalBest,
+		},
+	}
+}
+
+func ViewSpeedRun(run result.SpeedRun) SpeedRun {
+	return SpeedRun{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/speedruns/%s/%d", run.UserId, run.GameId,
+				run.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/speedruns/%s", run.UserId, run.GameId),
+		},
+		Data: speedRunData(run),
+	}
+}
+
+func ViewSpeedRuns(message result.SpeedRuns) SpeedRuns {
+	data := []SpeedRunData{}
+	for _, run := range message.Runs {
+		data = append(data, speedRunData(run))
+	}
+	self := fmt.Sprintf("http://localhost:8080/users/%s/speedruns", message.UserId)
+	if message.GameId != "" {
+		self += "/" + message.GameId
+	}
+	runs := SpeedRuns{
+		Links: Links{
+			Self:    self,
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+	}
+	if message.Limit > 0 {
+		runs.Meta = &PageMeta{Limit: message.Limit, Cursor: message.Cursor, HasMore: message.HasMore}
+	}
+	return runs
+}
+
+func ViewSpeedrunComparisons(message result.SpeedrunComparisons) SpeedrunComparisons {
+	data := []SpeedrunComparisonData{}
+	for _, comparison := range message.Comparisons {
+		compared := SpeedrunComparisonData{Category: comparison.Category, Runs: []ComparedRun{}}
+		if comparison.Own != nil {
+			own := speedRunData(*comparison.Own)
+			compared.Own = &own
+		}
+		for i, run := range comparison.Runs {
+			entry := ComparedRun{SpeedRunData: speedRunData(run)}
+			if comparison.Own != nil {
+				entry.Behind = &comparison.Behind[i]
+			}
+			compared.Runs = append(compared.Runs, entry)
+		}
+		data = append(data, compared)
+	}
+	return SpeedrunComparisons{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/speedruns/%s/compare", message.UserId,
+				message.GameId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/speedruns/%s", message.UserId,
+				message.GameId),
+		},
+		Data: data,
+	}
+}
+
+func ViewMatches(message result.Matches) Matches {
+	data := []MatchData{}
+	for _, match := range message.Matches {
+		if match.Opponents == nil {
+			match.Opponents = []string{}
+		}
+		data = append(data, MatchData{Type: "matches", Id: match.Id, Attributes: match})
+	}
+	return Matches{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/matches", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/matches/stats", message.UserId),
+		},
+		Data: data,
+		Meta: PageMeta{Limit: message.Limit, Cursor: message.Cursor, HasMore: message.HasMore},
+	}
+}
+
+func ViewMatchBatch(batch result.MatchBatch) MatchBatch {
+	items := batch.Items
+	if items == nil {
+		items = []result.MatchBatchItem{}
+	}
+	return MatchBatch{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/matches", batch.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/matches/stats", batch.UserId),
+		},
+		Data: items,
+	}
+}
+
+func ViewMatchStats(stats result.MatchStats) MatchStats {
+	if stats.Games == nil {
+		stats.Games = []result.GameMatchRecord{}
+	}
+	return MatchStats{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/matches/stats", stats.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/matches", stats.UserId),
+		},
+		Data: MatchStatsData{Type: "match-stats", Attributes: stats},
+	}
+}
+
+func ViewHeadToHeads(message result.HeadToHeads) HeadToHeads {
+	records := message.Records
+	if records == nil {
+		records = []result.HeadToHead{}
+	}
+	return HeadToHeads{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/matches/opponents", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/matches", message.UserId),
+		},
+		Data: records,
+	}
+}
+
+func agentData(agent result.Agent) AgentData {
+	return AgentData{
+		Type: "agents",
+		Id:   agent.Id,
+		Attributes: AgentAttributes{
+			Name:       agent.Name,
+			Token:      agent.Token,
+			LastSeenAt: timestamp(agent.LastSeenAt),
+			CreatedAt:  timestamp(agent.CreatedAt),
+		},
+	}
+}
+
+func ViewAgent(agent result.Agent) Agent {
+	return Agent{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/agents/%d", agent.UserId, agent.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/executables", agent.UserId),
+		},
+		Data: agentData(agent),
+	}
+}
+
+func ViewAgents(message result.Agents) Agents {
+	data := []AgentData{}
+	for _, agent := range message.Agents {
+		data = append(data, agentData(agent))
+	}
+	return Agents{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/agents", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/executables", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func executableData(executable result.Executable) ExecutableData {
+	return ExecutableData{
+		Type: "executables",
+		Id:   executable.Executable,
+		Attributes: ExecutableAttributes{
+			GameId:   executable.GameId,
+			GameName: executable.GameName,
+		},
+	}
+}
+
+func ViewExecutable(executable result.Executable) Executable {
+	return Executable{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/executables/%s", executable.UserId,
+				url.PathEscape(executable.Executable)),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/executables", executable.UserId),
+		},
+		Data: executableData(executable),
+	}
+}
+
+func ViewExecutables(message result.Executables) Executables {
+	data := []ExecutableData{}
+	for _, executable := range message.Executables {
+		data = append(data, executableData(executable))
+	}
+	return Executables{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/executables", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/agents", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func ViewAgentReport(report result.AgentReport) AgentReport {
+	attributes := AgentReportAttributes{
+		Executable: report.Executable,
+		Event:      report.Event,
+		Mapped:     report.Mapped,
+		GameId:     report.GameId,
+		GameName:   report.GameName,
+	}
+	if report.Session != nil {
+		session := sessionData(*report.Session)
+		attributes.Session = &session
+	}
+	return AgentReport{Data: AgentReportData{Type: "agent-reports", Attributes: attributes}}
+}
+
+func webhookData(webhook result.Webhook) WebhookData {
+	data := WebhookData{
+		Type: "webhooks",
+		Id:   webhook.Id,
+		Attributes: WebhookAttributes{
+			Name:            webhook.Name,
+			Action:          webhook.Action,
+			LibraryId:       webhook.LibraryId,
+			Secret:          webhook.Secret,
+			LastDeliveredAt: timestamp(webhook.LastDeliveredAt),
+			CreatedAt:       timestamp(webhook.CreatedAt),
+		},
+	}
+	if webhook.Key != "" {
+		data.Attributes.Url = fmt.Sprintf("http://localhost:8080/webhooks/%s", webhook.Key)
+	}
+	return data
+}
+
+func ViewWebhook(webhook result.Webhook) Webhook {
+	return Webhook{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/webhooks/%d", webhook.UserId, webhook.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/notifications", webhook.UserId),
+		},
+		Data: webhookData(webhook),
+	}
+}
+
+func ViewWebhooks(message result.Webhooks) Webhooks {
+	data := []WebhookData{}
+	for _, webhook := range message.Webhooks {
+		data = append(data, webhookData(webhook))
+	}
+	return Webhooks{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/webhooks", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/notifications", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func ruleData(rule result.Rule) RuleData {
+	return RuleData{
+		Type: "rules",
+		Id:   rule.Id,
+		Attributes: RuleAttributes{
+			Name:      rule.Name,
+			Event:     rule.Event,
+			Condition: rule.Condition,
+			Actions:   rule.Actions,
+			Enabled:   rule.Enabled,
+			DryRun:    rule.DryRun,
+			CreatedAt: timestamp(rule.CreatedAt),
+			UpdatedAt: timestamp(rule.UpdatedAt),
+		},
+	}
+}
+
+func ViewRule(rule result.Rule) Rule {
+	return Rule{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/rules/%d", rule.UserId, rule.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/rules/%d/runs", rule.UserId, rule.Id),
+		},
+		Data: ruleData(rule),
+	}
+}
+
+func ViewRules(message result.Rules) Rules {
+	data := []RuleData{}
+	for _, rule := range message.Rules {
+		data = append(data, ruleData(rule))
+	}
+	return Rules{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/rules", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func ViewRuleRuns(message result.RuleRuns) RuleRuns {
+	data := []RuleRunData{}
+	for _, run := range message.Runs {
+		actions := run.Actions
+		if actions == nil {
+			actions = []string{}
+		}
+		data = append(data, RuleRunData{
+			Type: "rule-runs",
+			Id:   run.Id,
+			Attributes: RuleRunAttributes{
+				Event:     run.Event,
+				EntityId:  run.EntityId,
+				Actions:   actions,
+				DryRun:    run.DryRun,
+				Error:     run.Error,
+				CreatedAt: timestamp(run.CreatedAt),
+			},
+		})
+	}
+	return RuleRuns{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/rules/%d/runs", message.UserId, message.RuleId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/rules/%d", message.UserId, message.RuleId),
+		},
+		Data: data,
+	}
+}
+
+func ViewRuleTest(test result.RuleTest) RuleTest {
+	actions := test.Actions
+	if actions == nil {
+		actions = []string{}
+	}
+	return RuleTest{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/rules/test", test.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/rules", test.UserId),
+		},
+		Data: RuleTestData{
+			Type:       "rule-tests",
+			Attributes: RuleTestAttributes{Matched: test.Matched, Actions: actions},
+		},
+	}
+}
+
+func scriptData(script result.Script) ScriptData {
+	return ScriptData{
+		Type: "scripts",
+		Id:   script.Id,
+		Attributes: ScriptAttributes{
+			Name:      script.Name,
+			Event:     script.Event,
+			Source:    script.Source,
+			UserId:    script.UserId,
+			Enabled:   script.Enabled,
+			CreatedBy: script.CreatedBy,
+			CreatedAt: timestamp(script.CreatedAt),
+			UpdatedAt: timestamp(script.UpdatedAt),
+		},
+	}
+}
+
+func ViewScript(script result.Script) Script {
+	return Script{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/admin/scripts/%d", script.Id),
+			Related: "http://localhost:8080/admin/scripts",
+		},
+		Data: scriptData(script),
+	}
+}
+
+func ViewScripts(message result.Scripts) Scripts {
+	data := []ScriptData{}
+	for _, script := range message.Scripts {
+		data = append(data, scriptData(script))
+	}
+	return Scripts{
+		Links: Links{
+			Self: "http://localhost:8080/admin/scripts",
+		},
+		Data: data,
+	}
+}
+
+func ViewTags(message result.Tags) Tags {
+	data := []TagData{}
+	for _, tag := range message.Tags {
+		data = append(data, TagData{Type: "tags", Id: tag.Tag, Attributes: TagAttributes{Games: tag.Games}})
+	}
+	return Tags{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/tags", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func ViewTagChange(change result.TagChange) TagChange {
+	return TagChange{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/tags", change.UserId),
+		},
+		Data: TagChangeData{
+			Type: "tag-changes",
+			Attributes: TagChangeAttributes{
+				From:      change.From,
+				To:        change.To,
+				Games:     change.Games,
+				Libraries: change.Libraries,
+			},
+		},
+	}
+}
+
+func savedSearchData(search result.SavedSearch) SavedSearchData {
+	attributes := SavedSearchAttributes{
+		Name:      search.Name,
+		Filter:    SearchFilter(search.Filter),
+		Shared:    search.Shared,
+		CreatedAt: timestamp(search.CreatedAt),
+		UpdatedAt: timestamp(search.UpdatedAt),
+	}
+	if search.Token != "" {
+		attributes.Url = fmt.Sprintf("http://localhost:8080/searches/%s", search.Token)
+	}
+	return SavedSearchData{Type: "searches", Id: search.Id, Attributes: attributes}
+}
+
+func ViewSavedSearch(search result.SavedSearch) SavedSearch {
+	return SavedSearch{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/searches/%d", search.UserId, search.Id),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/searches", search.UserId),
+		},
+		Data: savedSearchData(search),
+	}
+}
+
+func ViewSavedSearches(message result.SavedSearches) SavedSearches {
+	data := []SavedSearchData{}
+	for _, search := range message.Searches {
+		data = append(data, savedSearchData(search))
+	}
+	return SavedSearches{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/searches", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func ViewSharedSearch(message result.SharedSearch) SharedSearch {
+	return SharedSearch{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/searches/%s", message.Token),
+		},
+		Data: SharedSearchData{
+			Type:       "sharedSearches",
+			Attributes: SharedSearchAttributes{Name: message.Name, Filter: SearchFilter(message.Filter)},
+		},
+	}
+}
+
+func ViewGameTree(tree result.GameTree) GameTree {
+	addons := []GameAddon{}
+	for _, addon := range tree.Addons {
+		addons = append(addons, GameAddon{GameId: addon.GameId, Name: addon.Name, Kind: addon.Kind,
+			Status: addon.Status, Value: addon.Value})
+	}
+	return GameTree{
+		Links: Links{
+			Self: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games/%s/addons",
+				tree.UserId, tree.LibraryId, tree.GameId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/libraries/%s/games/%s",
+				tree.UserId, tree.LibraryId, tree.GameId),
+		},
+		Data: GameTreeData{
+			Type: "game-trees",
+			Id:   tree.GameId,
+			Attributes: GameTreeAttributes{
+				Name:       tree.Name,
+				Status:     tree.Status,
+				Value:      tree.Value,
+				Addons:     addons,
+				TotalValue: tree.TotalValue,
+				Completed:  tree.Completed,
+				Total:      tree.Total,
+				Completion: tree.Completion,
+			},
+		},
+	}
+}
+
+func ViewCatalogGames(message result.CatalogGames) CatalogGames {
+	data := []CatalogGameData{}
+	for _, game := range message.Games {
+		catalogs := []CatalogAvailability{}
+		for _, catalog := range game.Catalogs {
+			catalogs = append(catalogs, CatalogAvailability{Service: catalog.Service,
+				LeavesOn: catalog.LeavesOn})
+		}
+		data = append(data, CatalogGameData{
+			Type: "catalog-games",
+			Id:   game.GameId,
+			Attributes: CatalogGameAttributes{
+				Name:     game.GameName,
+				Status:   game.Status,
+				Catalogs: catalogs,
+			},
+		})
+	}
+	return CatalogGames{
+		Links: Links{
+			Self:    fmt.Sprintf("http://localhost:8080/users/%s/catalog", message.UserId),
+			Related: fmt.Sprintf("http://localhost:8080/users/%s/subscriptions", message.UserId),
+		},
+		Data: data,
+	}
+}
+
+func ViewProfileExport(message result.ProfileExport) ProfileExport {
+	export := ProfileExport{
+		User: ExportedUser{
+			Id:        message.UserId,
+			Name:      message.UserName,
+			CreatedAt: timestamp(message.CreatedAt),
+		},
+		Libraries:  []ExportedLibrary{},
+		Journal:    []JournalEntryAttributes{},
+		ExportedAt: timestamp(message.ExportedAt),
+	}
+	for _, library := range message.Libraries {
+		games := library.Games
+		if games == nil {
+			games = []result.Game{}
+		}
+		mods := []ModAttributes{}
+		for _, mod := range library.Mods {
+			mods = append(mods, modAttributes(mod))
+		}
+		export.Libraries = append(export.Libraries, ExportedLibrary{Id: library.Id, Games: games,
+			Mods: mods})
+	}
+	for _, entry := range message.Journal {
+		export.Journal = append(export.Journal, journalEntryAttributes(message.UserId, entry))
+	}
+	return export
+}
+
+func ViewRendered(message result.Rendered) Rendered {
+	return Rendered{
+		Data: RenderedData{
+			Type:       "renderedTexts",
+			Attributes: map[string]string{"html": message.Html},
+		},
+	}
+}
+
+// Journal html was sanitized when it was rendered
+var profileExportPage = template.Must(template.New("export").Funcs(template.FuncMap{
+	"sanitized": func(html string) template.HTML { return template.HTML(html) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.User.Name}} - Game Tracker export</title>
+</head>
+<body>
+<h1>{{.User.Name}}</h1>
+<p>Exported {{.ExportedAt}}</p>
+{{range .Libraries}}<h2>Library {{.Id}}</h2>
+<table>
+<tr><th>Name</th><th>Producer</th><th>Status</th><th>Platform</th><th>Tags</th></tr>
+{{range .Games}}<tr><td>{{.Name}}</td><td>{{.Producer}}</td><td>{{.Status}}</td><td>{{.Platform}}</td><td>{{range $i, $tag := .Tags}}{{if $i}}, {{end}}{{$tag}}{{end}}</td></tr>
+{{end}}</table>
+{{if .Mods}}<h3>Mods</h3>
+<table>
+<tr><th>Game</th><th>Mod</th><th>Version</th><th>Enabled</th><th>Source</th></tr>
+{{range .Mods}}<tr><td>{{.GameName}}</td><td>{{.Name}}</td><td>{{.Version}}</td><td>{{if .Enabled}}Yes{{else}}No{{end}}</td><td>{{.SourceUrl}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}<h2>Journal</h2>
+{{range .Journal}}<article>
+<h3>{{.GameName}} <time>{{.CreatedAt}}</time></h3>
+{{sanitized .Html}}
+{{if .Screenshot}}<img src="{{.Screenshot}}" alt="Screenshot of {{.GameName}}">{{end}}
+</article>
+{{else}}<p>No entries.</p>
+{{end}}</body>
+</html>
+`))
+
+// The same export as a single page, meant to be saved rather than served
+// as part of the site
+func ViewProfileExportHtml(message result.ProfileExport) ([]byte, error) {
+	var page bytes.Buffer
+	err := profileExportPage.Execute(&page, ViewProfileExport(message))
+	return page.Bytes(), err
+}
+
+// The token is only ever shown here, issuing a new one revokes the old link
+func ViewCalendarLink(message result.CalendarLink) CalendarLink {
+	return CalendarLink{
+		Links: Links{
+			Related: fmt.Sprintf("http://localhost:8080/users/%s", message.UserId),
+		},
+		Data: CalendarLinkData{
+			Type: "calendars",
+			Attributes: map[string]string{
+				"url": fmt.Sprintf("http://localhost:8080/calendar/%s.ics", message.Token),
+			},
+		},
+	}
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", "")
+
+// Renders the calendar as iCalendar (RFC 5545), sessions are timed events
+// and releases all-day ones
+func ViewCalendar(message result.Calendar) string {
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Game Tracker//Calendar//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:" + icalEscaper.Replace(message.UserName+" on Game Tracker"),
+	}
+	for _, session := range message.Sessions {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:session-%d@game-tracker", session.Id),
+			"DTSTAMP:"+stamp,
+			"DTSTART:"+session.StartsAt.UTC().Format("20060102T150405Z"),
+			"DTEND:"+session.EndsAt.UTC().Format("20060102T150405Z"),
+			"SUMMARY:"+icalEscaper.Replace("Play "+session.GameName),
+			"END:VEVENT")
+	}
+	for _, release := range message.Releases {
+		date, err := time.Parse("2006-01-02", release.Date)
+		if err != nil {
+			continue
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:release-%s@game-tracker", release.GameId),
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+date.Format("20060102"),
+			"DTEND;VALUE=DATE:"+date.AddDate(0, 0, 1).Format("20060102"),
+			"SUMMARY:"+icalEscaper.Replace(release.GameName+" releases"),
+			"TRANSP:TRANSPARENT",
+			"END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var calendar strings.Builder
+	for _, line := range lines {
+		calendar.WriteString(foldLine(line))
+		calendar.WriteString("\r\n")
+	}
+	return calendar.String()
+}
+
+// Lines longer than 75 octets are continued on lines starting with a space,
+// never splitting a UTF-8 sequence
+func foldLine(line string) string {
+	var folded strings.Builder
+	width := 0
+	for _, r := range line {
+		size := utf8.RuneLen(r)
+		if width+size > 75 {
+			folded.WriteString("\r\n ")
+			width = 1
+		}
+		folded.WriteRune(r)
+		width += size
+	}
+	return folded.String()
 }
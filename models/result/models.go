@@ -1,51 +1,1224 @@
 package result
 
+import (
+	"time"
+)
+
 type User struct {
-	Id         int    `json:"UserId"`
-	Name       string `json:"name"`
-	LibraryIds []int  `json:"libraryIds"`
+	Id         string    `json:"UserId"`
+	Name       string    `json:"name"`
+	LibraryIds []string  `json:"libraryIds"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type Token struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"` //Seconds until the access token expires
 }
 
 type UserAdd struct {
-	Id   int    `json:"userId"`
-	Name string `json:"name"`
+	Id         string `json:"userId"`
+	Name       string `json:"name"`
+	PlayerId   int    `json:"playerId"`
+	PlayerName string `json:"playerName"`
 }
 
 type UserDelete struct {
-	Id int `json:"userId"`
+	Id string `json:"userId"`
 }
 
 type UserInfo struct {
-	Id   int    `json:"userId"`
-	Info string `json:"userInfo"`
+	Id      string `json:"userId"`
+	Info    string `json:"userInfo"`
+	Version int64  `json:"version"`
 }
 
 type Game struct {
-	Id        int     `json:"gameId"`
-	LibraryId int     `json:"libraryId"`
-	UserId    int     `json:"userId"`
-	Name      string  `json:"name"`
-	Producer  string  `json:"producer"`
-	Value     float64 `json:"value"`
+	Id           string    `json:"gameId"`
+	LibraryId    string    `json:"libraryId"`
+	UserId       string    `json:"userId"`
+	Name         string    `json:"name"`
+	Producer     string    `json:"producer"`
+	Value        float64   `json:"value"`
+	MinAge       int       `json:"minAge"`
+	Rating       string    `json:"rating"`
+	Status       string    `json:"status"`
+	Platform     string    `json:"platform"`
+	Tags         []string  `json:"tags"`
+	WishlistRank int       `json:"wishlistRank"`
+	Version      int64     `json:"version"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type LibraryGames struct {
+	UserId    string
+	LibraryId string
+	Limit     int
+	Cursor    string //Of the next page
+	HasMore   bool
+	Games     []Game
+}
+
+type BatchItem struct {
+	GameId  string `json:"gameId"`
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Version int64  `json:"version"`
+}
+
+type GameBatch struct {
+	UserId    string      `json:"userId"`
+	LibraryId string      `json:"libraryId"`
+	Items     []BatchItem `json:"items"`
+}
+
+type ImportedGame struct {
+	Line     int    `json:"line"`
+	GameId   string `json:"gameId"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Platform string `json:"platform,omitempty"`
+	Rating   string `json:"rating,omitempty"`
+}
+
+// A row that was not imported, Reason is empty for skipped rows
+type ImportRow struct {
+	Line   int    `json:"line"`
+	Title  string `json:"title"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type ImportReport struct {
+	UserId    string         `json:"-"`
+	LibraryId string         `json:"-"`
+	Format    string         `json:"format"`
+	Imported  []ImportedGame `json:"imported"`
+	Skipped   []ImportRow    `json:"skipped"`
+	Unmatched []ImportRow    `json:"unmatched"`
 }
 
 type GameToLib struct {
-	Id        int `json:"gameId"`
-	LibraryId int `json:"libraryId"`
-	UserId    int `json:"userId"`
+	Id        string `json:"gameId"`
+	LibraryId string `json:"libraryId"`
+	UserId    string `json:"userId"`
 }
 
 type Library struct {
-	Id       int   `json:"libraryId"`
-	UserId   int   `json:"userId"`
-	GamesIds []int `json:"gameIds"`
+	Id        string    `json:"libraryId"`
+	UserId    string    `json:"userId"`
+	GamesIds  []string  `json:"gameIds"`
+	Version   int64     `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
 type LibraryAdd struct {
-	Id     int `json:"libraryId"`
-	UserId int `json:"userId"`
+	Id      string `json:"libraryId"`
+	UserId  string `json:"userId"`
+	Version int64  `json:"version"`
+}
+
+type LibraryVersion struct {
+	Id        string    `json:"libraryId"`
+	Version   int64     `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type LibraryVersions struct {
+	UserId    string           `json:"userId"`
+	Libraries []LibraryVersion `json:"libraries"`
 }
 
 type LibraryDelete struct {
-	Id int `json:"libraryId"`
+	Id string `json:"libraryId"`
+}
+
+type Notification struct {
+	Id        int       `json:"notificationId"`
+	Kind      string    `json:"kind"`
+	Message   string    `json:"message"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Notifications struct {
+	UserId        string         `json:"userId"`
+	Limit         int            `json:"limit"`
+	Cursor        string         `json:"cursor"` //Of the next page
+	HasMore       bool           `json:"hasMore"`
+	Unread        int            `json:"unread"`
+	Notifications []Notification `json:"notifications"`
+}
+
+type NotificationsRead struct {
+	UserId string `json:"userId"`
+	Ids    []int  `json:"notificationIds"`
+}
+
+type Settings struct {
+	UserId           string `json:"userId"`
+	DisplayCurrency  string `json:"displayCurrency"`
+	Timezone         string `json:"timezone"`
+	NotifyLibraries  bool   `json:"notifyLibraries"`
+	NotifyGames      bool   `json:"notifyGames"`
+	DefaultLibraryId string `json:"defaultLibraryId"`
+	ProfilePublic    bool   `json:"profilePublic"`
+	LibrariesPublic  bool   `json:"librariesPublic"`
+	RatingLimit      int    `json:"ratingLimit"`
+	Locale           string `json:"locale"`
+}
+
+type Change struct {
+	Entity    string    `json:"entity"`
+	EntityId  string    `json:"entityId"`
+	ParentId  string    `json:"parentId"`
+	Action    string    `json:"action"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+// Pushed to change watchers, Cursor resumes Sync after the change
+type WatchedChange struct {
+	Cursor string `json:"cursor"`
+	Change Change `json:"change"`
+}
+
+type Sync struct {
+	UserId  string   `json:"userId"`
+	Cursor  string   `json:"cursor"`
+	HasMore bool     `json:"hasMore"`
+	Changes []Change `json:"changes"`
+}
+
+type LibraryEvents struct {
+	UserId    string
+	LibraryId string
+	Limit     int
+	Cursor    string //Of the next page
+	HasMore   bool
+	Events    []LibraryEvent
+}
+
+// Games holds the entries added, the games removed or changed or the ranks
+// set, by kind of event
+type LibraryEvent struct {
+	Sequence   int64
+	Kind       string
+	Games      []LibraryEventGame
+	Status     *string
+	Platform   *string
+	Tags       *[]string
+	From       []string
+	To         string
+	RecordedAt time.Time
+}
+
+type LibraryEventGame struct {
+	GameId       string   `json:"gameId"` //Empty once the game was removed
+	Name         string   `json:"name,omitempty"`
+	Status       string   `json:"status,omitempty"`
+	Platform     string   `json:"platform,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	WishlistRank int      `json:"wishlistRank,omitempty"`
+}
+
+// Detailed is false when only the change log knew the library then, its
+// games come without status, platform or tags
+type LibraryAsOf struct {
+	UserId    string
+	LibraryId string
+	At        time.Time
+	Detailed  bool
+	Games     []LibraryEventGame
+}
+
+type LibraryDiff struct {
+	UserId    string
+	LibraryId string
+	From      time.Time
+	To        time.Time
+	Detailed  bool
+	Added     []LibraryEventGame
+	Removed   []LibraryEventGame
+	Changed   []LibraryEntryChange
+}
+
+type LibraryEntryChange struct {
+	Before LibraryEventGame `json:"before"`
+	After  LibraryEventGame `json:"after"`
+}
+
+// Before and After hold the entries of the action's games, games missing
+// from one were not in the library then
+type UndoAction struct {
+	Id        int64
+	Kind      string
+	LibraryId string
+	Before    []LibraryEventGame
+	After     []LibraryEventGame
+	CreatedAt time.Time
+	UndoneAt  time.Time
+}
+
+type UndoStack struct {
+	UserId  string
+	Actions []UndoAction
+}
+
+// LibraryId is empty for removed libraries, restoring one sets it to the
+// new library
+type TrashItem struct {
+	Id        int64
+	Kind      string
+	LibraryId string
+	Games     []LibraryEventGame
+	RemovedAt time.Time
+}
+
+type Trash struct {
+	UserId string
+	Items  []TrashItem
+}
+
+type LibraryReplay struct {
+	LibraryId string `json:"libraryId"`
+	Sequence  int64  `json:"sequence"` //Of the last event replayed
+	Games     int    `json:"games"`
+}
+
+type AdminUser struct {
+	Id             string     `json:"userId"`
+	Name           string     `json:"name"`
+	Role           string     `json:"role"`
+	Status         string     `json:"status"`
+	StatusReason   string     `json:"statusReason"`
+	SuspendedUntil *time.Time `json:"suspendedUntil"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+type AdminUsers struct {
+	Users   []AdminUser `json:"users"`
+	Limit   int         `json:"limit"`
+	Cursor  string      `json:"cursor"` //Of the next page
+	HasMore bool        `json:"hasMore"`
+}
+
+type UserStats struct {
+	UserId        string `json:"userId"`
+	Libraries     int    `json:"libraries"`
+	Games         int    `json:"games"`
+	Notifications int    `json:"notifications"`
+	Changes       int    `json:"changes"`
+	InfoBytes     *int   `json:"infoBytes"`
+}
+
+type Metrics struct {
+	Users         int            `json:"users"`
+	Players       int            `json:"players"`
+	Libraries     int            `json:"libraries"`
+	Games         int            `json:"games"`
+	GameEntries   int            `json:"gameEntries"`
+	UsersByStatus map[string]int `json:"usersByStatus"`
+}
+
+type IntegrityFinding struct {
+	Check       string `json:"check"`
+	Description string `json:"description"`
+	Repair      string `json:"repair"`
+	Count       int    `json:"count"`
+	Ids         []int  `json:"ids"` //A sample when Count is larger
+	Repaired    int    `json:"repaired"`
+}
+
+type Integrity struct {
+	Repaired bool
+	Findings []IntegrityFinding
+}
+
+type Maintenance struct {
+	Enabled    bool   `json:"enabled"`
+	RetryAfter int    `json:"retryAfter"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+type Diagnostics struct {
+	Goroutines    int                `json:"goroutines"`
+	HeapBytes     uint64             `json:"heapBytes"`
+	GcRuns        uint32             `json:"gcRuns"`
+	GcPauseMs     int64              `json:"gcPauseMs"`
+	Pool          *DatabasePool      `json:"pool,omitempty"` //Left out without a pool
+	Caches        []DiagnosticsCache `json:"caches"`
+	UptimeSeconds int64              `json:"uptimeSeconds"`
+	CollectedAt   string             `json:"collectedAt"`
+}
+
+type DatabasePool struct {
+	MaxOpen   int   `json:"maxOpen"` //0 is unlimited
+	Open      int   `json:"open"`
+	InUse     int   `json:"inUse"`
+	Idle      int   `json:"idle"`
+	Waits     int64 `json:"waits"`
+	WaitMs    int64 `json:"waitMs"`
+	Server    int   `json:"server"` //0 is the primary
+	Failovers int64 `json:"failovers"`
+}
+
+type DiagnosticsCache struct {
+	Name    string `json:"name"`
+	Entries int    `json:"entries"`
+}
+
+type FeatureFlag struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Enabled     bool      `json:"enabled"`
+	Percentage  int       `json:"percentage"`
+	UserIds     []string  `json:"userIds"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type FeatureFlags struct {
+	Flags []FeatureFlag `json:"flags"`
+}
+
+type Features struct {
+	UserId   string          `json:"userId"`
+	Features map[string]bool `json:"features"`
+}
+
+type FeedEntry struct {
+	Id        int64
+	Kind      string
+	GameId    string //Empty once the game is gone
+	GameName  string
+	Partners  []string
+	CreatedAt time.Time
+}
+
+type Feed struct {
+	UserId    string
+	UserName  string
+	UpdatedAt time.Time
+	Entries   []FeedEntry
+}
+
+type PlaySession struct {
+	Id        int
+	GameId    string
+	GameName  string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	Minutes   int
+	Notes     string
+	Partners  []SessionPartner
+	CreatedAt time.Time
+}
+
+type SessionPartner struct {
+	UserId   string
+	UserName string
+}
+
+type GameMinutes struct {
+	GameId   string
+	GameName string
+	Minutes  int
+}
+
+type CoopPartner struct {
+	UserId       string
+	PartnerId    string
+	PartnerName  string
+	Sessions     int
+	Minutes      int
+	LastPlayedAt time.Time
+	Games        []GameMinutes
+}
+
+type Coop struct {
+	UserId   string
+	Partners []CoopPartner
+}
+
+type PlaySessions struct {
+	UserId   string
+	From     time.Time
+	To       time.Time
+	Sessions []PlaySession
+}
+
+type HeatmapDay struct {
+	Date    string
+	Minutes int
+	Level   int
+}
+
+type Heatmap struct {
+	UserId     string
+	From       string
+	To         string
+	Minutes    int
+	ActiveDays int
+	MaxMinutes int
+	Days       []HeatmapDay
+}
+
+type LibraryStats struct {
+	UserId          string
+	Libraries       int
+	Games           int
+	Value           float64
+	Statuses        map[string]int
+	Platforms       map[string]int
+	PlatformMinutes map[string]int
+	RefreshedAt     *time.Time
+}
+
+type Streak struct {
+	UserId        string
+	Current       int
+	CurrentSince  string
+	Longest       int
+	LongestFrom   string
+	LongestTo     string
+	PlayedToday   bool
+	Milestones    []int
+	NextMilestone int
+}
+
+type Badge struct {
+	Id          string
+	Name        string
+	Description string
+	Goal        int
+	Progress    int
+	Earned      bool
+	EarnedAt    time.Time
+}
+
+type Badges struct {
+	UserId string
+	Badges []Badge
+}
+
+type Release struct {
+	GameId    string
+	GameName  string
+	Date      string
+	Source    string
+	UpdatedAt time.Time
+}
+
+type Releases struct {
+	UserId   string
+	Releases []Release
+}
+
+type FranchiseGame struct {
+	GameId   string
+	GameName string
+	Position int
+}
+
+type Franchise struct {
+	Id        string
+	Name      string
+	Games     []FranchiseGame
+	CreatedAt time.Time
+}
+
+type Franchises struct {
+	Franchises []Franchise
+}
+
+type FranchiseProgress struct {
+	Franchise Franchise
+	Total     int
+	Owned     int
+	Completed int
+}
+
+type FranchiseProgresses struct {
+	UserId   string
+	Progress []FranchiseProgress
+}
+
+type ChildAccount struct {
+	Id           string
+	Name         string
+	DailyMinutes int
+	RatingCap    int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type ChildAccounts struct {
+	UserId   string
+	Children []ChildAccount
+}
+
+type DayPlaytime struct {
+	Day       string
+	Minutes   int
+	OverLimit bool
+}
+
+type GamePlaytime struct {
+	GameId   string
+	GameName string
+	Minutes  int
+}
+
+type ChildReport struct {
+	UserId       string
+	Child        ChildAccount
+	From         time.Time
+	To           time.Time
+	TotalMinutes int
+	Days         []DayPlaytime
+	Games        []GamePlaytime
+}
+
+type PersonalMetadata struct {
+	GameId        string
+	Difficulty    int
+	Replayability int
+	Moods         []string
+	UpdatedAt     time.Time
+}
+
+type TonightGame struct {
+	GameId        string
+	GameName      string
+	Producer      string
+	Rating        string
+	Status        string
+	Platform      string
+	Difficulty    int
+	Replayability int
+	Moods         []string
+}
+
+type Tonight struct {
+	UserId string
+	Games  []TonightGame
+}
+
+type Suggestion struct {
+	UserId            string
+	Game              TonightGame
+	AddedAt           time.Time
+	CompletionMinutes int
+	UserRating        float64
+	Raters            int
+	Score             float64
+	Seed              int64
+}
+
+type JournalEntry struct {
+	Id             int
+	GameId         string
+	GameName       string
+	Text           string
+	Html           string
+	HasScreenshot  bool
+	HasSpoilers    bool
+	SpoilersHidden bool
+	CreatedAt      time.Time
+}
+
+type Journal struct {
+	UserId  string
+	Limit   int
+	Cursor  string //Of the next page
+	HasMore bool
+	Entries []JournalEntry
+}
+
+type Rendered struct {
+	Html string
+}
+
+type PhysicalCopy struct {
+	Id            int
+	GameId        string
+	GameName      string
+	Barcode       string
+	Platform      string
+	PaidPrice     float64
+	PaidCurrency  string
+	Value         float64
+	ValueCurrency string
+	ValuedAt      time.Time
+	CreatedAt     time.Time
+}
+
+type PhysicalCopies struct {
+	UserId    string
+	LibraryId string
+	Limit     int
+	Cursor    string //Of the next page
+	HasMore   bool
+	Copies    []PhysicalCopy
+}
+
+// Totals are keyed by currency
+type CollectionWorth struct {
+	UserId    string
+	LibraryId string
+	Copies    []PhysicalCopy
+	Paid      map[string]float64
+	Value     map[string]float64
+	Unpaid    int
+	Unvalued  int
+}
+
+type PhotoImport struct {
+	Id        int
+	UserId    string
+	LibraryId string
+	Status    string
+	Reason    string
+	Photos    int
+	Proposals []PhotoProposal
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type PhotoProposal struct {
+	Id         int
+	Photo      int
+	Title      string
+	Confidence float64
+	Matched    bool
+	Reason     string
+	Accepted   bool
+}
+
+type ShareLink struct {
+	Id          int
+	UserId      string
+	LibraryId   string
+	Token       string //Only known right after the link is issued
+	HasPassword bool
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+}
+
+type ShareLinks struct {
+	UserId    string
+	LibraryId string
+	Links     []ShareLink
+}
+
+type SharedLibrary struct {
+	Token     string
+	OwnerName string
+	ExpiresAt time.Time
+	Games     []Game
+}
+
+type LibraryMember struct {
+	UserId    string
+	UserName  string
+	Role      string
+	CreatedAt time.Time
+}
+
+type LibraryMembers struct {
+	UserId    string
+	LibraryId string
+	Members   []LibraryMember
+}
+
+type Membership struct {
+	LibraryId string
+	Role      string
+	CreatedAt time.Time
+}
+
+// Libraries of other users shared with UserId
+type Memberships struct {
+	UserId    string
+	Libraries []Membership
+}
+
+type TradeOffer struct {
+	Id                 int
+	UserId             string
+	ProposerId         string
+	ProposerLibraryId  string
+	RecipientId        string
+	RecipientLibraryId string
+	Offered            []int
+	Requested          []int
+	Status             string
+	CounterOf          int
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+type TradeOffers struct {
+	UserId  string
+	Limit   int
+	Cursor  string //Of the next page
+	HasMore bool
+	Offers  []TradeOffer
+}
+
+type Goal struct {
+	Id        int
+	UserId    string
+	Kind      string
+	Target    int
+	Period    string
+	Year      int
+	Quarter   int
+	StartsAt  time.Time
+	EndsAt    time.Time
+	Current   int
+	Expected  int
+	Achieved  bool
+	Behind    bool
+	CreatedAt time.Time
+}
+
+type Goals struct {
+	UserId string
+	Goals  []Goal
+}
+
+type Hardware struct {
+	Id            int
+	UserId        string
+	Kind          string
+	Name          string
+	Model         string
+	Serial        string
+	PurchasedOn   string
+	WarrantyUntil string
+	UnderWarranty bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type HardwareList struct {
+	UserId string
+	Items  []Hardware
+}
+
+type Subscription struct {
+	Id            int
+	UserId        string
+	Service       string
+	MonthlyCost   float64
+	Currency      string
+	BillingMonths int
+	StartedOn     string
+	RenewsOn      string
+	EndsOn        string
+	GameIds       []string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type Subscriptions struct {
+	UserId        string
+	Subscriptions []Subscription
+}
+
+type SubscriptionUsage struct {
+	Subscription Subscription
+	Cost         float64
+	Minutes      int
+	CostPerHour  float64
+	Games        []GameMinutes
+}
+
+type SubscriptionReport struct {
+	UserId        string
+	From          time.Time
+	To            time.Time
+	Subscriptions []SubscriptionUsage
+	Cost          map[string]float64
+	Minutes       int
+}
+
+type Mod struct {
+	Id        int
+	UserId    string
+	LibraryId string
+	GameId    string
+	GameName  string
+	Name      string
+	SourceUrl string
+	Version   string
+	Enabled   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Mods struct {
+	UserId    string
+	LibraryId string
+	GameId    string
+	Mods      []Mod
+}
+
+type ModImportReport struct {
+	UserId    string
+	LibraryId string
+	Imported  []Mod
+	Skipped   int
+	Unmatched []string
+}
+
+type SaveBackup struct {
+	Id         int
+	UserId     string
+	LibraryId  string
+	GameId     string
+	GameName   string
+	Location   string
+	Size       int64
+	Checksum   string
+	BackedUpAt time.Time
+	CreatedAt  time.Time
+}
+
+type SaveBackups struct {
+	UserId    string
+	LibraryId string
+	GameId    string
+	Backups   []SaveBackup
+}
+
+type SpeedRun struct {
+	Id           int
+	UserId       string
+	Runner       string //Name of the user who ran it
+	GameId       string
+	GameName     string
+	Category     string
+	Milliseconds int64
+	Time         string
+	RunAt        time.Time
+	PersonalBest bool
+}
+
+type SpeedRuns struct {
+	UserId  string
+	GameId  string //Empty for the personal bests of every game
+	Limit   int    //Zero for the personal bests, they are not paged
+	Cursor  string //Of the next page
+	HasMore bool
+	Runs    []SpeedRun
+}
+
+type SpeedrunComparison struct {
+	Category string
+	Own      *SpeedRun
+	Runs     []SpeedRun
+	Behind   []int64 //Milliseconds each run is slower than the own, negative when faster
+}
+
+type SpeedrunComparisons struct {
+	UserId      string
+	GameId      string
+	Comparisons []SpeedrunComparison
+}
+
+type Match struct {
+	Id        int       `json:"-"`
+	UserId    string    `json:"-"`
+	GameId    string    `json:"gameId"`
+	GameName  string    `json:"gameName"`
+	Result    string    `json:"result"`
+	Score     string    `json:"score,omitempty"`
+	Opponents []string  `json:"opponents"`
+	PlayedAt  time.Time `json:"playedAt"`
+}
+
+type Matches struct {
+	UserId  string
+	Limit   int
+	Cursor  string //Of the next page
+	HasMore bool
+	Matches []Match
+}
+
+type MatchBatchItem struct {
+	Index   int    `json:"index"`
+	Status  int    `json:"status"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+	MatchId int    `json:"matchId,omitempty"`
+}
+
+type MatchBatch struct {
+	UserId string
+	Items  []MatchBatchItem
+}
+
+type MatchRecord struct {
+	Matches int     `json:"matches"`
+	Wins    int     `json:"wins"`
+	Losses  int     `json:"losses"`
+	Draws   int     `json:"draws"`
+	WinRate float64 `json:"winRate"`
+}
+
+type GameMatchRecord struct {
+	MatchRecord
+	GameId   string `json:"gameId"`
+	GameName string `json:"gameName"`
+}
+
+type MatchStats struct {
+	UserId string `json:"-"`
+	MatchRecord
+	Games []GameMatchRecord `json:"games"`
+}
+
+type HeadToHead struct {
+	MatchRecord
+	Opponent     string    `json:"opponent"`
+	LastPlayedAt time.Time `json:"lastPlayedAt"`
+}
+
+type HeadToHeads struct {
+	UserId  string
+	Records []HeadToHead
+}
+
+type Agent struct {
+	Id         int
+	UserId     string
+	Name       string
+	Token      string //Only set right after the agent was added
+	LastSeenAt time.Time
+	CreatedAt  time.Time
+}
+
+type Agents struct {
+	UserId string
+	Agents []Agent
+}
+
+type Executable struct {
+	UserId     string
+	Executable string
+	GameId     string
+	GameName   string
+}
+
+type Executables struct {
+	UserId      string
+	Executables []Executable
+}
+
+type AgentReport struct {
+	Executable string
+	Event      string
+	Mapped     bool
+	GameId     string
+	GameName   string
+	Session    *PlaySession //Set when the report logged a session
+}
+
+type Webhook struct {
+	Id              int
+	UserId          string
+	Name            string
+	Action          string
+	LibraryId       string
+	Key             string //Key and Secret are only set right after the webhook was added
+	Secret          string
+	LastDeliveredAt time.Time
+	CreatedAt       time.Time
+}
+
+type Webhooks struct {
+	UserId   string
+	Webhooks []Webhook
+}
+
+type Rule struct {
+	Id        int
+	UserId    string
+	Name      string
+	Event     string
+	Condition string
+	Actions   string
+	Enabled   bool
+	DryRun    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Rules struct {
+	UserId string
+	Rules  []Rule
+}
+
+type RuleRun struct {
+	Id        int
+	Event     string
+	EntityId  int
+	Actions   []string
+	DryRun    bool
+	Error     string
+	CreatedAt time.Time
+}
+
+type RuleRuns struct {
+	UserId string
+	RuleId int
+	Runs   []RuleRun
+}
+
+type RuleTest struct {
+	UserId  string
+	Matched bool
+	Actions []string
+}
+
+type Script struct {
+	Id        int
+	Name      string
+	Event     string
+	Source    string
+	UserId    string
+	Enabled   bool
+	CreatedBy string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Scripts struct {
+	Scripts []Script
+}
+
+type Tag struct {
+	Tag   string
+	Games int
+}
+
+type Tags struct {
+	UserId string
+	Tags   []Tag
+}
+
+type TagChange struct {
+	UserId    string
+	From      string
+	To        string
+	Games     int
+	Libraries int
+}
+
+type SearchFilter struct {
+	Rating   string
+	MaxAge   int
+	Name     string
+	Status   string
+	Platform string
+	Tag      string
+	Sort     string
+}
+
+type SavedSearch struct {
+	Id        int
+	UserId    string
+	Name      string
+	Filter    SearchFilter
+	Shared    bool
+	Token     string //Only known right after the search is shared
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type SavedSearches struct {
+	UserId   string
+	Searches []SavedSearch
+}
+
+type SharedSearch struct {
+	Token  string
+	Name   string
+	Filter SearchFilter
+}
+
+type GameAddon struct {
+	GameId string
+	Name   string
+	Kind   string
+	Status string
+	Value  float64
+}
+
+type GameTree struct {
+	UserId     string
+	LibraryId  string
+	GameId     string
+	Name       string
+	Status     string
+	Value      float64
+	Addons     []GameAddon
+	TotalValue float64
+	Completed  int
+	Total      int
+	Completion int
+}
+
+type CatalogAvailability struct {
+	Service  string
+	LeavesOn string
+}
+
+type CatalogGame struct {
+	GameId   string
+	GameName string
+	Status   string
+	Catalogs []CatalogAvailability
+}
+
+type CatalogGames struct {
+	UserId string
+	Games  []CatalogGame
+}
+
+type GameSpoilers struct {
+	GameId           string
+	ContainsSpoilers bool
+}
+
+type LibraryExport struct {
+	Id    string
+	Games []Game
+	Mods  []Mod
+}
+
+type ProfileExport struct {
+	UserId     string
+	UserName   string
+	CreatedAt  time.Time
+	Libraries  []LibraryExport
+	Journal    []JournalEntry
+	ExportedAt time.Time
+}
+
+type CalendarLink struct {
+	UserId string
+	Token  string
+}
+
+type Calendar struct {
+	UserName string
+	Sessions []PlaySession
+	Releases []Release
 }
@@ -0,0 +1,233 @@
+package plugins
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"game-tracker/usecases"
+)
+
+// Kinds of provider a plugin supplies, each names the usecases interface its
+// provider implements
+const (
+	KindMetadata      = "metadata"      //usecases.MetadataProvider
+	KindLibrarySync   = "library_sync"  //usecases.SteamStore
+	KindPricing       = "pricing"       //usecases.PricingProvider
+	KindNotifications = "notifications" //usecases.NotificationChannel
+)
+
+// Types of a setting, JSON numbers are accepted for int when they are whole
+const (
+	TypeString  = "string"
+	TypeInt     = "int"
+	TypeBool    = "bool"
+	TypeStrings = "strings"
+)
+
+// One setting a plugin accepts
+type Field struct {
+	Name        string
+	Type        string
+	Required    bool
+	Default     interface{} //Used when the setting is left out, nil leaves it out
+	Description string
+}
+
+// A provider third parties can add by calling Register, typically from the
+// init function of their package. Open builds the provider from settings
+// already checked against Schema. When the provider also implements Starter
+// or Stopper it is started once every plugin is open and stopped on Close.
+type Plugin struct {
+	Name   string
+	Kind   string
+	Schema []Field
+	Open   func(settings Settings) (interface{}, error)
+}
+
+type Starter interface {
+	Start() error
+}
+
+type Stopper interface {
+	Stop() error
+}
+
+// How config.json turns a plugin on, settings are checked against its schema
+type Config struct {
+	Enabled  bool
+	Settings map[string]interface{}
+}
+
+var (
+	mu       sync.Mutex
+	registry = make(map[string]Plugin)
+)
+
+var kinds = map[string]bool{
+	KindMetadata:      true,
+	KindLibrarySync:   true,
+	KindPricing:       true,
+	KindNotifications: true,
+}
+
+// Makes a plugin available to Open. It panics when the plugin is invalid or
+// its name is taken, both are programming errors.
+func Register(plugin Plugin) {
+	mu.Lock()
+	defer mu.Unlock()
+	if plugin.Name == "" || plugin.Open == nil {
+		panic("plugins: Register needs a name and an Open function")
+	}
+	if !kinds[plugin.Kind] {
+		panic(fmt.Sprintf("plugins: plugin %s has unknown kind '%s'", plugin.Name, plugin.Kind))
+	}
+	for _, field := range plugin.Schema {
+		if field.Type != TypeString && field.Type != TypeInt && field.Type != TypeBool &&
+			field.Type != TypeStrings {
+			panic(fmt.Sprintf("plugins: setting %s of plugin %s has unknown type '%s'", field.Name,
+				plugin.Name, field.Type))
+		}
+	}
+	if _, taken := registry[plugin.Name]; taken {
+		panic(fmt.Sprintf("plugins: plugin %s is registered twice", plugin.Name))
+	}
+	registry[plugin.Name] = plugin
+}
+
+// Every registered plugin, by name
+func Registered() []Plugin {
+	mu.Lock()
+	defer mu.Unlock()
+	registered := make([]Plugin, 0, len(registry))
+	for _, plugin := range registry {
+		registered = append(registered, plugin)
+	}
+	sort.Slice(registered, func(i, j int) bool { return registered[i].Name < registered[j].Name })
+	return registered
+}
+
+// The providers of the enabled plugins. There is at most one metadata,
+// library sync and pricing provider, every notification channel is kept.
+type Host struct {
+	metadata usecases.MetadataProvider
+	steam    usecases.SteamStore
+	pricing  usecases.PricingProvider
+	channels []usecases.NotificationChannel
+	started  []interface{} //Providers to stop, in the order they were started
+}
+
+// Opens and starts the enabled plugins of configs, keyed by plugin name.
+// Nothing is left running when an error is returned.
+func Open(configs map[string]Config) (*Host, error) {
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	host := &Host{}
+	var providers []interface{}
+	owners := make(map[string]string) //Plugin supplying each single provider kind
+	for _, name := range names {
+		config := configs[name]
+		if !config.Enabled {
+			continue
+		}
+		mu.Lock()
+		plugin, found := registry[name]
+		mu.Unlock()
+		if !found {
+			return nil, fmt.Errorf("plugin %s is not registered", name)
+		}
+		settings, err := plugin.check(config.Settings)
+		if err != nil {
+			return nil, err
+		}
+		if owner, taken := owners[plugin.Kind]; taken {
+			return nil, fmt.Errorf("plugins %s and %s both provide %s, enable one", owner, name,
+				plugin.Kind)
+		}
+		provider, err := plugin.Open(settings)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open plugin %s: %v", name, err)
+		}
+		err = host.add(plugin, provider)
+		if err != nil {
+			return nil, err
+		}
+		if plugin.Kind != KindNotifications {
+			owners[plugin.Kind] = name
+		}
+		providers = append(providers, provider)
+	}
+
+	for _, provider := range providers {
+		if starter, ok := provider.(Starter); ok {
+			err := starter.Start()
+			if err != nil {
+				host.Close()
+				return nil, fmt.Errorf("cannot start plugin: %v", err)
+			}
+		}
+		host.started = append(host.started, provider)
+	}
+	return host, nil
+}
+
+func (host *Host) add(plugin Plugin, provider interface{}) error {
+	var ok bool
+	switch plugin.Kind {
+	case KindMetadata:
+		host.metadata, ok = provider.(usecases.MetadataProvider)
+	case KindLibrarySync:
+		host.steam, ok = provider.(usecases.SteamStore)
+	case KindPricing:
+		host.pricing, ok = provider.(usecases.PricingProvider)
+	case KindNotifications:
+		var channel usecases.NotificationChannel
+		channel, ok = provider.(usecases.NotificationChannel)
+		if ok {
+			host.channels = append(host.channels, channel)
+		}
+	}
+	if !ok {
+		return fmt.Errorf("plugin %s does not provide %s", plugin.Name, plugin.Kind)
+	}
+	return nil
+}
+
+// Stops the started providers in reverse order, the first error is returned
+// after every provider was asked to stop
+func (host *Host) Close() error {
+	var first error
+	for i := len(host.started) - 1; i >= 0; i-- {
+		if stopper, ok := host.started[i].(Stopper); ok {
+			err := stopper.Stop()
+			if err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	host.started = nil
+	return first
+}
+
+// Nil when no plugin provides metadata
+func (host *Host) Metadata() usecases.MetadataProvider {
+	return host.metadata
+}
+
+// Nil when no plugin syncs libraries
+func (host *Host) LibrarySync() usecases.SteamStore {
+	return host.steam
+}
+
+// Nil when no plugin provides prices
+func (host *Host) Pricing() usecases.PricingProvider {
+	return host.pricing
+}
+
+func (host *Host) NotificationChannels() []usecases.NotificationChannel {
+	return host.channels
+}
@@ -0,0 +1,101 @@
+package plugins
+
+import (
+	"fmt"
+	"math"
+)
+
+// Settings of a plugin after they were checked against its schema, every
+// value has the type of its field
+type Settings map[string]interface{}
+
+func (settings Settings) String(name string) string {
+	value, _ := settings[name].(string)
+	return value
+}
+
+func (settings Settings) Int(name string) int {
+	value, _ := settings[name].(int)
+	return value
+}
+
+func (settings Settings) Bool(name string) bool {
+	value, _ := settings[name].(bool)
+	return value
+}
+
+func (settings Settings) Strings(name string) []string {
+	value, _ := settings[name].([]string)
+	return value
+}
+
+// Fills in defaults and converts the values decoded from JSON to the types of
+// the schema, settings the schema does not know are rejected so typos show
+func (plugin Plugin) check(raw map[string]interface{}) (Settings, error) {
+	fields := make(map[string]Field)
+	for _, field := range plugin.Schema {
+		fields[field.Name] = field
+	}
+	for name := range raw {
+		if _, known := fields[name]; !known {
+			return nil, fmt.Errorf("plugin %s has no setting %s", plugin.Name, name)
+		}
+	}
+
+	settings := make(Settings)
+	for _, field := range plugin.Schema {
+		value, set := raw[field.Name]
+		if !set || value == nil {
+			if field.Required {
+				return nil, fmt.Errorf("plugin %s needs the setting %s", plugin.Name, field.Name)
+			}
+			if field.Default != nil {
+				settings[field.Name] = field.Default
+			}
+			continue
+		}
+		converted, ok := convert(field.Type, value)
+		if !ok {
+			return nil, fmt.Errorf("setting %s of plugin %s must be of type %s", field.Name,
+				plugin.Name, field.Type)
+		}
+		settings[field.Name] = converted
+	}
+	return settings, nil
+}
+
+func convert(kind string, value interface{}) (interface{}, bool) {
+	switch kind {
+	case TypeString:
+		text, ok := value.(string)
+		return text, ok
+	case TypeBool:
+		flag, ok := value.(bool)
+		return flag, ok
+	case TypeInt:
+		switch number := value.(type) {
+		case int:
+			return number, true
+		case float64:
+			if number != math.Trunc(number) {
+				return nil, false
+			}
+			return int(number), true
+		}
+	case TypeStrings:
+		items, ok := value.([]interface{})
+		if !ok {
+			texts, ok := value.([]string)
+			return texts, ok
+		}
+		texts := make([]string, len(items))
+		for i, item := range items {
+			texts[i], ok = item.(string)
+			if !ok {
+				return nil, false
+			}
+		}
+		return texts, true
+	}
+	return nil, false
+}
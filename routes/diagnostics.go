@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"expvar"
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+
+	"game-tracker/interfaces"
+	"game-tracker/middlewares/auth"
+	"game-tracker/middlewares/errres"
+	"game-tracker/middlewares/recovery"
+	"game-tracker/middlewares/reqlog"
+	res "game-tracker/models/responses"
+	"game-tracker/usecases"
+)
+
+// Builds the engine of the diagnostics port: net/http/pprof under
+// /debug/pprof, expvar under /debug/vars and the runtime figures under
+// /admin/diagnostics, all for admin tokens only
+func CreateDiagnosticsEngine(webserviceHandler interfaces.WebserviceHandler) *gin.Engine {
+	// Build may run more than once in a process, expvar panics on a second
+	// variable of the same name
+	if expvar.Get("diagnostics") == nil {
+		expvar.Publish("diagnostics", expvar.Func(webserviceHandler.DiagnosticsVar))
+	}
+
+	engine := gin.New()
+	engine.Use(reqlog.Log(), errres.ErrorHandle(webserviceHandler.Translator),
+		recovery.Recover(webserviceHandler.ErrorReporter))
+	engine.Use(auth.CheckRole(usecases.RoleAdmin, webserviceHandler.TokenSecret),
+		webserviceHandler.RequireAdmin)
+
+	engine.GET("/admin/diagnostics", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowDiagnostics(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewDiagnostics(message))
+		}
+	})
+	engine.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+
+	debug := engine.Group("/debug/pprof")
+	debug.GET("/", gin.WrapF(pprof.Index))
+	debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	debug.GET("/profile", gin.WrapF(pprof.Profile))
+	debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+	debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+	debug.GET("/trace", gin.WrapF(pprof.Trace))
+	// Named profiles such as heap, goroutine and block
+	debug.GET("/:profile", func(c *gin.Context) {
+		pprof.Handler(c.Param("profile")).ServeHTTP(c.Writer, c.Request)
+	})
+	return engine
+}
@@ -1,58 +1,174 @@
 package routes
 
 import (
+	"encoding/xml"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
 
 	"game-tracker/interfaces"
+	"game-tracker/interfaces/web"
 	"game-tracker/middlewares/auth"
+	"game-tracker/middlewares/bodycheck"
+	"game-tracker/middlewares/compress"
 	"game-tracker/middlewares/errres"
+	"game-tracker/middlewares/headers"
+	"game-tracker/middlewares/idempotency"
+	"game-tracker/middlewares/locale"
+	"game-tracker/middlewares/maintenance"
+	"game-tracker/middlewares/ratelimit"
+	"game-tracker/middlewares/recovery"
+	"game-tracker/middlewares/reqlog"
+	"game-tracker/middlewares/suspension"
+	"game-tracker/models/postgres"
 	res "game-tracker/models/responses"
+	"game-tracker/models/result"
+	"game-tracker/usecases"
 )
 
-func CreateEngine(webserviceHandler interfaces.WebserviceHandler) *gin.Engine {
+const maxBodyBytes = 1 << 20
+
+func CreateEngine(webserviceHandler interfaces.WebserviceHandler, site web.Site,
+	idempotencyStore idempotency.Store, rateCounter interfaces.Cache,
+	config postgres.Configuration) *gin.Engine {
 	engine := gin.New()
-	engine.Use(gin.Logger(), gin.Recovery())
-	engine.Use(errres.ErrorHandle())
+	engine.Use(reqlog.Log(), errres.ErrorHandle(webserviceHandler.Translator),
+		recovery.Recover(webserviceHandler.ErrorReporter))
+	engine.Use(headers.Security(config.Security), headers.Cors(config.Cors))
+	engine.Use(ratelimit.Limit(rateCounter, config.Redis.RequestsPerWindow,
+		time.Duration(config.Redis.RateLimit.Ttl)*time.Second))
+	engine.Use(maintenance.ReadOnly(webserviceHandler.Maintenance))
+	engine.Use(bodycheck.CheckBody(maxBodyBytes), compress.Compress())
+	engine.Use(locale.Detect(webserviceHandler))
 
 	engine.POST("/login", func(c *gin.Context) {
-		tokenString, code := webserviceHandler.Login(c)
+		message, code := webserviceHandler.Login(c)
 		c.Set("code", code)
 		if c.Errors.Last() == nil {
-			token := res.ViewToken(tokenString)
+			token := res.ViewToken(message)
 			c.JSON(201, token)
 		}
 	})
+	engine.POST("/refresh", func(c *gin.Context) {
+		message, code := webserviceHandler.Refresh(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewToken(message))
+		}
+	})
+	engine.GET("/calendar/:file", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowCalendar(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Header("Cache-Control", "private, max-age=300")
+			c.Data(200, "text/calendar; charset=utf-8", []byte(res.ViewCalendar(message)))
+		}
+	})
+	// Desktop agents report processes starting and stopping with their own
+	// token in X-Agent-Key, stops of mapped games are logged as play sessions
+	engine.POST("/agent/events", func(c *gin.Context) {
+		code, message := webserviceHandler.ReportAgentEvent(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewAgentReport(message))
+		}
+	})
+	// Inbound webhooks, signed with the webhook's secret in X-Webhook-Signature
+	engine.POST("/webhooks/:key", func(c *gin.Context) {
+		code := webserviceHandler.ReceiveWebhook(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+	// Read-only libraries behind a share link, the token stands in for a login
+	engine.GET("/shared/:token", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowSharedLibrary(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Header("Cache-Control", "private, no-store")
+			c.JSON(200, res.ViewSharedLibrary(message))
+		}
+	})
+	// Saved searches behind a link show their name and filter, not their owner
+	engine.GET("/searches/:token", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowSharedSearch(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Header("Cache-Control", "private, no-store")
+			c.JSON(200, res.ViewSharedSearch(message))
+		}
+	})
+	engine.GET("/franchises", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowFranchises(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewFranchises(message))
+		}
+	})
+	engine.GET("/franchises/:franchiseId", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowFranchise(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewFranchise(message))
+		}
+	})
+	engine.GET("/u/:username", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowUserByName(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			libraries := res.ViewLibraries(message.LibraryIds)
+			users := res.ViewUser(message.Id, message.Name, libraries, message.CreatedAt,
+				message.UpdatedAt)
+			c.JSON(200, users)
+		}
+	})
 
 	unAuth := engine.Group("/users")
+	unAuth.Use(idempotency.Replay(idempotencyStore))
 	unAuth.GET("/:id", func(c *gin.Context) {
 		code, message := webserviceHandler.ShowUser(c)
 		c.Set("code", code)
 		if c.Errors.Last() == nil {
 			libraries := res.ViewLibraries(message.LibraryIds)
-			users := res.ViewUser(message.Id, message.Name, libraries)
+			users := res.ViewUser(message.Id, message.Name, libraries, message.CreatedAt,
+				message.UpdatedAt)
 			c.JSON(200, users)
 		}
 	})
+	unAuth.GET("/:id/feed.atom", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowFeed(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			body, err := xml.MarshalIndent(res.ViewFeed(message), "", "  ")
+			if err != nil {
+				c.AbortWithError(500, err)
+				return
+			}
+			c.Data(200, "application/atom+xml; charset=utf-8", append([]byte(xml.Header), body...))
+		}
+	})
 	unAuth.POST("", func(c *gin.Context) {
 		code, message := webserviceHandler.AddUser(c)
 		c.Set("code", code)
 		if c.Errors.Last() == nil {
-			users := res.ViewUser(message.Id, message.Name, nil)
-			c.JSON(201, users)
+			c.JSON(201, res.ViewAddedUser(message))
 		}
 	})
 	unAuth.GET("/:id/info", func(c *gin.Context) {
 		code, message := webserviceHandler.ShowUserInfo(c)
 		c.Set("code", code)
 		if c.Errors.Last() == nil {
-			info := res.ViewInfo(message.Info, message.Id)
+			info := res.ViewInfo(message.Info, message.Id, message.Version)
 			c.JSON(200, info)
 		}
 	})
 
 	authorized := engine.Group("/users/:id")
-	authorized.Use(auth.CheckToken())
+	authorized.Use(auth.CheckToken(webserviceHandler.TokenSecret), locale.Detect(webserviceHandler),
+		suspension.BlockWrites(webserviceHandler), idempotency.Replay(idempotencyStore))
 
 	users := authorized.Group("")
 	users.DELETE("", func(c *gin.Context) {
@@ -62,75 +178,1422 @@ func CreateEngine(webserviceHandler interfaces.WebserviceHandler) *gin.Engine {
 			c.Status(204)
 		}
 	})
+	users.PUT("/name", func(c *gin.Context) {
+		code, message := webserviceHandler.RenameUser(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			libraries := res.ViewLibraries(message.LibraryIds)
+			users := res.ViewUser(message.Id, message.Name, libraries, message.CreatedAt,
+				message.UpdatedAt)
+			c.JSON(200, users)
+		}
+	})
+	users.GET("/features", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowFeatures(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewFeatures(message))
+		}
+	})
 	users.PUT("/info", func(c *gin.Context) {
 		code, message := webserviceHandler.EditUserInfo(c)
 		c.Set("code", code)
 		if c.Errors.Last() == nil {
-			info := res.ViewInfo(message.Info, message.Id)
+			info := res.ViewInfo(message.Info, message.Id, message.Version)
 			c.JSON(201, info)
 		}
 	})
 
-	libraries := users.Group("/libraries")
-	libraries.GET("/:libId", func(c *gin.Context) {
-		code, message := webserviceHandler.ShowLibrary(c)
+	users.GET("/settings", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowSettings(c)
 		c.Set("code", code)
 		if c.Errors.Last() == nil {
-			games := res.ViewGames(message.GamesIds)
-			library := res.ViewLibrary(message.UserId, message.Id, games)
-			c.JSON(200, library)
+			c.JSON(200, res.ViewSettings(message))
 		}
 	})
-	libraries.POST("", func(c *gin.Context) {
-		code, message := webserviceHandler.AddLibrary(c)
+	users.PUT("/settings", func(c *gin.Context) {
+		code, message := webserviceHandler.EditSettings(c)
 		c.Set("code", code)
 		if c.Errors.Last() == nil {
-			library := res.ViewLibrary(message.UserId, message.Id, nil)
-			c.JSON(201, library)
+			c.JSON(200, res.ViewSettings(message))
 		}
 	})
-	libraries.DELETE("/:libId", func(c *gin.Context) {
-		code, _ := webserviceHandler.RemoveLibrary(c)
+
+	users.GET("/sync", func(c *gin.Context) {
+		code, message := webserviceHandler.Sync(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSync(message))
+		}
+	})
+	// WebSocket, answered with 101 and no body
+	users.GET("/changes/watch", func(c *gin.Context) {
+		code := webserviceHandler.WatchChanges(c)
+		c.Set("code", code)
+	})
+
+	sessions := users.Group("/sessions")
+	sessions.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowSessions(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewPlaySessions(message))
+		}
+	})
+	sessions.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.AddSession(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewPlaySession(c.Param("id"), message))
+		}
+	})
+	sessions.PUT("/:sessionId", func(c *gin.Context) {
+		code, message := webserviceHandler.EditSession(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewPlaySession(c.Param("id"), message))
+		}
+	})
+	sessions.DELETE("/:sessionId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveSession(c)
 		c.Set("code", code)
 		if c.Errors.Last() == nil {
 			c.Status(204)
 		}
 	})
 
-	games := libraries.Group("/:libId/games")
-	games.GET(":gameId", func(c *gin.Context) {
-		code, message := webserviceHandler.ShowGame(c)
+	// Hours played with other users, from the partners named on sessions
+	users.GET("/coop", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowCoop(c)
 		c.Set("code", code)
 		if c.Errors.Last() == nil {
-			game := res.ViewGame(message.UserId, message.LibraryId, message.Id,
-				message.Name, message.Producer, message.Value)
-			c.JSON(code, game)
+			c.JSON(200, res.ViewCoop(message))
 		}
 	})
-	games.POST("", func(c *gin.Context) {
-		code, message := webserviceHandler.AddGame(c)
+	users.GET("/coop/:partnerId", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowCoop(c)
 		c.Set("code", code)
-		fmt.Printf("err: %v\n", c.Errors)
 		if c.Errors.Last() == nil {
-			game := res.ViewGame(message.UserId, message.LibraryId, message.Id,
-				message.Name, message.Producer, message.Value)
-			c.JSON(code, game)
+			c.JSON(200, res.ViewCoopPartner(message.Partners[0]))
 		}
 	})
-	games.POST("/:gameId", func(c *gin.Context) {
-		code, message := webserviceHandler.PickGame(c)
+
+	// Days in a row with a play session, in the user's timezone
+	users.GET("/streak", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowStreak(c)
 		c.Set("code", code)
 		if c.Errors.Last() == nil {
-			game := res.ViewGame(message.UserId, message.LibraryId, message.Id, "", "", 0)
-			c.JSON(code, game)
+			c.JSON(200, res.ViewStreak(message))
 		}
 	})
-	games.DELETE("/:gameId", func(c *gin.Context) {
-		code, _ := webserviceHandler.RemoveGame(c)
+	// Minutes played per day over the last year
+	users.GET("/heatmap", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowHeatmap(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewHeatmap(message))
+		}
+	})
+
+	// Totals over all libraries as of the last stats refresh
+	users.GET("/stats", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowLibraryStats(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewLibraryStats(message))
+		}
+	})
+
+	// Earned and upcoming badges, listing them awards any reached since
+	users.GET("/badges", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowBadges(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewBadges(message))
+		}
+	})
+
+	// Yearly and quarterly goals with their progress so far
+	goals := users.Group("/goals")
+	goals.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowGoals(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewGoals(message))
+		}
+	})
+	goals.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.AddGoal(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewGoal(message))
+		}
+	})
+	goals.GET("/:goalId", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowGoal(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewGoal(message))
+		}
+	})
+	goals.PUT("/:goalId/target", func(c *gin.Context) {
+		code, message := webserviceHandler.EditGoal(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewGoal(message))
+		}
+	})
+	goals.DELETE("/:goalId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveGoal(c)
 		c.Set("code", code)
 		if c.Errors.Last() == nil {
 			c.Status(204)
 		}
 	})
-	return engine
+
+	// Consoles and peripherals with their warranties
+	hardware := users.Group("/hardware")
+	hardware.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowHardware(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewHardwareList(message))
+		}
+	})
+	hardware.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.AddHardware(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewHardware(message))
+		}
+	})
+	hardware.GET("/:itemId", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowHardwareItem(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewHardware(message))
+		}
+	})
+	hardware.PUT("/:itemId", func(c *gin.Context) {
+		code, message := webserviceHandler.EditHardware(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewHardware(message))
+		}
+	})
+	hardware.DELETE("/:itemId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveHardware(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	// Multiplayer matches with win rates overall, per game and per opponent
+	matches := users.Group("/matches")
+	matches.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowMatches(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewMatches(message))
+		}
+	})
+	matches.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.IngestMatches(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewMatchBatch(message))
+		}
+	})
+	matches.GET("/stats", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowMatchStats(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewMatchStats(message))
+		}
+	})
+	matches.GET("/opponents", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowHeadToHead(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewHeadToHeads(message))
+		}
+	})
+	matches.DELETE("/:matchId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveMatch(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	// Desktop agents and the executables they report, mapped to games
+	agents := users.Group("/agents")
+	agents.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowAgents(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewAgents(message))
+		}
+	})
+	agents.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.AddAgent(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Header("Cache-Control", "no-store")
+			c.JSON(201, res.ViewAgent(message))
+		}
+	})
+	agents.DELETE("/:agentId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveAgent(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+	executables := users.Group("/executables")
+	executables.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowExecutables(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewExecutables(message))
+		}
+	})
+	executables.PUT("/:executable", func(c *gin.Context) {
+		code, message := webserviceHandler.MapExecutable(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewExecutable(message))
+		}
+	})
+	executables.DELETE("/:executable", func(c *gin.Context) {
+		code := webserviceHandler.UnmapExecutable(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	// Inbound webhooks external systems push notifications and game changes to
+	webhooks := users.Group("/webhooks")
+	webhooks.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowWebhooks(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewWebhooks(message))
+		}
+	})
+	webhooks.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.AddWebhook(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Header("Cache-Control", "no-store")
+			c.JSON(201, res.ViewWebhook(message))
+		}
+	})
+	webhooks.DELETE("/:webhookId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveWebhook(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	// Tags over all of the user's libraries, renamed or merged in one go
+	tags := users.Group("/tags")
+	tags.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowTags(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewTags(message))
+		}
+	})
+	tags.POST("/rename", func(c *gin.Context) {
+		code, message := webserviceHandler.RenameTag(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewTagChange(message))
+		}
+	})
+	tags.POST("/merge", func(c *gin.Context) {
+		code, message := webserviceHandler.MergeTags(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewTagChange(message))
+		}
+	})
+
+	// Automation rules run on the user's events, test tries a draft without saving it
+	rules := users.Group("/rules")
+	rules.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowRules(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewRules(message))
+		}
+	})
+	rules.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.AddRule(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewRule(message))
+		}
+	})
+	rules.POST("/test", func(c *gin.Context) {
+		code, message := webserviceHandler.TestRule(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewRuleTest(message))
+		}
+	})
+	rules.PUT("/:ruleId", func(c *gin.Context) {
+		code, message := webserviceHandler.EditRule(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewRule(message))
+		}
+	})
+	rules.DELETE("/:ruleId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveRule(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+	rules.GET("/:ruleId/runs", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowRuleRuns(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewRuleRuns(message))
+		}
+	})
+
+	// Named filters and sort orders of game lists, applied with ?search
+	searches := users.Group("/searches")
+	searches.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowSearches(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSavedSearches(message))
+		}
+	})
+	searches.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.AddSearch(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewSavedSearch(message))
+		}
+	})
+	searches.POST("/import", func(c *gin.Context) {
+		code, message := webserviceHandler.ImportSearch(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewSavedSearch(message))
+		}
+	})
+	searches.PUT("/:searchId", func(c *gin.Context) {
+		code, message := webserviceHandler.EditSearch(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSavedSearch(message))
+		}
+	})
+	searches.DELETE("/:searchId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveSearch(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+	searches.POST("/:searchId/share", func(c *gin.Context) {
+		code, message := webserviceHandler.ShareSearch(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSavedSearch(message))
+		}
+	})
+	searches.DELETE("/:searchId/share", func(c *gin.Context) {
+		code := webserviceHandler.UnshareSearch(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	// Personal bests per game and category, compared against co-op partners
+	speedruns := users.Group("/speedruns")
+	speedruns.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowPersonalBests(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSpeedRuns(message))
+		}
+	})
+	speedruns.GET("/:gameId", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowSpeedRuns(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSpeedRuns(message))
+		}
+	})
+	speedruns.POST("/:gameId", func(c *gin.Context) {
+		code, message := webserviceHandler.AddSpeedRun(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewSpeedRun(message))
+		}
+	})
+	speedruns.GET("/:gameId/compare", func(c *gin.Context) {
+		code, message := webserviceHandler.ComparePersonalBests(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSpeedrunComparisons(message))
+		}
+	})
+	speedruns.DELETE("/:gameId/:runId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveSpeedRun(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	// Memberships such as Game Pass with their renewals and what they cost per hour
+	subscriptions := users.Group("/subscriptions")
+	subscriptions.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowSubscriptions(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSubscriptions(message))
+		}
+	})
+	subscriptions.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.AddSubscription(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewSubscription(message))
+		}
+	})
+	subscriptions.GET("/report", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowSubscriptionReport(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSubscriptionReport(message))
+		}
+	})
+	subscriptions.GET("/:subscriptionId", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowSubscription(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSubscription(message))
+		}
+	})
+	subscriptions.PUT("/:subscriptionId", func(c *gin.Context) {
+		code, message := webserviceHandler.EditSubscription(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSubscription(message))
+		}
+	})
+	subscriptions.DELETE("/:subscriptionId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveSubscription(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+	subscriptions.PUT("/:subscriptionId/games/:gameId", func(c *gin.Context) {
+		code, message := webserviceHandler.AddSubscriptionGame(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSubscription(message))
+		}
+	})
+	subscriptions.DELETE("/:subscriptionId/games/:gameId", func(c *gin.Context) {
+		code, message := webserviceHandler.RemoveSubscriptionGame(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSubscription(message))
+		}
+	})
+
+	// Wishlist and backlog games a subscription currently includes
+	users.GET("/catalog", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowCatalogGames(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewCatalogGames(message))
+		}
+	})
+
+	releases := users.Group("/releases")
+	releases.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowReleases(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewReleases(message))
+		}
+	})
+	releases.PUT("/:gameId", func(c *gin.Context) {
+		code, message := webserviceHandler.TrackRelease(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewRelease(c.Param("id"), message))
+		}
+	})
+	releases.DELETE("/:gameId", func(c *gin.Context) {
+		code := webserviceHandler.UntrackRelease(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	children := users.Group("/children")
+	children.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowChildren(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewChildAccounts(message))
+		}
+	})
+	children.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.LinkChild(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(code, res.ViewChildAccount(c.Param("id"), message))
+		}
+	})
+	children.PUT("/:childId/limits", func(c *gin.Context) {
+		code, message := webserviceHandler.SetChildLimits(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewChildAccount(c.Param("id"), message))
+		}
+	})
+	children.DELETE("/:childId", func(c *gin.Context) {
+		code := webserviceHandler.UnlinkChild(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+	children.GET("/:childId/report", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowChildReport(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewChildReport(message))
+		}
+	})
+
+	personal := users.Group("/personal")
+	personal.GET("/:gameId", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowPersonalMetadata(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewPersonalMetadata(c.Param("id"), message))
+		}
+	})
+	personal.PUT("/:gameId", func(c *gin.Context) {
+		code, message := webserviceHandler.SetPersonalMetadata(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewPersonalMetadata(c.Param("id"), message))
+		}
+	})
+	personal.DELETE("/:gameId", func(c *gin.Context) {
+		code := webserviceHandler.RemovePersonalMetadata(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	users.GET("/tonight", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowTonight(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewTonight(message))
+		}
+	})
+
+	users.GET("/suggestion", func(c *gin.Context) {
+		code, message := webserviceHandler.PickNext(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSuggestion(message))
+		}
+	})
+
+	journal := users.Group("/journal")
+	journal.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowJournal(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewJournal(message))
+		}
+	})
+	journal.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.AddJournalEntry(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewJournalEntry(c.Param("id"), message))
+		}
+	})
+	journal.GET("/:entryId/screenshot", func(c *gin.Context) {
+		code, screenshot := webserviceHandler.ShowScreenshot(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Header("Cache-Control", "private, max-age=86400")
+			c.Data(200, screenshot.ContentType, screenshot.Data)
+		}
+	})
+	journal.DELETE("/:entryId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveJournalEntry(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	// Previews Markdown the way journal entries are rendered
+	users.POST("/render", func(c *gin.Context) {
+		code, message := webserviceHandler.RenderText(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewRendered(message))
+		}
+	})
+
+	// ?format=html returns a single page instead of JSON, ?spoilers=show keeps
+	// journal spoilers in. Other formats such as ?format=backloggd return the
+	// games as a CSV file for that tracker.
+	users.GET("/export", func(c *gin.Context) {
+		if format := c.Query("format"); format != "" && format != "json" && format != "html" {
+			code, file := webserviceHandler.ExportFile(c)
+			c.Set("code", code)
+			if c.Errors.Last() == nil {
+				c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, file.Name))
+				c.Data(200, file.ContentType, file.Data)
+			}
+			return
+		}
+		code, message := webserviceHandler.ExportProfile(c)
+		c.Set("code", code)
+		if c.Errors.Last() != nil {
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="game-tracker-export.json"`)
+		if c.Query("format") != "html" {
+			c.JSON(200, res.ViewProfileExport(message))
+			return
+		}
+		page, err := res.ViewProfileExportHtml(message)
+		if err != nil {
+			c.Set("code", 500)
+			c.Error(err)
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="game-tracker-export.html"`)
+		c.Data(200, "text/html; charset=utf-8", page)
+	})
+
+	users.GET("/franchises", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowFranchiseProgress(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewFranchiseProgress(message))
+		}
+	})
+
+	users.POST("/calendar", func(c *gin.Context) {
+		code, message := webserviceHandler.IssueCalendarToken(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewCalendarLink(message))
+		}
+	})
+
+	// The latest removed or batch-updated games can be undone and redone
+	// within Undo.Window of the configuration
+	users.GET("/undo", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowUndoStack(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewUndoStack(message))
+		}
+	})
+	users.POST("/undo", func(c *gin.Context) {
+		code, message := webserviceHandler.Undo(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewUndoAction(c.Param("id"), message))
+		}
+	})
+	users.POST("/redo", func(c *gin.Context) {
+		code, message := webserviceHandler.Redo(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewUndoAction(c.Param("id"), message))
+		}
+	})
+
+	// Games and libraries removed within Trash.RetainDays, restored one at a time
+	users.GET("/trash", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowTrash(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewTrash(message))
+		}
+	})
+	users.POST("/trash/:itemId/restore", func(c *gin.Context) {
+		code, message := webserviceHandler.RestoreFromTrash(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewTrashItem(c.Param("id"), message))
+		}
+	})
+
+	users.GET("/memberships", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowMemberships(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewMemberships(message))
+		}
+	})
+
+	trades := users.Group("/trades")
+	trades.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowTrades(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewTradeOffers(message))
+		}
+	})
+	trades.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.ProposeTrade(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewTradeOffer(message))
+		}
+	})
+	trades.GET("/:tradeId", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowTrade(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewTradeOffer(message))
+		}
+	})
+	trades.POST("/:tradeId/accept", func(c *gin.Context) {
+		code, message := webserviceHandler.AcceptTrade(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewTradeOffer(message))
+		}
+	})
+	trades.POST("/:tradeId/decline", func(c *gin.Context) {
+		code, message := webserviceHandler.DeclineTrade(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewTradeOffer(message))
+		}
+	})
+	trades.POST("/:tradeId/cancel", func(c *gin.Context) {
+		code, message := webserviceHandler.CancelTrade(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewTradeOffer(message))
+		}
+	})
+	trades.POST("/:tradeId/counter", func(c *gin.Context) {
+		code, message := webserviceHandler.CounterTrade(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewTradeOffer(message))
+		}
+	})
+
+	notifications := users.Group("/notifications")
+	notifications.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowNotifications(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewNotifications(message))
+		}
+	})
+	notifications.PUT("/read", func(c *gin.Context) {
+		code, _ := webserviceHandler.MarkNotificationsRead(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+	notifications.DELETE("", func(c *gin.Context) {
+		code, _ := webserviceHandler.ClearNotifications(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	libraries := users.Group("/libraries")
+	libraries.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowLibraryVersions(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewLibraryVersions(message))
+		}
+	})
+	libraries.GET("/:libId", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowLibrary(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			etag := fmt.Sprintf(`W/"%s-%d"`, message.Id, message.Version)
+			if notModified(c, etag, message.UpdatedAt) {
+				return
+			}
+			games := res.ViewGames(message.GamesIds)
+			library := res.ViewLibrary(message.UserId, message.Id, games, message.Version,
+				message.CreatedAt, message.UpdatedAt)
+			c.JSON(200, library)
+		}
+	})
+	libraries.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.AddLibrary(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			library := res.ViewLibrary(message.UserId, message.Id, nil, message.Version,
+				time.Time{}, time.Time{})
+			c.JSON(201, library)
+		}
+	})
+	libraries.DELETE("/:libId", func(c *gin.Context) {
+		code, _ := webserviceHandler.RemoveLibrary(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	// History of event sourced libraries, oldest first
+	libraries.GET("/:libId/events", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowLibraryEvents(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewLibraryEvents(message))
+		}
+	})
+
+	// The library at the end of ?date=, or what changed from the end of
+	// ?from= to the end of ?to=, days in the user's time zone
+	libraries.GET("/:libId/history", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowLibraryAt(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewLibraryAsOf(message))
+		}
+	})
+	libraries.GET("/:libId/history/diff", func(c *gin.Context) {
+		code, message := webserviceHandler.DiffLibrary(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewLibraryDiff(message))
+		}
+	})
+
+	// Games exported from another tracker, see ImportGames for the formats
+	libraries.POST("/:libId/import", func(c *gin.Context) {
+		code, message := webserviceHandler.ImportGames(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewImportReport(message))
+		}
+	})
+	libraries.POST("/:libId/import/steam", func(c *gin.Context) {
+		code, message := webserviceHandler.ImportSteamWishlist(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewImportReport(message))
+		}
+	})
+
+	// Boxed copies catalogued by scanning their UPC/EAN barcode
+	copies := libraries.Group("/:libId/copies")
+	copies.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowCopies(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewPhysicalCopies(message))
+		}
+	})
+	copies.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.ScanBarcode(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewPhysicalCopy(c.Param("id"), c.Param("libId"), message))
+		}
+	})
+	copies.DELETE("/:copyId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveCopy(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+	copies.PUT("/:copyId/price", func(c *gin.Context) {
+		code, message := webserviceHandler.SetCopyPrice(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewPhysicalCopy(c.Param("id"), c.Param("libId"), message))
+		}
+	})
+	libraries.GET("/:libId/worth", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowCollectionWorth(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewCollectionWorth(message))
+		}
+	})
+	libraries.GET("/:libId/worth.pdf", func(c *gin.Context) {
+		code, file := webserviceHandler.PrintCollectionWorth(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, file.Name))
+			c.Data(200, file.ContentType, file.Data)
+		}
+	})
+
+	// Photos of shelves read in the background, the proposed games are only
+	// catalogued once confirmed
+	photos := libraries.Group("/:libId/photos")
+	photos.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.StartPhotoImport(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(202, res.ViewPhotoImport(message))
+		}
+	})
+	photos.GET("/:importId", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowPhotoImport(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewPhotoImport(message))
+		}
+	})
+	photos.POST("/:importId/confirm", func(c *gin.Context) {
+		code, message := webserviceHandler.ConfirmPhotoImport(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewPhysicalCopies(message))
+		}
+	})
+
+	members := libraries.Group("/:libId/members")
+	members.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowMembers(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewLibraryMembers(message))
+		}
+	})
+	members.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.SetMember(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewLibraryMember(c.Param("id"), c.Param("libId"), message))
+		}
+	})
+	members.DELETE("/:memberId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveMember(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	shares := libraries.Group("/:libId/shares")
+	shares.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowShareLinks(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewShareLinks(message))
+		}
+	})
+	shares.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShareLibrary(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewShareLink(message))
+		}
+	})
+	shares.DELETE("/:shareId", func(c *gin.Context) {
+		code := webserviceHandler.RevokeShareLink(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	// The saved search the user's game list of the library starts from
+	libraries.PUT("/:libId/search", func(c *gin.Context) {
+		code := webserviceHandler.SetDefaultSearch(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	games := libraries.Group("/:libId/games")
+	games.GET("", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowGames(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewLibraryGames(message))
+		}
+	})
+	games.GET(":gameId", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowGame(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(code, res.ViewGame(message))
+		}
+	})
+	games.POST("", func(c *gin.Context) {
+		code, message := webserviceHandler.AddGame(c)
+		c.Set("code", code)
+		fmt.Printf("err: %v\n", c.Errors)
+		if c.Errors.Last() == nil {
+			c.JSON(code, res.ViewGame(message))
+		}
+	})
+	games.POST("/:gameId", func(c *gin.Context) {
+		code, message := webserviceHandler.PickGame(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			game := res.ViewGame(result.Game{Id: message.Id, LibraryId: message.LibraryId,
+				UserId: message.UserId})
+			c.JSON(code, game)
+		}
+	})
+	games.PUT("", func(c *gin.Context) {
+		code, message := webserviceHandler.UpdateGames(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewGameBatch(message))
+		}
+	})
+	games.DELETE("/:gameId", func(c *gin.Context) {
+		code, _ := webserviceHandler.RemoveGame(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	// DLCs, expansions and season passes under their base game
+	games.GET("/:gameId/addons", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowGameTree(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewGameTree(message))
+		}
+	})
+	games.PUT("/:gameId/parent", func(c *gin.Context) {
+		code, message := webserviceHandler.AttachAddon(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewGameTree(message))
+		}
+	})
+	games.DELETE("/:gameId/parent", func(c *gin.Context) {
+		code := webserviceHandler.DetachAddon(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+
+	// Mods installed on a game, the profile export lists them and the
+	// import reads them back
+	games.GET("/:gameId/mods", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowMods(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewMods(message))
+		}
+	})
+	games.POST("/:gameId/mods", func(c *gin.Context) {
+		code, message := webserviceHandler.AddMod(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewMod(message))
+		}
+	})
+	games.PUT("/:gameId/mods/:modId", func(c *gin.Context) {
+		code, message := webserviceHandler.EditMod(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewMod(message))
+		}
+	})
+	games.DELETE("/:gameId/mods/:modId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveMod(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+	games.GET("/:gameId/backups", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowBackups(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewSaveBackups(message))
+		}
+	})
+	games.POST("/:gameId/backups", func(c *gin.Context) {
+		code, message := webserviceHandler.AddBackup(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewSaveBackup(message))
+		}
+	})
+	games.DELETE("/:gameId/backups/:backupId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveBackup(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+	libraries.POST("/:libId/mods/import", func(c *gin.Context) {
+		code, message := webserviceHandler.ImportMods(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewModImportReport(message))
+		}
+	})
+
+	admin := engine.Group("/admin")
+	admin.Use(auth.CheckRole(usecases.RoleAdmin, webserviceHandler.TokenSecret))
+	admin.GET("/users", func(c *gin.Context) {
+		code, message := webserviceHandler.ListUsers(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewAdminUsers(message))
+		}
+	})
+	admin.GET("/users/:userId", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowUserAsAdmin(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewAdminUser(message))
+		}
+	})
+	admin.GET("/users/:userId/stats", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowUserStats(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewUserStats(message))
+		}
+	})
+	admin.POST("/users/:userId/suspend", func(c *gin.Context) {
+		code, message := webserviceHandler.SuspendUser(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewAdminUser(message))
+		}
+	})
+	admin.POST("/users/:userId/ban", func(c *gin.Context) {
+		code, message := webserviceHandler.BanUser(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewAdminUser(message))
+		}
+	})
+	admin.POST("/users/:userId/reinstate", func(c *gin.Context) {
+		code, message := webserviceHandler.ReinstateUser(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewAdminUser(message))
+		}
+	})
+	admin.POST("/users/:userId/impersonate", func(c *gin.Context) {
+		message, code := webserviceHandler.Impersonate(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewToken(message))
+		}
+	})
+	admin.GET("/maintenance", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowMaintenance(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewMaintenance(message))
+		}
+	})
+	admin.GET("/diagnostics", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowDiagnostics(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewDiagnostics(message))
+		}
+	})
+	admin.PUT("/maintenance", func(c *gin.Context) {
+		code, message := webserviceHandler.SetMaintenance(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewMaintenance(message))
+		}
+	})
+	admin.GET("/flags", func(c *gin.Context) {
+		code, message := webserviceHandler.ListFlags(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewFeatureFlags(message))
+		}
+	})
+	admin.PUT("/flags/:name", func(c *gin.Context) {
+		code, message := webserviceHandler.SetFlag(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewFeatureFlag(message))
+		}
+	})
+	admin.DELETE("/flags/:name", func(c *gin.Context) {
+		code := webserviceHandler.RemoveFlag(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+	admin.POST("/libraries/:libId/replay", func(c *gin.Context) {
+		code, message := webserviceHandler.ReplayLibrary(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewLibraryReplay(message))
+		}
+	})
+	admin.PUT("/games/:gameId/spoilers", func(c *gin.Context) {
+		code, message := webserviceHandler.FlagSpoilers(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewGameSpoilers(message))
+		}
+	})
+	admin.POST("/franchises", func(c *gin.Context) {
+		code, message := webserviceHandler.AddFranchise(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewFranchise(message))
+		}
+	})
+	admin.DELETE("/franchises/:franchiseId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveFranchise(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+	admin.PUT("/franchises/:franchiseId/games/:gameId", func(c *gin.Context) {
+		code, message := webserviceHandler.AddFranchiseGame(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewFranchise(message))
+		}
+	})
+	admin.DELETE("/franchises/:franchiseId/games/:gameId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveFranchiseGame(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+	// Starlark scripts bound to events, off unless Scripts.Enabled is set
+	admin.GET("/scripts", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowScripts(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewScripts(message))
+		}
+	})
+	admin.POST("/scripts", func(c *gin.Context) {
+		code, message := webserviceHandler.AddScript(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(201, res.ViewScript(message))
+		}
+	})
+	admin.PUT("/scripts/:scriptId", func(c *gin.Context) {
+		code, message := webserviceHandler.EditScript(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewScript(message))
+		}
+	})
+	admin.DELETE("/scripts/:scriptId", func(c *gin.Context) {
+		code := webserviceHandler.RemoveScript(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.Status(204)
+		}
+	})
+	// Rows breaking relations the database does not enforce, repaired on POST
+	admin.GET("/integrity", func(c *gin.Context) {
+		code, message := webserviceHandler.CheckIntegrity(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewIntegrity(message))
+		}
+	})
+	admin.POST("/integrity/repair", func(c *gin.Context) {
+		code, message := webserviceHandler.RepairIntegrity(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewIntegrity(message))
+		}
+	})
+	admin.GET("/metrics", func(c *gin.Context) {
+		code, message := webserviceHandler.ShowMetrics(c)
+		c.Set("code", code)
+		if c.Errors.Last() == nil {
+			c.JSON(200, res.ViewMetrics(message))
+		}
+	})
+	pages := engine.Group("/web")
+	pages.GET("/assets/*file", site.ServeAsset)
+	pages.GET("/login", site.ShowLogin)
+	pages.POST("/login", site.Login)
+	signedIn := pages.Group("")
+	signedIn.Use(site.RequireSession())
+	signedIn.POST("/logout", site.Logout)
+	signedIn.GET("/libraries", site.ShowLibraries)
+	signedIn.GET("/libraries/:libraryId/games/:gameId", site.ShowGame)
+	signedIn.GET("/stats", site.ShowStats)
+
+	return engine
+}
+
+// Sets the validator headers and answers 304 when the client already has this
+// version, If-None-Match takes precedence over If-Modified-Since
+func notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+
+	ifNoneMatch := c.Request.Header.Get("If-None-Match")
+	if ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == etag || candidate == "*" {
+				c.Status(304)
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(c.Request.Header.Get("If-Modified-Since"))
+	if err == nil && !lastModified.Truncate(time.Second).After(since) {
+		c.Status(304)
+		return true
+	}
+	return false
 }
@@ -0,0 +1,109 @@
+package testsupport
+
+import (
+	"fmt"
+	"testing"
+
+	"game-tracker/domain"
+	"game-tracker/interfaces"
+	"game-tracker/usecases"
+)
+
+// Builds users, libraries and games through the Postgres repositories, so
+// fixtures are stored exactly as the API would store them. Failing to store
+// one fails the test.
+type Fixtures struct {
+	Users     usecases.UserRepository
+	Libraries usecases.LibraryRepository
+	Games     usecases.GameRepository
+	t         testing.TB
+	games     int
+}
+
+func NewFixtures(t testing.TB, db *Database) *Fixtures {
+	handlers := map[string]interfaces.DbHandler{"DbUserRepo": db.Handler,
+		"DbPlayerRepo": db.Handler, "DbLibraryRepo": db.Handler, "DbGameRepo": db.Handler}
+	users := interfaces.NewDbUserRepo(handlers, interfaces.NewDbPlayerRepo(handlers))
+	return &Fixtures{
+		Users:     users,
+		Libraries: interfaces.NewDbLibraryRepo(handlers, users),
+		Games:     interfaces.NewDbGameRepo(handlers),
+		t:         t,
+	}
+}
+
+// A user named name whose player has the same name, password is its login
+func (fixtures *Fixtures) UserWithLogin(name, password string) usecases.User {
+	fixtures.t.Helper()
+	user := fixtures.User(name)
+	err := fixtures.Users.AddLoginInfo(name, password)
+	if err != nil {
+		fixtures.t.Fatalf("Cannot add login of user '%s': %v", name, err)
+	}
+	return user
+}
+
+// A user named name whose player has the same name
+func (fixtures *Fixtures) User(name string) usecases.User {
+	fixtures.t.Helper()
+	id, err := fixtures.Users.Store(usecases.User{Name: name, Player: domain.Player{Name: name}})
+	if err != nil {
+		fixtures.t.Fatalf("Cannot store user '%s': %v", name, err)
+	}
+	user, err, _ := fixtures.Users.FindById(id)
+	if err != nil {
+		fixtures.t.Fatalf("Cannot load user #%d: %v", id, err)
+	}
+	return user
+}
+
+// An empty library of user
+func (fixtures *Fixtures) Library(user usecases.User) usecases.Library {
+	fixtures.t.Helper()
+	id, err := fixtures.Libraries.Store(usecases.Library{User: user})
+	if err != nil {
+		fixtures.t.Fatalf("Cannot store library of user #%d: %v", user.Id, err)
+	}
+	library, err, _ := fixtures.Libraries.FindById(id)
+	if err != nil {
+		fixtures.t.Fatalf("Cannot load library #%d: %v", id, err)
+	}
+	return library
+}
+
+// Stores game, named after a counter when it has no name, and adds it to
+// library. Returns the game as found in the library.
+func (fixtures *Fixtures) Game(library usecases.Library, game usecases.Game) usecases.Game {
+	fixtures.t.Helper()
+	if game.Name == "" {
+		fixtures.games++
+		game.Name = fmt.Sprintf("Game %d", fixtures.games)
+	}
+	id, err := fixtures.Games.Store(game)
+	if err != nil {
+		fixtures.t.Fatalf("Cannot store game '%s': %v", game.Name, err)
+	}
+	err, _ = fixtures.Games.AddToLib(id, library.Id, 0)
+	if err != nil {
+		fixtures.t.Fatalf("Cannot add game #%d to library #%d: %v", id, library.Id, err)
+	}
+	stored, err, _ := fixtures.Games.FindInLib(id, library.Id)
+	if err != nil {
+		fixtures.t.Fatalf("Cannot load game #%d of library #%d: %v", id, library.Id, err)
+	}
+	return stored
+}
+
+// A library of a new user named name holding games
+func (fixtures *Fixtures) LibraryWith(name string, games ...usecases.Game) usecases.Library {
+	fixtures.t.Helper()
+	library := fixtures.Library(fixtures.User(name))
+	for _, game := range games {
+		fixtures.Game(library, game)
+	}
+	library, err, _ := fixtures.Libraries.FindById(library.Id)
+	if err != nil {
+		fixtures.t.Fatalf("Cannot load library #%d: %v", library.Id, err)
+	}
+	return library
+}
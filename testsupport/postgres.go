@@ -0,0 +1,276 @@
+// Package testsupport runs repository code against a real Postgres. A
+// container is started with docker unless TEST_POSTGRES_URL points at a
+// server already running (a CI service for instance). The migrations are
+// applied once to a template database every test then gets a copy of.
+//
+//	func TestMain(m *testing.M) {
+//		testsupport.Main(m)
+//	}
+//
+//	func TestStore(t *testing.T) {
+//		db := testsupport.Postgres(t)
+//		fixtures := testsupport.NewFixtures(t, db)
+//		library := fixtures.Library(fixtures.User("alice"))
+//		...
+//	}
+package testsupport
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"game-tracker/infrastructure"
+)
+
+const (
+	postgresImage = "postgres:16-alpine"
+	startTimeout  = 60 * time.Second
+)
+
+// A Postgres server holding the migrated template database. Packages are
+// tested by processes of their own sharing TEST_POSTGRES_URL, so every
+// process names its databases after its own id.
+type Server struct {
+	url       *url.URL
+	container string //Empty when the server was not started here
+	id        string //Pid and a random suffix
+	template  string //Empty until it is created
+	databases int
+	lock      sync.Mutex
+}
+
+// A database of its own, copied from the template so it starts migrated
+type Database struct {
+	Handler *infrastructure.PostgresqlHandler
+	Url     string
+	Name    string
+	server  *Server
+}
+
+// Connects to TEST_POSTGRES_URL, or starts a container, and migrates the
+// template database
+func StartPostgres() (*Server, error) {
+	suffix := make([]byte, 4)
+	_, err := rand.Read(suffix)
+	if err != nil {
+		return nil, err
+	}
+	server := &Server{id: fmt.Sprintf("%d_%s", os.Getpid(), hex.EncodeToString(suffix))}
+	address := os.Getenv("TEST_POSTGRES_URL")
+	if address == "" {
+		address, err = server.run()
+		if err != nil {
+			return nil, err
+		}
+	}
+	parsed, err := url.Parse(address)
+	if err != nil {
+		server.Close()
+		return nil, fmt.Errorf("Invalid Postgres URL: %v", err)
+	}
+	server.url = parsed
+
+	admin, err := server.open("postgres")
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+	defer admin.Close()
+	template := "gametracker_template_" + server.id
+	_, err = admin.Execute(`CREATE DATABASE ` + template)
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+	server.template = template
+
+	handler, err := server.open(template)
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+	dir, err := MigrationsDir()
+	if err == nil {
+		err = infrastructure.Migrate(handler, dir)
+	}
+	// Postgres copies a template only while nobody is connected to it
+	handler.Close()
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+	return server, nil
+}
+
+// Starts a throwaway container publishing Postgres on a free local port
+func (server *Server) run() (string, error) {
+	output, err := exec.Command("docker", "run", "--detach", "--rm",
+		"--env", "POSTGRES_PASSWORD=postgres", "--publish", "127.0.0.1::5432",
+		postgresImage).Output()
+	if err != nil {
+		return "", fmt.Errorf("Cannot start Postgres container: %v", err)
+	}
+	server.container = strings.TrimSpace(string(output))
+	output, err = exec.Command("docker", "port", server.container, "5432/tcp").Output()
+	if err != nil {
+		server.Close()
+		return "", fmt.Errorf("Cannot find Postgres port: %v", err)
+	}
+	address := strings.TrimSpace(strings.SplitN(string(output), "\n", 2)[0])
+	return "postgres://postgres:postgres@" + address + "/postgres?sslmode=disable", nil
+}
+
+// Opens name on the server, waiting for a container that is still booting
+func (server *Server) open(name string) (*infrastructure.PostgresqlHandler, error) {
+	handler, err := infrastructure.NewPostgresqlHandler(server.databaseUrl(name))
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(startTimeout)
+	for {
+		err = handler.DB().Ping()
+		if err == nil {
+			return handler, nil
+		}
+		if time.Now().After(deadline) {
+			handler.Close()
+			return nil, fmt.Errorf("Postgres is not reachable: %v", err)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+func (server *Server) databaseUrl(name string) string {
+	address := *server.url
+	address.Path = "/" + name
+	return address.String()
+}
+
+// Copies the template into a new database
+func (server *Server) NewDatabase() (*Database, error) {
+	server.lock.Lock()
+	server.databases++
+	name := fmt.Sprintf("gametracker_test_%s_%d", server.id, server.databases)
+	server.lock.Unlock()
+
+	admin, err := server.open("postgres")
+	if err != nil {
+		return nil, err
+	}
+	defer admin.Close()
+	_, err = admin.Execute(`CREATE DATABASE ` + name + ` TEMPLATE ` + server.template)
+	if err != nil {
+		return nil, err
+	}
+	handler, err := server.open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Database{Handler: handler, Url: server.databaseUrl(name), Name: name,
+		server: server}, nil
+}
+
+// Drops the database, the template stays for the next one
+func (db *Database) Close() error {
+	db.Handler.Close()
+	admin, err := db.server.open("postgres")
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+	_, err = admin.Execute(`DROP DATABASE IF EXISTS ` + db.Name)
+	return err
+}
+
+// Removes the container, a server given by TEST_POSTGRES_URL is left running
+// without the template of this process
+func (server *Server) Close() error {
+	if server.container != "" {
+		return exec.Command("docker", "rm", "--force", server.container).Run()
+	}
+	if server.template == "" {
+		return nil
+	}
+	admin, err := server.open("postgres")
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+	_, err = admin.Execute(`DROP DATABASE IF EXISTS ` + server.template)
+	return err
+}
+
+// The migrations directory of the repository, looked up from the working
+// directory since go test runs in the directory of the package
+func MigrationsDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		candidate := filepath.Join(dir, "migrations")
+		info, err := os.Stat(candidate)
+		if err == nil && info.IsDir() {
+			return candidate, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("No migrations directory above the working directory")
+		}
+		dir = parent
+	}
+}
+
+var (
+	shared      *Server
+	sharedErr   error
+	sharedStart sync.Once
+)
+
+// A fresh database for t on the server shared by the package's tests, the
+// test is skipped when neither docker nor TEST_POSTGRES_URL is available
+func Postgres(t testing.TB) *Database {
+	t.Helper()
+	if os.Getenv("TEST_POSTGRES_URL") == "" {
+		_, err := exec.LookPath("docker")
+		if err != nil {
+			t.Skip("Needs docker or TEST_POSTGRES_URL")
+		}
+	}
+	sharedStart.Do(func() {
+		shared, sharedErr = StartPostgres()
+	})
+	if sharedErr != nil {
+		t.Fatalf("Cannot start Postgres: %v", sharedErr)
+	}
+	db, err := shared.NewDatabase()
+	if err != nil {
+		t.Fatalf("Cannot create database: %v", err)
+	}
+	t.Cleanup(func() {
+		err := db.Close()
+		if err != nil {
+			t.Logf("Cannot drop database %s: %v", db.Name, err)
+		}
+	})
+	return db
+}
+
+// Runs the tests and removes the shared container afterwards, call it from
+// TestMain of packages using Postgres
+func Main(m *testing.M) {
+	code := m.Run()
+	if shared != nil {
+		shared.Close()
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,143 @@
+package usecases
+
+import (
+	"strconv"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const maxFeedEntries = 50
+
+const (
+	ActivityGameAdded     = "game_added"
+	ActivityGameCompleted = "game_completed"
+	ActivityPlayedWith    = "played_with"
+)
+
+type ActivityRepository interface {
+	Store(activity Activity) error
+	FindByUser(userId, limit int) ([]Activity, error)
+	CountGames(userId int, kind string, from, to time.Time) (int, error) //Distinct games with activity of kind
+	RemoveAll(userId int) error
+}
+
+// Something a user did that shows up on their public profile, the game's
+// name is kept as it was at the time
+type Activity struct {
+	Id             int64
+	UserId         int
+	Kind           string
+	GameId         int
+	GameExternalId string
+	GameName       string
+	Partners       []string //Names of the users a session was played with
+	CreatedAt      time.Time
+}
+
+type ActivityInteractor struct {
+	ActivityRepository    ActivityRepository
+	UserRepository        UserRepository
+	GameRepository        GameRepository
+	SettingsRepository    SettingsRepository
+	PlaySessionRepository PlaySessionRepository
+	logging
+}
+
+// Records activity from domain events, completing a game counts once its
+// status is set to completed
+func (interactor *ActivityInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventGameAdded, func(event domain.Event) {
+		interactor.record(event, ActivityGameAdded)
+	})
+	bus.Subscribe(domain.EventGameStatusChanged, func(event domain.Event) {
+		if event.Payload["status"] == "completed" {
+			interactor.record(event, ActivityGameCompleted)
+		}
+	})
+	bus.Subscribe(domain.EventSessionAdded, func(event domain.Event) {
+		interactor.recordCoop(event)
+	})
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.ActivityRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove activity of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+func (interactor *ActivityInteractor) record(event domain.Event, kind string) {
+	game, err, _ := interactor.GameRepository.FindById(event.EntityId)
+	if err != nil {
+		interactor.logf("Cannot load game #%d for activity: %v", event.EntityId, err)
+		return
+	}
+	err = interactor.ActivityRepository.Store(Activity{UserId: event.UserId, Kind: kind,
+		GameId: game.Id, GameExternalId: game.ExternalId, GameName: game.Name})
+	if err != nil {
+		interactor.logf("Cannot store activity of user #%d: %v", event.UserId, err)
+	}
+}
+
+// Sessions played with others show up once they started, planned ones do
+// not. Partners are only named when their own profile is public.
+func (interactor *ActivityInteractor) recordCoop(event domain.Event) {
+	session, err, _ := interactor.PlaySessionRepository.FindById(event.EntityId)
+	if err != nil {
+		interactor.logf("Cannot load session #%d for activity: %v", event.EntityId, err)
+		return
+	}
+	if len(session.PartnerIds) == 0 || session.StartsAt.After(time.Now()) {
+		return
+	}
+	activity := Activity{UserId: session.UserId, Kind: ActivityPlayedWith, GameId: session.GameId,
+		GameExternalId: session.GameExternalId, GameName: session.GameName}
+	for _, partnerId := range session.PartnerIds {
+		partner, err, _ := interactor.UserRepository.FindById(partnerId)
+		if err != nil {
+			continue
+		}
+		settings, err := loadSettings(interactor.SettingsRepository, partnerId)
+		if err == nil && settings.ProfilePublic && blockedAccount(partner) == nil {
+			activity.Partners = append(activity.Partners, partner.Name)
+		}
+	}
+	err = interactor.ActivityRepository.Store(activity)
+	if err != nil {
+		interactor.logf("Cannot store activity of user #%d: %v", session.UserId, err)
+	}
+}
+
+// The latest activity of the named user, newest first. Users with a
+// private profile are reported as not found.
+func (interactor *ActivityInteractor) ShowFeed(userName string) (User, []Activity, error, int) {
+	user, err, code := interactor.UserRepository.FindByName(domain.NormalizeName(userName), true)
+	if err != nil {
+		return User{}, nil, err, code
+	}
+	settings, err := loadSettings(interactor.SettingsRepository, user.Id)
+	if err != nil {
+		return User{}, nil, err, 500
+	}
+	if !settings.ProfilePublic || blockedAccount(user) != nil {
+		return User{}, nil, domain.NewError(domain.CodeNotFound, "User '%s' does not exist",
+			userName), 404
+	}
+
+	activities, err := interactor.ActivityRepository.FindByUser(user.Id, maxFeedEntries)
+	if err != nil {
+		return User{}, nil, err, 500
+	}
+	return user, activities, nil, 200
+}
+
+// Statuses are announced per game so each completion becomes its own entry
+func statusEvents(userId, libraryId int, gameIds []int, status string) []domain.Event {
+	var events []domain.Event
+	for _, gameId := range gameIds {
+		events = append(events, domain.Event{Name: domain.EventGameStatusChanged, UserId: userId,
+			EntityId: gameId, Payload: map[string]string{"status": status,
+				"libraryId": strconv.Itoa(libraryId)}})
+	}
+	return events
+}
@@ -0,0 +1,189 @@
+package usecases
+
+import (
+	"time"
+
+	"game-tracker/domain"
+)
+
+const (
+	AddonDlc        = "dlc"
+	AddonExpansion  = "expansion"
+	AddonSeasonPass = "season_pass"
+)
+
+var addonKinds = map[string]bool{
+	AddonDlc:        true,
+	AddonExpansion:  true,
+	AddonSeasonPass: true,
+}
+
+// Add-ons hang off a base game one level deep, an add-on has no add-ons of
+// its own
+type AddonRepository interface {
+	Attach(addon Addon) error //Moves an add-on already attached elsewhere
+	Detach(gameId int) (bool, error)
+	FindByGame(gameId int) (Addon, bool, error)
+	FindByParent(parentId int) ([]Addon, error)
+}
+
+type Addon struct {
+	GameId    int
+	ParentId  int
+	Kind      string
+	CreatedAt time.Time
+}
+
+// A base game of a library with its add-ons in the same library. Value and
+// completion add up the base game and every add-on.
+type GameTree struct {
+	Game       Game
+	Addons     []GameAddon
+	TotalValue float64
+	Completed  int //Base game and add-ons with the completed status
+	Total      int
+}
+
+type GameAddon struct {
+	Game Game //Loaded with FindInLib so it carries its own status
+	Kind string
+}
+
+// Completed games in percent of the whole tree
+func (tree GameTree) Completion() int {
+	if tree.Total == 0 {
+		return 0
+	}
+	return tree.Completed * 100 / tree.Total
+}
+
+func (interactor *ProfileInteractor) addonLibrary(userId, libraryId int, role string) (error, int) {
+	library, err, code := interactor.LibraryRepository.FindById(libraryId)
+	if err != nil {
+		return err, code
+	}
+	allowed, err := interactor.libraryAllows(userId, library, role)
+	if err != nil {
+		return err, 500
+	}
+	if !allowed {
+		message := "User #%d is not allowed to see games in library #%d of user #%d"
+		if role == LibraryRoleEditor {
+			message = "User #%d is not allowed to change games in library #%d of user #%d"
+		}
+		return domain.NewError(domain.CodeForbidden, message, userId, library.Id, library.User.Id), 403
+	}
+	return nil, 200
+}
+
+// Makes gameId an add-on of parentId, both must be in the library
+func (interactor *ProfileInteractor) AttachAddon(userId, libraryId, gameId, parentId int, kind string) (GameTree, error, int) {
+	if !addonKinds[kind] {
+		return GameTree{}, domain.NewFieldError("kind", "Must be %s, %s or %s", AddonDlc, AddonExpansion,
+			AddonSeasonPass), 400
+	}
+	if gameId == parentId {
+		return GameTree{}, domain.NewFieldError("parentId", "A game cannot be its own add-on"), 400
+	}
+	err, code := interactor.addonLibrary(userId, libraryId, LibraryRoleEditor)
+	if err != nil {
+		return GameTree{}, err, code
+	}
+	for _, id := range []int{gameId, parentId} {
+		_, err, code = interactor.GameRepository.FindInLib(id, libraryId)
+		if err != nil {
+			return GameTree{}, err, code
+		}
+	}
+	_, isAddon, err := interactor.AddonRepository.FindByGame(parentId)
+	if err != nil {
+		return GameTree{}, err, 500
+	}
+	if isAddon {
+		return GameTree{}, domain.NewFieldError("parentId", "Game #%d is an add-on itself", parentId), 400
+	}
+	addons, err := interactor.AddonRepository.FindByParent(gameId)
+	if err != nil {
+		return GameTree{}, err, 500
+	}
+	if len(addons) > 0 {
+		return GameTree{}, domain.NewError(domain.CodeConflict,
+			"Game #%d has add-ons of its own, detach them first", gameId), 409
+	}
+
+	err = interactor.AddonRepository.Attach(Addon{GameId: gameId, ParentId: parentId, Kind: kind})
+	if err != nil {
+		return GameTree{}, err, 500
+	}
+	interactor.logf("User #%d attached game #%d to game #%d as %s", userId, gameId, parentId, kind)
+	return interactor.gameTree(libraryId, parentId)
+}
+
+// Turns an add-on back into a game of its own
+func (interactor *ProfileInteractor) DetachAddon(userId, libraryId, gameId int) (error, int) {
+	err, code := interactor.addonLibrary(userId, libraryId, LibraryRoleEditor)
+	if err != nil {
+		return err, code
+	}
+	_, err, code = interactor.GameRepository.FindInLib(gameId, libraryId)
+	if err != nil {
+		return err, code
+	}
+	detached, err := interactor.AddonRepository.Detach(gameId)
+	if err != nil {
+		return err, 500
+	}
+	if !detached {
+		return domain.NewError(domain.CodeNotFound, "Game #%d is not an add-on", gameId), 404
+	}
+	interactor.logf("User #%d detached add-on #%d", userId, gameId)
+	return nil, 200
+}
+
+// The tree of the game's base game, so asking with an add-on shows its parent
+func (interactor *ProfileInteractor) ShowGameTree(userId, libraryId, gameId int) (GameTree, error, int) {
+	err, code := interactor.addonLibrary(userId, libraryId, LibraryRoleViewer)
+	if err != nil {
+		return GameTree{}, err, code
+	}
+	addon, isAddon, err := interactor.AddonRepository.FindByGame(gameId)
+	if err != nil {
+		return GameTree{}, err, 500
+	}
+	if isAddon {
+		gameId = addon.ParentId
+	}
+	return interactor.gameTree(libraryId, gameId)
+}
+
+// Add-ons missing from the library are left out
+func (interactor *ProfileInteractor) gameTree(libraryId, parentId int) (GameTree, error, int) {
+	game, err, code := interactor.GameRepository.FindInLib(parentId, libraryId)
+	if err != nil {
+		return GameTree{}, err, code
+	}
+	addons, err := interactor.AddonRepository.FindByParent(parentId)
+	if err != nil {
+		return GameTree{}, err, 500
+	}
+	tree := GameTree{Game: game, TotalValue: game.Value, Total: 1}
+	if game.Status == "completed" {
+		tree.Completed++
+	}
+	for _, addon := range addons {
+		addonGame, err, code := interactor.GameRepository.FindInLib(addon.GameId, libraryId)
+		if code == 404 {
+			continue
+		}
+		if err != nil {
+			return GameTree{}, err, code
+		}
+		tree.Addons = append(tree.Addons, GameAddon{Game: addonGame, Kind: addon.Kind})
+		tree.TotalValue += addonGame.Value
+		tree.Total++
+		if addonGame.Status == "completed" {
+			tree.Completed++
+		}
+	}
+	return tree, nil, 200
+}
@@ -0,0 +1,214 @@
+package usecases
+
+import (
+	"time"
+
+	"game-tracker/domain"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+const (
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	StatusBanned    = "banned"
+)
+
+var userStatuses = map[string]bool{StatusActive: true, StatusSuspended: true, StatusBanned: true}
+
+const (
+	AuditImpersonate = "impersonate"
+	AuditStatus      = "status"
+	AuditMaintenance = "maintenance"
+	AuditFlag        = "flag"
+	AuditSpoilers    = "spoilers"
+	AuditTrade       = "trade"  //Copies moved by an accepted trade, not an admin action
+	AuditScript      = "script" //Scripts being changed and every action they take
+	AuditIntegrity   = "integrity"
+)
+
+const maxUsersPerPage = 100
+
+type AdminRepository interface {
+	FindUsers(filter UserFilter) ([]User, error)
+	UserStats(userId int) (UserStats, error)
+	Metrics() (Metrics, error)
+	SetStatus(userId int, change StatusChange) error
+	Audit(entry AuditEntry) error
+}
+
+// Empty fields match every user, Name matches part of the username. Users
+// are listed oldest first, by id alone.
+type UserFilter struct {
+	Name   string
+	Role   string
+	Status string
+	Page   Page
+}
+
+type UserStats struct {
+	UserId        int
+	Libraries     int
+	Games         int //Entries over all libraries, a game in two libraries counts twice
+	Notifications int
+	Changes       int
+	InfoBytes     *int //Nil once the user is removed
+}
+
+type Metrics struct {
+	Users         int
+	Players       int
+	Libraries     int
+	Games         int
+	GameEntries   int
+	UsersByStatus map[string]int
+}
+
+// Every action an admin takes on an account is recorded
+type AuditEntry struct {
+	Id        int64
+	ActorId   int
+	Action    string
+	TargetId  int
+	Detail    string
+	CreatedAt time.Time
+}
+
+type AdminInteractor struct {
+	AdminRepository AdminRepository
+	UserRepository  UserRepository
+	GameRepository  GameRepository
+	Flags           *FlagService
+	logging
+}
+
+// The role in the token may be stale, the stored one decides
+func (interactor *AdminInteractor) requireAdmin(adminId int) (error, int) {
+	admin, err, code := interactor.UserRepository.FindById(adminId)
+	if err != nil {
+		return err, code
+	}
+	if admin.Role != RoleAdmin || admin.StatusAt(time.Now()) != StatusActive {
+		return domain.NewError(domain.CodeForbidden, "Admin role required"), 403
+	}
+	return nil, 200
+}
+
+// The cursor of the next page is zero on the last page
+func (interactor *AdminInteractor) ListUsers(adminId int, filter UserFilter) ([]User, Cursor, error, int) {
+	err, code := interactor.requireAdmin(adminId)
+	if err != nil {
+		return nil, Cursor{}, err, code
+	}
+	err = validPage(filter.Page, maxUsersPerPage)
+	if err != nil {
+		return nil, Cursor{}, err, 400
+	}
+	if filter.Page.After.Key != "" {
+		return nil, Cursor{}, domain.NewFieldError("cursor", "Cursor is invalid"), 400
+	}
+	if filter.Status != "" && !userStatuses[filter.Status] {
+		return nil, Cursor{}, domain.NewFieldError("status", "Unknown status '%s'", filter.Status), 400
+	}
+	page := filter.Page
+	filter.Page = page.probe()
+	users, err := interactor.AdminRepository.FindUsers(filter)
+	if err != nil {
+		return nil, Cursor{}, err, 500
+	}
+	count, more := page.cut(len(users))
+	users = users[:count]
+	var next Cursor
+	if more {
+		next = Cursor{Id: users[count-1].Id}
+	}
+	return users, next, nil, 200
+}
+
+func (interactor *AdminInteractor) ShowUser(adminId, userId int) (User, error, int) {
+	err, code := interactor.requireAdmin(adminId)
+	if err != nil {
+		return User{}, err, code
+	}
+	return interactor.UserRepository.FindById(userId)
+}
+
+func (interactor *AdminInteractor) ShowUserStats(adminId, userId int) (UserStats, error, int) {
+	err, code := interactor.requireAdmin(adminId)
+	if err != nil {
+		return UserStats{}, err, code
+	}
+	_, err, code = interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return UserStats{}, err, code
+	}
+	stats, err := interactor.AdminRepository.UserStats(userId)
+	if err != nil {
+		return UserStats{}, err, 500
+	}
+	return stats, nil, 200
+}
+
+func (interactor *AdminInteractor) ShowMetrics(adminId int) (Metrics, error, int) {
+	err, code := interactor.requireAdmin(adminId)
+	if err != nil {
+		return Metrics{}, err, code
+	}
+	metrics, err := interactor.AdminRepository.Metrics()
+	if err != nil {
+		return Metrics{}, err, 500
+	}
+	return metrics, nil, 200
+}
+
+// Lets support act as a user, the reason is kept in the audit log. The
+// caller issues the token, no refresh token is handed out.
+func (interactor *AdminInteractor) Impersonate(adminId, userId int, reason string) (User, error, int) {
+	err, code := interactor.requireAdmin(adminId)
+	if err != nil {
+		return User{}, err, code
+	}
+	if reason == "" {
+		return User{}, domain.NewFieldError("reason", "A reason is required"), 400
+	}
+	user, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return User{}, err, code
+	}
+	if user.Role == RoleAdmin {
+		return User{}, domain.NewError(domain.CodeForbidden, "Admins cannot be impersonated"), 403
+	}
+	err = interactor.AdminRepository.Audit(AuditEntry{ActorId: adminId,
+		Action: AuditImpersonate, TargetId: userId, Detail: reason})
+	if err != nil {
+		return User{}, err, 500
+	}
+	interactor.logf("Admin #%d impersonates user #%d", adminId, userId)
+	return user, nil, 200
+}
+
+// Checks the admin and records the change, the caller flips the switch
+func (interactor *AdminInteractor) ToggleMaintenance(adminId int, enabled bool, reason string) (error, int) {
+	err, code := interactor.requireAdmin(adminId)
+	if err != nil {
+		return err, code
+	}
+	detail := "off"
+	if enabled {
+		detail = "on: " + reason
+	}
+	err = interactor.AdminRepository.Audit(AuditEntry{ActorId: adminId,
+		Action: AuditMaintenance, Detail: detail})
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("Admin #%d turned maintenance %s", adminId, detail)
+	return nil, 200
+}
+
+func (interactor *AdminInteractor) Authorize(adminId int) (error, int) {
+	return interactor.requireAdmin(adminId)
+}
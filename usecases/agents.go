@@ -0,0 +1,326 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"game-tracker/domain"
+)
+
+const (
+	AgentProcessStarted = "started"
+	AgentProcessStopped = "stopped"
+
+	maxAgents           = 10   //Per user
+	maxExecutables      = 1000 //Mapped per user
+	maxAgentNameLength  = 100
+	maxExecutableLength = 255
+	maxAgentClockSkew   = 5 * time.Minute //How far ahead of the server an agent's clock may run
+)
+
+// Desktop agents report the processes they see with a token of their own,
+// only a hash of it is stored
+type AgentRepository interface {
+	Store(agent Agent) (int, error)
+	FindByTokenHash(tokenHash string) (Agent, bool, error)
+	FindByUser(userId int) ([]Agent, error) //Oldest first
+	MarkSeen(id int, at time.Time) error
+	Remove(agent Agent) error
+	RemoveAll(userId int) error
+}
+
+type ExecutableRepository interface {
+	Map(mapping ExecutableGame) error //Replaces the game of an executable already mapped
+	FindGame(userId int, executable string) (ExecutableGame, bool, error)
+	FindByUser(userId int) ([]ExecutableGame, error) //By executable
+	Unmap(userId int, executable string) (bool, error)
+	Start(process AgentProcess) error //Replaces a start not followed by a stop
+	Stop(userId int, executable string) (AgentProcess, bool, error)
+	RemoveAll(userId int) error
+}
+
+type Agent struct {
+	Id         int
+	UserId     int
+	Name       string //Such as "Living room PC"
+	TokenHash  string
+	LastSeenAt time.Time //Zero until the agent reported anything
+	CreatedAt  time.Time
+}
+
+// Executables are stored as lowercase file names without their directory
+type ExecutableGame struct {
+	UserId         int
+	Executable     string
+	GameId         int
+	GameExternalId string
+	GameName       string
+}
+
+// A mapped game the agent saw start and that did not stop yet
+type AgentProcess struct {
+	UserId     int
+	Executable string
+	GameId     int
+	AgentId    int
+	StartedAt  time.Time
+}
+
+// What an agent reports, At is zero when it happened just now
+type AgentEvent struct {
+	Executable string
+	Event      string
+	At         time.Time
+}
+
+// The answer to an agent, Session is set once a stop logged one
+type AgentReport struct {
+	Executable string
+	Event      string
+	Mapped     bool
+	Game       ExecutableGame
+	Session    PlaySession
+}
+
+type AgentInteractor struct {
+	AgentRepository      AgentRepository
+	ExecutableRepository ExecutableRepository
+	Calendar             CalendarInteractor //Sessions go through it so they are checked as any other
+	logging
+}
+
+func (interactor *AgentInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.AgentRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove agents of user #%d: %v", event.UserId, err)
+		}
+		err = interactor.ExecutableRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove executables of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+// The file name of an executable in lowercase, agents on Windows and
+// elsewhere send full paths with either separator
+func executableName(executable string) string {
+	executable = strings.TrimSpace(executable)
+	if i := strings.LastIndexAny(executable, `/\`); i >= 0 {
+		executable = executable[i+1:]
+	}
+	return strings.ToLower(executable)
+}
+
+func validExecutable(executable string) (string, error) {
+	executable = executableName(executable)
+	if executable == "" || utf8.RuneCountInString(executable) > maxExecutableLength {
+		return "", domain.NewFieldError("executable", "Must be between 1 and %d characters",
+			maxExecutableLength)
+	}
+	return executable, nil
+}
+
+// Registers an agent, the returned token is its only credential and is not
+// shown again
+func (interactor *AgentInteractor) AddAgent(userId int, name string) (Agent, string, error, int) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxAgentNameLength {
+		return Agent{}, "", domain.NewFieldError("name", "Must be between 1 and %d characters",
+			maxAgentNameLength), 400
+	}
+	_, err, code := interactor.Calendar.UserRepository.FindById(userId)
+	if err != nil {
+		return Agent{}, "", err, code
+	}
+	agents, err := interactor.AgentRepository.FindByUser(userId)
+	if err != nil {
+		return Agent{}, "", err, 500
+	}
+	if len(agents) >= maxAgents {
+		return Agent{}, "", domain.NewError(domain.CodeConflict,
+			"User #%d already has %d agents, remove one first", userId, maxAgents), 409
+	}
+
+	bytes := make([]byte, 24)
+	_, err = rand.Read(bytes)
+	if err != nil {
+		return Agent{}, "", err, 500
+	}
+	token := hex.EncodeToString(bytes)
+	agent := Agent{UserId: userId, Name: name, TokenHash: hashToken(token), CreatedAt: time.Now().UTC()}
+	agent.Id, err = interactor.AgentRepository.Store(agent)
+	if err != nil {
+		return Agent{}, "", err, 500
+	}
+	interactor.logf("User #%d added agent #%d", userId, agent.Id)
+	return agent, token, nil, 201
+}
+
+func (interactor *AgentInteractor) ShowAgents(userId int) ([]Agent, error, int) {
+	_, err, code := interactor.Calendar.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	agents, err := interactor.AgentRepository.FindByUser(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	return agents, nil, 200
+}
+
+func (interactor *AgentInteractor) RemoveAgent(userId, agentId int) (error, int) {
+	agents, err, code := interactor.ShowAgents(userId)
+	if err != nil {
+		return err, code
+	}
+	for _, agent := range agents {
+		if agent.Id == agentId {
+			err = interactor.AgentRepository.Remove(agent)
+			if err != nil {
+				return err, 500
+			}
+			interactor.logf("User #%d removed agent #%d", userId, agentId)
+			return nil, 200
+		}
+	}
+	return domain.NewError(domain.CodeNotFound, "Agent #%d does not exist", agentId), 404
+}
+
+// Points an executable at a game the user owns
+func (interactor *AgentInteractor) MapExecutable(userId int, executable string, gameId int) (ExecutableGame, error, int) {
+	executable, err := validExecutable(executable)
+	if err != nil {
+		return ExecutableGame{}, err, 400
+	}
+	game, err, code := interactor.Calendar.ownedGame(userId, gameId)
+	if err != nil {
+		return ExecutableGame{}, err, code
+	}
+	_, found, err := interactor.ExecutableRepository.FindGame(userId, executable)
+	if err != nil {
+		return ExecutableGame{}, err, 500
+	}
+	if !found {
+		mappings, err := interactor.ExecutableRepository.FindByUser(userId)
+		if err != nil {
+			return ExecutableGame{}, err, 500
+		}
+		if len(mappings) >= maxExecutables {
+			return ExecutableGame{}, domain.NewError(domain.CodeConflict,
+				"User #%d already mapped %d executables, remove one first", userId, maxExecutables), 409
+		}
+	}
+
+	mapping := ExecutableGame{UserId: userId, Executable: executable, GameId: game.Id,
+		GameExternalId: game.ExternalId, GameName: game.Name}
+	err = interactor.ExecutableRepository.Map(mapping)
+	if err != nil {
+		return ExecutableGame{}, err, 500
+	}
+	interactor.logf("User #%d mapped %s to game #%d", userId, executable, game.Id)
+	return mapping, nil, 200
+}
+
+func (interactor *AgentInteractor) ShowExecutables(userId int) ([]ExecutableGame, error, int) {
+	_, err, code := interactor.Calendar.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	mappings, err := interactor.ExecutableRepository.FindByUser(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	return mappings, nil, 200
+}
+
+func (interactor *AgentInteractor) UnmapExecutable(userId int, executable string) (error, int) {
+	name := executableName(executable)
+	removed, err := interactor.ExecutableRepository.Unmap(userId, name)
+	if err != nil {
+		return err, 500
+	}
+	if !removed {
+		return domain.NewError(domain.CodeNotFound, "Executable '%s' is not mapped", executable), 404
+	}
+	return nil, 200
+}
+
+// Handles a process an agent saw start or stop. Executables that are not
+// mapped are acknowledged and otherwise ignored, a stop after a start of a
+// mapped game logs the time between them as a play session.
+func (interactor *AgentInteractor) ReportProcess(token string, event AgentEvent) (AgentReport, error, int) {
+	agent, found, err := interactor.AgentRepository.FindByTokenHash(hashToken(token))
+	if err != nil {
+		return AgentReport{}, err, 500
+	}
+	if !found {
+		return AgentReport{}, domain.NewError(domain.CodeUnauthorized, "Agent token is invalid"), 401
+	}
+	executable, err := validExecutable(event.Executable)
+	if err != nil {
+		return AgentReport{}, err, 400
+	}
+	if event.Event != AgentProcessStarted && event.Event != AgentProcessStopped {
+		return AgentReport{}, domain.NewFieldError("event", "Must be %s or %s", AgentProcessStarted,
+			AgentProcessStopped), 400
+	}
+	now := time.Now().UTC()
+	at := event.At.UTC()
+	if event.At.IsZero() || at.After(now) {
+		if at.After(now.Add(maxAgentClockSkew)) {
+			return AgentReport{}, domain.NewFieldError("at", "Cannot be in the future"), 400
+		}
+		at = now
+	}
+	err = interactor.AgentRepository.MarkSeen(agent.Id, now)
+	if err != nil {
+		return AgentReport{}, err, 500
+	}
+
+	report := AgentReport{Executable: executable, Event: event.Event}
+	mapping, mapped, err := interactor.ExecutableRepository.FindGame(agent.UserId, executable)
+	if err != nil {
+		return AgentReport{}, err, 500
+	}
+	if event.Event == AgentProcessStarted {
+		if !mapped {
+			return report, nil, 200
+		}
+		report.Mapped, report.Game = true, mapping
+		err = interactor.ExecutableRepository.Start(AgentProcess{UserId: agent.UserId,
+			Executable: executable, GameId: mapping.GameId, AgentId: agent.Id, StartedAt: at})
+		if err != nil {
+			return AgentReport{}, err, 500
+		}
+		return report, nil, 200
+	}
+
+	// The game of the start counts, the mapping may have changed since
+	process, started, err := interactor.ExecutableRepository.Stop(agent.UserId, executable)
+	if err != nil {
+		return AgentReport{}, err, 500
+	}
+	report.Mapped, report.Game = mapped, mapping
+	if !started {
+		return report, nil, 200
+	}
+	minutes := int(at.Sub(process.StartedAt).Round(time.Minute) / time.Minute)
+	if minutes < 1 || minutes > maxSessionMinutes {
+		//Too short to count, or a stop the agent missed
+		return report, nil, 200
+	}
+	session, err, code := interactor.Calendar.AddSession(agent.UserId, process.GameId, process.StartedAt,
+		minutes, fmt.Sprintf("Detected by %s", agent.Name), nil)
+	if err != nil {
+		return AgentReport{}, err, code
+	}
+	report.Mapped, report.Session = true, session
+	report.Game = ExecutableGame{UserId: agent.UserId, Executable: executable, GameId: session.GameId,
+		GameExternalId: session.GameExternalId, GameName: session.GameName}
+	return report, nil, 200
+}
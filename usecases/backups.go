@@ -0,0 +1,156 @@
+package usecases
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"game-tracker/domain"
+)
+
+const (
+	maxBackupsPerGame       = 1000
+	maxBackupLocationLength = 1000
+	maxBackupChecksumLength = 140
+)
+
+// Hex digits, optionally named after their algorithm such as "sha256:"
+var checksumPattern = regexp.MustCompile(`^([a-z0-9-]{1,10}:)?[0-9a-f]{8,128}$`)
+
+type SaveBackupRepository interface {
+	Store(backup SaveBackup) (int, error)
+	FindById(id int) (SaveBackup, error, int)
+	FindByGame(libraryId, gameId int) ([]SaveBackup, error) //Newest first
+	FindStale(before time.Time) ([]SaveBackup, error)       //The latest backup of each game, older than before and not reminded yet
+	MarkReminded(id int, at time.Time) error
+	Remove(backup SaveBackup) error
+}
+
+// Where and when the save files of a game were backed up, the files
+// themselves are never uploaded
+type SaveBackup struct {
+	Id             int
+	UserId         int //Who made the backup, reminded once it gets old
+	LibraryId      int
+	GameId         int
+	GameExternalId string
+	GameName       string
+	Location       string //A local path or a cloud location such as "s3://saves/elden-ring"
+	SizeBytes      int64
+	Checksum       string
+	BackedUpAt     time.Time
+	RemindedAt     time.Time //Zero until the owner was reminded of it
+	CreatedAt      time.Time
+}
+
+func validBackup(backup SaveBackup, now time.Time) (SaveBackup, error) {
+	backup.Location = strings.TrimSpace(backup.Location)
+	backup.Checksum = strings.ToLower(strings.TrimSpace(backup.Checksum))
+	if backup.Location == "" || utf8.RuneCountInString(backup.Location) > maxBackupLocationLength {
+		return SaveBackup{}, domain.NewFieldError("location", "Must be between 1 and %d characters",
+			maxBackupLocationLength)
+	}
+	if backup.SizeBytes < 0 {
+		return SaveBackup{}, domain.NewFieldError("size", "Cannot be negative")
+	}
+	if backup.Checksum != "" && (len(backup.Checksum) > maxBackupChecksumLength ||
+		!checksumPattern.MatchString(backup.Checksum)) {
+		return SaveBackup{}, domain.NewFieldError("checksum", "Must be a hexadecimal checksum")
+	}
+	if backup.BackedUpAt.IsZero() {
+		backup.BackedUpAt = now
+	}
+	if backup.BackedUpAt.After(now) {
+		return SaveBackup{}, domain.NewFieldError("backedUpAt", "Cannot be in the future")
+	}
+	backup.BackedUpAt = backup.BackedUpAt.UTC()
+	return backup, nil
+}
+
+func (interactor *ProfileInteractor) AddBackup(userId, libraryId, gameId int, backup SaveBackup) (SaveBackup, error, int) {
+	backup, err := validBackup(backup, time.Now())
+	if err != nil {
+		return SaveBackup{}, err, 400
+	}
+	game, err, code := interactor.modGame(userId, libraryId, gameId, LibraryRoleEditor)
+	if err != nil {
+		return SaveBackup{}, err, code
+	}
+	backups, err := interactor.SaveBackupRepository.FindByGame(libraryId, gameId)
+	if err != nil {
+		return SaveBackup{}, err, 500
+	}
+	if len(backups) >= maxBackupsPerGame {
+		return SaveBackup{}, domain.NewError(domain.CodeConflict,
+			"Game #%d already has %d backups, remove one first", gameId, maxBackupsPerGame), 409
+	}
+
+	backup.UserId, backup.LibraryId, backup.GameId = userId, libraryId, gameId
+	backup.GameExternalId, backup.GameName = game.ExternalId, game.Name
+	id, err := interactor.SaveBackupRepository.Store(backup)
+	if err != nil {
+		return SaveBackup{}, err, 500
+	}
+	interactor.logf("User #%d recorded backup #%d of game #%d", userId, id, gameId)
+	backup, err, code = interactor.SaveBackupRepository.FindById(id)
+	if err != nil {
+		return SaveBackup{}, err, code
+	}
+	return backup, nil, 201
+}
+
+func (interactor *ProfileInteractor) ShowBackups(userId, libraryId, gameId int) ([]SaveBackup, error, int) {
+	_, err, code := interactor.modGame(userId, libraryId, gameId, LibraryRoleViewer)
+	if err != nil {
+		return nil, err, code
+	}
+	backups, err := interactor.SaveBackupRepository.FindByGame(libraryId, gameId)
+	if err != nil {
+		return nil, err, 500
+	}
+	return backups, nil, 200
+}
+
+func (interactor *ProfileInteractor) RemoveBackup(userId, libraryId, gameId, backupId int) (error, int) {
+	_, err, code := interactor.modGame(userId, libraryId, gameId, LibraryRoleEditor)
+	if err != nil {
+		return err, code
+	}
+	backup, err, code := interactor.SaveBackupRepository.FindById(backupId)
+	if code == 404 || (err == nil && (backup.LibraryId != libraryId || backup.GameId != gameId)) {
+		return domain.NewError(domain.CodeNotFound, "Backup #%d does not exist", backupId), 404
+	}
+	if err != nil {
+		return err, code
+	}
+	err = interactor.SaveBackupRepository.Remove(backup)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("User #%d removed backup #%d", userId, backupId)
+	return nil, 200
+}
+
+// Run by the backup job: whoever made the latest backup of a game is
+// reminded once when it is older than maxAge, a new backup starts over
+func (interactor *ProfileInteractor) RemindBackups(maxAge time.Duration) error {
+	now := time.Now().UTC()
+	backups, err := interactor.SaveBackupRepository.FindStale(now.Add(-maxAge))
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups {
+		err = interactor.SaveBackupRepository.MarkReminded(backup.Id, now)
+		if err != nil {
+			return err
+		}
+		interactor.publish(domain.Event{Name: domain.EventBackupStale, UserId: backup.UserId,
+			EntityId: backup.Id, Payload: map[string]string{"name": backup.GameName,
+				"date": backup.BackedUpAt.Format("2006-01-02")}})
+	}
+	if len(backups) > 0 {
+		interactor.logf("Reminded users of %d games not backed up recently", len(backups))
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package usecases
+
+import (
+	"sort"
+	"time"
+
+	"game-tracker/domain"
+)
+
+// Badges once earned are kept, even when the library shrinks again
+type BadgeRepository interface {
+	Award(userId int, badgeId string) (bool, error) //False when the user already had it
+	FindByUser(userId int) ([]EarnedBadge, error)
+	RemoveAll(userId int) error
+}
+
+type EarnedBadge struct {
+	UserId   int
+	BadgeId  string
+	EarnedAt time.Time
+}
+
+// What badge rules are checked against, gathered from the user's own
+// libraries and sessions
+type BadgeStats struct {
+	Minutes       int //Played so far, planned sessions do not count
+	Completed     int //Games with the completed status in any library
+	Worth         map[string]float64
+	LongestStreak int
+}
+
+// A badge is earned once Progress reaches Goal
+type BadgeRule struct {
+	Id          string
+	Name        string
+	Description string
+	Goal        int
+	Progress    func(stats BadgeStats) int
+}
+
+func playedHours(stats BadgeStats) int { return stats.Minutes / 60 }
+
+func completedGames(stats BadgeStats) int { return stats.Completed }
+
+func longestStreak(stats BadgeStats) int { return stats.LongestStreak }
+
+// Currencies are never converted, the one worth the most counts
+func highestWorth(stats BadgeStats) int {
+	highest := 0.0
+	for _, value := range stats.Worth {
+		if value > highest {
+			highest = value
+		}
+	}
+	return int(highest)
+}
+
+var badgeRules = []BadgeRule{
+	{"hours-10", "Warming up", "Play for 10 hours", 10, playedHours},
+	{"hours-100", "Dedicated", "Play for 100 hours", 100, playedHours},
+	{"hours-1000", "Lifer", "Play for 1000 hours", 1000, playedHours},
+	{"completions-1", "Credits rolled", "Complete a game", 1, completedGames},
+	{"completions-10", "Finisher", "Complete 10 games", 10, completedGames},
+	{"completions-50", "Completionist", "Complete 50 games", 50, completedGames},
+	{"streak-7", "Week streak", "Play 7 days in a row", 7, longestStreak},
+	{"streak-30", "Month streak", "Play 30 days in a row", 30, longestStreak},
+	{"streak-100", "Unstoppable", "Play 100 days in a row", 100, longestStreak},
+	{"worth-1000", "Collector", "Own physical copies worth 1000 in one currency", 1000, highestWorth},
+}
+
+// A badge as listed to its user, earned or with the progress towards it
+type Badge struct {
+	Rule     BadgeRule
+	Progress int //Capped at the goal
+	Earned   bool
+	EarnedAt time.Time
+}
+
+type BadgeInteractor struct {
+	BadgeRepository        BadgeRepository
+	UserRepository         UserRepository
+	GameRepository         GameRepository
+	PlaySessionRepository  PlaySessionRepository
+	PhysicalCopyRepository PhysicalCopyRepository
+	SettingsRepository     SettingsRepository
+	EventBus               domain.EventBus
+	logging
+}
+
+// Badges are checked again whenever something they count changes. Worth
+// changes with the pricing job and is caught up on when badges are listed.
+func (interactor *BadgeInteractor) Subscribe(bus domain.EventBus) {
+	evaluate := func(event domain.Event) {
+		_, err := interactor.EvaluateBadges(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot evaluate badges of user #%d: %v", event.UserId, err)
+		}
+	}
+	bus.Subscribe(domain.EventGameStatusChanged, evaluate)
+	bus.Subscribe(domain.EventSessionAdded, evaluate)
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.BadgeRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove badges of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+// Awards every badge the user has reached and not earned yet, running it
+// again awards nothing new. Returns the badges awarded now.
+func (interactor *BadgeInteractor) EvaluateBadges(userId int) ([]EarnedBadge, error) {
+	stats, err := interactor.badgeStats(userId)
+	if err != nil {
+		return nil, err
+	}
+	var awarded []EarnedBadge
+	for _, rule := range badgeRules {
+		if rule.Progress(stats) < rule.Goal {
+			continue
+		}
+		added, err := interactor.BadgeRepository.Award(userId, rule.Id)
+		if err != nil {
+			return awarded, err
+		}
+		if !added {
+			continue
+		}
+		awarded = append(awarded, EarnedBadge{UserId: userId, BadgeId: rule.Id})
+		interactor.logf("User #%d earned badge %s", userId, rule.Id)
+		if interactor.EventBus != nil {
+			interactor.EventBus.Publish(domain.Event{Name: domain.EventBadgeEarned, UserId: userId,
+				Payload: map[string]string{"badge": rule.Id, "name": rule.Name}})
+		}
+	}
+	return awarded, nil
+}
+
+// Earned badges first in the order they were earned, then the upcoming
+// ones closest to their goal first
+func (interactor *BadgeInteractor) ShowBadges(userId int) ([]Badge, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	_, err = interactor.EvaluateBadges(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	stats, err := interactor.badgeStats(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	earned, err := interactor.BadgeRepository.FindByUser(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	earnedAt := make(map[string]time.Time)
+	var badges []Badge
+	for _, badge := range earned {
+		earnedAt[badge.BadgeId] = badge.EarnedAt
+		for _, rule := range badgeRules {
+			if rule.Id == badge.BadgeId {
+				badges = append(badges, Badge{Rule: rule, Progress: rule.Goal, Earned: true,
+					EarnedAt: badge.EarnedAt})
+			}
+		}
+	}
+	var upcoming []Badge
+	for _, rule := range badgeRules {
+		if _, ok := earnedAt[rule.Id]; ok {
+			continue
+		}
+		progress := rule.Progress(stats)
+		if progress > rule.Goal {
+			progress = rule.Goal
+		}
+		upcoming = append(upcoming, Badge{Rule: rule, Progress: progress})
+	}
+	share := func(badge Badge) float64 { return float64(badge.Progress) / float64(badge.Rule.Goal) }
+	sort.SliceStable(upcoming, func(i, j int) bool { return share(upcoming[i]) > share(upcoming[j]) })
+	return append(badges, upcoming...), nil, 200
+}
+
+func (interactor *BadgeInteractor) badgeStats(userId int) (BadgeStats, error) {
+	user, err, _ := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return BadgeStats{}, err
+	}
+	now := time.Now()
+	sessions, err := interactor.PlaySessionRepository.FindByUser(userId, time.Time{}, now)
+	if err != nil {
+		return BadgeStats{}, err
+	}
+	stats := BadgeStats{Worth: make(map[string]float64)}
+	for _, session := range sessions {
+		stats.Minutes += session.Minutes
+	}
+	stats.LongestStreak = computeStreak(sessions, now,
+		userLocation(interactor.SettingsRepository, userId)).Longest
+
+	completed := make(map[int]bool)
+	for _, libraryId := range user.LibraryIds {
+		games, err := interactor.GameRepository.FindByLib(libraryId, GameFilter{}, Page{})
+		if err != nil {
+			return BadgeStats{}, err
+		}
+		for _, game := range games {
+			if game.Status == "completed" {
+				completed[game.Id] = true
+			}
+		}
+		copies, err := interactor.PhysicalCopyRepository.FindByLib(libraryId, Page{})
+		if err != nil {
+			return BadgeStats{}, err
+		}
+		for _, physical := range copies {
+			if physical.ValueCurrency != "" {
+				stats.Worth[physical.ValueCurrency] += physical.Value
+			}
+		}
+	}
+	stats.Completed = len(completed)
+	return stats, nil
+}
@@ -0,0 +1,232 @@
+package usecases
+
+import (
+	"strings"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const maxCopiesPerPage = 100
+
+// A retail product as known to a barcode database
+type BarcodeProduct struct {
+	Name     string
+	Platform string //Empty when the database does not say
+}
+
+// Resolves UPC and EAN barcodes to products
+type BarcodeProvider interface {
+	Lookup(barcode string) (BarcodeProduct, bool, error) //False when the barcode is unknown
+}
+
+type PhysicalCopyRepository interface {
+	Store(physical PhysicalCopy) (int, error)
+	FindById(id int) (PhysicalCopy, error, int)
+	FindByLib(libraryId int, page Page) ([]PhysicalCopy, error) //By game name, a zero page for every copy
+	Remove(physical PhysicalCopy) error
+	RemoveFromLib(libraryId, gameId int) error     //A zero gameId removes every copy of the library
+	FindBarcode(barcode string) (int, bool, error) //The game a barcode was resolved to before
+	LinkBarcode(barcode string, gameId int) error
+	SetPaidPrice(copyId int, price float64, currency string) error
+	FindStaleValues(before time.Time, limit int) ([]PhysicalCopy, error) //Never valued first, then oldest
+	StoreValue(copyId int, estimate PriceEstimate) error                 //A zero estimate clears the value
+}
+
+// A boxed copy of a game on a shelf, a game can have several copies in a
+// library such as two editions
+type PhysicalCopy struct {
+	Id             int
+	LibraryId      int
+	GameId         int
+	GameExternalId string
+	GameName       string
+	Barcode        string //EAN-13, UPC-A codes get a leading zero. Empty when catalogued from a photo.
+	Platform       string
+	PaidPrice      float64
+	PaidCurrency   string //Empty while the paid price is unknown
+	Value          float64
+	ValueCurrency  string    //Empty without an estimate
+	ValuedAt       time.Time //Zero until the pricing job first looks at the copy
+	CreatedAt      time.Time
+}
+
+// The barcode without separators as EAN-13 or EAN-8, false when the digits
+// or the check digit are wrong. ISBN-13 is EAN-13 and passes as well.
+func normalizeBarcode(barcode string) (string, bool) {
+	barcode = strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(barcode))
+	for _, digit := range barcode {
+		if digit < '0' || digit > '9' {
+			return "", false
+		}
+	}
+	switch len(barcode) {
+	case 12:
+		barcode = "0" + barcode
+	case 8, 13:
+	default:
+		return "", false
+	}
+	// Weights alternate 3 and 1 from the digit left of the check digit
+	sum := 0
+	for i := len(barcode) - 2; i >= 0; i-- {
+		weight := 1
+		if (len(barcode)-2-i)%2 == 0 {
+			weight = 3
+		}
+		sum += int(barcode[i]-'0') * weight
+	}
+	return barcode, (10-sum%10)%10 == int(barcode[len(barcode)-1]-'0')
+}
+
+// Catalogs a physical copy from a scanned barcode. A barcode resolved before
+// takes the same game, new ones are named by the barcode provider and go
+// through the same name dedup as games added by hand. The game is added to
+// the library unless it is there already, scanning a second copy only
+// records the copy.
+func (interactor *ProfileInteractor) ScanBarcode(userId, libraryId int, barcode string) (PhysicalCopy, error, int) {
+	barcode, valid := normalizeBarcode(barcode)
+	if !valid {
+		return PhysicalCopy{}, domain.NewFieldError("barcode",
+			"Must be a UPC or EAN barcode with a valid check digit"), 400
+	}
+	owned, err, code := interactor.importTarget(userId, libraryId)
+	if err != nil {
+		return PhysicalCopy{}, err, code
+	}
+	gameId, linked, err := interactor.PhysicalCopyRepository.FindBarcode(barcode)
+	if err != nil {
+		return PhysicalCopy{}, err, 500
+	}
+
+	var game Game
+	platform := ""
+	if linked {
+		game, err, code = interactor.GameRepository.FindById(gameId)
+		if err != nil {
+			return PhysicalCopy{}, err, code
+		}
+	} else {
+		if interactor.Barcodes == nil {
+			return PhysicalCopy{}, domain.NewError(domain.CodeUnavailable,
+				"Barcode lookups are not configured"), 503
+		}
+		product, found, err := interactor.Barcodes.Lookup(barcode)
+		if err != nil {
+			return PhysicalCopy{}, domain.NewError(domain.CodeUnavailable,
+				"Cannot look up barcode %s: %v", barcode, err), 502
+		}
+		product.Name = strings.TrimSpace(product.Name)
+		if !found || product.Name == "" {
+			return PhysicalCopy{}, domain.NewError(domain.CodeNotFound,
+				"Barcode %s matches no known game", barcode), 404
+		}
+		if len(product.Name) > maxImportTitleLength {
+			return PhysicalCopy{}, domain.NewError(domain.CodeInvalid,
+				"Barcode %s names a game longer than %d characters", barcode, maxImportTitleLength), 400
+		}
+		if len(product.Platform) <= maxPlatformLen {
+			platform = strings.TrimSpace(product.Platform)
+		}
+		game = Game{Name: product.Name}
+	}
+
+	if present, found := owned[strings.ToLower(game.Name)]; found {
+		game = present
+	} else if game.Id > 0 {
+		err, code = interactor.PickGame(userId, libraryId, game.Id, 0)
+	} else {
+		game, err, code = interactor.AddGame(userId, libraryId, game, 0)
+	}
+	if err != nil {
+		return PhysicalCopy{}, err, code
+	}
+	if !linked {
+		err = interactor.PhysicalCopyRepository.LinkBarcode(barcode, game.Id)
+		if err != nil {
+			return PhysicalCopy{}, err, 500
+		}
+	}
+
+	physical := PhysicalCopy{LibraryId: libraryId, GameId: game.Id, GameExternalId: game.ExternalId,
+		GameName: game.Name, Barcode: barcode, Platform: platform}
+	physical.Id, err = interactor.PhysicalCopyRepository.Store(physical)
+	if err != nil {
+		return PhysicalCopy{}, err, 500
+	}
+	interactor.count("ScanBarcode")
+	interactor.logf("User #%d scanned %s as game #%d into library #%d", userId, barcode, game.Id, libraryId)
+	return interactor.PhysicalCopyRepository.FindById(physical.Id)
+}
+
+// By game name, the cursor of the next page is zero on the last page
+func (interactor *ProfileInteractor) ShowCopies(userId, libraryId int, page Page) ([]PhysicalCopy, Cursor, error, int) {
+	err := validPage(page, maxCopiesPerPage)
+	if err != nil {
+		return nil, Cursor{}, err, 400
+	}
+	copies, err, code := interactor.copies(userId, libraryId, page.probe())
+	if err != nil {
+		return nil, Cursor{}, err, code
+	}
+	count, more := page.cut(len(copies))
+	copies = copies[:count]
+	var next Cursor
+	if more {
+		last := copies[count-1]
+		next = Cursor{Key: last.GameName, Id: last.Id}
+	}
+	return copies, next, nil, 200
+}
+
+// A zero page reads every copy of the library
+func (interactor *ProfileInteractor) copies(userId, libraryId int, page Page) ([]PhysicalCopy, error, int) {
+	_, err, code := interactor.copyLibrary(userId, libraryId, LibraryRoleViewer)
+	if err != nil {
+		return nil, err, code
+	}
+	copies, err := interactor.PhysicalCopyRepository.FindByLib(libraryId, page)
+	if err != nil {
+		return nil, err, 500
+	}
+	return copies, nil, 200
+}
+
+// The game stays in the library, only the copy goes
+func (interactor *ProfileInteractor) RemoveCopy(userId, libraryId, copyId int) (error, int) {
+	_, err, code := interactor.copyLibrary(userId, libraryId, LibraryRoleEditor)
+	if err != nil {
+		return err, code
+	}
+	physical, err, code := interactor.PhysicalCopyRepository.FindById(copyId)
+	if err != nil {
+		return err, code
+	}
+	if physical.LibraryId != libraryId {
+		return domain.NewError(domain.CodeNotFound, "Copy #%d does not exist", copyId), 404
+	}
+	err = interactor.PhysicalCopyRepository.Remove(physical)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("User #%d removed copy #%d from library #%d", userId, copyId, libraryId)
+	return nil, 200
+}
+
+// Members of the library see its copies, editors may remove them
+func (interactor *ProfileInteractor) copyLibrary(userId, libraryId int, role string) (Library, error, int) {
+	library, err, code := interactor.LibraryRepository.FindById(libraryId)
+	if err != nil {
+		return Library{}, err, code
+	}
+	allowed, err := interactor.libraryAllows(userId, library, role)
+	if err != nil {
+		return Library{}, err, 500
+	}
+	if !allowed {
+		message := "User #%d is not allowed to see the copies in library #%d of user #%d"
+		err := domain.NewError(domain.CodeForbidden, message, userId, library.Id, library.User.Id)
+		return Library{}, err, 403
+	}
+	return library, nil, 200
+}
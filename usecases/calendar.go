@@ -0,0 +1,320 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const (
+	maxSessionMinutes = 24 * 60
+	// How far back and ahead the calendar feed reaches
+	calendarPast   = 30 * 24 * time.Hour
+	calendarFuture = 365 * 24 * time.Hour
+)
+
+type PlaySessionRepository interface {
+	Store(session PlaySession) (int, error)
+	FindById(id int) (PlaySession, error, int)
+	FindByUser(userId int, from, to time.Time) ([]PlaySession, error)
+	FindCoop(userId, partnerId int, until time.Time) ([]PlaySession, error) //Any partner when partnerId is 0
+	// Minutes of the sessions starting in the range, keyed by the day they
+	// start on in the timezone as a date at midnight UTC
+	MinutesPerDay(userId int, from, to time.Time, timezone string) (map[time.Time]int, error)
+	SetDetails(session PlaySession, notes string, partnerIds []int) error
+	Remove(session PlaySession) error
+	RemoveAll(userId int) error        //Archived months included
+	RemovePartner(partnerId int) error //Unlinks the user from sessions of others
+	// Readies the store for sessions starting in the months from the one of
+	// from to the one of until, in UTC
+	PrepareMonths(from, until time.Time) error
+	// Replaces the sessions of months ending by before with the minutes
+	// played per user, game and month
+	Archive(before time.Time) error
+}
+
+type ReleaseRepository interface {
+	Store(release Release) error //Replaces the date of a game already tracked
+	FindByUser(userId int, from time.Time) ([]Release, error)
+	FindUnannounced() ([]Release, error)
+	MarkAnnounced(userId, gameId int) error
+	Remove(userId, gameId int) error
+	RemoveAll(userId int) error
+}
+
+// Calendar apps cannot send tokens, feeds are read through a secret URL
+// instead. Only a hash of the secret is stored.
+type CalendarTokenRepository interface {
+	Store(userId int, tokenHash string) error
+	FindUserId(tokenHash string) (int, bool, error)
+	Remove(userId int) error
+}
+
+// Time spent, or planned to be spent, on a game. Sessions starting in the
+// future are planned ones.
+type PlaySession struct {
+	Id             int
+	UserId         int
+	GameId         int
+	GameExternalId string
+	GameName       string
+	StartsAt       time.Time
+	Minutes        int
+	Notes          string
+	PartnerIds     []int  //Users the session was played with
+	Partners       []User //Filled in by the interactor, removed users are left out
+	CreatedAt      time.Time
+}
+
+func (session PlaySession) EndsAt() time.Time {
+	return session.StartsAt.Add(time.Duration(session.Minutes) * time.Minute)
+}
+
+// A game the user waits for, Date only carries a day. Dates from the
+// metadata provider are kept up to date, manual ones are left alone.
+type Release struct {
+	UserId         int
+	GameId         int
+	GameExternalId string
+	GameName       string
+	Date           time.Time
+	Source         string
+	UpdatedAt      time.Time
+}
+
+type CalendarInteractor struct {
+	PlaySessionRepository   PlaySessionRepository
+	ReleaseRepository       ReleaseRepository
+	CalendarTokenRepository CalendarTokenRepository
+	UserRepository          UserRepository
+	LibraryRepository       LibraryRepository
+	GameRepository          GameRepository
+	SettingsRepository      SettingsRepository
+	MetadataProvider        MetadataProvider //Nil when no provider is configured
+	EventBus                domain.EventBus
+	Parental                *ParentalControls
+	logging
+}
+
+func (interactor *CalendarInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.PlaySessionRepository.RemoveAll(event.UserId)
+		if err == nil {
+			err = interactor.PlaySessionRepository.RemovePartner(event.UserId)
+		}
+		if err == nil {
+			err = interactor.ReleaseRepository.RemoveAll(event.UserId)
+		}
+		if err == nil {
+			err = interactor.CalendarTokenRepository.Remove(event.UserId)
+		}
+		if err != nil {
+			interactor.logf("Cannot remove calendar of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+// Sessions and releases can only refer to games in one of the user's libraries
+func (interactor *CalendarInteractor) ownedGame(userId, gameId int) (Game, error, int) {
+	return findOwnedGame(interactor.UserRepository, interactor.LibraryRepository,
+		interactor.GameRepository, userId, gameId)
+}
+
+func (interactor *CalendarInteractor) AddSession(userId, gameId int, startsAt time.Time, minutes int, notes string, partnerIds []int) (PlaySession, error, int) {
+	if startsAt.IsZero() {
+		return PlaySession{}, domain.NewFieldError("startsAt", "Start time is required"), 400
+	}
+	if minutes < 1 || minutes > maxSessionMinutes {
+		return PlaySession{}, domain.NewFieldError("minutes", "Must be between 1 and %d",
+			maxSessionMinutes), 400
+	}
+	notes, partnerIds, err, code := interactor.sessionDetails(userId, notes, partnerIds)
+	if err != nil {
+		return PlaySession{}, err, code
+	}
+	game, err, code := interactor.ownedGame(userId, gameId)
+	if err != nil {
+		return PlaySession{}, err, code
+	}
+	err, code = interactor.Parental.CheckPlaytime(userId, startsAt, minutes)
+	if err != nil {
+		return PlaySession{}, err, code
+	}
+
+	session := PlaySession{UserId: userId, GameId: game.Id, GameExternalId: game.ExternalId,
+		GameName: game.Name, StartsAt: startsAt.UTC(), Minutes: minutes, Notes: notes,
+		PartnerIds: partnerIds}
+	id, err := interactor.PlaySessionRepository.Store(session)
+	if err != nil {
+		return PlaySession{}, err, 500
+	}
+	session, err, code = interactor.PlaySessionRepository.FindById(id)
+	if err != nil {
+		return PlaySession{}, err, code
+	}
+	interactor.logf("User #%d added session #%d for game #%d", userId, id, game.Id)
+	interactor.publish(domain.Event{Name: domain.EventSessionAdded, UserId: userId, EntityId: id})
+	return interactor.withPartners(session), nil, 201
+}
+
+func (interactor *CalendarInteractor) ShowSessions(userId int, from, to time.Time) ([]PlaySession, error, int) {
+	if !to.After(from) {
+		return nil, domain.NewFieldError("to", "Must be after from"), 400
+	}
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	sessions, err := interactor.PlaySessionRepository.FindByUser(userId, from, to)
+	if err != nil {
+		return nil, err, 500
+	}
+	for i := range sessions {
+		sessions[i] = interactor.withPartners(sessions[i])
+	}
+	return sessions, nil, 200
+}
+
+func (interactor *CalendarInteractor) RemoveSession(userId, sessionId int) (error, int) {
+	session, err, code := interactor.PlaySessionRepository.FindById(sessionId)
+	if err != nil {
+		return err, code
+	}
+	if session.UserId != userId {
+		return domain.NewError(domain.CodeForbidden, "User #%d cannot remove session #%d of user #%d",
+			userId, sessionId, session.UserId), 403
+	}
+	err = interactor.PlaySessionRepository.Remove(session)
+	if err != nil {
+		return err, 500
+	}
+	return nil, 200
+}
+
+// Readies the months up to ahead months from now for sessions and, unless
+// retain is 0, archives the sessions of months over retain months old.
+// Archived sessions only count in the totals of game stats.
+func (interactor *CalendarInteractor) MaintainSessions(ahead, retain int) error {
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	err := interactor.PlaySessionRepository.PrepareMonths(month, month.AddDate(0, ahead, 0))
+	if err != nil {
+		return err
+	}
+	if retain < 1 {
+		return nil
+	}
+	return interactor.PlaySessionRepository.Archive(month.AddDate(0, -retain, 0))
+}
+
+// Tracking a game again moves its date. Without a date the metadata
+// provider is asked for one.
+func (interactor *CalendarInteractor) TrackRelease(userId, gameId int, date time.Time) (Release, error, int) {
+	game, err, code := interactor.ownedGame(userId, gameId)
+	if err != nil {
+		return Release{}, err, code
+	}
+	source := ReleaseManual
+	if date.IsZero() {
+		if interactor.MetadataProvider == nil {
+			return Release{}, domain.NewFieldError("date", "Release date is required"), 400
+		}
+		var found bool
+		date, found, err = interactor.MetadataProvider.ReleaseDate(game.Name)
+		if err != nil {
+			return Release{}, err, 502
+		}
+		if !found {
+			return Release{}, domain.NewFieldError("date",
+				"No release date is known for '%s', it has to be given", game.Name), 400
+		}
+		source = ReleaseProvider
+	}
+	release := Release{UserId: userId, GameId: game.Id, GameExternalId: game.ExternalId,
+		GameName: game.Name, Date: date, Source: source, UpdatedAt: time.Now().UTC()}
+	err = interactor.ReleaseRepository.Store(release)
+	if err != nil {
+		return Release{}, err, 500
+	}
+	return release, nil, 200
+}
+
+func (interactor *CalendarInteractor) UntrackRelease(userId, gameId int) (error, int) {
+	err := interactor.ReleaseRepository.Remove(userId, gameId)
+	if err != nil {
+		return err, 500
+	}
+	return nil, 200
+}
+
+// Releases from today on, soonest first
+func (interactor *CalendarInteractor) ShowReleases(userId int) ([]Release, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	releases, err := interactor.ReleaseRepository.FindByUser(userId, today())
+	if err != nil {
+		return nil, err, 500
+	}
+	return releases, nil, 200
+}
+
+// Creates the secret the calendar feed is read with, a previous one stops working
+func (interactor *CalendarInteractor) IssueCalendarToken(userId int) (string, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return "", err, code
+	}
+	bytes := make([]byte, 24)
+	_, err = rand.Read(bytes)
+	if err != nil {
+		return "", err, 500
+	}
+	token := hex.EncodeToString(bytes)
+	err = interactor.CalendarTokenRepository.Store(userId, hashToken(token))
+	if err != nil {
+		return "", err, 500
+	}
+	interactor.logf("Issued calendar token for user #%d", userId)
+	return token, nil, 200
+}
+
+// The sessions and releases a calendar subscription shows
+func (interactor *CalendarInteractor) ShowCalendar(token string) (User, []PlaySession, []Release, error, int) {
+	userId, found, err := interactor.CalendarTokenRepository.FindUserId(hashToken(token))
+	if err != nil {
+		return User{}, nil, nil, err, 500
+	}
+	if !found {
+		return User{}, nil, nil, domain.NewError(domain.CodeNotFound, "Calendar does not exist"), 404
+	}
+	user, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return User{}, nil, nil, err, code
+	}
+
+	now := time.Now().UTC()
+	sessions, err := interactor.PlaySessionRepository.FindByUser(userId, now.Add(-calendarPast),
+		now.Add(calendarFuture))
+	if err != nil {
+		return User{}, nil, nil, err, 500
+	}
+	releases, err := interactor.ReleaseRepository.FindByUser(userId, today().Add(-calendarPast))
+	if err != nil {
+		return User{}, nil, nil, err, 500
+	}
+	return user, sessions, releases, nil, 200
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
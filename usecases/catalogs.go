@@ -0,0 +1,234 @@
+package usecases
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"game-tracker/domain"
+)
+
+// Games currently included in a subscription service such as Game Pass,
+// as the catalog provider lists them
+type CatalogProvider interface {
+	Catalog(service string) ([]CatalogEntry, error)
+}
+
+type CatalogRepository interface {
+	Replace(service string, entries []CatalogEntry) error
+	FindByNames(names []string) ([]CatalogEntry, error)      //Names matched ignoring case, by service
+	StoreAlert(userId int, entry CatalogEntry) (bool, error) //False when the user was already told
+	RemoveAlerts(userId int) error
+}
+
+// LeavesOn is the day a game is announced to leave the catalog, zero while
+// it stays
+type CatalogEntry struct {
+	Service   string
+	Name      string
+	LeavesOn  time.Time
+	UpdatedAt time.Time
+}
+
+// A wishlist or backlog game with the catalogs it is in
+type CatalogGame struct {
+	Game     Game
+	Catalogs []CatalogEntry
+}
+
+type CatalogInteractor struct {
+	CatalogRepository      CatalogRepository
+	Catalogs               CatalogProvider //Nil keeps the catalogs as last fetched
+	Services               []string
+	SubscriptionRepository SubscriptionRepository
+	UserRepository         UserRepository
+	GameRepository         GameRepository
+	EventBus               domain.EventBus
+	logging
+}
+
+func (interactor *CatalogInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.CatalogRepository.RemoveAlerts(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove catalog alerts of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+// Game names are matched ignoring case and surrounding spaces
+func catalogName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Services compare by their letters and digits, so a "Game Pass Ultimate"
+// subscription is a member of the "Game Pass" catalog
+func memberOf(subscription, service string) bool {
+	key := func(name string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, name)
+	}
+	service = key(service)
+	return service != "" && strings.Contains(key(subscription), service)
+}
+
+// Wishlist and backlog games of the user's own libraries that a catalog
+// currently includes, sorted by name
+func (interactor *CatalogInteractor) ShowCatalogGames(userId int) ([]CatalogGame, error, int) {
+	user, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	flagged, err := interactor.catalogGames(user)
+	if err != nil {
+		return nil, err, 500
+	}
+	return flagged, nil, 200
+}
+
+func (interactor *CatalogInteractor) catalogGames(user User) ([]CatalogGame, error) {
+	wanted := make(map[string]Game)
+	for _, libraryId := range user.LibraryIds {
+		games, err := interactor.GameRepository.FindByLib(libraryId, GameFilter{}, Page{})
+		if err != nil {
+			return nil, err
+		}
+		for _, game := range games {
+			if game.Status == "wishlist" || game.Status == "backlog" {
+				wanted[catalogName(game.Name)] = game
+			}
+		}
+	}
+	if len(wanted) == 0 {
+		return []CatalogGame{}, nil
+	}
+	var names []string
+	for name := range wanted {
+		names = append(names, name)
+	}
+	entries, err := interactor.CatalogRepository.FindByNames(names)
+	if err != nil {
+		return nil, err
+	}
+
+	included := make(map[string]*CatalogGame)
+	for _, entry := range entries {
+		name := catalogName(entry.Name)
+		flagged := included[name]
+		if flagged == nil {
+			flagged = &CatalogGame{Game: wanted[name]}
+			included[name] = flagged
+		}
+		flagged.Catalogs = append(flagged.Catalogs, entry)
+	}
+	flagged := []CatalogGame{}
+	for _, game := range included {
+		flagged = append(flagged, *game)
+	}
+	sort.Slice(flagged, func(i, j int) bool {
+		return catalogName(flagged[i].Game.Name) < catalogName(flagged[j].Game.Name)
+	})
+	return flagged, nil
+}
+
+// Run by the catalog job: every service is fetched again, then members of
+// a service are told once about each of their wishlist and backlog games
+// announced to leave it. A service the provider fails on, or lists empty,
+// keeps its previous catalog.
+func (interactor *CatalogInteractor) RefreshCatalogs() error {
+	if interactor.Catalogs == nil {
+		return nil
+	}
+	for _, service := range interactor.Services {
+		entries, err := interactor.Catalogs.Catalog(service)
+		if err != nil {
+			interactor.logf("Cannot fetch the %s catalog: %v", service, err)
+			continue
+		}
+		seen := make(map[string]bool)
+		var valid []CatalogEntry
+		for _, entry := range entries {
+			entry.Service = service
+			entry.Name = strings.TrimSpace(entry.Name)
+			if entry.Name == "" || seen[catalogName(entry.Name)] {
+				continue
+			}
+			seen[catalogName(entry.Name)] = true
+			valid = append(valid, entry)
+		}
+		if len(valid) == 0 {
+			interactor.logf("The %s catalog came back empty, keeping the previous one", service)
+			continue
+		}
+		err = interactor.CatalogRepository.Replace(service, valid)
+		if err != nil {
+			return err
+		}
+	}
+	return interactor.alertLeaving()
+}
+
+func (interactor *CatalogInteractor) alertLeaving() error {
+	subscriptions, err := interactor.SubscriptionRepository.FindActive(today())
+	if err != nil {
+		return err
+	}
+	services := make(map[int][]string)
+	for _, subscription := range subscriptions {
+		services[subscription.UserId] = append(services[subscription.UserId], subscription.Service)
+	}
+
+	alerted := 0
+	for userId, subscribed := range services {
+		user, err, code := interactor.UserRepository.FindById(userId)
+		if code == 404 {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		flagged, err := interactor.catalogGames(user)
+		if err != nil {
+			return err
+		}
+		for _, game := range flagged {
+			for _, entry := range game.Catalogs {
+				if entry.LeavesOn.IsZero() || entry.LeavesOn.Before(today()) || !memberOfAny(subscribed, entry.Service) {
+					continue
+				}
+				added, err := interactor.CatalogRepository.StoreAlert(userId, entry)
+				if err != nil {
+					return err
+				}
+				if !added {
+					continue
+				}
+				alerted++
+				if interactor.EventBus != nil {
+					interactor.EventBus.Publish(domain.Event{Name: domain.EventCatalogLeaving,
+						UserId: userId, EntityId: game.Game.Id, Payload: map[string]string{
+							"name": game.Game.Name, "service": entry.Service,
+							"date": entry.LeavesOn.Format("2006-01-02")}})
+				}
+			}
+		}
+	}
+	if alerted > 0 {
+		interactor.logf("Told users of %d games leaving a catalog", alerted)
+	}
+	return nil
+}
+
+func memberOfAny(subscriptions []string, service string) bool {
+	for _, subscription := range subscriptions {
+		if memberOf(subscription, service) {
+			return true
+		}
+	}
+	return false
+}
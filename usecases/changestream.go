@@ -0,0 +1,132 @@
+package usecases
+
+import (
+	"sync"
+
+	"game-tracker/domain"
+)
+
+// Kinds of change notices
+const (
+	NoticeChange = "change" //An entry of the change log was written
+	NoticeGame   = "game"   //A game was edited or removed
+	NoticeMissed = "missed" //The stream reconnected, notices may have been lost
+)
+
+// Something written by any instance of the service, as the database tells
+type ChangeNotice struct {
+	Kind           string
+	Change         Change //For NoticeChange
+	GameId         int    //For NoticeGame
+	GameExternalId string //For NoticeGame
+}
+
+// Feeds the notices of the database to handle until the process ends
+type ChangeStream interface {
+	Run(handle func(notice ChangeNotice))
+}
+
+// Caches holding games of their own that edits elsewhere make stale
+type GameCache interface {
+	Forget(gameId int, externalId string)
+}
+
+// Changes of a user kept for watchers that read slower than the stream
+const watchBuffer = 64
+
+// Drops cached games edited by other instances and passes the change log on
+// to the watchers of each user, such as WebSocket clients. Shared by
+// pointer, the watchers live in it.
+type ChangeStreamInteractor struct {
+	Stream             ChangeStream //Nil leaves the stream off
+	Games              GameCache    //Nil leaves cached games to expire
+	UserRepository     UserRepository
+	SettingsRepository SettingsRepository
+	lock               sync.Mutex
+	watchers           map[int]map[chan Change]bool //By user id
+}
+
+func NewChangeStreamInteractor(stream ChangeStream, games GameCache, users UserRepository,
+	settings SettingsRepository) *ChangeStreamInteractor {
+	return &ChangeStreamInteractor{Stream: stream, Games: games, UserRepository: users,
+		SettingsRepository: settings}
+}
+
+func (interactor *ChangeStreamInteractor) Run() {
+	interactor.Stream.Run(interactor.handle)
+}
+
+func (interactor *ChangeStreamInteractor) handle(notice ChangeNotice) {
+	switch notice.Kind {
+	case NoticeGame:
+		if interactor.Games != nil {
+			interactor.Games.Forget(notice.GameId, notice.GameExternalId)
+		}
+	case NoticeChange:
+		interactor.deliver(notice.Change)
+	case NoticeMissed:
+		interactor.closeAll()
+	}
+}
+
+// Watchers that fell behind lose the change, they catch up through Sync
+func (interactor *ChangeStreamInteractor) deliver(change Change) {
+	interactor.lock.Lock()
+	defer interactor.lock.Unlock()
+	if len(interactor.watchers[change.UserId]) == 0 {
+		return
+	}
+	change.ChangedAt = change.ChangedAt.In(userLocation(interactor.SettingsRepository, change.UserId))
+	for watcher := range interactor.watchers[change.UserId] {
+		select {
+		case watcher <- change:
+		default:
+		}
+	}
+}
+
+// Watchers cannot tell which changes the gap swallowed, closing them makes
+// clients sync from their last cursor
+func (interactor *ChangeStreamInteractor) closeAll() {
+	interactor.lock.Lock()
+	defer interactor.lock.Unlock()
+	for _, watchers := range interactor.watchers {
+		for watcher := range watchers {
+			close(watcher)
+		}
+	}
+	interactor.watchers = nil
+}
+
+// Changes of the user as they are written, until stop is called. The
+// channel is closed when the stream cannot vouch for having sent them all.
+func (interactor *ChangeStreamInteractor) Watch(userId int) (<-chan Change, func(), error, int) {
+	if interactor.Stream == nil {
+		return nil, nil, domain.NewError(domain.CodeUnavailable, "Change streams are not configured"), 503
+	}
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, nil, err, code
+	}
+
+	watcher := make(chan Change, watchBuffer)
+	interactor.lock.Lock()
+	if interactor.watchers == nil {
+		interactor.watchers = make(map[int]map[chan Change]bool)
+	}
+	if interactor.watchers[userId] == nil {
+		interactor.watchers[userId] = make(map[chan Change]bool)
+	}
+	interactor.watchers[userId][watcher] = true
+	interactor.lock.Unlock()
+
+	stop := func() {
+		interactor.lock.Lock()
+		defer interactor.lock.Unlock()
+		if interactor.watchers[userId][watcher] {
+			delete(interactor.watchers[userId], watcher)
+			close(watcher)
+		}
+	}
+	return watcher, stop, nil, 200
+}
@@ -0,0 +1,177 @@
+package usecases
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"game-tracker/domain"
+)
+
+const (
+	maxSessionNotesLength = 2000
+	maxSessionPartners    = 8
+)
+
+// Time spent playing with one partner, games with the most minutes first
+type CoopPartner struct {
+	Partner      User
+	Sessions     int
+	Minutes      int
+	LastPlayedAt time.Time
+	Games        []GameMinutes
+}
+
+// Time played on one game
+type GameMinutes struct {
+	GameId         int
+	GameExternalId string
+	GameName       string
+	Minutes        int
+}
+
+// Trims the notes and checks every partner is another existing user,
+// partners named twice count once
+func (interactor *CalendarInteractor) sessionDetails(userId int, notes string, partnerIds []int) (string, []int, error, int) {
+	notes = strings.TrimSpace(notes)
+	if utf8.RuneCountInString(notes) > maxSessionNotesLength {
+		return "", nil, domain.NewFieldError("notes", "Must be at most %d characters",
+			maxSessionNotesLength), 400
+	}
+	seen := make(map[int]bool)
+	var partners []int
+	for _, partnerId := range partnerIds {
+		if seen[partnerId] {
+			continue
+		}
+		seen[partnerId] = true
+		if partnerId == userId {
+			return "", nil, domain.NewFieldError("partners", "Cannot list yourself as a partner"), 400
+		}
+		_, err, code := interactor.UserRepository.FindById(partnerId)
+		if err != nil {
+			return "", nil, err, code
+		}
+		partners = append(partners, partnerId)
+	}
+	if len(partners) > maxSessionPartners {
+		return "", nil, domain.NewFieldError("partners", "Must be at most %d users",
+			maxSessionPartners), 400
+	}
+	return notes, partners, nil, 200
+}
+
+func (interactor *CalendarInteractor) withPartners(session PlaySession) PlaySession {
+	session.Partners = nil
+	for _, partnerId := range session.PartnerIds {
+		partner, err, _ := interactor.UserRepository.FindById(partnerId)
+		if err == nil {
+			session.Partners = append(session.Partners, partner)
+		}
+	}
+	return session
+}
+
+// Replaces the notes and partners of a session
+func (interactor *CalendarInteractor) EditSession(userId, sessionId int, notes string, partnerIds []int) (PlaySession, error, int) {
+	session, err, code := interactor.PlaySessionRepository.FindById(sessionId)
+	if err != nil {
+		return PlaySession{}, err, code
+	}
+	if session.UserId != userId {
+		return PlaySession{}, domain.NewError(domain.CodeForbidden,
+			"User #%d cannot edit session #%d of user #%d", userId, sessionId, session.UserId), 403
+	}
+	notes, partnerIds, err, code = interactor.sessionDetails(userId, notes, partnerIds)
+	if err != nil {
+		return PlaySession{}, err, code
+	}
+	err = interactor.PlaySessionRepository.SetDetails(session, notes, partnerIds)
+	if err != nil {
+		return PlaySession{}, err, 500
+	}
+	interactor.logf("User #%d edited session #%d", userId, sessionId)
+	session.Notes = notes
+	session.PartnerIds = partnerIds
+	return interactor.withPartners(session), nil, 200
+}
+
+// Totals of the sessions played so far with others, per partner with the
+// most minutes first. With a partner id only that partner is reported.
+func (interactor *CalendarInteractor) ShowCoop(userId, partnerId int) ([]CoopPartner, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	var partner User
+	if partnerId != 0 {
+		partner, err, code = interactor.UserRepository.FindById(partnerId)
+		if err != nil {
+			return nil, err, code
+		}
+	}
+	sessions, err := interactor.PlaySessionRepository.FindCoop(userId, partnerId, time.Now())
+	if err != nil {
+		return nil, err, 500
+	}
+
+	totals := make(map[int]*CoopPartner)
+	games := make(map[int]map[int]*GameMinutes)
+	for _, session := range sessions {
+		for _, id := range session.PartnerIds {
+			if partnerId != 0 && id != partnerId {
+				continue
+			}
+			total := totals[id]
+			if total == nil {
+				user, err, code := interactor.UserRepository.FindById(id)
+				if code == 404 {
+					continue
+				}
+				if err != nil {
+					return nil, err, code
+				}
+				total = &CoopPartner{Partner: user}
+				totals[id] = total
+				games[id] = make(map[int]*GameMinutes)
+			}
+			total.Sessions++
+			total.Minutes += session.Minutes
+			if session.StartsAt.After(total.LastPlayedAt) {
+				total.LastPlayedAt = session.StartsAt
+			}
+			game := games[id][session.GameId]
+			if game == nil {
+				game = &GameMinutes{GameId: session.GameId, GameExternalId: session.GameExternalId,
+					GameName: session.GameName}
+				games[id][session.GameId] = game
+			}
+			game.Minutes += session.Minutes
+		}
+	}
+
+	if partnerId != 0 && totals[partnerId] == nil {
+		return []CoopPartner{{Partner: partner}}, nil, 200
+	}
+	partners := []CoopPartner{}
+	for id, total := range totals {
+		for _, game := range games[id] {
+			total.Games = append(total.Games, *game)
+		}
+		sort.Slice(total.Games, func(i, j int) bool {
+			if total.Games[i].Minutes != total.Games[j].Minutes {
+				return total.Games[i].Minutes > total.Games[j].Minutes
+			}
+			return total.Games[i].GameName < total.Games[j].GameName
+		})
+		partners = append(partners, *total)
+	}
+	sort.Slice(partners, func(i, j int) bool {
+		if partners[i].Minutes != partners[j].Minutes {
+			return partners[i].Minutes > partners[j].Minutes
+		}
+		return partners[i].Partner.Name < partners[j].Partner.Name
+	})
+	return partners, nil, 200
+}
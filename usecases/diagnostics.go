@@ -0,0 +1,91 @@
+package usecases
+
+import (
+	"runtime"
+	"sort"
+	"time"
+)
+
+// Connection pool figures of the database, see database/sql.DBStats
+type PoolStats struct {
+	MaxOpen      int
+	Open         int
+	InUse        int
+	Idle         int
+	WaitCount    int64
+	WaitDuration time.Duration
+	Server       int   //Index of the DSN in use, 0 is the primary
+	Failovers    int64 //Since start
+}
+
+// Implemented by database handlers that keep a connection pool
+type PoolStatsProvider interface {
+	PoolStats() PoolStats
+}
+
+// Implemented by caches that can tell how many entries they hold, caches
+// shared with other instances do not
+type SizedCache interface {
+	Len() int
+}
+
+// Runtime figures of this instance, for debugging slowness in production
+type Diagnostics struct {
+	Goroutines  int
+	HeapBytes   uint64
+	GcRuns      uint32
+	GcPause     time.Duration //Total stop the world time
+	Pool        *PoolStats    //Nil unless the database keeps a pool
+	Caches      []CacheSize   //Sorted by name
+	Uptime      time.Duration
+	CollectedAt time.Time
+}
+
+type CacheSize struct {
+	Name    string
+	Entries int
+}
+
+type DiagnosticsInteractor struct {
+	Admin     AdminInteractor
+	Pool      PoolStatsProvider     //Nil when the database has no pool to report
+	Caches    map[string]SizedCache //By name, caches that cannot count are left out
+	StartedAt time.Time
+}
+
+func (interactor *DiagnosticsInteractor) ShowDiagnostics(adminId int) (Diagnostics, error, int) {
+	err, code := interactor.Admin.Authorize(adminId)
+	if err != nil {
+		return Diagnostics{}, err, code
+	}
+	return interactor.Collect(), nil, 200
+}
+
+// Reads the figures without checking the caller, for expvar which the
+// diagnostics port only serves to admins
+func (interactor *DiagnosticsInteractor) Collect() Diagnostics {
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	now := time.Now().UTC()
+	diagnostics := Diagnostics{
+		Goroutines:  runtime.NumGoroutine(),
+		HeapBytes:   memory.HeapAlloc,
+		GcRuns:      memory.NumGC,
+		GcPause:     time.Duration(memory.PauseTotalNs),
+		CollectedAt: now,
+	}
+	if !interactor.StartedAt.IsZero() {
+		diagnostics.Uptime = now.Sub(interactor.StartedAt)
+	}
+	if interactor.Pool != nil {
+		pool := interactor.Pool.PoolStats()
+		diagnostics.Pool = &pool
+	}
+	for name, cache := range interactor.Caches {
+		diagnostics.Caches = append(diagnostics.Caches, CacheSize{Name: name, Entries: cache.Len()})
+	}
+	sort.Slice(diagnostics.Caches, func(i, j int) bool {
+		return diagnostics.Caches[i].Name < diagnostics.Caches[j].Name
+	})
+	return diagnostics
+}
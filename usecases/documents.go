@@ -0,0 +1,83 @@
+package usecases
+
+import (
+	"time"
+
+	"game-tracker/domain"
+)
+
+// A report laid out independently of the file it ends up in
+type Document struct {
+	Title    string
+	Sections []DocumentSection
+}
+
+type DocumentSection struct {
+	Heading    string
+	Paragraphs []string
+	Table      [][]string //The first row is the header
+}
+
+// Fills the named template with data, its text translated to locale.
+// Templates are written by us, not by users, and are loaded once at startup.
+type DocumentTemplates interface {
+	Execute(name, locale string, data interface{}) (Document, error)
+}
+
+// Prints documents to a file format such as PDF
+type DocumentRenderer interface {
+	Render(document Document) ([]byte, error)
+	ContentType() string
+	Extension() string //Without the dot
+}
+
+// Prints a template to a file named name plus the renderer's extension
+func (interactor *ProfileInteractor) printDocument(template, name, locale string, data interface{}) (ExportedFile, error, int) {
+	if interactor.Templates == nil || interactor.Printer == nil {
+		return ExportedFile{}, domain.NewError(domain.CodeUnavailable, "Printing is not configured"), 503
+	}
+	document, err := interactor.Templates.Execute(template, locale, data)
+	if err != nil {
+		return ExportedFile{}, err, 500
+	}
+	printed, err := interactor.Printer.Render(document)
+	if err != nil {
+		return ExportedFile{}, err, 500
+	}
+	return ExportedFile{Name: name + "." + interactor.Printer.Extension(),
+		ContentType: interactor.Printer.ContentType(), Data: printed}, nil, 200
+}
+
+// What the worth template is filled with
+type worthReport struct {
+	Owner     string
+	LibraryId string
+	Worth     CollectionWorth
+	PrintedAt time.Time
+}
+
+// The collection worth as a document to hand to an insurer
+func (interactor *ProfileInteractor) PrintCollectionWorth(userId, libraryId int, locale string) (ExportedFile, error, int) {
+	worth, err, code := interactor.ShowCollectionWorth(userId, libraryId)
+	if err != nil {
+		return ExportedFile{}, err, code
+	}
+	library, err, code := interactor.LibraryRepository.FindById(libraryId)
+	if err != nil {
+		return ExportedFile{}, err, code
+	}
+	owner, err, code := interactor.UserRepository.FindById(library.User.Id)
+	if err != nil {
+		return ExportedFile{}, err, code
+	}
+	location := userLocation(interactor.SettingsRepository, userId)
+	file, err, code := interactor.printDocument("worth", "collection-worth", locale, worthReport{
+		Owner: owner.Name, LibraryId: library.ExternalId, Worth: worth,
+		PrintedAt: time.Now().In(location)})
+	if err != nil {
+		return ExportedFile{}, err, code
+	}
+	interactor.count("PrintCollectionWorth")
+	interactor.logf("User #%d printed the worth of library #%d", userId, libraryId)
+	return file, nil, 200
+}
@@ -0,0 +1,76 @@
+package usecases
+
+import "time"
+
+// Everything a user keeps in the tracker, so they can take it with them
+type ProfileExport struct {
+	User       User
+	Libraries  []LibraryExport
+	Journal    []JournalEntry
+	ExportedAt time.Time
+}
+
+type LibraryExport struct {
+	Library Library
+	Games   []Game //With the status, platform and tags of their library entry
+	Mods    []Mod
+}
+
+type ExportInteractor struct {
+	UserRepository    UserRepository
+	LibraryRepository LibraryRepository
+	GameRepository    GameRepository
+	JournalRepository JournalRepository
+	ModRepository     ModRepository
+	Renderer          MarkdownRenderer
+	logging
+}
+
+// Journal spoilers are hidden unless showSpoilers is set
+func (interactor *ExportInteractor) ExportProfile(userId int, showSpoilers bool) (ProfileExport, error, int) {
+	user, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return ProfileExport{}, err, code
+	}
+	export := ProfileExport{User: user, ExportedAt: time.Now().UTC()}
+	export.Libraries, err, code = interactor.libraries(user)
+	if err != nil {
+		return ProfileExport{}, err, code
+	}
+	export.Journal, err = interactor.JournalRepository.FindByUser(userId, 0, Page{})
+	if err != nil {
+		return ProfileExport{}, err, 500
+	}
+	err = renderJournal(interactor.Renderer, interactor.JournalRepository, export.Journal)
+	if err != nil {
+		return ProfileExport{}, err, 500
+	}
+	if !showSpoilers {
+		err = hideSpoilers(interactor.GameRepository, export.Journal)
+		if err != nil {
+			return ProfileExport{}, err, 500
+		}
+	}
+	interactor.logf("Exported profile of user #%d", userId)
+	return export, nil, 200
+}
+
+func (interactor *ExportInteractor) libraries(user User) ([]LibraryExport, error, int) {
+	var libraries []LibraryExport
+	for _, libraryId := range user.LibraryIds {
+		library, err, code := interactor.LibraryRepository.FindById(libraryId)
+		if err != nil {
+			return nil, err, code
+		}
+		games, err := interactor.GameRepository.FindByLib(libraryId, GameFilter{}, Page{})
+		if err != nil {
+			return nil, err, 500
+		}
+		mods, err := interactor.ModRepository.FindByLib(libraryId)
+		if err != nil {
+			return nil, err, 500
+		}
+		libraries = append(libraries, LibraryExport{Library: library, Games: games, Mods: mods})
+	}
+	return libraries, nil, 200
+}
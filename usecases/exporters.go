@@ -0,0 +1,140 @@
+package usecases
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"game-tracker/domain"
+)
+
+// Writes the games of a user in the shape another tracker imports. A new
+// target only needs an entry in exportFormats.
+type exportFormat struct {
+	Header []string
+	Row    func(game Game) ([]string, error)
+}
+
+var exportFormats = map[string]exportFormat{
+	"backloggd": {Header: []string{"Game Name", "Platform", "Status"}, Row: backloggdRow},
+	"grouvee":   {Header: []string{"name", "platforms", "shelves"}, Row: grouveeRow},
+}
+
+// A file ready to be downloaded
+type ExportedFile struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Every game of the user's libraries once, in the first library holding it,
+// sorted by name
+func (interactor *ExportInteractor) ExportFile(userId int, format string) (ExportedFile, error, int) {
+	format = strings.ToLower(format)
+	target, known := exportFormats[format]
+	if !known {
+		var names []string
+		for name := range exportFormats {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return ExportedFile{}, domain.NewFieldError("format", "Format '%s' is unknown, use json, html or %s",
+			format, strings.Join(names, ", ")), 400
+	}
+	user, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return ExportedFile{}, err, code
+	}
+	libraries, err, code := interactor.libraries(user)
+	if err != nil {
+		return ExportedFile{}, err, code
+	}
+	var games []Game
+	seen := make(map[int]bool)
+	for _, library := range libraries {
+		for _, game := range library.Games {
+			if !seen[game.Id] {
+				seen[game.Id] = true
+				games = append(games, game)
+			}
+		}
+	}
+	sort.SliceStable(games, func(i, j int) bool {
+		return strings.ToLower(games[i].Name) < strings.ToLower(games[j].Name)
+	})
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	err = writer.Write(target.Header)
+	for _, game := range games {
+		if err != nil {
+			break
+		}
+		var row []string
+		row, err = target.Row(game)
+		if err == nil {
+			err = writer.Write(row)
+		}
+	}
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	if err != nil {
+		return ExportedFile{}, fmt.Errorf("Cannot write %s export: %v", format, err), 500
+	}
+	interactor.logf("Exported %d games of user #%d for %s", len(games), userId, format)
+	return ExportedFile{Name: fmt.Sprintf("game-tracker-%s.csv", format),
+		ContentType: "text/csv; charset=utf-8", Data: buffer.Bytes()}, nil, 200
+}
+
+// Our statuses in the words of Backloggd, owned games have not been
+// started so they go to the backlog
+var backloggdExportStatuses = map[string]string{
+	"owned":     "Backlog",
+	"wishlist":  "Wishlist",
+	"backlog":   "Backlog",
+	"playing":   "Playing",
+	"completed": "Completed",
+	"abandoned": "Abandoned",
+}
+
+func backloggdRow(game Game) ([]string, error) {
+	status, found := backloggdExportStatuses[game.Status]
+	if !found {
+		status = "Backlog"
+	}
+	return []string{game.Name, game.Platform, status}, nil
+}
+
+var grouveeExportShelves = map[string]string{
+	"owned":     "Backlog",
+	"wishlist":  "Wish List",
+	"backlog":   "Backlog",
+	"playing":   "Playing",
+	"completed": "Beaten",
+	"abandoned": "Abandoned",
+}
+
+// Grouvee keeps shelves and platforms as JSON objects keyed by name
+func grouveeRow(game Game) ([]string, error) {
+	shelf, found := grouveeExportShelves[game.Status]
+	if !found {
+		shelf = "Backlog"
+	}
+	shelves, err := json.Marshal(map[string]interface{}{shelf: map[string]string{}})
+	if err != nil {
+		return nil, err
+	}
+	platforms := []byte("{}")
+	if game.Platform != "" {
+		platforms, err = json.Marshal(map[string]interface{}{game.Platform: map[string]string{}})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return []string{game.Name, string(platforms), string(shelves)}, nil
+}
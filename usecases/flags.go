@@ -0,0 +1,235 @@
+package usecases
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const (
+	FlagGameBatchUpdates = "game_batch_updates"
+	FlagUsernameChanges  = "username_changes"
+)
+
+// Flags the code consults, a flag missing from the repository evaluates to
+// its default here so features can be gated before anyone configures them
+var knownFlags = map[string]bool{
+	FlagGameBatchUpdates: true,
+	FlagUsernameChanges:  true,
+}
+
+var flagNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// Flags are re-read at most this often, edits made through the service
+// take effect immediately on this instance
+const flagRefreshInterval = 30 * time.Second
+
+type FlagRepository interface {
+	FindAll() ([]FeatureFlag, error)
+	Store(flag FeatureFlag) error
+	Remove(name string) error
+}
+
+// A flag is on for the listed users and for Percentage of everybody else,
+// Enabled switches it off for all of them
+type FeatureFlag struct {
+	Name        string
+	Description string
+	Enabled     bool
+	Percentage  int
+	UserIds     []int
+	UpdatedAt   time.Time
+}
+
+// Each user lands in a stable bucket per flag, so raising the percentage
+// only ever adds users
+func (flag FeatureFlag) EnabledFor(userId int) bool {
+	if !flag.Enabled {
+		return false
+	}
+	for _, id := range flag.UserIds {
+		if id == userId {
+			return true
+		}
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(fmt.Sprintf("%s:%d", flag.Name, userId)))
+	return int(hash.Sum32()%100) < flag.Percentage
+}
+
+type FlagService struct {
+	Repository FlagRepository
+
+	mu       sync.Mutex
+	flags    map[string]FeatureFlag
+	loadedAt time.Time
+}
+
+func NewFlagService(repository FlagRepository) *FlagService {
+	return &FlagService{Repository: repository}
+}
+
+func (service *FlagService) load() (map[string]FeatureFlag, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if service.flags != nil && time.Since(service.loadedAt) < flagRefreshInterval {
+		return service.flags, nil
+	}
+	flags, err := service.Repository.FindAll()
+	if err != nil {
+		return service.flags, err
+	}
+	service.flags = make(map[string]FeatureFlag)
+	for _, flag := range flags {
+		service.flags[flag.Name] = flag
+	}
+	service.loadedAt = time.Now()
+	return service.flags, nil
+}
+
+func (service *FlagService) invalidate() {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	service.flags = nil
+}
+
+// Falls back to the default, or off for unknown flags, when the flag is not
+// configured or cannot be read. A nil service only knows the defaults.
+func (service *FlagService) IsEnabled(name string, userId int) bool {
+	if service == nil {
+		return knownFlags[name]
+	}
+	flags, err := service.load()
+	if err != nil {
+		logf(nil, "Cannot load feature flags: %v", err)
+	}
+	flag, ok := flags[name]
+	if !ok {
+		return knownFlags[name]
+	}
+	return flag.EnabledFor(userId)
+}
+
+// Evaluates every known and configured flag for the user
+func (service *FlagService) EvaluateAll(userId int) map[string]bool {
+	result := make(map[string]bool)
+	for name := range knownFlags {
+		result[name] = service.IsEnabled(name, userId)
+	}
+	if service == nil {
+		return result
+	}
+	flags, _ := service.load()
+	for name := range flags {
+		result[name] = service.IsEnabled(name, userId)
+	}
+	return result
+}
+
+// Lists the configured flags followed by known flags left at their default
+func (service *FlagService) List() ([]FeatureFlag, error) {
+	flags, err := service.Repository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	configured := make(map[string]bool)
+	for _, flag := range flags {
+		configured[flag.Name] = true
+	}
+	var defaults []FeatureFlag
+	for name, enabled := range knownFlags {
+		if configured[name] {
+			continue
+		}
+		flag := FeatureFlag{Name: name, Enabled: enabled}
+		if enabled {
+			flag.Percentage = 100
+		}
+		defaults = append(defaults, flag)
+	}
+	sort.Slice(defaults, func(i, j int) bool { return defaults[i].Name < defaults[j].Name })
+	return append(flags, defaults...), nil
+}
+
+func (service *FlagService) Store(flag FeatureFlag) error {
+	defer service.invalidate()
+	return service.Repository.Store(flag)
+}
+
+func (service *FlagService) Remove(name string) error {
+	defer service.invalidate()
+	return service.Repository.Remove(name)
+}
+
+func (interactor *AdminInteractor) ListFlags(adminId int) ([]FeatureFlag, error, int) {
+	err, code := interactor.requireAdmin(adminId)
+	if err != nil {
+		return nil, err, code
+	}
+	flags, err := interactor.Flags.List()
+	if err != nil {
+		return nil, err, 500
+	}
+	return flags, nil, 200
+}
+
+func (interactor *AdminInteractor) SetFlag(adminId int, flag FeatureFlag) (FeatureFlag, error, int) {
+	err, code := interactor.requireAdmin(adminId)
+	if err != nil {
+		return FeatureFlag{}, err, code
+	}
+	if !flagNamePattern.MatchString(flag.Name) {
+		err = domain.NewFieldError("name", "Must be lowercase letters, digits and '_'")
+		return FeatureFlag{}, err, 400
+	}
+	if flag.Percentage < 0 || flag.Percentage > 100 {
+		return FeatureFlag{}, domain.NewFieldError("percentage", "Must be between 0 and 100"), 400
+	}
+	err = interactor.Flags.Store(flag)
+	if err != nil {
+		return FeatureFlag{}, err, 500
+	}
+	err = interactor.AdminRepository.Audit(AuditEntry{ActorId: adminId, Action: AuditFlag,
+		Detail: fmt.Sprintf("%s enabled=%t percentage=%d users=%v", flag.Name, flag.Enabled,
+			flag.Percentage, flag.UserIds)})
+	if err != nil {
+		return FeatureFlag{}, err, 500
+	}
+	flag.UpdatedAt = time.Now()
+	return flag, nil, 200
+}
+
+// Removing a known flag returns it to its default
+func (interactor *AdminInteractor) RemoveFlag(adminId int, name string) (error, int) {
+	err, code := interactor.requireAdmin(adminId)
+	if err != nil {
+		return err, code
+	}
+	err = interactor.Flags.Remove(name)
+	if err != nil {
+		return err, 500
+	}
+	err = interactor.AdminRepository.Audit(AuditEntry{ActorId: adminId, Action: AuditFlag,
+		Detail: name + " removed"})
+	if err != nil {
+		return err, 500
+	}
+	return nil, 200
+}
+
+func (interactor *ProfileInteractor) ShowFeatures(userId int) (map[string]bool, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	return interactor.Flags.EvaluateAll(userId), nil, 200
+}
+
+func featureDisabled(name string) error {
+	return domain.NewError(domain.CodeForbidden, "Feature '%s' is not enabled", name)
+}
@@ -0,0 +1,169 @@
+package usecases
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const maxFranchiseNameLength = 100
+
+type FranchiseRepository interface {
+	Store(franchise Franchise) (int, error)
+	Remove(franchise Franchise) error
+	FindById(id int) (Franchise, error, int)
+	FindByExternalId(externalId string) (Franchise, error, int)
+	FindAll() ([]Franchise, error)                   //Without their games
+	AddGame(franchiseId, gameId, position int) error //Moves a game already in the franchise
+	RemoveGame(franchiseId, gameId int) (bool, error)
+	FindProgress(userId int) ([]FranchiseProgress, error)
+}
+
+// A series of games such as all Zelda titles, games are ordered by their
+// position in the series
+type Franchise struct {
+	Id         int
+	ExternalId string
+	Name       string
+	Games      []FranchiseGame
+	CreatedAt  time.Time
+}
+
+type FranchiseGame struct {
+	GameId         int
+	GameExternalId string
+	GameName       string
+	Position       int
+}
+
+// How much of a franchise a user has, a game counts once however many of
+// the user's libraries hold it. Franchises the user owns nothing of are left out.
+type FranchiseProgress struct {
+	Franchise Franchise
+	Total     int
+	Owned     int
+	Completed int
+}
+
+type FranchiseInteractor struct {
+	FranchiseRepository FranchiseRepository
+	GameRepository      GameRepository
+	UserRepository      UserRepository
+	logging
+}
+
+func (interactor *FranchiseInteractor) FindFranchiseId(externalId string) (int, error, int) {
+	notFound := domain.NewError(domain.CodeNotFound, "Franchise '%s' does not exist", externalId)
+	if !externalIdPattern.MatchString(externalId) {
+		return 0, notFound, 404
+	}
+	franchise, err, code := interactor.FranchiseRepository.FindByExternalId(externalId)
+	if code == 404 {
+		return 0, notFound, 404
+	}
+	if err != nil {
+		return 0, err, code
+	}
+	return franchise.Id, nil, 200
+}
+
+func (interactor *FranchiseInteractor) AddFranchise(name string) (Franchise, error, int) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxFranchiseNameLength {
+		return Franchise{}, domain.NewFieldError("name", "Must be between 1 and %d characters",
+			maxFranchiseNameLength), 400
+	}
+	id, err := interactor.FranchiseRepository.Store(Franchise{Name: name})
+	if err != nil {
+		return Franchise{}, err, 500
+	}
+	interactor.logf("Added franchise #%d '%s'", id, name)
+	return interactor.FranchiseRepository.FindById(id)
+}
+
+func (interactor *FranchiseInteractor) RemoveFranchise(franchiseId int) (error, int) {
+	franchise, err, code := interactor.FranchiseRepository.FindById(franchiseId)
+	if err != nil {
+		return err, code
+	}
+	err = interactor.FranchiseRepository.Remove(franchise)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("Removed franchise #%d", franchiseId)
+	return nil, 200
+}
+
+func (interactor *FranchiseInteractor) ShowFranchises() ([]Franchise, error, int) {
+	franchises, err := interactor.FranchiseRepository.FindAll()
+	if err != nil {
+		return nil, err, 500
+	}
+	return franchises, nil, 200
+}
+
+func (interactor *FranchiseInteractor) ShowFranchise(franchiseId int) (Franchise, error, int) {
+	return interactor.FranchiseRepository.FindById(franchiseId)
+}
+
+// Without a position the game goes after the last entry
+func (interactor *FranchiseInteractor) AddGame(franchiseId, gameId, position int) (Franchise, error, int) {
+	if position < 0 {
+		return Franchise{}, domain.NewFieldError("position", "Cannot be negative"), 400
+	}
+	franchise, err, code := interactor.FranchiseRepository.FindById(franchiseId)
+	if err != nil {
+		return Franchise{}, err, code
+	}
+	_, err, code = interactor.GameRepository.FindById(gameId)
+	if err != nil {
+		return Franchise{}, err, code
+	}
+	if position == 0 {
+		for _, game := range franchise.Games {
+			if game.GameId != gameId && game.Position >= position {
+				position = game.Position + 1
+			}
+		}
+		if position == 0 {
+			position = 1
+		}
+	}
+	err = interactor.FranchiseRepository.AddGame(franchiseId, gameId, position)
+	if err != nil {
+		return Franchise{}, err, 500
+	}
+	return interactor.FranchiseRepository.FindById(franchiseId)
+}
+
+func (interactor *FranchiseInteractor) RemoveGame(franchiseId, gameId int) (error, int) {
+	removed, err := interactor.FranchiseRepository.RemoveGame(franchiseId, gameId)
+	if err != nil {
+		return err, 500
+	}
+	if !removed {
+		return domain.NewError(domain.CodeNotFound, "Game #%d is not part of franchise #%d",
+			gameId, franchiseId), 404
+	}
+	return nil, 200
+}
+
+// Franchise progress of a user, the franchises closest to being owned in
+// full come first
+func (interactor *FranchiseInteractor) ShowProgress(userId int) ([]FranchiseProgress, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	progress, err := interactor.FranchiseRepository.FindProgress(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	sort.SliceStable(progress, func(i, j int) bool {
+		// Compares Owned/Total without dividing
+		return progress[i].Owned*progress[j].Total > progress[j].Owned*progress[i].Total
+	})
+	return progress, nil, 200
+}
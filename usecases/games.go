@@ -0,0 +1,216 @@
+package usecases
+
+import (
+	"strings"
+
+	"game-tracker/domain"
+)
+
+const (
+	maxBatchSize        = 100
+	maxTags             = 20
+	maxTagLength        = 32
+	maxPlatformLen      = 64
+	maxFilterNameLength = 100 //Characters of a name filter
+	maxGamesPerPage     = 500
+)
+
+const DefaultGameStatus = "owned"
+
+var gameStatuses = map[string]bool{
+	"owned":     true,
+	"wishlist":  true,
+	"backlog":   true,
+	"playing":   true,
+	"completed": true,
+	"abandoned": true,
+}
+
+// Finds a game held by one of the user's libraries
+func findOwnedGame(users UserRepository, libraries LibraryRepository, games GameRepository, userId, gameId int) (Game, error, int) {
+	user, err, code := users.FindById(userId)
+	if err != nil {
+		return Game{}, err, code
+	}
+	for _, libraryId := range user.LibraryIds {
+		library, err, code := libraries.FindById(libraryId)
+		if err != nil {
+			return Game{}, err, code
+		}
+		for _, id := range library.GameIds {
+			if id == gameId {
+				return games.FindById(gameId)
+			}
+		}
+	}
+	return Game{}, domain.NewError(domain.CodeNotFound, "Game #%d is in none of the libraries of user #%d",
+		gameId, userId), 404
+}
+
+// Fields of a library entry to change, nil fields are left untouched
+type GameChange struct {
+	Status   *string
+	Platform *string
+	Tags     *[]string
+}
+
+// Outcome for one game of a batch, Error is nil when the change was applied
+type BatchItem struct {
+	GameId  int
+	Error   error
+	Code    int
+	Version int64 //Version of the entry after the change
+}
+
+// Applies change to every listed game of the library in one transaction.
+// Games that are not in the library are reported per item, the rest still
+// get updated. Entries are last-writer-wins unless baseVersions holds the
+// version the client last saw, an entry past it is reported with a 409.
+func (interactor *ProfileInteractor) UpdateGames(userId, libraryId int, gameIds []int, change GameChange, baseVersions map[int]int64) ([]BatchItem, error, int) {
+	if !interactor.Flags.IsEnabled(FlagGameBatchUpdates, userId) {
+		return nil, featureDisabled(FlagGameBatchUpdates), 403
+	}
+	if len(gameIds) == 0 || len(gameIds) > maxBatchSize {
+		return nil, domain.NewFieldError("ids", "Between 1 and %d games can be updated at once",
+			maxBatchSize), 400
+	}
+	change, err := normalizeGameChange(change)
+	if err != nil {
+		return nil, err, 400
+	}
+	for _, version := range baseVersions {
+		if version < 0 {
+			return nil, domain.NewFieldError("versions", "Versions must be positive"), 400
+		}
+	}
+
+	user, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	library, err, code := interactor.LibraryRepository.FindById(libraryId)
+	if err != nil {
+		return nil, err, code
+	}
+	allowed, err := interactor.libraryAllows(user.Id, library, LibraryRoleEditor)
+	if err != nil {
+		return nil, err, 500
+	}
+	if !allowed {
+		message := "User #%d is not allowed to edit games in library #%d of user #%d"
+		err := domain.NewError(domain.CodeForbidden, message, user.Id, library.Id, library.User.Id)
+		return nil, err, 403
+	}
+
+	inLibrary := make(map[int]bool)
+	for _, id := range library.GameIds {
+		inLibrary[id] = true
+	}
+	var items []BatchItem
+	var updated []int
+	seen := make(map[int]bool)
+	for _, id := range gameIds {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if !inLibrary[id] {
+			err := domain.NewError(domain.CodeNotFound, "Game #%d is not in library #%d", id,
+				libraryId)
+			items = append(items, BatchItem{GameId: id, Error: err, Code: 404})
+			continue
+		}
+		updated = append(updated, id)
+	}
+
+	if len(updated) > 0 {
+		before, err := interactor.libraryEntries(libraryId, updated)
+		if err != nil {
+			return nil, err, 500
+		}
+		versions, err := interactor.GameRepository.UpdateBatch(libraryId, updated, change,
+			baseVersions)
+		if err != nil {
+			return nil, err, 500
+		}
+		var applied []int
+		for _, id := range updated {
+			version, found := versions[id]
+			if !found {
+				err := domain.NewError(domain.CodeConflict,
+					"Game #%d was changed elsewhere, version %d is stale", id, baseVersions[id])
+				items = append(items, BatchItem{GameId: id, Error: err, Code: 409})
+				continue
+			}
+			items = append(items, BatchItem{GameId: id, Code: 200, Version: version})
+			applied = append(applied, id)
+		}
+		if len(applied) > 0 {
+			interactor.recordUpdate(user.Id, libraryId, applied, before, change)
+		}
+	}
+	interactor.count("UpdateGames")
+	return items, nil, 200
+}
+
+// Records the undo of the entries applied and publishes their status events,
+// entries left stale stay out of both
+func (interactor *ProfileInteractor) recordUpdate(userId, libraryId int, applied []int, before []LibraryEntry, change GameChange) {
+	isApplied := make(map[int]bool)
+	for _, id := range applied {
+		isApplied[id] = true
+	}
+	var entries []LibraryEntry
+	for _, entry := range before {
+		if isApplied[entry.GameId] {
+			entries = append(entries, entry)
+		}
+	}
+	after := LibraryState{}
+	after.Apply(LibraryEvent{Kind: LibrarySeeded, Entries: entries})
+	after.Apply(LibraryEvent{Kind: GamesChanged, GameIds: applied, Change: change})
+	interactor.recordUndo(UndoAction{UserId: userId, Kind: UndoUpdateGames, LibraryId: libraryId,
+		GameIds: applied, Before: entries, After: after.SortedEntries()})
+	if change.Status != nil {
+		for _, event := range statusEvents(userId, libraryId, applied, *change.Status) {
+			interactor.publish(event)
+		}
+	}
+}
+
+func normalizeGameChange(change GameChange) (GameChange, error) {
+	if change.Status == nil && change.Platform == nil && change.Tags == nil {
+		return change, domain.NewError(domain.CodeInvalid, "Nothing to change")
+	}
+	if change.Status != nil && !gameStatuses[*change.Status] {
+		return change, domain.NewFieldError("status", "Status '%s' is unknown", *change.Status)
+	}
+	if change.Platform != nil {
+		platform := strings.TrimSpace(*change.Platform)
+		if len(platform) > maxPlatformLen {
+			return change, domain.NewFieldError("platform", "Must be at most %d characters",
+				maxPlatformLen)
+		}
+		change.Platform = &platform
+	}
+	if change.Tags != nil {
+		tags := []string{}
+		seen := make(map[string]bool)
+		for _, tag := range *change.Tags {
+			tag = strings.TrimSpace(tag)
+			if tag == "" || len(tag) > maxTagLength {
+				return change, domain.NewFieldError("tags", "Tags must be 1 to %d characters",
+					maxTagLength)
+			}
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) > maxTags {
+			return change, domain.NewFieldError("tags", "At most %d tags are allowed", maxTags)
+		}
+		change.Tags = &tags
+	}
+	return change, nil
+}
@@ -0,0 +1,339 @@
+package usecases
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const (
+	GoalCompleteGames = "complete_games" //Complete Target games within the period
+	GoalPlayHours     = "play_hours"     //Play Target hours within the period
+	GoalBacklogUnder  = "backlog_under"  //Keep fewer than Target games in the backlog
+
+	GoalPeriodYear    = "year"
+	GoalPeriodQuarter = "quarter"
+
+	maxGoals         = 20 //Per user
+	maxGoalTarget    = 100000
+	goalReminderGap  = 7 * 24 * time.Hour
+	goalReminderLead = 7 * 24 * time.Hour //Goals are not nagged about in their first week
+)
+
+var goalKinds = map[string]bool{
+	GoalCompleteGames: true,
+	GoalPlayHours:     true,
+	GoalBacklogUnder:  true,
+}
+
+type GoalRepository interface {
+	Store(goal Goal) (int, error)
+	FindById(id int) (Goal, error, int)
+	FindByUser(userId int) ([]Goal, error) //Newest period first
+	FindRunning(now time.Time) ([]Goal, error)
+	SetTarget(id, target int) error
+	MarkReminded(id int, at time.Time) error
+	Remove(goal Goal) error
+	RemoveAll(userId int) error
+}
+
+// A target for a calendar year or quarter. The period is fixed in the
+// user's timezone when the goal is set.
+type Goal struct {
+	Id         int
+	UserId     int
+	Kind       string
+	Target     int
+	Period     string
+	Year       int
+	Quarter    int //1 to 4, 0 for yearly goals
+	StartsAt   time.Time
+	EndsAt     time.Time
+	RemindedAt time.Time //Zero until the first reminder
+	CreatedAt  time.Time
+}
+
+// Such as "2026" or "Q3 2026"
+func (goal Goal) PeriodName() string {
+	if goal.Period == GoalPeriodQuarter {
+		return fmt.Sprintf("Q%d %d", goal.Quarter, goal.Year)
+	}
+	return strconv.Itoa(goal.Year)
+}
+
+// Progress is computed from the sessions, completions and backlog the user
+// already tracks. Expected is where a steady pace would be by now.
+type GoalProgress struct {
+	Goal     Goal
+	Current  int
+	Expected int
+	Achieved bool
+	Behind   bool //Only running goals fall behind
+}
+
+type GoalInteractor struct {
+	GoalRepository        GoalRepository
+	UserRepository        UserRepository
+	GameRepository        GameRepository
+	ActivityRepository    ActivityRepository
+	PlaySessionRepository PlaySessionRepository
+	SettingsRepository    SettingsRepository
+	EventBus              domain.EventBus
+	logging
+}
+
+func (interactor *GoalInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.GoalRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove goals of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+func (interactor *GoalInteractor) AddGoal(userId int, goal Goal) (GoalProgress, error, int) {
+	if !goalKinds[goal.Kind] {
+		return GoalProgress{}, domain.NewFieldError("kind", "Must be %s, %s or %s", GoalCompleteGames,
+			GoalPlayHours, GoalBacklogUnder), 400
+	}
+	if goal.Target < 1 || goal.Target > maxGoalTarget {
+		return GoalProgress{}, domain.NewFieldError("target", "Must be between 1 and %d", maxGoalTarget), 400
+	}
+	location := userLocation(interactor.SettingsRepository, userId)
+	now := time.Now().In(location)
+	if goal.Year == 0 {
+		goal.Year = now.Year()
+	}
+	if goal.Year < now.Year()-1 || goal.Year > now.Year()+1 {
+		return GoalProgress{}, domain.NewFieldError("year", "Must be between %d and %d",
+			now.Year()-1, now.Year()+1), 400
+	}
+	switch goal.Period {
+	case GoalPeriodYear:
+		goal.Quarter = 0
+		goal.StartsAt = time.Date(goal.Year, time.January, 1, 0, 0, 0, 0, location)
+		goal.EndsAt = goal.StartsAt.AddDate(1, 0, 0)
+	case GoalPeriodQuarter:
+		if goal.Quarter == 0 && goal.Year == now.Year() {
+			goal.Quarter = (int(now.Month())-1)/3 + 1
+		}
+		if goal.Quarter < 1 || goal.Quarter > 4 {
+			return GoalProgress{}, domain.NewFieldError("quarter", "Must be between 1 and 4"), 400
+		}
+		goal.StartsAt = time.Date(goal.Year, time.Month(goal.Quarter*3-2), 1, 0, 0, 0, 0, location)
+		goal.EndsAt = goal.StartsAt.AddDate(0, 3, 0)
+	default:
+		return GoalProgress{}, domain.NewFieldError("period", "Must be %s or %s", GoalPeriodYear,
+			GoalPeriodQuarter), 400
+	}
+
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return GoalProgress{}, err, code
+	}
+	goals, err := interactor.GoalRepository.FindByUser(userId)
+	if err != nil {
+		return GoalProgress{}, err, 500
+	}
+	if len(goals) >= maxGoals {
+		return GoalProgress{}, domain.NewError(domain.CodeConflict,
+			"User #%d already has %d goals, remove one first", userId, maxGoals), 409
+	}
+	goal.UserId = userId
+	goal.StartsAt, goal.EndsAt = goal.StartsAt.UTC(), goal.EndsAt.UTC()
+	goal.Id, err = interactor.GoalRepository.Store(goal)
+	if err != nil {
+		return GoalProgress{}, err, 500
+	}
+	interactor.logf("User #%d set goal #%d to %s %d in %s", userId, goal.Id, goal.Kind, goal.Target,
+		goal.PeriodName())
+	progress, err, code := interactor.ShowGoal(userId, goal.Id)
+	if err != nil {
+		return GoalProgress{}, err, code
+	}
+	return progress, nil, 201
+}
+
+func (interactor *GoalInteractor) ShowGoals(userId int) ([]GoalProgress, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	goals, err := interactor.GoalRepository.FindByUser(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	var progresses []GoalProgress
+	now := time.Now()
+	for _, goal := range goals {
+		progress, err := interactor.progress(goal, now)
+		if err != nil {
+			return nil, err, 500
+		}
+		progresses = append(progresses, progress)
+	}
+	return progresses, nil, 200
+}
+
+func (interactor *GoalInteractor) ShowGoal(userId, goalId int) (GoalProgress, error, int) {
+	goal, err, code := interactor.findGoal(userId, goalId)
+	if err != nil {
+		return GoalProgress{}, err, code
+	}
+	progress, err := interactor.progress(goal, time.Now())
+	if err != nil {
+		return GoalProgress{}, err, 500
+	}
+	return progress, nil, 200
+}
+
+// Only the target can change, a goal for another period is a new goal
+func (interactor *GoalInteractor) EditGoal(userId, goalId, target int) (GoalProgress, error, int) {
+	if target < 1 || target > maxGoalTarget {
+		return GoalProgress{}, domain.NewFieldError("target", "Must be between 1 and %d", maxGoalTarget), 400
+	}
+	goal, err, code := interactor.findGoal(userId, goalId)
+	if err != nil {
+		return GoalProgress{}, err, code
+	}
+	err = interactor.GoalRepository.SetTarget(goal.Id, target)
+	if err != nil {
+		return GoalProgress{}, err, 500
+	}
+	interactor.logf("User #%d changed the target of goal #%d to %d", userId, goalId, target)
+	return interactor.ShowGoal(userId, goalId)
+}
+
+func (interactor *GoalInteractor) RemoveGoal(userId, goalId int) (error, int) {
+	goal, err, code := interactor.findGoal(userId, goalId)
+	if err != nil {
+		return err, code
+	}
+	err = interactor.GoalRepository.Remove(goal)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("User #%d removed goal #%d", userId, goalId)
+	return nil, 200
+}
+
+// Run by the goal job: users behind on a running goal are reminded, at most
+// once a week per goal
+func (interactor *GoalInteractor) RemindGoals() error {
+	now := time.Now()
+	goals, err := interactor.GoalRepository.FindRunning(now)
+	if err != nil {
+		return err
+	}
+	reminded := 0
+	for _, goal := range goals {
+		if now.Sub(goal.StartsAt) < goalReminderLead ||
+			(!goal.RemindedAt.IsZero() && now.Sub(goal.RemindedAt) < goalReminderGap) {
+			continue
+		}
+		progress, err := interactor.progress(goal, now)
+		if err != nil {
+			return err
+		}
+		if !progress.Behind {
+			continue
+		}
+		err = interactor.GoalRepository.MarkReminded(goal.Id, now.UTC())
+		if err != nil {
+			return err
+		}
+		if interactor.EventBus != nil {
+			interactor.EventBus.Publish(domain.Event{Name: domain.EventGoalBehind, UserId: goal.UserId,
+				EntityId: goal.Id, Payload: map[string]string{"kind": goal.Kind,
+					"current": strconv.Itoa(progress.Current), "target": strconv.Itoa(goal.Target),
+					"period": goal.PeriodName()}})
+		}
+		reminded++
+	}
+	if reminded > 0 {
+		interactor.logf("Reminded users of %d goals they are behind on", reminded)
+	}
+	return nil
+}
+
+func (interactor *GoalInteractor) findGoal(userId, goalId int) (Goal, error, int) {
+	goal, err, code := interactor.GoalRepository.FindById(goalId)
+	if code == 404 || (err == nil && goal.UserId != userId) {
+		return Goal{}, domain.NewError(domain.CodeNotFound, "Goal #%d does not exist", goalId), 404
+	}
+	if err != nil {
+		return Goal{}, err, code
+	}
+	return goal, nil, 200
+}
+
+func (interactor *GoalInteractor) progress(goal Goal, now time.Time) (GoalProgress, error) {
+	progress := GoalProgress{Goal: goal}
+	until := now
+	if goal.EndsAt.Before(until) {
+		until = goal.EndsAt
+	}
+	running := now.After(goal.StartsAt) && now.Before(goal.EndsAt)
+
+	switch goal.Kind {
+	case GoalBacklogUnder:
+		backlog, err := interactor.backlogSize(goal.UserId)
+		if err != nil {
+			return GoalProgress{}, err
+		}
+		progress.Current = backlog
+		progress.Expected = goal.Target - 1
+		progress.Achieved = backlog < goal.Target
+		progress.Behind = running && !progress.Achieved
+		return progress, nil
+	case GoalCompleteGames:
+		completed, err := interactor.ActivityRepository.CountGames(goal.UserId, ActivityGameCompleted,
+			goal.StartsAt, until)
+		if err != nil {
+			return GoalProgress{}, err
+		}
+		progress.Current = completed
+	case GoalPlayHours:
+		sessions, err := interactor.PlaySessionRepository.FindByUser(goal.UserId, goal.StartsAt, until)
+		if err != nil {
+			return GoalProgress{}, err
+		}
+		minutes := 0
+		for _, session := range sessions {
+			minutes += session.Minutes
+		}
+		progress.Current = minutes / 60
+	}
+
+	// A steady pace, rounded down so nobody is behind on day one
+	elapsed := until.Sub(goal.StartsAt)
+	if elapsed > 0 {
+		progress.Expected = int(float64(goal.Target) * float64(elapsed) / float64(goal.EndsAt.Sub(goal.StartsAt)))
+	}
+	progress.Achieved = progress.Current >= goal.Target
+	progress.Behind = running && !progress.Achieved && progress.Current < progress.Expected
+	return progress, nil
+}
+
+// Games with the backlog status in any of the user's own libraries
+func (interactor *GoalInteractor) backlogSize(userId int) (int, error) {
+	user, err, _ := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return 0, err
+	}
+	backlog := make(map[int]bool)
+	for _, libraryId := range user.LibraryIds {
+		games, err := interactor.GameRepository.FindByLib(libraryId, GameFilter{}, Page{})
+		if err != nil {
+			return 0, err
+		}
+		for _, game := range games {
+			if game.Status == "backlog" {
+				backlog[game.Id] = true
+			}
+		}
+	}
+	return len(backlog), nil
+}
@@ -0,0 +1,221 @@
+package usecases
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"game-tracker/domain"
+)
+
+const (
+	HardwareConsole    = "console"
+	HardwareHandheld   = "handheld"
+	HardwarePeripheral = "peripheral"
+
+	maxHardware           = 500 //Per user
+	maxHardwareNameLength = 100
+)
+
+var hardwareKinds = map[string]bool{
+	HardwareConsole:    true,
+	HardwareHandheld:   true,
+	HardwarePeripheral: true,
+}
+
+type HardwareRepository interface {
+	Store(item Hardware) (int, error)
+	FindById(id int) (Hardware, error, int)
+	FindByUser(userId int) ([]Hardware, error)           //By kind and name
+	FindExpiring(from, to time.Time) ([]Hardware, error) //Not reminded yet, warranty ending in the range
+	Update(item Hardware) error                          //Forgets the reminder when the warranty date changes
+	MarkReminded(id int, at time.Time) error
+	Remove(item Hardware) error
+	RemoveAll(userId int) error
+}
+
+// A console or peripheral the user owns. PurchasedOn and WarrantyUntil
+// only carry a day and are zero when unknown.
+type Hardware struct {
+	Id            int
+	UserId        int
+	Kind          string
+	Name          string //Such as "PlayStation 5"
+	Model         string //The manufacturer's model number
+	Serial        string
+	PurchasedOn   time.Time
+	WarrantyUntil time.Time
+	RemindedAt    time.Time //Zero until the warranty reminder was sent
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Under warranty through the last day of it
+func (item Hardware) UnderWarranty(today time.Time) bool {
+	return !item.WarrantyUntil.IsZero() && !today.After(item.WarrantyUntil)
+}
+
+type HardwareInteractor struct {
+	HardwareRepository HardwareRepository
+	UserRepository     UserRepository
+	SettingsRepository SettingsRepository
+	EventBus           domain.EventBus
+	logging
+}
+
+func (interactor *HardwareInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.HardwareRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove hardware of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+func validHardware(item Hardware, today time.Time) (Hardware, error) {
+	item.Name = strings.TrimSpace(item.Name)
+	item.Model = strings.TrimSpace(item.Model)
+	item.Serial = strings.TrimSpace(item.Serial)
+	if !hardwareKinds[item.Kind] {
+		return Hardware{}, domain.NewFieldError("kind", "Must be %s, %s or %s", HardwareConsole,
+			HardwareHandheld, HardwarePeripheral)
+	}
+	for _, field := range []struct{ name, value string }{
+		{"name", item.Name}, {"model", item.Model}, {"serial", item.Serial}} {
+		if utf8.RuneCountInString(field.value) > maxHardwareNameLength {
+			return Hardware{}, domain.NewFieldError(field.name, "Must be at most %d characters",
+				maxHardwareNameLength)
+		}
+	}
+	if item.Name == "" {
+		return Hardware{}, domain.NewFieldError("name", "Name is required")
+	}
+	if item.PurchasedOn.After(today) {
+		return Hardware{}, domain.NewFieldError("purchasedOn", "Cannot be in the future")
+	}
+	if !item.WarrantyUntil.IsZero() && item.WarrantyUntil.Before(item.PurchasedOn) {
+		return Hardware{}, domain.NewFieldError("warrantyUntil", "Cannot be before the purchase date")
+	}
+	return item, nil
+}
+
+func (interactor *HardwareInteractor) AddHardware(userId int, item Hardware) (Hardware, error, int) {
+	item, err := validHardware(item, interactor.today(userId))
+	if err != nil {
+		return Hardware{}, err, 400
+	}
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return Hardware{}, err, code
+	}
+	items, err := interactor.HardwareRepository.FindByUser(userId)
+	if err != nil {
+		return Hardware{}, err, 500
+	}
+	if len(items) >= maxHardware {
+		return Hardware{}, domain.NewError(domain.CodeConflict,
+			"User #%d already has %d hardware items, remove one first", userId, maxHardware), 409
+	}
+
+	item.UserId = userId
+	id, err := interactor.HardwareRepository.Store(item)
+	if err != nil {
+		return Hardware{}, err, 500
+	}
+	interactor.logf("User #%d added hardware #%d", userId, id)
+	item, err, code = interactor.HardwareRepository.FindById(id)
+	if err != nil {
+		return Hardware{}, err, code
+	}
+	return item, nil, 201
+}
+
+func (interactor *HardwareInteractor) ShowHardware(userId int) ([]Hardware, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	items, err := interactor.HardwareRepository.FindByUser(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	return items, nil, 200
+}
+
+func (interactor *HardwareInteractor) ShowHardwareItem(userId, itemId int) (Hardware, error, int) {
+	return interactor.findHardware(userId, itemId)
+}
+
+// Replaces every field, a new warranty date is reminded of again
+func (interactor *HardwareInteractor) EditHardware(userId, itemId int, changed Hardware) (Hardware, error, int) {
+	changed, err := validHardware(changed, interactor.today(userId))
+	if err != nil {
+		return Hardware{}, err, 400
+	}
+	item, err, code := interactor.findHardware(userId, itemId)
+	if err != nil {
+		return Hardware{}, err, code
+	}
+	changed.Id = item.Id
+	changed.UserId = item.UserId
+	err = interactor.HardwareRepository.Update(changed)
+	if err != nil {
+		return Hardware{}, err, 500
+	}
+	interactor.logf("User #%d edited hardware #%d", userId, itemId)
+	return interactor.HardwareRepository.FindById(itemId)
+}
+
+func (interactor *HardwareInteractor) RemoveHardware(userId, itemId int) (error, int) {
+	item, err, code := interactor.findHardware(userId, itemId)
+	if err != nil {
+		return err, code
+	}
+	err = interactor.HardwareRepository.Remove(item)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("User #%d removed hardware #%d", userId, itemId)
+	return nil, 200
+}
+
+// Run by the hardware job: owners are reminded once when a warranty ends
+// within lead
+func (interactor *HardwareInteractor) RemindWarranties(lead time.Duration) error {
+	now := time.Now().UTC()
+	items, err := interactor.HardwareRepository.FindExpiring(today(), now.Add(lead))
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		err = interactor.HardwareRepository.MarkReminded(item.Id, now)
+		if err != nil {
+			return err
+		}
+		if interactor.EventBus != nil {
+			interactor.EventBus.Publish(domain.Event{Name: domain.EventWarrantyExpiring,
+				UserId: item.UserId, EntityId: item.Id, Payload: map[string]string{"name": item.Name,
+					"date": item.WarrantyUntil.Format("2006-01-02")}})
+		}
+	}
+	if len(items) > 0 {
+		interactor.logf("Reminded users of %d warranties ending", len(items))
+	}
+	return nil
+}
+
+func (interactor *HardwareInteractor) findHardware(userId, itemId int) (Hardware, error, int) {
+	item, err, code := interactor.HardwareRepository.FindById(itemId)
+	if code == 404 || (err == nil && item.UserId != userId) {
+		return Hardware{}, domain.NewError(domain.CodeNotFound, "Hardware #%d does not exist", itemId), 404
+	}
+	if err != nil {
+		return Hardware{}, err, code
+	}
+	return item, nil, 200
+}
+
+// Purchase dates are checked against the day in the user's timezone
+func (interactor *HardwareInteractor) today(userId int) time.Time {
+	return localDay(time.Now(), userLocation(interactor.SettingsRepository, userId))
+}
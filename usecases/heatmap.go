@@ -0,0 +1,66 @@
+package usecases
+
+import (
+	"time"
+)
+
+// How far back the heatmap reaches, it always starts on a Sunday so the
+// weeks line up as columns
+const heatmapWeeks = 53
+
+// Minutes played per day over the last year, like a contribution graph.
+// Days are dates at midnight UTC in the user's timezone, days without
+// sessions are listed with zero minutes.
+type Heatmap struct {
+	From       time.Time
+	To         time.Time //Today
+	Days       []HeatmapDay
+	Minutes    int
+	ActiveDays int
+	MaxMinutes int
+}
+
+type HeatmapDay struct {
+	Day     time.Time
+	Minutes int
+	Level   int //0 without play, 1 to 4 by quarters of the busiest day
+}
+
+func (interactor *CalendarInteractor) ShowHeatmap(userId int) (Heatmap, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return Heatmap{}, err, code
+	}
+	location := userLocation(interactor.SettingsRepository, userId)
+	now := time.Now()
+	today := localDay(now, location)
+	from := today.AddDate(0, 0, -7*(heatmapWeeks-1)-int(today.Weekday()))
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location)
+	minutes, err := interactor.PlaySessionRepository.MinutesPerDay(userId, start, now, location.String())
+	if err != nil {
+		return Heatmap{}, err, 500
+	}
+	return computeHeatmap(minutes, from, today), nil, 200
+}
+
+func computeHeatmap(minutes map[time.Time]int, from, to time.Time) Heatmap {
+	heatmap := Heatmap{From: from, To: to}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		played := minutes[day]
+		heatmap.Days = append(heatmap.Days, HeatmapDay{Day: day, Minutes: played})
+		heatmap.Minutes += played
+		if played > 0 {
+			heatmap.ActiveDays++
+		}
+		if played > heatmap.MaxMinutes {
+			heatmap.MaxMinutes = played
+		}
+	}
+	for i, day := range heatmap.Days {
+		if day.Minutes > 0 {
+			heatmap.Days[i].Level = (4*day.Minutes + heatmap.MaxMinutes - 1) / heatmap.MaxMinutes
+		}
+	}
+	return heatmap
+}
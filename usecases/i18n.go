@@ -0,0 +1,43 @@
+package usecases
+
+import (
+	"fmt"
+)
+
+// The locale messages are written in, catalogs of other locales translate
+// its format strings
+const DefaultLocale = "en"
+
+// Translates a message given by its English format string. Messages missing
+// from a catalog stay in English.
+type Translator interface {
+	Translate(locale, format string, args ...interface{}) string
+	Lookup(locale, text string) string //Text that is not a format, such as a name, in locale
+	Supports(locale string) bool
+	Locales() []string
+	Match(acceptLanguage string) string //The best supported locale of an Accept-Language header
+}
+
+// An empty locale follows the client's Accept-Language
+func validLocale(translator Translator, locale string) bool {
+	if locale == "" || locale == DefaultLocale {
+		return true
+	}
+	return translator != nil && translator.Supports(locale)
+}
+
+func supportedLocales(translator Translator) []string {
+	if translator == nil {
+		return []string{DefaultLocale}
+	}
+	return translator.Locales()
+}
+
+// Messages written outside of a request, such as notifications, are in the
+// user's locale or English
+func translate(translator Translator, settings Settings, format string, args ...interface{}) string {
+	if translator == nil || settings.Locale == "" {
+		return fmt.Sprintf(format, args...)
+	}
+	return translator.Translate(settings.Locale, format, args...)
+}
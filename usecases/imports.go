@@ -0,0 +1,427 @@
+package usecases
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"game-tracker/domain"
+)
+
+const (
+	maxImportRows        = 10000
+	maxImportTitleLength = 200
+)
+
+// A game as read from the export of another tracker, the status is already
+// mapped to ours
+type ImportRow struct {
+	Line     int
+	Title    string
+	Platform string
+	Status   string
+}
+
+// Reads the export of one tracker
+type importFormat func(data []byte) ([]ImportRow, error)
+
+var importFormats = map[string]importFormat{
+	"backloggd": parseBackloggd,
+	"hltb":      parseHltb,
+	"grouvee":   parseGrouvee,
+}
+
+type ImportedGame struct {
+	Row  ImportRow
+	Game Game
+}
+
+type UnmatchedRow struct {
+	Row    ImportRow
+	Reason string
+}
+
+// What became of every row of an import
+type ImportReport struct {
+	Format    string
+	Imported  []ImportedGame
+	Skipped   []ImportRow //Already in the library or listed twice
+	Unmatched []UnmatchedRow
+}
+
+// Adds the games of another tracker's export to a library. Titles are
+// resolved through the metadata provider, the ones it does not know are
+// reported unmatched and left out. Without a provider every title is taken
+// as written. Games already in the library are skipped, so an export can be
+// imported again.
+func (interactor *ProfileInteractor) ImportGames(userId, libraryId int, format string, data []byte) (ImportReport, error, int) {
+	parse, known := importFormats[strings.ToLower(format)]
+	if !known {
+		return ImportReport{}, domain.NewFieldError("format",
+			"Format '%s' is unknown, use backloggd, hltb or grouvee", format), 400
+	}
+	rows, err := parse(data)
+	if err != nil {
+		return ImportReport{}, err, 400
+	}
+	if len(rows) > maxImportRows {
+		return ImportReport{}, domain.NewFieldError("file", "At most %d games can be imported at once",
+			maxImportRows), 400
+	}
+
+	owned, err, code := interactor.importTarget(userId, libraryId)
+	if err != nil {
+		return ImportReport{}, err, code
+	}
+	seen := make(map[string]bool)
+	for name := range owned {
+		seen[name] = true
+	}
+
+	limit, err := interactor.Parental.RatingLimit(userId)
+	if err != nil {
+		return ImportReport{}, err, 500
+	}
+	report := ImportReport{Format: strings.ToLower(format)}
+	var pending []ImportRow
+	var games []Game
+	for _, row := range rows {
+		if seen[strings.ToLower(row.Title)] {
+			report.Skipped = append(report.Skipped, row)
+			continue
+		}
+		seen[strings.ToLower(row.Title)] = true
+		if len(row.Platform) > maxPlatformLen || !printableText(row.Platform) {
+			row.Platform = ""
+		}
+		if reason := interactor.resolveTitle(row.Title); reason != "" {
+			report.Unmatched = append(report.Unmatched, UnmatchedRow{Row: row, Reason: reason})
+			continue
+		}
+		game, err, _ := interactor.rateGame(Game{Name: row.Title})
+		if err != nil {
+			report.Unmatched = append(report.Unmatched, UnmatchedRow{Row: row, Reason: err.Error()})
+			continue
+		}
+		pending = append(pending, row)
+		games = append(games, game)
+	}
+	if len(games) > 0 {
+		err = interactor.importBatch(userId, libraryId, limit, pending, games, &report)
+		if err != nil {
+			return ImportReport{}, err, 500
+		}
+	}
+	interactor.count("ImportGames")
+	interactor.logf("User #%d imported %d games from %s into library #%d, %d unmatched",
+		userId, len(report.Imported), report.Format, libraryId, len(report.Unmatched))
+	return report, nil, 200
+}
+
+// Stores the games of the rows left to import and adds them to the library
+// in two batches, instead of a few statements per row which large exports
+// would take minutes for. Games rated above limit are reported unmatched.
+func (interactor *ProfileInteractor) importBatch(userId, libraryId, limit int, rows []ImportRow, games []Game, report *ImportReport) error {
+	games, err := interactor.GameRepository.StoreBatch(games)
+	if err != nil {
+		return err
+	}
+	var entries []Game
+	var imported []ImportedGame
+	for i, game := range games {
+		// A game stored before keeps its rating
+		err, _ := checkRating(game, limit)
+		if err != nil {
+			report.Unmatched = append(report.Unmatched, UnmatchedRow{Row: rows[i], Reason: err.Error()})
+			continue
+		}
+		game.Status, game.Platform = rows[i].Status, rows[i].Platform
+		entries = append(entries, game)
+		imported = append(imported, ImportedGame{Row: rows[i], Game: game})
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	added, err := interactor.GameRepository.AddBatchToLib(libraryId, entries)
+	if err != nil {
+		return err
+	}
+	isAdded := make(map[int]bool)
+	for _, gameId := range added {
+		isAdded[gameId] = true
+	}
+	// Statuses come with the entries and raise no events of their own, the
+	// whole history of another tracker should not flood the activity feed
+	for _, game := range imported {
+		if !isAdded[game.Game.Id] {
+			report.Skipped = append(report.Skipped, game.Row)
+			continue
+		}
+		report.Imported = append(report.Imported, game)
+		interactor.publish(domain.Event{Name: domain.EventGameAdded, UserId: userId,
+			EntityId: game.Game.Id, Payload: map[string]string{"name": game.Game.Name,
+				"libraryId": strconv.Itoa(libraryId)}})
+	}
+	return nil
+}
+
+// The games already in the library keyed by lowercased name, imports only
+// go to libraries the importing user may edit
+func (interactor *ProfileInteractor) importTarget(userId, libraryId int) (map[string]Game, error, int) {
+	user, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	library, err, code := interactor.LibraryRepository.FindById(libraryId)
+	if err != nil {
+		return nil, err, code
+	}
+	allowed, err := interactor.libraryAllows(user.Id, library, LibraryRoleEditor)
+	if err != nil {
+		return nil, err, 500
+	}
+	if !allowed {
+		message := "User #%d is not allowed to import games to library #%d of user #%d"
+		err := domain.NewError(domain.CodeForbidden, message, user.Id, library.Id, library.User.Id)
+		return nil, err, 403
+	}
+	games, err := interactor.GameRepository.FindByLib(libraryId, GameFilter{}, Page{})
+	if err != nil {
+		return nil, err, 500
+	}
+	owned := make(map[string]Game)
+	for _, game := range games {
+		owned[strings.ToLower(game.Name)] = game
+	}
+	return owned, nil, 200
+}
+
+// Empty when the title can be imported, the reason otherwise
+func (interactor *ProfileInteractor) resolveTitle(title string) string {
+	if len(title) > maxImportTitleLength {
+		return fmt.Sprintf("Title is longer than %d characters", maxImportTitleLength)
+	}
+	if !printableText(title) {
+		return "Title is not valid UTF-8 or contains control characters"
+	}
+	if interactor.MetadataProvider == nil {
+		return ""
+	}
+	_, found, err := interactor.MetadataProvider.ReleaseDate(title)
+	if err != nil {
+		interactor.logf("Cannot look up '%s': %v", title, err)
+		return "The metadata provider could not be reached"
+	}
+	if !found {
+		return "The metadata provider does not know this title"
+	}
+	return ""
+}
+
+// Records of an export with the line each starts on, columns are looked up
+// by lowercased header
+type csvExport struct {
+	columns map[string]int
+	records [][]string
+	lines   []int
+}
+
+func readCsvExport(data []byte, titleColumns ...string) (csvExport, int, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err != nil {
+		return csvExport{}, 0, domain.NewFieldError("file", "Is not a CSV file with a header row")
+	}
+	export := csvExport{columns: make(map[string]int)}
+	for i, name := range header {
+		export.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	title := export.column(titleColumns...)
+	if title < 0 {
+		return csvExport{}, 0, domain.NewFieldError("file", "Has no %s column", titleColumns[0])
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return csvExport{}, 0, domain.NewFieldError("file", "Is not valid CSV: %v", err)
+		}
+		// Rows without a title are dropped by every format, so padding does
+		// not count toward the limit
+		if export.value(record, title) == "" {
+			continue
+		}
+		// Stops reading huge files early, ImportGames rejects them anyway
+		if len(export.records) == maxImportRows {
+			return csvExport{}, 0, domain.NewFieldError("file", "At most %d games can be imported at once",
+				maxImportRows)
+		}
+		line, _ := reader.FieldPos(0)
+		export.records = append(export.records, record)
+		export.lines = append(export.lines, line)
+	}
+	return export, title, nil
+}
+
+// Index of the first column found, -1 when the export has none of them
+func (export csvExport) column(names ...string) int {
+	for _, name := range names {
+		if index, found := export.columns[name]; found {
+			return index
+		}
+	}
+	return -1
+}
+
+func (export csvExport) value(record []string, column int) string {
+	if column < 0 || column >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[column])
+}
+
+// Backloggd exports one row per game with a single status
+var backloggdStatuses = map[string]string{
+	"playing":   "playing",
+	"backlog":   "backlog",
+	"wishlist":  "wishlist",
+	"completed": "completed",
+	"mastered":  "completed",
+	"retired":   "completed",
+	"played":    "owned",
+	"shelved":   "backlog",
+	"abandoned": "abandoned",
+}
+
+func parseBackloggd(data []byte) ([]ImportRow, error) {
+	export, title, err := readCsvExport(data, "game name", "name", "title")
+	if err != nil {
+		return nil, err
+	}
+	status := export.column("status", "played status")
+	platform := export.column("platform", "platforms")
+	var rows []ImportRow
+	for i, record := range export.records {
+		row := ImportRow{Line: export.lines[i], Title: export.value(record, title),
+			Platform: export.value(record, platform), Status: "owned"}
+		if mapped, found := backloggdStatuses[strings.ToLower(export.value(record, status))]; found {
+			row.Status = mapped
+		}
+		if row.Title != "" {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// HowLongToBeat marks the lists a game is on with one column each, the
+// first list found decides
+var hltbLists = []struct{ column, status string }{
+	{"playing", "playing"},
+	{"completed", "completed"},
+	{"retired", "abandoned"},
+	{"replay", "completed"},
+	{"backlog", "backlog"},
+}
+
+func parseHltb(data []byte) ([]ImportRow, error) {
+	export, title, err := readCsvExport(data, "title", "name")
+	if err != nil {
+		return nil, err
+	}
+	platform := export.column("platform")
+	var rows []ImportRow
+	for i, record := range export.records {
+		row := ImportRow{Line: export.lines[i], Title: export.value(record, title),
+			Platform: export.value(record, platform), Status: "owned"}
+		for _, list := range hltbLists {
+			marked := export.value(record, export.column(list.column))
+			if marked != "" && marked != "0" && !strings.EqualFold(marked, "no") {
+				row.Status = list.status
+				break
+			}
+		}
+		if row.Title != "" {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// Grouvee keeps the shelves and platforms of a game as JSON objects keyed
+// by name, the most active shelf decides
+var grouveeShelves = []struct{ shelf, status string }{
+	{"playing", "playing"},
+	{"beaten", "completed"},
+	{"completed", "completed"},
+	{"abandoned", "abandoned"},
+	{"dropped", "abandoned"},
+	{"backlog", "backlog"},
+	{"wish list", "wishlist"},
+	{"wishlist", "wishlist"},
+	{"played", "owned"},
+}
+
+func parseGrouvee(data []byte) ([]ImportRow, error) {
+	export, title, err := readCsvExport(data, "name", "title")
+	if err != nil {
+		return nil, err
+	}
+	shelvesColumn := export.column("shelves")
+	platformsColumn := export.column("platforms")
+	var rows []ImportRow
+	for i, record := range export.records {
+		row := ImportRow{Line: export.lines[i], Title: export.value(record, title), Status: "owned"}
+		shelves, err := jsonKeys(export.value(record, shelvesColumn))
+		if err != nil {
+			return nil, domain.NewFieldError("file", "Line %d has unreadable shelves", row.Line)
+		}
+		platforms, err := jsonKeys(export.value(record, platformsColumn))
+		if err != nil {
+			return nil, domain.NewFieldError("file", "Line %d has unreadable platforms", row.Line)
+		}
+		if len(platforms) > 0 {
+			row.Platform = platforms[0]
+		}
+	shelf:
+		for _, known := range grouveeShelves {
+			for _, name := range shelves {
+				if strings.EqualFold(name, known.shelf) {
+					row.Status = known.status
+					break shelf
+				}
+			}
+		}
+		if row.Title != "" {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// Sorted keys of a JSON object, an empty value has none
+func jsonKeys(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var object map[string]json.RawMessage
+	err := json.Unmarshal([]byte(value), &object)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,89 @@
+package usecases
+
+import (
+	"strings"
+	"testing"
+)
+
+var importSeeds = []string{
+	"",
+	"\ufeffGame Name,Status,Platform\nHades,Completed,PC\n",
+	"Title,Platform,Playing,Completed\nCeleste,Switch,,1\n\"Outer Wilds\",PC,x,\n",
+	"Name,Shelves,Platforms\nTunic,\"{\"\"Playing\"\": {}}\",\"{\"\"PC\"\": {}}\"\n",
+	"Name,Shelves\nTunic,{not json\n",
+	"Title\n,,,\n\n\"unterminated\n",
+	"Title\n\x00\xff\n",
+}
+
+// Padding rows without a title are skipped instead of counting toward
+// maxImportRows
+func TestReadCsvExportSkipsBlankRows(t *testing.T) {
+	data := "Title\n" + strings.Repeat(",\n", maxImportRows) + "Hades\n"
+	export, _, err := readCsvExport([]byte(data), "title")
+	if err != nil {
+		t.Fatalf("readCsvExport: %v", err)
+	}
+	if len(export.records) != 1 || export.lines[0] != maxImportRows+2 {
+		t.Fatalf("got records %v on lines %v, want Hades on line %d", export.records, export.lines,
+			maxImportRows+2)
+	}
+}
+
+func TestReadCsvExportLimitsRows(t *testing.T) {
+	data := "Title\n" + strings.Repeat("Hades\n", maxImportRows+1)
+	_, _, err := readCsvExport([]byte(data), "title")
+	if err == nil {
+		t.Fatalf("readCsvExport accepted %d rows", maxImportRows+1)
+	}
+}
+
+func FuzzReadCsvExport(f *testing.F) {
+	for _, seed := range importSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		export, title, err := readCsvExport(data, "title", "name")
+		if err != nil {
+			return
+		}
+		if len(export.records) != len(export.lines) || len(export.records) > maxImportRows {
+			t.Fatalf("%d records on %d lines", len(export.records), len(export.lines))
+		}
+		for i, record := range export.records {
+			if export.value(record, title) == "" {
+				t.Fatalf("record %d has no title: %q", i, record)
+			}
+			if i > 0 && export.lines[i] <= export.lines[i-1] {
+				t.Fatalf("lines are not increasing: %v", export.lines)
+			}
+		}
+	})
+}
+
+// Every format reads any upload without panicking, and the rows it returns
+// have a title, a line and one of our statuses
+func FuzzImportFormats(f *testing.F) {
+	for _, seed := range importSeeds {
+		for format := range importFormats {
+			f.Add(format, []byte(seed))
+		}
+	}
+	f.Fuzz(func(t *testing.T, format string, data []byte) {
+		parse, known := importFormats[format]
+		if !known {
+			return
+		}
+		rows, err := parse(data)
+		if err != nil {
+			return
+		}
+		for _, row := range rows {
+			if row.Title == "" || row.Line < 2 {
+				t.Fatalf("%s returned row %+v", format, row)
+			}
+			if !gameStatuses[row.Status] {
+				t.Fatalf("%s mapped line %d to status %q", format, row.Line, row.Status)
+			}
+		}
+	})
+}
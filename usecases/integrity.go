@@ -0,0 +1,125 @@
+package usecases
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Names of the integrity checks
+const (
+	IntegrityUsersWithoutPlayers     = "usersWithoutPlayers"
+	IntegrityLibrariesWithoutUsers   = "librariesWithoutUsers"
+	IntegrityEntriesWithoutLibraries = "entriesWithoutLibraries"
+	IntegrityEntriesWithoutGames     = "entriesWithoutGames"
+	IntegrityCopiesWithoutLibraries  = "copiesWithoutLibraries"
+	IntegrityMembersWithoutOwners    = "membersWithoutOwners"
+	IntegrityNotificationsOrphaned   = "notificationsWithoutUsers"
+	IntegritySettingsOrphaned        = "settingsWithoutUsers"
+	IntegrityDefaultLibraryMissing   = "defaultLibrariesMissing"
+	IntegrityLoginsWithoutUsers      = "loginsWithoutUsers"
+)
+
+// A relation the storage does not enforce and what repairing it does
+type IntegrityCheck struct {
+	Name        string
+	Description string
+	Repair      string
+}
+
+// In the order they run. Repairing removes rows later checks look at, so
+// libraries without users come before the entries of missing libraries.
+var IntegrityChecks = []IntegrityCheck{
+	{IntegrityUsersWithoutPlayers, "Users whose player does not exist",
+		"Gives them a player named after them"},
+	{IntegrityLibrariesWithoutUsers, "Libraries whose user does not exist", "Removes the libraries"},
+	{IntegrityEntriesWithoutLibraries, "Games in libraries that do not exist", "Removes the entries"},
+	{IntegrityEntriesWithoutGames, "Library entries of games that do not exist", "Removes the entries"},
+	{IntegrityCopiesWithoutLibraries, "Physical copies in libraries that do not exist",
+		"Removes the copies"},
+	{IntegrityMembersWithoutOwners, "Library members of libraries or users that do not exist",
+		"Removes the memberships"},
+	{IntegrityNotificationsOrphaned, "Notifications of users that do not exist",
+		"Removes the notifications"},
+	{IntegritySettingsOrphaned, "Settings of users that do not exist", "Removes the settings"},
+	{IntegrityDefaultLibraryMissing, "Settings whose default library does not exist",
+		"Clears the default library"},
+	{IntegrityLoginsWithoutUsers, "Logins of users that do not exist", "Removes the logins"},
+}
+
+// Ids of broken rows a finding lists, the count covers all of them
+const integritySample = 50
+
+// What one check found. Ids are those of the rows named by the check, such
+// as users or library entries, and the users of memberships and settings.
+type IntegrityFinding struct {
+	IntegrityCheck
+	Count    int
+	Ids      []int //At most integritySample
+	Repaired int   //Rows repaired, 0 unless repairing
+}
+
+type IntegrityRepository interface {
+	// The number of rows breaking the check and up to limit of their ids
+	FindBroken(check string, limit int) ([]int, int, error)
+	// Repairs every row breaking the check, returns how many
+	Repair(check string) (int, error)
+}
+
+// Finds and repairs what the missing foreign keys let through, for admins
+// over the API and for operators with cmd/integrity
+type IntegrityInteractor struct {
+	IntegrityRepository IntegrityRepository
+	Admin               AdminInteractor
+}
+
+// Runs every check, repairing each before the next one when repair is set.
+// Findings of a dry run may miss rows a repair would orphan.
+func (interactor *IntegrityInteractor) Check(repair bool) ([]IntegrityFinding, error) {
+	var findings []IntegrityFinding
+	for _, check := range IntegrityChecks {
+		ids, count, err := interactor.IntegrityRepository.FindBroken(check.Name, integritySample)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot check %s: %v", check.Name, err)
+		}
+		finding := IntegrityFinding{IntegrityCheck: check, Count: count, Ids: ids}
+		if repair && count > 0 {
+			finding.Repaired, err = interactor.IntegrityRepository.Repair(check.Name)
+			if err != nil {
+				return nil, fmt.Errorf("Cannot repair %s: %v", check.Name, err)
+			}
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+// Repairs are recorded in the audit log with the number of rows per check
+func (interactor *IntegrityInteractor) CheckIntegrity(adminId int, repair bool) ([]IntegrityFinding, error, int) {
+	err, code := interactor.Admin.requireAdmin(adminId)
+	if err != nil {
+		return nil, err, code
+	}
+	findings, err := interactor.Check(repair)
+	if err != nil {
+		return nil, err, 500
+	}
+	if !repair {
+		return findings, nil, 200
+	}
+	var repaired []string
+	for _, finding := range findings {
+		if finding.Repaired > 0 {
+			repaired = append(repaired, fmt.Sprintf("%s: %d", finding.Name, finding.Repaired))
+		}
+	}
+	if len(repaired) > 0 {
+		detail := strings.Join(repaired, ", ")
+		err = interactor.Admin.AdminRepository.Audit(AuditEntry{ActorId: adminId,
+			Action: AuditIntegrity, Detail: detail})
+		if err != nil {
+			return nil, err, 500
+		}
+		interactor.Admin.logf("Admin #%d repaired %s", adminId, detail)
+	}
+	return findings, nil, 200
+}
@@ -0,0 +1,19 @@
+package usecases
+
+import (
+	"time"
+)
+
+// Keeps background jobs from running on more than one instance. Claims
+// lapse after 90% of the interval, so instances whose tickers drift apart
+// still leave one run per interval.
+type JobLocks interface {
+	// False when another instance runs job now or started it within the
+	// interval. release is called once the run ended.
+	Claim(job string, interval time.Duration) (release func(), claimed bool, err error)
+}
+
+// How long a claim keeps others from running the job
+func ClaimPeriod(interval time.Duration) time.Duration {
+	return interval * 9 / 10
+}
@@ -0,0 +1,243 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const (
+	maxJournalTextLength  = 10000
+	maxScreenshotBytes    = 5 << 20
+	maxJournalEntriesPage = 100
+)
+
+var screenshotTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Keeps uploaded files out of the database, keys are chosen by the usecases
+type BlobStore interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, bool, error)
+	Delete(key string) error
+}
+
+type JournalRepository interface {
+	Store(entry JournalEntry) (int, error)
+	FindById(id int) (JournalEntry, error, int)
+	// Oldest first, gameId 0 for every game and a zero page for every entry
+	FindByUser(userId, gameId int, page Page) ([]JournalEntry, error)
+	StoreHtml(entry JournalEntry) error //Stores the rendered fields of the entry
+	Remove(entry JournalEntry) error
+	RemoveAll(userId int) ([]string, error) //Returns the screenshot keys of the removed entries
+}
+
+// A dated note about a game, with a screenshot or not
+type JournalEntry struct {
+	Id              int
+	UserId          int
+	GameId          int
+	GameExternalId  string
+	GameName        string
+	Text            string //Markdown
+	Html            string //Text rendered and sanitized
+	HasSpoilers     bool   //The text has spoiler blocks
+	SpoilerFreeHtml string //Html with the spoiler blocks left out
+	SpoilersHidden  bool   //Spoilers were taken out before the entry was handed back, never stored
+	Screenshot      string //Blob key, empty without a screenshot
+	ScreenshotType  string
+	CreatedAt       time.Time
+}
+
+type Screenshot struct {
+	ContentType string
+	Data        []byte
+}
+
+type JournalInteractor struct {
+	JournalRepository JournalRepository
+	UserRepository    UserRepository
+	LibraryRepository LibraryRepository
+	GameRepository    GameRepository
+	BlobStore         BlobStore
+	Renderer          MarkdownRenderer //Nil only escapes the text
+	logging
+}
+
+func (interactor *JournalInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		keys, err := interactor.JournalRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove journal of user #%d: %v", event.UserId, err)
+			return
+		}
+		for _, key := range keys {
+			err = interactor.BlobStore.Delete(key)
+			if err != nil {
+				interactor.logf("Cannot remove screenshot %s of user #%d: %v", key, event.UserId, err)
+			}
+		}
+	})
+}
+
+// An entry needs text, a screenshot or both
+func (interactor *JournalInteractor) AddEntry(userId, gameId int, text string, screenshot *Screenshot) (JournalEntry, error, int) {
+	text = strings.TrimSpace(text)
+	if len(text) > maxJournalTextLength {
+		return JournalEntry{}, domain.NewFieldError("text", "Must be at most %d characters",
+			maxJournalTextLength), 400
+	}
+	if text == "" && screenshot == nil {
+		return JournalEntry{}, domain.NewFieldError("text", "Write something or add a screenshot"), 400
+	}
+	if screenshot != nil {
+		if !screenshotTypes[screenshot.ContentType] {
+			return JournalEntry{}, domain.NewFieldError("screenshot",
+				"Must be a PNG, JPEG, GIF or WebP image"), 400
+		}
+		if len(screenshot.Data) > maxScreenshotBytes {
+			return JournalEntry{}, domain.NewFieldError("screenshot", "Must be at most %d bytes",
+				maxScreenshotBytes), 400
+		}
+	}
+	game, err, code := findOwnedGame(interactor.UserRepository, interactor.LibraryRepository,
+		interactor.GameRepository, userId, gameId)
+	if err != nil {
+		return JournalEntry{}, err, code
+	}
+
+	entry := JournalEntry{UserId: userId, GameId: gameId, GameExternalId: game.ExternalId,
+		GameName: game.Name, Text: text}
+	entry.Html, entry.SpoilerFreeHtml, entry.HasSpoilers, err = renderWithSpoilers(interactor.Renderer, text)
+	if err != nil {
+		return JournalEntry{}, err, 500
+	}
+	if screenshot != nil {
+		entry.Screenshot, err = newBlobKey("screenshots")
+		if err != nil {
+			return JournalEntry{}, err, 500
+		}
+		entry.ScreenshotType = screenshot.ContentType
+		err = interactor.BlobStore.Put(entry.Screenshot, screenshot.Data)
+		if err != nil {
+			return JournalEntry{}, err, 500
+		}
+	}
+	entry.Id, err = interactor.JournalRepository.Store(entry)
+	if err != nil {
+		if entry.Screenshot != "" {
+			interactor.BlobStore.Delete(entry.Screenshot)
+		}
+		return JournalEntry{}, err, 500
+	}
+	interactor.logf("User #%d wrote journal entry #%d about game #%d", userId, entry.Id, gameId)
+	return interactor.entry(userId, entry.Id)
+}
+
+// Keys are random so they cannot be guessed from the entry they belong to
+func newBlobKey(prefix string) (string, error) {
+	bytes := make([]byte, 16)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+	return prefix + "/" + hex.EncodeToString(bytes), nil
+}
+
+// Entries of other users look like they do not exist
+func (interactor *JournalInteractor) entry(userId, entryId int) (JournalEntry, error, int) {
+	entry, err, code := interactor.JournalRepository.FindById(entryId)
+	if code == 404 || (err == nil && entry.UserId != userId) {
+		return JournalEntry{}, domain.NewError(domain.CodeNotFound,
+			"User #%d has no journal entry #%d", userId, entryId), 404
+	}
+	if err != nil {
+		return JournalEntry{}, err, code
+	}
+	return entry, nil, 200
+}
+
+// Chronological, gameId 0 lists the entries about every game. Spoilers are
+// hidden unless showSpoilers is set. The cursor of the next page is zero on
+// the last page.
+func (interactor *JournalInteractor) ShowEntries(userId, gameId int, showSpoilers bool, page Page) ([]JournalEntry, Cursor, error, int) {
+	err := validPage(page, maxJournalEntriesPage)
+	if err != nil {
+		return nil, Cursor{}, err, 400
+	}
+	_, err = page.After.Time()
+	if err != nil {
+		return nil, Cursor{}, err, 400
+	}
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, Cursor{}, err, code
+	}
+	entries, err := interactor.JournalRepository.FindByUser(userId, gameId, page.probe())
+	if err != nil {
+		return nil, Cursor{}, err, 500
+	}
+	count, more := page.cut(len(entries))
+	entries = entries[:count]
+	var next Cursor
+	if more {
+		last := entries[count-1]
+		next = timeCursor(last.CreatedAt, last.Id)
+	}
+	err = renderJournal(interactor.Renderer, interactor.JournalRepository, entries)
+	if err != nil {
+		return nil, Cursor{}, err, 500
+	}
+	if !showSpoilers {
+		err = hideSpoilers(interactor.GameRepository, entries)
+		if err != nil {
+			return nil, Cursor{}, err, 500
+		}
+	}
+	return entries, next, nil, 200
+}
+
+func (interactor *JournalInteractor) ShowScreenshot(userId, entryId int) (Screenshot, error, int) {
+	entry, err, code := interactor.entry(userId, entryId)
+	if err != nil {
+		return Screenshot{}, err, code
+	}
+	notFound := domain.NewError(domain.CodeNotFound, "Journal entry #%d has no screenshot", entryId)
+	if entry.Screenshot == "" {
+		return Screenshot{}, notFound, 404
+	}
+	data, found, err := interactor.BlobStore.Get(entry.Screenshot)
+	if err != nil {
+		return Screenshot{}, err, 500
+	}
+	if !found {
+		return Screenshot{}, notFound, 404
+	}
+	return Screenshot{ContentType: entry.ScreenshotType, Data: data}, nil, 200
+}
+
+func (interactor *JournalInteractor) RemoveEntry(userId, entryId int) (error, int) {
+	entry, err, code := interactor.entry(userId, entryId)
+	if err != nil {
+		return err, code
+	}
+	err = interactor.JournalRepository.Remove(entry)
+	if err != nil {
+		return err, 500
+	}
+	if entry.Screenshot != "" {
+		err = interactor.BlobStore.Delete(entry.Screenshot)
+		if err != nil {
+			interactor.logf("Cannot remove screenshot %s of journal entry #%d: %v",
+				entry.Screenshot, entryId, err)
+		}
+	}
+	return nil, 200
+}
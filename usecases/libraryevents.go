@@ -0,0 +1,266 @@
+package usecases
+
+import (
+	"sort"
+	"time"
+
+	"game-tracker/domain"
+)
+
+// Kinds of library events
+const (
+	LibraryCreated  = "created"
+	LibraryRemoved  = "removed"
+	LibrarySeeded   = "seeded" //The entries a library had when events started being recorded
+	GamesAdded      = "gamesAdded"
+	GameRemoved     = "gameRemoved"
+	GamesChanged    = "gamesChanged"
+	WishlistRanked  = "wishlistRanked"
+	TagsReplaced    = "tagsReplaced"
+	EntriesRestored = "entriesRestored" //Undo and redo setting entries back
+)
+
+// One write to a library, as appended to its history. Only the fields of
+// the kind are set.
+type LibraryEvent struct {
+	LibraryId  int
+	Sequence   int64 //1 for the first event of the library
+	Kind       string
+	UserId     int            //LibraryCreated
+	Entries    []LibraryEntry //GamesAdded, LibrarySeeded, EntriesRestored
+	GameIds    []int          //GameRemoved, GamesChanged, EntriesRestored
+	Change     GameChange     //GamesChanged
+	Ranks      map[int]int    //WishlistRanked, keyed by game id
+	From       []string       //TagsReplaced
+	To         string         //TagsReplaced
+	RecordedAt time.Time
+	// External ids of the games the event names, by game id. Only set on
+	// events shown to users.
+	GameExternalIds map[int]string
+}
+
+// A game in a library with what belongs to the entry rather than the game
+type LibraryEntry struct {
+	GameId       int
+	Status       string
+	Platform     string
+	Tags         []string
+	WishlistRank int
+}
+
+// A library as its events leave it, snapshots store it so replays only fold
+// the events after them
+type LibraryState struct {
+	LibraryId int
+	UserId    int
+	Sequence  int64 //Of the last event applied
+	Removed   bool
+	Entries   map[int]LibraryEntry //By game id
+}
+
+// Applies the event the way the repositories write it
+func (state *LibraryState) Apply(event LibraryEvent) {
+	if state.Entries == nil {
+		state.Entries = make(map[int]LibraryEntry)
+	}
+	state.LibraryId = event.LibraryId
+	state.Sequence = event.Sequence
+	switch event.Kind {
+	case LibraryCreated:
+		state.UserId = event.UserId
+	case LibraryRemoved:
+		state.Removed = true
+	case LibrarySeeded, GamesAdded:
+		for _, entry := range event.Entries {
+			if _, found := state.Entries[entry.GameId]; found {
+				continue
+			}
+			if entry.Status == "" {
+				entry.Status = DefaultGameStatus
+			}
+			state.Entries[entry.GameId] = entry
+		}
+	case GameRemoved:
+		for _, gameId := range event.GameIds {
+			delete(state.Entries, gameId)
+		}
+	case GamesChanged:
+		for _, gameId := range event.GameIds {
+			entry, found := state.Entries[gameId]
+			if !found {
+				continue
+			}
+			if event.Change.Status != nil {
+				entry.Status = *event.Change.Status
+			}
+			if event.Change.Platform != nil {
+				entry.Platform = *event.Change.Platform
+			}
+			if event.Change.Tags != nil {
+				entry.Tags = append([]string(nil), *event.Change.Tags...)
+			}
+			state.Entries[gameId] = entry
+		}
+	case WishlistRanked:
+		for gameId, rank := range event.Ranks {
+			entry, found := state.Entries[gameId]
+			if found {
+				entry.WishlistRank = rank
+				state.Entries[gameId] = entry
+			}
+		}
+	case EntriesRestored:
+		for _, gameId := range event.GameIds {
+			delete(state.Entries, gameId)
+		}
+		for _, entry := range event.Entries {
+			state.Entries[entry.GameId] = entry
+		}
+	case TagsReplaced:
+		for gameId, entry := range state.Entries {
+			entry.Tags = replaceTags(entry.Tags, event.From, event.To)
+			state.Entries[gameId] = entry
+		}
+	}
+}
+
+func (state LibraryState) SortedEntries() []LibraryEntry {
+	var entries []LibraryEntry
+	for _, entry := range state.Entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].GameId < entries[j].GameId })
+	return entries
+}
+
+// Replaces the from tags by to in place, to is kept where it came first
+func replaceTags(tags []string, from []string, to string) []string {
+	replacing := make(map[string]bool)
+	for _, tag := range from {
+		replacing[tag] = true
+	}
+	var replaced []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		if replacing[tag] {
+			tag = to
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			replaced = append(replaced, tag)
+		}
+	}
+	return replaced
+}
+
+// The history of libraries kept when they are event sourced. The library
+// and game repositories append to it in the transaction of each write and
+// keep their tables as a projection of it.
+type LibraryEventStore interface {
+	FindEvents(libraryId int, after int64, limit int) ([]LibraryEvent, error) //By sequence
+	// Folds the events after the latest snapshot onto it
+	Load(libraryId int) (LibraryState, error)
+	// The state after the events recorded before at, false when the history
+	// of the library starts after at
+	LoadAt(libraryId int, at time.Time) (LibraryState, bool, error)
+	// Rewrites the entries of the library from its events, for tables that
+	// drifted or were restored from an older backup
+	Replay(libraryId int) (LibraryState, error, int)
+}
+
+// Events of a library shown at once at most
+const maxLibraryEventsPerPage = 500
+
+type LibraryEventsInteractor struct {
+	Events           LibraryEventStore //Nil unless libraries are event sourced
+	ChangeRepository ChangeRepository  //Tells the past of libraries without events
+	Profile          ProfileInteractor
+	Admin            AdminInteractor
+}
+
+func (interactor *LibraryEventsInteractor) enabled() (error, int) {
+	if interactor.Events == nil {
+		return domain.NewError(domain.CodeUnavailable, "Library events are not recorded"), 503
+	}
+	return nil, 200
+}
+
+// The events of the library, oldest first and a page at a time. Cursors
+// hold the sequence of the last event of their page, the cursor of the next
+// page is zero on the last page.
+func (interactor *LibraryEventsInteractor) ShowLibraryEvents(userId, libraryId int,
+	page Page) ([]LibraryEvent, Cursor, error, int) {
+	err, code := interactor.enabled()
+	if err != nil {
+		return nil, Cursor{}, err, code
+	}
+	err = validPage(page, maxLibraryEventsPerPage)
+	if err != nil {
+		return nil, Cursor{}, err, 400
+	}
+	if page.After.Key != "" {
+		return nil, Cursor{}, domain.NewFieldError("cursor", "Cursor is invalid"), 400
+	}
+	_, err, code = interactor.Profile.ShowLibrary(userId, libraryId)
+	if err != nil {
+		return nil, Cursor{}, err, code
+	}
+	events, err := interactor.Events.FindEvents(libraryId, int64(page.After.Id),
+		page.probe().Limit)
+	if err != nil {
+		return nil, Cursor{}, err, 500
+	}
+	count, more := page.cut(len(events))
+	events = events[:count]
+	var next Cursor
+	if more {
+		next = Cursor{Id: int(events[count-1].Sequence)}
+	}
+	location := userLocation(interactor.Profile.SettingsRepository, userId)
+	externalIds := make(map[int]string)
+	for i := range events {
+		events[i].RecordedAt = events[i].RecordedAt.In(location)
+		events[i].GameExternalIds = make(map[int]string)
+		for _, gameId := range events[i].gameIds() {
+			externalId, found := externalIds[gameId]
+			if !found {
+				// Games removed since are shown without their id
+				game, err, _ := interactor.Profile.GameRepository.FindById(gameId)
+				if err == nil {
+					externalId = game.ExternalId
+				}
+				externalIds[gameId] = externalId
+			}
+			events[i].GameExternalIds[gameId] = externalId
+		}
+	}
+	return events, next, nil, 200
+}
+
+// Ids of every game the event names
+func (event LibraryEvent) gameIds() []int {
+	gameIds := append([]int(nil), event.GameIds...)
+	for _, entry := range event.Entries {
+		gameIds = append(gameIds, entry.GameId)
+	}
+	for gameId := range event.Ranks {
+		gameIds = append(gameIds, gameId)
+	}
+	return gameIds
+}
+
+func (interactor *LibraryEventsInteractor) ReplayLibrary(adminId, libraryId int) (LibraryState, error, int) {
+	err, code := interactor.Admin.Authorize(adminId)
+	if err != nil {
+		return LibraryState{}, err, code
+	}
+	err, code = interactor.enabled()
+	if err != nil {
+		return LibraryState{}, err, code
+	}
+	state, err, code := interactor.Events.Replay(libraryId)
+	if err != nil {
+		return LibraryState{}, err, code
+	}
+	return state, nil, 200
+}
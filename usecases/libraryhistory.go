@@ -0,0 +1,167 @@
+package usecases
+
+import (
+	"sort"
+	"time"
+
+	"game-tracker/domain"
+)
+
+// A library as it was at the end of a day. The change log only records which
+// games were added and removed, entries rebuilt from it have no status,
+// platform or tags and Detailed is false. Libraries with events have them.
+type LibraryAsOf struct {
+	LibraryId int
+	At        time.Time //End of the day asked for, in the user's time zone
+	Detailed  bool
+	Entries   []LibraryEntry //By game id
+	// External ids and names of the entries' games, by game id. Games
+	// removed from the catalog since are left out.
+	Games map[int]Game
+}
+
+// An entry found on both days whose status, platform or tags changed
+type LibraryEntryChange struct {
+	Before LibraryEntry
+	After  LibraryEntry
+}
+
+// What happened to a library between the ends of two days
+type LibraryDiff struct {
+	From     LibraryAsOf
+	To       LibraryAsOf
+	Added    []LibraryEntry //By game id
+	Removed  []LibraryEntry //By game id
+	Changed  []LibraryEntryChange
+	Detailed bool //Whether both days know statuses, Changed is empty otherwise
+}
+
+// End of day in the user's time zone, of which only the date counts
+func endOfDay(day time.Time, location *time.Location) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, location)
+}
+
+// Folds the library's events when its history reaches back to at, otherwise
+// replays the change log
+func (interactor *LibraryEventsInteractor) libraryAt(library Library, at time.Time) (LibraryAsOf, error) {
+	asOf := LibraryAsOf{LibraryId: library.Id, At: at, Games: make(map[int]Game)}
+	if interactor.Events != nil {
+		state, found, err := interactor.Events.LoadAt(library.Id, at)
+		if err != nil {
+			return asOf, err
+		}
+		if found {
+			asOf.Detailed = true
+			asOf.Entries = state.SortedEntries()
+		}
+	}
+	if !asOf.Detailed {
+		changes, err := interactor.ChangeRepository.FindByParent(library.ExternalId, at)
+		if err != nil {
+			return asOf, err
+		}
+		// The last change of each game up to at tells whether it was in
+		added := make(map[string]bool)
+		for _, change := range changes {
+			if change.Entity == "game" {
+				added[change.EntityId] = change.Action != ChangeDeleted
+			}
+		}
+		for externalId, in := range added {
+			if !in {
+				continue
+			}
+			game, err, _ := interactor.Profile.GameRepository.FindByExternalId(externalId)
+			if err != nil {
+				continue
+			}
+			asOf.Entries = append(asOf.Entries, LibraryEntry{GameId: game.Id})
+			asOf.Games[game.Id] = game
+		}
+	}
+
+	sort.Slice(asOf.Entries, func(i, j int) bool {
+		return asOf.Entries[i].GameId < asOf.Entries[j].GameId
+	})
+	for _, entry := range asOf.Entries {
+		if _, found := asOf.Games[entry.GameId]; found {
+			continue
+		}
+		game, err, _ := interactor.Profile.GameRepository.FindById(entry.GameId)
+		if err == nil {
+			asOf.Games[entry.GameId] = game
+		}
+	}
+	return asOf, nil
+}
+
+// The library as it was at the end of day, for questions such as which games
+// were owned at the end of 2023
+func (interactor *LibraryEventsInteractor) ShowLibraryAt(userId, libraryId int, day time.Time) (LibraryAsOf, error, int) {
+	library, err, code := interactor.Profile.ShowLibrary(userId, libraryId)
+	if err != nil {
+		return LibraryAsOf{}, err, code
+	}
+	at := endOfDay(day, userLocation(interactor.Profile.SettingsRepository, userId))
+	asOf, err := interactor.libraryAt(library, at)
+	if err != nil {
+		return LibraryAsOf{}, err, 500
+	}
+	return asOf, nil, 200
+}
+
+// What changed in the library from the end of from to the end of to, such as
+// the games bought in 2023 when from is 2022-12-31 and to is 2023-12-31
+func (interactor *LibraryEventsInteractor) DiffLibrary(userId, libraryId int, from, to time.Time) (LibraryDiff, error, int) {
+	if !to.After(from) {
+		return LibraryDiff{}, domain.NewFieldError("to", "Must be after from"), 400
+	}
+	library, err, code := interactor.Profile.ShowLibrary(userId, libraryId)
+	if err != nil {
+		return LibraryDiff{}, err, code
+	}
+	location := userLocation(interactor.Profile.SettingsRepository, userId)
+	diff := LibraryDiff{}
+	diff.From, err = interactor.libraryAt(library, endOfDay(from, location))
+	if err == nil {
+		diff.To, err = interactor.libraryAt(library, endOfDay(to, location))
+	}
+	if err != nil {
+		return LibraryDiff{}, err, 500
+	}
+	diff.Detailed = diff.From.Detailed && diff.To.Detailed
+
+	before := make(map[int]LibraryEntry)
+	for _, entry := range diff.From.Entries {
+		before[entry.GameId] = entry
+	}
+	for _, entry := range diff.To.Entries {
+		previous, found := before[entry.GameId]
+		delete(before, entry.GameId)
+		switch {
+		case !found:
+			diff.Added = append(diff.Added, entry)
+		case diff.Detailed && entryChanged(previous, entry):
+			diff.Changed = append(diff.Changed, LibraryEntryChange{Before: previous, After: entry})
+		}
+	}
+	for _, entry := range diff.From.Entries {
+		if _, gone := before[entry.GameId]; gone {
+			diff.Removed = append(diff.Removed, entry)
+		}
+	}
+	return diff, nil, 200
+}
+
+func entryChanged(before, after LibraryEntry) bool {
+	if before.Status != after.Status || before.Platform != after.Platform ||
+		len(before.Tags) != len(after.Tags) {
+		return true
+	}
+	for i := range before.Tags {
+		if before.Tags[i] != after.Tags[i] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package usecases
+
+import (
+	"fmt"
+)
+
+// Repositories that log implement it so their lines can carry the caller's tag
+type loggingGameRepository interface {
+	WithLogger(logger LoggerRepository) GameRepository
+}
+
+func logf(logger LoggerRepository, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if logger == nil {
+		fmt.Println(message)
+		return
+	}
+	logger.Log(message)
+}
+
+// Embedded by the interactors, which log through Loggr. Outside of requests,
+// as in event handlers and jobs, Loggr is nil and lines are printed plain.
+type logging struct {
+	Loggr LoggerRepository
+}
+
+func (logging *logging) logf(format string, args ...interface{}) {
+	logf(logging.Loggr, format, args...)
+}
+
+// A copy of the interactor logging through logger, repositories that log
+// are switched over as well
+func (interactor ProfileInteractor) WithLogger(logger LoggerRepository) ProfileUsecase {
+	interactor = interactor.withLogger(logger)
+	return &interactor
+}
+
+// Interactors embedding the profile switch it over with this
+func (interactor ProfileInteractor) withLogger(logger LoggerRepository) ProfileInteractor {
+	interactor.Loggr = logger
+	if repo, ok := interactor.GameRepository.(loggingGameRepository); ok {
+		interactor.GameRepository = repo.WithLogger(logger)
+	}
+	return interactor
+}
+
+func (interactor AdminInteractor) WithLogger(logger LoggerRepository) AdminUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+// The interactors below are copied the same way, interactors they embed log
+// through logger too
+
+func (interactor ActivityInteractor) WithLogger(logger LoggerRepository) ActivityUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor AgentInteractor) WithLogger(logger LoggerRepository) AgentUsecase {
+	interactor.Loggr = logger
+	interactor.Calendar.Loggr = logger
+	return &interactor
+}
+
+func (interactor BadgeInteractor) WithLogger(logger LoggerRepository) BadgeUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor CalendarInteractor) WithLogger(logger LoggerRepository) CalendarUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor CatalogInteractor) WithLogger(logger LoggerRepository) CatalogUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor ExportInteractor) WithLogger(logger LoggerRepository) ExportUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor FranchiseInteractor) WithLogger(logger LoggerRepository) FranchiseUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor GoalInteractor) WithLogger(logger LoggerRepository) GoalUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor HardwareInteractor) WithLogger(logger LoggerRepository) HardwareUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor JournalInteractor) WithLogger(logger LoggerRepository) JournalUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor MatchInteractor) WithLogger(logger LoggerRepository) MatchUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor NotificationInteractor) WithLogger(logger LoggerRepository) NotificationUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor ParentalInteractor) WithLogger(logger LoggerRepository) ParentalUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor PersonalInteractor) WithLogger(logger LoggerRepository) PersonalUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor RuleInteractor) WithLogger(logger LoggerRepository) RuleUsecase {
+	interactor.Loggr = logger
+	interactor.Profile = interactor.Profile.withLogger(logger)
+	return &interactor
+}
+
+func (interactor ScriptInteractor) WithLogger(logger LoggerRepository) ScriptUsecase {
+	interactor.Loggr = logger
+	interactor.Admin.Loggr = logger
+	interactor.Rules.Loggr = logger
+	interactor.Rules.Profile = interactor.Rules.Profile.withLogger(logger)
+	return &interactor
+}
+
+func (interactor SearchInteractor) WithLogger(logger LoggerRepository) SearchUsecase {
+	interactor.Loggr = logger
+	interactor.Profile = interactor.Profile.withLogger(logger)
+	return &interactor
+}
+
+func (interactor SettingsInteractor) WithLogger(logger LoggerRepository) SettingsUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor SharingInteractor) WithLogger(logger LoggerRepository) SharingUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor SpeedrunInteractor) WithLogger(logger LoggerRepository) SpeedrunUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor SubscriptionInteractor) WithLogger(logger LoggerRepository) SubscriptionUsecase {
+	interactor.Loggr = logger
+	return &interactor
+}
+
+func (interactor WebhookInteractor) WithLogger(logger LoggerRepository) WebhookUsecase {
+	interactor.Loggr = logger
+	interactor.Profile = interactor.Profile.withLogger(logger)
+	return &interactor
+}
@@ -0,0 +1,341 @@
+package usecases
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"game-tracker/domain"
+)
+
+const (
+	MatchWin  = "win"
+	MatchLoss = "loss"
+	MatchDraw = "draw"
+
+	maxMatchBatch         = 500
+	maxMatchOpponents     = 20
+	maxOpponentNameLength = 100
+	maxMatchScoreLength   = 50
+	maxMatchesPerPage     = 100
+)
+
+var matchResults = map[string]bool{MatchWin: true, MatchLoss: true, MatchDraw: true}
+
+type MatchRepository interface {
+	StoreBatch(matches []Match) ([]int, error) //All or none, the ids in the order given
+	FindById(id int) (Match, error, int)
+	FindByUser(userId int, filter MatchFilter) ([]Match, error) //Newest first
+	Remove(match Match) error
+	RemoveAll(userId int) error
+}
+
+// Zero fields do not filter
+type MatchFilter struct {
+	GameId int
+	From   time.Time
+	To     time.Time
+	Page   Page //A zero page finds every match
+}
+
+// A multiplayer match the user played. Opponents are names as they appear
+// in the game, they need not be users.
+type Match struct {
+	Id             int
+	UserId         int
+	GameId         int
+	GameExternalId string
+	GameName       string
+	Result         string
+	Score          string //Free text such as "3-1"
+	Opponents      []string
+	PlayedAt       time.Time
+	CreatedAt      time.Time
+}
+
+// Outcome for one match of an ingested batch, Error is nil when it was stored
+type MatchItem struct {
+	Index int
+	Match Match
+	Error error
+	Code  int
+}
+
+type MatchRecord struct {
+	Matches int
+	Wins    int
+	Losses  int
+	Draws   int
+}
+
+func (record *MatchRecord) add(result string) {
+	record.Matches++
+	switch result {
+	case MatchWin:
+		record.Wins++
+	case MatchLoss:
+		record.Losses++
+	case MatchDraw:
+		record.Draws++
+	}
+}
+
+// Share of the matches won, draws count as not won
+func (record MatchRecord) WinRate() float64 {
+	if record.Matches == 0 {
+		return 0
+	}
+	return float64(record.Wins) / float64(record.Matches)
+}
+
+type GameMatchRecord struct {
+	MatchRecord
+	GameId         int
+	GameExternalId string
+	GameName       string
+}
+
+type MatchStats struct {
+	MatchRecord
+	Games []GameMatchRecord //Most played first
+}
+
+// The record against one opponent, named as in the latest match
+type HeadToHead struct {
+	MatchRecord
+	Opponent     string
+	LastPlayedAt time.Time
+}
+
+type MatchInteractor struct {
+	MatchRepository MatchRepository
+	UserRepository  UserRepository
+	GameRepository  GameRepository
+	logging
+}
+
+func (interactor *MatchInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.MatchRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove matches of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+func validMatch(match Match, now time.Time) (Match, error) {
+	match.Result = strings.ToLower(strings.TrimSpace(match.Result))
+	match.Score = strings.TrimSpace(match.Score)
+	if !matchResults[match.Result] {
+		return Match{}, domain.NewFieldError("result", "Must be %s, %s or %s", MatchWin, MatchLoss,
+			MatchDraw)
+	}
+	if utf8.RuneCountInString(match.Score) > maxMatchScoreLength {
+		return Match{}, domain.NewFieldError("score", "Must be at most %d characters", maxMatchScoreLength)
+	}
+	seen := make(map[string]bool)
+	var opponents []string
+	for _, opponent := range match.Opponents {
+		opponent = domain.NormalizeName(opponent)
+		if opponent == "" || seen[strings.ToLower(opponent)] {
+			continue
+		}
+		if utf8.RuneCountInString(opponent) > maxOpponentNameLength {
+			return Match{}, domain.NewFieldError("opponents", "Must be at most %d characters",
+				maxOpponentNameLength)
+		}
+		seen[strings.ToLower(opponent)] = true
+		opponents = append(opponents, opponent)
+	}
+	if len(opponents) > maxMatchOpponents {
+		return Match{}, domain.NewFieldError("opponents", "Must be at most %d names", maxMatchOpponents)
+	}
+	match.Opponents = opponents
+	if match.PlayedAt.IsZero() {
+		match.PlayedAt = now
+	}
+	if match.PlayedAt.After(now) {
+		return Match{}, domain.NewFieldError("playedAt", "Cannot be in the future")
+	}
+	match.PlayedAt = match.PlayedAt.UTC()
+	return match, nil
+}
+
+// Stores the valid matches of the batch together, the others are reported
+// per item
+func (interactor *MatchInteractor) IngestMatches(userId int, matches []Match) ([]MatchItem, error, int) {
+	if len(matches) == 0 || len(matches) > maxMatchBatch {
+		return nil, domain.NewFieldError("matches", "Between 1 and %d matches can be added at once",
+			maxMatchBatch), 400
+	}
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+
+	now := time.Now()
+	games := make(map[int]Game)
+	items := make([]MatchItem, len(matches))
+	var valid []Match
+	var stored []int
+	for i, match := range matches {
+		items[i] = MatchItem{Index: i, Code: 201}
+		match, err = validMatch(match, now)
+		if err != nil {
+			items[i].Error, items[i].Code = err, 400
+			continue
+		}
+		game, found := games[match.GameId]
+		if !found {
+			game, err, code = interactor.GameRepository.FindById(match.GameId)
+			if code == 404 {
+				items[i].Error, items[i].Code = err, 404
+				continue
+			}
+			if err != nil {
+				return nil, err, code
+			}
+			games[match.GameId] = game
+		}
+		match.UserId = userId
+		match.GameExternalId, match.GameName = game.ExternalId, game.Name
+		valid = append(valid, match)
+		stored = append(stored, i)
+	}
+	if len(valid) == 0 {
+		return items, nil, 200
+	}
+
+	ids, err := interactor.MatchRepository.StoreBatch(valid)
+	if err != nil {
+		return nil, err, 500
+	}
+	for j, i := range stored {
+		valid[j].Id = ids[j]
+		valid[j].CreatedAt = now.UTC()
+		items[i].Match = valid[j]
+	}
+	interactor.logf("User #%d added %d matches", userId, len(valid))
+	return items, nil, 200
+}
+
+// Newest first, the cursor of the next page is zero on the last page
+func (interactor *MatchInteractor) ShowMatches(userId int, filter MatchFilter) ([]Match, Cursor, error, int) {
+	err := validPage(filter.Page, maxMatchesPerPage)
+	if err != nil {
+		return nil, Cursor{}, err, 400
+	}
+	_, err = filter.Page.After.Time()
+	if err != nil {
+		return nil, Cursor{}, err, 400
+	}
+	page := filter.Page
+	filter.Page = page.probe()
+	matches, err, code := interactor.matches(userId, filter)
+	if err != nil {
+		return nil, Cursor{}, err, code
+	}
+	count, more := page.cut(len(matches))
+	matches = matches[:count]
+	var next Cursor
+	if more {
+		last := matches[count-1]
+		next = timeCursor(last.PlayedAt, last.Id)
+	}
+	return matches, next, nil, 200
+}
+
+// Wins, losses and draws overall and per game
+func (interactor *MatchInteractor) ShowMatchStats(userId int, filter MatchFilter) (MatchStats, error, int) {
+	filter.Page = Page{}
+	matches, err, code := interactor.matches(userId, filter)
+	if err != nil {
+		return MatchStats{}, err, code
+	}
+	stats := MatchStats{}
+	games := make(map[int]*GameMatchRecord)
+	for _, match := range matches {
+		stats.add(match.Result)
+		game := games[match.GameId]
+		if game == nil {
+			game = &GameMatchRecord{GameId: match.GameId, GameExternalId: match.GameExternalId,
+				GameName: match.GameName}
+			games[match.GameId] = game
+		}
+		game.add(match.Result)
+	}
+	for _, game := range games {
+		stats.Games = append(stats.Games, *game)
+	}
+	sort.Slice(stats.Games, func(i, j int) bool {
+		if stats.Games[i].Matches != stats.Games[j].Matches {
+			return stats.Games[i].Matches > stats.Games[j].Matches
+		}
+		return stats.Games[i].GameName < stats.Games[j].GameName
+	})
+	return stats, nil, 200
+}
+
+// The record against every opponent, the most played first. Opponents are
+// told apart regardless of case.
+func (interactor *MatchInteractor) ShowHeadToHead(userId int, filter MatchFilter) ([]HeadToHead, error, int) {
+	matches, err, code := interactor.matches(userId, filter)
+	if err != nil {
+		return nil, err, code
+	}
+	opponents := make(map[string]*HeadToHead)
+	for _, match := range matches {
+		for _, name := range match.Opponents {
+			record := opponents[strings.ToLower(name)]
+			if record == nil {
+				// Newest first, so the first spelling met is the latest one
+				record = &HeadToHead{Opponent: name, LastPlayedAt: match.PlayedAt}
+				opponents[strings.ToLower(name)] = record
+			}
+			record.add(match.Result)
+		}
+	}
+	records := []HeadToHead{}
+	for _, record := range opponents {
+		records = append(records, *record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Matches != records[j].Matches {
+			return records[i].Matches > records[j].Matches
+		}
+		return strings.ToLower(records[i].Opponent) < strings.ToLower(records[j].Opponent)
+	})
+	return records, nil, 200
+}
+
+func (interactor *MatchInteractor) RemoveMatch(userId, matchId int) (error, int) {
+	match, err, code := interactor.MatchRepository.FindById(matchId)
+	if code == 404 || (err == nil && match.UserId != userId) {
+		return domain.NewError(domain.CodeNotFound, "Match #%d does not exist", matchId), 404
+	}
+	if err != nil {
+		return err, code
+	}
+	err = interactor.MatchRepository.Remove(match)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("User #%d removed match #%d", userId, matchId)
+	return nil, 200
+}
+
+func (interactor *MatchInteractor) matches(userId int, filter MatchFilter) ([]Match, error, int) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, domain.NewFieldError("to", "Must be after from"), 400
+	}
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	matches, err := interactor.MatchRepository.FindByUser(userId, filter)
+	if err != nil {
+		return nil, err, 500
+	}
+	return matches, nil, 200
+}
@@ -0,0 +1,200 @@
+package usecases
+
+import (
+	"strings"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const (
+	LibraryRoleOwner  = "owner"
+	LibraryRoleEditor = "editor"
+	LibraryRoleViewer = "viewer"
+)
+
+const maxLibraryMembers = 20
+
+// Each role may do what the ones ranked below it may
+var libraryRoleRanks = map[string]int{
+	LibraryRoleViewer: 1,
+	LibraryRoleEditor: 2,
+	LibraryRoleOwner:  3,
+}
+
+type LibraryMemberRepository interface {
+	Store(member LibraryMember) error //Replaces the role of an existing member
+	FindByLib(libraryId int) ([]LibraryMember, error)
+	FindByUser(userId int) ([]LibraryMember, error)
+	// The role the user has on the library, the user who created it is an
+	// owner. Empty when the user is not a member.
+	FindRole(libraryId, userId int) (string, error)
+	Remove(libraryId, userId int) error
+	RemoveFromLib(libraryId int) error
+	RemoveFromUser(userId int) error
+}
+
+// A user a library is shared with, for households sharing one collection.
+// The repositories only set the ids of User, the rest is loaded on demand.
+type LibraryMember struct {
+	LibraryId         int
+	LibraryExternalId string
+	User              User
+	Role              string
+	CreatedAt         time.Time
+}
+
+// Whether the user has at least role on the library. The creator of a
+// library always owns it, everyone else needs a membership.
+func (interactor *ProfileInteractor) libraryAllows(userId int, library Library, role string) (bool, error) {
+	if userId == library.User.Id {
+		return true, nil
+	}
+	granted, err := interactor.LibraryMemberRepository.FindRole(library.Id, userId)
+	if err != nil {
+		return false, err
+	}
+	return libraryRoleRanks[granted] >= libraryRoleRanks[role], nil
+}
+
+// The creator comes first, as an owner
+func (interactor *ProfileInteractor) ShowMembers(userId, libraryId int) ([]LibraryMember, error, int) {
+	library, err, code := interactor.memberLibrary(userId, libraryId, LibraryRoleViewer)
+	if err != nil {
+		return nil, err, code
+	}
+	members, err := interactor.LibraryMemberRepository.FindByLib(libraryId)
+	if err != nil {
+		return nil, err, 500
+	}
+	members = append([]LibraryMember{{LibraryId: libraryId, User: library.User, Role: LibraryRoleOwner,
+		CreatedAt: library.CreatedAt}}, members...)
+	for i := range members {
+		members[i].LibraryExternalId = library.ExternalId
+		members[i].User, err, code = interactor.UserRepository.FindById(members[i].User.Id)
+		if err != nil {
+			return nil, err, code
+		}
+	}
+	return members, nil, 200
+}
+
+// Libraries of other users the user is a member of
+func (interactor *ProfileInteractor) ShowMemberships(userId int) ([]LibraryMember, error, int) {
+	user, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	memberships, err := interactor.LibraryMemberRepository.FindByUser(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	for i := range memberships {
+		library, err, code := interactor.LibraryRepository.FindById(memberships[i].LibraryId)
+		if err != nil {
+			return nil, err, code
+		}
+		memberships[i].LibraryExternalId = library.ExternalId
+		memberships[i].User = user
+	}
+	return memberships, nil, 200
+}
+
+// Adds the user named memberName to the library or changes their role, only
+// owners manage members. The creator keeps owning the library.
+func (interactor *ProfileInteractor) SetMember(userId, libraryId int, memberName, role string) (LibraryMember, error, int) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if libraryRoleRanks[role] == 0 {
+		return LibraryMember{}, domain.NewFieldError("role", "Must be %s, %s or %s",
+			LibraryRoleOwner, LibraryRoleEditor, LibraryRoleViewer), 400
+	}
+	library, err, code := interactor.memberLibrary(userId, libraryId, LibraryRoleOwner)
+	if err != nil {
+		return LibraryMember{}, err, code
+	}
+	member, err, code := interactor.UserRepository.FindByName(strings.TrimSpace(memberName), true)
+	if code == 404 {
+		return LibraryMember{}, domain.NewFieldError("userName", "User '%s' does not exist", memberName), 400
+	}
+	if err != nil {
+		return LibraryMember{}, err, code
+	}
+	if member.Id == library.User.Id {
+		return LibraryMember{}, domain.NewError(domain.CodeConflict,
+			"User #%d created library #%d and always owns it", member.Id, libraryId), 409
+	}
+	members, err := interactor.LibraryMemberRepository.FindByLib(libraryId)
+	if err != nil {
+		return LibraryMember{}, err, 500
+	}
+	known := false
+	for _, present := range members {
+		known = known || present.User.Id == member.Id
+	}
+	if !known && len(members) >= maxLibraryMembers {
+		return LibraryMember{}, domain.NewError(domain.CodeConflict,
+			"Library #%d already has %d members, remove one first", libraryId, maxLibraryMembers), 409
+	}
+
+	err = interactor.LibraryMemberRepository.Store(LibraryMember{LibraryId: libraryId, User: member, Role: role})
+	if err != nil {
+		return LibraryMember{}, err, 500
+	}
+	interactor.count("SetMember")
+	interactor.logf("User #%d made user #%d %s of library #%d", userId, member.Id, role, libraryId)
+	return LibraryMember{LibraryId: libraryId, LibraryExternalId: library.ExternalId, User: member,
+		Role: role, CreatedAt: time.Now()}, nil, 200
+}
+
+// Owners remove any member but the creator, everyone else may only leave
+func (interactor *ProfileInteractor) RemoveMember(userId, libraryId, memberId int) (error, int) {
+	library, err, code := interactor.LibraryRepository.FindById(libraryId)
+	if err != nil {
+		return err, code
+	}
+	if memberId == library.User.Id {
+		return domain.NewError(domain.CodeConflict,
+			"User #%d created library #%d and cannot be removed from it", memberId, libraryId), 409
+	}
+	if memberId != userId {
+		_, err, code = interactor.memberLibrary(userId, libraryId, LibraryRoleOwner)
+		if err != nil {
+			return err, code
+		}
+	}
+	role, err := interactor.LibraryMemberRepository.FindRole(libraryId, memberId)
+	if err != nil {
+		return err, 500
+	}
+	if role == "" {
+		return domain.NewError(domain.CodeNotFound, "User #%d is not a member of library #%d",
+			memberId, libraryId), 404
+	}
+	err = interactor.LibraryMemberRepository.Remove(libraryId, memberId)
+	if err != nil {
+		return err, 500
+	}
+	interactor.count("RemoveMember")
+	interactor.logf("User #%d removed user #%d from library #%d", userId, memberId, libraryId)
+	return nil, 200
+}
+
+func (interactor *ProfileInteractor) memberLibrary(userId, libraryId int, role string) (Library, error, int) {
+	library, err, code := interactor.LibraryRepository.FindById(libraryId)
+	if err != nil {
+		return Library{}, err, code
+	}
+	allowed, err := interactor.libraryAllows(userId, library, role)
+	if err != nil {
+		return Library{}, err, 500
+	}
+	if !allowed {
+		message := "User #%d is not allowed to manage the members of library #%d of user #%d"
+		if role != LibraryRoleOwner {
+			message = "User #%d is not allowed to see the members of library #%d of user #%d"
+		}
+		err := domain.NewError(domain.CodeForbidden, message, userId, library.Id, library.User.Id)
+		return Library{}, err, 403
+	}
+	return library, nil, 200
+}
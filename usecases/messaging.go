@@ -0,0 +1,103 @@
+package usecases
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"game-tracker/domain"
+)
+
+// Carries messages between processes, such as NATS or RabbitMQ
+type MessageBus interface {
+	Publish(subject string, payload []byte) error
+	// Calls handle with the messages of subject until the bus is closed,
+	// each message goes to one subscriber of queue. Returns once subscribed.
+	Subscribe(subject, queue string, handle func(payload []byte) error) error
+	Close() error
+}
+
+// Kinds of work handed to workers
+const (
+	WorkPhotoImports = "photoImports" //Reads the photos of pending imports
+)
+
+// Work done outside of requests, by whichever worker takes it first
+type Work struct {
+	Kind     string `json:"kind"`
+	UserId   int    `json:"userId"`
+	EntityId int    `json:"entityId"`
+}
+
+type WorkQueue interface {
+	Enqueue(work Work) error
+}
+
+// Domain events as other services read them
+type eventMessage struct {
+	Name     string            `json:"name"`
+	UserId   int               `json:"userId"`
+	EntityId int               `json:"entityId"`
+	Payload  map[string]string `json:"payload,omitempty"`
+}
+
+// Queue group every worker subscribes in, so each work is done once
+const workerQueue = "workers"
+
+// Hands work to worker processes over the bus and publishes domain events
+// on it. Subjects are Prefix plus "work.<kind>" or "events.<name>".
+type MessagingInteractor struct {
+	Bus     MessageBus
+	Prefix  string
+	Profile *ProfileInteractor
+	logging
+}
+
+func (interactor *MessagingInteractor) Enqueue(work Work) error {
+	payload, err := json.Marshal(work)
+	if err != nil {
+		return err
+	}
+	return interactor.Bus.Publish(interactor.Prefix+"work."+work.Kind, payload)
+}
+
+// Takes work off the bus until it is closed, for cmd/worker
+func (interactor *MessagingInteractor) Serve() error {
+	handlers := map[string]func(work Work) error{
+		WorkPhotoImports: func(work Work) error {
+			return interactor.Profile.ProcessPhotoImports()
+		},
+	}
+	for kind, handle := range handlers {
+		handle := handle
+		err := interactor.Bus.Subscribe(interactor.Prefix+"work."+kind, workerQueue,
+			func(payload []byte) error {
+				var work Work
+				err := json.Unmarshal(payload, &work)
+				if err != nil {
+					return fmt.Errorf("Cannot read work: %v", err)
+				}
+				return handle(work)
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Publishes every event of eventBus on the bus as well, failures are only
+// logged as the event already happened
+func (interactor *MessagingInteractor) ForwardEvents(eventBus domain.EventBus) {
+	for _, name := range domain.Events {
+		eventBus.Subscribe(name, func(event domain.Event) {
+			payload, err := json.Marshal(eventMessage{Name: event.Name, UserId: event.UserId,
+				EntityId: event.EntityId, Payload: event.Payload})
+			if err == nil {
+				err = interactor.Bus.Publish(interactor.Prefix+"events."+event.Name, payload)
+			}
+			if err != nil {
+				interactor.logf("Cannot forward event %s: %v", event.Name, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package usecases
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"game-tracker/domain"
+)
+
+const (
+	maxModsPerGame     = 500
+	maxModNameLength   = 200
+	maxModVersionLen   = 50
+	maxModSourceLength = 2000
+)
+
+type ModRepository interface {
+	Store(mod Mod) (int, error)
+	FindById(id int) (Mod, error, int)
+	FindByGame(libraryId, gameId int) ([]Mod, error) //By name
+	FindByLib(libraryId int) ([]Mod, error)          //By game and name
+	Update(mod Mod) error
+	Remove(mod Mod) error
+}
+
+// A mod installed on a game of a library, as PC players keep them
+type Mod struct {
+	Id             int
+	LibraryId      int
+	GameId         int
+	GameExternalId string
+	GameName       string
+	Name           string
+	SourceUrl      string //Where it was downloaded from, such as a Nexus Mods page
+	Version        string
+	Enabled        bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// What became of the mods of an imported export
+type ModImportReport struct {
+	Imported  []Mod
+	Skipped   int      //Already on the game
+	Unmatched []string //Games of the export missing from the library
+}
+
+func validMod(mod Mod) (Mod, error) {
+	mod.Name = strings.TrimSpace(mod.Name)
+	mod.SourceUrl = strings.TrimSpace(mod.SourceUrl)
+	mod.Version = strings.TrimSpace(mod.Version)
+	if mod.Name == "" || utf8.RuneCountInString(mod.Name) > maxModNameLength {
+		return Mod{}, domain.NewFieldError("name", "Must be between 1 and %d characters", maxModNameLength)
+	}
+	if utf8.RuneCountInString(mod.Version) > maxModVersionLen {
+		return Mod{}, domain.NewFieldError("version", "Must be at most %d characters", maxModVersionLen)
+	}
+	if mod.SourceUrl != "" {
+		source, err := url.Parse(mod.SourceUrl)
+		if err != nil || (source.Scheme != "http" && source.Scheme != "https") || source.Host == "" ||
+			len(mod.SourceUrl) > maxModSourceLength {
+			return Mod{}, domain.NewFieldError("sourceUrl", "Must be an http or https URL")
+		}
+	}
+	return mod, nil
+}
+
+// The game as its library entry, after checking the user may use the
+// library in the role
+func (interactor *ProfileInteractor) modGame(userId, libraryId, gameId int, role string) (Game, error, int) {
+	err, code := interactor.addonLibrary(userId, libraryId, role)
+	if err != nil {
+		return Game{}, err, code
+	}
+	return interactor.GameRepository.FindInLib(gameId, libraryId)
+}
+
+func (interactor *ProfileInteractor) AddMod(userId, libraryId, gameId int, mod Mod) (Mod, error, int) {
+	mod, err := validMod(mod)
+	if err != nil {
+		return Mod{}, err, 400
+	}
+	game, err, code := interactor.modGame(userId, libraryId, gameId, LibraryRoleEditor)
+	if err != nil {
+		return Mod{}, err, code
+	}
+	mods, err := interactor.ModRepository.FindByGame(libraryId, gameId)
+	if err != nil {
+		return Mod{}, err, 500
+	}
+	if len(mods) >= maxModsPerGame {
+		return Mod{}, domain.NewError(domain.CodeConflict,
+			"Game #%d already has %d mods, remove one first", gameId, maxModsPerGame), 409
+	}
+
+	mod.LibraryId, mod.GameId = libraryId, gameId
+	mod.GameExternalId, mod.GameName = game.ExternalId, game.Name
+	id, err := interactor.ModRepository.Store(mod)
+	if err != nil {
+		return Mod{}, err, 500
+	}
+	interactor.logf("User #%d added mod #%d to game #%d", userId, id, gameId)
+	mod, err, code = interactor.ModRepository.FindById(id)
+	if err != nil {
+		return Mod{}, err, code
+	}
+	return mod, nil, 201
+}
+
+func (interactor *ProfileInteractor) ShowMods(userId, libraryId, gameId int) ([]Mod, error, int) {
+	_, err, code := interactor.modGame(userId, libraryId, gameId, LibraryRoleViewer)
+	if err != nil {
+		return nil, err, code
+	}
+	mods, err := interactor.ModRepository.FindByGame(libraryId, gameId)
+	if err != nil {
+		return nil, err, 500
+	}
+	return mods, nil, 200
+}
+
+// Replaces every field of the mod
+func (interactor *ProfileInteractor) EditMod(userId, libraryId, gameId, modId int, changed Mod) (Mod, error, int) {
+	changed, err := validMod(changed)
+	if err != nil {
+		return Mod{}, err, 400
+	}
+	mod, err, code := interactor.findMod(userId, libraryId, gameId, modId)
+	if err != nil {
+		return Mod{}, err, code
+	}
+	mod.Name, mod.SourceUrl, mod.Version, mod.Enabled = changed.Name, changed.SourceUrl, changed.Version,
+		changed.Enabled
+	err = interactor.ModRepository.Update(mod)
+	if err != nil {
+		return Mod{}, err, 500
+	}
+	interactor.logf("User #%d edited mod #%d", userId, modId)
+	return interactor.ModRepository.FindById(modId)
+}
+
+func (interactor *ProfileInteractor) RemoveMod(userId, libraryId, gameId, modId int) (error, int) {
+	mod, err, code := interactor.findMod(userId, libraryId, gameId, modId)
+	if err != nil {
+		return err, code
+	}
+	err = interactor.ModRepository.Remove(mod)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("User #%d removed mod #%d", userId, modId)
+	return nil, 200
+}
+
+func (interactor *ProfileInteractor) findMod(userId, libraryId, gameId, modId int) (Mod, error, int) {
+	_, err, code := interactor.modGame(userId, libraryId, gameId, LibraryRoleEditor)
+	if err != nil {
+		return Mod{}, err, code
+	}
+	mod, err, code := interactor.ModRepository.FindById(modId)
+	if code == 404 || (err == nil && (mod.LibraryId != libraryId || mod.GameId != gameId)) {
+		return Mod{}, domain.NewError(domain.CodeNotFound, "Mod #%d does not exist", modId), 404
+	}
+	if err != nil {
+		return Mod{}, err, code
+	}
+	return mod, nil, 200
+}
+
+// The parts of a profile export that mods are read back from
+type modExport struct {
+	Libraries []struct {
+		Games []struct {
+			Id   string `json:"gameId"`
+			Name string `json:"name"`
+		} `json:"games"`
+		Mods []struct {
+			GameId    string `json:"gameId"`
+			Name      string `json:"name"`
+			SourceUrl string `json:"sourceUrl"`
+			Version   string `json:"version"`
+			Enabled   bool   `json:"enabled"`
+		} `json:"mods"`
+	} `json:"libraries"`
+}
+
+// Reads the mods of a JSON profile export into a library. Games are matched
+// by name, mods a game already has by that name are skipped so an export can
+// be imported again.
+func (interactor *ProfileInteractor) ImportMods(userId, libraryId int, data []byte) (ModImportReport, error, int) {
+	var export modExport
+	err := json.Unmarshal(data, &export)
+	if err != nil {
+		return ModImportReport{}, domain.NewFieldError("file", "Is not a JSON profile export"), 400
+	}
+	owned, err, code := interactor.importTarget(userId, libraryId)
+	if err != nil {
+		return ModImportReport{}, err, code
+	}
+
+	report := ModImportReport{}
+	existing := make(map[int]map[string]bool)
+	unmatched := make(map[string]bool)
+	for _, library := range export.Libraries {
+		names := make(map[string]string)
+		for _, game := range library.Games {
+			names[game.Id] = game.Name
+		}
+		for _, exported := range library.Mods {
+			name := names[exported.GameId]
+			game, found := owned[strings.ToLower(name)]
+			if !found {
+				if name != "" && !unmatched[strings.ToLower(name)] {
+					unmatched[strings.ToLower(name)] = true
+					report.Unmatched = append(report.Unmatched, name)
+				}
+				continue
+			}
+			if existing[game.Id] == nil {
+				mods, err := interactor.ModRepository.FindByGame(libraryId, game.Id)
+				if err != nil {
+					return ModImportReport{}, err, 500
+				}
+				existing[game.Id] = make(map[string]bool)
+				for _, mod := range mods {
+					existing[game.Id][strings.ToLower(mod.Name)] = true
+				}
+			}
+			if existing[game.Id][strings.ToLower(strings.TrimSpace(exported.Name))] {
+				report.Skipped++
+				continue
+			}
+			mod, err, code := interactor.AddMod(userId, libraryId, game.Id, Mod{Name: exported.Name,
+				SourceUrl: exported.SourceUrl, Version: exported.Version, Enabled: exported.Enabled})
+			if code == 400 || code == 409 {
+				report.Skipped++
+				continue
+			}
+			if err != nil {
+				return ModImportReport{}, err, code
+			}
+			existing[game.Id][strings.ToLower(mod.Name)] = true
+			report.Imported = append(report.Imported, mod)
+		}
+	}
+	interactor.logf("User #%d imported %d mods into library #%d", userId, len(report.Imported), libraryId)
+	return report, nil, 200
+}
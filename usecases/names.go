@@ -0,0 +1,133 @@
+package usecases
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"game-tracker/domain"
+)
+
+// Decides whether a username or player name may be used, a violation is
+// returned as an error whose message is shown to the client. Violations
+// made with domain.NewError keep their format as the translation key.
+type NamePolicy interface {
+	Check(name string) error
+}
+
+// Applies each policy in turn, the first violation wins
+type NamePolicies []NamePolicy
+
+func (policies NamePolicies) Check(name string) error {
+	for _, policy := range policies {
+		err := policy.Check(name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type LengthPolicy struct {
+	Min int
+	Max int //0 leaves the length unbounded
+}
+
+func (policy LengthPolicy) Check(name string) error {
+	length := utf8.RuneCountInString(name)
+	if length < policy.Min {
+		return domain.NewError(domain.CodeInvalid, "Name must be at least %d characters long", policy.Min)
+	}
+	if policy.Max > 0 && length > policy.Max {
+		return domain.NewError(domain.CodeInvalid, "Name must be at most %d characters long", policy.Max)
+	}
+	return nil
+}
+
+var nameCharset = regexp.MustCompile(`^[\p{L}\p{N}_.-]+$`)
+
+// Names end up in URLs such as /u/{username}, so only letters, digits and
+// the characters _ . - are allowed
+type CharsetPolicy struct{}
+
+func (policy CharsetPolicy) Check(name string) error {
+	if !nameCharset.MatchString(name) {
+		return domain.NewError(domain.CodeInvalid, "Name may only contain letters, digits, '_', '.' and '-'")
+	}
+	return nil
+}
+
+// Rejects names equal to a reserved word such as "admin", ignoring case
+type ReservedPolicy struct {
+	words map[string]bool
+}
+
+func NewReservedPolicy(words []string) ReservedPolicy {
+	policy := ReservedPolicy{words: map[string]bool{}}
+	for _, word := range words {
+		policy.words[domain.NameKey(word)] = true
+	}
+	return policy
+}
+
+func (policy ReservedPolicy) Check(name string) error {
+	if policy.words[domain.NameKey(name)] {
+		return domain.NewError(domain.CodeInvalid, "Name '%s' is reserved", name)
+	}
+	return nil
+}
+
+// Rejects names containing a listed word anywhere, ignoring case
+type ProfanityPolicy struct {
+	words []string
+}
+
+func NewProfanityPolicy(words []string) ProfanityPolicy {
+	policy := ProfanityPolicy{}
+	for _, word := range words {
+		policy.words = append(policy.words, domain.NameKey(word))
+	}
+	return policy
+}
+
+func (policy ProfanityPolicy) Check(name string) error {
+	key := domain.NameKey(name)
+	for _, word := range policy.words {
+		if strings.Contains(key, word) {
+			return domain.NewError(domain.CodeInvalid, "Name contains a word that is not allowed")
+		}
+	}
+	return nil
+}
+
+// Reports a violation as a field error, no policy allows every name
+func (interactor *ProfileInteractor) checkName(field, name string) error {
+	if interactor.NamePolicy == nil {
+		return nil
+	}
+	err := interactor.NamePolicy.Check(name)
+	if err == nil {
+		return nil
+	}
+	var violation *domain.Error
+	if errors.As(err, &violation) {
+		return domain.NewFieldError(field, violation.Format, violation.Args...)
+	}
+	return domain.NewFieldError(field, "%s", err.Error())
+}
+
+// Whether text can be stored as it is. Postgres refuses invalid UTF-8 and
+// NUL characters, other control characters only garble lists and exports.
+func printableText(text string) bool {
+	if !utf8.ValidString(text) {
+		return false
+	}
+	for _, char := range text {
+		if unicode.IsControl(char) && char != '\t' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+package usecases
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzPrintableText(f *testing.F) {
+	for _, seed := range []string{"", "Hades", "Pokémon\tRed", "a\x00b", "\xff\xfe", "line\nbreak",
+		"\u200bzero width", "\x7f"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		if !printableText(text) {
+			return
+		}
+		if !utf8.ValidString(text) || strings.ContainsAny(text, "\x00\n\r") {
+			t.Fatalf("printableText accepted %q", text)
+		}
+	})
+}
+
+// The name is an argument of the message, so a % in it is shown as it is
+func TestCheckNameKeepsPercentSigns(t *testing.T) {
+	interactor := ProfileInteractor{NamePolicy: NewReservedPolicy([]string{"100%off"})}
+	err := interactor.checkName("name", "100%off")
+	if err == nil || err.Error() != "Name '100%off' is reserved" {
+		t.Fatalf("checkName: %v, want the name reserved", err)
+	}
+}
@@ -0,0 +1,218 @@
+package usecases
+
+import (
+	"fmt"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const maxNotificationsPerPage = 100
+
+type NotificationRepository interface {
+	Store(notification Notification) (int, error)
+	FindByUser(userId int, page Page) ([]Notification, error) //Newest first
+	CountUnread(userId int) (int, error)
+	MarkRead(userId int, ids []int) error
+	MarkAllRead(userId int) error
+	RemoveAll(userId int) error
+}
+
+// Somewhere besides the inbox notifications are sent to, such as a chat
+// service. Channels come from plugins.
+type NotificationChannel interface {
+	Send(notification Notification) error
+}
+
+type Notification struct {
+	Id        int
+	UserId    int
+	Kind      string
+	Message   string
+	Read      bool
+	CreatedAt time.Time
+}
+
+type NotificationInteractor struct {
+	NotificationRepository NotificationRepository
+	UserRepository         UserRepository
+	SettingsRepository     SettingsRepository
+	Translator             Translator //Nil writes notifications in English
+	Channels               []NotificationChannel
+	logging
+}
+
+// Turns domain events into notifications in the owner's inbox
+func (interactor *NotificationInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventLibraryAdded, interactor.handleEvent)
+	bus.Subscribe(domain.EventLibraryRemoved, interactor.handleEvent)
+	bus.Subscribe(domain.EventGameAdded, interactor.handleEvent)
+	bus.Subscribe(domain.EventGameRemoved, interactor.handleEvent)
+	bus.Subscribe(domain.EventReleaseMoved, interactor.handleEvent)
+	bus.Subscribe(domain.EventReleaseLaunched, interactor.handleEvent)
+	bus.Subscribe(domain.EventBadgeEarned, interactor.handleEvent)
+	bus.Subscribe(domain.EventGoalBehind, interactor.handleEvent)
+	bus.Subscribe(domain.EventWarrantyExpiring, interactor.handleEvent)
+	bus.Subscribe(domain.EventSubscriptionRenewing, interactor.handleEvent)
+	bus.Subscribe(domain.EventCatalogLeaving, interactor.handleEvent)
+	bus.Subscribe(domain.EventBackupStale, interactor.handleEvent)
+	bus.Subscribe(domain.EventPersonalBest, interactor.handleEvent)
+	bus.Subscribe(domain.EventWebhookReceived, interactor.handleEvent)
+	bus.Subscribe(domain.EventRuleFired, interactor.handleEvent)
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		interactor.ClearNotifications(event.UserId)
+	})
+}
+
+// Reminders for goals the user is behind on, given the current count, the
+// target and the period
+var goalReminders = map[string]string{
+	GoalCompleteGames: "You completed %s of the %s games planned for %s",
+	GoalPlayHours:     "You played %s of the %s hours planned for %s",
+	GoalBacklogUnder:  "Your backlog holds %[1]s games, the goal for %[3]s keeps it under %[2]s",
+}
+
+func (interactor *NotificationInteractor) handleEvent(event domain.Event) {
+	var format string
+	var args []interface{}
+	switch event.Name {
+	case domain.EventLibraryAdded:
+		format, args = "Library #%d was created", []interface{}{event.EntityId}
+	case domain.EventLibraryRemoved:
+		format, args = "Library #%d was removed", []interface{}{event.EntityId}
+	case domain.EventGameAdded:
+		format = "Game '%s' was added to library #%s"
+		args = []interface{}{event.Payload["name"], event.Payload["libraryId"]}
+	case domain.EventGameRemoved:
+		format = "Game #%d was removed from library #%s"
+		args = []interface{}{event.EntityId, event.Payload["libraryId"]}
+	case domain.EventReleaseMoved:
+		format = "The release of '%s' moved from %s to %s"
+		args = []interface{}{event.Payload["name"], event.Payload["from"], event.Payload["to"]}
+	case domain.EventReleaseLaunched:
+		format, args = "'%s' is out now", []interface{}{event.Payload["name"]}
+	case domain.EventBadgeEarned:
+		format, args = "You earned the badge '%s'", []interface{}{event.Payload["name"]}
+	case domain.EventGoalBehind:
+		format = goalReminders[event.Payload["kind"]]
+		if format == "" {
+			return
+		}
+		args = []interface{}{event.Payload["current"], event.Payload["target"], event.Payload["period"]}
+	case domain.EventWarrantyExpiring:
+		format = "The warranty of your %s ends on %s"
+		args = []interface{}{event.Payload["name"], event.Payload["date"]}
+	case domain.EventSubscriptionRenewing:
+		format = "Your %s subscription renews on %s for %s"
+		args = []interface{}{event.Payload["service"], event.Payload["date"], event.Payload["cost"]}
+	case domain.EventCatalogLeaving:
+		format = "%s leaves %s on %s"
+		args = []interface{}{event.Payload["name"], event.Payload["service"], event.Payload["date"]}
+	case domain.EventBackupStale:
+		format = "The saves of '%s' were last backed up on %s"
+		args = []interface{}{event.Payload["name"], event.Payload["date"]}
+	case domain.EventPersonalBest:
+		format = "New personal best in %s (%s): %s, %s faster"
+		args = []interface{}{event.Payload["name"], event.Payload["category"], event.Payload["time"],
+			event.Payload["improvement"]}
+	case domain.EventWebhookReceived, domain.EventRuleFired:
+		format, args = "%s: %s", []interface{}{event.Payload["name"], event.Payload["message"]}
+	default:
+		return
+	}
+
+	settings, err := loadSettings(interactor.SettingsRepository, event.UserId)
+	if err != nil {
+		interactor.logf("Cannot load settings of user #%d: %v", event.UserId, err)
+		return
+	}
+	if !settings.NotifiesOn(event.Name) {
+		return
+	}
+
+	// Stored translated, changing the locale later leaves old notifications as they are
+	message := translate(interactor.Translator, settings, format, args...)
+	_, err, _ = interactor.AddNotification(event.UserId, event.Name, message)
+	if err != nil {
+		interactor.logf("Cannot store notification for user #%d: %v", event.UserId, err)
+	}
+}
+
+func (interactor *NotificationInteractor) AddNotification(userId int, kind, message string) (int, error, int) {
+	notification := Notification{UserId: userId, Kind: kind, Message: message, CreatedAt: time.Now().UTC()}
+	id, err := interactor.NotificationRepository.Store(notification)
+	if err != nil {
+		return 0, err, 500
+	}
+	// The inbox has it, a channel that is down only costs the copy it would send
+	notification.Id = id
+	for _, channel := range interactor.Channels {
+		err = channel.Send(notification)
+		if err != nil {
+			interactor.logf("Cannot send notification #%d to a channel: %v", id, err)
+		}
+	}
+	return id, nil, 201
+}
+
+// Newest first, the cursor of the next page is zero on the last page
+func (interactor *NotificationInteractor) ShowNotifications(userId int, page Page) ([]Notification, int, Cursor, error, int) {
+	err := validPage(page, maxNotificationsPerPage)
+	if err != nil {
+		return nil, 0, Cursor{}, err, 400
+	}
+	_, err = page.After.Time()
+	if err != nil {
+		return nil, 0, Cursor{}, err, 400
+	}
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		err = fmt.Errorf("User #%d does not exist", userId)
+		return nil, 0, Cursor{}, err, code
+	}
+
+	notifications, err := interactor.NotificationRepository.FindByUser(userId, page.probe())
+	if err != nil {
+		return nil, 0, Cursor{}, err, 500
+	}
+	count, more := page.cut(len(notifications))
+	notifications = notifications[:count]
+	var next Cursor
+	if more {
+		last := notifications[count-1]
+		next = timeCursor(last.CreatedAt, last.Id)
+	}
+	unread, err := interactor.NotificationRepository.CountUnread(userId)
+	if err != nil {
+		return nil, 0, Cursor{}, err, 500
+	}
+	location := userLocation(interactor.SettingsRepository, userId)
+	for i := range notifications {
+		notifications[i].CreatedAt = notifications[i].CreatedAt.In(location)
+	}
+	return notifications, unread, next, nil, 200
+}
+
+// Marks the given notifications as read, or every notification when ids is empty
+func (interactor *NotificationInteractor) MarkNotificationsRead(userId int, ids []int) (error, int) {
+	var err error
+	if len(ids) == 0 {
+		err = interactor.NotificationRepository.MarkAllRead(userId)
+	} else {
+		err = interactor.NotificationRepository.MarkRead(userId, ids)
+	}
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("Marked notifications of user #%d as read", userId)
+	return nil, 200
+}
+
+func (interactor *NotificationInteractor) ClearNotifications(userId int) (error, int) {
+	err := interactor.NotificationRepository.RemoveAll(userId)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("Cleared notifications of user #%d", userId)
+	return nil, 200
+}
@@ -0,0 +1,95 @@
+package usecases
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"game-tracker/domain"
+)
+
+// A position in a list ordered by a sort key and then by id. A page starts
+// after the cursor of the last row of the page before, so rows added or
+// removed meanwhile do not shift it as they would an offset. The zero cursor
+// starts at the top of the list.
+type Cursor struct {
+	Key string //Sort key of the row, empty for lists ordered by id alone
+	Id  int
+}
+
+// Limit rows after a cursor
+type Page struct {
+	After Cursor
+	Limit int
+}
+
+func (cursor Cursor) IsZero() bool {
+	return cursor == Cursor{}
+}
+
+// Clients get cursors as opaque tokens and hand them back unchanged
+func (cursor Cursor) String() string {
+	if cursor.IsZero() {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(cursor.Id) + ":" + cursor.Key))
+}
+
+// Reads a token made by String, the empty token is the zero cursor
+func ParseCursor(token string) (Cursor, error) {
+	invalid := domain.NewFieldError("cursor", "Cursor is invalid")
+	if token == "" {
+		return Cursor{}, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, invalid
+	}
+	id, key, found := strings.Cut(string(decoded), ":")
+	cursor := Cursor{Key: key}
+	cursor.Id, err = strconv.Atoi(id)
+	if !found || err != nil || cursor.Id < 1 {
+		return Cursor{}, invalid
+	}
+	return cursor, nil
+}
+
+func timeCursor(at time.Time, id int) Cursor {
+	return Cursor{Key: at.UTC().Format(time.RFC3339Nano), Id: id}
+}
+
+// The sort key of a cursor made by timeCursor, zero for the zero cursor
+func (cursor Cursor) Time() (time.Time, error) {
+	if cursor.IsZero() {
+		return time.Time{}, nil
+	}
+	at, err := time.Parse(time.RFC3339Nano, cursor.Key)
+	if err != nil {
+		return time.Time{}, domain.NewFieldError("cursor", "Cursor is invalid")
+	}
+	return at, nil
+}
+
+func validPage(page Page, maxLimit int) error {
+	if page.Limit < 1 || page.Limit > maxLimit {
+		return domain.NewFieldError("limit", "Must be between 1 and %d", maxLimit)
+	}
+	return nil
+}
+
+// The page repositories are asked for, one row longer so that a full page
+// tells whether another follows it
+func (page Page) probe() Page {
+	page.Limit++
+	return page
+}
+
+// How many of the rows fetched with probe belong on the page and whether
+// more follow
+func (page Page) cut(fetched int) (int, bool) {
+	if fetched > page.Limit {
+		return page.Limit, true
+	}
+	return fetched, false
+}
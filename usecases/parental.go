@@ -0,0 +1,349 @@
+package usecases
+
+import (
+	"sort"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const (
+	maxRatingCap = 18
+	// Reports cover at most this many days
+	maxReportDays = 93
+)
+
+type ChildAccountRepository interface {
+	Store(account ChildAccount) error //Replaces the limits of a child already linked
+	FindByChild(childId int) (ChildAccount, bool, error)
+	FindByParent(parentId int) ([]ChildAccount, error)
+	Remove(childId int) error
+	RemoveAll(userId int) error //Links the user is the parent or the child of
+}
+
+// A child account is linked to one parent, who sets its limits. Zero
+// limits are not enforced.
+type ChildAccount struct {
+	ParentId     int
+	ChildId      int
+	Child        User //Filled in by the interactor
+	DailyMinutes int  //Playtime allowed per day in the child's timezone
+	RatingCap    int  //Highest MinAge of the games the child may add
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type DayPlaytime struct {
+	Day       time.Time //Midnight in the child's timezone
+	Minutes   int
+	OverLimit bool
+}
+
+type GamePlaytime struct {
+	GameId         int
+	GameExternalId string
+	GameName       string
+	Minutes        int
+}
+
+// What a child played over a period, sessions count on the day they start
+type ChildReport struct {
+	Account      ChildAccount
+	From         time.Time
+	To           time.Time
+	TotalMinutes int
+	Days         []DayPlaytime
+	Games        []GamePlaytime //Most played first
+}
+
+// Enforces the limits of child accounts in the usecases that add games and
+// sessions. A nil service enforces nothing.
+type ParentalControls struct {
+	ChildAccountRepository ChildAccountRepository
+	PlaySessionRepository  PlaySessionRepository
+	SettingsRepository     SettingsRepository
+}
+
+func NewParentalControls(children ChildAccountRepository, sessions PlaySessionRepository,
+	settings SettingsRepository) *ParentalControls {
+	return &ParentalControls{ChildAccountRepository: children, PlaySessionRepository: sessions,
+		SettingsRepository: settings}
+}
+
+func (controls *ParentalControls) limits(userId int) (ChildAccount, bool, error) {
+	if controls == nil {
+		return ChildAccount{}, false, nil
+	}
+	return controls.ChildAccountRepository.FindByChild(userId)
+}
+
+// The strictest of the rating limit in the user's settings, the household
+// limit in the parent's settings and the cap the parent set on the child
+// account. 0 when nothing limits the user.
+func (controls *ParentalControls) RatingLimit(userId int) (int, error) {
+	if controls == nil {
+		return 0, nil
+	}
+	settings, err := loadSettings(controls.SettingsRepository, userId)
+	if err != nil {
+		return 0, err
+	}
+	limit := settings.RatingLimit
+	account, found, err := controls.limits(userId)
+	if err != nil || !found {
+		return limit, err
+	}
+	parentSettings, err := loadSettings(controls.SettingsRepository, account.ParentId)
+	if err != nil {
+		return 0, err
+	}
+	for _, other := range []int{parentSettings.RatingLimit, account.RatingCap} {
+		if other > 0 && (limit == 0 || other < limit) {
+			limit = other
+		}
+	}
+	return limit, nil
+}
+
+func (controls *ParentalControls) CheckGame(userId int, game Game) (error, int) {
+	limit, err := controls.RatingLimit(userId)
+	if err != nil {
+		return err, 500
+	}
+	return checkRating(game, limit)
+}
+
+// A limit of 0 allows every game, batches look the limit up once
+func checkRating(game Game, limit int) (error, int) {
+	if limit == 0 || game.MinAge <= limit {
+		return nil, 200
+	}
+	return domain.NewError(domain.CodeForbidden, "'%s' is rated %d+, above the limit of %d+ for this household",
+		game.Name, game.MinAge, limit), 403
+}
+
+// The session counts towards the day it starts on
+func (controls *ParentalControls) CheckPlaytime(userId int, startsAt time.Time, minutes int) (error, int) {
+	account, found, err := controls.limits(userId)
+	if err != nil {
+		return err, 500
+	}
+	if !found || account.DailyMinutes == 0 {
+		return nil, 200
+	}
+	start := startsAt.In(userLocation(controls.SettingsRepository, userId))
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	sessions, err := controls.PlaySessionRepository.FindByUser(userId, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return err, 500
+	}
+	played := 0
+	for _, session := range sessions {
+		played += session.Minutes
+	}
+	if played+minutes > account.DailyMinutes {
+		left := account.DailyMinutes - played
+		if left < 0 {
+			left = 0
+		}
+		return domain.NewError(domain.CodeForbidden,
+			"A parent allows %d minutes of play a day, %d are left on %s",
+			account.DailyMinutes, left, day.Format("2006-01-02")), 403
+	}
+	return nil, 200
+}
+
+type ParentalInteractor struct {
+	ChildAccountRepository ChildAccountRepository
+	UserRepository         UserRepository
+	PlaySessionRepository  PlaySessionRepository
+	SettingsRepository     SettingsRepository
+	logging
+}
+
+func (interactor *ParentalInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.ChildAccountRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove account links of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+// Accounts only link one level deep, a child cannot be a parent itself
+func (interactor *ParentalInteractor) LinkChild(parentId, childId int) (ChildAccount, error, int) {
+	if parentId == childId {
+		return ChildAccount{}, domain.NewError(domain.CodeInvalid, "An account cannot be its own child"), 400
+	}
+	_, err, code := interactor.UserRepository.FindById(parentId)
+	if err != nil {
+		return ChildAccount{}, err, code
+	}
+	_, isChild, err := interactor.ChildAccountRepository.FindByChild(parentId)
+	if err != nil {
+		return ChildAccount{}, err, 500
+	}
+	if isChild {
+		return ChildAccount{}, domain.NewError(domain.CodeForbidden,
+			"User #%d is a child account and cannot link children", parentId), 403
+	}
+	children, err := interactor.ChildAccountRepository.FindByParent(childId)
+	if err != nil {
+		return ChildAccount{}, err, 500
+	}
+	if len(children) > 0 {
+		return ChildAccount{}, domain.NewError(domain.CodeConflict,
+			"User #%d is a parent and cannot become a child account", childId), 409
+	}
+	account, linked, err := interactor.ChildAccountRepository.FindByChild(childId)
+	if err != nil {
+		return ChildAccount{}, err, 500
+	}
+	if linked {
+		if account.ParentId == parentId {
+			return interactor.withChild(account)
+		}
+		return ChildAccount{}, domain.NewError(domain.CodeConflict,
+			"User #%d is already linked to another parent", childId), 409
+	}
+
+	err = interactor.ChildAccountRepository.Store(ChildAccount{ParentId: parentId, ChildId: childId})
+	if err != nil {
+		return ChildAccount{}, err, 500
+	}
+	interactor.logf("User #%d linked child account #%d", parentId, childId)
+	account, err, code = interactor.child(parentId, childId)
+	if err != nil {
+		return ChildAccount{}, err, code
+	}
+	return account, nil, 201
+}
+
+func (interactor *ParentalInteractor) withChild(account ChildAccount) (ChildAccount, error, int) {
+	child, err, code := interactor.UserRepository.FindById(account.ChildId)
+	if err != nil {
+		return ChildAccount{}, err, code
+	}
+	account.Child = child
+	return account, nil, 200
+}
+
+// Loads a child of the parent, other accounts look like they do not exist
+func (interactor *ParentalInteractor) child(parentId, childId int) (ChildAccount, error, int) {
+	account, found, err := interactor.ChildAccountRepository.FindByChild(childId)
+	if err != nil {
+		return ChildAccount{}, err, 500
+	}
+	if !found || account.ParentId != parentId {
+		return ChildAccount{}, domain.NewError(domain.CodeNotFound,
+			"User #%d has no child account #%d", parentId, childId), 404
+	}
+	return interactor.withChild(account)
+}
+
+func (interactor *ParentalInteractor) ShowChildren(parentId int) ([]ChildAccount, error, int) {
+	_, err, code := interactor.UserRepository.FindById(parentId)
+	if err != nil {
+		return nil, err, code
+	}
+	accounts, err := interactor.ChildAccountRepository.FindByParent(parentId)
+	if err != nil {
+		return nil, err, 500
+	}
+	var children []ChildAccount
+	for _, account := range accounts {
+		account, err, code = interactor.withChild(account)
+		if err != nil {
+			return nil, err, code
+		}
+		children = append(children, account)
+	}
+	return children, nil, 200
+}
+
+func (interactor *ParentalInteractor) SetLimits(parentId, childId, dailyMinutes, ratingCap int) (ChildAccount, error, int) {
+	if dailyMinutes < 0 || dailyMinutes > maxSessionMinutes {
+		return ChildAccount{}, domain.NewFieldError("dailyMinutes", "Must be between 0 and %d",
+			maxSessionMinutes), 400
+	}
+	if ratingCap < 0 || ratingCap > maxRatingCap {
+		return ChildAccount{}, domain.NewFieldError("ratingCap", "Must be between 0 and %d",
+			maxRatingCap), 400
+	}
+	account, err, code := interactor.child(parentId, childId)
+	if err != nil {
+		return ChildAccount{}, err, code
+	}
+	account.DailyMinutes, account.RatingCap = dailyMinutes, ratingCap
+	err = interactor.ChildAccountRepository.Store(account)
+	if err != nil {
+		return ChildAccount{}, err, 500
+	}
+	interactor.logf("User #%d set limits of child account #%d: %d minutes a day, rated %d+ at most",
+		parentId, childId, dailyMinutes, ratingCap)
+	return interactor.child(parentId, childId)
+}
+
+func (interactor *ParentalInteractor) UnlinkChild(parentId, childId int) (error, int) {
+	_, err, code := interactor.child(parentId, childId)
+	if err != nil {
+		return err, code
+	}
+	err = interactor.ChildAccountRepository.Remove(childId)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("User #%d unlinked child account #%d", parentId, childId)
+	return nil, 200
+}
+
+func (interactor *ParentalInteractor) ShowReport(parentId, childId int, from, to time.Time) (ChildReport, error, int) {
+	if !to.After(from) {
+		return ChildReport{}, domain.NewFieldError("to", "Must be after from"), 400
+	}
+	if to.Sub(from) > maxReportDays*24*time.Hour {
+		return ChildReport{}, domain.NewFieldError("to", "Reports cover at most %d days",
+			maxReportDays), 400
+	}
+	account, err, code := interactor.child(parentId, childId)
+	if err != nil {
+		return ChildReport{}, err, code
+	}
+	sessions, err := interactor.PlaySessionRepository.FindByUser(childId, from, to)
+	if err != nil {
+		return ChildReport{}, err, 500
+	}
+
+	report := ChildReport{Account: account, From: from, To: to}
+	location := userLocation(interactor.SettingsRepository, childId)
+	days := make(map[time.Time]int)
+	games := make(map[int]*GamePlaytime)
+	for _, session := range sessions {
+		start := session.StartsAt.In(location)
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, location)
+		days[day] += session.Minutes
+		game, ok := games[session.GameId]
+		if !ok {
+			game = &GamePlaytime{GameId: session.GameId, GameExternalId: session.GameExternalId,
+				GameName: session.GameName}
+			games[session.GameId] = game
+		}
+		game.Minutes += session.Minutes
+		report.TotalMinutes += session.Minutes
+	}
+	for day, minutes := range days {
+		report.Days = append(report.Days, DayPlaytime{Day: day, Minutes: minutes,
+			OverLimit: account.DailyMinutes > 0 && minutes > account.DailyMinutes})
+	}
+	sort.Slice(report.Days, func(i, j int) bool { return report.Days[i].Day.Before(report.Days[j].Day) })
+	for _, game := range games {
+		report.Games = append(report.Games, *game)
+	}
+	sort.Slice(report.Games, func(i, j int) bool {
+		if report.Games[i].Minutes != report.Games[j].Minutes {
+			return report.Games[i].Minutes > report.Games[j].Minutes
+		}
+		return report.Games[i].GameName < report.Games[j].GameName
+	})
+	return report, nil, 200
+}
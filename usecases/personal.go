@@ -0,0 +1,269 @@
+package usecases
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const (
+	maxPersonalScore = 5
+	maxMoods         = 5
+)
+
+// Moods a user can tag their games with, a fixed list keeps the tonight
+// filter from splitting over spelling
+var moods = map[string]bool{
+	"relaxing":    true,
+	"intense":     true,
+	"cozy":        true,
+	"competitive": true,
+	"story":       true,
+	"social":      true,
+	"creative":    true,
+	"spooky":      true,
+	"quick":       true,
+	"thoughtful":  true,
+}
+
+// Statuses a game can be picked tonight from, the most active first
+var tonightStatuses = []string{"playing", "backlog", "owned"}
+
+type PersonalMetadataRepository interface {
+	Store(metadata PersonalMetadata) error //Replaces what the user set on the game before
+	Find(userId, gameId int) (PersonalMetadata, bool, error)
+	Remove(userId, gameId int) (bool, error)
+	RemoveAll(userId int) error
+	FindCandidates(userId int, statuses []string) ([]Candidate, error) //One per library entry
+	FindGameStats(userId int, gameIds []int) ([]GameStats, error)      //Leaves out what the user set
+}
+
+// How a user feels about a game, kept apart from the game itself which all
+// users share. Zero scores are unset.
+type PersonalMetadata struct {
+	UserId        int
+	GameId        int
+	Difficulty    int //1 to 5
+	Replayability int //1 to 5
+	Moods         []string
+	UpdatedAt     time.Time
+}
+
+// A game of one of the user's libraries with what the user set on it, the
+// status of the game is the one of its library entry
+type Candidate struct {
+	Game     Game
+	Metadata PersonalMetadata
+	AddedAt  time.Time //When the game was added to the library
+}
+
+// Narrows down the games to play tonight, zero fields match every game. A
+// game must carry all the moods asked for.
+type TonightFilter struct {
+	Moods            []string
+	MaxDifficulty    int
+	MinReplayability int
+	Statuses         []string //Defaults to the playing, backlog and owned games
+}
+
+type PersonalInteractor struct {
+	PersonalMetadataRepository PersonalMetadataRepository
+	UserRepository             UserRepository
+	LibraryRepository          LibraryRepository
+	GameRepository             GameRepository
+	Parental                   *ParentalControls
+	logging
+}
+
+func (interactor *PersonalInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.PersonalMetadataRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove personal metadata of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+func normalizeMoods(field string, values []string) ([]string, error) {
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, mood := range values {
+		mood = strings.ToLower(strings.TrimSpace(mood))
+		if !moods[mood] {
+			return nil, domain.NewFieldError(field, "Mood '%s' is unknown", mood)
+		}
+		if !seen[mood] {
+			seen[mood] = true
+			normalized = append(normalized, mood)
+		}
+	}
+	return normalized, nil
+}
+
+func (interactor *PersonalInteractor) SetMetadata(metadata PersonalMetadata) (PersonalMetadata, error, int) {
+	if metadata.Difficulty < 0 || metadata.Difficulty > maxPersonalScore {
+		return PersonalMetadata{}, domain.NewFieldError("difficulty", "Must be between 0 and %d",
+			maxPersonalScore), 400
+	}
+	if metadata.Replayability < 0 || metadata.Replayability > maxPersonalScore {
+		return PersonalMetadata{}, domain.NewFieldError("replayability", "Must be between 0 and %d",
+			maxPersonalScore), 400
+	}
+	tags, err := normalizeMoods("moods", metadata.Moods)
+	if err != nil {
+		return PersonalMetadata{}, err, 400
+	}
+	if len(tags) > maxMoods {
+		return PersonalMetadata{}, domain.NewFieldError("moods", "At most %d moods are allowed",
+			maxMoods), 400
+	}
+	metadata.Moods = tags
+	_, err, code := findOwnedGame(interactor.UserRepository, interactor.LibraryRepository,
+		interactor.GameRepository, metadata.UserId, metadata.GameId)
+	if err != nil {
+		return PersonalMetadata{}, err, code
+	}
+	err = interactor.PersonalMetadataRepository.Store(metadata)
+	if err != nil {
+		return PersonalMetadata{}, err, 500
+	}
+	return interactor.ShowMetadata(metadata.UserId, metadata.GameId)
+}
+
+// Games the user set nothing on have zero metadata
+func (interactor *PersonalInteractor) ShowMetadata(userId, gameId int) (PersonalMetadata, error, int) {
+	_, err, code := findOwnedGame(interactor.UserRepository, interactor.LibraryRepository,
+		interactor.GameRepository, userId, gameId)
+	if err != nil {
+		return PersonalMetadata{}, err, code
+	}
+	metadata, found, err := interactor.PersonalMetadataRepository.Find(userId, gameId)
+	if err != nil {
+		return PersonalMetadata{}, err, 500
+	}
+	if !found {
+		return PersonalMetadata{UserId: userId, GameId: gameId, Moods: []string{}}, nil, 200
+	}
+	return metadata, nil, 200
+}
+
+func (interactor *PersonalInteractor) RemoveMetadata(userId, gameId int) (error, int) {
+	removed, err := interactor.PersonalMetadataRepository.Remove(userId, gameId)
+	if err != nil {
+		return err, 500
+	}
+	if !removed {
+		return domain.NewError(domain.CodeNotFound, "User #%d set nothing on game #%d",
+			userId, gameId), 404
+	}
+	return nil, 200
+}
+
+// Games of the user's libraries matching the filter, a game held by several
+// libraries counts once with its most active status. Playing games come
+// first, then the most replayable and the easiest.
+func (interactor *PersonalInteractor) ShowTonight(userId int, filter TonightFilter) ([]Candidate, error, int) {
+	wanted, err := normalizeMoods("mood", filter.Moods)
+	if err != nil {
+		return nil, err, 400
+	}
+	if filter.MaxDifficulty < 0 || filter.MaxDifficulty > maxPersonalScore {
+		return nil, domain.NewFieldError("maxDifficulty", "Must be between 0 and %d",
+			maxPersonalScore), 400
+	}
+	if filter.MinReplayability < 0 || filter.MinReplayability > maxPersonalScore {
+		return nil, domain.NewFieldError("minReplayability", "Must be between 0 and %d",
+			maxPersonalScore), 400
+	}
+	statuses := filter.Statuses
+	if len(statuses) == 0 {
+		statuses = tonightStatuses
+	}
+	for _, status := range statuses {
+		if !gameStatuses[status] {
+			return nil, domain.NewFieldError("status", "Status '%s' is unknown", status), 400
+		}
+	}
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	limit, err := interactor.Parental.RatingLimit(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	candidates, err := interactor.PersonalMetadataRepository.FindCandidates(userId, statuses)
+	if err != nil {
+		return nil, err, 500
+	}
+
+	best := make(map[int]Candidate)
+	for _, candidate := range candidates {
+		if limit > 0 && candidate.Game.MinAge > limit {
+			continue
+		}
+		if !matchesTonight(candidate.Metadata, wanted, filter) {
+			continue
+		}
+		other, seen := best[candidate.Game.Id]
+		if !seen || statusRank(candidate.Game.Status) < statusRank(other.Game.Status) {
+			best[candidate.Game.Id] = candidate
+		}
+	}
+	picked := []Candidate{}
+	for _, candidate := range best {
+		picked = append(picked, candidate)
+	}
+	sort.Slice(picked, func(i, j int) bool {
+		a, b := picked[i], picked[j]
+		if statusRank(a.Game.Status) != statusRank(b.Game.Status) {
+			return statusRank(a.Game.Status) < statusRank(b.Game.Status)
+		}
+		if a.Metadata.Replayability != b.Metadata.Replayability {
+			return a.Metadata.Replayability > b.Metadata.Replayability
+		}
+		if a.Metadata.Difficulty != b.Metadata.Difficulty {
+			return a.Metadata.Difficulty < b.Metadata.Difficulty
+		}
+		if a.Game.Name != b.Game.Name {
+			return a.Game.Name < b.Game.Name
+		}
+		return a.Game.Id < b.Game.Id
+	})
+	return picked, nil, 200
+}
+
+// Games without a difficulty pass a difficulty cap, the user has not
+// called them hard
+func matchesTonight(metadata PersonalMetadata, wanted []string, filter TonightFilter) bool {
+	if filter.MaxDifficulty > 0 && metadata.Difficulty > filter.MaxDifficulty {
+		return false
+	}
+	if filter.MinReplayability > 0 && metadata.Replayability < filter.MinReplayability {
+		return false
+	}
+	for _, mood := range wanted {
+		found := false
+		for _, tag := range metadata.Moods {
+			if tag == mood {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func statusRank(status string) int {
+	for rank, tonight := range tonightStatuses {
+		if status == tonight {
+			return rank
+		}
+	}
+	return len(tonightStatuses)
+}
@@ -0,0 +1,291 @@
+package usecases
+
+import (
+	"strings"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const (
+	PhotoImportPending   = "pending"
+	PhotoImportProposed  = "proposed"
+	PhotoImportFailed    = "failed"
+	PhotoImportConfirmed = "confirmed"
+)
+
+const (
+	maxImportPhotos  = 10
+	photoImportBatch = 20 //Imports looked at per run of the job
+)
+
+// A title read off a photo, Confidence runs from 0 to 1
+type RecognizedTitle struct {
+	Title      string
+	Confidence float64
+}
+
+// Reads the titles printed on game spines and cases, a photo of a shelf
+// gives one title per game it shows
+type VisionProvider interface {
+	RecognizeTitles(contentType string, image []byte) ([]RecognizedTitle, error)
+}
+
+type PhotoImportRepository interface {
+	Store(photoImport PhotoImport) (int, error)
+	FindById(id int) (PhotoImport, error, int)                    //With its proposals
+	FindPending(limit int) ([]PhotoImport, error)                 //Oldest first
+	StoreProposals(importId int, proposals []PhotoProposal) error //Moves the import to proposed
+	Fail(importId int, reason string) error
+	Confirm(importId int, accepted map[int]int) error //Proposal ids to their game, the others are rejected
+	RemoveFromLib(libraryId int) ([]string, error)    //Blob keys of the photos still kept
+}
+
+// Photos of shelves waiting to be turned into physical copies. The job
+// proposes a game for every title it reads and nothing is catalogued until
+// the user confirms the proposals.
+type PhotoImport struct {
+	Id        int
+	UserId    int
+	LibraryId int
+	Status    string
+	Reason    string //Why the import failed
+	Photos    []ImportPhoto
+	Proposals []PhotoProposal
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ImportPhoto struct {
+	Key         string //Blob key, photos are deleted once the import is confirmed
+	ContentType string
+}
+
+// Ids count from 1 within their import
+type PhotoProposal struct {
+	Id         int
+	Photo      int //Index of the photo the title was read from
+	Title      string
+	Confidence float64
+	Matched    bool   //The metadata provider knows the title
+	Reason     string //Why it is not matched
+	Accepted   bool
+	GameId     int //Set once accepted
+}
+
+// Keeps the photos and queues them for the import job
+func (interactor *ProfileInteractor) StartPhotoImport(userId, libraryId int, photos []Screenshot) (PhotoImport, error, int) {
+	if interactor.Vision == nil {
+		return PhotoImport{}, domain.NewError(domain.CodeUnavailable,
+			"Photo imports are not configured"), 503
+	}
+	if len(photos) == 0 || len(photos) > maxImportPhotos {
+		return PhotoImport{}, domain.NewFieldError("photos", "Upload between 1 and %d photos",
+			maxImportPhotos), 400
+	}
+	for _, photo := range photos {
+		if !screenshotTypes[photo.ContentType] {
+			return PhotoImport{}, domain.NewFieldError("photos",
+				"Must be PNG, JPEG, GIF or WebP images"), 400
+		}
+		if len(photo.Data) > maxScreenshotBytes {
+			return PhotoImport{}, domain.NewFieldError("photos", "Each must be at most %d bytes",
+				maxScreenshotBytes), 400
+		}
+	}
+	_, err, code := interactor.importTarget(userId, libraryId)
+	if err != nil {
+		return PhotoImport{}, err, code
+	}
+
+	photoImport := PhotoImport{UserId: userId, LibraryId: libraryId, Status: PhotoImportPending}
+	for _, photo := range photos {
+		key, err := newBlobKey("photos")
+		if err != nil {
+			return PhotoImport{}, err, 500
+		}
+		err = interactor.BlobStore.Put(key, photo.Data)
+		if err != nil {
+			return PhotoImport{}, err, 500
+		}
+		photoImport.Photos = append(photoImport.Photos, ImportPhoto{Key: key, ContentType: photo.ContentType})
+	}
+	photoImport.Id, err = interactor.PhotoImportRepository.Store(photoImport)
+	if err != nil {
+		return PhotoImport{}, err, 500
+	}
+	interactor.count("StartPhotoImport")
+	interactor.logf("User #%d queued %d photos for library #%d as import #%d",
+		userId, len(photos), libraryId, photoImport.Id)
+	// A worker reads the photos right away, the job picks up what it misses
+	if interactor.WorkQueue != nil {
+		err = interactor.WorkQueue.Enqueue(Work{Kind: WorkPhotoImports, UserId: userId,
+			EntityId: photoImport.Id})
+		if err != nil {
+			interactor.logf("Cannot hand import #%d to workers: %v", photoImport.Id, err)
+		}
+	}
+	return interactor.PhotoImportRepository.FindById(photoImport.Id)
+}
+
+// Imports of other users or libraries look like they do not exist
+func (interactor *ProfileInteractor) ShowPhotoImport(userId, libraryId, importId int) (PhotoImport, error, int) {
+	photoImport, err, code := interactor.PhotoImportRepository.FindById(importId)
+	if code == 404 || (err == nil && (photoImport.UserId != userId || photoImport.LibraryId != libraryId)) {
+		return PhotoImport{}, domain.NewError(domain.CodeNotFound,
+			"Photo import #%d does not exist", importId), 404
+	}
+	return photoImport, err, code
+}
+
+// Run by the photo import job: every pending import gets its proposals.
+// Imports the provider cannot be reached for stay pending and are tried
+// again on the next run.
+func (interactor *ProfileInteractor) ProcessPhotoImports() error {
+	if interactor.Vision == nil {
+		return nil
+	}
+	imports, err := interactor.PhotoImportRepository.FindPending(photoImportBatch)
+	if err != nil {
+		return err
+	}
+	for _, photoImport := range imports {
+		proposals, reason, err := interactor.proposeGames(photoImport)
+		if err != nil {
+			interactor.logf("Cannot read the photos of import #%d: %v", photoImport.Id, err)
+			continue
+		}
+		if reason != "" {
+			err = interactor.PhotoImportRepository.Fail(photoImport.Id, reason)
+		} else {
+			err = interactor.PhotoImportRepository.StoreProposals(photoImport.Id, proposals)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The proposals for every title read, or the reason the import cannot go on
+func (interactor *ProfileInteractor) proposeGames(photoImport PhotoImport) ([]PhotoProposal, string, error) {
+	var proposals []PhotoProposal
+	seen := make(map[string]bool)
+	for i, photo := range photoImport.Photos {
+		data, found, err := interactor.BlobStore.Get(photo.Key)
+		if err != nil {
+			return nil, "", err
+		}
+		if !found {
+			return nil, "A photo is missing, upload them again", nil
+		}
+		titles, err := interactor.Vision.RecognizeTitles(photo.ContentType, data)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, recognized := range titles {
+			title := strings.TrimSpace(recognized.Title)
+			if title == "" || seen[strings.ToLower(title)] {
+				continue
+			}
+			seen[strings.ToLower(title)] = true
+			reason := interactor.resolveTitle(title)
+			proposals = append(proposals, PhotoProposal{Id: len(proposals) + 1, Photo: i, Title: title,
+				Confidence: recognized.Confidence, Matched: reason == "", Reason: reason})
+		}
+	}
+	if len(proposals) == 0 {
+		return nil, "No game titles could be read from the photos", nil
+	}
+	return proposals, "", nil
+}
+
+// Catalogs the accepted proposals as physical copies, keyed by proposal id
+// with the title to take; an empty title keeps the one read from the photo.
+// Accepted games not yet in the library are added to it, the ones parental
+// controls keep out stay unaccepted. The photos are deleted afterwards.
+func (interactor *ProfileInteractor) ConfirmPhotoImport(userId, libraryId, importId int, accepted map[int]string) ([]PhysicalCopy, error, int) {
+	photoImport, err, code := interactor.ShowPhotoImport(userId, libraryId, importId)
+	if err != nil {
+		return nil, err, code
+	}
+	if photoImport.Status != PhotoImportProposed {
+		return nil, domain.NewError(domain.CodeConflict,
+			"Photo import #%d is %s, only proposed imports can be confirmed", importId, photoImport.Status), 409
+	}
+	titles := make(map[int]string)
+	for _, proposal := range photoImport.Proposals {
+		titles[proposal.Id] = proposal.Title
+	}
+	for proposalId, title := range accepted {
+		if _, found := titles[proposalId]; !found {
+			return nil, domain.NewFieldError("accept", "Proposal #%d does not exist", proposalId), 400
+		}
+		if title = strings.TrimSpace(title); title != "" {
+			titles[proposalId] = title
+		}
+		if len(titles[proposalId]) > maxImportTitleLength {
+			return nil, domain.NewFieldError("accept", "Titles must be at most %d characters",
+				maxImportTitleLength), 400
+		}
+	}
+	owned, err, code := interactor.importTarget(userId, photoImport.LibraryId)
+	if err != nil {
+		return nil, err, code
+	}
+
+	games := make(map[int]int)
+	var copies []PhysicalCopy
+	for _, proposal := range photoImport.Proposals {
+		if _, found := accepted[proposal.Id]; !found {
+			continue
+		}
+		game, found := owned[strings.ToLower(titles[proposal.Id])]
+		if !found {
+			game, err, code = interactor.AddGame(userId, photoImport.LibraryId, Game{Name: titles[proposal.Id]}, 0)
+			if code == 403 || code == 400 {
+				interactor.logf("Proposal #%d of photo import #%d left out: %v", proposal.Id, importId, err)
+				continue
+			}
+			if err != nil {
+				return nil, err, code
+			}
+			owned[strings.ToLower(game.Name)] = game
+		}
+		games[proposal.Id] = game.Id
+		physical := PhysicalCopy{LibraryId: photoImport.LibraryId, GameId: game.Id,
+			GameExternalId: game.ExternalId, GameName: game.Name}
+		id, err := interactor.PhysicalCopyRepository.Store(physical)
+		if err != nil {
+			return nil, err, 500
+		}
+		physical, err, code = interactor.PhysicalCopyRepository.FindById(id)
+		if err != nil {
+			return nil, err, code
+		}
+		copies = append(copies, physical)
+	}
+	err = interactor.PhotoImportRepository.Confirm(importId, games)
+	if err != nil {
+		return nil, err, 500
+	}
+	var keys []string
+	for _, photo := range photoImport.Photos {
+		keys = append(keys, photo.Key)
+	}
+	interactor.removePhotos(keys)
+	interactor.count("ConfirmPhotoImport")
+	interactor.logf("User #%d confirmed %d of %d proposals of photo import #%d",
+		userId, len(copies), len(photoImport.Proposals), importId)
+	return copies, nil, 200
+}
+
+// Photos left behind only take up space, failing to delete one is logged
+func (interactor *ProfileInteractor) removePhotos(keys []string) {
+	for _, key := range keys {
+		err := interactor.BlobStore.Delete(key)
+		if err != nil {
+			interactor.logf("Cannot remove photo %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package usecases
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const maxPickWeight = 10
+
+// Figures about a game gathered across all users. There are no friend lists,
+// so the rating is the average replayability other users gave the game.
+type GameStats struct {
+	GameId            int
+	CompletionMinutes int //Average playtime of the users who completed it, 0 when unknown
+	Rating            float64
+	Raters            int
+}
+
+// How much each criterion counts towards a pick, all zero weighs them equally
+type PickWeights struct {
+	Shortest float64 //Shortest completion time
+	Oldest   float64 //Longest in the user's libraries
+	Rating   float64 //Highest rated by other users
+	Mood     float64 //Tagged with the mood asked for
+}
+
+type PickOptions struct {
+	Weights PickWeights
+	Mood    string
+	Seed    int64 //0 draws a new seed, the same seed over the same backlog picks the same game
+}
+
+type Suggestion struct {
+	Candidate Candidate
+	Stats     GameStats
+	Score     float64 //Between 0 and the sum of the weights
+	Seed      int64   //Picks the same game again
+}
+
+// Draws a game of the backlog, the higher it scores on the weighted
+// criteria the likelier it is picked. A criterion a game has no data for
+// scores half.
+func (interactor *PersonalInteractor) PickNext(userId int, options PickOptions) (Suggestion, error, int) {
+	weights := options.Weights
+	for field, weight := range map[string]float64{"shortestWeight": weights.Shortest,
+		"oldestWeight": weights.Oldest, "ratingWeight": weights.Rating, "moodWeight": weights.Mood} {
+		if weight < 0 || weight > maxPickWeight {
+			return Suggestion{}, domain.NewFieldError(field, "Must be between 0 and %d",
+				maxPickWeight), 400
+		}
+	}
+	if weights == (PickWeights{}) {
+		weights = PickWeights{Shortest: 1, Oldest: 1, Rating: 1, Mood: 1}
+	}
+	var mood []string
+	if options.Mood != "" {
+		var err error
+		mood, err = normalizeMoods("mood", []string{options.Mood})
+		if err != nil {
+			return Suggestion{}, err, 400
+		}
+	}
+
+	backlog, err, code := interactor.ShowTonight(userId, TonightFilter{Statuses: []string{"backlog"}})
+	if err != nil {
+		return Suggestion{}, err, code
+	}
+	if len(backlog) == 0 {
+		return Suggestion{}, domain.NewError(domain.CodeNotFound,
+			"User #%d has nothing in the backlog to pick from", userId), 404
+	}
+	// Draws only repeat when the candidates come in the same order
+	sort.Slice(backlog, func(i, j int) bool { return backlog[i].Game.Id < backlog[j].Game.Id })
+	ids := make([]int, len(backlog))
+	for i, candidate := range backlog {
+		ids[i] = candidate.Game.Id
+	}
+	found, err := interactor.PersonalMetadataRepository.FindGameStats(userId, ids)
+	if err != nil {
+		return Suggestion{}, err, 500
+	}
+	stats := make(map[int]GameStats)
+	for _, entry := range found {
+		stats[entry.GameId] = entry
+	}
+
+	shortest, longest := 0, 0
+	oldest, newest := backlog[0].AddedAt, backlog[0].AddedAt
+	for _, candidate := range backlog {
+		if minutes := stats[candidate.Game.Id].CompletionMinutes; minutes > 0 {
+			if shortest == 0 || minutes < shortest {
+				shortest = minutes
+			}
+			if minutes > longest {
+				longest = minutes
+			}
+		}
+		if candidate.AddedAt.Before(oldest) {
+			oldest = candidate.AddedAt
+		}
+		if candidate.AddedAt.After(newest) {
+			newest = candidate.AddedAt
+		}
+	}
+
+	suggestions := make([]Suggestion, len(backlog))
+	total := 0.0
+	for i, candidate := range backlog {
+		entry := stats[candidate.Game.Id]
+		entry.GameId = candidate.Game.Id
+		score := weights.Shortest*closeness(float64(entry.CompletionMinutes), float64(shortest),
+			float64(longest), entry.CompletionMinutes > 0) +
+			weights.Oldest*closeness(float64(candidate.AddedAt.Unix()), float64(oldest.Unix()),
+				float64(newest.Unix()), true) +
+			weights.Rating*ratingScore(entry)
+		if len(mood) > 0 {
+			if matchesTonight(candidate.Metadata, mood, TonightFilter{}) {
+				score += weights.Mood
+			}
+		} else {
+			score += weights.Mood / 2
+		}
+		suggestions[i] = Suggestion{Candidate: candidate, Stats: entry, Score: score}
+		total += score
+	}
+
+	seed := options.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	random := rand.New(rand.NewSource(seed))
+	// Nothing scored, every game is as likely
+	if total == 0 {
+		picked := suggestions[random.Intn(len(suggestions))]
+		picked.Seed = seed
+		return picked, nil, 200
+	}
+	draw := random.Float64() * total
+	picked := suggestions[len(suggestions)-1]
+	for _, suggestion := range suggestions {
+		if draw < suggestion.Score {
+			picked = suggestion
+			break
+		}
+		draw -= suggestion.Score
+	}
+	picked.Seed = seed
+	return picked, nil, 200
+}
+
+// 1 for the lowest value of the range down to 0 for the highest, half when
+// the value is unknown
+func closeness(value, lowest, highest float64, known bool) float64 {
+	if !known {
+		return 0.5
+	}
+	if highest == lowest {
+		return 1
+	}
+	return (highest - value) / (highest - lowest)
+}
+
+func ratingScore(stats GameStats) float64 {
+	if stats.Raters == 0 {
+		return 0.5
+	}
+	return stats.Rating / maxPersonalScore
+}
@@ -0,0 +1,369 @@
+package usecases
+
+import (
+	"time"
+)
+
+// The usecases as the delivery layer sees them, implemented by pointers to
+// the interactors. Handlers only hold these so they can be tested against
+// fakes, and the interactors can change behind them without touching the
+// web layer as long as the results they hand out stay the same.
+
+type ProfileUsecase interface {
+	WithLogger(logger LoggerRepository) ProfileUsecase
+	AttachAddon(userId, libraryId, gameId, parentId int, kind string) (GameTree, error, int)
+	DetachAddon(userId, libraryId, gameId int) (error, int)
+	ShowGameTree(userId, libraryId, gameId int) (GameTree, error, int)
+	AddBackup(userId, libraryId, gameId int, backup SaveBackup) (SaveBackup, error, int)
+	ShowBackups(userId, libraryId, gameId int) ([]SaveBackup, error, int)
+	RemoveBackup(userId, libraryId, gameId, backupId int) (error, int)
+	ScanBarcode(userId, libraryId int, barcode string) (PhysicalCopy, error, int)
+	ShowCopies(userId, libraryId int, page Page) ([]PhysicalCopy, Cursor, error, int)
+	RemoveCopy(userId, libraryId, copyId int) (error, int)
+	PrintCollectionWorth(userId, libraryId int, locale string) (ExportedFile, error, int)
+	ShowFeatures(userId int) (map[string]bool, error, int)
+	UpdateGames(userId, libraryId int, gameIds []int, change GameChange, baseVersions map[int]int64) ([]BatchItem, error, int)
+	ImportGames(userId, libraryId int, format string, data []byte) (ImportReport, error, int)
+	ShowMembers(userId, libraryId int) ([]LibraryMember, error, int)
+	ShowMemberships(userId int) ([]LibraryMember, error, int)
+	SetMember(userId, libraryId int, memberName, role string) (LibraryMember, error, int)
+	RemoveMember(userId, libraryId, memberId int) (error, int)
+	AddMod(userId, libraryId, gameId int, mod Mod) (Mod, error, int)
+	ShowMods(userId, libraryId, gameId int) ([]Mod, error, int)
+	EditMod(userId, libraryId, gameId, modId int, changed Mod) (Mod, error, int)
+	RemoveMod(userId, libraryId, gameId, modId int) (error, int)
+	ImportMods(userId, libraryId int, data []byte) (ModImportReport, error, int)
+	StartPhotoImport(userId, libraryId int, photos []Screenshot) (PhotoImport, error, int)
+	ShowPhotoImport(userId, libraryId, importId int) (PhotoImport, error, int)
+	ConfirmPhotoImport(userId, libraryId, importId int, accepted map[int]string) ([]PhysicalCopy, error, int)
+	SetCopyPrice(userId, libraryId, copyId int, paid float64, currency string) (PhysicalCopy, error, int)
+	ShowCollectionWorth(userId, libraryId int) (CollectionWorth, error, int)
+	ShowGames(userId, libraryId int, filter GameFilter, page Page) ([]Game, Cursor, error, int)
+	ImportSteamWishlist(userId, libraryId int, steamId string) (ImportReport, error, int)
+	CheckWritable(userId int) (error, int)
+	ShowTags(userId int) ([]TagCount, error, int)
+	RenameTag(userId int, from, to string) (TagChange, error, int)
+	MergeTags(userId int, from, into string) (TagChange, error, int)
+	ProposeTrade(userId int, offer TradeOffer) (TradeOffer, error, int)
+	ShowTrades(userId int, page Page) ([]TradeOffer, Cursor, error, int)
+	ShowTrade(userId, tradeId int) (TradeOffer, error, int)
+	AcceptTrade(userId, tradeId int) (TradeOffer, error, int)
+	DeclineTrade(userId, tradeId int) (TradeOffer, error, int)
+	CancelTrade(userId, tradeId int) (TradeOffer, error, int)
+	CounterTrade(userId, tradeId int, offered, requested []int) (TradeOffer, error, int)
+	AddUser(playerName, userName, password string) (User, error, int)
+	ShowUser(userId int) (User, error, int)
+	ShowActiveUser(userId int) (User, error, int)
+	RenameUser(userId int, name string) (User, error, int)
+	RemoveUser(userId int) (error, int)
+	ShowUserInfo(userId int) (string, int64, error, int)
+	EditUserInfo(userId int, info string, baseVersion int64) (int64, error, int)
+	AddLibrary(userId int) (Library, error, int)
+	ShowLibrary(userId, libraryId int) (Library, error, int)
+	ShowLibraryVersions(userId int) ([]Library, error, int)
+	RemoveLibrary(userId, libraryId int) (error, int)
+	ShowGame(userId, libraryId, gameId int) (Game, error, int)
+	AddGame(userId, libraryId int, game Game, baseVersion int64) (Game, error, int)
+	PickGame(userId, libraryId, gameId int, baseVersion int64) (error, int)
+	RemoveGame(userId, libraryId, gameId int, baseVersion int64) (error, int)
+	FindLoginId(username, password string) (int, error, int)
+	FindUserIdByName(userName string) (int, error, int)
+	FindUserId(externalId string) (int, error, int)
+	FindLibraryId(externalId string) (int, error, int)
+	FindGameId(externalId string) (int, error, int)
+}
+
+type NotificationUsecase interface {
+	WithLogger(logger LoggerRepository) NotificationUsecase
+	ShowNotifications(userId int, page Page) ([]Notification, int, Cursor, error, int)
+	MarkNotificationsRead(userId int, ids []int) (error, int)
+	ClearNotifications(userId int) (error, int)
+}
+
+type SettingsUsecase interface {
+	WithLogger(logger LoggerRepository) SettingsUsecase
+	ShowSettings(userId int) (Settings, error, int)
+	UserLocale(userId int) (string, error)
+	EditSettings(userId int, settings Settings) (Settings, error, int)
+}
+
+type SyncUsecase interface {
+	Sync(userId int, cursor string, limit int) ([]Change, string, bool, error, int)
+}
+
+type AdminUsecase interface {
+	WithLogger(logger LoggerRepository) AdminUsecase
+	ListUsers(adminId int, filter UserFilter) ([]User, Cursor, error, int)
+	ShowUser(adminId, userId int) (User, error, int)
+	ShowUserStats(adminId, userId int) (UserStats, error, int)
+	ShowMetrics(adminId int) (Metrics, error, int)
+	Impersonate(adminId, userId int, reason string) (User, error, int)
+	ToggleMaintenance(adminId int, enabled bool, reason string) (error, int)
+	Authorize(adminId int) (error, int)
+	ListFlags(adminId int) ([]FeatureFlag, error, int)
+	SetFlag(adminId int, flag FeatureFlag) (FeatureFlag, error, int)
+	RemoveFlag(adminId int, name string) (error, int)
+	FlagSpoilers(adminId, gameId int, containsSpoilers bool) (Game, error, int)
+	Suspend(adminId, userId int, reason string, until *time.Time) (User, error, int)
+	Ban(adminId, userId int, reason string) (User, error, int)
+	Reinstate(adminId, userId int, reason string) (User, error, int)
+}
+
+type ActivityUsecase interface {
+	WithLogger(logger LoggerRepository) ActivityUsecase
+	ShowFeed(userName string) (User, []Activity, error, int)
+}
+
+type CalendarUsecase interface {
+	WithLogger(logger LoggerRepository) CalendarUsecase
+	AddSession(userId, gameId int, startsAt time.Time, minutes int, notes string, partnerIds []int) (PlaySession, error, int)
+	ShowSessions(userId int, from, to time.Time) ([]PlaySession, error, int)
+	RemoveSession(userId, sessionId int) (error, int)
+	TrackRelease(userId, gameId int, date time.Time) (Release, error, int)
+	UntrackRelease(userId, gameId int) (error, int)
+	ShowReleases(userId int) ([]Release, error, int)
+	IssueCalendarToken(userId int) (string, error, int)
+	ShowCalendar(token string) (User, []PlaySession, []Release, error, int)
+	EditSession(userId, sessionId int, notes string, partnerIds []int) (PlaySession, error, int)
+	ShowCoop(userId, partnerId int) ([]CoopPartner, error, int)
+	ShowHeatmap(userId int) (Heatmap, error, int)
+	ShowStreak(userId int) (Streak, error, int)
+}
+
+type FranchiseUsecase interface {
+	WithLogger(logger LoggerRepository) FranchiseUsecase
+	FindFranchiseId(externalId string) (int, error, int)
+	AddFranchise(name string) (Franchise, error, int)
+	RemoveFranchise(franchiseId int) (error, int)
+	ShowFranchises() ([]Franchise, error, int)
+	ShowFranchise(franchiseId int) (Franchise, error, int)
+	AddGame(franchiseId, gameId, position int) (Franchise, error, int)
+	RemoveGame(franchiseId, gameId int) (error, int)
+	ShowProgress(userId int) ([]FranchiseProgress, error, int)
+}
+
+type ParentalUsecase interface {
+	WithLogger(logger LoggerRepository) ParentalUsecase
+	LinkChild(parentId, childId int) (ChildAccount, error, int)
+	ShowChildren(parentId int) ([]ChildAccount, error, int)
+	SetLimits(parentId, childId, dailyMinutes, ratingCap int) (ChildAccount, error, int)
+	UnlinkChild(parentId, childId int) (error, int)
+	ShowReport(parentId, childId int, from, to time.Time) (ChildReport, error, int)
+}
+
+type PersonalUsecase interface {
+	WithLogger(logger LoggerRepository) PersonalUsecase
+	SetMetadata(metadata PersonalMetadata) (PersonalMetadata, error, int)
+	ShowMetadata(userId, gameId int) (PersonalMetadata, error, int)
+	RemoveMetadata(userId, gameId int) (error, int)
+	ShowTonight(userId int, filter TonightFilter) ([]Candidate, error, int)
+	PickNext(userId int, options PickOptions) (Suggestion, error, int)
+}
+
+type JournalUsecase interface {
+	WithLogger(logger LoggerRepository) JournalUsecase
+	AddEntry(userId, gameId int, text string, screenshot *Screenshot) (JournalEntry, error, int)
+	ShowEntries(userId, gameId int, showSpoilers bool, page Page) ([]JournalEntry, Cursor, error, int)
+	ShowScreenshot(userId, entryId int) (Screenshot, error, int)
+	RemoveEntry(userId, entryId int) (error, int)
+}
+
+type ExportUsecase interface {
+	WithLogger(logger LoggerRepository) ExportUsecase
+	ExportProfile(userId int, showSpoilers bool) (ProfileExport, error, int)
+	ExportFile(userId int, format string) (ExportedFile, error, int)
+}
+
+type SharingUsecase interface {
+	WithLogger(logger LoggerRepository) SharingUsecase
+	ShareLibrary(userId, libraryId int, expiresAt time.Time, password string) (ShareLink, string, error, int)
+	ShowShareLinks(userId, libraryId int) ([]ShareLink, error, int)
+	RevokeShareLink(userId, libraryId, linkId int) (error, int)
+	ShowSharedLibrary(token, password string) (User, ShareLink, []Game, error, int)
+}
+
+type BadgeUsecase interface {
+	WithLogger(logger LoggerRepository) BadgeUsecase
+	ShowBadges(userId int) ([]Badge, error, int)
+}
+
+type GoalUsecase interface {
+	WithLogger(logger LoggerRepository) GoalUsecase
+	AddGoal(userId int, goal Goal) (GoalProgress, error, int)
+	ShowGoals(userId int) ([]GoalProgress, error, int)
+	ShowGoal(userId, goalId int) (GoalProgress, error, int)
+	EditGoal(userId, goalId, target int) (GoalProgress, error, int)
+	RemoveGoal(userId, goalId int) (error, int)
+}
+
+type HardwareUsecase interface {
+	WithLogger(logger LoggerRepository) HardwareUsecase
+	AddHardware(userId int, item Hardware) (Hardware, error, int)
+	ShowHardware(userId int) ([]Hardware, error, int)
+	ShowHardwareItem(userId, itemId int) (Hardware, error, int)
+	EditHardware(userId, itemId int, changed Hardware) (Hardware, error, int)
+	RemoveHardware(userId, itemId int) (error, int)
+}
+
+type SubscriptionUsecase interface {
+	WithLogger(logger LoggerRepository) SubscriptionUsecase
+	AddSubscription(userId int, subscription Subscription) (Subscription, error, int)
+	ShowSubscriptions(userId int) ([]Subscription, error, int)
+	ShowSubscription(userId, subscriptionId int) (Subscription, error, int)
+	EditSubscription(userId, subscriptionId int, changed Subscription) (Subscription, error, int)
+	RemoveSubscription(userId, subscriptionId int) (error, int)
+	AddSubscriptionGame(userId, subscriptionId, gameId int) (Subscription, error, int)
+	RemoveSubscriptionGame(userId, subscriptionId, gameId int) (Subscription, error, int)
+	ShowSubscriptionReport(userId int, from, to time.Time) (SubscriptionReport, error, int)
+}
+
+type CatalogUsecase interface {
+	WithLogger(logger LoggerRepository) CatalogUsecase
+	ShowCatalogGames(userId int) ([]CatalogGame, error, int)
+}
+
+type SpeedrunUsecase interface {
+	WithLogger(logger LoggerRepository) SpeedrunUsecase
+	AddRun(userId, gameId int, run SpeedRun) (SpeedRun, error, int)
+	ShowRuns(userId, gameId int, category string, page Page) ([]SpeedRun, Cursor, error, int)
+	ShowPersonalBests(userId int) ([]SpeedRun, error, int)
+	RemoveRun(userId, gameId, runId int) (error, int)
+	ComparePersonalBests(userId, gameId int) ([]SpeedrunComparison, error, int)
+}
+
+type MatchUsecase interface {
+	WithLogger(logger LoggerRepository) MatchUsecase
+	IngestMatches(userId int, matches []Match) ([]MatchItem, error, int)
+	ShowMatches(userId int, filter MatchFilter) ([]Match, Cursor, error, int)
+	ShowMatchStats(userId int, filter MatchFilter) (MatchStats, error, int)
+	ShowHeadToHead(userId int, filter MatchFilter) ([]HeadToHead, error, int)
+	RemoveMatch(userId, matchId int) (error, int)
+}
+
+type AgentUsecase interface {
+	WithLogger(logger LoggerRepository) AgentUsecase
+	AddAgent(userId int, name string) (Agent, string, error, int)
+	ShowAgents(userId int) ([]Agent, error, int)
+	RemoveAgent(userId, agentId int) (error, int)
+	MapExecutable(userId int, executable string, gameId int) (ExecutableGame, error, int)
+	ShowExecutables(userId int) ([]ExecutableGame, error, int)
+	UnmapExecutable(userId int, executable string) (error, int)
+	ReportProcess(token string, event AgentEvent) (AgentReport, error, int)
+}
+
+type WebhookUsecase interface {
+	WithLogger(logger LoggerRepository) WebhookUsecase
+	AddWebhook(userId int, name, action string, libraryId int) (Webhook, string, error, int)
+	ShowWebhooks(userId int) ([]Webhook, error, int)
+	RemoveWebhook(userId, webhookId int) (error, int)
+	VerifyDelivery(key string, body []byte, signature string) (Webhook, error, int)
+	Deliver(webhook Webhook, delivery WebhookDelivery) (error, int)
+}
+
+type RuleUsecase interface {
+	WithLogger(logger LoggerRepository) RuleUsecase
+	AddRule(userId int, rule Rule) (Rule, error, int)
+	ShowRules(userId int) ([]Rule, error, int)
+	EditRule(userId, ruleId int, changed Rule) (Rule, error, int)
+	RemoveRule(userId, ruleId int) (error, int)
+	ShowRuns(userId, ruleId int) ([]RuleRun, error, int)
+	TestRule(userId int, rule Rule, fields map[string]string) (RuleTest, error, int)
+}
+
+type ScriptUsecase interface {
+	WithLogger(logger LoggerRepository) ScriptUsecase
+	AddScript(adminId int, script Script) (Script, error, int)
+	ShowScripts(adminId int) ([]Script, error, int)
+	EditScript(adminId, scriptId int, changed Script) (Script, error, int)
+	RemoveScript(adminId, scriptId int) (error, int)
+}
+
+type SearchUsecase interface {
+	WithLogger(logger LoggerRepository) SearchUsecase
+	AddSearch(userId int, search SavedSearch) (SavedSearch, error, int)
+	ShowSearches(userId int) ([]SavedSearch, error, int)
+	EditSearch(userId, searchId int, changed SavedSearch) (SavedSearch, error, int)
+	RemoveSearch(userId, searchId int) (error, int)
+	SearchFilter(userId, searchId int) (GameFilter, error, int)
+	DefaultFilter(userId, libraryId int) (GameFilter, error, int)
+	SetDefaultSearch(userId, libraryId, searchId int) (error, int)
+	ShareSearch(userId, searchId int) (SavedSearch, string, error, int)
+	UnshareSearch(userId, searchId int) (error, int)
+	ShowSharedSearch(token string) (SavedSearch, error, int)
+	ImportSearch(userId int, token string) (SavedSearch, error, int)
+}
+
+type StatsUsecase interface {
+	ShowStats(userId int) (LibraryStats, error, int)
+}
+
+type RenderUsecase interface {
+	Render(source string) (string, error, int)
+}
+
+type DiagnosticsUsecase interface {
+	ShowDiagnostics(adminId int) (Diagnostics, error, int)
+	Collect() Diagnostics
+}
+
+type ChangeStreamUsecase interface {
+	Watch(userId int) (<-chan Change, func(), error, int)
+}
+
+type LibraryEventsUsecase interface {
+	ShowLibraryEvents(userId, libraryId int, page Page) ([]LibraryEvent, Cursor, error, int)
+	ReplayLibrary(adminId, libraryId int) (LibraryState, error, int)
+	ShowLibraryAt(userId, libraryId int, day time.Time) (LibraryAsOf, error, int)
+	DiffLibrary(userId, libraryId int, from, to time.Time) (LibraryDiff, error, int)
+}
+
+type UndoUsecase interface {
+	ShowUndoStack(userId int) ([]UndoAction, error, int)
+	Undo(userId int) (UndoAction, error, int)
+	Redo(userId int) (UndoAction, error, int)
+}
+
+type TrashUsecase interface {
+	ShowTrash(userId int) ([]TrashItem, error, int)
+	RestoreFromTrash(userId int, itemId int64) (TrashItem, error, int)
+}
+
+type IntegrityUsecase interface {
+	CheckIntegrity(adminId int, repair bool) ([]IntegrityFinding, error, int)
+}
+
+var (
+	_ ProfileUsecase       = &ProfileInteractor{}
+	_ NotificationUsecase  = &NotificationInteractor{}
+	_ SettingsUsecase      = &SettingsInteractor{}
+	_ SyncUsecase          = &SyncInteractor{}
+	_ AdminUsecase         = &AdminInteractor{}
+	_ ActivityUsecase      = &ActivityInteractor{}
+	_ CalendarUsecase      = &CalendarInteractor{}
+	_ FranchiseUsecase     = &FranchiseInteractor{}
+	_ ParentalUsecase      = &ParentalInteractor{}
+	_ PersonalUsecase      = &PersonalInteractor{}
+	_ JournalUsecase       = &JournalInteractor{}
+	_ ExportUsecase        = &ExportInteractor{}
+	_ SharingUsecase       = &SharingInteractor{}
+	_ BadgeUsecase         = &BadgeInteractor{}
+	_ GoalUsecase          = &GoalInteractor{}
+	_ HardwareUsecase      = &HardwareInteractor{}
+	_ SubscriptionUsecase  = &SubscriptionInteractor{}
+	_ CatalogUsecase       = &CatalogInteractor{}
+	_ SpeedrunUsecase      = &SpeedrunInteractor{}
+	_ MatchUsecase         = &MatchInteractor{}
+	_ AgentUsecase         = &AgentInteractor{}
+	_ WebhookUsecase       = &WebhookInteractor{}
+	_ RuleUsecase          = &RuleInteractor{}
+	_ ScriptUsecase        = &ScriptInteractor{}
+	_ SearchUsecase        = &SearchInteractor{}
+	_ StatsUsecase         = &StatsInteractor{}
+	_ RenderUsecase        = &RenderInteractor{}
+	_ DiagnosticsUsecase   = &DiagnosticsInteractor{}
+	_ ChangeStreamUsecase  = &ChangeStreamInteractor{}
+	_ LibraryEventsUsecase = &LibraryEventsInteractor{}
+	_ UndoUsecase          = &UndoInteractor{}
+	_ TrashUsecase         = &TrashInteractor{}
+	_ IntegrityUsecase     = &IntegrityInteractor{}
+)
@@ -0,0 +1,134 @@
+package usecases
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const (
+	priceBatch = 100 //Copies valued per page of a refresh
+	maxPrice   = 1e7
+)
+
+// A resale value as a marketplace quotes it for a boxed copy
+type PriceEstimate struct {
+	Value    float64
+	Currency string //ISO 4217
+}
+
+// Estimates what physical copies sell for second hand
+type PricingProvider interface {
+	Estimate(name, platform, barcode string) (PriceEstimate, bool, error) //False when the game is not listed
+}
+
+// What copies of one library cost and are worth now. Currencies are never
+// converted, every total is kept per currency.
+type CollectionWorth struct {
+	LibraryId int
+	Copies    []PhysicalCopy
+	Paid      map[string]float64
+	Value     map[string]float64
+	Unpaid    int //Copies without a paid price
+	Unvalued  int //Copies without an estimate
+}
+
+// Records what the user paid for a copy, in their display currency unless
+// another is given
+func (interactor *ProfileInteractor) SetCopyPrice(userId, libraryId, copyId int, paid float64, currency string) (PhysicalCopy, error, int) {
+	if paid < 0 || paid > maxPrice || math.IsNaN(paid) {
+		return PhysicalCopy{}, domain.NewFieldError("paidPrice", "Must be between 0 and %.0f", maxPrice), 400
+	}
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		settings, err := loadSettings(interactor.SettingsRepository, userId)
+		if err != nil {
+			return PhysicalCopy{}, err, 500
+		}
+		currency = settings.DisplayCurrency
+	}
+	if !currencyPattern.MatchString(currency) {
+		return PhysicalCopy{}, domain.NewFieldError("currency", "Currency '%s' is not an ISO 4217 code",
+			currency), 400
+	}
+	_, err, code := interactor.copyLibrary(userId, libraryId, LibraryRoleEditor)
+	if err != nil {
+		return PhysicalCopy{}, err, code
+	}
+	physical, err, code := interactor.PhysicalCopyRepository.FindById(copyId)
+	if err != nil {
+		return PhysicalCopy{}, err, code
+	}
+	if physical.LibraryId != libraryId {
+		return PhysicalCopy{}, domain.NewError(domain.CodeNotFound, "Copy #%d does not exist", copyId), 404
+	}
+	err = interactor.PhysicalCopyRepository.SetPaidPrice(copyId, paid, currency)
+	if err != nil {
+		return PhysicalCopy{}, err, 500
+	}
+	interactor.logf("User #%d paid %.2f %s for copy #%d", userId, paid, currency, copyId)
+	return interactor.PhysicalCopyRepository.FindById(copyId)
+}
+
+func (interactor *ProfileInteractor) ShowCollectionWorth(userId, libraryId int) (CollectionWorth, error, int) {
+	copies, err, code := interactor.copies(userId, libraryId, Page{})
+	if err != nil {
+		return CollectionWorth{}, err, code
+	}
+	worth := CollectionWorth{LibraryId: libraryId, Copies: copies, Paid: make(map[string]float64),
+		Value: make(map[string]float64)}
+	for _, physical := range copies {
+		if physical.PaidCurrency == "" {
+			worth.Unpaid++
+		} else {
+			worth.Paid[physical.PaidCurrency] += physical.PaidPrice
+		}
+		if physical.ValueCurrency == "" {
+			worth.Unvalued++
+		} else {
+			worth.Value[physical.ValueCurrency] += physical.Value
+		}
+	}
+	interactor.count("ShowCollectionWorth")
+	return worth, nil, 200
+}
+
+// Run by the pricing job: asks the provider again for every copy valued
+// longer than maxAge ago. Copies the provider does not list are marked
+// valued without an estimate so they wait for the next round, a provider
+// error stops the run and the rest is picked up next time.
+func (interactor *ProfileInteractor) RefreshPrices(maxAge time.Duration) error {
+	if interactor.Pricing == nil {
+		return nil
+	}
+	refreshed := 0
+	for {
+		copies, err := interactor.PhysicalCopyRepository.FindStaleValues(time.Now().Add(-maxAge), priceBatch)
+		if err != nil {
+			return err
+		}
+		for _, physical := range copies {
+			estimate, found, err := interactor.Pricing.Estimate(physical.GameName, physical.Platform,
+				physical.Barcode)
+			if err != nil {
+				interactor.logf("Refreshed %d prices, stopped at copy #%d: %v", refreshed, physical.Id, err)
+				return err
+			}
+			if !found || estimate.Value < 0 || estimate.Value > maxPrice ||
+				!currencyPattern.MatchString(estimate.Currency) {
+				estimate = PriceEstimate{}
+			}
+			err = interactor.PhysicalCopyRepository.StoreValue(physical.Id, estimate)
+			if err != nil {
+				return err
+			}
+			refreshed++
+		}
+		if len(copies) < priceBatch {
+			interactor.logf("Refreshed the prices of %d copies", refreshed)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package usecases
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"game-tracker/domain"
+)
+
+// A given rating decides the minimum age, unrated games are rated by the
+// metadata provider when one is configured. A provider that cannot answer
+// leaves the game unrated rather than failing the request.
+func (interactor *ProfileInteractor) rateGame(game Game) (Game, error, int) {
+	if game.Rating == "" && interactor.MetadataProvider != nil {
+		rating, found, err := interactor.MetadataProvider.Rating(game.Name)
+		if err != nil {
+			interactor.logf("Cannot look up the rating of '%s': %v", game.Name, err)
+		}
+		if _, known := domain.NormalizeRating(rating); found && known {
+			game.Rating = rating
+		}
+	}
+	if game.Rating != "" {
+		rating, known := domain.NormalizeRating(game.Rating)
+		if !known {
+			return Game{}, domain.NewFieldError("rating",
+				"Rating '%s' is unknown, use an ESRB or PEGI rating such as 'PEGI 12'", game.Rating), 400
+		}
+		game.Rating, game.MinAge = rating, domain.RatingMinAge(rating)
+	}
+	if game.MinAge < 0 || game.MinAge > maxRatingCap {
+		return Game{}, domain.NewFieldError("minAge", "Must be between 0 and %d", maxRatingCap), 400
+	}
+	return game, nil, 200
+}
+
+// Lists the games of a library, games above the household's rating limit
+// are left out whatever the filter asks for. The cursor of the next page is
+// zero on the last page.
+func (interactor *ProfileInteractor) ShowGames(userId, libraryId int, filter GameFilter, page Page) ([]Game, Cursor, error, int) {
+	filter, err := normalizeGameFilter(filter)
+	if err != nil {
+		return nil, Cursor{}, err, 400
+	}
+	err = validPage(page, maxGamesPerPage)
+	if err != nil {
+		return nil, Cursor{}, err, 400
+	}
+	_, err = page.After.Game(filter.Sort)
+	if err != nil {
+		return nil, Cursor{}, err, 400
+	}
+	user, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, Cursor{}, err, code
+	}
+	library, err, code := interactor.LibraryRepository.FindById(libraryId)
+	if err != nil {
+		return nil, Cursor{}, err, code
+	}
+	allowed, err := interactor.libraryAllows(user.Id, library, LibraryRoleViewer)
+	if err != nil {
+		return nil, Cursor{}, err, 500
+	}
+	if !allowed {
+		message := "User #%d is not allowed to see games in library #%d of user #%d"
+		err := domain.NewError(domain.CodeForbidden, message, user.Id, library.Id, library.User.Id)
+		return nil, Cursor{}, err, 403
+	}
+
+	limit, err := interactor.Parental.RatingLimit(userId)
+	if err != nil {
+		return nil, Cursor{}, err, 500
+	}
+	if limit > 0 && (filter.MaxAge == 0 || filter.MaxAge > limit) {
+		filter.MaxAge = limit
+	}
+	games, err := interactor.GameRepository.FindByLib(libraryId, filter, page.probe())
+	if err != nil {
+		return nil, Cursor{}, err, 500
+	}
+	count, more := page.cut(len(games))
+	games = games[:count]
+	var next Cursor
+	if more {
+		next = gameCursor(games[count-1], filter.Sort)
+	}
+	location := userLocation(interactor.SettingsRepository, userId)
+	for i := range games {
+		games[i].CreatedAt = games[i].CreatedAt.In(location)
+		games[i].UpdatedAt = games[i].UpdatedAt.In(location)
+	}
+	return games, next, nil, 200
+}
+
+// Sort orders of game lists, prefixed with "-" for descending order. Ties
+// are broken by name.
+var gameSorts = map[string]bool{
+	"name":    true,
+	"value":   true,
+	"rating":  true,
+	"status":  true,
+	"updated": true,
+	"rank":    true,
+}
+
+// Game lists continue after the sort value, name and id of the last game of
+// a page, the key holds the value and the name on a line each
+func gameCursor(game Game, sort string) Cursor {
+	var value string
+	switch strings.TrimPrefix(sort, "-") {
+	case "value":
+		value = strconv.FormatFloat(game.Value, 'f', -1, 64)
+	case "rating":
+		value = strconv.Itoa(game.MinAge)
+	case "status":
+		value = game.Status
+	case "updated":
+		value = game.EntryUpdatedAt.UTC().Format(time.RFC3339Nano)
+	case "rank":
+		value = strconv.Itoa(game.WishlistRank)
+	}
+	return Cursor{Key: value + "\n" + game.Name, Id: game.Id}
+}
+
+// The game a cursor made by gameCursor was made from, as far as the list
+// sorted by sort needs it. The zero cursor gives the zero game.
+func (cursor Cursor) Game(sort string) (Game, error) {
+	if cursor.IsZero() {
+		return Game{}, nil
+	}
+	invalid := domain.NewFieldError("cursor", "Cursor is invalid")
+	value, name, found := strings.Cut(cursor.Key, "\n")
+	if !found {
+		return Game{}, invalid
+	}
+	game := Game{Id: cursor.Id, Name: name}
+	var err error
+	switch strings.TrimPrefix(sort, "-") {
+	case "value":
+		game.Value, err = strconv.ParseFloat(value, 64)
+	case "rating":
+		game.MinAge, err = strconv.Atoi(value)
+	case "status":
+		game.Status = value
+	case "updated":
+		game.EntryUpdatedAt, err = time.Parse(time.RFC3339Nano, value)
+	case "rank":
+		game.WishlistRank, err = strconv.Atoi(value)
+	default:
+		if value != "" {
+			err = invalid
+		}
+	}
+	if err != nil {
+		return Game{}, invalid
+	}
+	return game, nil
+}
+
+func normalizeGameFilter(filter GameFilter) (GameFilter, error) {
+	if filter.Rating != "" {
+		rating, known := domain.NormalizeRating(filter.Rating)
+		if !known {
+			return filter, domain.NewFieldError("rating", "Rating '%s' is unknown", filter.Rating)
+		}
+		filter.Rating = rating
+	}
+	if filter.MaxAge < 0 {
+		return filter, domain.NewFieldError("maxAge", "Cannot be negative")
+	}
+	if !printableText(filter.Name) || !printableText(filter.Platform) || !printableText(filter.Tag) {
+		return filter, domain.NewError(domain.CodeInvalid,
+			"Filter is not valid UTF-8 or contains control characters")
+	}
+	filter.Name = strings.TrimSpace(filter.Name)
+	if len(filter.Name) > maxFilterNameLength {
+		return filter, domain.NewFieldError("name", "Must be at most %d characters", maxFilterNameLength)
+	}
+	if filter.Status != "" && !gameStatuses[filter.Status] {
+		return filter, domain.NewFieldError("status", "Status '%s' is unknown", filter.Status)
+	}
+	filter.Platform = strings.TrimSpace(filter.Platform)
+	if len(filter.Platform) > maxPlatformLen {
+		return filter, domain.NewFieldError("platform", "Must be at most %d characters", maxPlatformLen)
+	}
+	filter.Tag = strings.TrimSpace(filter.Tag)
+	if len(filter.Tag) > maxTagLength {
+		return filter, domain.NewFieldError("tag", "Must be at most %d characters", maxTagLength)
+	}
+	if filter.Sort != "" && !gameSorts[strings.TrimPrefix(filter.Sort, "-")] {
+		return filter, domain.NewFieldError("sort", "Cannot sort by '%s'", filter.Sort)
+	}
+	return filter, nil
+}
@@ -0,0 +1,62 @@
+package usecases
+
+import (
+	"testing"
+	"time"
+)
+
+// Filters that pass are printable, within the limits and unchanged when
+// normalized again
+func FuzzNormalizeGameFilter(f *testing.F) {
+	f.Add("Hades", "PC", "roguelike", "", "completed", "-rating", 0)
+	f.Add(" zelda ", "", "", "PEGI 12", "", "name", 12)
+	f.Add("a\x00b", "\xff", "tag\n", "unknown", "nope", "--name", -1)
+	f.Fuzz(func(t *testing.T, name, platform, tag, rating, status, sort string, maxAge int) {
+		filter := GameFilter{Name: name, Platform: platform, Tag: tag, Rating: rating,
+			Status: status, Sort: sort, MaxAge: maxAge}
+		normalized, err := normalizeGameFilter(filter)
+		if err != nil {
+			return
+		}
+		if !printableText(normalized.Name) || !printableText(normalized.Platform) ||
+			!printableText(normalized.Tag) {
+			t.Fatalf("normalizeGameFilter accepted %+v", normalized)
+		}
+		if len(normalized.Name) > maxFilterNameLength || len(normalized.Platform) > maxPlatformLen ||
+			len(normalized.Tag) > maxTagLength {
+			t.Fatalf("normalizeGameFilter passed %+v over the limits", normalized)
+		}
+		again, err := normalizeGameFilter(normalized)
+		if err != nil || again != normalized {
+			t.Fatalf("normalizing %+v again gave %+v, %v", normalized, again, err)
+		}
+	})
+}
+
+// A cursor gives back the sort value, name and id of the game it was made
+// from, whatever the name holds
+func TestGameCursorRoundTrip(t *testing.T) {
+	game := Game{Id: 7, Name: "Baba:\nIs You", Value: 14.99, MinAge: 12, Status: "backlog",
+		WishlistRank: 3, EntryUpdatedAt: time.Date(2026, 3, 1, 12, 0, 0, 123456000, time.UTC)}
+	for _, sort := range []string{"", "name", "-value", "rating", "status", "-updated", "rank"} {
+		token := gameCursor(game, sort).String()
+		cursor, err := ParseCursor(token)
+		if err != nil {
+			t.Fatalf("ParseCursor of the %q cursor: %v", sort, err)
+		}
+		found, err := cursor.Game(sort)
+		if err != nil {
+			t.Fatalf("Game of the %q cursor: %v", sort, err)
+		}
+		if found.Id != game.Id || found.Name != game.Name {
+			t.Fatalf("The %q cursor gave %+v", sort, found)
+		}
+		if gameCursor(found, sort) != gameCursor(game, sort) {
+			t.Fatalf("The %q cursor lost the sort value: %+v", sort, found)
+		}
+	}
+	_, err := Cursor{Key: "Hades", Id: 1}.Game("-value")
+	if err == nil {
+		t.Fatalf("A cursor without a sort value was accepted for -value")
+	}
+}
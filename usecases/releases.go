@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"time"
+
+	"game-tracker/domain"
+)
+
+const (
+	ReleaseManual   = "manual"
+	ReleaseProvider = "provider"
+)
+
+const maxAnnouncementDelay = 7 * 24 * time.Hour
+
+// Looks up release dates and content ratings of games by name
+type MetadataProvider interface {
+	ReleaseDate(gameName string) (time.Time, bool, error)
+	Rating(gameName string) (string, bool, error)
+}
+
+func (interactor *CalendarInteractor) publish(event domain.Event) {
+	if interactor.EventBus != nil {
+		interactor.EventBus.Publish(event)
+	}
+}
+
+// Run by the release job: dates from the provider are refreshed, users are
+// told when one moves, and releases that arrived are announced once
+func (interactor *CalendarInteractor) CheckReleases() error {
+	releases, err := interactor.ReleaseRepository.FindUnannounced()
+	if err != nil {
+		return err
+	}
+	// Users tracking the same game share one lookup
+	dates := make(map[int]time.Time)
+	for _, release := range releases {
+		if release.Source == ReleaseProvider && interactor.MetadataProvider != nil {
+			release, err = interactor.refreshRelease(release, dates)
+			if err != nil {
+				interactor.logf("Cannot refresh release of game #%d: %v", release.GameId, err)
+			}
+		}
+		if release.Date.After(today()) {
+			continue
+		}
+		err = interactor.ReleaseRepository.MarkAnnounced(release.UserId, release.GameId)
+		if err != nil {
+			return err
+		}
+		// Games tracked long after they came out are not news
+		if release.Date.Before(today().Add(-maxAnnouncementDelay)) {
+			continue
+		}
+		interactor.publish(domain.Event{Name: domain.EventReleaseLaunched, UserId: release.UserId,
+			EntityId: release.GameId, Payload: map[string]string{"name": release.GameName}})
+	}
+	return nil
+}
+
+func (interactor *CalendarInteractor) refreshRelease(release Release, dates map[int]time.Time) (Release, error) {
+	date, known := dates[release.GameId]
+	if !known {
+		var found bool
+		var err error
+		date, found, err = interactor.MetadataProvider.ReleaseDate(release.GameName)
+		if err != nil || !found {
+			return release, err
+		}
+		dates[release.GameId] = date
+	}
+	if date.Equal(release.Date) {
+		return release, nil
+	}
+
+	previous := release.Date
+	release.Date = date
+	err := interactor.ReleaseRepository.Store(release)
+	if err != nil {
+		return release, err
+	}
+	interactor.publish(domain.Event{Name: domain.EventReleaseMoved, UserId: release.UserId,
+		EntityId: release.GameId, Payload: map[string]string{"name": release.GameName,
+			"from": previous.Format("2006-01-02"), "to": date.Format("2006-01-02")}})
+	return release, nil
+}
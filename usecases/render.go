@@ -0,0 +1,69 @@
+package usecases
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"game-tracker/domain"
+)
+
+// Turns Markdown into HTML that is safe to show as it is
+type MarkdownRenderer interface {
+	Render(source string) (string, error)
+}
+
+// Without a renderer the text is only escaped, paragraphs are kept
+func renderMarkdown(renderer MarkdownRenderer, source string) (string, error) {
+	if source == "" {
+		return "", nil
+	}
+	if renderer != nil {
+		return renderer.Render(source)
+	}
+	var paragraphs []string
+	for _, paragraph := range strings.Split(source, "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			paragraphs = append(paragraphs, "<p>"+html.EscapeString(paragraph)+"</p>")
+		}
+	}
+	return strings.Join(paragraphs, "\n"), nil
+}
+
+// Entries written before rendering or spoilers existed are rendered on first
+// read and stored, so lists do not render them again
+func renderJournal(renderer MarkdownRenderer, repo JournalRepository, entries []JournalEntry) error {
+	for i, entry := range entries {
+		if entry.Text == "" || (entry.Html != "" && entry.SpoilerFreeHtml != "") {
+			continue
+		}
+		var err error
+		entry.Html, entry.SpoilerFreeHtml, entry.HasSpoilers, err = renderWithSpoilers(renderer, entry.Text)
+		if err != nil {
+			return err
+		}
+		err = repo.StoreHtml(entry)
+		if err != nil {
+			return err
+		}
+		entries[i] = entry
+	}
+	return nil
+}
+
+// Previews what a text will look like once saved, spoilers included
+type RenderInteractor struct {
+	Renderer MarkdownRenderer
+}
+
+func (interactor *RenderInteractor) Render(source string) (string, error, int) {
+	if len(source) > maxJournalTextLength {
+		return "", domain.NewFieldError("text", "Must be at most %d characters",
+			maxJournalTextLength), 400
+	}
+	rendered, _, _, err := renderWithSpoilers(interactor.Renderer, source)
+	if err != nil {
+		return "", fmt.Errorf("Cannot render text: %v", err), 500
+	}
+	return rendered, nil, 200
+}
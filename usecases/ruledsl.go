@@ -0,0 +1,382 @@
+package usecases
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"game-tracker/domain"
+)
+
+// The language of automation rules. A condition compares fields of the
+// event and combines comparisons with and, or, not and parentheses:
+//
+//	status == "completed" and not (name contains "demo")
+//
+// Comparisons are ==, !=, contains, <, <=, > and >=. Text compares ignoring
+// case, the ordering operators compare numbers and are false for anything
+// else. An empty condition always matches.
+//
+// Actions are separated by semicolons or new lines, an argument is quoted and
+// {field} in it is replaced by the field of the event:
+//
+//	set_status "completed"; notify "Finished {name}"
+
+const (
+	ruleTokenIdent = iota
+	ruleTokenString
+	ruleTokenNumber
+	ruleTokenOperator
+	ruleTokenEnd
+)
+
+type ruleToken struct {
+	kind int
+	text string
+	at   int //Character the token starts at, from 1
+}
+
+var ruleOperators = []string{"==", "!=", "<=", ">=", "<", ">", "(", ")", ";"}
+
+// Splits source into tokens, new lines separate actions like semicolons
+// when separators is set and are plain space otherwise
+func lexRule(field, source string, separators bool) ([]ruleToken, error) {
+	var tokens []ruleToken
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '\n' && separators:
+			tokens = append(tokens, ruleToken{ruleTokenOperator, ";", i + 1})
+			i++
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n':
+			i++
+		case r == '"':
+			var text strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '"'; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				text.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, domain.NewFieldError(field, "Unterminated text at character %d", i+1)
+			}
+			tokens = append(tokens, ruleToken{ruleTokenString, text.String(), i + 1})
+			i = j + 1
+		case r >= '0' && r <= '9' || r == '-':
+			j := i + 1
+			for j < len(runes) && (runes[j] >= '0' && runes[j] <= '9' || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, ruleToken{ruleTokenNumber, string(runes[i:j]), i + 1})
+			i = j
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+			j := i + 1
+			for j < len(runes) && (runes[j] == '_' || runes[j] >= 'a' && runes[j] <= 'z' ||
+				runes[j] >= 'A' && runes[j] <= 'Z' || runes[j] >= '0' && runes[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, ruleToken{ruleTokenIdent, string(runes[i:j]), i + 1})
+			i = j
+		default:
+			operator := ""
+			for _, candidate := range ruleOperators {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					operator = candidate
+					break
+				}
+			}
+			if operator == "" {
+				return nil, domain.NewFieldError(field, "Unexpected '%s' at character %d", string(r), i+1)
+			}
+			tokens = append(tokens, ruleToken{ruleTokenOperator, operator, i + 1})
+			i += len(operator)
+		}
+	}
+	return append(tokens, ruleToken{ruleTokenEnd, "", len(runes) + 1}), nil
+}
+
+type ruleCondition interface {
+	matches(fields map[string]string) bool
+}
+
+type ruleAnd struct{ left, right ruleCondition }
+type ruleOr struct{ left, right ruleCondition }
+type ruleNot struct{ inner ruleCondition }
+type ruleAlways struct{}
+
+type ruleComparison struct {
+	field    string
+	operator string
+	value    string
+}
+
+func (condition ruleAnd) matches(fields map[string]string) bool {
+	return condition.left.matches(fields) && condition.right.matches(fields)
+}
+
+func (condition ruleOr) matches(fields map[string]string) bool {
+	return condition.left.matches(fields) || condition.right.matches(fields)
+}
+
+func (condition ruleNot) matches(fields map[string]string) bool {
+	return !condition.inner.matches(fields)
+}
+
+func (ruleAlways) matches(fields map[string]string) bool {
+	return true
+}
+
+func (comparison ruleComparison) matches(fields map[string]string) bool {
+	value := fields[comparison.field]
+	switch comparison.operator {
+	case "==":
+		return strings.EqualFold(value, comparison.value)
+	case "!=":
+		return !strings.EqualFold(value, comparison.value)
+	case "contains":
+		return strings.Contains(strings.ToLower(value), strings.ToLower(comparison.value))
+	}
+	left, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	right, err := strconv.ParseFloat(comparison.value, 64)
+	if err != nil {
+		return false
+	}
+	switch comparison.operator {
+	case "<":
+		return left < right
+	case "<=":
+		return left <= right
+	case ">":
+		return left > right
+	}
+	return left >= right
+}
+
+type ruleParser struct {
+	field  string
+	tokens []ruleToken
+	at     int
+	known  map[string]bool //Fields the event has
+}
+
+func (parser *ruleParser) peek() ruleToken {
+	return parser.tokens[parser.at]
+}
+
+func (parser *ruleParser) next() ruleToken {
+	token := parser.tokens[parser.at]
+	if token.kind != ruleTokenEnd {
+		parser.at++
+	}
+	return token
+}
+
+func (parser *ruleParser) unexpected(token ruleToken) error {
+	if token.kind == ruleTokenEnd {
+		return domain.NewFieldError(parser.field, "Unexpected end at character %d", token.at)
+	}
+	return domain.NewFieldError(parser.field, "Unexpected '%s' at character %d", token.text, token.at)
+}
+
+func (parser *ruleParser) keyword(word string) bool {
+	token := parser.peek()
+	if token.kind == ruleTokenIdent && strings.EqualFold(token.text, word) {
+		parser.next()
+		return true
+	}
+	return false
+}
+
+// Parses a condition against the fields of an event
+func parseCondition(source string, fields []string) (ruleCondition, error) {
+	if strings.TrimSpace(source) == "" {
+		return ruleAlways{}, nil
+	}
+	tokens, err := lexRule("condition", source, false)
+	if err != nil {
+		return nil, err
+	}
+	parser := &ruleParser{field: "condition", tokens: tokens, known: make(map[string]bool)}
+	for _, field := range fields {
+		parser.known[field] = true
+	}
+	condition, err := parser.or()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != ruleTokenEnd {
+		return nil, parser.unexpected(token)
+	}
+	return condition, nil
+}
+
+func (parser *ruleParser) or() (ruleCondition, error) {
+	left, err := parser.and()
+	if err != nil {
+		return nil, err
+	}
+	for parser.keyword("or") {
+		right, err := parser.and()
+		if err != nil {
+			return nil, err
+		}
+		left = ruleOr{left, right}
+	}
+	return left, nil
+}
+
+func (parser *ruleParser) and() (ruleCondition, error) {
+	left, err := parser.unary()
+	if err != nil {
+		return nil, err
+	}
+	for parser.keyword("and") {
+		right, err := parser.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = ruleAnd{left, right}
+	}
+	return left, nil
+}
+
+func (parser *ruleParser) unary() (ruleCondition, error) {
+	if parser.keyword("not") {
+		inner, err := parser.unary()
+		if err != nil {
+			return nil, err
+		}
+		return ruleNot{inner}, nil
+	}
+	token := parser.next()
+	if token.kind == ruleTokenOperator && token.text == "(" {
+		inner, err := parser.or()
+		if err != nil {
+			return nil, err
+		}
+		if closing := parser.next(); closing.text != ")" || closing.kind != ruleTokenOperator {
+			return nil, parser.unexpected(closing)
+		}
+		return inner, nil
+	}
+	if token.kind != ruleTokenIdent {
+		return nil, parser.unexpected(token)
+	}
+	if !parser.known[token.text] {
+		return nil, domain.NewFieldError(parser.field, "Unknown field '%s' at character %d", token.text,
+			token.at)
+	}
+
+	operator := parser.next()
+	switch {
+	case operator.kind == ruleTokenIdent && strings.EqualFold(operator.text, "contains"):
+		operator.text = "contains"
+	case operator.kind != ruleTokenOperator || operator.text == "(" || operator.text == ")" ||
+		operator.text == ";":
+		return nil, parser.unexpected(operator)
+	}
+	value := parser.next()
+	if value.kind != ruleTokenString && value.kind != ruleTokenNumber {
+		return nil, parser.unexpected(value)
+	}
+	return ruleComparison{field: token.text, operator: operator.text, value: value.text}, nil
+}
+
+// An action of a rule, Argument is the quoted text before fields are filled in
+type RuleAction struct {
+	Name     string
+	Argument string
+}
+
+const (
+	RuleActionNotify             = "notify"               //Sends the argument as a notification
+	RuleActionSetStatus          = "set_status"           //Sets the status of the event's game
+	RuleActionRemoveFromWishlist = "remove_from_wishlist" //Removes the game from the user's other libraries where it is wishlisted
+)
+
+// Whether each action takes an argument and needs the event to be about a
+// game in a library
+var ruleActionKinds = map[string]struct{ argument, game bool }{
+	RuleActionNotify:             {true, false},
+	RuleActionSetStatus:          {true, true},
+	RuleActionRemoveFromWishlist: {false, true},
+}
+
+func parseActions(source string, gameEvent bool) ([]RuleAction, error) {
+	tokens, err := lexRule("actions", source, true)
+	if err != nil {
+		return nil, err
+	}
+	parser := &ruleParser{field: "actions", tokens: tokens}
+	var actions []RuleAction
+	for {
+		for parser.peek().kind == ruleTokenOperator && parser.peek().text == ";" {
+			parser.next()
+		}
+		token := parser.next()
+		if token.kind == ruleTokenEnd {
+			break
+		}
+		if token.kind != ruleTokenIdent {
+			return nil, parser.unexpected(token)
+		}
+		kind, found := ruleActionKinds[token.text]
+		if !found {
+			return nil, domain.NewFieldError("actions", "Unknown action '%s' at character %d", token.text,
+				token.at)
+		}
+		if kind.game && !gameEvent {
+			return nil, domain.NewFieldError("actions", "Action %s needs an event about a game in a library",
+				token.text)
+		}
+		action := RuleAction{Name: token.text}
+		if kind.argument {
+			argument := parser.next()
+			if argument.kind != ruleTokenString {
+				return nil, domain.NewFieldError("actions", "Action %s needs a quoted argument", token.text)
+			}
+			action.Argument = argument.text
+		}
+		if separator := parser.peek(); separator.kind != ruleTokenEnd && separator.text != ";" {
+			return nil, parser.unexpected(separator)
+		}
+		actions = append(actions, action)
+	}
+	if len(actions) == 0 || len(actions) > maxRuleActions {
+		return nil, domain.NewFieldError("actions", "Between 1 and %d actions are allowed", maxRuleActions)
+	}
+	for _, action := range actions {
+		if action.Name == RuleActionSetStatus && !gameStatuses[action.Argument] {
+			return nil, domain.NewFieldError("actions", "Status '%s' is unknown", action.Argument)
+		}
+	}
+	return actions, nil
+}
+
+var ruleFieldPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// The argument with {field} replaced by the fields of the event, unknown
+// fields are left as they are
+func (action RuleAction) expand(fields map[string]string) string {
+	return ruleFieldPattern.ReplaceAllStringFunc(action.Argument, func(match string) string {
+		value, found := fields[match[1:len(match)-1]]
+		if !found {
+			return match
+		}
+		return value
+	})
+}
+
+// How the action reads with its fields filled in, as listed in runs
+func (action RuleAction) describe(fields map[string]string) string {
+	if action.Argument == "" && !ruleActionKinds[action.Name].argument {
+		return action.Name
+	}
+	return action.Name + " " + strconv.Quote(action.expand(fields))
+}
@@ -0,0 +1,416 @@
+package usecases
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"game-tracker/domain"
+)
+
+const (
+	maxRules          = 50 //Per user
+	maxRuleActions    = 5
+	maxRuleNameLength = 100
+	maxRuleSource     = 1000 //Characters of a condition or of the actions
+	maxRuleDepth      = 3    //Rules fired by events of rules fired by events of rules
+	keptRuleRuns      = 50   //Per rule, older runs are dropped
+)
+
+type RuleRepository interface {
+	Store(rule Rule) (int, error)
+	Update(rule Rule) error
+	FindById(id int) (Rule, error, int)
+	FindByUser(userId int) ([]Rule, error) //Oldest first
+	FindEnabled(userId int, event string) ([]Rule, error)
+	Remove(rule Rule) error
+	RemoveAll(userId int) error
+	StoreRun(run RuleRun, keep int) error              //Drops all but the keep latest runs of the rule
+	FindRuns(ruleId int, limit int) ([]RuleRun, error) //Latest first
+}
+
+// When Event happens and Condition holds, Actions are run. A dry run rule
+// only records what it would have done.
+type Rule struct {
+	Id        int
+	UserId    int
+	Name      string
+	Event     string
+	Condition string
+	Actions   string
+	Enabled   bool
+	DryRun    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// What a rule did, or would have done, for one event
+type RuleRun struct {
+	Id        int
+	RuleId    int
+	UserId    int
+	Event     string
+	EntityId  int
+	Actions   []string //As described by RuleAction, with fields filled in
+	DryRun    bool
+	Error     string //Of the action that failed, the actions after it were not run
+	CreatedAt time.Time
+}
+
+// The outcome of trying a rule on a made up event
+type RuleTest struct {
+	Matched bool
+	Actions []string
+}
+
+// Events rules can react to and the fields their conditions can compare.
+// Game events are about a game in a library and allow the game actions.
+var ruleEvents = map[string]struct {
+	fields []string
+	game   bool
+}{
+	domain.EventGameAdded:         {[]string{"gameId", "name", "libraryId"}, true},
+	domain.EventGameStatusChanged: {[]string{"gameId", "name", "status", "libraryId"}, true},
+	domain.EventGameRemoved:       {[]string{"gameId", "name", "libraryId"}, false},
+	domain.EventSessionAdded:      {[]string{"gameId", "name", "minutes"}, false},
+	domain.EventBadgeEarned:       {[]string{"badge", "name"}, false},
+	domain.EventPersonalBest:      {[]string{"name", "category", "time", "improvement"}, false},
+	domain.EventReleaseLaunched:   {[]string{"gameId", "name"}, false},
+	domain.EventWebhookReceived:   {[]string{"name", "message"}, false},
+}
+
+// Counts the rules and scripts running per user, events published by their
+// actions are handled before the action returns
+type ruleDepth struct {
+	mu    sync.Mutex
+	users map[int]int
+}
+
+// Counts an event of the user as handled, false when the user's rules are
+// already maxRuleDepth events deep
+func (depth *ruleDepth) enter(userId int) bool {
+	depth.mu.Lock()
+	defer depth.mu.Unlock()
+	if depth.users[userId] >= maxRuleDepth {
+		return false
+	}
+	depth.users[userId]++
+	return true
+}
+
+func (depth *ruleDepth) leave(userId int) {
+	depth.mu.Lock()
+	defer depth.mu.Unlock()
+	depth.users[userId]--
+	if depth.users[userId] == 0 {
+		delete(depth.users, userId)
+	}
+}
+
+type RuleInteractor struct {
+	RuleRepository        RuleRepository
+	UserRepository        UserRepository
+	GameRepository        GameRepository
+	PlaySessionRepository PlaySessionRepository
+	Profile               ProfileInteractor //Game actions go through it so they are checked as any other
+	EventBus              domain.EventBus
+	depth                 *ruleDepth
+	logging
+}
+
+func (interactor *RuleInteractor) Subscribe(bus domain.EventBus) {
+	interactor.depth = &ruleDepth{users: make(map[int]int)}
+	for event := range ruleEvents {
+		bus.Subscribe(event, interactor.handleEvent)
+	}
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.RuleRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove rules of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+func validRule(rule Rule) (Rule, error) {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" || utf8.RuneCountInString(rule.Name) > maxRuleNameLength {
+		return rule, domain.NewFieldError("name", "Must be between 1 and %d characters", maxRuleNameLength)
+	}
+	event, found := ruleEvents[rule.Event]
+	if !found {
+		return rule, domain.NewFieldError("event", "Rules cannot react to '%s'", rule.Event)
+	}
+	if utf8.RuneCountInString(rule.Condition) > maxRuleSource {
+		return rule, domain.NewFieldError("condition", "Must be at most %d characters", maxRuleSource)
+	}
+	if utf8.RuneCountInString(rule.Actions) > maxRuleSource {
+		return rule, domain.NewFieldError("actions", "Must be at most %d characters", maxRuleSource)
+	}
+	_, err := parseCondition(rule.Condition, event.fields)
+	if err != nil {
+		return rule, err
+	}
+	_, err = parseActions(rule.Actions, event.game)
+	return rule, err
+}
+
+func (interactor *RuleInteractor) AddRule(userId int, rule Rule) (Rule, error, int) {
+	rule, err := validRule(rule)
+	if err != nil {
+		return Rule{}, err, 400
+	}
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return Rule{}, err, code
+	}
+	rules, err := interactor.RuleRepository.FindByUser(userId)
+	if err != nil {
+		return Rule{}, err, 500
+	}
+	if len(rules) >= maxRules {
+		return Rule{}, domain.NewError(domain.CodeConflict,
+			"User #%d already has %d rules, remove one first", userId, maxRules), 409
+	}
+	rule.UserId = userId
+	rule.Id, err = interactor.RuleRepository.Store(rule)
+	if err != nil {
+		return Rule{}, err, 500
+	}
+	interactor.logf("User #%d added rule #%d", userId, rule.Id)
+	return interactor.RuleRepository.FindById(rule.Id)
+}
+
+func (interactor *RuleInteractor) ShowRules(userId int) ([]Rule, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	rules, err := interactor.RuleRepository.FindByUser(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	return rules, nil, 200
+}
+
+func (interactor *RuleInteractor) ownRule(userId, ruleId int) (Rule, error, int) {
+	rule, err, code := interactor.RuleRepository.FindById(ruleId)
+	if err != nil {
+		return Rule{}, err, code
+	}
+	if rule.UserId != userId {
+		return Rule{}, domain.NewError(domain.CodeNotFound, "Rule #%d does not exist", ruleId), 404
+	}
+	return rule, nil, 200
+}
+
+// Replaces everything but the owner of the rule
+func (interactor *RuleInteractor) EditRule(userId, ruleId int, changed Rule) (Rule, error, int) {
+	rule, err, code := interactor.ownRule(userId, ruleId)
+	if err != nil {
+		return Rule{}, err, code
+	}
+	changed, err = validRule(changed)
+	if err != nil {
+		return Rule{}, err, 400
+	}
+	changed.Id, changed.UserId = rule.Id, rule.UserId
+	err = interactor.RuleRepository.Update(changed)
+	if err != nil {
+		return Rule{}, err, 500
+	}
+	interactor.logf("User #%d edited rule #%d", userId, ruleId)
+	return interactor.RuleRepository.FindById(ruleId)
+}
+
+func (interactor *RuleInteractor) RemoveRule(userId, ruleId int) (error, int) {
+	rule, err, code := interactor.ownRule(userId, ruleId)
+	if err != nil {
+		return err, code
+	}
+	err = interactor.RuleRepository.Remove(rule)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("User #%d removed rule #%d", userId, ruleId)
+	return nil, 200
+}
+
+func (interactor *RuleInteractor) ShowRuns(userId, ruleId int) ([]RuleRun, error, int) {
+	_, err, code := interactor.ownRule(userId, ruleId)
+	if err != nil {
+		return nil, err, code
+	}
+	runs, err := interactor.RuleRepository.FindRuns(ruleId, keptRuleRuns)
+	if err != nil {
+		return nil, err, 500
+	}
+	return runs, nil, 200
+}
+
+// Checks a rule against event fields given by hand without running or
+// storing anything, so rules can be tried before they are saved
+func (interactor *RuleInteractor) TestRule(userId int, rule Rule, fields map[string]string) (RuleTest, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return RuleTest{}, err, code
+	}
+	rule.Name = "test"
+	_, err = validRule(rule)
+	if err != nil {
+		return RuleTest{}, err, 400
+	}
+	event := ruleEvents[rule.Event]
+	condition, _ := parseCondition(rule.Condition, event.fields)
+	actions, _ := parseActions(rule.Actions, event.game)
+	test := RuleTest{Matched: condition.matches(fields)}
+	if test.Matched {
+		for _, action := range actions {
+			test.Actions = append(test.Actions, action.describe(fields))
+		}
+	}
+	return test, nil, 200
+}
+
+// The fields conditions see, the game's name is looked up for events that
+// only carry its id
+func (interactor *RuleInteractor) eventFields(event domain.Event) map[string]string {
+	fields := make(map[string]string)
+	for key, value := range event.Payload {
+		fields[key] = value
+	}
+	switch event.Name {
+	case domain.EventSessionAdded:
+		session, err, _ := interactor.PlaySessionRepository.FindById(event.EntityId)
+		if err == nil {
+			fields["gameId"], fields["name"] = session.GameExternalId, session.GameName
+			fields["minutes"] = strconv.Itoa(session.Minutes)
+		}
+	case domain.EventGameAdded, domain.EventGameStatusChanged, domain.EventGameRemoved,
+		domain.EventReleaseLaunched:
+		game, err, _ := interactor.GameRepository.FindById(event.EntityId)
+		if err == nil {
+			fields["gameId"] = game.ExternalId
+			if fields["name"] == "" {
+				fields["name"] = game.Name
+			}
+		}
+	}
+	return fields
+}
+
+func (interactor *RuleInteractor) handleEvent(event domain.Event) {
+	if !interactor.depth.enter(event.UserId) {
+		interactor.logf("Rules of user #%d went %d events deep, the event is not handled",
+			event.UserId, maxRuleDepth)
+		return
+	}
+	defer interactor.depth.leave(event.UserId)
+
+	rules, err := interactor.RuleRepository.FindEnabled(event.UserId, event.Name)
+	if err != nil {
+		interactor.logf("Cannot load rules of user #%d: %v", event.UserId, err)
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+	fields := interactor.eventFields(event)
+	for _, rule := range rules {
+		interactor.fire(rule, event, fields)
+	}
+}
+
+// Runs the actions of a rule whose condition holds, rules stored before a
+// change of the language that no longer parse are skipped
+func (interactor *RuleInteractor) fire(rule Rule, event domain.Event, fields map[string]string) {
+	kind := ruleEvents[rule.Event]
+	condition, err := parseCondition(rule.Condition, kind.fields)
+	if err != nil {
+		interactor.logf("Rule #%d no longer parses: %v", rule.Id, err)
+		return
+	}
+	if !condition.matches(fields) {
+		return
+	}
+	actions, err := parseActions(rule.Actions, kind.game)
+	if err != nil {
+		interactor.logf("Rule #%d no longer parses: %v", rule.Id, err)
+		return
+	}
+
+	run := RuleRun{RuleId: rule.Id, UserId: rule.UserId, Event: event.Name, EntityId: event.EntityId,
+		DryRun: rule.DryRun, CreatedAt: time.Now().UTC()}
+	for _, action := range actions {
+		run.Actions = append(run.Actions, action.describe(fields))
+		if rule.DryRun {
+			continue
+		}
+		err, _ = interactor.runAction(rule.UserId, rule.Name, action, event, fields)
+		if err != nil {
+			run.Error = err.Error()
+			break
+		}
+	}
+	err = interactor.RuleRepository.StoreRun(run, keptRuleRuns)
+	if err != nil {
+		interactor.logf("Cannot record run of rule #%d: %v", rule.Id, err)
+	}
+}
+
+// Runs an action for the user, name is the rule or script it came from
+func (interactor *RuleInteractor) runAction(userId int, name string, action RuleAction, event domain.Event, fields map[string]string) (error, int) {
+	switch action.Name {
+	case RuleActionNotify:
+		// Notification channels such as Discord get it along with the inbox
+		if interactor.EventBus != nil {
+			interactor.EventBus.Publish(domain.Event{Name: domain.EventRuleFired, UserId: userId,
+				Payload: map[string]string{"name": name, "message": action.expand(fields)}})
+		}
+		return nil, 200
+	case RuleActionSetStatus:
+		libraryId, err := strconv.Atoi(fields["libraryId"])
+		if err != nil {
+			return domain.NewError(domain.CodeInvalid, "Event %s names no library", event.Name), 400
+		}
+		status := action.Argument
+		items, err, code := interactor.Profile.UpdateGames(userId, libraryId, []int{event.EntityId},
+			GameChange{Status: &status}, nil)
+		if err != nil {
+			return err, code
+		}
+		for _, item := range items {
+			if item.Error != nil {
+				return item.Error, item.Code
+			}
+		}
+		return nil, 200
+	case RuleActionRemoveFromWishlist:
+		user, err, code := interactor.UserRepository.FindById(userId)
+		if err != nil {
+			return err, code
+		}
+		for _, libraryId := range user.LibraryIds {
+			if strconv.Itoa(libraryId) == fields["libraryId"] {
+				continue
+			}
+			game, err, code := interactor.GameRepository.FindInLib(event.EntityId, libraryId)
+			if code == 404 {
+				continue
+			}
+			if err != nil {
+				return err, code
+			}
+			if game.Status != "wishlist" {
+				continue
+			}
+			err, code = interactor.Profile.RemoveGame(userId, libraryId, event.EntityId, 0)
+			if err != nil {
+				return err, code
+			}
+		}
+		return nil, 200
+	}
+	return domain.NewError(domain.CodeInvalid, "Unknown action '%s'", action.Name), 400
+}
@@ -0,0 +1,291 @@
+package usecases
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"game-tracker/domain"
+)
+
+const (
+	maxScriptSource   = 20000 //Characters
+	maxScriptSteps    = 100000
+	scriptTimeout     = 250 * time.Millisecond
+	maxScriptCalls    = 10   //Actions a script may take per event
+	maxScriptArgument = 1000 //Characters of an action's argument
+)
+
+type ScriptRepository interface {
+	Store(script Script) (int, error)
+	Update(script Script) error
+	FindById(id int) (Script, error, int)
+	FindAll() ([]Script, error)
+	FindEnabled(event string) ([]Script, error)
+	Remove(id int) error
+	RemoveByUser(userId int) error
+}
+
+// A Starlark script admins bind to an event for rules the rule language
+// cannot express. It defines handle(event), event is a dict of the fields
+// rules see and the actions of rules are its functions.
+type Script struct {
+	Id        int
+	Name      string
+	Event     string
+	Source    string
+	UserId    int //Whose events the script handles, 0 for every user
+	Enabled   bool
+	CreatedBy int //The admin the actions of the script are audited under
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Runs scripts in a sandbox, implemented by infrastructure
+type ScriptEngine interface {
+	// Compiles the source with only builtins defined, it must define handle
+	Check(name, source string, builtins []string) error
+	Run(name, source string, call ScriptCall) error
+}
+
+// A function scripts call with their string arguments
+type ScriptBuiltin func(args []string) error
+
+// One run of a script, the engine stops it after Steps steps or Timeout
+type ScriptCall struct {
+	Event    map[string]string
+	Builtins map[string]ScriptBuiltin
+	Print    func(message string)
+	Steps    uint64
+	Timeout  time.Duration
+}
+
+type ScriptInteractor struct {
+	ScriptRepository ScriptRepository
+	AdminRepository  AdminRepository
+	Engine           ScriptEngine //Nil turns scripts off
+	Admin            AdminInteractor
+	Rules            RuleInteractor //Scripts see the fields and take the actions of rules
+	logging
+}
+
+// Must come after the rules subscribed, scripts and rules share their depth
+// so they cannot fire each other forever
+func (interactor *ScriptInteractor) Subscribe(bus domain.EventBus) {
+	if interactor.Engine == nil {
+		return
+	}
+	for event := range ruleEvents {
+		bus.Subscribe(event, interactor.handleEvent)
+	}
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.ScriptRepository.RemoveByUser(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove scripts of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+// The functions a script bound to event can call
+func scriptBuiltins(event string) []string {
+	var builtins []string
+	for name, kind := range ruleActionKinds {
+		if !kind.game || ruleEvents[event].game {
+			builtins = append(builtins, name)
+		}
+	}
+	return builtins
+}
+
+func (interactor *ScriptInteractor) validScript(script Script) (Script, error, int) {
+	if interactor.Engine == nil {
+		return script, domain.NewError(domain.CodeUnavailable, "Scripts are not enabled"), 503
+	}
+	script.Name = strings.TrimSpace(script.Name)
+	if script.Name == "" || utf8.RuneCountInString(script.Name) > maxRuleNameLength {
+		return script, domain.NewFieldError("name", "Must be between 1 and %d characters",
+			maxRuleNameLength), 400
+	}
+	if _, found := ruleEvents[script.Event]; !found {
+		return script, domain.NewFieldError("event", "Scripts cannot react to '%s'", script.Event), 400
+	}
+	if utf8.RuneCountInString(script.Source) > maxScriptSource {
+		return script, domain.NewFieldError("source", "Must be at most %d characters", maxScriptSource), 400
+	}
+	err := interactor.Engine.Check(script.Name, script.Source, scriptBuiltins(script.Event))
+	if err != nil {
+		return script, domain.NewFieldError("source", "Does not compile: %s", err.Error()), 400
+	}
+	return script, nil, 200
+}
+
+func (interactor *ScriptInteractor) audit(adminId, targetId int, format string, args ...interface{}) error {
+	return interactor.AdminRepository.Audit(AuditEntry{ActorId: adminId, Action: AuditScript,
+		TargetId: targetId, Detail: fmt.Sprintf(format, args...)})
+}
+
+func (interactor *ScriptInteractor) AddScript(adminId int, script Script) (Script, error, int) {
+	err, code := interactor.Admin.Authorize(adminId)
+	if err != nil {
+		return Script{}, err, code
+	}
+	script, err, code = interactor.validScript(script)
+	if err != nil {
+		return Script{}, err, code
+	}
+	script.CreatedBy = adminId
+	script.Id, err = interactor.ScriptRepository.Store(script)
+	if err != nil {
+		return Script{}, err, 500
+	}
+	err = interactor.audit(adminId, script.UserId, "added script #%d %s on %s", script.Id, script.Name,
+		script.Event)
+	if err != nil {
+		return Script{}, err, 500
+	}
+	return interactor.ScriptRepository.FindById(script.Id)
+}
+
+func (interactor *ScriptInteractor) ShowScripts(adminId int) ([]Script, error, int) {
+	err, code := interactor.Admin.Authorize(adminId)
+	if err != nil {
+		return nil, err, code
+	}
+	scripts, err := interactor.ScriptRepository.FindAll()
+	if err != nil {
+		return nil, err, 500
+	}
+	return scripts, nil, 200
+}
+
+// Replaces everything but who added the script, its actions are audited
+// under the admin who edited it last
+func (interactor *ScriptInteractor) EditScript(adminId, scriptId int, changed Script) (Script, error, int) {
+	err, code := interactor.Admin.Authorize(adminId)
+	if err != nil {
+		return Script{}, err, code
+	}
+	_, err, code = interactor.ScriptRepository.FindById(scriptId)
+	if err != nil {
+		return Script{}, err, code
+	}
+	changed, err, code = interactor.validScript(changed)
+	if err != nil {
+		return Script{}, err, code
+	}
+	changed.Id, changed.CreatedBy = scriptId, adminId
+	err = interactor.ScriptRepository.Update(changed)
+	if err != nil {
+		return Script{}, err, 500
+	}
+	err = interactor.audit(adminId, changed.UserId, "edited script #%d %s on %s enabled=%t", scriptId,
+		changed.Name, changed.Event, changed.Enabled)
+	if err != nil {
+		return Script{}, err, 500
+	}
+	return interactor.ScriptRepository.FindById(scriptId)
+}
+
+func (interactor *ScriptInteractor) RemoveScript(adminId, scriptId int) (error, int) {
+	err, code := interactor.Admin.Authorize(adminId)
+	if err != nil {
+		return err, code
+	}
+	script, err, code := interactor.ScriptRepository.FindById(scriptId)
+	if err != nil {
+		return err, code
+	}
+	err = interactor.ScriptRepository.Remove(scriptId)
+	if err != nil {
+		return err, 500
+	}
+	err = interactor.audit(adminId, script.UserId, "removed script #%d %s", scriptId, script.Name)
+	if err != nil {
+		return err, 500
+	}
+	return nil, 200
+}
+
+func (interactor *ScriptInteractor) handleEvent(event domain.Event) {
+	if !interactor.Rules.depth.enter(event.UserId) {
+		interactor.logf("Rules of user #%d went %d events deep, the event is not handled",
+			event.UserId, maxRuleDepth)
+		return
+	}
+	defer interactor.Rules.depth.leave(event.UserId)
+
+	scripts, err := interactor.ScriptRepository.FindEnabled(event.Name)
+	if err != nil {
+		interactor.logf("Cannot load scripts for %s: %v", event.Name, err)
+		return
+	}
+	var fields map[string]string
+	for _, script := range scripts {
+		if script.UserId != 0 && script.UserId != event.UserId {
+			continue
+		}
+		if fields == nil {
+			fields = interactor.Rules.eventFields(event)
+		}
+		interactor.run(script, event, fields)
+	}
+}
+
+// Runs a script on an event, every action it takes and its failure are
+// written to the audit log
+func (interactor *ScriptInteractor) run(script Script, event domain.Event, fields map[string]string) {
+	scriptFields := map[string]string{"event": event.Name}
+	for key, value := range fields {
+		scriptFields[key] = value
+	}
+	calls := 0
+	call := ScriptCall{Event: scriptFields, Builtins: make(map[string]ScriptBuiltin),
+		Steps: maxScriptSteps, Timeout: scriptTimeout}
+	call.Print = func(message string) {
+		interactor.logf("Script #%d: %s", script.Id, message)
+	}
+	for _, name := range scriptBuiltins(script.Event) {
+		name := name
+		call.Builtins[name] = func(args []string) error {
+			calls++
+			if calls > maxScriptCalls {
+				return fmt.Errorf("%s: scripts may take at most %d actions", name, maxScriptCalls)
+			}
+			action, wanted := RuleAction{Name: name}, 0
+			if ruleActionKinds[name].argument {
+				wanted = 1
+			}
+			if len(args) != wanted {
+				return fmt.Errorf("%s: takes %d arguments, got %d", name, wanted, len(args))
+			}
+			if wanted == 1 {
+				if utf8.RuneCountInString(args[0]) > maxScriptArgument {
+					return fmt.Errorf("%s: argument is longer than %d characters", name, maxScriptArgument)
+				}
+				action.Argument = args[0]
+			}
+			err, _ := interactor.Rules.runAction(event.UserId, script.Name, action, event, fields)
+			detail := action.describe(fields)
+			if err != nil {
+				detail += ": " + err.Error()
+			}
+			auditErr := interactor.audit(script.CreatedBy, event.UserId, "script #%d on %s: %s",
+				script.Id, event.Name, detail)
+			if auditErr != nil {
+				interactor.logf("Cannot audit script #%d: %v", script.Id, auditErr)
+			}
+			return err
+		}
+	}
+
+	err := interactor.Engine.Run(script.Name, script.Source, call)
+	if err != nil {
+		interactor.logf("Script #%d failed on %s: %v", script.Id, event.Name, err)
+		err = interactor.audit(script.CreatedBy, event.UserId, "script #%d failed on %s: %v", script.Id,
+			event.Name, err)
+		if err != nil {
+			interactor.logf("Cannot audit script #%d: %v", script.Id, err)
+		}
+	}
+}
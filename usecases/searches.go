@@ -0,0 +1,290 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"game-tracker/domain"
+)
+
+const (
+	maxSearches          = 50 //Per user
+	maxSearchNameLength  = 100
+	searchShareTokenSize = 24 //Random bytes, hex encoded in the link
+)
+
+// Saved searches are shared like libraries, by a link whose token is the only
+// credential and of which only the hash is kept
+type SavedSearchRepository interface {
+	Store(search SavedSearch) (int, error)
+	Update(search SavedSearch) error //Name, filter and share token hash
+	FindById(id int) (SavedSearch, error, int)
+	FindByUser(userId int) ([]SavedSearch, error) //By name
+	FindByShareToken(tokenHash string) (SavedSearch, bool, error)
+	Remove(search SavedSearch) error //Along with the defaults it is
+	RemoveAll(userId int) error
+	SetDefault(userId, libraryId, searchId int) error //Search 0 clears the default
+	FindDefault(userId, libraryId int) (int, error)   //0 when the library has none
+	RemoveDefaults(libraryId int) error
+}
+
+// A named filter and sort order of game lists, it belongs to no library so
+// it can be applied to any the user can see
+type SavedSearch struct {
+	Id             int
+	UserId         int
+	Name           string
+	Filter         GameFilter
+	ShareTokenHash string //Empty when the search is not shared
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type SearchInteractor struct {
+	SavedSearchRepository SavedSearchRepository
+	UserRepository        UserRepository
+	LibraryRepository     LibraryRepository
+	Profile               ProfileInteractor
+	logging
+}
+
+func (interactor *SearchInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.SavedSearchRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove saved searches of user #%d: %v", event.UserId, err)
+		}
+	})
+	bus.Subscribe(domain.EventLibraryRemoved, func(event domain.Event) {
+		err := interactor.SavedSearchRepository.RemoveDefaults(event.EntityId)
+		if err != nil {
+			interactor.logf("Cannot remove default searches of library #%d: %v", event.EntityId, err)
+		}
+	})
+}
+
+func validSearch(search SavedSearch) (SavedSearch, error) {
+	search.Name = strings.TrimSpace(search.Name)
+	if search.Name == "" || utf8.RuneCountInString(search.Name) > maxSearchNameLength {
+		return search, domain.NewFieldError("name", "Must be between 1 and %d characters",
+			maxSearchNameLength)
+	}
+	filter, err := normalizeGameFilter(search.Filter)
+	if err != nil {
+		return search, err
+	}
+	search.Filter = filter
+	return search, nil
+}
+
+func (interactor *SearchInteractor) AddSearch(userId int, search SavedSearch) (SavedSearch, error, int) {
+	search, err := validSearch(search)
+	if err != nil {
+		return SavedSearch{}, err, 400
+	}
+	return interactor.store(userId, search)
+}
+
+func (interactor *SearchInteractor) store(userId int, search SavedSearch) (SavedSearch, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return SavedSearch{}, err, code
+	}
+	searches, err := interactor.SavedSearchRepository.FindByUser(userId)
+	if err != nil {
+		return SavedSearch{}, err, 500
+	}
+	if len(searches) >= maxSearches {
+		return SavedSearch{}, domain.NewError(domain.CodeConflict,
+			"User #%d already has %d saved searches, remove one first", userId, maxSearches), 409
+	}
+	search.UserId, search.ShareTokenHash = userId, ""
+	search.Id, err = interactor.SavedSearchRepository.Store(search)
+	if err != nil {
+		return SavedSearch{}, err, 500
+	}
+	interactor.logf("User #%d saved search #%d", userId, search.Id)
+	return interactor.SavedSearchRepository.FindById(search.Id)
+}
+
+func (interactor *SearchInteractor) ShowSearches(userId int) ([]SavedSearch, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	searches, err := interactor.SavedSearchRepository.FindByUser(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	return searches, nil, 200
+}
+
+func (interactor *SearchInteractor) ownSearch(userId, searchId int) (SavedSearch, error, int) {
+	search, err, code := interactor.SavedSearchRepository.FindById(searchId)
+	if err != nil {
+		return SavedSearch{}, err, code
+	}
+	if search.UserId != userId {
+		return SavedSearch{}, domain.NewError(domain.CodeNotFound,
+			"Saved search #%d does not exist", searchId), 404
+	}
+	return search, nil, 200
+}
+
+// Replaces the name and filter, a shared search keeps its link
+func (interactor *SearchInteractor) EditSearch(userId, searchId int, changed SavedSearch) (SavedSearch, error, int) {
+	search, err, code := interactor.ownSearch(userId, searchId)
+	if err != nil {
+		return SavedSearch{}, err, code
+	}
+	changed, err = validSearch(changed)
+	if err != nil {
+		return SavedSearch{}, err, 400
+	}
+	search.Name, search.Filter = changed.Name, changed.Filter
+	err = interactor.SavedSearchRepository.Update(search)
+	if err != nil {
+		return SavedSearch{}, err, 500
+	}
+	interactor.logf("User #%d edited saved search #%d", userId, searchId)
+	return interactor.SavedSearchRepository.FindById(searchId)
+}
+
+// Libraries that showed the search by default go back to showing everything
+func (interactor *SearchInteractor) RemoveSearch(userId, searchId int) (error, int) {
+	search, err, code := interactor.ownSearch(userId, searchId)
+	if err != nil {
+		return err, code
+	}
+	err = interactor.SavedSearchRepository.Remove(search)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("User #%d removed saved search #%d", userId, searchId)
+	return nil, 200
+}
+
+// The filter of one of the user's searches, to apply to a game list
+func (interactor *SearchInteractor) SearchFilter(userId, searchId int) (GameFilter, error, int) {
+	search, err, code := interactor.ownSearch(userId, searchId)
+	if err != nil {
+		return GameFilter{}, err, code
+	}
+	return search.Filter, nil, 200
+}
+
+// The filter a game list of the library starts from, zero when the user did
+// not pick a default search for it
+func (interactor *SearchInteractor) DefaultFilter(userId, libraryId int) (GameFilter, error, int) {
+	searchId, err := interactor.SavedSearchRepository.FindDefault(userId, libraryId)
+	if err != nil {
+		return GameFilter{}, err, 500
+	}
+	if searchId == 0 {
+		return GameFilter{}, nil, 200
+	}
+	search, err, code := interactor.SavedSearchRepository.FindById(searchId)
+	if code == 404 {
+		return GameFilter{}, nil, 200
+	}
+	if err != nil {
+		return GameFilter{}, err, code
+	}
+	return search.Filter, nil, 200
+}
+
+// Makes a search the default view of a library the user can see, search 0
+// clears it. Each user picks their own default for a shared library.
+func (interactor *SearchInteractor) SetDefaultSearch(userId, libraryId, searchId int) (error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return err, code
+	}
+	library, err, code := interactor.LibraryRepository.FindById(libraryId)
+	if err != nil {
+		return err, code
+	}
+	allowed, err := interactor.Profile.libraryAllows(userId, library, LibraryRoleViewer)
+	if err != nil {
+		return err, 500
+	}
+	if !allowed {
+		message := "User #%d is not allowed to see games in library #%d of user #%d"
+		return domain.NewError(domain.CodeForbidden, message, userId, library.Id, library.User.Id), 403
+	}
+	if searchId != 0 {
+		_, err, code = interactor.ownSearch(userId, searchId)
+		if err != nil {
+			return err, code
+		}
+	}
+	err = interactor.SavedSearchRepository.SetDefault(userId, libraryId, searchId)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("User #%d made search #%d the default of library #%d", userId, searchId, libraryId)
+	return nil, 200
+}
+
+// Issues a link to the search, the token is only ever returned here. Sharing
+// again replaces the previous link.
+func (interactor *SearchInteractor) ShareSearch(userId, searchId int) (SavedSearch, string, error, int) {
+	search, err, code := interactor.ownSearch(userId, searchId)
+	if err != nil {
+		return SavedSearch{}, "", err, code
+	}
+	bytes := make([]byte, searchShareTokenSize)
+	_, err = rand.Read(bytes)
+	if err != nil {
+		return SavedSearch{}, "", err, 500
+	}
+	token := hex.EncodeToString(bytes)
+	search.ShareTokenHash = hashToken(token)
+	err = interactor.SavedSearchRepository.Update(search)
+	if err != nil {
+		return SavedSearch{}, "", err, 500
+	}
+	interactor.logf("User #%d shared saved search #%d", userId, searchId)
+	return search, token, nil, 200
+}
+
+// The link stops working at once
+func (interactor *SearchInteractor) UnshareSearch(userId, searchId int) (error, int) {
+	search, err, code := interactor.ownSearch(userId, searchId)
+	if err != nil {
+		return err, code
+	}
+	search.ShareTokenHash = ""
+	err = interactor.SavedSearchRepository.Update(search)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("User #%d unshared saved search #%d", userId, searchId)
+	return nil, 200
+}
+
+// What a search link shows: the name and filter, not whose search it is.
+// Unknown and unshared links look the same.
+func (interactor *SearchInteractor) ShowSharedSearch(token string) (SavedSearch, error, int) {
+	search, found, err := interactor.SavedSearchRepository.FindByShareToken(hashToken(token))
+	if err != nil {
+		return SavedSearch{}, err, 500
+	}
+	if !found {
+		return SavedSearch{}, domain.NewError(domain.CodeNotFound, "Shared search does not exist"), 404
+	}
+	return search, nil, 200
+}
+
+// Saves a copy of a shared search for the user, later edits to either do
+// not show in the other
+func (interactor *SearchInteractor) ImportSearch(userId int, token string) (SavedSearch, error, int) {
+	shared, err, code := interactor.ShowSharedSearch(token)
+	if err != nil {
+		return SavedSearch{}, err, code
+	}
+	return interactor.store(userId, SavedSearch{Name: shared.Name, Filter: shared.Filter})
+}
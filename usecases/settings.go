@@ -0,0 +1,197 @@
+package usecases
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"game-tracker/domain"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type SettingsRepository interface {
+	Load(userId int) (Settings, bool, error)
+	Store(settings Settings) error
+	Remove(userId int) error
+}
+
+type Settings struct {
+	UserId                   int
+	DisplayCurrency          string
+	Timezone                 string
+	NotifyLibraries          bool
+	NotifyGames              bool
+	DefaultLibraryId         int //0 when the user has no default library
+	DefaultLibraryExternalId string
+	ProfilePublic            bool
+	LibrariesPublic          bool   //Visibility given to newly created libraries
+	RatingLimit              int    //Highest MinAge of games added in the household, 0 for none
+	Locale                   string //Empty follows the client's Accept-Language
+}
+
+func DefaultSettings(userId int) Settings {
+	return Settings{
+		UserId:          userId,
+		DisplayCurrency: "USD",
+		Timezone:        "UTC",
+		NotifyLibraries: true,
+		NotifyGames:     true,
+		ProfilePublic:   true,
+	}
+}
+
+func (settings Settings) Location() *time.Location {
+	location, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// Reports whether the user opted in to notifications for the given event
+func (settings Settings) NotifiesOn(eventName string) bool {
+	switch eventName {
+	case domain.EventLibraryAdded, domain.EventLibraryRemoved:
+		return settings.NotifyLibraries
+	case domain.EventGameAdded, domain.EventGameRemoved:
+		return settings.NotifyGames
+	}
+	return true
+}
+
+func loadSettings(repository SettingsRepository, userId int) (Settings, error) {
+	settings, found, err := repository.Load(userId)
+	if err != nil {
+		return Settings{}, err
+	}
+	if !found {
+		return DefaultSettings(userId), nil
+	}
+	return settings, nil
+}
+
+// Falls back to UTC when the user has no usable timezone setting
+func userLocation(repository SettingsRepository, userId int) *time.Location {
+	if repository == nil {
+		return time.UTC
+	}
+	settings, err := loadSettings(repository, userId)
+	if err != nil {
+		return time.UTC
+	}
+	return settings.Location()
+}
+
+type SettingsInteractor struct {
+	SettingsRepository SettingsRepository
+	UserRepository     UserRepository
+	LibraryRepository  LibraryRepository
+	Translator         Translator //Nil only knows English
+	logging
+}
+
+func (interactor *SettingsInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventLibraryRemoved, interactor.clearDefaultLibrary)
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.SettingsRepository.Remove(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove settings of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+func (interactor *SettingsInteractor) clearDefaultLibrary(event domain.Event) {
+	settings, found, err := interactor.SettingsRepository.Load(event.UserId)
+	if err != nil || !found || settings.DefaultLibraryId != event.EntityId {
+		return
+	}
+	settings.DefaultLibraryId = 0
+	settings.DefaultLibraryExternalId = ""
+	err = interactor.SettingsRepository.Store(settings)
+	if err != nil {
+		interactor.logf("Cannot reset default library of user #%d: %v", event.UserId, err)
+	}
+}
+
+func (interactor *SettingsInteractor) ShowSettings(userId int) (Settings, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		err = fmt.Errorf("User #%d does not exist", userId)
+		return Settings{}, err, code
+	}
+	settings, err := loadSettings(interactor.SettingsRepository, userId)
+	if err != nil {
+		return Settings{}, err, 500
+	}
+	return settings, nil, 200
+}
+
+// The locale the user picked, empty when they left it to their client
+func (interactor *SettingsInteractor) UserLocale(userId int) (string, error) {
+	settings, err := loadSettings(interactor.SettingsRepository, userId)
+	if err != nil {
+		return "", err
+	}
+	return settings.Locale, nil
+}
+
+func (interactor *SettingsInteractor) EditSettings(userId int, settings Settings) (Settings, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		err = fmt.Errorf("User #%d does not exist", userId)
+		return Settings{}, err, code
+	}
+	settings.UserId = userId
+
+	err, code = interactor.validate(settings)
+	if err != nil {
+		return Settings{}, err, code
+	}
+	settings.DefaultLibraryExternalId = ""
+	if settings.DefaultLibraryId != 0 {
+		library, err, code := interactor.LibraryRepository.FindById(settings.DefaultLibraryId)
+		if err != nil {
+			return Settings{}, err, code
+		}
+		settings.DefaultLibraryExternalId = library.ExternalId
+	}
+	err = interactor.SettingsRepository.Store(settings)
+	if err != nil {
+		return Settings{}, err, 500
+	}
+	interactor.logf("Editted settings of user #%d", userId)
+	return settings, nil, 200
+}
+
+func (interactor *SettingsInteractor) validate(settings Settings) (error, int) {
+	if !currencyPattern.MatchString(settings.DisplayCurrency) {
+		return domain.NewFieldError("displayCurrency", "Currency '%s' is not an ISO 4217 code",
+			settings.DisplayCurrency), 400
+	}
+	_, err := time.LoadLocation(settings.Timezone)
+	if err != nil || settings.Timezone == "" {
+		return domain.NewFieldError("timezone", "Timezone '%s' is unknown", settings.Timezone), 400
+	}
+	if !validLocale(interactor.Translator, settings.Locale) {
+		return domain.NewFieldError("locale", "Locale '%s' is not supported, use one of %s",
+			settings.Locale, strings.Join(supportedLocales(interactor.Translator), ", ")), 400
+	}
+	if settings.RatingLimit < 0 || settings.RatingLimit > maxRatingCap {
+		return domain.NewFieldError("ratingLimit", "Must be between 0 and %d", maxRatingCap), 400
+	}
+	if settings.DefaultLibraryId != 0 {
+		library, err, code := interactor.LibraryRepository.FindById(settings.DefaultLibraryId)
+		if err != nil {
+			err = fmt.Errorf("Library #%d does not exist", settings.DefaultLibraryId)
+			return err, code
+		}
+		if library.User.Id != settings.UserId {
+			err = domain.NewError(domain.CodeForbidden, "Library #%d does not belong to user #%d",
+				settings.DefaultLibraryId, settings.UserId)
+			return err, 403
+		}
+	}
+	return nil, 200
+}
@@ -0,0 +1,255 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"game-tracker/domain"
+)
+
+const (
+	minSharePasswordLength   = 6
+	maxSharePasswordLength   = 72 //Bytes bcrypt reads
+	maxShareLinks            = 20 //Per library
+	maxSharePasswordFailures = 5  //Per link in the window of the counter
+)
+
+// Share links work like calendar feeds, the token in the URL is the only
+// credential and only its hash is kept
+type ShareLinkRepository interface {
+	Store(link ShareLink) (int, error)
+	FindById(id int) (ShareLink, error, int)
+	FindByToken(tokenHash string) (ShareLink, bool, error)
+	FindByLib(libraryId int) ([]ShareLink, error) //Newest first
+	Remove(link ShareLink) error
+	RemoveFromLib(libraryId int) error
+}
+
+// A read-only view of one library for people without an account
+type ShareLink struct {
+	Id           int
+	LibraryId    int
+	TokenHash    string
+	PasswordHash string    //Bcrypt hash, empty when the link needs no password
+	ExpiresAt    time.Time //Zero when the link never expires
+	CreatedAt    time.Time
+}
+
+func (link ShareLink) expired(now time.Time) bool {
+	return !link.ExpiresAt.IsZero() && !now.Before(link.ExpiresAt)
+}
+
+// Counts failed password attempts, a count is dropped once its window is
+// over. The rate limit cache is one.
+type FailureCounter interface {
+	Get(key string) ([]byte, bool, error)
+	Increment(key string) (int64, error)
+}
+
+type SharingInteractor struct {
+	ShareLinkRepository ShareLinkRepository
+	UserRepository      UserRepository
+	LibraryRepository   LibraryRepository
+	GameRepository      GameRepository
+	Failures            FailureCounter //Nil leaves password attempts unlimited
+	logging
+}
+
+func (interactor *SharingInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventLibraryRemoved, func(event domain.Event) {
+		err := interactor.ShareLinkRepository.RemoveFromLib(event.EntityId)
+		if err != nil {
+			interactor.logf("Cannot remove share links of library #%d: %v", event.EntityId, err)
+		}
+	})
+}
+
+// Issues a link to the library, the token is only ever returned here. A zero
+// expiry keeps the link working until it is revoked.
+func (interactor *SharingInteractor) ShareLibrary(userId, libraryId int, expiresAt time.Time, password string) (ShareLink, string, error, int) {
+	if !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return ShareLink{}, "", domain.NewFieldError("expiresAt", "Must be in the future"), 400
+	}
+	if password != "" && (len(password) < minSharePasswordLength || len(password) > maxSharePasswordLength) {
+		return ShareLink{}, "", domain.NewFieldError("password", "Must be between %d and %d characters",
+			minSharePasswordLength, maxSharePasswordLength), 400
+	}
+	_, err, code := interactor.ownedLibrary(userId, libraryId)
+	if err != nil {
+		return ShareLink{}, "", err, code
+	}
+	links, err := interactor.ShareLinkRepository.FindByLib(libraryId)
+	if err != nil {
+		return ShareLink{}, "", err, 500
+	}
+	if len(links) >= maxShareLinks {
+		return ShareLink{}, "", domain.NewError(domain.CodeConflict,
+			"Library #%d already has %d share links, revoke one first", libraryId, maxShareLinks), 409
+	}
+
+	bytes := make([]byte, 24)
+	_, err = rand.Read(bytes)
+	if err != nil {
+		return ShareLink{}, "", err, 500
+	}
+	token := hex.EncodeToString(bytes)
+	link := ShareLink{LibraryId: libraryId, TokenHash: hashToken(token), ExpiresAt: expiresAt.UTC()}
+	if password != "" {
+		link.PasswordHash, err = hashSharePassword(password)
+		if err != nil {
+			return ShareLink{}, "", err, 500
+		}
+	}
+	link.Id, err = interactor.ShareLinkRepository.Store(link)
+	if err != nil {
+		return ShareLink{}, "", err, 500
+	}
+	interactor.logf("User #%d shared library #%d as link #%d", userId, libraryId, link.Id)
+	link, err, code = interactor.ShareLinkRepository.FindById(link.Id)
+	return link, token, err, code
+}
+
+// Expired links are listed until they are revoked
+func (interactor *SharingInteractor) ShowShareLinks(userId, libraryId int) ([]ShareLink, error, int) {
+	_, err, code := interactor.ownedLibrary(userId, libraryId)
+	if err != nil {
+		return nil, err, code
+	}
+	links, err := interactor.ShareLinkRepository.FindByLib(libraryId)
+	if err != nil {
+		return nil, err, 500
+	}
+	return links, nil, 200
+}
+
+// The link stops working at once
+func (interactor *SharingInteractor) RevokeShareLink(userId, libraryId, linkId int) (error, int) {
+	_, err, code := interactor.ownedLibrary(userId, libraryId)
+	if err != nil {
+		return err, code
+	}
+	link, err, code := interactor.ShareLinkRepository.FindById(linkId)
+	if code == 404 || (err == nil && link.LibraryId != libraryId) {
+		return domain.NewError(domain.CodeNotFound, "Share link #%d does not exist", linkId), 404
+	}
+	if err != nil {
+		return err, code
+	}
+	err = interactor.ShareLinkRepository.Remove(link)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("User #%d revoked share link #%d of library #%d", userId, linkId, libraryId)
+	return nil, 200
+}
+
+// What a share link shows: the owner's name and the games of the one library
+// it was issued for. Unknown, revoked and expired links look the same.
+func (interactor *SharingInteractor) ShowSharedLibrary(token, password string) (User, ShareLink, []Game, error, int) {
+	notFound := domain.NewError(domain.CodeNotFound, "Shared library does not exist")
+	link, found, err := interactor.ShareLinkRepository.FindByToken(hashToken(token))
+	if err != nil {
+		return User{}, ShareLink{}, nil, err, 500
+	}
+	if !found || link.expired(time.Now()) {
+		return User{}, ShareLink{}, nil, notFound, 404
+	}
+	if link.PasswordHash != "" {
+		if password == "" {
+			return User{}, ShareLink{}, nil, domain.NewError(domain.CodeUnauthorized,
+				"This shared library needs a password"), 401
+		}
+		err, code := interactor.checkSharePassword(link, password)
+		if err != nil {
+			return User{}, ShareLink{}, nil, err, code
+		}
+	}
+	library, err, code := interactor.LibraryRepository.FindById(link.LibraryId)
+	if code == 404 {
+		return User{}, ShareLink{}, nil, notFound, 404
+	}
+	if err != nil {
+		return User{}, ShareLink{}, nil, err, code
+	}
+	user, err, code := interactor.UserRepository.FindById(library.User.Id)
+	if err != nil {
+		return User{}, ShareLink{}, nil, err, code
+	}
+	games, err := interactor.GameRepository.FindByLib(library.Id, GameFilter{}, Page{})
+	if err != nil {
+		return User{}, ShareLink{}, nil, err, 500
+	}
+	return user, link, games, nil, 200
+}
+
+func (interactor *SharingInteractor) ownedLibrary(userId, libraryId int) (Library, error, int) {
+	library, err, code := interactor.LibraryRepository.FindById(libraryId)
+	if err != nil {
+		return Library{}, err, code
+	}
+	if library.User.Id != userId {
+		message := "User #%d is not allowed to share library #%d of user #%d"
+		err := domain.NewError(domain.CodeForbidden, message, userId, library.Id, library.User.Id)
+		return Library{}, err, 403
+	}
+	return library, nil, 200
+}
+
+// Once a link saw maxSharePasswordFailures wrong passwords it refuses every
+// password until the window of the counter is over, the right one too. A
+// failing counter lets attempts through.
+func (interactor *SharingInteractor) checkSharePassword(link ShareLink, password string) (error, int) {
+	key := fmt.Sprintf("share-password:%d", link.Id)
+	if interactor.Failures != nil {
+		value, found, err := interactor.Failures.Get(key)
+		if err != nil {
+			interactor.logf("Cannot count password failures of share link #%d: %v", link.Id, err)
+		}
+		failures, _ := strconv.Atoi(string(value))
+		if found && failures >= maxSharePasswordFailures {
+			return domain.NewError(domain.CodeRateLimited,
+				"Too many wrong passwords, try again later"), 429
+		}
+	}
+	if sharePasswordMatches(link.PasswordHash, password) {
+		return nil, 200
+	}
+	if interactor.Failures != nil {
+		_, err := interactor.Failures.Increment(key)
+		if err != nil {
+			interactor.logf("Cannot count password failures of share link #%d: %v", link.Id, err)
+		}
+	}
+	return domain.NewError(domain.CodeUnauthorized, "The password is incorrect"), 401
+}
+
+func hashSharePassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// Links shared before passwords were hashed with bcrypt keep salt$hash, both
+// hex, of a salted SHA-256
+func sharePasswordMatches(hash, password string) bool {
+	if strings.HasPrefix(hash, "$2") {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+	parts := strings.SplitN(hash, "$", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	sum := sha256.Sum256(append(salt, password...))
+	return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(parts[1])) == 1
+}
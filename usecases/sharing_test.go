@@ -0,0 +1,49 @@
+package usecases
+
+import (
+	"strconv"
+	"testing"
+)
+
+type failureCounts map[string]int64
+
+func (counts failureCounts) Get(key string) ([]byte, bool, error) {
+	count, found := counts[key]
+	return []byte(strconv.FormatInt(count, 10)), found, nil
+}
+
+func (counts failureCounts) Increment(key string) (int64, error) {
+	counts[key]++
+	return counts[key], nil
+}
+
+// After too many wrong passwords the right one is refused as well, other
+// links are not affected
+func TestCheckSharePasswordLocksOut(t *testing.T) {
+	hash, err := hashSharePassword("open sesame")
+	if err != nil {
+		t.Fatal(err)
+	}
+	interactor := SharingInteractor{Failures: failureCounts{}}
+	link := ShareLink{Id: 1, PasswordHash: hash}
+
+	_, code := interactor.checkSharePassword(link, "open sesame")
+	if code != 200 {
+		t.Fatalf("The right password gave %d, want 200", code)
+	}
+	for i := 0; i < maxSharePasswordFailures; i++ {
+		_, code = interactor.checkSharePassword(link, "guess")
+		if code != 401 {
+			t.Fatalf("Wrong password #%d gave %d, want 401", i+1, code)
+		}
+	}
+	_, code = interactor.checkSharePassword(link, "open sesame")
+	if code != 429 {
+		t.Fatalf("The right password after %d failures gave %d, want 429",
+			maxSharePasswordFailures, code)
+	}
+	_, code = interactor.checkSharePassword(ShareLink{Id: 2, PasswordHash: hash}, "open sesame")
+	if code != 200 {
+		t.Fatalf("Another link gave %d, want 200", code)
+	}
+}
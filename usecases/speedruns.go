@@ -0,0 +1,353 @@
+package usecases
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"game-tracker/domain"
+)
+
+const (
+	SpeedrunAnyPercent     = "any%"
+	SpeedrunHundredPercent = "100%"
+
+	maxRunsPerGame         = 1000 //Per user
+	maxRunCategoryLength   = 50
+	maxRunMilliseconds     = 1000 * 60 * 60 * 24 * 7
+	maxSpeedrunComparisons = 50 //Friends compared at most
+	maxRunsPerPage         = 100
+)
+
+type SpeedrunRepository interface {
+	Store(run SpeedRun) (int, error)
+	FindById(id int) (SpeedRun, error, int)
+	FindByGame(userId, gameId int) ([]SpeedRun, error) //By category, oldest first
+	// The fastest run of each user in every category, of one game or of any
+	// game when gameId is 0. Ties go to the earlier run.
+	FindBest(userIds []int, gameId int) ([]SpeedRun, error)
+	Remove(run SpeedRun) error
+	RemoveAll(userId int) error
+}
+
+// A timed run through a game. Categories are lowercase and free text,
+// any% and 100% are the common ones.
+type SpeedRun struct {
+	Id             int
+	UserId         int
+	GameId         int
+	GameExternalId string
+	GameName       string
+	Category       string
+	Milliseconds   int64
+	RunAt          time.Time
+	PersonalBest   bool //Filled in by the interactor, faster than every run before it
+	Runner         User //Filled in by the interactor when comparing runs
+	CreatedAt      time.Time
+}
+
+// The run time as h:mm:ss.mmm, hours left out under an hour
+func (run SpeedRun) Time() string {
+	duration := time.Duration(run.Milliseconds) * time.Millisecond
+	hours := int(duration.Hours())
+	minutes := int(duration.Minutes()) % 60
+	seconds := int(duration.Seconds()) % 60
+	millis := run.Milliseconds % 1000
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d.%03d", hours, minutes, seconds, millis)
+	}
+	return fmt.Sprintf("%d:%02d.%03d", minutes, seconds, millis)
+}
+
+// The personal bests of a user and friends in one category, fastest first
+type SpeedrunComparison struct {
+	Category string
+	Own      SpeedRun //Zero when the user has no run in the category
+	Runs     []SpeedRun
+}
+
+type SpeedrunInteractor struct {
+	SpeedrunRepository    SpeedrunRepository
+	UserRepository        UserRepository
+	GameRepository        GameRepository
+	PlaySessionRepository PlaySessionRepository
+	SettingsRepository    SettingsRepository
+	EventBus              domain.EventBus
+	logging
+}
+
+func (interactor *SpeedrunInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.SpeedrunRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove speedruns of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+func validRun(run SpeedRun, now time.Time) (SpeedRun, error) {
+	run.Category = strings.ToLower(strings.TrimSpace(run.Category))
+	if run.Category == "" {
+		run.Category = SpeedrunAnyPercent
+	}
+	if utf8.RuneCountInString(run.Category) > maxRunCategoryLength {
+		return SpeedRun{}, domain.NewFieldError("category", "Must be at most %d characters",
+			maxRunCategoryLength)
+	}
+	if run.Milliseconds <= 0 || run.Milliseconds > maxRunMilliseconds {
+		return SpeedRun{}, domain.NewFieldError("milliseconds", "Must be between 1 and %d",
+			maxRunMilliseconds)
+	}
+	if run.RunAt.IsZero() {
+		run.RunAt = now
+	}
+	if run.RunAt.After(now) {
+		return SpeedRun{}, domain.NewFieldError("runAt", "Cannot be in the future")
+	}
+	run.RunAt = run.RunAt.UTC()
+	return run, nil
+}
+
+// Records a run, users are notified when it beats their personal best in
+// the category
+func (interactor *SpeedrunInteractor) AddRun(userId, gameId int, run SpeedRun) (SpeedRun, error, int) {
+	run, err := validRun(run, time.Now())
+	if err != nil {
+		return SpeedRun{}, err, 400
+	}
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return SpeedRun{}, err, code
+	}
+	game, err, code := interactor.GameRepository.FindById(gameId)
+	if err != nil {
+		return SpeedRun{}, err, code
+	}
+	runs, err := interactor.SpeedrunRepository.FindByGame(userId, gameId)
+	if err != nil {
+		return SpeedRun{}, err, 500
+	}
+	if len(runs) >= maxRunsPerGame {
+		return SpeedRun{}, domain.NewError(domain.CodeConflict,
+			"User #%d already has %d runs of game #%d, remove one first", userId, maxRunsPerGame, gameId), 409
+	}
+	var best SpeedRun
+	for _, previous := range runs {
+		if previous.Category == run.Category && (best.Id == 0 || previous.Milliseconds < best.Milliseconds) {
+			best = previous
+		}
+	}
+
+	run.UserId, run.GameId = userId, gameId
+	run.GameExternalId, run.GameName = game.ExternalId, game.Name
+	id, err := interactor.SpeedrunRepository.Store(run)
+	if err != nil {
+		return SpeedRun{}, err, 500
+	}
+	interactor.logf("User #%d recorded run #%d of game #%d", userId, id, gameId)
+	added, err, code := interactor.SpeedrunRepository.FindById(id)
+	if err != nil {
+		return SpeedRun{}, err, code
+	}
+	added.PersonalBest = best.Id == 0 || added.Milliseconds < best.Milliseconds
+	if best.Id != 0 && added.PersonalBest && interactor.EventBus != nil {
+		interactor.EventBus.Publish(domain.Event{Name: domain.EventPersonalBest, UserId: userId,
+			EntityId: id, Payload: map[string]string{"name": game.Name, "category": added.Category,
+				"time": added.Time(), "improvement": SpeedRun{Milliseconds: best.Milliseconds -
+					added.Milliseconds}.Time()}})
+	}
+	return added, nil, 201
+}
+
+// The runs of a game in the order they were run, those that set a new
+// personal best are flagged. An empty category lists every category. Flags
+// depend on the runs before, so every run of the game is read and the page
+// is cut from them, there are at most maxRunsPerGame. The cursor of the next
+// page is zero on the last page.
+func (interactor *SpeedrunInteractor) ShowRuns(userId, gameId int, category string, page Page) ([]SpeedRun, Cursor, error, int) {
+	err := validPage(page, maxRunsPerPage)
+	if err != nil {
+		return nil, Cursor{}, err, 400
+	}
+	after, err := page.After.Time()
+	if err != nil {
+		return nil, Cursor{}, err, 400
+	}
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, Cursor{}, err, code
+	}
+	runs, err := interactor.SpeedrunRepository.FindByGame(userId, gameId)
+	if err != nil {
+		return nil, Cursor{}, err, 500
+	}
+	category = strings.ToLower(strings.TrimSpace(category))
+	sort.SliceStable(runs, func(i, j int) bool {
+		if !runs[i].RunAt.Equal(runs[j].RunAt) {
+			return runs[i].RunAt.Before(runs[j].RunAt)
+		}
+		return runs[i].Id < runs[j].Id
+	})
+	best := make(map[string]int64)
+	history := []SpeedRun{}
+	for _, run := range runs {
+		if fastest, found := best[run.Category]; !found || run.Milliseconds < fastest {
+			best[run.Category] = run.Milliseconds
+			run.PersonalBest = true
+		}
+		if category == "" || run.Category == category {
+			history = append(history, run)
+		}
+	}
+	start := sort.Search(len(history), func(i int) bool {
+		if page.After.IsZero() || history[i].RunAt.After(after) {
+			return true
+		}
+		return history[i].RunAt.Equal(after) && history[i].Id > page.After.Id
+	})
+	history = history[start:]
+	count, more := page.cut(len(history))
+	history = history[:count]
+	var next Cursor
+	if more {
+		last := history[count-1]
+		next = timeCursor(last.RunAt, last.Id)
+	}
+	return history, next, nil, 200
+}
+
+// The personal best of every game and category the user ran
+func (interactor *SpeedrunInteractor) ShowPersonalBests(userId int) ([]SpeedRun, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	runs, err := interactor.SpeedrunRepository.FindBest([]int{userId}, 0)
+	if err != nil {
+		return nil, err, 500
+	}
+	for i := range runs {
+		runs[i].PersonalBest = true
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		if runs[i].GameName != runs[j].GameName {
+			return runs[i].GameName < runs[j].GameName
+		}
+		return runs[i].Category < runs[j].Category
+	})
+	return runs, nil, 200
+}
+
+func (interactor *SpeedrunInteractor) RemoveRun(userId, gameId, runId int) (error, int) {
+	run, err, code := interactor.SpeedrunRepository.FindById(runId)
+	if code == 404 || (err == nil && (run.UserId != userId || run.GameId != gameId)) {
+		return domain.NewError(domain.CodeNotFound, "Run #%d does not exist", runId), 404
+	}
+	if err != nil {
+		return err, code
+	}
+	err = interactor.SpeedrunRepository.Remove(run)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("User #%d removed run #%d", userId, runId)
+	return nil, 200
+}
+
+// Compares the personal bests of a game against those of friends. There
+// are no friend lists, friends are the users played with in co-op sessions
+// whose profile is public.
+func (interactor *SpeedrunInteractor) ComparePersonalBests(userId, gameId int) ([]SpeedrunComparison, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	_, err, code = interactor.GameRepository.FindById(gameId)
+	if err != nil {
+		return nil, err, code
+	}
+	friends, err := interactor.friends(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	runs, err := interactor.SpeedrunRepository.FindBest(append([]int{userId}, friends...), gameId)
+	if err != nil {
+		return nil, err, 500
+	}
+
+	runners := make(map[int]User)
+	comparisons := make(map[string]*SpeedrunComparison)
+	for _, run := range runs {
+		runner, found := runners[run.UserId]
+		if !found {
+			runner, err, code = interactor.UserRepository.FindById(run.UserId)
+			if code == 404 {
+				continue
+			}
+			if err != nil {
+				return nil, err, code
+			}
+			runners[run.UserId] = runner
+		}
+		run.Runner = runner
+		run.PersonalBest = true
+		comparison := comparisons[run.Category]
+		if comparison == nil {
+			comparison = &SpeedrunComparison{Category: run.Category}
+			comparisons[run.Category] = comparison
+		}
+		if run.UserId == userId {
+			comparison.Own = run
+		}
+		comparison.Runs = append(comparison.Runs, run)
+	}
+	compared := []SpeedrunComparison{}
+	for _, comparison := range comparisons {
+		sort.SliceStable(comparison.Runs, func(i, j int) bool {
+			if comparison.Runs[i].Milliseconds != comparison.Runs[j].Milliseconds {
+				return comparison.Runs[i].Milliseconds < comparison.Runs[j].Milliseconds
+			}
+			return comparison.Runs[i].RunAt.Before(comparison.Runs[j].RunAt)
+		})
+		compared = append(compared, *comparison)
+	}
+	sort.Slice(compared, func(i, j int) bool {
+		return compared[i].Category < compared[j].Category
+	})
+	return compared, nil, 200
+}
+
+// Co-op partners with a public profile, those played with most recently first
+func (interactor *SpeedrunInteractor) friends(userId int) ([]int, error) {
+	sessions, err := interactor.PlaySessionRepository.FindCoop(userId, 0, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].StartsAt.After(sessions[j].StartsAt)
+	})
+	seen := make(map[int]bool)
+	var friends []int
+	for _, session := range sessions {
+		for _, partnerId := range session.PartnerIds {
+			if seen[partnerId] || partnerId == userId {
+				continue
+			}
+			seen[partnerId] = true
+			settings, err := loadSettings(interactor.SettingsRepository, partnerId)
+			if err != nil {
+				return nil, err
+			}
+			if !settings.ProfilePublic {
+				continue
+			}
+			friends = append(friends, partnerId)
+			if len(friends) == maxSpeedrunComparisons {
+				return friends, nil
+			}
+		}
+	}
+	return friends, nil
+}
@@ -0,0 +1,144 @@
+package usecases
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	spoilerOpen  = ":::spoiler"
+	spoilerClose = ":::"
+	// Takes the place of a hidden spoiler block
+	hiddenSpoilerText = "[spoiler hidden]"
+	hiddenSpoilerHtml = `<p class="spoiler-hidden">Spoiler hidden</p>`
+)
+
+// A run of text either shown to everyone or only to readers who asked for
+// spoilers
+type textBlock struct {
+	Text    string
+	Spoiler bool
+}
+
+// Spoilers are fenced on their own lines:
+//
+//	:::spoiler
+//	The butler did it
+//	:::
+//
+// A fence left open runs to the end of the text
+func splitSpoilers(text string) []textBlock {
+	var blocks []textBlock
+	var lines []string
+	spoiler := false
+	flush := func() {
+		if joined := strings.TrimSpace(strings.Join(lines, "\n")); joined != "" {
+			blocks = append(blocks, textBlock{Text: joined, Spoiler: spoiler})
+		}
+		lines = nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case !spoiler && strings.EqualFold(trimmed, spoilerOpen):
+			flush()
+			spoiler = true
+		case spoiler && trimmed == spoilerClose:
+			flush()
+			spoiler = false
+		default:
+			lines = append(lines, line)
+		}
+	}
+	flush()
+	return blocks
+}
+
+// Renders every block on its own. The full rendering wraps spoilers so
+// clients can blur them, the spoiler free one leaves a marker in their place.
+func renderWithSpoilers(renderer MarkdownRenderer, text string) (full, spoilerFree string, spoilers bool, err error) {
+	var fullParts, freeParts []string
+	for _, block := range splitSpoilers(text) {
+		rendered, err := renderMarkdown(renderer, block.Text)
+		if err != nil {
+			return "", "", false, err
+		}
+		if block.Spoiler {
+			spoilers = true
+			fullParts = append(fullParts, `<div class="spoiler">`+rendered+"</div>")
+			freeParts = append(freeParts, hiddenSpoilerHtml)
+			continue
+		}
+		fullParts = append(fullParts, rendered)
+		freeParts = append(freeParts, rendered)
+	}
+	return strings.Join(fullParts, "\n"), strings.Join(freeParts, "\n"), spoilers, nil
+}
+
+// The text with its spoiler blocks swapped for a marker
+func spoilerFreeText(text string) string {
+	var parts []string
+	for _, block := range splitSpoilers(text) {
+		if block.Spoiler {
+			parts = append(parts, hiddenSpoilerText)
+			continue
+		}
+		parts = append(parts, block.Text)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Hides what readers did not opt in to. Entries about a game flagged as
+// containing spoilers are hidden whole, screenshot included, other entries
+// only lose their spoiler blocks.
+func hideSpoilers(games GameRepository, entries []JournalEntry) error {
+	flagged := make(map[int]bool)
+	for i, entry := range entries {
+		contains, seen := flagged[entry.GameId]
+		if !seen {
+			game, err, _ := games.FindById(entry.GameId)
+			if err != nil {
+				return err
+			}
+			contains = game.ContainsSpoilers
+			flagged[entry.GameId] = contains
+		}
+		switch {
+		case contains:
+			entries[i].Text = hiddenSpoilerText
+			entries[i].Html = hiddenSpoilerHtml
+			entries[i].Screenshot = ""
+			entries[i].ScreenshotType = ""
+			entries[i].SpoilersHidden = true
+		case entry.HasSpoilers:
+			entries[i].Text = spoilerFreeText(entry.Text)
+			entries[i].Html = entry.SpoilerFreeHtml
+			entries[i].SpoilersHidden = true
+		}
+	}
+	return nil
+}
+
+// Marks a whole game as spoiling, its journal entries are then hidden from
+// readers who did not ask for spoilers
+func (interactor *AdminInteractor) FlagSpoilers(adminId, gameId int, containsSpoilers bool) (Game, error, int) {
+	err, code := interactor.requireAdmin(adminId)
+	if err != nil {
+		return Game{}, err, code
+	}
+	_, err, code = interactor.GameRepository.FindById(gameId)
+	if err != nil {
+		return Game{}, err, code
+	}
+	err = interactor.GameRepository.SetSpoilers(gameId, containsSpoilers)
+	if err != nil {
+		return Game{}, err, 500
+	}
+	err = interactor.AdminRepository.Audit(AuditEntry{ActorId: adminId, Action: AuditSpoilers,
+		Detail: fmt.Sprintf("game #%d containsSpoilers=%t", gameId, containsSpoilers)})
+	if err != nil {
+		return Game{}, err, 500
+	}
+	interactor.logf("Admin #%d set game #%d containsSpoilers=%t", adminId, gameId, containsSpoilers)
+	return interactor.GameRepository.FindById(gameId)
+}
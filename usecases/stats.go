@@ -0,0 +1,46 @@
+package usecases
+
+import (
+	"time"
+)
+
+// Summing every entry and session of a user per request is slow for large
+// collections, the stats job keeps totals the repository reads instead. They
+// lag behind edits by up to the job's interval.
+type StatsRepository interface {
+	FindByUser(userId int) (LibraryStats, error) //Zero totals for users the job has not seen yet
+	Refresh() error
+}
+
+// Totals over all libraries of a user
+type LibraryStats struct {
+	UserId          int
+	Libraries       int
+	Games           int            //Entries, a game in two libraries counts twice
+	Value           float64        //Game values summed over entries
+	Statuses        map[string]int //Entries per status
+	Platforms       map[string]int //Entries per platform, "" for entries without one
+	PlatformMinutes map[string]int //Minutes played per platform, archived months included
+	RefreshedAt     *time.Time     //Nil before the first refresh
+}
+
+type StatsInteractor struct {
+	StatsRepository StatsRepository
+	UserRepository  UserRepository
+}
+
+func (interactor *StatsInteractor) ShowStats(userId int) (LibraryStats, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return LibraryStats{}, err, code
+	}
+	stats, err := interactor.StatsRepository.FindByUser(userId)
+	if err != nil {
+		return LibraryStats{}, err, 500
+	}
+	return stats, nil, 200
+}
+
+func (interactor *StatsInteractor) RefreshStats() error {
+	return interactor.StatsRepository.Refresh()
+}
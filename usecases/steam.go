@@ -0,0 +1,149 @@
+package usecases
+
+import (
+	"regexp"
+	"strings"
+
+	"game-tracker/domain"
+)
+
+// Steam ids are the 64-bit form shown in profile urls, vanity names need an
+// API key to resolve
+var steamIdPattern = regexp.MustCompile(`^7656119\d{10}$`)
+
+// A game on a Steam wishlist, Rank starts at 1
+type SteamWishlistItem struct {
+	AppId int
+	Rank  int
+}
+
+// Reads public data from Steam
+type SteamStore interface {
+	Wishlist(steamId string) ([]SteamWishlistItem, bool, error) //Ranked, false when private or unknown
+	AppName(appId int) (string, bool, error)
+}
+
+// Remembers which local game a Steam app was matched to
+type SteamRepository interface {
+	FindGameIds(appIds []int) (map[int]int, error) //Apps matched before, keyed by app id
+	Link(appId, gameId int) error
+}
+
+// Copies a public Steam wishlist into a library in rank order. Apps matched
+// before are taken as they were, the others are named by Steam and go
+// through the same name dedup as games added by hand. Games already in the
+// library keep their status and only get their rank.
+func (interactor *ProfileInteractor) ImportSteamWishlist(userId, libraryId int, steamId string) (ImportReport, error, int) {
+	if interactor.Steam == nil {
+		return ImportReport{}, domain.NewError(domain.CodeUnavailable,
+			"Steam imports are not configured"), 503
+	}
+	steamId = strings.TrimSpace(steamId)
+	if !steamIdPattern.MatchString(steamId) {
+		return ImportReport{}, domain.NewFieldError("steamId", "Must be a 17 digit Steam id"), 400
+	}
+	owned, err, code := interactor.importTarget(userId, libraryId)
+	if err != nil {
+		return ImportReport{}, err, code
+	}
+	items, found, err := interactor.Steam.Wishlist(steamId)
+	if err != nil {
+		return ImportReport{}, domain.NewError(domain.CodeUnavailable,
+			"Cannot read the Steam wishlist: %v", err), 502
+	}
+	if !found {
+		return ImportReport{}, domain.NewError(domain.CodeNotFound,
+			"The Steam wishlist of %s is private or does not exist", steamId), 404
+	}
+	if len(items) > maxImportRows {
+		items = items[:maxImportRows]
+	}
+	appIds := make([]int, len(items))
+	for i, item := range items {
+		appIds[i] = item.AppId
+	}
+	matched, err := interactor.SteamRepository.FindGameIds(appIds)
+	if err != nil {
+		return ImportReport{}, err, 500
+	}
+
+	report := ImportReport{Format: "steam"}
+	ranks := make(map[int]int)
+	var added []int
+	for _, item := range items {
+		row := ImportRow{Line: item.Rank, Status: "wishlist"}
+		game, err, code := interactor.steamGame(item.AppId, matched)
+		if code == 404 {
+			report.Unmatched = append(report.Unmatched, UnmatchedRow{Row: row, Reason: err.Error()})
+			continue
+		}
+		if err != nil {
+			return ImportReport{}, err, code
+		}
+		row.Title = game.Name
+		if present, found := owned[strings.ToLower(game.Name)]; found {
+			ranks[present.Id] = item.Rank
+			report.Skipped = append(report.Skipped, row)
+			continue
+		}
+
+		if game.Id > 0 {
+			err, code = interactor.PickGame(userId, libraryId, game.Id, 0)
+		} else {
+			game, err, code = interactor.AddGame(userId, libraryId, game, 0)
+		}
+		if code == 403 || code == 400 {
+			report.Unmatched = append(report.Unmatched, UnmatchedRow{Row: row, Reason: err.Error()})
+			continue
+		}
+		if err != nil {
+			return ImportReport{}, err, code
+		}
+		if _, linked := matched[item.AppId]; !linked {
+			err = interactor.SteamRepository.Link(item.AppId, game.Id)
+			if err != nil {
+				return ImportReport{}, err, 500
+			}
+		}
+		owned[strings.ToLower(game.Name)] = game
+		ranks[game.Id] = item.Rank
+		added = append(added, game.Id)
+		game.Status = "wishlist"
+		report.Imported = append(report.Imported, ImportedGame{Row: row, Game: game})
+	}
+
+	if len(added) > 0 {
+		status := "wishlist"
+		_, err = interactor.GameRepository.UpdateBatch(libraryId, added, GameChange{Status: &status}, nil)
+		if err != nil {
+			return ImportReport{}, err, 500
+		}
+	}
+	if len(ranks) > 0 {
+		err = interactor.GameRepository.RankWishlist(libraryId, ranks)
+		if err != nil {
+			return ImportReport{}, err, 500
+		}
+	}
+	interactor.count("ImportSteamWishlist")
+	interactor.logf("User #%d imported %d wishlisted games from Steam into library #%d, %d unmatched",
+		userId, len(report.Imported), libraryId, len(report.Unmatched))
+	return report, nil, 200
+}
+
+// The local game of an app, or one named by Steam with a zero id when the
+// app was never matched
+func (interactor *ProfileInteractor) steamGame(appId int, matched map[int]int) (Game, error, int) {
+	if gameId, found := matched[appId]; found {
+		return interactor.GameRepository.FindById(gameId)
+	}
+	name, found, err := interactor.Steam.AppName(appId)
+	if err != nil {
+		return Game{}, domain.NewError(domain.CodeUnavailable,
+			"Cannot look up Steam app #%d: %v", appId, err), 502
+	}
+	if !found || strings.TrimSpace(name) == "" {
+		return Game{}, domain.NewError(domain.CodeNotFound, "Steam has no app #%d", appId), 404
+	}
+	return Game{Name: strings.TrimSpace(name)}, nil, 200
+}
@@ -0,0 +1,87 @@
+package usecases
+
+import (
+	"sort"
+	"time"
+)
+
+// Streak lengths in days that earn a badge
+var streakMilestones = []int{3, 7, 14, 30, 100, 365}
+
+// Days in a row with at least one play session, counted on the day a session
+// starts in the user's timezone. Days are dates at midnight UTC.
+type Streak struct {
+	Current       int //Still running when something was played today or yesterday
+	CurrentSince  time.Time
+	Longest       int
+	LongestFrom   time.Time
+	LongestTo     time.Time
+	PlayedToday   bool
+	Milestones    []int //Reached by the longest streak
+	NextMilestone int   //0 once every milestone is reached
+}
+
+func (interactor *CalendarInteractor) ShowStreak(userId int) (Streak, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return Streak{}, err, code
+	}
+	now := time.Now()
+	sessions, err := interactor.PlaySessionRepository.FindByUser(userId, time.Time{}, now)
+	if err != nil {
+		return Streak{}, err, 500
+	}
+	return computeStreak(sessions, now, userLocation(interactor.SettingsRepository, userId)), nil, 200
+}
+
+func computeStreak(sessions []PlaySession, now time.Time, location *time.Location) Streak {
+	played := make(map[time.Time]bool)
+	for _, session := range sessions {
+		played[localDay(session.StartsAt, location)] = true
+	}
+	var days []time.Time
+	for day := range played {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	var streak Streak
+	length := 0
+	for i, day := range days {
+		if i > 0 && days[i-1].AddDate(0, 0, 1).Equal(day) {
+			length++
+		} else {
+			length = 1
+		}
+		if length > streak.Longest {
+			streak.Longest = length
+			streak.LongestFrom = day.AddDate(0, 0, 1-length)
+			streak.LongestTo = day
+		}
+	}
+
+	today := localDay(now, location)
+	if length > 0 {
+		last := days[len(days)-1]
+		streak.PlayedToday = last.Equal(today)
+		if streak.PlayedToday || last.Equal(today.AddDate(0, 0, -1)) {
+			streak.Current = length
+			streak.CurrentSince = last.AddDate(0, 0, 1-length)
+		}
+	}
+	for _, milestone := range streakMilestones {
+		if streak.Longest >= milestone {
+			streak.Milestones = append(streak.Milestones, milestone)
+		} else if streak.NextMilestone == 0 {
+			streak.NextMilestone = milestone
+		}
+	}
+	return streak
+}
+
+// The calendar date of t in location, as midnight UTC so days can be
+// counted without daylight saving getting in the way
+func localDay(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.In(location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
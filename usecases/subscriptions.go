@@ -0,0 +1,400 @@
+package usecases
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"game-tracker/domain"
+)
+
+const (
+	maxSubscriptions          = 50 //Per user
+	maxSubscriptionNameLength = 100
+	maxSubscriptionGames      = 1000
+	daysPerMonth              = 365.25 / 12
+)
+
+var billingPeriods = map[int]bool{1: true, 3: true, 6: true, 12: true}
+
+type SubscriptionRepository interface {
+	Store(subscription Subscription) (int, error)
+	FindById(id int) (Subscription, error, int)
+	FindByUser(userId int) ([]Subscription, error)        //By service
+	FindRenewing(until time.Time) ([]Subscription, error) //Not ended, renewing on or before until
+	FindActive(day time.Time) ([]Subscription, error)     //Started and not ended on the day
+	Update(subscription Subscription) error               //Forgets the reminder when the renewal date changes
+	SetRenewal(id int, renewsOn time.Time) error          //Forgets the reminder
+	MarkReminded(id int, at time.Time) error
+	AddGame(id, gameId int) error
+	RemoveGame(id, gameId int) error
+	Remove(subscription Subscription) error
+	RemoveAll(userId int) error
+}
+
+// A membership such as Game Pass or PS Plus, billed every BillingMonths.
+// Days are dates at midnight UTC, EndsOn is zero while it keeps renewing.
+type Subscription struct {
+	Id            int
+	UserId        int
+	Service       string
+	MonthlyCost   float64
+	Currency      string //ISO 4217
+	BillingMonths int
+	StartedOn     time.Time
+	RenewsOn      time.Time
+	EndsOn        time.Time
+	GameIds       []int  //Games played through the subscription
+	Games         []Game //Filled in by the interactor
+	RemindedAt    time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// What the subscriptions cost over a range against the hours played on
+// their games. Totals are kept per currency.
+type SubscriptionReport struct {
+	From          time.Time
+	To            time.Time
+	Subscriptions []SubscriptionUsage
+	Cost          map[string]float64
+	Minutes       int
+}
+
+type SubscriptionUsage struct {
+	Subscription Subscription
+	Cost         float64
+	Minutes      int
+	CostPerHour  float64 //0 without play
+	Games        []GameMinutes
+}
+
+type SubscriptionInteractor struct {
+	SubscriptionRepository SubscriptionRepository
+	UserRepository         UserRepository
+	LibraryRepository      LibraryRepository
+	GameRepository         GameRepository
+	PlaySessionRepository  PlaySessionRepository
+	SettingsRepository     SettingsRepository
+	EventBus               domain.EventBus
+	logging
+}
+
+func (interactor *SubscriptionInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.SubscriptionRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove subscriptions of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+// Fills in the currency and the next renewal, which follows the start by
+// whole billing periods
+func (interactor *SubscriptionInteractor) validSubscription(userId int, subscription Subscription) (Subscription, error, int) {
+	subscription.Service = strings.TrimSpace(subscription.Service)
+	if subscription.Service == "" {
+		return Subscription{}, domain.NewFieldError("service", "Service is required"), 400
+	}
+	if utf8.RuneCountInString(subscription.Service) > maxSubscriptionNameLength {
+		return Subscription{}, domain.NewFieldError("service", "Must be at most %d characters",
+			maxSubscriptionNameLength), 400
+	}
+	if subscription.MonthlyCost < 0 || subscription.MonthlyCost > maxPrice || math.IsNaN(subscription.MonthlyCost) {
+		return Subscription{}, domain.NewFieldError("monthlyCost", "Must be between 0 and %.0f", maxPrice), 400
+	}
+	if subscription.BillingMonths == 0 {
+		subscription.BillingMonths = 1
+	}
+	if !billingPeriods[subscription.BillingMonths] {
+		return Subscription{}, domain.NewFieldError("billingMonths", "Must be 1, 3, 6 or 12"), 400
+	}
+	today := localDay(time.Now(), userLocation(interactor.SettingsRepository, userId))
+	if subscription.StartedOn.IsZero() {
+		subscription.StartedOn = today
+	}
+	if subscription.StartedOn.After(today) {
+		return Subscription{}, domain.NewFieldError("startedOn", "Cannot be in the future"), 400
+	}
+	if !subscription.EndsOn.IsZero() && !subscription.EndsOn.After(subscription.StartedOn) {
+		return Subscription{}, domain.NewFieldError("endsOn", "Must be after the start"), 400
+	}
+	subscription.Currency = strings.ToUpper(strings.TrimSpace(subscription.Currency))
+	if subscription.Currency == "" {
+		settings, err := loadSettings(interactor.SettingsRepository, userId)
+		if err != nil {
+			return Subscription{}, err, 500
+		}
+		subscription.Currency = settings.DisplayCurrency
+	}
+	if !currencyPattern.MatchString(subscription.Currency) {
+		return Subscription{}, domain.NewFieldError("currency", "Currency '%s' is not an ISO 4217 code",
+			subscription.Currency), 400
+	}
+	subscription.RenewsOn = nextRenewal(subscription.StartedOn, subscription.BillingMonths, today)
+	return subscription, nil, 200
+}
+
+func (interactor *SubscriptionInteractor) withGames(subscription Subscription, err error, code int) (Subscription, error, int) {
+	if err != nil {
+		return Subscription{}, err, code
+	}
+	subscription.Games = nil
+	for _, gameId := range subscription.GameIds {
+		game, err, _ := interactor.GameRepository.FindById(gameId)
+		if err == nil {
+			subscription.Games = append(subscription.Games, game)
+		}
+	}
+	return subscription, nil, code
+}
+
+// The first renewal on or after today
+func nextRenewal(startedOn time.Time, billingMonths int, today time.Time) time.Time {
+	renewsOn := startedOn.AddDate(0, billingMonths, 0)
+	for periods := 2; renewsOn.Before(today); periods++ {
+		renewsOn = startedOn.AddDate(0, billingMonths*periods, 0)
+	}
+	return renewsOn
+}
+
+func (interactor *SubscriptionInteractor) AddSubscription(userId int, subscription Subscription) (Subscription, error, int) {
+	subscription, err, code := interactor.validSubscription(userId, subscription)
+	if err != nil {
+		return Subscription{}, err, code
+	}
+	_, err, code = interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return Subscription{}, err, code
+	}
+	subscriptions, err := interactor.SubscriptionRepository.FindByUser(userId)
+	if err != nil {
+		return Subscription{}, err, 500
+	}
+	if len(subscriptions) >= maxSubscriptions {
+		return Subscription{}, domain.NewError(domain.CodeConflict,
+			"User #%d already has %d subscriptions, remove one first", userId, maxSubscriptions), 409
+	}
+
+	subscription.UserId = userId
+	id, err := interactor.SubscriptionRepository.Store(subscription)
+	if err != nil {
+		return Subscription{}, err, 500
+	}
+	interactor.logf("User #%d added subscription #%d", userId, id)
+	subscription, err, code = interactor.withGames(interactor.SubscriptionRepository.FindById(id))
+	if err != nil {
+		return Subscription{}, err, code
+	}
+	return subscription, nil, 201
+}
+
+func (interactor *SubscriptionInteractor) ShowSubscriptions(userId int) ([]Subscription, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	subscriptions, err := interactor.SubscriptionRepository.FindByUser(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	for i := range subscriptions {
+		subscriptions[i], _, _ = interactor.withGames(subscriptions[i], nil, 200)
+	}
+	return subscriptions, nil, 200
+}
+
+func (interactor *SubscriptionInteractor) ShowSubscription(userId, subscriptionId int) (Subscription, error, int) {
+	return interactor.withGames(interactor.findSubscription(userId, subscriptionId))
+}
+
+// Replaces every field but the games, setting EndsOn cancels the renewals
+func (interactor *SubscriptionInteractor) EditSubscription(userId, subscriptionId int, changed Subscription) (Subscription, error, int) {
+	changed, err, code := interactor.validSubscription(userId, changed)
+	if err != nil {
+		return Subscription{}, err, code
+	}
+	subscription, err, code := interactor.findSubscription(userId, subscriptionId)
+	if err != nil {
+		return Subscription{}, err, code
+	}
+	changed.Id = subscription.Id
+	changed.UserId = subscription.UserId
+	err = interactor.SubscriptionRepository.Update(changed)
+	if err != nil {
+		return Subscription{}, err, 500
+	}
+	interactor.logf("User #%d edited subscription #%d", userId, subscriptionId)
+	return interactor.withGames(interactor.SubscriptionRepository.FindById(subscriptionId))
+}
+
+func (interactor *SubscriptionInteractor) RemoveSubscription(userId, subscriptionId int) (error, int) {
+	subscription, err, code := interactor.findSubscription(userId, subscriptionId)
+	if err != nil {
+		return err, code
+	}
+	err = interactor.SubscriptionRepository.Remove(subscription)
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("User #%d removed subscription #%d", userId, subscriptionId)
+	return nil, 200
+}
+
+// Marks a game in one of the user's libraries as played through the subscription
+func (interactor *SubscriptionInteractor) AddSubscriptionGame(userId, subscriptionId, gameId int) (Subscription, error, int) {
+	subscription, err, code := interactor.findSubscription(userId, subscriptionId)
+	if err != nil {
+		return Subscription{}, err, code
+	}
+	if len(subscription.GameIds) >= maxSubscriptionGames {
+		return Subscription{}, domain.NewError(domain.CodeConflict,
+			"Subscription #%d already has %d games", subscriptionId, maxSubscriptionGames), 409
+	}
+	_, err, code = findOwnedGame(interactor.UserRepository, interactor.LibraryRepository,
+		interactor.GameRepository, userId, gameId)
+	if err != nil {
+		return Subscription{}, err, code
+	}
+	err = interactor.SubscriptionRepository.AddGame(subscriptionId, gameId)
+	if err != nil {
+		return Subscription{}, err, 500
+	}
+	return interactor.withGames(interactor.SubscriptionRepository.FindById(subscriptionId))
+}
+
+func (interactor *SubscriptionInteractor) RemoveSubscriptionGame(userId, subscriptionId, gameId int) (Subscription, error, int) {
+	_, err, code := interactor.findSubscription(userId, subscriptionId)
+	if err != nil {
+		return Subscription{}, err, code
+	}
+	err = interactor.SubscriptionRepository.RemoveGame(subscriptionId, gameId)
+	if err != nil {
+		return Subscription{}, err, 500
+	}
+	return interactor.withGames(interactor.SubscriptionRepository.FindById(subscriptionId))
+}
+
+// Cost of every subscription while it ran within the range, against the
+// sessions played on its games in the same time
+func (interactor *SubscriptionInteractor) ShowSubscriptionReport(userId int, from, to time.Time) (SubscriptionReport, error, int) {
+	if !to.After(from) {
+		return SubscriptionReport{}, domain.NewFieldError("to", "Must be after from"), 400
+	}
+	subscriptions, err, code := interactor.ShowSubscriptions(userId)
+	if err != nil {
+		return SubscriptionReport{}, err, code
+	}
+	sessions, err := interactor.PlaySessionRepository.FindByUser(userId, from, to)
+	if err != nil {
+		return SubscriptionReport{}, err, 500
+	}
+
+	now := time.Now()
+	report := SubscriptionReport{From: from, To: to, Cost: make(map[string]float64)}
+	for _, subscription := range subscriptions {
+		usage := SubscriptionUsage{Subscription: subscription}
+		start, end := subscription.StartedOn, to
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(now) {
+			end = now
+		}
+		if !subscription.EndsOn.IsZero() && subscription.EndsOn.Before(end) {
+			end = subscription.EndsOn
+		}
+		if end.After(start) {
+			days := end.Sub(start).Hours() / 24
+			usage.Cost = math.Round(subscription.MonthlyCost*days/daysPerMonth*100) / 100
+		}
+
+		games := make(map[int]bool)
+		for _, gameId := range subscription.GameIds {
+			games[gameId] = true
+		}
+		played := make(map[int]*GameMinutes)
+		for _, session := range sessions {
+			if !games[session.GameId] || session.StartsAt.Before(start) || !session.StartsAt.Before(end) {
+				continue
+			}
+			game := played[session.GameId]
+			if game == nil {
+				game = &GameMinutes{GameId: session.GameId, GameExternalId: session.GameExternalId,
+					GameName: session.GameName}
+				played[session.GameId] = game
+			}
+			game.Minutes += session.Minutes
+			usage.Minutes += session.Minutes
+		}
+		for _, game := range played {
+			usage.Games = append(usage.Games, *game)
+		}
+		sort.Slice(usage.Games, func(i, j int) bool {
+			return usage.Games[i].Minutes > usage.Games[j].Minutes
+		})
+		if usage.Minutes > 0 {
+			usage.CostPerHour = math.Round(usage.Cost/(float64(usage.Minutes)/60)*100) / 100
+		}
+		report.Cost[subscription.Currency] += usage.Cost
+		report.Minutes += usage.Minutes
+		report.Subscriptions = append(report.Subscriptions, usage)
+	}
+	return report, nil, 200
+}
+
+// Run by the subscription job: renewal dates that passed move on by a
+// billing period, and owners are reminded once before each renewal
+func (interactor *SubscriptionInteractor) RemindRenewals(lead time.Duration) error {
+	now := time.Now().UTC()
+	subscriptions, err := interactor.SubscriptionRepository.FindRenewing(now.Add(lead))
+	if err != nil {
+		return err
+	}
+	reminded := 0
+	for _, subscription := range subscriptions {
+		if subscription.RenewsOn.Before(today()) {
+			subscription.RenewsOn = nextRenewal(subscription.StartedOn, subscription.BillingMonths, today())
+			subscription.RemindedAt = time.Time{}
+			err = interactor.SubscriptionRepository.SetRenewal(subscription.Id, subscription.RenewsOn)
+			if err != nil {
+				return err
+			}
+		}
+		if !subscription.RemindedAt.IsZero() || subscription.RenewsOn.After(now.Add(lead)) ||
+			(!subscription.EndsOn.IsZero() && !subscription.EndsOn.After(subscription.RenewsOn)) {
+			continue
+		}
+		err = interactor.SubscriptionRepository.MarkReminded(subscription.Id, now)
+		if err != nil {
+			return err
+		}
+		if interactor.EventBus != nil {
+			interactor.EventBus.Publish(domain.Event{Name: domain.EventSubscriptionRenewing,
+				UserId: subscription.UserId, EntityId: subscription.Id, Payload: map[string]string{
+					"service": subscription.Service, "date": subscription.RenewsOn.Format("2006-01-02"),
+					"cost": fmt.Sprintf("%.2f %s", subscription.MonthlyCost*float64(subscription.BillingMonths),
+						subscription.Currency)}})
+		}
+		reminded++
+	}
+	if reminded > 0 {
+		interactor.logf("Reminded users of %d subscription renewals", reminded)
+	}
+	return nil
+}
+
+func (interactor *SubscriptionInteractor) findSubscription(userId, subscriptionId int) (Subscription, error, int) {
+	subscription, err, code := interactor.SubscriptionRepository.FindById(subscriptionId)
+	if code == 404 || (err == nil && subscription.UserId != userId) {
+		return Subscription{}, domain.NewError(domain.CodeNotFound, "Subscription #%d does not exist",
+			subscriptionId), 404
+	}
+	if err != nil {
+		return Subscription{}, err, code
+	}
+	return subscription, nil, 200
+}
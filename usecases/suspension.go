@@ -0,0 +1,114 @@
+package usecases
+
+import (
+	"time"
+
+	"game-tracker/domain"
+)
+
+// A status set by an admin, Until only applies to suspensions and a nil
+// Until suspends until the user is reinstated
+type StatusChange struct {
+	Status string
+	Reason string
+	Until  *time.Time
+}
+
+// Suspensions lapse on their own once their end has passed, the stored
+// status is left as it is until an admin changes it
+func (user User) StatusAt(t time.Time) string {
+	if user.Status == StatusSuspended && user.SuspendedUntil != nil &&
+		!t.Before(*user.SuspendedUntil) {
+		return StatusActive
+	}
+	return user.Status
+}
+
+// Describes why the account may not be used, nil for active accounts
+func blockedAccount(user User) error {
+	switch user.StatusAt(time.Now()) {
+	case StatusActive:
+		return nil
+	case StatusSuspended:
+		if user.SuspendedUntil != nil {
+			return domain.NewError(domain.CodeForbidden, "Account is suspended until %s: %s",
+				user.SuspendedUntil.Format(time.RFC3339), user.StatusReason)
+		}
+	}
+	return domain.NewError(domain.CodeForbidden, "Account is %s: %s", user.Status,
+		user.StatusReason)
+}
+
+// Suspended and banned users may still read their data but not change it
+func (interactor *ProfileInteractor) CheckWritable(userId int) (error, int) {
+	user, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return err, code
+	}
+	err = blockedAccount(user)
+	if err != nil {
+		return err, 403
+	}
+	return nil, 200
+}
+
+// A nil until suspends the user until reinstated
+func (interactor *AdminInteractor) Suspend(adminId, userId int, reason string, until *time.Time) (User, error, int) {
+	if until != nil && !until.After(time.Now()) {
+		return User{}, domain.NewFieldError("until", "Must be in the future"), 400
+	}
+	return interactor.changeStatus(adminId, userId,
+		StatusChange{Status: StatusSuspended, Reason: reason, Until: until})
+}
+
+func (interactor *AdminInteractor) Ban(adminId, userId int, reason string) (User, error, int) {
+	return interactor.changeStatus(adminId, userId,
+		StatusChange{Status: StatusBanned, Reason: reason})
+}
+
+func (interactor *AdminInteractor) Reinstate(adminId, userId int, reason string) (User, error, int) {
+	return interactor.changeStatus(adminId, userId,
+		StatusChange{Status: StatusActive, Reason: reason})
+}
+
+// Every change is audited with its reason, the change and its audit entry
+// are not atomic so a failed audit is reported as an error
+func (interactor *AdminInteractor) changeStatus(adminId, userId int, change StatusChange) (User, error, int) {
+	err, code := interactor.requireAdmin(adminId)
+	if err != nil {
+		return User{}, err, code
+	}
+	if change.Reason == "" {
+		return User{}, domain.NewFieldError("reason", "A reason is required"), 400
+	}
+	if adminId == userId {
+		err = domain.NewError(domain.CodeForbidden, "Admins cannot change their own status")
+		return User{}, err, 403
+	}
+	user, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return User{}, err, code
+	}
+	if user.Role == RoleAdmin && change.Status != StatusActive {
+		err = domain.NewError(domain.CodeForbidden, "Admins cannot be suspended or banned")
+		return User{}, err, 403
+	}
+
+	err = interactor.AdminRepository.SetStatus(userId, change)
+	if err != nil {
+		return User{}, err, 500
+	}
+	detail := change.Status
+	if change.Until != nil {
+		detail += " until " + change.Until.UTC().Format(time.RFC3339)
+	}
+	err = interactor.AdminRepository.Audit(AuditEntry{ActorId: adminId, Action: AuditStatus,
+		TargetId: userId, Detail: detail + ": " + change.Reason})
+	if err != nil {
+		return User{}, err, 500
+	}
+	interactor.logf("Admin #%d set status of user #%d to %s", adminId, userId, change.Status)
+
+	user.Status, user.StatusReason, user.SuspendedUntil = change.Status, change.Reason, change.Until
+	return user, nil, 200
+}
@@ -0,0 +1,80 @@
+package usecases
+
+import (
+	"strconv"
+	"time"
+
+	"game-tracker/domain"
+)
+
+const maxChangesPerSync = 500
+
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+type ChangeRepository interface {
+	FindSince(userId int, after int64, limit int) ([]Change, error)
+	// Changes of the children of the entity parentId made before until,
+	// such as the games of a library, by id
+	FindByParent(parentId string, until time.Time) ([]Change, error)
+}
+
+// One entry of the change log, EntityId and ParentId are external ids
+type Change struct {
+	Id        int64
+	UserId    int
+	Entity    string
+	EntityId  string
+	ParentId  string //Library of a game, empty for users and libraries
+	Action    string
+	ChangedAt time.Time
+}
+
+type SyncInteractor struct {
+	ChangeRepository   ChangeRepository
+	UserRepository     UserRepository
+	SettingsRepository SettingsRepository
+}
+
+// Returns the changes made after cursor, the cursor to resume from and
+// whether more changes are waiting. An empty cursor starts from the beginning.
+func (interactor *SyncInteractor) Sync(userId int, cursor string, limit int) ([]Change, string, bool, error, int) {
+	if limit < 1 || limit > maxChangesPerSync {
+		err := domain.NewFieldError("limit", "Must be between 1 and %d", maxChangesPerSync)
+		return nil, "", false, err, 400
+	}
+	var after int64
+	if cursor != "" {
+		var err error
+		after, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil || after < 0 {
+			return nil, "", false, domain.NewFieldError("cursor", "Cursor is invalid"), 400
+		}
+	}
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, "", false, err, code
+	}
+
+	changes, err := interactor.ChangeRepository.FindSince(userId, after, limit+1)
+	if err != nil {
+		return nil, "", false, err, 500
+	}
+	hasMore := len(changes) > limit
+	if hasMore {
+		changes = changes[:limit]
+	}
+
+	next := cursor
+	if len(changes) > 0 {
+		next = strconv.FormatInt(changes[len(changes)-1].Id, 10)
+	}
+	location := userLocation(interactor.SettingsRepository, userId)
+	for i := range changes {
+		changes[i].ChangedAt = changes[i].ChangedAt.In(location)
+	}
+	return changes, next, hasMore, nil, 200
+}
@@ -0,0 +1,113 @@
+package usecases
+
+import (
+	"strconv"
+	"strings"
+
+	"game-tracker/domain"
+)
+
+// How many entries over the user's libraries carry a tag
+type TagCount struct {
+	Tag   string
+	Games int
+}
+
+// The outcome of renaming or merging a tag
+type TagChange struct {
+	From      string
+	To        string
+	Games     int
+	Libraries int
+}
+
+func (interactor *ProfileInteractor) ShowTags(userId int) ([]TagCount, error, int) {
+	user, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	tags, err := interactor.GameRepository.FindTags(user.LibraryIds)
+	if err != nil {
+		return nil, err, 500
+	}
+	return tags, nil, 200
+}
+
+func validTag(field, tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" || len(tag) > maxTagLength {
+		return tag, domain.NewFieldError(field, "Tags must be 1 to %d characters", maxTagLength)
+	}
+	return tag, nil
+}
+
+// Renames a tag in every library of the user, to must not be in use yet so
+// a rename never silently merges two tags
+func (interactor *ProfileInteractor) RenameTag(userId int, from, to string) (TagChange, error, int) {
+	return interactor.replaceTag(userId, from, to, false)
+}
+
+// Replaces from by into in every library of the user, entries carrying both
+// keep into once
+func (interactor *ProfileInteractor) MergeTags(userId int, from, into string) (TagChange, error, int) {
+	return interactor.replaceTag(userId, from, into, true)
+}
+
+func (interactor *ProfileInteractor) replaceTag(userId int, from, to string, merge bool) (TagChange, error, int) {
+	toField := "to"
+	if merge {
+		toField = "into"
+	}
+	from, err := validTag("from", from)
+	if err != nil {
+		return TagChange{}, err, 400
+	}
+	to, err = validTag(toField, to)
+	if err != nil {
+		return TagChange{}, err, 400
+	}
+	if from == to {
+		return TagChange{}, domain.NewFieldError(toField, "Must differ from '%s'", from), 400
+	}
+
+	user, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return TagChange{}, err, code
+	}
+	tags, err := interactor.GameRepository.FindTags(user.LibraryIds)
+	if err != nil {
+		return TagChange{}, err, 500
+	}
+	used := make(map[string]bool)
+	for _, tag := range tags {
+		used[tag.Tag] = true
+	}
+	if !used[from] {
+		return TagChange{}, domain.NewError(domain.CodeNotFound,
+			"Tag '%s' is not used in any library of user #%d", from, userId), 404
+	}
+	if merge && !used[to] {
+		return TagChange{}, domain.NewError(domain.CodeNotFound,
+			"Tag '%s' is not used in any library of user #%d", to, userId), 404
+	}
+	if !merge && used[to] {
+		return TagChange{}, domain.NewError(domain.CodeConflict,
+			"Tag '%s' is already used, merge the tags instead", to), 409
+	}
+
+	changed, err := interactor.GameRepository.ReplaceTags(user.LibraryIds, []string{from}, to)
+	if err != nil {
+		return TagChange{}, err, 500
+	}
+	result := TagChange{From: from, To: to}
+	for libraryId, games := range changed {
+		result.Games += games
+		result.Libraries++
+		// Library versions were bumped, subscribers holding tags drop them
+		interactor.publish(domain.Event{Name: domain.EventTagsChanged, UserId: userId,
+			EntityId: libraryId, Payload: map[string]string{"from": from, "to": to,
+				"games": strconv.Itoa(games)}})
+	}
+	interactor.count("ReplaceTags")
+	return result, nil, 200
+}
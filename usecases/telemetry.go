@@ -0,0 +1,41 @@
+package usecases
+
+// Receives anonymous usage stats when telemetry is opted into, nothing
+// passed to it identifies a user or their data
+type Reporter interface {
+	Count(usecase string)
+	LibrarySize(bucket string)
+}
+
+// Upper bounds of the library size buckets, larger libraries go to the last one
+var librarySizeBuckets = []struct {
+	max  int
+	name string
+}{
+	{0, "0"},
+	{9, "1-9"},
+	{49, "10-49"},
+	{199, "50-199"},
+	{999, "200-999"},
+}
+
+func librarySizeBucket(games int) string {
+	for _, bucket := range librarySizeBuckets {
+		if games <= bucket.max {
+			return bucket.name
+		}
+	}
+	return "1000+"
+}
+
+func (interactor *ProfileInteractor) count(usecase string) {
+	if interactor.Reporter != nil {
+		interactor.Reporter.Count(usecase)
+	}
+}
+
+func (interactor *ProfileInteractor) reportLibrary(library Library) {
+	if interactor.Reporter != nil {
+		interactor.Reporter.LibrarySize(librarySizeBucket(len(library.GameIds)))
+	}
+}
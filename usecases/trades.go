@@ -0,0 +1,343 @@
+package usecases
+
+import (
+	"time"
+
+	"game-tracker/domain"
+)
+
+const (
+	TradePending   = "pending"
+	TradeAccepted  = "accepted"
+	TradeDeclined  = "declined"
+	TradeCountered = "countered"
+	TradeCancelled = "cancelled"
+)
+
+const (
+	maxTradeCopies   = 20 //Per side of an offer
+	maxTradesPerPage = 100
+)
+
+type TradeRepository interface {
+	Store(offer TradeOffer) (int, error)
+	FindById(id int) (TradeOffer, error, int)
+	FindByUser(userId int, page Page) ([]TradeOffer, error) //Proposed and received, newest first
+	SetStatus(id int, from, to string) (bool, error)
+	// Moves the copies of a pending offer between the two libraries and marks
+	// it accepted, with an audit entry per copy. False when the offer is no
+	// longer pending or one of its copies left its library, nothing moves then.
+	Transfer(offer TradeOffer, actorId int) (bool, error)
+	CancelForLib(libraryId int) error //Pending offers either side of the library
+}
+
+// A proposal to swap physical copies. The proposer gives the Offered copies
+// from their library and gets the Requested copies of the recipient's.
+type TradeOffer struct {
+	Id                 int
+	ProposerId         int
+	ProposerLibraryId  int
+	RecipientId        int
+	RecipientLibraryId int
+	Offered            []int //Copy ids
+	Requested          []int
+	Status             string
+	CounterOf          int //The offer this one answers, 0 for new offers
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	// Set by the interactor, empty once the user or library is removed
+	ProposerExternalId         string
+	ProposerLibraryExternalId  string
+	RecipientExternalId        string
+	RecipientLibraryExternalId string
+}
+
+// Offers copies of a library the user edits for copies of a library the
+// recipient edits. One side may be empty to give or ask for copies.
+func (interactor *ProfileInteractor) ProposeTrade(userId int, offer TradeOffer) (TradeOffer, error, int) {
+	offer, err, code := interactor.checkTrade(userId, offer)
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	offer.Status = TradePending
+	offer.Id, err = interactor.TradeRepository.Store(offer)
+	if err != nil {
+		return TradeOffer{}, err, 500
+	}
+	interactor.count("ProposeTrade")
+	interactor.logf("User #%d proposed trade #%d to user #%d", userId, offer.Id, offer.RecipientId)
+	return interactor.findTrade(offer.Id)
+}
+
+// Newest first, the cursor of the next page is zero on the last page
+func (interactor *ProfileInteractor) ShowTrades(userId int, page Page) ([]TradeOffer, Cursor, error, int) {
+	err := validPage(page, maxTradesPerPage)
+	if err != nil {
+		return nil, Cursor{}, err, 400
+	}
+	_, err = page.After.Time()
+	if err != nil {
+		return nil, Cursor{}, err, 400
+	}
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, Cursor{}, err, code
+	}
+	offers, err := interactor.TradeRepository.FindByUser(userId, page.probe())
+	if err != nil {
+		return nil, Cursor{}, err, 500
+	}
+	count, more := page.cut(len(offers))
+	offers = offers[:count]
+	var next Cursor
+	if more {
+		last := offers[count-1]
+		next = timeCursor(last.CreatedAt, last.Id)
+	}
+	for i := range offers {
+		offers[i], err, code = interactor.describeTrade(offers[i])
+		if err != nil {
+			return nil, Cursor{}, err, code
+		}
+	}
+	return offers, next, nil, 200
+}
+
+// Offers between other users look like they do not exist
+func (interactor *ProfileInteractor) ShowTrade(userId, tradeId int) (TradeOffer, error, int) {
+	offer, err, code := interactor.TradeRepository.FindById(tradeId)
+	if code == 404 || (err == nil && offer.ProposerId != userId && offer.RecipientId != userId) {
+		return TradeOffer{}, domain.NewError(domain.CodeNotFound, "Trade #%d does not exist", tradeId), 404
+	}
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	return interactor.describeTrade(offer)
+}
+
+// Swaps the copies. Games a side did not have yet are added to its library,
+// games a side gave its last copy of leave it.
+func (interactor *ProfileInteractor) AcceptTrade(userId, tradeId int) (TradeOffer, error, int) {
+	offer, err, code := interactor.receivedTrade(userId, tradeId)
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	err, code = interactor.checkTradeGames(offer.RecipientId, offer.Offered)
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	err, code = interactor.checkTradeGames(offer.ProposerId, offer.Requested)
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	moved, err := interactor.TradeRepository.Transfer(offer, userId)
+	if err != nil {
+		return TradeOffer{}, err, 500
+	}
+	if !moved {
+		return TradeOffer{}, domain.NewError(domain.CodeConflict,
+			"Trade #%d can no longer be accepted, its copies or status changed", tradeId), 409
+	}
+	interactor.count("AcceptTrade")
+	interactor.logf("User #%d accepted trade #%d of user #%d", userId, tradeId, offer.ProposerId)
+	return interactor.findTrade(tradeId)
+}
+
+func (interactor *ProfileInteractor) DeclineTrade(userId, tradeId int) (TradeOffer, error, int) {
+	_, err, code := interactor.receivedTrade(userId, tradeId)
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	return interactor.closeTrade(userId, tradeId, TradeDeclined)
+}
+
+// Proposers withdraw their own offers while pending
+func (interactor *ProfileInteractor) CancelTrade(userId, tradeId int) (TradeOffer, error, int) {
+	offer, err, code := interactor.ShowTrade(userId, tradeId)
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	if offer.ProposerId != userId {
+		return TradeOffer{}, domain.NewError(domain.CodeForbidden,
+			"Only the proposer can cancel trade #%d, decline it instead", tradeId), 403
+	}
+	return interactor.closeTrade(userId, tradeId, TradeCancelled)
+}
+
+// Answers a received offer with a new one the other way round, the libraries
+// stay those of the original offer. The original is closed as countered.
+func (interactor *ProfileInteractor) CounterTrade(userId, tradeId int, offered, requested []int) (TradeOffer, error, int) {
+	original, err, code := interactor.receivedTrade(userId, tradeId)
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	counter, err, code := interactor.checkTrade(userId, TradeOffer{ProposerLibraryId: original.RecipientLibraryId,
+		RecipientId: original.ProposerId, RecipientLibraryId: original.ProposerLibraryId,
+		Offered: offered, Requested: requested, CounterOf: tradeId})
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	closed, err := interactor.TradeRepository.SetStatus(tradeId, TradePending, TradeCountered)
+	if err != nil {
+		return TradeOffer{}, err, 500
+	}
+	if !closed {
+		return TradeOffer{}, domain.NewError(domain.CodeConflict, "Trade #%d is no longer pending", tradeId), 409
+	}
+	counter.Status = TradePending
+	counter.Id, err = interactor.TradeRepository.Store(counter)
+	if err != nil {
+		return TradeOffer{}, err, 500
+	}
+	interactor.count("CounterTrade")
+	interactor.logf("User #%d countered trade #%d with trade #%d", userId, tradeId, counter.Id)
+	return interactor.findTrade(counter.Id)
+}
+
+// The offer with its proposer set, once both libraries and all copies check out
+func (interactor *ProfileInteractor) checkTrade(userId int, offer TradeOffer) (TradeOffer, error, int) {
+	if len(offer.Offered)+len(offer.Requested) == 0 {
+		return TradeOffer{}, domain.NewFieldError("offered", "A trade needs at least one copy"), 400
+	}
+	if len(offer.Offered) > maxTradeCopies || len(offer.Requested) > maxTradeCopies {
+		return TradeOffer{}, domain.NewFieldError("offered", "At most %d copies can be traded each way",
+			maxTradeCopies), 400
+	}
+	if offer.RecipientId == userId {
+		return TradeOffer{}, domain.NewFieldError("recipientId", "Cannot trade with yourself"), 400
+	}
+	library, err, code := interactor.LibraryRepository.FindById(offer.ProposerLibraryId)
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	allowed, err := interactor.libraryAllows(userId, library, LibraryRoleEditor)
+	if err != nil {
+		return TradeOffer{}, err, 500
+	}
+	if !allowed {
+		message := "User #%d is not allowed to trade copies of library #%d of user #%d"
+		err := domain.NewError(domain.CodeForbidden, message, userId, library.Id, library.User.Id)
+		return TradeOffer{}, err, 403
+	}
+	recipientLibrary, err, code := interactor.LibraryRepository.FindById(offer.RecipientLibraryId)
+	if err == nil {
+		allowed, err = interactor.libraryAllows(offer.RecipientId, recipientLibrary, LibraryRoleEditor)
+		if err != nil {
+			return TradeOffer{}, err, 500
+		}
+	}
+	if code == 404 || (err == nil && !allowed) {
+		return TradeOffer{}, domain.NewFieldError("recipientLibraryId",
+			"Library #%d of user #%d does not exist", offer.RecipientLibraryId, offer.RecipientId), 400
+	}
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	err, code = interactor.checkTradeCopies("offered", offer.Offered, offer.ProposerLibraryId)
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	err, code = interactor.checkTradeCopies("requested", offer.Requested, offer.RecipientLibraryId)
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	offer.ProposerId = userId
+	return offer, nil, 200
+}
+
+func (interactor *ProfileInteractor) checkTradeCopies(field string, copyIds []int, libraryId int) (error, int) {
+	seen := make(map[int]bool)
+	for _, id := range copyIds {
+		if seen[id] {
+			return domain.NewFieldError(field, "Copy #%d is listed twice", id), 400
+		}
+		seen[id] = true
+		physical, err, code := interactor.PhysicalCopyRepository.FindById(id)
+		if code == 404 || (err == nil && physical.LibraryId != libraryId) {
+			return domain.NewFieldError(field, "Copy #%d is not in library #%d", id, libraryId), 400
+		}
+		if err != nil {
+			return err, code
+		}
+	}
+	return nil, 200
+}
+
+// Parental controls apply to games received through trades as well
+func (interactor *ProfileInteractor) checkTradeGames(userId int, copyIds []int) (error, int) {
+	for _, id := range copyIds {
+		physical, err, code := interactor.PhysicalCopyRepository.FindById(id)
+		if err != nil {
+			return err, code
+		}
+		game, err, code := interactor.GameRepository.FindById(physical.GameId)
+		if err != nil {
+			return err, code
+		}
+		err, code = interactor.Parental.CheckGame(userId, game)
+		if err != nil {
+			return err, code
+		}
+	}
+	return nil, 200
+}
+
+// Only recipients accept, decline or counter, and only pending offers
+func (interactor *ProfileInteractor) receivedTrade(userId, tradeId int) (TradeOffer, error, int) {
+	offer, err, code := interactor.ShowTrade(userId, tradeId)
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	if offer.RecipientId != userId {
+		return TradeOffer{}, domain.NewError(domain.CodeForbidden,
+			"Only the recipient can answer trade #%d", tradeId), 403
+	}
+	if offer.Status != TradePending {
+		return TradeOffer{}, domain.NewError(domain.CodeConflict,
+			"Trade #%d is %s, only pending trades can be answered", tradeId, offer.Status), 409
+	}
+	return offer, nil, 200
+}
+
+func (interactor *ProfileInteractor) closeTrade(userId, tradeId int, status string) (TradeOffer, error, int) {
+	closed, err := interactor.TradeRepository.SetStatus(tradeId, TradePending, status)
+	if err != nil {
+		return TradeOffer{}, err, 500
+	}
+	if !closed {
+		return TradeOffer{}, domain.NewError(domain.CodeConflict, "Trade #%d is no longer pending", tradeId), 409
+	}
+	interactor.logf("User #%d %s trade #%d", userId, status, tradeId)
+	return interactor.findTrade(tradeId)
+}
+
+func (interactor *ProfileInteractor) findTrade(tradeId int) (TradeOffer, error, int) {
+	offer, err, code := interactor.TradeRepository.FindById(tradeId)
+	if err != nil {
+		return TradeOffer{}, err, code
+	}
+	return interactor.describeTrade(offer)
+}
+
+// Fills in the external ids of both sides
+func (interactor *ProfileInteractor) describeTrade(offer TradeOffer) (TradeOffer, error, int) {
+	for _, side := range []struct {
+		userId, libraryId             int
+		userExternal, libraryExternal *string
+	}{
+		{offer.ProposerId, offer.ProposerLibraryId, &offer.ProposerExternalId, &offer.ProposerLibraryExternalId},
+		{offer.RecipientId, offer.RecipientLibraryId, &offer.RecipientExternalId, &offer.RecipientLibraryExternalId},
+	} {
+		user, err, code := interactor.UserRepository.FindById(side.userId)
+		if err != nil && code != 404 {
+			return TradeOffer{}, err, code
+		}
+		*side.userExternal = user.ExternalId
+		library, err, code := interactor.LibraryRepository.FindById(side.libraryId)
+		if err != nil && code != 404 {
+			return TradeOffer{}, err, code
+		}
+		*side.libraryExternal = library.ExternalId
+	}
+	return offer, nil, 200
+}
@@ -0,0 +1,228 @@
+package usecases
+
+import (
+	"strconv"
+	"time"
+
+	"game-tracker/domain"
+)
+
+// Kinds of trashed items
+const (
+	TrashedGame    = "game"
+	TrashedLibrary = "library"
+)
+
+// A game removed from a library or a library removed with its entries, kept
+// until it is restored or purged. LibraryId is the library the game was in
+// or the library removed.
+type TrashItem struct {
+	Id        int64
+	UserId    int //Who removed it, the only one who sees it
+	Kind      string
+	LibraryId int
+	Entries   []LibraryEntry
+	RemovedAt time.Time
+	// The library's external id and the games by id, only set on items
+	// shown to users. Removed libraries and games removed from the catalog
+	// since are left out.
+	LibraryExternalId string
+	Games             map[int]Game
+}
+
+type TrashRepository interface {
+	Store(item TrashItem) (int64, error)
+	FindByUser(userId int) ([]TrashItem, error) //Newest first
+	FindById(id int64) (TrashItem, error, int)
+	// False when the item is gone already, so two requests cannot restore
+	// it twice
+	Remove(id int64) (bool, error)
+	Purge(before time.Time) (int64, error) //Returns how many items were purged
+}
+
+// Keeps what was removed when the trash is on, failures are only logged as
+// the removal itself succeeded
+func (interactor *ProfileInteractor) trash(item TrashItem) {
+	if interactor.TrashRepository == nil {
+		return
+	}
+	_, err := interactor.TrashRepository.Store(item)
+	if err != nil {
+		interactor.logf("Cannot trash %s of library #%d: %v", item.Kind, item.LibraryId, err)
+	}
+}
+
+// Lists and restores what users removed, and purges it after Retention
+type TrashInteractor struct {
+	TrashRepository TrashRepository //Nil turns the trash off
+	Profile         ProfileInteractor
+	Retention       time.Duration
+}
+
+func (interactor *TrashInteractor) enabled() (error, int) {
+	if interactor.TrashRepository == nil {
+		return domain.NewError(domain.CodeUnavailable, "The trash is turned off"), 503
+	}
+	return nil, 200
+}
+
+// What the user removed within Retention, newest first
+func (interactor *TrashInteractor) ShowTrash(userId int) ([]TrashItem, error, int) {
+	err, code := interactor.enabled()
+	if err != nil {
+		return nil, err, code
+	}
+	_, err, code = interactor.Profile.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	items, err := interactor.TrashRepository.FindByUser(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	var recent []TrashItem
+	since := time.Now().Add(-interactor.Retention)
+	for _, item := range items {
+		// Items the purge has not reached yet are as good as gone
+		if item.RemovedAt.After(since) {
+			recent = append(recent, interactor.describe(userId, item))
+		}
+	}
+	return recent, nil, 200
+}
+
+// Fills in what users see of the item, in their time zone
+func (interactor *TrashInteractor) describe(userId int, item TrashItem) TrashItem {
+	location := userLocation(interactor.Profile.SettingsRepository, userId)
+	item.RemovedAt = item.RemovedAt.In(location)
+	library, err, _ := interactor.Profile.LibraryRepository.FindById(item.LibraryId)
+	if err == nil {
+		item.LibraryExternalId = library.ExternalId
+	}
+	item.Games = make(map[int]Game)
+	for _, entry := range item.Entries {
+		game, err, _ := interactor.Profile.GameRepository.FindById(entry.GameId)
+		if err == nil {
+			item.Games[entry.GameId] = game
+		}
+	}
+	return item
+}
+
+// Puts a game back into its library or a library back with its entries. A
+// restored library is a new library, with a new id. Games removed from the
+// catalog since are left out.
+func (interactor *TrashInteractor) RestoreFromTrash(userId int, itemId int64) (TrashItem, error, int) {
+	err, code := interactor.enabled()
+	if err != nil {
+		return TrashItem{}, err, code
+	}
+	user, err, code := interactor.Profile.UserRepository.FindById(userId)
+	if err != nil {
+		return TrashItem{}, err, code
+	}
+	item, err, code := interactor.TrashRepository.FindById(itemId)
+	if err != nil {
+		return TrashItem{}, err, code
+	}
+	if item.UserId != userId || item.RemovedAt.Before(time.Now().Add(-interactor.Retention)) {
+		return TrashItem{}, domain.NewError(domain.CodeNotFound,
+			"Item #%d is not in the trash of user #%d", itemId, userId), 404
+	}
+	var entries []LibraryEntry
+	for _, entry := range item.Entries {
+		_, err, code := interactor.Profile.GameRepository.FindById(entry.GameId)
+		if code == 404 {
+			continue
+		}
+		if err != nil {
+			return TrashItem{}, err, code
+		}
+		entries = append(entries, entry)
+	}
+
+	if item.Kind == TrashedGame {
+		library, err, code := interactor.Profile.LibraryRepository.FindById(item.LibraryId)
+		if code == 404 {
+			return TrashItem{}, domain.NewError(domain.CodeConflict,
+				"Library #%d was removed", item.LibraryId), 409
+		}
+		if err != nil {
+			return TrashItem{}, err, code
+		}
+		allowed, err := interactor.Profile.libraryAllows(userId, library, LibraryRoleEditor)
+		if err != nil {
+			return TrashItem{}, err, 500
+		}
+		if !allowed {
+			message := "User #%d is not allowed to edit games in library #%d of user #%d"
+			err := domain.NewError(domain.CodeForbidden, message, userId, library.Id, library.User.Id)
+			return TrashItem{}, err, 403
+		}
+	}
+
+	claimed, err := interactor.TrashRepository.Remove(item.Id)
+	if err != nil {
+		return TrashItem{}, err, 500
+	}
+	if !claimed {
+		return TrashItem{}, domain.NewError(domain.CodeConflict,
+			"Item #%d was restored meanwhile", item.Id), 409
+	}
+	libraryId, err := interactor.restore(user, item, entries)
+	if err != nil {
+		// Trashed again under a new id
+		interactor.Profile.trash(item)
+		return TrashItem{}, err, 500
+	}
+	item.LibraryId = libraryId
+	interactor.Profile.logf("User #%d restored %s #%d of library #%d", userId, item.Kind, item.Id,
+		item.LibraryId)
+	return interactor.describe(userId, item), nil, 200
+}
+
+// Writes the entries back, into a new library when the item is one.
+// Returns the id of the library restored into.
+func (interactor *TrashInteractor) restore(user User, item TrashItem, entries []LibraryEntry) (int, error) {
+	profile := &interactor.Profile
+	libraryId := item.LibraryId
+	if item.Kind == TrashedLibrary {
+		id, err := profile.LibraryRepository.Store(Library{User: user, GameIds: []int{}})
+		if err != nil {
+			return 0, err
+		}
+		profile.publish(domain.Event{Name: domain.EventLibraryAdded, UserId: user.Id, EntityId: id})
+		libraryId = id
+	}
+	if len(entries) == 0 {
+		return libraryId, nil
+	}
+	var gameIds []int
+	for _, entry := range entries {
+		gameIds = append(gameIds, entry.GameId)
+	}
+	err := profile.GameRepository.RestoreEntries(libraryId, gameIds, entries)
+	if err != nil {
+		return 0, err
+	}
+	for _, gameId := range gameIds {
+		profile.publish(domain.Event{Name: domain.EventGameAdded, UserId: user.Id, EntityId: gameId,
+			Payload: map[string]string{"libraryId": strconv.Itoa(libraryId)}})
+	}
+	return libraryId, nil
+}
+
+// Drops what was removed more than Retention ago, run by the trash job
+func (interactor *TrashInteractor) PurgeTrash() error {
+	if interactor.TrashRepository == nil {
+		return nil
+	}
+	purged, err := interactor.TrashRepository.Purge(time.Now().Add(-interactor.Retention))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		interactor.Profile.logf("Purged %d items from the trash", purged)
+	}
+	return nil
+}
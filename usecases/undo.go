@@ -0,0 +1,224 @@
+package usecases
+
+import (
+	"time"
+
+	"game-tracker/domain"
+)
+
+// Kinds of actions that can be undone
+const (
+	UndoRemoveGame  = "removeGame"
+	UndoUpdateGames = "updateGames"
+)
+
+// An action of a user with the library entries of GameIds before and after
+// it. Games missing from Before or After were not in the library then.
+type UndoAction struct {
+	Id        int64
+	UserId    int
+	Kind      string
+	LibraryId int
+	GameIds   []int
+	Before    []LibraryEntry
+	After     []LibraryEntry
+	CreatedAt time.Time
+	UndoneAt  time.Time //Zero unless undone
+	// The library's external id and the games by id, only set on actions
+	// shown to users. Games removed from the catalog since are left out.
+	LibraryExternalId string
+	Games             map[int]Game
+}
+
+type UndoRepository interface {
+	// Also forgets the user's undone actions, which cannot be redone once
+	// something else happened, and the actions created before since
+	Store(action UndoAction, since time.Time) (int64, error)
+	FindRecent(userId int, since time.Time) ([]UndoAction, error) //Newest first
+	// False when the action already was in that state, so two requests
+	// cannot undo it twice
+	SetUndone(id int64, undone bool) (bool, error)
+}
+
+// Remembers what an action changed when undo is on, failures are only
+// logged as the action itself succeeded
+func (interactor *ProfileInteractor) recordUndo(action UndoAction) {
+	if interactor.UndoRepository == nil {
+		return
+	}
+	_, err := interactor.UndoRepository.Store(action, time.Now().Add(-interactor.UndoWindow))
+	if err != nil {
+		interactor.logf("Cannot record %s of user #%d for undo: %v", action.Kind, action.UserId, err)
+	}
+}
+
+// The entries of the games as they are now, games not in the library are
+// left out. Fails on any other error, as a snapshot missing a game that is
+// still in the library would remove it when restored.
+func (interactor *ProfileInteractor) libraryEntries(libraryId int, gameIds []int) ([]LibraryEntry, error) {
+	var entries []LibraryEntry
+	for _, gameId := range gameIds {
+		game, err, code := interactor.GameRepository.FindInLib(gameId, libraryId)
+		if code == 404 {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, LibraryEntry{GameId: gameId, Status: game.Status,
+			Platform: game.Platform, Tags: game.Tags, WishlistRank: game.WishlistRank})
+	}
+	return entries, nil
+}
+
+// The games of the action in Before or After. Games in neither were not
+// in the library at either time, restoring leaves them alone.
+func (action UndoAction) snapshotIds() []int {
+	snapshot := make(map[int]bool)
+	for _, entries := range [][]LibraryEntry{action.Before, action.After} {
+		for _, entry := range entries {
+			snapshot[entry.GameId] = true
+		}
+	}
+	var gameIds []int
+	for _, gameId := range action.GameIds {
+		if snapshot[gameId] {
+			gameIds = append(gameIds, gameId)
+		}
+	}
+	return gameIds
+}
+
+// Undoes and redoes the latest actions of a user within Window, restoring
+// the entries the action touched in one transaction
+type UndoInteractor struct {
+	UndoRepository UndoRepository //Nil turns undo off
+	GameRepository GameRepository
+	Profile        ProfileInteractor
+	Window         time.Duration
+}
+
+func (interactor *UndoInteractor) recent(userId int) ([]UndoAction, error, int) {
+	if interactor.UndoRepository == nil {
+		return nil, domain.NewError(domain.CodeUnavailable, "Undo is turned off"), 503
+	}
+	_, err, code := interactor.Profile.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	actions, err := interactor.UndoRepository.FindRecent(userId, time.Now().Add(-interactor.Window))
+	if err != nil {
+		return nil, err, 500
+	}
+	return actions, nil, 200
+}
+
+// The actions that can still be undone or redone, newest first
+func (interactor *UndoInteractor) ShowUndoStack(userId int) ([]UndoAction, error, int) {
+	actions, err, code := interactor.recent(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	for i := range actions {
+		actions[i] = interactor.describe(userId, actions[i])
+	}
+	return actions, nil, 200
+}
+
+// Fills in what users see of the action, in their time zone
+func (interactor *UndoInteractor) describe(userId int, action UndoAction) UndoAction {
+	location := userLocation(interactor.Profile.SettingsRepository, userId)
+	action.CreatedAt = action.CreatedAt.In(location)
+	if !action.UndoneAt.IsZero() {
+		action.UndoneAt = action.UndoneAt.In(location)
+	}
+	library, err, _ := interactor.Profile.LibraryRepository.FindById(action.LibraryId)
+	if err == nil {
+		action.LibraryExternalId = library.ExternalId
+	}
+	action.Games = make(map[int]Game)
+	for _, gameId := range action.GameIds {
+		game, err, _ := interactor.Profile.GameRepository.FindById(gameId)
+		if err == nil {
+			action.Games[gameId] = game
+		}
+	}
+	return action
+}
+
+// Undoes the latest action that is not undone yet
+func (interactor *UndoInteractor) Undo(userId int) (UndoAction, error, int) {
+	actions, err, code := interactor.recent(userId)
+	if err != nil {
+		return UndoAction{}, err, code
+	}
+	for _, action := range actions {
+		if action.UndoneAt.IsZero() {
+			return interactor.restore(userId, action, true)
+		}
+	}
+	return UndoAction{}, domain.NewError(domain.CodeNotFound, "Nothing to undo"), 404
+}
+
+// Redoes the action undone last
+func (interactor *UndoInteractor) Redo(userId int) (UndoAction, error, int) {
+	actions, err, code := interactor.recent(userId)
+	if err != nil {
+		return UndoAction{}, err, code
+	}
+	var latest *UndoAction
+	for i, action := range actions {
+		if !action.UndoneAt.IsZero() && (latest == nil || action.UndoneAt.After(latest.UndoneAt)) {
+			latest = &actions[i]
+		}
+	}
+	if latest == nil {
+		return UndoAction{}, domain.NewError(domain.CodeNotFound, "Nothing to redo"), 404
+	}
+	return interactor.restore(userId, *latest, false)
+}
+
+// Sets the entries back to Before when undoing or to After when redoing.
+// The action is claimed first and released when the restore fails.
+func (interactor *UndoInteractor) restore(userId int, action UndoAction, undo bool) (UndoAction, error, int) {
+	library, err, code := interactor.Profile.LibraryRepository.FindById(action.LibraryId)
+	if err != nil {
+		return UndoAction{}, err, code
+	}
+	allowed, err := interactor.Profile.libraryAllows(userId, library, LibraryRoleEditor)
+	if err != nil {
+		return UndoAction{}, err, 500
+	}
+	if !allowed {
+		message := "User #%d is not allowed to edit games in library #%d of user #%d"
+		err := domain.NewError(domain.CodeForbidden, message, userId, library.Id, library.User.Id)
+		return UndoAction{}, err, 403
+	}
+
+	claimed, err := interactor.UndoRepository.SetUndone(action.Id, undo)
+	if err != nil {
+		return UndoAction{}, err, 500
+	}
+	if !claimed {
+		return UndoAction{}, domain.NewError(domain.CodeConflict,
+			"Action #%d was undone or redone meanwhile", action.Id), 409
+	}
+	entries := action.After
+	if undo {
+		entries = action.Before
+	}
+	err = interactor.GameRepository.RestoreEntries(action.LibraryId, action.snapshotIds(), entries)
+	if err != nil {
+		interactor.UndoRepository.SetUndone(action.Id, !undo)
+		return UndoAction{}, err, 500
+	}
+
+	if undo {
+		action.UndoneAt = time.Now()
+		interactor.Profile.logf("User #%d undid %s #%d", userId, action.Kind, action.Id)
+	} else {
+		action.UndoneAt = time.Time{}
+		interactor.Profile.logf("User #%d redid %s #%d", userId, action.Kind, action.Id)
+	}
+	return interactor.describe(userId, action), nil, 200
+}
@@ -1,20 +1,49 @@
 package usecases
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
+	"time"
 
 	"game-tracker/domain"
 )
 
+var externalIdPattern = regexp.MustCompile(
+	`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Returned by repositories storing a name another user has, when it was
+// taken after it was checked
+type ErrAlreadyExists struct {
+	Field string //"name", as in requests
+	Name  string
+}
+
+func (err *ErrAlreadyExists) Error() string {
+	return fmt.Sprintf("%s '%s' already exists", err.Field, err.Name)
+}
+
+// The conflict users see for names taken meanwhile, other errors stay 500
+func nameTaken(err error) (error, int) {
+	var exists *ErrAlreadyExists
+	if !errors.As(err, &exists) {
+		return err, 500
+	}
+	return domain.NewError(domain.CodeConflict, "Username '%s' is taken", exists.Name), 409
+}
+
 type UserRepository interface {
 	Store(user User) (int, error)
 	Remove(user User) error
 	FindById(id int) (User, error, int)
+	FindByExternalId(externalId string) (User, error, int)
+	FindByName(name string, ignoreCase bool) (User, error, int)
 	UserExisted(userName string) (bool, error)
-	StoreInfo(user User, info string) error
+	StoreInfo(user User, info string, baseVersion int64) (int64, bool, error)
+	Rename(user User, name string) error
 	LoadInfo(user User) (string, error)
-	PlayerNameMatchesId(user User) (bool, error)
-	FindLoginId(username, password string) (int, bool, error)
+	CheckLogin(username, password string) (bool, error)
 	AddLoginInfo(username, password string) error
 	RemoveLoginInfo(user User) error
 }
@@ -23,34 +52,98 @@ type LibraryRepository interface {
 	Store(library Library) (int, error)
 	Remove(library Library) error
 	FindById(id int) (Library, error, int)
+	FindByExternalId(externalId string) (Library, error, int)
+	FindVersionsByUser(userId int) ([]Library, error)
 }
 
 type GameRepository interface {
 	Store(game Game) (int, error)
-	AddToLib(gameId, libraryId int) (error, int)
-	RemoveFromLib(game Game, libraryId int) error
+	// Stores the games whose name is not stored yet, as Store does, and
+	// returns every game of the batch as stored, in order
+	StoreBatch(games []Game) ([]Game, error)
+	// Adds and removes fail with 409 when baseVersion is not 0 and the
+	// library is past it
+	AddToLib(gameId, libraryId int, baseVersion int64) (error, int)
+	// Adds entries with their status and platform, games already in the
+	// library are left out. Returns the ids of the games added.
+	AddBatchToLib(libraryId int, entries []Game) ([]int, error)
+	RemoveFromLib(game Game, libraryId int, baseVersion int64) (error, int)
 	FindById(id int) (Game, error, int)
+	FindByExternalId(externalId string) (Game, error, int)
+	FindInLib(gameId, libraryId int) (Game, error, int)
+	// Entries whose base version is not 0 are changed only while at it.
+	// Returns the new version of every entry changed.
+	UpdateBatch(libraryId int, gameIds []int, change GameChange, baseVersions map[int]int64) (map[int]int64, error)
+	FindByLib(libraryId int, filter GameFilter, page Page) ([]Game, error) //Sorted by name, a zero page for every game
+	SetSpoilers(gameId int, containsSpoilers bool) error
+	RankWishlist(libraryId int, ranks map[int]int) error //Ranks keyed by game id
+	FindTags(libraryIds []int) ([]TagCount, error)       //Sorted by tag
+	// Replaces the from tags of every entry in the libraries by to, in one
+	// transaction. Returns how many entries changed per library id.
+	ReplaceTags(libraryIds []int, from []string, to string) (map[int]int, error)
+	// Sets the entries of gameIds to entries in one transaction, games
+	// without an entry are removed from the library. Games not in gameIds
+	// are left alone.
+	RestoreEntries(libraryId int, gameIds []int, entries []LibraryEntry) error
+}
+
+// Zero fields do not filter, games without a rating pass any MaxAge. Name
+// matches part of the name ignoring case, Sort is one of gameSorts.
+type GameFilter struct {
+	Rating   string
+	MaxAge   int
+	Name     string
+	Status   string
+	Platform string
+	Tag      string
+	Sort     string
 }
 
 type User struct {
-	Id           int
-	Name         string
-	Player       domain.Player //This user (account) was created by some player
-	PersonalInfo string
-	LibraryIds   []int
+	Id                 int
+	ExternalId         string
+	Name               string
+	Player             domain.Player //This user (account) was created by some player
+	PersonalInfo       string
+	LibraryIds         []int
+	LibraryExternalIds []string
+	Version            int64 //Bumped whenever the personal info is edited
+	Role               string
+	Status             string
+	StatusReason       string
+	SuspendedUntil     *time.Time //Nil while not suspended or suspended indefinitely
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
 }
 
 type Library struct {
-	Id      int
-	User    User //This library belongs to some user
-	GameIds []int
+	Id              int
+	ExternalId      string
+	User            User //This library belongs to some user
+	GameIds         []int
+	GameExternalIds []string
+	Version         int64 //Bumped whenever a game is added, removed or edited
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
 
 type Game struct {
-	Id       int
-	Name     string
-	Producer string
-	Value    float64
+	Id               int
+	ExternalId       string
+	Name             string
+	Producer         string
+	Value            float64
+	MinAge           int    //Youngest age the game is rated for, 0 when unrated
+	Rating           string //ESRB or PEGI rating such as "PEGI 12", MinAge follows from it
+	Status           string //Status, Platform, Tags, WishlistRank and Version belong to a
+	Platform         string //library entry, they are only set when loaded with FindInLib
+	Tags             []string
+	WishlistRank     int       //Position on the Steam wishlist it was imported from, 0 when unranked
+	Version          int64     //Bumped whenever the library entry is edited
+	EntryUpdatedAt   time.Time //When the library entry was last edited, set by FindByLib
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	ContainsSpoilers bool //Journal entries about it are hidden unless spoilers are asked for
 }
 
 type LoggerRepository interface {
@@ -61,64 +154,152 @@ type ProfileInteractor struct {
 	UserRepository    UserRepository
 	LibraryRepository LibraryRepository
 	GameRepository    GameRepository
-	Loggr             LoggerRepository
+	logging
+	SettingsRepository      SettingsRepository
+	EventBus                domain.EventBus
+	NamePolicy              NamePolicy //Usernames and player names must pass it, nil allows any
+	Flags                   *FlagService
+	Reporter                Reporter //Nil unless telemetry is opted into
+	Parental                *ParentalControls
+	MetadataProvider        MetadataProvider //Rates games added without a rating, nil leaves them unrated
+	Steam                   SteamStore       //Nil turns Steam imports off
+	SteamRepository         SteamRepository
+	Barcodes                BarcodeProvider //Nil only scans barcodes resolved before
+	PhysicalCopyRepository  PhysicalCopyRepository
+	Vision                  VisionProvider //Nil turns photo imports off
+	PhotoImportRepository   PhotoImportRepository
+	BlobStore               BlobStore //Keeps the photos of pending imports
+	WorkQueue               WorkQueue //Nil leaves pending imports to the photo import job
+	LibraryMemberRepository LibraryMemberRepository
+	TradeRepository         TradeRepository
+	AddonRepository         AddonRepository
+	ModRepository           ModRepository
+	SaveBackupRepository    SaveBackupRepository
+	Pricing                 PricingProvider //Nil leaves copies unvalued
+	Templates               DocumentTemplates
+	Printer                 DocumentRenderer //Nil turns printed reports off
+	UndoRepository          UndoRepository   //Nil turns undo off
+	UndoWindow              time.Duration    //How long actions can be undone
+	TrashRepository         TrashRepository  //Nil turns the trash off
+}
+
+func (interactor *ProfileInteractor) publish(event domain.Event) {
+	if interactor.EventBus != nil {
+		interactor.EventBus.Publish(event)
+	}
 }
 
-func (interactor *ProfileInteractor) AddUser(player domain.Player, userName, password string) (int, error, int) {
+// Creates the user together with its player, the player is found by name
+// when it already exists. The returned user carries both ids.
+func (interactor *ProfileInteractor) AddUser(playerName, userName, password string) (User, error, int) {
+	userName = domain.NormalizeName(userName)
+	playerName = domain.NormalizeName(playerName)
+	err := interactor.checkName("name", userName)
+	if err == nil {
+		err = interactor.checkName("playerName", playerName)
+	}
+	if err != nil {
+		return User{}, err, 400
+	}
+
 	// Application rule: usernames cannot repeat
 	existed, err := interactor.UserRepository.UserExisted(userName)
 	if err != nil {
-		return 0, err, 500
+		return User{}, err, 500
 	}
 	if existed {
-		err := fmt.Errorf("Username '%s' is taken", userName)
+		err := domain.NewError(domain.CodeConflict, "Username '%s' is taken", userName)
 		// interactor.Logger.Log(err.Error())
-		return 0, err, 400
+		return User{}, err, 409
 	}
 
-	user := User{Name: userName, Player: player, PersonalInfo: ""}
-
-	match, err := interactor.UserRepository.PlayerNameMatchesId(user)
+	user := User{Name: userName, Player: domain.Player{Name: playerName}, PersonalInfo: ""}
+	id, err := interactor.UserRepository.Store(user)
 	if err != nil {
-		return 0, err, 500
+		// interactor.Logger.Log(err.Error())
+		err, code := nameTaken(err)
+		return User{}, err, code
 	}
-	if !match {
-		err = fmt.Errorf("Player name does not match player Id")
-		return 0, err, 400
+	err = interactor.UserRepository.AddLoginInfo(userName, password)
+	if err != nil {
+		return User{}, err, 500
 	}
 
-	id, err := interactor.UserRepository.Store(user)
+	user, err, code := interactor.UserRepository.FindById(id)
 	if err != nil {
-		// interactor.Logger.Log(err.Error())
-		return 0, err, 500
+		return User{}, err, code
 	}
-	err = interactor.UserRepository.AddLoginInfo(userName, password)
+	interactor.count("AddUser")
+	interactor.logf("Added user #%d for player #%d", id, user.Player.Id)
+	return user, nil, 201
+}
+
+func (interactor *ProfileInteractor) ShowUser(userId int) (User, error, int) {
+	user, err, code := interactor.UserRepository.FindById(userId)
 	if err != nil {
-		return 0, err, 500
+		err = fmt.Errorf("User #%d does not exist", userId)
+		return User{}, err, code
 	}
+	location := userLocation(interactor.SettingsRepository, userId)
+	user.CreatedAt = user.CreatedAt.In(location)
+	user.UpdatedAt = user.UpdatedAt.In(location)
+	return user, nil, 200
+}
 
-	fmt.Printf("Added user #%d for player #%d\n", id, player.Id)
-	return id, nil, 201
+// Like ShowUser but refuses suspended and banned accounts, tokens are only
+// issued for users it returns
+func (interactor *ProfileInteractor) ShowActiveUser(userId int) (User, error, int) {
+	user, err, code := interactor.ShowUser(userId)
+	if err != nil {
+		return User{}, err, code
+	}
+	err = blockedAccount(user)
+	if err != nil {
+		return User{}, err, 403
+	}
+	return user, nil, 200
 }
 
-func (interactor *ProfileInteractor) ShowUser(userId int) (string, []int, error, int) {
+// Changes the username, changing only its letter case is allowed even
+// though the name then matches the user's own
+func (interactor *ProfileInteractor) RenameUser(userId int, name string) (User, error, int) {
+	if !interactor.Flags.IsEnabled(FlagUsernameChanges, userId) {
+		return User{}, featureDisabled(FlagUsernameChanges), 403
+	}
+	name = domain.NormalizeName(name)
+	err := interactor.checkName("name", name)
+	if err != nil {
+		return User{}, err, 400
+	}
 	user, err, code := interactor.UserRepository.FindById(userId)
 	if err != nil {
-		err = fmt.Errorf(fmt.Sprintf("User #%d does not exist", userId))
-		return "", nil, err, code
+		return User{}, err, code
 	}
-	var libraryIds []int
-	for _, libraryId := range user.LibraryIds {
-		libraryIds = append(libraryIds, libraryId)
+	if domain.NameKey(name) != domain.NameKey(user.Name) {
+		existed, err := interactor.UserRepository.UserExisted(name)
+		if err != nil {
+			return User{}, err, 500
+		}
+		if existed {
+			return User{}, domain.NewError(domain.CodeConflict, "Username '%s' is taken", name), 409
+		}
 	}
-	return user.Name, libraryIds, nil, 200
+	err = interactor.UserRepository.Rename(user, name)
+	if err != nil {
+		err, code := nameTaken(err)
+		return User{}, err, code
+	}
+	interactor.logf("Renamed user #%d from '%s' to '%s'", userId, user.Name, name)
+	interactor.count("RenameUser")
+	user.Name = name
+	return user, nil, 200
 }
 
 func (interactor *ProfileInteractor) RemoveUser(userId int) (error, int) {
 	user, err, code := interactor.UserRepository.FindById(userId)
 	if err != nil {
 		// interactor.Logger.Log(err.Error())
-		err = fmt.Errorf(fmt.Sprintf("User #%d does not exist", userId))
+		err = fmt.Errorf("User #%d does not exist", userId)
 		return err, code
 	}
 
@@ -128,6 +309,10 @@ func (interactor *ProfileInteractor) RemoveUser(userId int) (error, int) {
 			return err, code
 		}
 	}
+	err = interactor.LibraryMemberRepository.RemoveFromUser(userId)
+	if err != nil {
+		return err, 500
+	}
 	err = interactor.UserRepository.Remove(user)
 	if err != nil {
 		return err, 500
@@ -137,72 +322,113 @@ func (interactor *ProfileInteractor) RemoveUser(userId int) (error, int) {
 		return err, 500
 	}
 	// interactor.Logger.Log(fmt.Sprintf("Removed user #%s (id #%d)", user.Name, user.Id))
-	fmt.Printf("Deleted user #%d\n", userId)
+	interactor.count("RemoveUser")
+	interactor.logf("Deleted user #%d", userId)
+	interactor.publish(domain.Event{Name: domain.EventUserRemoved, UserId: userId, EntityId: userId})
 	return nil, 200
 }
 
-func (interactor *ProfileInteractor) ShowUserInfo(userId int) (string, error, int) {
+func (interactor *ProfileInteractor) ShowUserInfo(userId int) (string, int64, error, int) {
 	user, err, code := interactor.UserRepository.FindById(userId)
 	if err != nil {
-		err = fmt.Errorf(fmt.Sprintf("User #%d does not exist", userId))
-		return "", err, code
+		err = fmt.Errorf("User #%d does not exist", userId)
+		return "", 0, err, code
 	}
 	info, err := interactor.UserRepository.LoadInfo(user)
 	if err != nil {
-		return "", err, 500
+		return "", 0, err, 500
 	}
-	fmt.Println(fmt.Sprintf("Printed information of user #%d", user.Id))
-	return info, nil, 200
+	interactor.logf("Printed information of user #%d", user.Id)
+	return info, user.Version, nil, 200
 }
 
-func (interactor *ProfileInteractor) EditUserInfo(userId int, info string) (error, int) {
+// Edits are last-writer-wins unless the client passes the version it last
+// saw, a stale version is rejected instead of clobbering another device's edit.
+func (interactor *ProfileInteractor) EditUserInfo(userId int, info string, baseVersion int64) (int64, error, int) {
+	if baseVersion < 0 {
+		return 0, domain.NewFieldError("version", "Version must be positive"), 400
+	}
 	user, err, code := interactor.UserRepository.FindById(userId)
 	if err != nil {
-		return err, code
+		return 0, err, code
 	}
-	err = interactor.UserRepository.StoreInfo(user, info)
+	version, applied, err := interactor.UserRepository.StoreInfo(user, info, baseVersion)
 	if err != nil {
-		return err, 500
+		return 0, err, 500
 	}
-	fmt.Println(fmt.Sprintf("Editted information of user '%s' (id #%d)", user.Name, user.Id))
-	return nil, 200
+	if !applied {
+		err = domain.NewError(domain.CodeConflict,
+			"Info of user #%d was changed elsewhere, version %d is stale", user.Id, baseVersion)
+		return 0, err, 409
+	}
+	interactor.count("EditUserInfo")
+	interactor.logf("Editted information of user '%s' (id #%d)", user.Name, user.Id)
+	return version, nil, 200
 }
 
-func (interactor *ProfileInteractor) AddLibrary(userId int) (int, error, int) {
+func (interactor *ProfileInteractor) AddLibrary(userId int) (Library, error, int) {
 	user, err, code := interactor.UserRepository.FindById(userId)
 	if err != nil {
-		return 0, err, code
+		return Library{}, err, code
 	}
 
 	library := Library{User: user, GameIds: []int{}}
 	id, err := interactor.LibraryRepository.Store(library)
 	if err != nil {
-		return 0, err, 500
+		return Library{}, err, 500
+	}
+	library, err, code = interactor.LibraryRepository.FindById(id)
+	if err != nil {
+		return Library{}, err, code
 	}
-	fmt.Printf("User #%d added library #%d\n", user.Id, id)
-	return id, nil, 200
+	interactor.count("AddLibrary")
+	interactor.logf("User #%d added library #%d", user.Id, id)
+	interactor.publish(domain.Event{Name: domain.EventLibraryAdded, UserId: user.Id, EntityId: id})
+	return library, nil, 200
 }
 
-func (interactor *ProfileInteractor) ShowLibrary(userId, libraryId int) ([]int, error, int) {
-	var gameIds []int
+func (interactor *ProfileInteractor) ShowLibrary(userId, libraryId int) (Library, error, int) {
 	library, err, code := interactor.LibraryRepository.FindById(libraryId)
 	if err != nil {
-		err = fmt.Errorf(fmt.Sprintf("Library #%d of user #%d does not exist", libraryId, userId))
-		return nil, err, code
+		err = fmt.Errorf("Library #%d of user #%d does not exist", libraryId, userId)
+		return Library{}, err, code
 	}
 
-	if userId != library.User.Id {
+	allowed, err := interactor.libraryAllows(userId, library, LibraryRoleViewer)
+	if err != nil {
+		return Library{}, err, 500
+	}
+	if !allowed {
 		message := "User #%d is not allowed to see library #%d of user #%d"
-		err := fmt.Errorf(message, userId, libraryId, library.User.Id)
-		return nil, err, 403
+		err := domain.NewError(domain.CodeForbidden, message, userId, libraryId, library.User.Id)
+		return Library{}, err, 403
 	} else {
-		for _, gameId := range library.GameIds {
-			gameIds = append(gameIds, gameId)
-		}
-		return gameIds, nil, 200
+		location := userLocation(interactor.SettingsRepository, userId)
+		library.CreatedAt = library.CreatedAt.In(location)
+		library.UpdatedAt = library.UpdatedAt.In(location)
+		interactor.count("ShowLibrary")
+		interactor.reportLibrary(library)
+		return library, nil, 200
 	}
 }
 
+func (interactor *ProfileInteractor) ShowLibraryVersions(userId int) ([]Library, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		err = fmt.Errorf("User #%d does not exist", userId)
+		return nil, err, code
+	}
+	libraries, err := interactor.LibraryRepository.FindVersionsByUser(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	location := userLocation(interactor.SettingsRepository, userId)
+	for i := range libraries {
+		libraries[i].UpdatedAt = libraries[i].UpdatedAt.In(location)
+	}
+	return libraries, nil, 200
+}
+
 func (interactor *ProfileInteractor) RemoveLibrary(userId, libraryId int) (error, int) {
 	user, err, code := interactor.UserRepository.FindById(userId)
 	if err != nil {
@@ -212,27 +438,57 @@ func (interactor *ProfileInteractor) RemoveLibrary(userId, libraryId int) (error
 	if err != nil {
 		return err, code
 	}
-	if userId != library.User.Id {
-		err := fmt.Errorf("User #%d cannot remove library of user #%d",
+	allowed, err := interactor.libraryAllows(userId, library, LibraryRoleOwner)
+	if err != nil {
+		return err, 500
+	}
+	if !allowed {
+		err := domain.NewError(domain.CodeForbidden, "User #%d cannot remove library of user #%d",
 			userId, library.User.Id)
 		return err, 403
 	}
 
+	entries, err := interactor.libraryEntries(libraryId, library.GameIds)
+	if err != nil {
+		return err, 500
+	}
 	for _, gameId := range library.GameIds {
 		game, err, code := interactor.GameRepository.FindById(gameId)
 		if err != nil {
 			return err, code
 		}
-		err = interactor.GameRepository.RemoveFromLib(game, libraryId)
+		err, code = interactor.GameRepository.RemoveFromLib(game, libraryId, 0)
 		if err != nil {
-			return err, 500
+			return err, code
 		}
 	}
+	err = interactor.PhysicalCopyRepository.RemoveFromLib(libraryId, 0)
+	if err != nil {
+		return err, 500
+	}
+	keys, err := interactor.PhotoImportRepository.RemoveFromLib(libraryId)
+	if err != nil {
+		return err, 500
+	}
+	interactor.removePhotos(keys)
+	err = interactor.TradeRepository.CancelForLib(libraryId)
+	if err != nil {
+		return err, 500
+	}
+	err = interactor.LibraryMemberRepository.RemoveFromLib(libraryId)
+	if err != nil {
+		return err, 500
+	}
 	err = interactor.LibraryRepository.Remove(library)
 	if err != nil {
 		return err, 500
 	}
-	fmt.Printf("User #%d removed library #%d\n", user.Id, library.Id)
+	interactor.trash(TrashItem{UserId: user.Id, Kind: TrashedLibrary, LibraryId: library.Id,
+		Entries: entries})
+	interactor.count("RemoveLibrary")
+	interactor.logf("User #%d removed library #%d", user.Id, library.Id)
+	interactor.publish(domain.Event{Name: domain.EventLibraryRemoved, UserId: user.Id,
+		EntityId: library.Id})
 	return nil, 200
 }
 
@@ -245,50 +501,84 @@ func (interactor *ProfileInteractor) ShowGame(userId, libraryId, gameId int) (Ga
 	if err != nil {
 		return Game{}, err, code
 	}
-	if user.Id != library.User.Id {
+	allowed, err := interactor.libraryAllows(user.Id, library, LibraryRoleViewer)
+	if err != nil {
+		return Game{}, err, 500
+	}
+	if !allowed {
 		message := "User #%d is not allowed to see games in library #%d of user #%d"
-		err := fmt.Errorf(message, user.Id, library.Id, library.User.Id)
+		err := domain.NewError(domain.CodeForbidden, message, user.Id, library.Id, library.User.Id)
 		return Game{}, err, 403
 	}
 
-	game, err, code := interactor.GameRepository.FindById(gameId)
+	game, err, code := interactor.GameRepository.FindInLib(gameId, libraryId)
 	if err != nil {
 		return Game{}, err, code
 	}
+	location := userLocation(interactor.SettingsRepository, userId)
+	game.CreatedAt = game.CreatedAt.In(location)
+	game.UpdatedAt = game.UpdatedAt.In(location)
 	return game, nil, 200
 }
 
-func (interactor *ProfileInteractor) AddGame(userId, libraryId int, gameName, gameProducer string, gameValue float64) (int, error, int) {
+// The game is stored even when parental controls keep it out of the library.
+// Adding and removing games are last-writer-wins unless the client passes the
+// library version it last saw, as with user info.
+func (interactor *ProfileInteractor) AddGame(userId, libraryId int, game Game, baseVersion int64) (Game, error, int) {
+	if baseVersion < 0 {
+		return Game{}, domain.NewFieldError("version", "Version must be positive"), 400
+	}
+	game, err, code := interactor.rateGame(game)
+	if err != nil {
+		return Game{}, err, code
+	}
 	user, err, code := interactor.UserRepository.FindById(userId)
 	if err != nil {
-		return 0, err, code
+		return Game{}, err, code
 	}
 	library, err, code := interactor.LibraryRepository.FindById(libraryId)
 	if err != nil {
-		return 0, err, code
+		return Game{}, err, code
 	}
-	if user.Id != library.User.Id {
+	allowed, err := interactor.libraryAllows(user.Id, library, LibraryRoleEditor)
+	if err != nil {
+		return Game{}, err, 500
+	}
+	if !allowed {
 		message := "User #%d is not allowed to add games to library #%d of user #%d"
-		err := fmt.Errorf(message, user.Id, library.Id, library.User.Id)
-		return 0, err, 403
+		err := domain.NewError(domain.CodeForbidden, message, user.Id, library.Id, library.User.Id)
+		return Game{}, err, 403
 	}
 
-	game := Game{Name: gameName, Producer: gameProducer, Value: gameValue}
 	id, err := interactor.GameRepository.Store(game)
 	if err != nil {
-		return 0, err, 500
+		return Game{}, err, 500
 	}
-	err, code = interactor.GameRepository.AddToLib(id, libraryId)
+	game, err, code = interactor.GameRepository.FindById(id)
 	if err != nil {
-		return 0, err, code
+		return Game{}, err, code
+	}
+	err, code = interactor.Parental.CheckGame(user.Id, game)
+	if err != nil {
+		return Game{}, err, code
+	}
+	err, code = interactor.GameRepository.AddToLib(id, libraryId, baseVersion)
+	if err != nil {
+		return Game{}, err, code
 	}
 
-	fmt.Println(fmt.Sprintf("User added game %s (id #%d) to library #%d",
-		game.Name, id, library.Id))
-	return id, nil, 200
+	interactor.count("AddGame")
+	interactor.logf("User added game %s (id #%d) to library #%d",
+		game.Name, id, library.Id)
+	interactor.publish(domain.Event{Name: domain.EventGameAdded, UserId: user.Id, EntityId: id,
+		Payload: map[string]string{"name": game.Name, "libraryId": strconv.Itoa(library.Id)}})
+	return game, nil, 200
 }
 
-func (interactor *ProfileInteractor) PickGame(userId, libraryId, gameId int) (error, int) {
+func (interactor *ProfileInteractor) PickGame(userId, libraryId, gameId int, baseVersion int64) (error, int) {
+	if baseVersion < 0 {
+		return domain.NewFieldError("version", "Version must be positive"), 400
+	}
 	user, err, code := interactor.UserRepository.FindById(userId)
 	if err != nil {
 		return err, code
@@ -297,25 +587,39 @@ func (interactor *ProfileInteractor) PickGame(userId, libraryId, gameId int) (er
 	if err != nil {
 		return err, code
 	}
-	_, err, code = interactor.GameRepository.FindById(gameId)
+	game, err, code := interactor.GameRepository.FindById(gameId)
 	if err != nil {
 		return err, code
 	}
-	if user.Id != library.User.Id {
+	allowed, err := interactor.libraryAllows(user.Id, library, LibraryRoleEditor)
+	if err != nil {
+		return err, 500
+	}
+	if !allowed {
 		message := "User #%d is not allowed to add games to library #%d of user #%d"
-		err := fmt.Errorf(message, user.Id, library.Id, library.User.Id)
+		err := domain.NewError(domain.CodeForbidden, message, user.Id, library.Id, library.User.Id)
 		return err, 403
 	}
-	err, code = interactor.GameRepository.AddToLib(gameId, libraryId)
+	err, code = interactor.Parental.CheckGame(user.Id, game)
+	if err != nil {
+		return err, code
+	}
+	err, code = interactor.GameRepository.AddToLib(gameId, libraryId, baseVersion)
 	if err != nil {
 		return err, code
 	}
-	fmt.Println(fmt.Sprintf("User added game #%d to library #%d",
-		gameId, libraryId))
+	interactor.count("PickGame")
+	interactor.logf("User added game #%d to library #%d",
+		gameId, libraryId)
+	interactor.publish(domain.Event{Name: domain.EventGameAdded, UserId: user.Id, EntityId: gameId,
+		Payload: map[string]string{"name": game.Name, "libraryId": strconv.Itoa(libraryId)}})
 	return nil, 200
 }
 
-func (interactor *ProfileInteractor) RemoveGame(userId, libraryId, gameId int) (error, int) {
+func (interactor *ProfileInteractor) RemoveGame(userId, libraryId, gameId int, baseVersion int64) (error, int) {
+	if baseVersion < 0 {
+		return domain.NewFieldError("version", "Version must be positive"), 400
+	}
 	user, err, code := interactor.UserRepository.FindById(userId)
 	if err != nil {
 		// interactor.Logger.Log(err.Error())
@@ -326,9 +630,13 @@ func (interactor *ProfileInteractor) RemoveGame(userId, libraryId, gameId int) (
 		// interactor.Logger.Log(err.Error())
 		return err, code
 	}
-	if user.Player.Id != library.User.Player.Id {
+	allowed, err := interactor.libraryAllows(user.Id, library, LibraryRoleEditor)
+	if err != nil {
+		return err, 500
+	}
+	if !allowed && user.Player.Id != library.User.Player.Id {
 		message := "User #%d is not allowed to remove games from library #%d of user #%d"
-		err := fmt.Errorf(message, user.Id, library.Id, library.User.Id)
+		err := domain.NewError(domain.CodeForbidden, message, user.Id, library.Id, library.User.Id)
 		// interactor.Logger.Log(err.Error())
 		return err, 403
 	}
@@ -338,22 +646,104 @@ func (interactor *ProfileInteractor) RemoveGame(userId, libraryId, gameId int) (
 		return err, code
 	}
 
-	err = interactor.GameRepository.RemoveFromLib(game, libraryId)
+	before, err := interactor.libraryEntries(libraryId, []int{game.Id})
+	if err != nil {
+		return err, 500
+	}
+	err, code = interactor.GameRepository.RemoveFromLib(game, libraryId, baseVersion)
+	if err != nil {
+		return err, code
+	}
+	if len(before) > 0 {
+		interactor.recordUndo(UndoAction{UserId: user.Id, Kind: UndoRemoveGame, LibraryId: libraryId,
+			GameIds: []int{game.Id}, Before: before})
+		interactor.trash(TrashItem{UserId: user.Id, Kind: TrashedGame, LibraryId: libraryId,
+			Entries: before})
+	}
+	err = interactor.PhysicalCopyRepository.RemoveFromLib(libraryId, game.Id)
 	if err != nil {
 		return err, 500
 	}
+	interactor.count("RemoveGame")
+	interactor.publish(domain.Event{Name: domain.EventGameRemoved, UserId: user.Id, EntityId: game.Id,
+		Payload: map[string]string{"libraryId": strconv.Itoa(libraryId)}})
 	return nil, 200
 }
 
 func (interactor *ProfileInteractor) FindLoginId(username, password string) (int, error, int) {
-	id, exist, err := interactor.UserRepository.FindLoginId(username, password)
+	incorrect := domain.NewError(domain.CodeUnauthorized, "Username/password incorrect")
+	// Logins are stored under the username as registered, whatever case is typed
+	user, err, code := interactor.UserRepository.FindByName(username, true)
+	if code == 404 {
+		return 0, incorrect, 401
+	}
+	if err != nil {
+		return 0, err, code
+	}
+	exist, err := interactor.UserRepository.CheckLogin(user.Name, password)
 	if err != nil {
 		return 0, err, 500
 	}
 	if !exist {
-		err := fmt.Errorf("Username/password incorrect")
-		return 0, err, 400
+		return 0, incorrect, 401
+	}
+	interactor.logf("Found login id: #%d", user.Id)
+	return user.Id, nil, 200
+}
+
+// Resolves the username of a profile URL, letter case is ignored
+func (interactor *ProfileInteractor) FindUserIdByName(userName string) (int, error, int) {
+	user, err, code := interactor.UserRepository.FindByName(userName, true)
+	if code == 404 {
+		return 0, domain.NewError(domain.CodeNotFound, "User '%s' does not exist", userName), 404
+	}
+	if err != nil {
+		return 0, err, code
+	}
+	return user.Id, nil, 200
+}
+
+func (interactor *ProfileInteractor) FindUserId(externalId string) (int, error, int) {
+	notFound := domain.NewError(domain.CodeNotFound, "User '%s' does not exist", externalId)
+	if !externalIdPattern.MatchString(externalId) {
+		return 0, notFound, 404
+	}
+	user, err, code := interactor.UserRepository.FindByExternalId(externalId)
+	if code == 404 {
+		return 0, notFound, 404
+	}
+	if err != nil {
+		return 0, err, code
+	}
+	return user.Id, nil, 200
+}
+
+func (interactor *ProfileInteractor) FindLibraryId(externalId string) (int, error, int) {
+	notFound := domain.NewError(domain.CodeNotFound, "Library '%s' does not exist", externalId)
+	if !externalIdPattern.MatchString(externalId) {
+		return 0, notFound, 404
+	}
+	library, err, code := interactor.LibraryRepository.FindByExternalId(externalId)
+	if code == 404 {
+		return 0, notFound, 404
+	}
+	if err != nil {
+		return 0, err, code
+	}
+	return library.Id, nil, 200
+}
+
+func (interactor *ProfileInteractor) FindGameId(externalId string) (int, error, int) {
+	notFound := domain.NewError(domain.CodeNotFound, "Game '%s' does not exist", externalId)
+	if !externalIdPattern.MatchString(externalId) {
+		return 0, notFound, 404
+	}
+	game, err, code := interactor.GameRepository.FindByExternalId(externalId)
+	if code == 404 {
+		return 0, notFound, 404
+	}
+	if err != nil {
+		return 0, err, code
 	}
-	fmt.Printf("Found login id: #%d\n", id)
-	return id, nil, 200
+	return game.Id, nil, 200
 }
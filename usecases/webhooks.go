@@ -0,0 +1,236 @@
+package usecases
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"game-tracker/domain"
+)
+
+const (
+	// What an inbound webhook does with a delivery
+	WebhookNotify     = "notify"      //Puts the message in the owner's notifications
+	WebhookGameStatus = "game_status" //Sets the status of a game in the webhook's library
+
+	maxWebhooks             = 20 //Per user
+	maxWebhookNameLength    = 100
+	maxWebhookMessageLength = 500
+	webhookSignaturePrefix  = "sha256="
+)
+
+var webhookActions = map[string]bool{
+	WebhookNotify:     true,
+	WebhookGameStatus: true,
+}
+
+// Inbound webhooks are found by a hash of the key in their URL, the secret
+// signing deliveries is kept as is since it is needed to verify them
+type WebhookRepository interface {
+	Store(webhook Webhook) (int, error)
+	FindByKeyHash(keyHash string) (Webhook, bool, error)
+	FindByUser(userId int) ([]Webhook, error) //Oldest first
+	MarkDelivered(id int, at time.Time) error
+	Remove(webhook Webhook) error
+	RemoveAll(userId int) error
+}
+
+type Webhook struct {
+	Id                int
+	UserId            int
+	Name              string
+	Action            string
+	LibraryId         int //Only for game_status, 0 otherwise
+	LibraryExternalId string
+	KeyHash           string
+	Secret            string
+	LastDeliveredAt   time.Time //Zero until the first verified delivery
+	CreatedAt         time.Time
+}
+
+// The fields of a delivery the action needs, the rest is ignored
+type WebhookDelivery struct {
+	Message string
+	GameId  int
+	Status  string
+}
+
+type WebhookInteractor struct {
+	WebhookRepository WebhookRepository
+	UserRepository    UserRepository
+	LibraryRepository LibraryRepository
+	Profile           ProfileInteractor //Game changes go through it so they are checked as any other
+	EventBus          domain.EventBus
+	logging
+}
+
+func (interactor *WebhookInteractor) Subscribe(bus domain.EventBus) {
+	bus.Subscribe(domain.EventUserRemoved, func(event domain.Event) {
+		err := interactor.WebhookRepository.RemoveAll(event.UserId)
+		if err != nil {
+			interactor.logf("Cannot remove webhooks of user #%d: %v", event.UserId, err)
+		}
+	})
+}
+
+// The signature of a delivery, as senders put it in the signature header
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Adds a webhook, the returned key and the secret in the webhook are only
+// shown this once. libraryId is required for game_status and ignored
+// otherwise.
+func (interactor *WebhookInteractor) AddWebhook(userId int, name, action string, libraryId int) (Webhook, string, error, int) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxWebhookNameLength {
+		return Webhook{}, "", domain.NewFieldError("name", "Must be between 1 and %d characters",
+			maxWebhookNameLength), 400
+	}
+	if !webhookActions[action] {
+		return Webhook{}, "", domain.NewFieldError("action", "Must be %s or %s", WebhookNotify,
+			WebhookGameStatus), 400
+	}
+	user, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return Webhook{}, "", err, code
+	}
+	webhook := Webhook{UserId: userId, Name: name, Action: action, CreatedAt: time.Now().UTC()}
+	if action == WebhookGameStatus {
+		if libraryId == 0 {
+			return Webhook{}, "", domain.NewFieldError("libraryId", "Is required for %s",
+				WebhookGameStatus), 400
+		}
+		library, err, code := interactor.LibraryRepository.FindById(libraryId)
+		if err != nil {
+			return Webhook{}, "", err, code
+		}
+		allowed, err := interactor.Profile.libraryAllows(user.Id, library, LibraryRoleEditor)
+		if err != nil {
+			return Webhook{}, "", err, 500
+		}
+		if !allowed {
+			message := "User #%d is not allowed to edit games in library #%d of user #%d"
+			return Webhook{}, "", domain.NewError(domain.CodeForbidden, message, user.Id, library.Id,
+				library.User.Id), 403
+		}
+		webhook.LibraryId, webhook.LibraryExternalId = library.Id, library.ExternalId
+	}
+	webhooks, err := interactor.WebhookRepository.FindByUser(userId)
+	if err != nil {
+		return Webhook{}, "", err, 500
+	}
+	if len(webhooks) >= maxWebhooks {
+		return Webhook{}, "", domain.NewError(domain.CodeConflict,
+			"User #%d already has %d webhooks, remove one first", userId, maxWebhooks), 409
+	}
+
+	bytes := make([]byte, 56)
+	_, err = rand.Read(bytes)
+	if err != nil {
+		return Webhook{}, "", err, 500
+	}
+	key, secret := hex.EncodeToString(bytes[:24]), hex.EncodeToString(bytes[24:])
+	webhook.KeyHash, webhook.Secret = hashToken(key), secret
+	webhook.Id, err = interactor.WebhookRepository.Store(webhook)
+	if err != nil {
+		return Webhook{}, "", err, 500
+	}
+	interactor.logf("User #%d added webhook #%d", userId, webhook.Id)
+	return webhook, key, nil, 201
+}
+
+func (interactor *WebhookInteractor) ShowWebhooks(userId int) ([]Webhook, error, int) {
+	_, err, code := interactor.UserRepository.FindById(userId)
+	if err != nil {
+		return nil, err, code
+	}
+	webhooks, err := interactor.WebhookRepository.FindByUser(userId)
+	if err != nil {
+		return nil, err, 500
+	}
+	return webhooks, nil, 200
+}
+
+func (interactor *WebhookInteractor) RemoveWebhook(userId, webhookId int) (error, int) {
+	webhooks, err, code := interactor.ShowWebhooks(userId)
+	if err != nil {
+		return err, code
+	}
+	for _, webhook := range webhooks {
+		if webhook.Id == webhookId {
+			err = interactor.WebhookRepository.Remove(webhook)
+			if err != nil {
+				return err, 500
+			}
+			interactor.logf("User #%d removed webhook #%d", userId, webhookId)
+			return nil, 200
+		}
+	}
+	return domain.NewError(domain.CodeNotFound, "Webhook #%d does not exist", webhookId), 404
+}
+
+// Finds the webhook of a key and checks the body was signed with its secret.
+// Unknown keys and bad signatures answer alike so keys cannot be probed.
+func (interactor *WebhookInteractor) VerifyDelivery(key string, body []byte, signature string) (Webhook, error, int) {
+	webhook, found, err := interactor.WebhookRepository.FindByKeyHash(hashToken(key))
+	if err != nil {
+		return Webhook{}, err, 500
+	}
+	invalid := domain.NewError(domain.CodeUnauthorized, "Webhook signature is invalid")
+	if !found {
+		return Webhook{}, invalid, 401
+	}
+	expected := WebhookSignature(webhook.Secret, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(strings.TrimSpace(signature)))) {
+		return Webhook{}, invalid, 401
+	}
+	return webhook, nil, 200
+}
+
+// Applies a verified delivery, see VerifyDelivery
+func (interactor *WebhookInteractor) Deliver(webhook Webhook, delivery WebhookDelivery) (error, int) {
+	switch webhook.Action {
+	case WebhookNotify:
+		message := strings.TrimSpace(delivery.Message)
+		if message == "" || utf8.RuneCountInString(message) > maxWebhookMessageLength {
+			return domain.NewFieldError("message", "Must be between 1 and %d characters",
+				maxWebhookMessageLength), 400
+		}
+		if interactor.EventBus != nil {
+			interactor.EventBus.Publish(domain.Event{Name: domain.EventWebhookReceived,
+				UserId: webhook.UserId, EntityId: webhook.Id,
+				Payload: map[string]string{"name": webhook.Name, "message": message}})
+		}
+	case WebhookGameStatus:
+		if delivery.GameId == 0 {
+			return domain.NewFieldError("gameId", "Is required for %s", WebhookGameStatus), 400
+		}
+		status := delivery.Status
+		items, err, code := interactor.Profile.UpdateGames(webhook.UserId, webhook.LibraryId,
+			[]int{delivery.GameId}, GameChange{Status: &status}, nil)
+		if err != nil {
+			return err, code
+		}
+		for _, item := range items {
+			if item.Error != nil {
+				return item.Error, item.Code
+			}
+		}
+	default:
+		return domain.NewError(domain.CodeInvalid, "Webhook action '%s' is unknown", webhook.Action), 500
+	}
+
+	err := interactor.WebhookRepository.MarkDelivered(webhook.Id, time.Now().UTC())
+	if err != nil {
+		return err, 500
+	}
+	interactor.logf("Webhook #%d of user #%d delivered", webhook.Id, webhook.UserId)
+	return nil, 200
+}